@@ -11,7 +11,7 @@ from sqlalchemy import select
 from db.models import Patch, Run
 from db.session import get_db
 from schemas.patches import LLMPatchSummaryResponse, PatchOut
-from services.confluence_client import ConfluenceClient
+from services.confluence_client import ConfluenceClient, load_confluence_settings
 from services.confluence_publisher import ConfluencePublisher
 
 router = APIRouter(prefix="/patches", tags=["patches"])
@@ -217,7 +217,7 @@ def apply_patch(
         # Load connection from database
         from db.models import Connection
         from core.encryption import decrypt_token
-        from autodoc.config.settings import ConfluenceSettings, get_settings
+        from autodoc.config.settings import ConfluenceSettings
 
         connection = db.execute(select(Connection).limit(1)).scalar_one_or_none()
         if not connection:
@@ -235,20 +235,20 @@ def apply_patch(
                 detail=f"Failed to decrypt connection token: {e}",
             ) from e
 
-        # Get app settings for timeout and max_retries
-        app_settings = get_settings()
+        # Get the environment and autodoc.yaml settings for timeout and max_retries
+        defaults = load_confluence_settings()
         
         # Create ConfluenceSettings from database connection
         # Use token as username if CONFLUENCE_USERNAME is not set (token:token format)
-        username = app_settings.confluence.username or decrypted_token
+        username = defaults.username or decrypted_token
 
         confluence_settings = ConfluenceSettings(
             url=connection.confluence_base_url,
             username=username,
             token=decrypted_token,
             space_key=connection.space_key,
-            timeout=app_settings.confluence.timeout,
-            max_retries=app_settings.confluence.max_retries,
+            timeout=defaults.timeout,
+            max_retries=defaults.max_retries,
         )
 
         # Get Confluence client and publisher with database connection settings
@@ -0,0 +1,98 @@
+"""``autodoc analytics`` - rank the most viewed symbols of hosted docs."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import add_config_argument
+from autodoc.config.project import ProjectConfigError, load_project_config
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_analytics import load_views, most_viewed, render_report
+from services.schema import stamp_schema
+
+DEFAULT_TOP = 20
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``analytics`` subcommand."""
+    parser = subparsers.add_parser(
+        "analytics",
+        help="Rank the most viewed symbols and pages of the docs",
+        description=(
+            "Read the page views recorded by 'autodoc serve --analytics' or a "
+            "site.analytics.beacon endpoint and list the most viewed symbols "
+            "and pages. With --root, only symbols are listed, each noted as "
+            "documented or not, so the most read docs are improved first."
+        ),
+    )
+    parser.add_argument(
+        "logs",
+        nargs="+",
+        metavar="LOG",
+        help="File of page views, one JSON object per line",
+    )
+    parser.add_argument(
+        "--root",
+        default=None,
+        help="Source tree the docs were generated from, to check docstrings",
+    )
+    add_config_argument(parser)
+    parser.add_argument(
+        "--top",
+        type=int,
+        default=DEFAULT_TOP,
+        metavar="N",
+        help=f"Symbols and pages to list (default: {DEFAULT_TOP})",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["text", "markdown", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.add_argument(
+        "--output",
+        default=None,
+        help="File to write (default: stdout)",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``analytics`` subcommand."""
+    try:
+        views = load_views(args.logs)
+        documented = None
+        if args.root is not None:
+            config = load_project_config(args.root, args.config)
+            documented = {
+                symbol.qualified_name: symbol.is_documented
+                for package in build_model(parse_tree(args.root), config)
+                for symbol in package.symbols()
+            }
+    except (OSError, ProjectConfigError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    symbols, pages = most_viewed(views, documented, args.top)
+    if args.format == "json":
+        data = {
+            "views": len(views),
+            "symbols": [count.to_dict() for count in symbols],
+            "pages": [count.to_dict() for count in pages],
+        }
+        text = json.dumps(stamp_schema(data), indent=2) + "\n"
+    else:
+        text = render_report(symbols, pages, len(views), args.format == "markdown")
+    if args.output is None:
+        sys.stdout.write(text)
+        return 0
+    try:
+        Path(args.output).write_text(text, encoding="utf-8")
+    except OSError as exc:
+        print(f"Error: Cannot write {args.output}: {exc}", file=sys.stderr)
+        return 1
+    print(f"Wrote the report to {args.output}")
+    return 0
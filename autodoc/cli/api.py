@@ -0,0 +1,219 @@
+"""``autodoc api`` - write or check the public API manifest."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import (
+    add_config_argument,
+    add_dry_run_argument,
+    add_signing_arguments,
+    add_timeout_argument,
+    add_walk_arguments,
+    report_plan,
+    walk_options,
+)
+from autodoc.config.project import ProjectConfigError, load_project_config
+from services.api_manifest import (
+    DEFAULT_MANIFEST_FILE,
+    ApiManifestError,
+    diff_manifest,
+    freeze,
+    load_manifest,
+    manifest_lines,
+    parse_manifest,
+    render_manifest,
+    write_manifest,
+)
+from services.doc_symbols import load_doc_symbols
+from services.git_source import GitError, repo_root, show_file, tags
+from services.schema import stamp_schema
+from services.semver import VersionError, latest_tag, parse_version, recommend
+from services.signing import SigningError, sign_file
+from services.write_plan import plan_writes
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``api`` subcommand."""
+    parser = subparsers.add_parser(
+        "api",
+        help="Write or check the public API manifest",
+        description=(
+            "Write a sorted manifest of the exported API surface, or with "
+            "--check fail when the code no longer matches it. The lines of "
+            "frozen packages (api.frozen in autodoc.yaml) are never rewritten. "
+            "--bump recommends the next semantic version from the API changes "
+            "since the last release."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    add_config_argument(parser)
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--manifest",
+        default=None,
+        help=f"Manifest file (default: <root>/{DEFAULT_MANIFEST_FILE})",
+    )
+    mode = parser.add_mutually_exclusive_group()
+    mode.add_argument(
+        "--check",
+        action="store_true",
+        help="Fail if the manifest is out of date instead of writing it",
+    )
+    mode.add_argument(
+        "--bump",
+        action="store_true",
+        help="Recommend a semver bump from the API changes since --since",
+    )
+    parser.add_argument(
+        "--since",
+        default=None,
+        help="Released revision to compare against (default: highest semver tag)",
+    )
+    parser.add_argument(
+        "--current-version",
+        default=None,
+        help="Version of --since (default: parsed from the --since tag)",
+    )
+    parser.add_argument(
+        "--tag",
+        default=None,
+        help="Pending release tag to validate against the recommendation",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format for --bump (default: text)",
+    )
+    add_signing_arguments(parser, "written manifest")
+    add_dry_run_argument(parser)
+    parser.set_defaults(handler=run)
+
+
+def _governance(governance: str | None) -> str:
+    if governance:
+        return f"see {governance}"
+    return "unfreeze it in api.frozen of autodoc.yaml once the change is approved"
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``api`` subcommand."""
+    path = Path(args.manifest or Path(args.root) / DEFAULT_MANIFEST_FILE)
+    try:
+        config = load_project_config(args.root, args.config).api
+    except ProjectConfigError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    symbols = load_doc_symbols(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    if args.cancel.partial:
+        # A partial parse would look like removed API.
+        print(f"Error: {path} not used with a partial parse", file=sys.stderr)
+        return 1
+    current = manifest_lines(symbols)
+    if args.bump:
+        return run_bump(args, path, current)
+    if not args.check:
+        if config.frozen and path.is_file():
+            try:
+                committed = load_manifest(path)
+            except ApiManifestError as exc:
+                print(f"Error: {exc}", file=sys.stderr)
+                return 1
+            held = diff_manifest(committed, current).frozen(config.frozen)
+            for package, count in held.items():
+                print(
+                    f"Warning: kept the committed API of frozen package {package} "
+                    f"({count} changed line(s)); {_governance(config.governance)}",
+                    file=sys.stderr,
+                )
+            current = freeze(committed, current, config.frozen)
+        if args.dry_run:
+            return report_plan(plan_writes([(path, render_manifest(current))]))
+        write_manifest(current, path)
+        print(f"Wrote {len(current)} API line(s) to {path}")
+        if args.sign:
+            try:
+                signature = sign_file(path, args.sign, args.sign_key)
+            except SigningError as exc:
+                print(f"Error: {exc}", file=sys.stderr)
+                return 1
+            print(f"Signed {path} with {args.sign}: {signature}")
+        return 0
+
+    try:
+        committed = load_manifest(path)
+    except ApiManifestError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    diff = diff_manifest(committed, current)
+    if not diff:
+        print(f"API manifest {path} is up to date")
+        return 0
+    for line in diff.removed:
+        print(f"- {line}")
+    for line in diff.added:
+        print(f"+ {line}")
+    changed = diff.frozen(config.frozen)
+    for package, count in changed.items():
+        print(
+            f"Error: the API of frozen package {package} changed "
+            f"({count} line(s)); {_governance(config.governance)}",
+            file=sys.stderr,
+        )
+    if sum(changed.values()) < len(diff.added) + len(diff.removed):
+        print(
+            f"API manifest {path} is out of date: {len(diff.added)} added, "
+            f"{len(diff.removed)} removed; run 'autodoc api' to update it",
+            file=sys.stderr,
+        )
+    return 1
+
+
+def _released_manifest(args: argparse.Namespace, path: Path) -> tuple[str, list[str]]:
+    """The ``--since`` revision and the manifest committed at it."""
+    repo = repo_root(Path(args.root))
+    since = args.since or latest_tag(tags(repo))
+    if since is None:
+        raise ApiManifestError("No semver tag found; pass --since")
+    relative = path.resolve().relative_to(repo.resolve()).as_posix()
+    text = show_file(repo, since, relative)
+    if text is None:
+        raise ApiManifestError(f"{relative} does not exist at {since}")
+    return since, parse_manifest(text)
+
+
+def run_bump(args: argparse.Namespace, path: Path, current: list[str]) -> int:
+    """Execute ``api --bump``."""
+    try:
+        since, released = _released_manifest(args, path)
+        version = parse_version(args.current_version or since)
+        recommendation = recommend(diff_manifest(released, current), version)
+        problem = recommendation.check_tag(args.tag) if args.tag else None
+    except (ApiManifestError, GitError, VersionError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    if args.format == "json":
+        report = {**recommendation.to_dict(), "since": since}
+        print(json.dumps(stamp_schema(report), indent=2))
+    else:
+        print(
+            f"{recommendation.change} changes since {since}: bump "
+            f"{recommendation.level} to {recommendation.next}",
+        )
+    if problem:
+        print(f"Error: {problem}", file=sys.stderr)
+        return 1
+    return 0
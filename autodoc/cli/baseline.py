@@ -0,0 +1,133 @@
+"""``autodoc baseline`` - record and report accepted documentation findings."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import (
+    add_dry_run_argument,
+    add_offline_argument,
+    add_source_arguments,
+    lint_tree,
+    report_plan,
+)
+from autodoc.config.project import ProjectConfigError
+from services.custom_lint_rules import RuleConfigError
+from services.doc_baseline import (
+    DEFAULT_BASELINE_FILE,
+    BaselineError,
+    aging_report,
+    build_baseline,
+    load_baseline,
+    render_baseline,
+    write_baseline,
+)
+from services.git_source import GitError
+from services.schema import stamp_schema
+from services.write_plan import plan_writes
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``baseline`` subcommand and its actions."""
+    parser = subparsers.add_parser(
+        "baseline",
+        help="Record existing findings so only new problems fail checks",
+        description=(
+            "Manage the documentation baseline: the set of existing lint "
+            "findings that enforcement tolerates."
+        ),
+    )
+    actions = parser.add_subparsers(dest="action", required=True)
+
+    write_parser = actions.add_parser(
+        "write",
+        help="Record the current findings as the baseline",
+    )
+    add_source_arguments(write_parser)
+    add_offline_argument(write_parser)
+    write_parser.add_argument(
+        "--output",
+        default=None,
+        help=f"Baseline file (default: <root>/{DEFAULT_BASELINE_FILE})",
+    )
+    add_dry_run_argument(write_parser)
+    write_parser.set_defaults(handler=run_write)
+
+    report_parser = actions.add_parser(
+        "report",
+        help="Show how long baselined findings have been tolerated",
+    )
+    add_source_arguments(report_parser)
+    add_offline_argument(report_parser)
+    report_parser.add_argument(
+        "--baseline",
+        default=None,
+        help=f"Baseline file (default: <root>/{DEFAULT_BASELINE_FILE})",
+    )
+    report_parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    report_parser.set_defaults(handler=run_report)
+
+
+def _baseline_path(args: argparse.Namespace, value: str | None) -> Path:
+    return Path(value) if value else Path(args.root) / DEFAULT_BASELINE_FILE
+
+
+def run_write(args: argparse.Namespace) -> int:
+    """Execute ``baseline write``."""
+    path = _baseline_path(args, args.output)
+    try:
+        findings = lint_tree(args)
+        previous = load_baseline(path) if path.exists() else None
+    except (GitError, BaselineError, ProjectConfigError, RuleConfigError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    if args.cancel.partial:
+        # Writing would drop the entries of every file that was not parsed.
+        print(f"Error: {path} not written from a partial parse", file=sys.stderr)
+        return 1
+
+    baseline = build_baseline(findings, previous)
+    if args.dry_run:
+        return report_plan(plan_writes([(path, render_baseline(baseline))]))
+    write_baseline(baseline, path)
+    print(f"Wrote {len(baseline.entries)} baseline entries to {path}")
+    return 0
+
+
+def run_report(args: argparse.Namespace) -> int:
+    """Execute ``baseline report``."""
+    path = _baseline_path(args, args.baseline)
+    try:
+        baseline = load_baseline(path)
+        findings = lint_tree(args)
+    except (GitError, BaselineError, ProjectConfigError, RuleConfigError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    report = aging_report(baseline, findings)
+    if args.format == "json":
+        print(json.dumps(stamp_schema(report), indent=2))
+        return 0
+
+    print(f"Baselined findings: {report['total']}")
+    for label, count in report["by_age"].items():
+        print(f"  {label}: {count}")
+    print("By rule:")
+    for rule, count in report["by_rule"].items():
+        print(f"  {rule}: {count}")
+    if report["oldest"]:
+        print("Oldest:")
+        for entry in report["oldest"]:
+            print(f"  {entry['age_days']}d  {entry['symbol']} [{entry['rule']}]")
+    if report["fixed"]:
+        print(
+            f"{len(report['fixed'])} baselined finding(s) are fixed; "
+            "run 'autodoc baseline write' to prune them",
+        )
+    return 0
@@ -0,0 +1,140 @@
+"""``autodoc browse`` - read the docs of a tree in the terminal."""
+
+import argparse
+import os
+import sys
+
+from autodoc.cli.options import (
+    add_config_argument,
+    add_timeout_argument,
+    add_walk_arguments,
+    walk_options,
+)
+from autodoc.config.project import ProjectConfigError, load_project_config
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_browser import DocBrowser
+from services.doc_markdown import render_symbol_markdown
+
+# Key codes of the terminal (besides curses' own) -> names of DocBrowser keys.
+_KEY_CODES = {
+    10: "enter",
+    13: "enter",
+    8: "backspace",
+    127: "backspace",
+    27: "escape",
+}
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``browse`` subcommand."""
+    parser = subparsers.add_parser(
+        "browse",
+        help="Read the docs of a tree in the terminal",
+        description=(
+            "Browse the packages and symbols of a tree, search them, and read "
+            "their docs in an interactive terminal view, without generating a "
+            "site. Useful over SSH."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to document (default: current directory)",
+    )
+    add_config_argument(parser)
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--include-private",
+        action="store_true",
+        help="Also document private symbols",
+    )
+    parser.add_argument(
+        "--show",
+        default=None,
+        metavar="SYMBOL",
+        help="Print the docs of one symbol, by qualified name, and exit",
+    )
+    parser.set_defaults(handler=run)
+
+
+def _browse(screen: "curses.window", browser: DocBrowser) -> None:
+    import curses
+
+    names = {
+        curses.KEY_UP: "up",
+        curses.KEY_DOWN: "down",
+        curses.KEY_LEFT: "left",
+        curses.KEY_RIGHT: "right",
+        curses.KEY_PPAGE: "pageup",
+        curses.KEY_NPAGE: "pagedown",
+        curses.KEY_HOME: "home",
+        curses.KEY_END: "end",
+        curses.KEY_ENTER: "enter",
+        curses.KEY_BACKSPACE: "backspace",
+        **_KEY_CODES,
+    }
+    styles = {
+        "title": curses.A_BOLD,
+        "plain": curses.A_NORMAL,
+        "selected": curses.A_REVERSE,
+        "status": curses.A_DIM,
+    }
+    curses.curs_set(0)
+    while True:
+        height, width = screen.getmaxyx()
+        screen.erase()
+        for row, (text, style) in enumerate(browser.screen(width, height)):
+            # Writing the bottom-right cell moves the cursor off the screen.
+            screen.addnstr(row, 0, text, max(width - 1, 0), styles[style])
+        screen.refresh()
+        code = screen.get_wch()
+        if isinstance(code, str):
+            key = names.get(ord(code), code) if len(code) == 1 else None
+        else:
+            key = names.get(code)
+        if key is not None and not browser.press(key, height):
+            return
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``browse`` subcommand."""
+    try:
+        config = load_project_config(args.root, args.config)
+    except ProjectConfigError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    tree = parse_tree(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    browser = DocBrowser(
+        build_model(tree, config, args.include_private),
+        config.site.title,
+    )
+    if args.show is not None:
+        found = browser.find(args.show)
+        if found is None:
+            print(f"Error: no documented symbol {args.show}", file=sys.stderr)
+            return 1
+        package, symbol = found
+        print(render_symbol_markdown(package, symbol.qualified_name), end="")
+        return 0
+    if not (sys.stdin.isatty() and sys.stdout.isatty()):
+        print(
+            "Error: browse needs a terminal; use --show to print one symbol",
+            file=sys.stderr,
+        )
+        return 1
+    try:
+        import curses
+    except ImportError:
+        print("Error: browse needs the curses module", file=sys.stderr)
+        return 1
+    # Esc closes the search; curses would otherwise wait a second for more keys.
+    os.environ.setdefault("ESCDELAY", "25")
+    curses.wrapper(_browse, browser)
+    return 0
@@ -0,0 +1,77 @@
+"""``autodoc collisions`` - report confusable exported names."""
+
+import argparse
+import json
+
+from autodoc.cli.options import add_timeout_argument, add_walk_arguments, walk_options
+from autodoc.parser import parse_tree
+from services.doc_site import summary
+from services.doc_symbols import root_relative
+from services.name_collisions import find_collisions
+from services.schema import stamp_schema
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``collisions`` subcommand."""
+    parser = subparsers.add_parser(
+        "collisions",
+        help="Report exported names shared across packages or shadowing builtins",
+        description=(
+            "List exported classes and functions whose name is defined in "
+            "several packages, or hides a Python builtin, with the namesakes "
+            "that have no distinct summary to tell them apart."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.add_argument(
+        "--check",
+        action="store_true",
+        help="Exit with status 1 when any collision is reported",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``collisions`` subcommand."""
+    tree = parse_tree(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    collisions = find_collisions(root_relative(tree.symbols, tree.root))
+    if args.format == "json":
+        report = {"collisions": [c.to_dict() for c in collisions]}
+        print(json.dumps(stamp_schema(report), indent=2))
+        return 1 if args.check and collisions else 0
+
+    for collision in collisions:
+        reasons = []
+        if len(collision.packages) > 1:
+            reasons.append(f"defined in {len(collision.packages)} packages")
+        if collision.shadows_builtin:
+            reasons.append("shadows a builtin")
+        print(f"{collision.name}: {', '.join(reasons)}")
+        indistinct = set(collision.indistinct)
+        for symbol in collision.symbols:
+            text = summary(symbol.docstring)
+            if not text:
+                text = "no summary"
+            elif symbol.qualified_name in indistinct:
+                text += " (same summary as a namesake)"
+            location = f"{symbol.file_path}:{symbol.lineno}"
+            print(f"  {location}: {symbol.qualified_name} - {text}")
+    print(f"{len(collisions)} name collision(s)")
+    return 1 if args.check and collisions else 0
@@ -0,0 +1,86 @@
+"""``autodoc compare`` - report the pages that differ between two sites."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from services.schema import stamp_schema
+from services.site_compare import (
+    SiteCompareError,
+    compare_sites,
+    render_markdown,
+    render_text,
+)
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``compare`` subcommand."""
+    parser = subparsers.add_parser(
+        "compare",
+        help="Report which pages changed, were added, or were removed between sites",
+        description=(
+            "Compare two outputs of 'autodoc generate', such as the site of "
+            "the last release and that of the release candidate, and list "
+            "the pages that changed, with the lines they gained and lost, "
+            "and the pages only one of them has."
+        ),
+    )
+    parser.add_argument("old", help="Directory of the earlier site")
+    parser.add_argument("new", help="Directory of the later site")
+    parser.add_argument(
+        "--ignore",
+        action="append",
+        default=[],
+        metavar="PATTERN",
+        help="Leave out pages matching this fnmatch pattern; repeatable",
+    )
+    parser.add_argument(
+        "--diff",
+        action="store_true",
+        help="Also print a unified diff of each changed text page",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["text", "markdown", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.add_argument(
+        "--output",
+        default=None,
+        help="File to write (default: stdout)",
+    )
+    parser.add_argument(
+        "--exit-code",
+        action="store_true",
+        help="Exit 1 if the sites differ",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``compare`` subcommand."""
+    try:
+        comparison = compare_sites(args.old, args.new, args.ignore)
+    except (OSError, SiteCompareError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    if args.format == "json":
+        text = json.dumps(stamp_schema(comparison.to_dict()), indent=2) + "\n"
+    elif args.format == "markdown":
+        text = render_markdown(comparison, args.diff)
+    else:
+        text = render_text(comparison, args.diff)
+    status = 1 if args.exit_code and comparison.changes else 0
+    if args.output is None:
+        sys.stdout.write(text)
+        return status
+    try:
+        Path(args.output).write_text(text, encoding="utf-8")
+    except OSError as exc:
+        print(f"Error: Cannot write {args.output}: {exc}", file=sys.stderr)
+        return 1
+    print(f"Wrote the comparison to {args.output}")
+    return status
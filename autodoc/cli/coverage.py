@@ -0,0 +1,64 @@
+"""``autodoc coverage`` - report documentation coverage per package."""
+
+import argparse
+import json
+import sys
+
+from autodoc.cli.options import add_source_arguments, load_symbols
+from services.doc_coverage import compute_coverage, packages_below_threshold
+from services.git_source import GitError
+from services.schema import stamp_schema
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``coverage`` subcommand."""
+    parser = subparsers.add_parser(
+        "coverage",
+        help="Report documentation coverage per package",
+        description=(
+            "Compute documentation coverage per package, optionally failing "
+            "when any package is below a threshold."
+        ),
+    )
+    add_source_arguments(parser)
+    parser.add_argument(
+        "--threshold",
+        type=float,
+        default=None,
+        help="Fail when any package is below this coverage percentage",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``coverage`` subcommand."""
+    try:
+        symbols = load_symbols(args)
+    except GitError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    coverage = compute_coverage(symbols)
+    failing = (
+        packages_below_threshold(coverage, args.threshold)
+        if args.threshold is not None
+        else []
+    )
+
+    if args.format == "json":
+        report = {"packages": [c.to_dict() for c in coverage]}
+        print(json.dumps(stamp_schema(report), indent=2))
+    else:
+        for package in coverage:
+            marker = " (below threshold)" if package in failing else ""
+            print(
+                f"{package.package}: {package.percent:.1f}% "
+                f"({package.documented}/{package.total}){marker}",
+            )
+    return 1 if failing else 0
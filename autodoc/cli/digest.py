@@ -0,0 +1,88 @@
+"""``autodoc digest`` - summarize a period of documentation changes."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from services.doc_digest import (
+    DEFAULT_PERIOD,
+    DEFAULT_TOP,
+    DigestError,
+    collect_digest,
+    parse_period,
+    render_html,
+    render_markdown,
+)
+from services.git_source import GitError
+from services.schema import stamp_schema
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``digest`` subcommand."""
+    parser = subparsers.add_parser(
+        "digest",
+        help="Summarize API, documentation, and coverage changes over a period",
+        description=(
+            "Compare the tree at the last commit before the period with HEAD "
+            "and summarize the API changes, newly documented symbols, coverage "
+            "movement, and the largest undocumented additions, as Markdown or "
+            "email-ready HTML."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to summarize (default: current directory)",
+    )
+    parser.add_argument(
+        "--since",
+        default=DEFAULT_PERIOD,
+        metavar="PERIOD",
+        help=f"How far back to look: 12h, 7d, 2w, ... (default: {DEFAULT_PERIOD})",
+    )
+    parser.add_argument(
+        "--top",
+        type=int,
+        default=DEFAULT_TOP,
+        metavar="N",
+        help=f"Undocumented additions to list (default: {DEFAULT_TOP})",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["markdown", "html", "json"],
+        default="markdown",
+        help="Output format (default: markdown)",
+    )
+    parser.add_argument(
+        "--output",
+        default=None,
+        help="File to write (default: stdout)",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``digest`` subcommand."""
+    try:
+        digest = collect_digest(args.root, parse_period(args.since), top=args.top)
+    except (DigestError, GitError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    if args.format == "json":
+        text = json.dumps(stamp_schema(digest.to_dict()), indent=2) + "\n"
+    elif args.format == "html":
+        text = render_html(digest)
+    else:
+        text = render_markdown(digest)
+    if args.output is None:
+        sys.stdout.write(text)
+        return 0
+    try:
+        Path(args.output).write_text(text, encoding="utf-8")
+    except OSError as exc:
+        print(f"Error: Cannot write {args.output}: {exc}", file=sys.stderr)
+        return 1
+    print(f"Wrote the digest to {args.output}")
+    return 0
@@ -0,0 +1,103 @@
+"""``autodoc doc`` - print the documentation of one symbol."""
+
+import argparse
+import json
+import sys
+
+from autodoc.cli.options import (
+    add_config_argument,
+    add_timeout_argument,
+    add_walk_arguments,
+    walk_options,
+)
+from autodoc.config.project import ProjectConfigError, load_project_config
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.code_examples import find_examples
+from services.relation_graph import build_relation_graph
+from services.schema import stamp_schema
+from services.symbol_doc import (
+    build_symbol_doc,
+    find_symbols,
+    render_symbol_doc_markdown,
+    render_symbol_text,
+)
+from services.test_docs import build_test_suite_doc
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``doc`` subcommand."""
+    parser = subparsers.add_parser(
+        "doc",
+        help="Print the documentation of one symbol",
+        description=(
+            "Print the documentation of a module, class, function, or method: "
+            "its signature and docstring, then its members, subclasses, "
+            "callers, tests, and the guide examples using it. NAME is a "
+            "qualified name or its end, such as shop.cart.Cart or cart.Cart."
+        ),
+    )
+    parser.add_argument("name", metavar="NAME", help="The symbol to document")
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to document (default: current directory)",
+    )
+    add_config_argument(parser)
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--include-private",
+        action="store_true",
+        help="Also document private symbols",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["text", "markdown", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``doc`` subcommand."""
+    try:
+        config = load_project_config(args.root, args.config)
+    except ProjectConfigError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    tree = parse_tree(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    packages = build_model(tree, config, args.include_private)
+    found = find_symbols(packages, args.name)
+    if not found:
+        print(f"Error: no documented symbol {args.name}", file=sys.stderr)
+        return 1
+    if len(found) > 1:
+        print(f"Error: {args.name} is ambiguous; did you mean", file=sys.stderr)
+        for _, symbol in found:
+            print(f"  {symbol.qualified_name}", file=sys.stderr)
+        return 1
+    [(package, symbol)] = found
+    site = config.site
+    guides = config.base_dir(tree.root) / site.guides if site.guides else None
+    doc = build_symbol_doc(
+        package,
+        symbol,
+        tree.root,
+        build_relation_graph(tree.root, tree.graph, tree.symbols),
+        build_test_suite_doc(tree.root, tree.graph, tree.symbols),
+        find_examples([], guides, tree.root),
+    )
+    if args.format == "json":
+        print(json.dumps(stamp_schema(doc.to_dict()), indent=2))
+    elif args.format == "markdown":
+        print(render_symbol_doc_markdown(doc), end="")
+    else:
+        print(render_symbol_text(doc), end="")
+    return 0
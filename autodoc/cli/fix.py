@@ -0,0 +1,87 @@
+"""``autodoc fix`` - repair mechanical docstring issues in place."""
+
+import argparse
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import add_walk_arguments, walk_options
+from services.doc_fix import (
+    DEFAULT_FIX_RULES,
+    FIX_RULES,
+    TAG_SECTIONS,
+    DocFixError,
+    fix_source,
+)
+from services.doc_symbols import discover_python_files
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``fix`` subcommand."""
+    parser = subparsers.add_parser(
+        "fix",
+        help="Repair mechanical docstring issues (capitals, periods, blank lines)",
+        description=(
+            "Rewrite docstrings to start with a capital letter, end their "
+            "summary with a period, and put blank lines after the summary and "
+            "before sections and directives. Only docstring literals change; "
+            "a file whose code would change is left alone."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to fix (default: current directory)",
+    )
+    add_walk_arguments(parser)
+    parser.add_argument(
+        "--select",
+        action="append",
+        choices=FIX_RULES,
+        metavar="RULE",
+        help=(
+            f"Apply only this fix; repeatable (one of {', '.join(FIX_RULES)}; "
+            f"{TAG_SECTIONS} runs only when selected)"
+        ),
+    )
+    parser.add_argument(
+        "--diff",
+        action="store_true",
+        help="Print the changes as a unified diff and write nothing; exit 1 if any",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``fix`` subcommand."""
+    root = Path(args.root)
+    rules = args.select or DEFAULT_FIX_RULES
+    base = root if root.is_dir() else root.parent
+    fixes = 0
+    files = 0
+    failed = False
+    for path in discover_python_files(root, walk=walk_options(args)):
+        relative = path.relative_to(base).as_posix()
+        try:
+            # Bytes, so CRLF line endings survive the round trip.
+            source = path.read_bytes().decode("utf-8")
+            result = fix_source(source, relative, rules)
+        except (OSError, UnicodeDecodeError, DocFixError) as exc:
+            print(f"Error: {exc}", file=sys.stderr)
+            failed = True
+            continue
+        if not result.changed:
+            continue
+        files += 1
+        fixes += len(result.fixes)
+        if args.diff:
+            sys.stdout.write(result.diff())
+            continue
+        path.write_bytes(result.fixed.encode("utf-8"))
+        for fix in result.fixes:
+            print(fix.format())
+
+    verb = "Would fix" if args.diff else "Fixed"
+    print(f"{verb} {fixes} issue(s) in {files} file(s)")
+    if failed:
+        return 1
+    return 1 if args.diff and files else 0
@@ -0,0 +1,775 @@
+"""``autodoc generate`` - render a documentation site from source."""
+
+import argparse
+import logging
+import sys
+from dataclasses import asdict
+from datetime import datetime
+from pathlib import Path
+
+from autodoc import __version__
+from autodoc.cli.options import (
+    add_config_argument,
+    add_dry_run_argument,
+    add_signing_arguments,
+    add_timeout_argument,
+    add_walk_arguments,
+    report_plan,
+    walk_options,
+)
+from autodoc.config.project import (
+    ProjectConfig,
+    ProjectConfigError,
+    load_project_config,
+)
+from autodoc.model import build_model
+from autodoc.parser import ParsedTree, parse_tree
+from autodoc.render import FORMATS, PAGE_SUFFIXES, render_site, render_test_site
+from services.attestation import (
+    ATTESTATION_FILE,
+    Attestation,
+    AttestationError,
+    Mismatch,
+    file_hash,
+    load_attestation,
+    tree_hash,
+    verify_site,
+)
+from services.audience import AUDIENCE_NAME, for_audience
+from services.checkpoint import CHECKPOINT_DIR, Checkpoint
+from services.code_examples import check_examples, find_examples
+from services.doc_accessibility import audit_site
+from services.doc_assets import AssetError
+from services.doc_caching import apply_caching
+from services.doc_highlight import HighlightError
+from services.doc_links import NetrcCredentials
+from services.doc_site import (
+    PackageDoc,
+    SitePage,
+    SiteWriteError,
+    generation_time,
+    plan_site,
+    stamp_pages,
+    write_site,
+)
+from services.doc_symbols import DocSymbolLoader, WalkOptions, discover_python_files
+from services.doc_theme import ThemeError
+from services.doc_translations import (
+    TranslationError,
+    catalog_path,
+    load_catalog,
+    localize,
+)
+from services.entry_points import ArchitectureError
+from services.git_source import GitError, has_changes, head_commit
+from services.glossary import GlossaryError
+from services.incremental import (
+    STATE_FILE,
+    AnalysisCache,
+    affected_packages,
+    fingerprint,
+    load_state,
+    package_dependencies,
+    write_state,
+)
+from services.package_index import (
+    DEFAULT_CACHE,
+    INDEX_ENV,
+    IndexRelease,
+    PackageIndexError,
+    index_url,
+)
+from services.progress import DISCOVER, PUBLISH, Progress, json_listener
+from services.redaction import redact_packages, redact_tree
+from services.signing import (
+    SigningError,
+    find_signature,
+    sign_bytes,
+    signature_path,
+    verify_file,
+)
+from services.write_plan import WritePlan
+
+logger = logging.getLogger(__name__)
+
+
+def _format_list(value: str) -> list[str]:
+    """Parse ``--format``: one format or a comma-separated list."""
+    formats = list(dict.fromkeys(f.strip() for f in value.split(",") if f.strip()))
+    unknown = [f for f in formats if f not in FORMATS]
+    if unknown or not formats:
+        raise argparse.ArgumentTypeError(
+            f"invalid format {', '.join(unknown) or value!r} "
+            f"(choose from {', '.join(FORMATS)})",
+        )
+    return formats
+
+
+def _audience(value: str) -> str:
+    """Parse ``--audience``: one audience name, as the tags give it."""
+    if not AUDIENCE_NAME.match(value):
+        raise argparse.ArgumentTypeError(f"invalid audience {value!r}")
+    return value.lower()
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``generate`` subcommand."""
+    parser = subparsers.add_parser(
+        "generate",
+        help="Render API documentation for a source tree",
+        description=(
+            "Render Markdown, HTML, or JSON API documentation, or an LSIF index, "
+            "for a source tree, or with --tests, documentation of its test "
+            "suite. Given a release such as billing==1.2.0, document that "
+            "published version from the package index instead of a local tree."
+        ),
+    )
+    parser.add_argument(
+        "release",
+        nargs="?",
+        default=None,
+        metavar="NAME[==VERSION]",
+        help=(
+            "Release to download and document instead of --root; the newest "
+            "when unpinned"
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to document (default: current directory)",
+    )
+    release_group = parser.add_argument_group("releases")
+    release_group.add_argument(
+        "--index",
+        default=None,
+        metavar="URL",
+        help=f"Simple (PEP 503/691) package index (default: ${INDEX_ENV}, then PyPI)",
+    )
+    release_group.add_argument(
+        "--cache",
+        default=DEFAULT_CACHE,
+        metavar="DIR",
+        help=f"Where downloaded releases are unpacked (default: {DEFAULT_CACHE})",
+    )
+    add_config_argument(parser)
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--output",
+        default="site",
+        help="Directory to write the documentation into (default: site)",
+    )
+    parser.add_argument(
+        "--format",
+        type=_format_list,
+        default=["markdown"],
+        help=(
+            "Output format, or a comma-separated list rendered from one parse "
+            f"into <output>/<format>/ ({', '.join(FORMATS)}; default: markdown)"
+        ),
+    )
+    parser.add_argument(
+        "--include-private",
+        action="store_true",
+        help="Also document private symbols",
+    )
+    parser.add_argument(
+        "--internal",
+        action="store_true",
+        help="Build docs for internal readers, without applying site.redact",
+    )
+    parser.add_argument(
+        "--audience",
+        type=_audience,
+        default=None,
+        metavar="NAME",
+        help=(
+            "Leave out the symbols an @autodoc:audience tag keeps from NAME, "
+            "e.g. --audience public (default: document every symbol)"
+        ),
+    )
+    parser.add_argument(
+        "--timestamp",
+        action="store_true",
+        help=(
+            "Note the generation time on index pages (SOURCE_DATE_EPOCH if set); "
+            "output is byte-stable without it"
+        ),
+    )
+    parser.add_argument(
+        "--tests",
+        action="store_true",
+        help="Document the test suite instead of the API",
+    )
+    parser.add_argument(
+        "--incremental",
+        action="store_true",
+        help=(
+            f"Re-parse only changed files and re-render only the pages they "
+            f"affect, using <output>/{STATE_FILE} from the previous run"
+        ),
+    )
+    parser.add_argument(
+        "--resume",
+        action="store_true",
+        help=(
+            "Checkpoint progress while running, and continue from the "
+            "checkpoint of an interrupted run instead of starting over"
+        ),
+    )
+    parser.add_argument(
+        "--checkpoint-dir",
+        default=None,
+        metavar="DIR",
+        help=(
+            f"Where --resume keeps its checkpoint; keep it on storage that "
+            f"outlives the machine (default: <output>/{CHECKPOINT_DIR})"
+        ),
+    )
+    parser.add_argument(
+        "--language",
+        default=None,
+        help=(
+            "Use the docstring translations of <site.translations.dir>/"
+            "<language>.po (see 'autodoc translate')"
+        ),
+    )
+    parser.add_argument(
+        "--audit",
+        action="store_true",
+        help=(
+            "Check the HTML pages and theme for accessibility problems instead "
+            "of writing them; exit 1 if any are found"
+        ),
+    )
+    parser.add_argument(
+        "--verify",
+        action="store_true",
+        help=(
+            f"Check that the site in --output is what this checkout generates, "
+            f"using its {ATTESTATION_FILE}, instead of writing; exit 1 if not"
+        ),
+    )
+    add_signing_arguments(parser, f"{ATTESTATION_FILE} of each output directory")
+    verify_group = parser.add_argument_group("signature verification (--verify)")
+    verify_group.add_argument(
+        "--public-key",
+        default=None,
+        metavar="KEY",
+        help="Require the attestation to be signed by this minisign or cosign key",
+    )
+    verify_group.add_argument(
+        "--certificate-identity",
+        default=None,
+        metavar="IDENTITY",
+        help="Require a keyless cosign signature by this identity, e.g. a workflow",
+    )
+    verify_group.add_argument(
+        "--certificate-oidc-issuer",
+        default=None,
+        metavar="URL",
+        help="OIDC issuer of --certificate-identity",
+    )
+    add_dry_run_argument(parser)
+    parser.add_argument(
+        "--progress",
+        choices=["json"],
+        default=None,
+        help=(
+            "Print progress events for wrapping UIs to stderr, one JSON object "
+            "per line: files discovered, parsed, analyzed, pages rendered, and "
+            "files written, with counts and ETAs"
+        ),
+    )
+    parser.set_defaults(handler=run)
+
+
+def _output_dir(args: argparse.Namespace, fmt: str) -> Path:
+    # A single format keeps writing straight into --output.
+    return Path(args.output) / fmt if len(args.format) > 1 else Path(args.output)
+
+
+def _settings(
+    args: argparse.Namespace,
+    config: ProjectConfig,
+    walk: WalkOptions,
+) -> str:
+    """Fingerprint of everything besides the sources that shapes the output."""
+    return fingerprint(
+        {
+            "root": Path(args.root).resolve(),
+            "config": config.raw,
+            "formats": args.format,
+            "include_private": args.include_private,
+            "internal": args.internal,
+            "audience": args.audience,
+            "tests": args.tests,
+            "walk": asdict(walk),
+            "language": args.language,
+            "catalog": _catalog_digest(args, config),
+        },
+    )
+
+
+def _catalog_path(args: argparse.Namespace, config: ProjectConfig) -> Path:
+    directory = config.base_dir(args.root) / config.site.translations.dir
+    return catalog_path(directory, args.language)
+
+
+def _catalog_digest(args: argparse.Namespace, config: ProjectConfig) -> str | None:
+    """Fingerprint of the ``--language`` catalog, so edits re-render every page."""
+    if args.language is None:
+        return None
+    path = _catalog_path(args, config)
+    return fingerprint(path.read_text(encoding="utf-8")) if path.is_file() else None
+
+
+def _checkpoint_dir(args: argparse.Namespace) -> Path:
+    if args.checkpoint_dir is not None:
+        return Path(args.checkpoint_dir)
+    return Path(args.output) / CHECKPOINT_DIR
+
+
+def _reused_pages(
+    args: argparse.Namespace,
+    packages: list[PackageDoc],
+    only: set[str],
+    checkpoint: Checkpoint | None = None,
+) -> dict[str, list[SitePage]]:
+    """Previous package pages for the slugs not in ``only``, per format.
+
+    Pages the checkpoint holds come from there, the others from the output.
+    """
+    saved = checkpoint.pages() if checkpoint is not None else {}
+    reused: dict[str, list[SitePage]] = {}
+    for fmt in args.format:
+        suffix = PAGE_SUFFIXES.get(fmt)
+        if suffix is None:
+            continue
+        output = _output_dir(args, fmt)
+        reused[fmt] = []
+        for package in packages:
+            path = f"{package.slug}{suffix}"
+            if package.slug in only:
+                continue
+            if checkpoint is not None and path in saved.get(fmt, ()):
+                reused[fmt].extend(checkpoint.load_pages(fmt, [path]))
+            else:
+                reused[fmt].append(SitePage(path, (output / path).read_bytes()))
+    return reused
+
+
+def _resumed_only(
+    args: argparse.Namespace,
+    packages: list[PackageDoc],
+    tree: ParsedTree,
+    cache: AnalysisCache,
+    checkpoint: Checkpoint,
+    only: set[str] | None,
+) -> set[str]:
+    """``only`` (default: every package) less the pages ``checkpoint`` holds.
+
+    Saved pages of packages whose sources changed since are rendered again.
+    """
+    saved = checkpoint.pages()
+    formats = [fmt for fmt in args.format if fmt in PAGE_SUFFIXES]
+    stale: set[str] = set()
+    if cache.changed - set(cache.files):
+        # A removed file may have fed any page.
+        stale = {package.slug for package in packages}
+    elif cache.changed:
+        dependencies = package_dependencies(tree.graph, packages, tree.root)
+        stale = {
+            slug
+            for slug, sources in dependencies.items()
+            if cache.changed & set(sources)
+        }
+    done = {
+        package.slug
+        for package in packages
+        if formats
+        and package.slug not in stale
+        and all(
+            f"{package.slug}{PAGE_SUFFIXES[fmt]}" in saved.get(fmt, ())
+            for fmt in formats
+        )
+    }
+    if done:
+        logger.info(
+            "The checkpoint holds %d of %d package page(s)",
+            len(done),
+            len(packages),
+            extra={"resumed": len(done)},
+        )
+    wanted = only if only is not None else {package.slug for package in packages}
+    return wanted - done
+
+
+def _incremental_only(
+    args: argparse.Namespace,
+    packages: list[PackageDoc],
+    tree: ParsedTree,
+    cache: AnalysisCache,
+) -> tuple[set[str], dict[str, list[str]]]:
+    """Slugs to render, and the page dependencies to record for next time."""
+    dependencies = package_dependencies(tree.graph, packages, tree.root)
+    only = affected_packages(dependencies, cache.previous, cache.changed)
+    for fmt in args.format:
+        suffix = PAGE_SUFFIXES.get(fmt)
+        if suffix is not None:
+            output = _output_dir(args, fmt)
+            only |= {
+                p.slug for p in packages if not (output / f"{p.slug}{suffix}").is_file()
+            }
+    logger.info(
+        "%d source file(s) changed; rendering %d of %d package page(s)",
+        len(cache.changed),
+        len(only),
+        len(packages),
+        extra={"changed": len(cache.changed), "rendered": len(only)},
+    )
+    return only, dependencies
+
+
+def _audit(
+    args: argparse.Namespace,
+    config: ProjectConfig,
+    sites: dict[str, list[SitePage]],
+) -> int:
+    """Report the accessibility problems of the rendered HTML site."""
+    if "html" not in sites:
+        print(
+            "Error: --audit checks HTML output; add html to --format",
+            file=sys.stderr,
+        )
+        return 1
+    pages = sites["html"]
+    findings = audit_site(pages, config.site.theme, config.base_dir(args.root))
+    for finding in findings:
+        print(finding.format())
+    if findings:
+        return 1
+    count = sum(page.path.endswith(".html") for page in pages)
+    print(f"No accessibility problems in {count} page(s)")
+    return 0
+
+
+def _check_examples(
+    config: ProjectConfig,
+    tree: ParsedTree,
+    packages: list[PackageDoc],
+) -> bool:
+    """Check the code examples as ``site.examples`` says; whether all work."""
+    site = config.site
+    guides = config.base_dir(tree.root) / site.guides if site.guides else None
+    examples = find_examples(packages, guides, tree.root)
+    failures = check_examples(examples, site.examples == "run", tree.root)
+    for failure in failures:
+        print(failure.format(), file=sys.stderr)
+    if failures:
+        print(
+            f"Error: {len(failures)} of {len(examples)} code example(s) are broken",
+            file=sys.stderr,
+        )
+        return False
+    logger.info("Checked %d code example(s)", len(examples))
+    return True
+
+
+def _inputs(
+    args: argparse.Namespace,
+    config: ProjectConfig,
+    walk: WalkOptions,
+    when: datetime | None,
+) -> Attestation:
+    """What this run documents, for the attestation of each output directory."""
+    root = Path(args.root)
+    try:
+        commit: str | None = head_commit(root)
+        # Only the inputs count: an output directory inside the tree does not.
+        specs = ["*.py"]
+        if config.path and config.path.resolve().is_relative_to(root.resolve()):
+            specs.append(str(config.path.resolve()))
+        dirty = has_changes(root, *specs)
+    except GitError:
+        # Not a git checkout, such as a downloaded release.
+        commit, dirty = None, False
+    return Attestation(
+        __version__,
+        tree_hash(root, discover_python_files(root, walk=walk)),
+        file_hash(config.path),
+        commit,
+        dirty,
+        {
+            "audience": args.audience,
+            "catalog": _catalog_digest(args, config),
+            "formats": args.format,
+            "include_private": args.include_private,
+            "internal": args.internal,
+            "language": args.language,
+            "release": args.release,
+            "tests": args.tests,
+            "timestamp": when.isoformat() if when else None,
+            "walk": asdict(walk),
+        },
+    )
+
+
+def _verify(
+    args: argparse.Namespace,
+    config: ProjectConfig,
+    walk: WalkOptions,
+    sites: dict[str, list[SitePage]],
+) -> int:
+    """Compare the sites in ``--output`` with what this checkout generates."""
+    failed = False
+    for fmt, pages in sites.items():
+        output = _output_dir(args, fmt)
+        try:
+            recorded = load_attestation(output)
+            # Stamp the rebuild like the original, so the index pages match.
+            stamp = recorded.settings.get("timestamp")
+            when = datetime.fromisoformat(stamp) if stamp else None
+            inputs = _inputs(args, config, walk, when)
+        except (AttestationError, OSError, ValueError) as exc:
+            print(f"Error: {exc}", file=sys.stderr)
+            return 1
+        if when is not None:
+            pages = stamp_pages(pages, when)
+        problems = verify_site(output, recorded, inputs.with_outputs(pages))
+        signed_by, signature_problems = _check_signature(args, output)
+        problems.extend(signature_problems)
+        for problem in problems:
+            print(f"{output}: {problem.format()}")
+        if problems:
+            failed = True
+            continue
+        if recorded.commit:
+            source = f"commit {recorded.commit[:12]}"
+        else:
+            source = f"source tree {recorded.source_tree[:12]}"
+        signed = f", signed with {signed_by}" if signed_by else ""
+        print(
+            f"Verified {output}: {len(recorded.outputs)} file(s) match "
+            f"{source}{signed}",
+        )
+    return 1 if failed else 0
+
+
+def _check_signature(
+    args: argparse.Namespace,
+    output: Path,
+) -> tuple[str | None, list[Mismatch]]:
+    """The tool that signed the attestation in ``output``, if checked, and problems.
+
+    A signature is required once a key or identity to check it against is given.
+    """
+    attestation = output / ATTESTATION_FILE
+    found = find_signature(attestation)
+    expected = args.public_key or args.certificate_identity
+    if found is None:
+        return None, [Mismatch(ATTESTATION_FILE, "is not signed")] if expected else []
+    tool, signature = found
+    if not expected:
+        logger.warning(
+            "%s is signed with %s; pass --public-key or --certificate-identity "
+            "to check the signature",
+            attestation,
+            tool,
+        )
+        return None, []
+    try:
+        verify_file(
+            attestation,
+            tool,
+            signature,
+            args.public_key,
+            args.certificate_identity,
+            args.certificate_oidc_issuer,
+        )
+    except SigningError as exc:
+        return None, [Mismatch(signature.name, str(exc))]
+    return tool, []
+
+
+def _fetch_release(args: argparse.Namespace) -> Path:
+    """Download ``args.release`` and return the directory holding its config.
+
+    ``args.root`` is pointed at the import root of the release, so the rest
+    of the run documents it like a local tree.
+    """
+    release = IndexRelease(
+        index_url(args.index),
+        args.release,
+        Path(args.cache),
+        credentials=NetrcCredentials(),
+    )
+    args.root = str(release.root())
+    logger.info("Documenting %s from %s", args.release, args.root)
+    return release.project_dir or Path(args.root)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``generate`` subcommand."""
+    cache = None
+    checkpoint = None
+    dependencies: dict[str, list[str]] = {}
+    progress = None
+    if args.progress == "json":
+        progress = Progress(json_listener(sys.stderr))
+    try:
+        project_dir = args.root if args.release is None else _fetch_release(args)
+        config = load_project_config(project_dir, args.config)
+        walk = walk_options(args)
+        # Runs that write nothing do not need (or remove) a checkpoint.
+        resume = args.resume and not (args.verify or args.audit or args.dry_run)
+        if (args.incremental and not args.verify) or resume:
+            settings = _settings(args, config, walk)
+            if resume:
+                checkpoint = Checkpoint.open(_checkpoint_dir(args), settings)
+            if checkpoint is not None and checkpoint.resumed:
+                previous = checkpoint.analysis()
+            elif args.incremental:
+                previous = load_state(Path(args.output) / STATE_FILE, settings)
+            else:
+                previous = None
+            files = discover_python_files(args.root, walk=walk)
+            if progress is not None:
+                progress.complete(DISCOVER, len(files))
+            cache = AnalysisCache(args.root, files, previous, checkpoint)
+            loader = DocSymbolLoader(args.root, walk=walk)
+            tree = ParsedTree(
+                Path(args.root),
+                cache.load_symbols(loader, cancel=args.cancel, progress=progress),
+                cache.build_graph(cancel=args.cancel, progress=progress),
+            )
+        else:
+            tree = parse_tree(
+                args.root,
+                walk=walk,
+                cancel=args.cancel,
+                max_memory=args.max_memory,
+                progress=progress,
+            )
+        if checkpoint is not None and args.cancel.partial:
+            print(
+                f"Stopped before rendering; rerun with --resume to continue "
+                f"from {checkpoint.directory}",
+                file=sys.stderr,
+            )
+            return 1
+        if args.cancel.partial:
+            # Swapping in the site would prune the pages of every skipped file.
+            print(
+                f"Error: {args.output} not written from a partial parse",
+                file=sys.stderr,
+            )
+            return 1
+        if args.audience is not None:
+            tree = for_audience(tree, args.audience)
+        if not args.internal:
+            tree = redact_tree(tree, config.site.redact)
+        if args.tests:
+            sites = render_test_site(tree, args.format, config, progress)
+        else:
+            packages = build_model(tree, config, args.include_private)
+            if not args.internal:
+                packages = redact_packages(packages, config.site.redact)
+            if config.site.examples != "off" and not _check_examples(
+                config,
+                tree,
+                packages,
+            ):
+                return 1
+            if args.language is not None:
+                catalog = load_catalog(_catalog_path(args, config))
+                packages, _ = localize(packages, catalog)
+            only = None
+            if cache is not None and args.incremental:
+                only, dependencies = _incremental_only(args, packages, tree, cache)
+            if cache is not None and checkpoint is not None:
+                only = _resumed_only(args, packages, tree, cache, checkpoint, only)
+            sites = render_site(
+                tree,
+                packages,
+                args.format,
+                config,
+                only,
+                args.language,
+                on_page=checkpoint.save_page if checkpoint is not None else None,
+                progress=progress,
+            )
+            if only is not None:
+                reused = _reused_pages(args, packages, only, checkpoint)
+                for fmt, pages in reused.items():
+                    sites[fmt].extend(pages)
+    except (
+        OSError,
+        ProjectConfigError,
+        ThemeError,
+        HighlightError,
+        ArchitectureError,
+        GlossaryError,
+        AssetError,
+        TranslationError,
+        PackageIndexError,
+    ) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    if "html" in sites:
+        # After carrying over pages, so their asset references are rewritten too.
+        sites["html"] = apply_caching(sites["html"], config.site.caching)
+    if args.audit:
+        return _audit(args, config, sites)
+    if args.verify:
+        return _verify(args, config, walk, sites)
+
+    when = generation_time() if args.timestamp else None
+    try:
+        inputs = _inputs(args, config, walk, when)
+    except OSError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    outputs: list[tuple[Path, list[SitePage]]] = []
+    for fmt, pages in sites.items():
+        if when is not None:
+            pages = stamp_pages(pages, when)
+        attestation = inputs.with_outputs(pages).page()
+        pages = [*pages, attestation]
+        if args.sign and not args.dry_run:
+            try:
+                signature = sign_bytes(
+                    attestation.content,
+                    attestation.path,
+                    args.sign,
+                    args.sign_key,
+                )
+            except SigningError as exc:
+                print(f"Error: {exc}", file=sys.stderr)
+                return 1
+            path = signature_path(attestation.path, args.sign).as_posix()
+            pages.append(SitePage(path, signature))
+        outputs.append((_output_dir(args, fmt), pages))
+    if args.dry_run:
+        plan = WritePlan([])
+        for output, pages in outputs:
+            plan.writes.extend(plan_site(pages, output).writes)
+        return report_plan(plan)
+    if progress is not None:
+        progress.start(PUBLISH, sum(len(pages) for _, pages in outputs))
+    for output, pages in outputs:
+        try:
+            written = write_site(pages, output, progress)
+        except SiteWriteError as exc:
+            print(f"Error: {exc}", file=sys.stderr)
+            return 1
+        print(f"Wrote {len(written)} file(s) to {output}")
+    if progress is not None:
+        progress.finish(PUBLISH)
+    if cache is not None and args.incremental:
+        write_state(cache.state(settings, dependencies), Path(args.output) / STATE_FILE)
+    if checkpoint is not None:
+        checkpoint.remove()
+    return 0
@@ -0,0 +1,97 @@
+"""``autodoc graph`` - export the symbol relation graph."""
+
+import argparse
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import (
+    add_dry_run_argument,
+    add_timeout_argument,
+    add_walk_arguments,
+    report_plan,
+    walk_options,
+)
+from autodoc.parser import parse_tree
+from services.graph_export import (
+    GRAPH_FORMATS,
+    render_dot,
+    render_graphml,
+    render_neo4j_csv,
+)
+from services.relation_graph import RELATION_KINDS, build_relation_graph
+from services.write_plan import plan_writes
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``graph`` subcommand."""
+    parser = subparsers.add_parser(
+        "graph",
+        help="Export symbols and their relations as GraphML, DOT, or Neo4j CSV",
+        description=(
+            "Export the modules, classes, and functions of a source tree with "
+            f"their relations ({', '.join(RELATION_KINDS)}) for graph tooling."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--format",
+        choices=GRAPH_FORMATS,
+        default="graphml",
+        help="Export format (default: graphml)",
+    )
+    parser.add_argument(
+        "--output",
+        default=None,
+        help=(
+            "File to write (default: stdout); for neo4j, the directory to "
+            "write nodes.csv and relationships.csv into (required)"
+        ),
+    )
+    add_dry_run_argument(parser)
+    parser.set_defaults(handler=run, parser=parser)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``graph`` subcommand."""
+    if args.format == "neo4j" and args.output is None:
+        args.parser.error("--format neo4j needs --output DIR")
+
+    tree = parse_tree(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    graph = build_relation_graph(tree.root, tree.graph, tree.symbols)
+    if args.format == "neo4j":
+        files = {
+            Path(args.output) / name: content
+            for name, content in render_neo4j_csv(graph).items()
+        }
+    else:
+        render = render_dot if args.format == "dot" else render_graphml
+        if args.output is None:
+            sys.stdout.write(render(graph))
+            return 0
+        files = {Path(args.output): render(graph)}
+
+    if args.dry_run:
+        return report_plan(plan_writes(files.items()))
+    try:
+        for path, content in files.items():
+            path.parent.mkdir(parents=True, exist_ok=True)
+            path.write_text(content, encoding="utf-8")
+    except OSError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    print(
+        f"Wrote {len(graph.nodes)} node(s) and {len(graph.edges)} edge(s) "
+        f"to {', '.join(str(path) for path in files)}",
+    )
+    return 0
@@ -0,0 +1,63 @@
+"""``autodoc hook`` - pre-commit documentation check."""
+
+import argparse
+import json
+import sys
+
+from autodoc.cli.options import add_config_argument, load_rules
+from autodoc.config.project import ProjectConfigError
+from services.custom_lint_rules import RuleConfigError
+from services.git_source import GitError, repo_root
+from services.precommit_hook import check_staged
+from services.schema import stamp_schema
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``hook`` subcommand."""
+    parser = subparsers.add_parser(
+        "hook",
+        help="Block commits that introduce undocumented exported symbols",
+        description=(
+            "Lint the staged version of staged Python files and fail when the "
+            "commit introduces new documentation findings."
+        ),
+    )
+    parser.add_argument(
+        "--staged",
+        action="store_true",
+        help="Check files staged in the index (required)",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    add_config_argument(parser)
+    parser.set_defaults(handler=run, parser=parser)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``hook`` subcommand."""
+    if not args.staged:
+        args.parser.error("only --staged checks are supported")
+
+    try:
+        repo = repo_root()
+        result = check_staged(repo, load_rules(args, repo))
+    except (GitError, ProjectConfigError, RuleConfigError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    if args.format == "json":
+        print(json.dumps(stamp_schema(result.to_dict()), indent=2))
+    else:
+        for finding in result.findings:
+            print(finding.format())
+        if not result.passed:
+            print(
+                f"autodoc: {len(result.findings)} new documentation finding(s) "
+                "in staged files; commit blocked",
+                file=sys.stderr,
+            )
+    return 0 if result.passed else 1
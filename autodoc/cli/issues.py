@@ -0,0 +1,82 @@
+"""``autodoc issues`` - file tracker issues for documentation debt."""
+
+import argparse
+import json
+import logging
+import sys
+
+from autodoc.config.project import ProjectConfigError, load_project_config
+from autodoc.config.secrets import SecretError, redact
+from services.doc_coverage import compute_package_coverage
+from services.doc_debt_issues import (
+    DocDebtIssueFiler,
+    IssueTrackerConfigurationError,
+    IssueTrackerError,
+    get_issue_tracker,
+)
+from services.schema import stamp_schema
+
+logger = logging.getLogger(__name__)
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``issues`` subcommand."""
+    parser = subparsers.add_parser(
+        "issues",
+        help="Open or update issues for packages below a coverage threshold",
+        description=(
+            "Compute documentation coverage per package and open (or update) "
+            "one tracker issue for every package below the threshold."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    parser.add_argument(
+        "--threshold",
+        type=float,
+        default=80.0,
+        help="Minimum documentation coverage percentage (default: 80)",
+    )
+    parser.add_argument(
+        "--tracker",
+        choices=["jira", "github"],
+        default="jira",
+        help="Issue tracker to file issues in (default: jira)",
+    )
+    parser.add_argument(
+        "--dry-run",
+        action="store_true",
+        help="Report which issues would be created or updated; change none",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``issues`` subcommand."""
+    coverage = compute_package_coverage(args.root)
+
+    try:
+        config = load_project_config(args.root)
+        overrides = config.integrations.settings(
+            args.tracker,
+            config.base_dir(args.root),
+        )
+        tracker = get_issue_tracker(args.tracker, overrides)
+    except IssueTrackerConfigurationError as exc:
+        if not args.dry_run:
+            print(f"Error: {redact(str(exc))}", file=sys.stderr)
+            return 1
+        # Without a tracker, a dry run cannot tell updates from new issues.
+        logger.warning("Reporting every issue as new: %s", redact(str(exc)))
+        tracker = None
+    except (IssueTrackerError, ProjectConfigError, SecretError) as exc:
+        print(f"Error: {redact(str(exc))}", file=sys.stderr)
+        return 1
+
+    filer = DocDebtIssueFiler(tracker, threshold=args.threshold)
+    result = filer.file_issues(coverage, dry_run=args.dry_run)
+    print(json.dumps(stamp_schema(result.to_dict()), indent=2))
+    return 0 if result.success else 1
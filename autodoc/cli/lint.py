@@ -0,0 +1,78 @@
+"""``autodoc lint`` - report documentation lint findings."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import (
+    add_offline_argument,
+    add_source_arguments,
+    lint_tree,
+)
+from autodoc.config.project import ProjectConfigError
+from services.custom_lint_rules import RuleConfigError
+from services.doc_baseline import DEFAULT_BASELINE_FILE, BaselineError, load_baseline
+from services.git_source import GitError
+from services.schema import stamp_schema
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``lint`` subcommand."""
+    parser = subparsers.add_parser(
+        "lint",
+        help="Report documentation lint findings",
+        description=(
+            "Run the documentation lint rules and the configured import "
+            "and link rules over a source tree."
+        ),
+    )
+    add_source_arguments(parser)
+    add_offline_argument(parser)
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.add_argument(
+        "--baseline",
+        default=None,
+        help=(
+            "Ignore findings recorded in this baseline file "
+            f"(default: <root>/{DEFAULT_BASELINE_FILE} when present)"
+        ),
+    )
+    parser.add_argument(
+        "--no-baseline",
+        action="store_true",
+        help="Report every finding, even those recorded in the baseline",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``lint`` subcommand."""
+    baseline_path = (
+        Path(args.baseline) if args.baseline else Path(args.root) / DEFAULT_BASELINE_FILE
+    )
+    try:
+        findings = lint_tree(args)
+        baseline = (
+            load_baseline(baseline_path)
+            if not args.no_baseline and (args.baseline or baseline_path.exists())
+            else None
+        )
+    except (GitError, BaselineError, ProjectConfigError, RuleConfigError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    if baseline is not None:
+        findings = baseline.filter_new(findings)
+    if args.format == "json":
+        report = {"findings": [f.to_dict() for f in findings]}
+        print(json.dumps(stamp_schema(report), indent=2))
+    else:
+        for finding in findings:
+            print(finding.format())
+    return 1 if findings else 0
@@ -3,15 +3,89 @@
 This module provides a command-line interface for creating runs from CI/CD pipelines.
 It accepts --commit, --repo, --branch, --pr-id, and --dry-run flags and creates
 a Run entity in the database with the is_dry_run flag set appropriately.
+
+Documentation tooling is exposed as subcommands (``autodoc <command> ...``);
+each command lives in its own module under ``autodoc.cli`` and registers
+itself through :data:`COMMANDS`.
 """
 
 import argparse
+import logging
 import sys
+import time
+from collections.abc import Iterator, Sequence
 from datetime import UTC, datetime
 
-from db.models import Run
-from db.session import SessionLocal
+from autodoc.cli import (
+    analytics,
+    api,
+    baseline,
+    browse,
+    collisions,
+    compare,
+    coverage,
+    digest,
+    doc,
+    fix,
+    generate,
+    graph,
+    hook,
+    issues,
+    lint,
+    migrate,
+    portal,
+    pragmas,
+    publish,
+    relnotes,
+    rename,
+    risks,
+    serve,
+    shard,
+    syntax,
+    translate,
+    unused,
+    version,
+)
+from autodoc.cli.options import add_logging_arguments
+from autodoc.logging import cli_logging
 from autodoc.logging.correlation import generate_correlation_id
+from services.cancellation import CancelToken, handle_interrupts
+from services.telemetry import configure, shutdown, span
+
+logger = logging.getLogger(__name__)
+
+# Subcommand modules. Each exposes ``register(subparsers)``, which adds its
+# parser and sets ``handler`` to a callable returning the process exit code.
+COMMANDS = {
+    "analytics": analytics,
+    "api": api,
+    "baseline": baseline,
+    "browse": browse,
+    "collisions": collisions,
+    "compare": compare,
+    "coverage": coverage,
+    "digest": digest,
+    "doc": doc,
+    "fix": fix,
+    "generate": generate,
+    "graph": graph,
+    "hook": hook,
+    "issues": issues,
+    "lint": lint,
+    "migrate": migrate,
+    "portal": portal,
+    "pragmas": pragmas,
+    "publish": publish,
+    "relnotes": relnotes,
+    "rename": rename,
+    "risks": risks,
+    "serve": serve,
+    "shard": shard,
+    "syntax": syntax,
+    "translate": translate,
+    "unused": unused,
+    "version": version,
+}
 
 
 def create_run_from_cli(
@@ -36,6 +110,9 @@ def create_run_from_cli(
     Raises:
         SystemExit: If run creation fails
     """
+    from db.models import Run
+    from db.session import SessionLocal
+
     db = SessionLocal()
     try:
         correlation_id = generate_correlation_id()
@@ -62,8 +139,86 @@ def create_run_from_cli(
         db.close()
 
 
-def main() -> None:
+def build_command_parser() -> argparse.ArgumentParser:
+    """Build the parser for the documentation subcommands."""
+    parser = argparse.ArgumentParser(
+        prog="autodoc",
+        description="AutoDoc CLI - Documentation tooling",
+    )
+    subparsers = parser.add_subparsers(dest="command", required=True)
+    for module in COMMANDS.values():
+        module.register(subparsers)
+    for command in _leaf_parsers(parser):
+        add_logging_arguments(command)
+    return parser
+
+
+def _leaf_parsers(parser: argparse.ArgumentParser) -> Iterator[argparse.ArgumentParser]:
+    """The parsers that run a handler (``baseline write``, not ``baseline``)."""
+    actions = [
+        action
+        for action in parser._actions
+        if isinstance(action, argparse._SubParsersAction)
+    ]
+    if not actions:
+        yield parser
+    for action in actions:
+        for child in dict.fromkeys(action.choices.values()):
+            yield from _leaf_parsers(child)
+
+
+def run_command(argv: Sequence[str]) -> int:
+    """Parse and execute a documentation subcommand.
+
+    Commands that accept ``--timeout`` get a cancel token as ``args.cancel``,
+    and Ctrl-C cancels it instead of aborting. When a stage was cut short the
+    partial results are still reported, followed by a summary of what was
+    skipped, and the exit code is 124 (timeout) or 130 (interrupt).
+
+    The run is traced as the span ``autodoc <command>`` when an OpenTelemetry
+    exporter is configured (see :mod:`services.telemetry`).
+    """
+    parser = build_command_parser()
+    args = parser.parse_args(argv)
+    with cli_logging(args.verbose, args.log_format):
+        started = time.perf_counter()
+        configure()
+        try:
+            with span(f"autodoc {args.command}", command=args.command) as run:
+                code = _run_handler(args)
+                run.set(exit_code=code)
+        finally:
+            shutdown()
+        elapsed = time.perf_counter() - started
+        logger.info(
+            "%s finished in %.2fs with exit code %d",
+            args.command,
+            elapsed,
+            code,
+            extra={"command": args.command, "seconds": elapsed, "exit_code": code},
+        )
+    return code
+
+
+def _run_handler(args: argparse.Namespace) -> int:
+    if not hasattr(args, "timeout"):
+        return args.handler(args)
+
+    args.cancel = CancelToken(timeout=args.timeout)
+    with handle_interrupts(args.cancel):
+        code = args.handler(args)
+    if args.cancel.partial:
+        print(args.cancel.summary(), file=sys.stderr)
+        return args.cancel.exit_code
+    return code
+
+
+def main(argv: Sequence[str] | None = None) -> None:
     """Main CLI entrypoint."""
+    argv = list(sys.argv[1:] if argv is None else argv)
+    if argv and argv[0] in COMMANDS:
+        sys.exit(run_command(argv))
+
     parser = argparse.ArgumentParser(
         description="AutoDoc CLI - Create runs from CI/CD pipelines",
         formatter_class=argparse.RawDescriptionHelpFormatter,
@@ -72,6 +227,32 @@ Examples:
   %(prog)s --commit abc123 --repo myorg/myrepo
   %(prog)s --commit abc123 --repo myorg/myrepo --branch dev --pr-id 42
   %(prog)s --commit abc123 --repo myorg/myrepo --dry-run
+  %(prog)s issues --root . --threshold 80 --tracker jira
+  %(prog)s hook --staged
+  %(prog)s lint --changed-only --base origin/main
+  %(prog)s baseline write
+  %(prog)s fix --diff
+  %(prog)s generate --root . --format html --output site
+  %(prog)s generate --root . --format html --output site --verify
+  %(prog)s api --check
+  %(prog)s graph --format dot --output graph.dot
+  %(prog)s unused --format json
+  %(prog)s collisions --check
+  %(prog)s pragmas --check
+  %(prog)s syntax --check
+  %(prog)s digest --since 7d --format html --output digest.html
+  %(prog)s rename shop.cart.Cart Basket --diff
+  %(prog)s version --json
+  %(prog)s migrate .autodoc-baseline.json
+  %(prog)s translate update --language de
+  %(prog)s generate --language de --output site/de
+  %(prog)s generate billing==1.2.0 --format html --output site/1.2.0
+  %(prog)s publish s3://docs-bucket/api --site site --dry-run
+  %(prog)s portal repos.yaml --output portal
+  %(prog)s serve --source ../shop --source ../billing --port 8000
+  %(prog)s browse --root .
+  %(prog)s doc shop.cart.Cart
+  %(prog)s shard --total 8 --index 3
         """,
     )
 
@@ -100,7 +281,7 @@ Examples:
         help="Generate patches without updating Confluence (FR-14, UC-4)",
     )
 
-    args = parser.parse_args()
+    args = parser.parse_args(argv)
 
     run_id = create_run_from_cli(
         commit_sha=args.commit,
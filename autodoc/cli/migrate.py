@@ -0,0 +1,78 @@
+"""``autodoc migrate`` - upgrade JSON artifacts to the current schema."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import add_dry_run_argument, report_plan
+from services.schema import SCHEMA_VERSION, Migration, SchemaError, migrate
+from services.write_plan import plan_writes
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``migrate`` subcommand."""
+    parser = subparsers.add_parser(
+        "migrate",
+        help="Upgrade baselines and JSON sites to the current schema",
+        description=(
+            "Rewrite JSON artifacts written by an older AutoDoc (a baseline, "
+            f"or a JSON site's index.json) in schema version {SCHEMA_VERSION}."
+        ),
+    )
+    parser.add_argument(
+        "paths",
+        nargs="+",
+        metavar="PATH",
+        help="Artifact to migrate in place",
+    )
+    add_dry_run_argument(parser)
+    parser.set_defaults(handler=run)
+
+
+def _migrate_file(path: Path) -> Migration:
+    try:
+        data = json.loads(path.read_text(encoding="utf-8"))
+    except (OSError, ValueError) as exc:
+        raise SchemaError(f"Cannot read {path}: {exc}") from exc
+    try:
+        return migrate(data)
+    except SchemaError as exc:
+        raise SchemaError(f"{path}: {exc}") from exc
+
+
+def _render(data: dict) -> str:
+    # Every artifact is written as sorted, indented JSON.
+    return json.dumps(data, indent=2, sort_keys=True) + "\n"
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``migrate`` subcommand."""
+    migrations = []
+    failed = False
+    for value in args.paths:
+        path = Path(value)
+        try:
+            migrations.append((path, _migrate_file(path)))
+        except SchemaError as exc:
+            print(f"Error: {exc}", file=sys.stderr)
+            failed = True
+
+    if args.dry_run:
+        report_plan(
+            plan_writes(
+                (path, _render(migration.data)) for path, migration in migrations
+            ),
+        )
+        return 1 if failed else 0
+
+    for path, migration in migrations:
+        if not migration.changed:
+            print(f"{path}: {migration.kind} is up to date (schema {SCHEMA_VERSION})")
+            continue
+        path.write_text(_render(migration.data), encoding="utf-8")
+        print(
+            f"{path}: migrated {migration.kind} from schema "
+            f"{migration.from_version} to {migration.to_version}",
+        )
+    return 1 if failed else 0
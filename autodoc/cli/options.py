@@ -0,0 +1,332 @@
+"""Argument groups and helpers shared by the documentation subcommands."""
+
+import argparse
+import os
+from collections.abc import Sequence
+from pathlib import Path
+
+from autodoc.config.project import ProjectConfig, load_project_config
+from services.changed_scope import load_changed_scope
+from services.custom_lint_rules import rules_from_config
+from services.doc_links import (
+    REFERENCE_RULE_ID,
+    URL_RULE_ID,
+    LinkCache,
+    NetrcCredentials,
+    SymbolIndex,
+    UrlChecker,
+    check_links,
+    private_patterns,
+)
+from services.doc_lint import LintFinding, LintRule, lint_symbols
+from services.doc_symbols import (
+    DEFAULT_MAX_FILE_SIZE,
+    DocSymbol,
+    WalkOptions,
+    format_size,
+    load_doc_symbols,
+    root_relative,
+)
+from services.git_source import repo_root
+from services.import_graph import ImportGraph, build_import_graph
+from services.import_rules import check_imports, import_rules_from_config
+from services.signing import KEY_ENV, SIGNING_TOOLS
+from services.write_plan import WritePlan
+
+
+def add_logging_arguments(parser: argparse.ArgumentParser) -> None:
+    """Add ``-v/--verbose`` and ``--log-format`` to ``parser``."""
+    parser.add_argument(
+        "-v",
+        "--verbose",
+        action="count",
+        default=0,
+        help="Log progress and timings to stderr; -vv adds per-file detail",
+    )
+    parser.add_argument(
+        "--log-format",
+        choices=["text", "json"],
+        default="text",
+        help="Format of log records on stderr (default: text)",
+    )
+
+
+def add_dry_run_argument(parser: argparse.ArgumentParser) -> None:
+    """Add ``--dry-run`` to a command that writes files."""
+    parser.add_argument(
+        "--dry-run",
+        action="store_true",
+        help="Report the files that would be created or modified; write nothing",
+    )
+
+
+def report_plan(plan: WritePlan) -> int:
+    """Print a ``--dry-run`` plan and return the exit code."""
+    for line in plan.lines():
+        print(line)
+    print(plan.summary())
+    return 0
+
+
+def add_config_argument(parser: argparse.ArgumentParser) -> None:
+    """Add ``--config`` to ``parser``."""
+    parser.add_argument(
+        "--config",
+        default=None,
+        help="Project config file (default: autodoc.yaml in --root when present)",
+    )
+
+
+_SIZE_UNITS = {"": 1, "K": 1024, "M": 1024**2, "G": 1024**3}
+
+
+def file_size(value: str) -> int | None:
+    """Parse a ``--max-file-size`` value such as ``512K`` or ``2M``; 0 is no limit."""
+    text = value.strip().upper().removesuffix("B").removesuffix("I")
+    unit = text[-1:] if text[-1:] in _SIZE_UNITS else ""
+    try:
+        number = float(text[: len(text) - len(unit)])
+    except ValueError:
+        raise argparse.ArgumentTypeError(
+            f"invalid size {value!r} (expected e.g. 512K, 2M, or 0)",
+        ) from None
+    if number < 0:
+        raise argparse.ArgumentTypeError(f"size must not be negative: {value!r}")
+    size = int(number * _SIZE_UNITS[unit])
+    return size or None
+
+
+def add_walk_arguments(parser: argparse.ArgumentParser) -> None:
+    """Add the symlink, nested-project, file-size, and memory flags to ``parser``."""
+    parser.add_argument(
+        "--follow-symlinks",
+        action="store_true",
+        help="Descend into symlinked directories and parse symlinked files",
+    )
+    parser.add_argument(
+        "--nested-projects",
+        action="store_true",
+        help=(
+            "Descend into subdirectories that are projects of their own "
+            "(with a pyproject.toml, setup.py, or setup.cfg)"
+        ),
+    )
+    parser.add_argument(
+        "--max-file-size",
+        type=file_size,
+        default=DEFAULT_MAX_FILE_SIZE,
+        metavar="SIZE",
+        help=(
+            "Skip larger files, e.g. 512K or 4M; 0 disables the limit "
+            f"(default: {format_size(DEFAULT_MAX_FILE_SIZE)})"
+        ),
+    )
+    parser.add_argument(
+        "--max-memory",
+        type=file_size,
+        default=None,
+        metavar="SIZE",
+        help=(
+            "Spill parsed symbols to a temporary file past this much memory, "
+            "e.g. 512M; slower, for huge trees (default: no limit)"
+        ),
+    )
+
+
+def walk_options(args: argparse.Namespace) -> WalkOptions:
+    """The :class:`~services.doc_symbols.WalkOptions` selected by ``args``."""
+    return WalkOptions(
+        follow_symlinks=args.follow_symlinks,
+        nested_projects=args.nested_projects,
+        max_file_size=args.max_file_size,
+    )
+
+
+def add_timeout_argument(parser: argparse.ArgumentParser) -> None:
+    """Add ``--timeout`` to ``parser``.
+
+    Commands with this flag also stop cleanly on Ctrl-C: see
+    :func:`autodoc.cli.main.run_command`.
+    """
+    parser.add_argument(
+        "--timeout",
+        type=float,
+        default=None,
+        metavar="SECONDS",
+        help="Stop parsing after this long and report partial results",
+    )
+
+
+def add_source_arguments(parser: argparse.ArgumentParser) -> None:
+    """Add ``--root``, ``--config``, the walk, timeout and diff-scoping flags."""
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    add_config_argument(parser)
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--changed-only",
+        action="store_true",
+        help="Only enforce on symbols touched since the merge base with --base",
+    )
+    parser.add_argument(
+        "--base",
+        default="origin/main",
+        help="Base revision for --changed-only (default: origin/main)",
+    )
+
+
+def load_symbols(args: argparse.Namespace) -> Sequence[DocSymbol]:
+    """Load symbols below ``args.root``, narrowed to the diff if requested.
+
+    File paths are relative to ``args.root`` (see
+    :func:`~services.doc_symbols.root_relative`).
+
+    Raises:
+        services.git_source.GitError: If ``--changed-only`` is set and the
+            repository or base revision cannot be read
+    """
+    symbols = load_doc_symbols(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    if args.changed_only:
+        scope = load_changed_scope(repo_root(Path(args.root)), args.base)
+        symbols = scope.filter(symbols)
+    return root_relative(symbols, args.root)
+
+
+def load_rules(args: argparse.Namespace, root: str | Path | None = None) -> list[LintRule]:
+    """Return the lint rules configured for ``root`` (default: ``args.root``).
+
+    ``--config`` takes precedence over the ``autodoc.yaml`` found in the root.
+
+    Raises:
+        autodoc.config.project.ProjectConfigError: If the config file is invalid
+        services.custom_lint_rules.RuleConfigError: If a custom rule is invalid
+    """
+    config = load_project_config(root or args.root, args.config)
+    return rules_from_config(config.lint, config.base_dir(root or args.root))
+
+
+def add_offline_argument(parser: argparse.ArgumentParser) -> None:
+    """Add ``--offline`` to a command that checks docstring links."""
+    parser.add_argument(
+        "--offline",
+        action="store_true",
+        help="Do not fetch URLs; report only dead links already in the link cache",
+    )
+
+
+def add_signing_arguments(parser: argparse.ArgumentParser, artifact: str) -> None:
+    """Add ``--sign`` and ``--sign-key`` to a command that writes ``artifact``."""
+    group = parser.add_argument_group("signing")
+    group.add_argument(
+        "--sign",
+        choices=SIGNING_TOOLS,
+        default=None,
+        metavar="TOOL",
+        help=f"Sign the {artifact} with {' or '.join(SIGNING_TOOLS)}",
+    )
+    group.add_argument(
+        "--sign-key",
+        default=os.environ.get(KEY_ENV),
+        metavar="KEY",
+        help=(
+            f"Secret key file or cosign KMS URI (default: ${KEY_ENV}; cosign "
+            "signs keylessly without one)"
+        ),
+    )
+
+
+def _link_findings(
+    args: argparse.Namespace,
+    config: ProjectConfig,
+    symbols: Sequence[DocSymbol],
+    checked: Sequence[DocSymbol],
+    graph: ImportGraph | None,
+) -> list[LintFinding]:
+    """Dead references and URLs in the docstrings of ``checked``."""
+    links = config.lint.links
+    disabled = set(config.lint.disable)
+    index = (
+        SymbolIndex(symbols, graph)
+        if links.references and REFERENCE_RULE_ID not in disabled
+        else None
+    )
+    checker = None
+    if links.urls and URL_RULE_ID not in disabled:
+        cache_dir = config.base_dir(args.root)
+        checker = UrlChecker(
+            timeout=links.timeout,
+            cache=LinkCache.load(cache_dir / links.cache) if links.cache else None,
+            max_age=links.cache_hours * 3600,
+            offline=args.offline,
+            private=private_patterns(links.private),
+            credentials=NetrcCredentials(
+                cache_dir / Path(links.netrc).expanduser() if links.netrc else None,
+            ),
+        )
+    try:
+        return check_links(checked, index, checker, links.ignore)
+    finally:
+        if checker is not None:
+            checker.close()
+
+
+def lint_tree(args: argparse.Namespace) -> list[LintFinding]:
+    """Lint the symbols and check the import and link rules below ``args.root``.
+
+    With ``--changed-only``, symbol findings are kept for changed symbols and
+    import findings only on changed lines; references still resolve against
+    the whole tree. The import graph is only built when the config defines
+    import rules or checks links.
+
+    Returns:
+        Findings sorted by file, line, and rule
+
+    Raises:
+        autodoc.config.project.ProjectConfigError: If the config file is invalid
+        services.custom_lint_rules.RuleConfigError: If a configured rule is invalid
+        services.git_source.GitError: If ``--changed-only`` is set and the
+            repository or base revision cannot be read
+    """
+    config = load_project_config(args.root, args.config)
+    import_rules = import_rules_from_config(config.lint)
+    rules = rules_from_config(config.lint, config.base_dir(args.root))
+    symbols = load_doc_symbols(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    scope = (
+        load_changed_scope(repo_root(Path(args.root)), args.base)
+        if args.changed_only
+        else None
+    )
+    checked = root_relative(scope.filter(symbols) if scope else symbols, args.root)
+    findings = lint_symbols(checked, rules)
+    if not import_rules and not config.lint.links.enabled:
+        return findings
+
+    graph = build_import_graph(args.root, walk=walk_options(args), cancel=args.cancel)
+    violations = check_imports(graph, import_rules, args.root)
+    if scope is not None:
+        root = Path(args.root)
+        violations = [
+            finding
+            for finding in violations
+            if scope.touches_line(str(root / finding.file_path), finding.lineno)
+        ]
+    if config.lint.links.enabled:
+        findings += _link_findings(args, config, symbols, checked, graph)
+    return sorted(
+        [*findings, *violations],
+        key=lambda f: (f.file_path, f.lineno, f.rule, f.symbol),
+    )
@@ -0,0 +1,63 @@
+"""``autodoc portal`` - document many repositories behind one searchable index."""
+
+import argparse
+import sys
+
+from autodoc.cli.options import add_dry_run_argument, report_plan
+from services.doc_portal import (
+    DEFAULT_CACHE,
+    DEFAULT_MANIFEST,
+    PortalError,
+    build_portal,
+    load_manifest,
+)
+from services.doc_site import SiteWriteError, plan_site, write_site
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``portal`` subcommand."""
+    parser = subparsers.add_parser(
+        "portal",
+        help="Build one documentation portal for the repositories of a manifest",
+        description=(
+            "Document every repository listed in a manifest, local paths or "
+            "git URLs, as HTML below one output directory, with a global "
+            "index and a search across all of them."
+        ),
+    )
+    parser.add_argument(
+        "manifest",
+        nargs="?",
+        default=DEFAULT_MANIFEST,
+        help=f"Manifest of repositories (default: {DEFAULT_MANIFEST})",
+    )
+    parser.add_argument(
+        "--output",
+        default="portal",
+        help="Directory to write the portal to (default: portal)",
+    )
+    parser.add_argument(
+        "--cache",
+        default=DEFAULT_CACHE,
+        metavar="DIR",
+        help=f"Where git URLs are cloned (default: {DEFAULT_CACHE})",
+    )
+    add_dry_run_argument(parser)
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``portal`` subcommand."""
+    try:
+        manifest = load_manifest(args.manifest)
+        pages, sites = build_portal(manifest, args.cache)
+        if args.dry_run:
+            return report_plan(plan_site(pages, args.output))
+        written = write_site(pages, args.output)
+    except (PortalError, SiteWriteError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    print(
+        f"Wrote {len(written)} file(s) for {len(sites)} repositories to {args.output}",
+    )
+    return 0
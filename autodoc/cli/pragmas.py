@@ -0,0 +1,80 @@
+"""``autodoc pragmas`` - report linter and type checker pragmas on symbols."""
+
+import argparse
+import json
+
+from autodoc.cli.options import add_timeout_argument, add_walk_arguments, walk_options
+from autodoc.parser import parse_tree
+from services.pragmas import Pragma, find_pragmas
+from services.schema import stamp_schema
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``pragmas`` subcommand."""
+    parser = subparsers.add_parser(
+        "pragmas",
+        help="Report tool pragmas and the private access they hide",
+        description=(
+            "List the noqa, type: ignore, pylint, coverage, and formatter "
+            "pragmas of every class, function, and module. Pragmas silencing "
+            "warnings about private names of other modules are listed first "
+            "as maintenance risks: the code they cover breaks when those "
+            "internals change."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.add_argument(
+        "--check",
+        action="store_true",
+        help="Exit with status 1 when a pragma hides private access",
+    )
+    parser.set_defaults(handler=run)
+
+
+def _line(pragma: Pragma) -> str:
+    return f"{pragma.file_path}:{pragma.lineno}: {pragma.symbol} - {pragma.text}"
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``pragmas`` subcommand."""
+    tree = parse_tree(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    pragmas = find_pragmas(tree.root, tree.graph)
+    risks = [pragma for pragma in pragmas if pragma.private_access]
+    if args.format == "json":
+        data = {
+            "pragmas": [pragma.to_dict() for pragma in pragmas],
+            "private_access": len(risks),
+        }
+        print(json.dumps(stamp_schema(data), indent=2))
+        return 1 if args.check and risks else 0
+
+    if risks:
+        print("Maintenance risks (private access):")
+        for pragma in risks:
+            print(f"  {_line(pragma)}")
+        print()
+    for pragma in pragmas:
+        print(_line(pragma))
+    symbols = len({pragma.symbol for pragma in pragmas})
+    print(
+        f"{len(pragmas)} pragma(s) on {symbols} symbol(s), "
+        f"{len(risks)} hiding private access",
+    )
+    return 1 if args.check and risks else 0
@@ -0,0 +1,141 @@
+"""``autodoc publish`` - upload a generated site to a bucket or a git branch."""
+
+import argparse
+import sys
+
+from services.bucket_publisher import PublishError, open_store, sync_site
+from services.pages_publisher import (
+    DEFAULT_MESSAGE,
+    GIT_SCHEME,
+    PagesTarget,
+    publish_to_branch,
+    push_branch,
+)
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``publish`` subcommand."""
+    parser = subparsers.add_parser(
+        "publish",
+        help="Upload a generated site to a bucket or a GitHub Pages branch",
+        description=(
+            "Make a bucket prefix or a git branch mirror the output of "
+            "'autodoc generate': changed files are uploaded, and files that "
+            "are no longer part of the site are deleted."
+        ),
+    )
+    parser.add_argument(
+        "url",
+        help=(
+            "Destination: s3://bucket/prefix, gs://bucket/prefix, or "
+            "git:<branch>[:<folder>] such as git:gh-pages"
+        ),
+    )
+    parser.add_argument(
+        "--site",
+        default="site",
+        help="Directory written by 'autodoc generate' (default: site)",
+    )
+    parser.add_argument(
+        "--no-delete",
+        dest="delete",
+        action="store_false",
+        help="Keep objects that are no longer part of the site",
+    )
+    parser.add_argument(
+        "--force",
+        action="store_true",
+        help="Upload unchanged files to a bucket too, e.g. after changing site.caching",
+    )
+    git_group = parser.add_argument_group("git branches")
+    git_group.add_argument(
+        "--repo",
+        default=".",
+        help="Repository holding the branch (default: current directory)",
+    )
+    git_group.add_argument(
+        "--message",
+        default=DEFAULT_MESSAGE,
+        help=(
+            "Commit message; {commit} is the short hash of HEAD and {branch} "
+            f"the target branch (default: {DEFAULT_MESSAGE!r})"
+        ),
+    )
+    git_group.add_argument(
+        "--single-commit",
+        action="store_true",
+        help="Replace the branch's history by the new commit",
+    )
+    git_group.add_argument(
+        "--push",
+        default=None,
+        metavar="REMOTE",
+        help="Push the branch to REMOTE after committing",
+    )
+    parser.add_argument(
+        "--dry-run",
+        action="store_true",
+        help="Report the objects that would be uploaded or deleted; change nothing",
+    )
+    parser.add_argument(
+        "--timeout",
+        type=float,
+        default=None,
+        metavar="SECONDS",
+        help="Stop uploading after this long; objects not yet deleted are kept",
+    )
+    parser.set_defaults(handler=run_publish)
+
+
+def run_publish(args: argparse.Namespace) -> int:
+    """Execute the ``publish`` subcommand."""
+    if args.url.startswith(f"{GIT_SCHEME}:"):
+        return _publish_branch(args)
+    try:
+        store = open_store(args.url)
+        plan = sync_site(
+            args.site,
+            store,
+            delete=args.delete,
+            force=args.force,
+            dry_run=args.dry_run,
+            cancel=args.cancel,
+        )
+    except PublishError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    if args.cancel.partial:
+        # run_command reports what was skipped and sets the exit code.
+        return 1
+    if args.dry_run:
+        for line in plan.lines(store.url):
+            print(line)
+    print(plan.summary(args.dry_run))
+    return 0
+
+
+def _publish_branch(args: argparse.Namespace) -> int:
+    try:
+        target = PagesTarget.parse(args.url)
+        result = publish_to_branch(
+            args.site,
+            target,
+            repo=args.repo,
+            message=args.message,
+            delete=args.delete,
+            single_commit=args.single_commit,
+            dry_run=args.dry_run,
+            cancel=args.cancel,
+        )
+        if args.push and not args.dry_run and not args.cancel.partial:
+            push_branch(target, args.push, args.repo, force=args.single_commit)
+    except PublishError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    if args.cancel.partial:
+        return 1
+    if args.dry_run:
+        for line in result.plan.lines(target.url):
+            print(line)
+    print(result.summary(args.dry_run))
+    return 0
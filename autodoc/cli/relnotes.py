@@ -0,0 +1,96 @@
+"""``autodoc relnotes`` - compile release notes from docstring fragments."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from services.doc_digest import symbols_at
+from services.git_source import GitError, repo_root, tags
+from services.release_notes import (
+    DEFAULT_TITLE,
+    collect_release_notes,
+    new_release_notes,
+    render_release_notes,
+)
+from services.schema import stamp_schema
+from services.semver import latest_tag
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``relnotes`` subcommand."""
+    parser = subparsers.add_parser(
+        "relnotes",
+        help="Compile release notes from @autodoc:relnote docstring fragments",
+        description=(
+            "Collect the @autodoc:relnote fragments added to docstrings since "
+            "the last release and compile them into release notes grouped by "
+            "kind, each naming the symbol it describes. Both revisions are "
+            "read from git."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to collect from (default: current directory)",
+    )
+    parser.add_argument(
+        "--since",
+        default=None,
+        help="Released revision to compare against (default: highest semver tag)",
+    )
+    parser.add_argument(
+        "--until",
+        default="HEAD",
+        help="Revision being released (default: HEAD)",
+    )
+    parser.add_argument(
+        "--title",
+        default=None,
+        help=f"Heading of the notes (default: '{DEFAULT_TITLE}', or the --until tag)",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["markdown", "json"],
+        default="markdown",
+        help="Output format (default: markdown)",
+    )
+    parser.add_argument(
+        "--output",
+        default=None,
+        help="File to write (default: stdout)",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``relnotes`` subcommand."""
+    try:
+        since = args.since or latest_tag(tags(repo_root(args.root)))
+        before = collect_release_notes(symbols_at(args.root, since)) if since else []
+        after = collect_release_notes(symbols_at(args.root, args.until))
+    except GitError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    notes = new_release_notes(before, after)
+
+    if args.format == "json":
+        report = {
+            "since": since,
+            "until": args.until,
+            "notes": [note.to_dict() for note in notes],
+        }
+        text = json.dumps(stamp_schema(report), indent=2) + "\n"
+    else:
+        default = args.until if args.until != "HEAD" else DEFAULT_TITLE
+        text = render_release_notes(notes, args.title or default)
+    if args.output is None:
+        sys.stdout.write(text)
+        return 0
+    try:
+        Path(args.output).write_text(text, encoding="utf-8")
+    except OSError as exc:
+        print(f"Error: Cannot write {args.output}: {exc}", file=sys.stderr)
+        return 1
+    print(f"Wrote {len(notes)} release note(s) to {args.output}")
+    return 0
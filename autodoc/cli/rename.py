@@ -0,0 +1,94 @@
+"""``autodoc rename`` - report and rewrite the docs a symbol rename breaks."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import add_walk_arguments, walk_options
+from autodoc.parser import parse_tree
+from services.doc_links import SymbolIndex
+from services.doc_symbols import discover_python_files
+from services.rename_impact import RenameError, discover_documents, find_rename_impact
+from services.schema import stamp_schema
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``rename`` subcommand."""
+    parser = subparsers.add_parser(
+        "rename",
+        help="Report the docstrings and README code a symbol rename would break",
+        description=(
+            "List every cross-reference, docstring example, and Markdown or "
+            "reST code block that names OLD and would break once it is "
+            "renamed to NEW, and optionally rewrite them. Code is not "
+            "changed: rename the symbol itself with your editor."
+        ),
+    )
+    parser.add_argument("old", help="Qualified name of the symbol (shop.cart.Cart)")
+    parser.add_argument("new", help="Its new name, bare or with the same parent")
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to search (default: current directory)",
+    )
+    add_walk_arguments(parser)
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    mode = parser.add_mutually_exclusive_group()
+    mode.add_argument(
+        "--write",
+        action="store_true",
+        help="Rewrite the references in docstrings and documents",
+    )
+    mode.add_argument(
+        "--diff",
+        action="store_true",
+        help="Print the rewrites as a unified diff and write nothing",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``rename`` subcommand."""
+    walk = walk_options(args)
+    root = Path(args.root)
+    tree = parse_tree(root, walk=walk, max_memory=args.max_memory)
+    try:
+        report = find_rename_impact(
+            root,
+            args.old,
+            args.new,
+            SymbolIndex(tree.symbols, tree.graph),
+            discover_python_files(root, walk=walk),
+            discover_documents(root),
+        )
+    except RenameError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    for error in report.errors:
+        print(f"Error: {error}", file=sys.stderr)
+
+    base = root if root.is_dir() else root.parent
+    if args.write:
+        for renamed in report.files:
+            (base / renamed.path).write_bytes(renamed.renamed.encode("utf-8"))
+
+    if args.format == "json":
+        print(json.dumps(stamp_schema(report.to_dict()), indent=2))
+    elif args.diff:
+        for renamed in report.files:
+            sys.stdout.write(renamed.diff())
+    else:
+        for impact in report.impacts:
+            print(impact.format())
+        verb = "Rewrote" if args.write else "Found"
+        print(
+            f"{verb} {len(report.impacts)} reference(s) to {report.old} in "
+            f"{len(report.files)} file(s)",
+        )
+    return 1 if report.errors else 0
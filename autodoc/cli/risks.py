@@ -0,0 +1,69 @@
+"""``autodoc risks`` - report native code, unsafe calls, and reflection."""
+
+import argparse
+import json
+
+from autodoc.cli.options import add_timeout_argument, add_walk_arguments, walk_options
+from autodoc.parser import parse_tree
+from services.schema import stamp_schema
+from services.unsafe_usage import RISK_KINDS, RiskUsage, find_risks, package_risks
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``risks`` subcommand."""
+    parser = subparsers.add_parser(
+        "risks",
+        help="Report native code, unsafe calls, and reflection for security review",
+        description=(
+            "List the imports of ctypes and cffi, compiled extension modules, "
+            "calls that run dynamic code or touch raw memory (eval, "
+            "pickle.loads, ctypes.cast), and reflection with computed names, "
+            "per package."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.set_defaults(handler=run)
+
+
+def _line(usage: RiskUsage) -> str:
+    where = f"{usage.file_path}:{usage.lineno}" if usage.lineno else usage.file_path
+    sentence = usage.sentence(str)
+    if usage.symbol is not None:
+        return f"{where}: {usage.kind} - {usage.symbol} {sentence}"
+    return f"{where}: {usage.kind} - {sentence}"
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``risks`` subcommand."""
+    tree = parse_tree(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    packages = package_risks(find_risks(tree.root, tree.graph))
+    if args.format == "json":
+        data = {"packages": [package.to_dict() for package in packages]}
+        print(json.dumps(stamp_schema(data), indent=2))
+        return 0
+
+    for package in packages:
+        counts = ", ".join(f"{package.count(kind)} {kind}" for kind in RISK_KINDS)
+        print(f"{package.package}: {counts}")
+        for usage in package.usages:
+            print(f"  {_line(usage)}")
+    total = sum(len(package.usages) for package in packages)
+    print(f"{total} usage(s) in {len(packages)} package(s)")
+    return 0
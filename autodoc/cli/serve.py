@@ -0,0 +1,129 @@
+"""``autodoc serve`` - browse the docs of several packages by import path."""
+
+import argparse
+import sys
+from pathlib import Path
+
+from services.doc_analytics import ViewLog
+from services.doc_links import NetrcCredentials
+from services.doc_server import CheckoutSource, DocServer, ServeError, serve
+from services.package_index import (
+    DEFAULT_CACHE,
+    INDEX_ENV,
+    IndexRelease,
+    PackageIndexError,
+    index_url,
+)
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``serve`` subcommand."""
+    parser = subparsers.add_parser(
+        "serve",
+        help="Serve the docs of private packages, rendered on demand",
+        description=(
+            "Serve HTML docs for a set of packages, from source checkouts or "
+            "releases on a package index. Open /<import path>, such as "
+            "/shop/cart, to read a module; pages are rendered on the first "
+            "request and again after the source changes."
+        ),
+    )
+    parser.add_argument(
+        "--source",
+        action="append",
+        default=[],
+        metavar="DIR",
+        help="Directory of packages to document (repeatable)",
+    )
+    parser.add_argument(
+        "--index",
+        default=None,
+        metavar="URL",
+        help=(
+            "Simple (PEP 503/691) index to download --package releases from "
+            f"(default: ${INDEX_ENV}, then PyPI)"
+        ),
+    )
+    parser.add_argument(
+        "--package",
+        action="append",
+        default=[],
+        metavar="NAME[==VERSION]",
+        help="Release to document from the index; the newest if unpinned (repeatable)",
+    )
+    parser.add_argument(
+        "--cache",
+        default=DEFAULT_CACHE,
+        metavar="DIR",
+        help=f"Where downloaded releases are unpacked (default: {DEFAULT_CACHE})",
+    )
+    parser.add_argument(
+        "--netrc",
+        default=None,
+        metavar="FILE",
+        help="netrc file with the index login (default: $NETRC, then ~/.netrc)",
+    )
+    parser.add_argument(
+        "--host",
+        default="127.0.0.1",
+        help="Address to listen on (default: 127.0.0.1)",
+    )
+    parser.add_argument(
+        "--port",
+        type=int,
+        default=8000,
+        help="Port to listen on (default: 8000)",
+    )
+    parser.add_argument(
+        "--analytics",
+        default=None,
+        metavar="FILE",
+        help=(
+            "Record page views to FILE, one JSON object per line, for "
+            "'autodoc analytics'"
+        ),
+    )
+    parser.set_defaults(handler=run_serve)
+
+
+def build_server(args: argparse.Namespace) -> DocServer:
+    """The server for the sources named by ``args``."""
+    sources: list[CheckoutSource | IndexRelease] = []
+    for directory in args.source:
+        path = Path(directory).resolve()
+        if not path.is_dir():
+            raise ServeError(f"{directory} is not a directory")
+        sources.append(CheckoutSource(path, path.name))
+    credentials = NetrcCredentials(args.netrc)
+    index = index_url(args.index)
+    sources.extend(
+        IndexRelease(index, package, Path(args.cache), credentials=credentials)
+        for package in args.package
+    )
+    if not sources:
+        raise ServeError("Nothing to serve: pass --source or --package")
+    names = [source.name for source in sources]
+    duplicates = sorted({name for name in names if names.count(name) > 1})
+    if duplicates:
+        raise ServeError(f"Two sources are named {', '.join(duplicates)}")
+    analytics = ViewLog(args.analytics) if args.analytics else None
+    return DocServer(sources, analytics=analytics)
+
+
+def run_serve(args: argparse.Namespace) -> int:
+    """Execute the ``serve`` subcommand."""
+    try:
+        server = build_server(args)
+    except (ServeError, PackageIndexError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    print(f"Serving {len(server.sources)} source(s) on http://{args.host}:{args.port}/")
+    try:
+        serve(server, args.host, args.port)
+    except OSError as exc:
+        address = f"{args.host}:{args.port}"
+        print(f"Error: cannot listen on {address}: {exc}", file=sys.stderr)
+        return 1
+    except KeyboardInterrupt:
+        pass
+    return 0
@@ -0,0 +1,71 @@
+"""``autodoc shard`` - pick this CI matrix job's share of the packages."""
+
+import argparse
+import json
+import sys
+
+from autodoc.cli.options import add_walk_arguments, walk_options
+from services.doc_symbols import discover_python_files
+from services.schema import stamp_schema
+from services.sharding import ShardError, packages_of, select_shard
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``shard`` subcommand."""
+    parser = subparsers.add_parser(
+        "shard",
+        help="Print the packages one job of a CI matrix should cover",
+        description=(
+            "Split the packages below --root into --total shards balanced by "
+            "their number of Python files and print shard --index (from 0). "
+            "Every job computes the same split from the checkout, so a "
+            "matrix covers each package exactly once without a coordinator."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to split (default: current directory)",
+    )
+    parser.add_argument(
+        "--total",
+        type=int,
+        required=True,
+        help="Number of shards (matrix jobs)",
+    )
+    parser.add_argument(
+        "--index",
+        type=int,
+        required=True,
+        help="Shard to print, from 0 to --total minus 1",
+    )
+    add_walk_arguments(parser)
+    parser.add_argument(
+        "--files",
+        action="store_true",
+        help="Print the shard's Python files rather than its package names",
+    )
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text, one name or path per line)",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``shard`` subcommand."""
+    files = discover_python_files(args.root, walk=walk_options(args))
+    try:
+        shard = select_shard(packages_of(files, args.root), args.total, args.index)
+    except ShardError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    if args.format == "json":
+        print(json.dumps(stamp_schema(shard.to_dict()), indent=2))
+        return 0
+    for line in shard.files if args.files else sorted(shard.packages):
+        print(line)
+    return 0
@@ -0,0 +1,78 @@
+"""``autodoc syntax`` - report syntax newer than the minimum Python version."""
+
+import argparse
+import json
+import sys
+
+from autodoc.cli.options import (
+    add_config_argument,
+    add_timeout_argument,
+    add_walk_arguments,
+    walk_options,
+)
+from autodoc.config.project import ProjectConfigError, load_project_config
+from autodoc.parser import parse_tree
+from services.python_features import python_version_report, version_label
+from services.schema import stamp_schema
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``syntax`` subcommand."""
+    parser = subparsers.add_parser(
+        "syntax",
+        help="Report syntax newer than the minimum Python version",
+        description=(
+            "Compare the language features the code uses (assignment "
+            "expressions, match statements, except*, type statements, and "
+            "the like) with the minimum Python version of requires-python in "
+            "pyproject.toml, and list those that need a newer one."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    add_config_argument(parser)
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.add_argument(
+        "--check",
+        action="store_true",
+        help="Exit with status 1 when any syntax needs a newer Python",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``syntax`` subcommand."""
+    try:
+        config = load_project_config(args.root, args.config)
+    except ProjectConfigError as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    tree = parse_tree(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    report = python_version_report(config.base_dir(tree.root), tree.root, tree.graph)
+    newer = report.newer()
+    if args.format == "json":
+        print(json.dumps(stamp_schema(report.to_dict()), indent=2))
+    else:
+        print(report.sentence(str))
+        for usage in newer:
+            print(
+                f"{usage.file_path}:{usage.lineno}: {usage.description} "
+                f"need Python {version_label(usage.version)}",
+            )
+        print(f"{len(newer)} use(s) of newer syntax")
+    return 1 if args.check and newer else 0
@@ -0,0 +1,237 @@
+"""``autodoc translate`` - extract and merge docstring translation catalogs."""
+
+import argparse
+import json
+import sys
+from pathlib import Path
+
+from autodoc.cli.options import (
+    add_config_argument,
+    add_dry_run_argument,
+    add_timeout_argument,
+    add_walk_arguments,
+    report_plan,
+    walk_options,
+)
+from autodoc.config.project import (
+    ProjectConfig,
+    ProjectConfigError,
+    load_project_config,
+)
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_translations import (
+    TEMPLATE_FILE,
+    Catalog,
+    TranslationError,
+    catalog_path,
+    extract_messages,
+    load_catalog,
+    merge_catalog,
+    render_catalog,
+    write_catalog,
+)
+from services.schema import stamp_schema
+from services.write_plan import plan_writes
+
+
+def _add_tree_arguments(parser: argparse.ArgumentParser) -> None:
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to document (default: current directory)",
+    )
+    add_config_argument(parser)
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--include-private",
+        action="store_true",
+        help="Also translate private symbols (match 'generate --include-private')",
+    )
+
+
+def _add_language_argument(parser: argparse.ArgumentParser) -> None:
+    parser.add_argument(
+        "--language",
+        action="append",
+        default=None,
+        help=(
+            "Language catalog to work on; repeatable "
+            "(default: every language in site.translations.languages)"
+        ),
+    )
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``translate`` subcommand and its actions."""
+    parser = subparsers.add_parser(
+        "translate",
+        help="Extract docstrings for translation and merge translated catalogs",
+        description=(
+            "Manage gettext catalogs of translated docstrings, which "
+            "'autodoc generate --language' uses to build localized sites."
+        ),
+    )
+    actions = parser.add_subparsers(dest="action", required=True)
+
+    extract_parser = actions.add_parser(
+        "extract",
+        help=f"Write the {TEMPLATE_FILE} template of every documented symbol",
+    )
+    _add_tree_arguments(extract_parser)
+    extract_parser.add_argument(
+        "--output",
+        default=None,
+        help=f"Template file (default: <site.translations.dir>/{TEMPLATE_FILE})",
+    )
+    add_dry_run_argument(extract_parser)
+    extract_parser.set_defaults(handler=run_extract)
+
+    update_parser = actions.add_parser(
+        "update",
+        help="Create or update <language>.po catalogs from the current sources",
+    )
+    _add_tree_arguments(update_parser)
+    _add_language_argument(update_parser)
+    add_dry_run_argument(update_parser)
+    update_parser.set_defaults(handler=run_update)
+
+    status_parser = actions.add_parser(
+        "status",
+        help="Show how much of each catalog is translated and up to date",
+    )
+    _add_tree_arguments(status_parser)
+    _add_language_argument(status_parser)
+    status_parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    status_parser.add_argument(
+        "--check",
+        action="store_true",
+        help="Exit with status 1 unless every message is translated and current",
+    )
+    status_parser.set_defaults(handler=run_status)
+
+
+def _template(args: argparse.Namespace) -> tuple[ProjectConfig, Catalog, Path]:
+    """The config, the template of the tree, and the catalog directory."""
+    config = load_project_config(args.root, args.config)
+    tree = parse_tree(
+        args.root,
+        walk=walk_options(args),
+        cancel=args.cancel,
+        max_memory=args.max_memory,
+    )
+    packages = build_model(tree, config, args.include_private)
+    directory = config.base_dir(args.root) / config.site.translations.dir
+    return config, extract_messages(packages, tree.root), directory
+
+
+def _languages(args: argparse.Namespace, config: ProjectConfig) -> list[str]:
+    languages = args.language or config.site.translations.languages
+    if not languages:
+        raise TranslationError(
+            "No language given; pass --language or set site.translations.languages",
+        )
+    return list(dict.fromkeys(languages))
+
+
+def _merged(
+    directory: Path,
+    language: str,
+    template: Catalog,
+) -> tuple[Path, Catalog, int]:
+    path = catalog_path(directory, language)
+    existing = load_catalog(path) if path.exists() else None
+    catalog, removed = merge_catalog(template, existing, language)
+    return path, catalog, removed
+
+
+def run_extract(args: argparse.Namespace) -> int:
+    """Execute ``translate extract``."""
+    try:
+        _, template, directory = _template(args)
+    except (OSError, ProjectConfigError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    if args.cancel.partial:
+        print("Error: template not written from a partial parse", file=sys.stderr)
+        return 1
+    path = Path(args.output) if args.output else catalog_path(directory)
+    if args.dry_run:
+        return report_plan(plan_writes([(path, render_catalog(template))]))
+    write_catalog(template, path)
+    print(f"Wrote {len(template.messages)} message(s) to {path}")
+    return 0
+
+
+def run_update(args: argparse.Namespace) -> int:
+    """Execute ``translate update``."""
+    try:
+        config, template, directory = _template(args)
+        merged = [
+            _merged(directory, language, template)
+            for language in _languages(args, config)
+        ]
+    except (OSError, ProjectConfigError, TranslationError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+    if args.cancel.partial:
+        # Merging would drop the messages of every file that was not parsed.
+        print("Error: catalogs not updated from a partial parse", file=sys.stderr)
+        return 1
+
+    if args.dry_run:
+        return report_plan(
+            plan_writes((path, render_catalog(c)) for path, c, _ in merged),
+        )
+    for path, catalog, removed in merged:
+        write_catalog(catalog, path)
+        status = catalog.status()
+        print(
+            f"Wrote {path}: {status['translated']} translated, "
+            f"{status['fuzzy']} fuzzy, {status['untranslated']} untranslated, "
+            f"{removed} removed",
+        )
+    return 0
+
+
+def run_status(args: argparse.Namespace) -> int:
+    """Execute ``translate status``.
+
+    The catalogs are compared with the current sources without writing them,
+    so docstrings changed since the last ``update`` count as fuzzy.
+    """
+    try:
+        config, template, directory = _template(args)
+        reports = []
+        for language in _languages(args, config):
+            path, catalog, removed = _merged(directory, language, template)
+            reports.append(
+                {**catalog.status(), "file": str(path), "removed": removed},
+            )
+    except (OSError, ProjectConfigError, TranslationError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    incomplete = any(
+        report["fuzzy"] or report["untranslated"] or report["removed"]
+        for report in reports
+    )
+    code = 1 if args.check and incomplete else 0
+    if args.format == "json":
+        print(json.dumps(stamp_schema({"catalogs": reports}), indent=2))
+        return code
+    for report in reports:
+        total = report["total"]
+        done = report["translated"] * 100 // total if total else 100
+        print(
+            f"{report['language']}: {report['translated']}/{report['total']} "
+            f"translated ({done}%), {report['fuzzy']} fuzzy, "
+            f"{report['untranslated']} untranslated",
+        )
+    return code
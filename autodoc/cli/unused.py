@@ -0,0 +1,89 @@
+"""``autodoc unused`` - report exported symbols no other package uses."""
+
+import argparse
+import json
+import sys
+
+from autodoc.cli.options import (
+    add_config_argument,
+    add_timeout_argument,
+    add_walk_arguments,
+    walk_options,
+)
+from autodoc.config.project import ProjectConfigError, load_project_config
+from autodoc.parser import parse_tree
+from services.doc_symbols import root_relative
+from services.entry_points import ArchitectureError, detect_architecture, read_plugins
+from services.schema import stamp_schema
+from services.unused_symbols import find_unused
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``unused`` subcommand."""
+    parser = subparsers.add_parser(
+        "unused",
+        help="Report exported symbols never referenced outside their package",
+        description=(
+            "List exported classes and functions that no other package "
+            "references: candidates to make private or delete. Main packages, "
+            "plugins, registered handlers, and the patterns under 'unused' in "
+            "autodoc.yaml are left out."
+        ),
+    )
+    parser.add_argument(
+        "--root",
+        default=".",
+        help="Source tree to analyze (default: current directory)",
+    )
+    add_config_argument(parser)
+    add_walk_arguments(parser)
+    add_timeout_argument(parser)
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.add_argument(
+        "--check",
+        action="store_true",
+        help="Exit with status 1 when any unused symbol is reported",
+    )
+    parser.set_defaults(handler=run)
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``unused`` subcommand."""
+    try:
+        config = load_project_config(args.root, args.config)
+        tree = parse_tree(
+            args.root,
+            walk=walk_options(args),
+            cancel=args.cancel,
+            max_memory=args.max_memory,
+        )
+        overview = detect_architecture(tree.root, tree.graph)
+        plugins = [entry.target for entry in read_plugins(tree.root) if entry.target]
+    except (ArchitectureError, ProjectConfigError) as exc:
+        print(f"Error: {exc}", file=sys.stderr)
+        return 1
+
+    unused = find_unused(
+        tree.graph,
+        root_relative(tree.symbols, tree.root),
+        config.unused,
+        overview,
+        plugins,
+    )
+    if args.format == "json":
+        report = {"unused": [symbol.to_dict() for symbol in unused]}
+        print(json.dumps(stamp_schema(report), indent=2))
+    else:
+        for symbol in unused:
+            print(
+                f"{symbol.file_path}:{symbol.lineno}: {symbol.kind} "
+                f"{symbol.qualified_name} is not used outside {symbol.package} "
+                f"({symbol.suggestion})",
+            )
+        print(f"{len(unused)} unused exported symbol(s)")
+    return 1 if args.check and unused else 0
@@ -0,0 +1,157 @@
+"""``autodoc version`` - tool version, schema version, and features."""
+
+import argparse
+import json
+import sys
+from typing import Any
+
+from autodoc import __version__
+from services.schema import SCHEMA_VERSION
+
+# Capabilities wrapper tooling can check for with ``--require``. Names are
+# never reused: a feature that changes incompatibly gets a new name.
+FEATURES = (
+    "accessibility-audit",
+    "analytics",
+    "api-manifest",
+    "attestation",
+    "audiences",
+    "baseline",
+    "browse",
+    "build-constants",
+    "cache-headers",
+    "changed-only",
+    "code-generation",
+    "compare",
+    "ctags",
+    "custom-lint-rules",
+    "diagrams",
+    "digest",
+    "doc-command",
+    "doc-fix",
+    "docstring-sections",
+    "dry-run",
+    "embedded-assets",
+    "etags",
+    "example-check",
+    "external-links",
+    "faq",
+    "frozen-api",
+    "generate-html",
+    "generate-incremental",
+    "generate-json",
+    "generate-markdown",
+    "generate-release",
+    "generate-resume",
+    "generate-tests",
+    "github-pages",
+    "glossary",
+    "graph-export",
+    "guides",
+    "html-sanitize",
+    "ignore-file",
+    "import-rules",
+    "inherited-docs",
+    "library-api",
+    "link-check",
+    "log-format-json",
+    "lsif",
+    "max-memory",
+    "migrate",
+    "multi-repo-portal",
+    "name-collisions",
+    "none-safety",
+    "opentelemetry",
+    "package-readmes",
+    "placeholder-summaries",
+    "platform-support",
+    "pragmas",
+    "progress-events",
+    "project-directives",
+    "publish",
+    "quickstart",
+    "raises",
+    "redaction",
+    "release-notes",
+    "rename-impact",
+    "risk-report",
+    "serve",
+    "sharding",
+    "side-effects",
+    "signing",
+    "sitemap",
+    "spans",
+    "spelling",
+    "symbol-stats",
+    "syntax-report",
+    "third-party-appendix",
+    "timeout",
+    "translations",
+    "unused-report",
+    "walk-options",
+)
+
+
+def register(subparsers: argparse._SubParsersAction) -> None:
+    """Register the ``version`` subcommand."""
+    parser = subparsers.add_parser(
+        "version",
+        help="Show the tool version, schema version, and supported features",
+        description=(
+            "Show the AutoDoc version, the schema version of its JSON output, "
+            "and the features it supports, for wrapper tooling to check."
+        ),
+    )
+    parser.add_argument(
+        "--format",
+        choices=["text", "json"],
+        default="text",
+        help="Output format (default: text)",
+    )
+    parser.add_argument(
+        "--json",
+        dest="format",
+        action="store_const",
+        const="json",
+        help="Shorthand for --format json",
+    )
+    parser.add_argument(
+        "--require",
+        action="append",
+        default=[],
+        metavar="FEATURE",
+        help="Exit with status 1 unless FEATURE is supported (repeatable)",
+    )
+    parser.set_defaults(handler=run)
+
+
+def version_info() -> dict[str, Any]:
+    """The handshake printed by ``autodoc version --json``."""
+    from autodoc.cli.main import COMMANDS
+
+    return {
+        "name": "autodoc",
+        "version": __version__,
+        "schema_version": SCHEMA_VERSION,
+        "commands": sorted(COMMANDS),
+        "features": sorted(FEATURES),
+    }
+
+
+def run(args: argparse.Namespace) -> int:
+    """Execute the ``version`` subcommand."""
+    info = version_info()
+    if args.format == "json":
+        print(json.dumps(info, indent=2))
+    else:
+        print(f"autodoc {info['version']} (schema {info['schema_version']})")
+        print(f"Features: {', '.join(info['features'])}")
+
+    missing = [feature for feature in args.require if feature not in FEATURES]
+    if missing:
+        print(
+            f"Error: unsupported feature(s): {', '.join(missing)}",
+            file=sys.stderr,
+        )
+        return 1
+    return 0
@@ -0,0 +1,1228 @@
+"""Project configuration loaded from ``autodoc.yaml``.
+
+Environment settings (:mod:`autodoc.config.settings`) configure the AutoDoc
+service itself. This module covers the per-repository configuration that
+teams commit next to their code: lint rules, output options, and so on.
+Every section is optional; a missing file yields the defaults.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+import yaml
+
+from autodoc.config.secrets import is_reference, resolve_secret
+
+CONFIG_FILENAMES = ("autodoc.yaml", "autodoc.yml")
+
+THEME_MODES = ("light", "dark", "auto")
+MOCK_MODES = ("hide", "show")
+EXAMPLE_MODES = ("off", "compile", "run")
+# What ``site.redact`` does with the symbols it matches.
+REDACT_MODES = ("exclude", "mask")
+DIAGRAM_FORMATS = ("svg", "png")
+# Cache header manifests written by ``site.caching.headers``.
+HEADER_FORMATS = ("netlify", "s3")
+# Locale names accepted for translations: ``de``, ``pt_BR``, ``zh-Hant``.
+LANGUAGE_CODE = re.compile(r"^[A-Za-z]{2,3}(?:[_-][A-Za-z0-9]+)*$")
+
+# Edit URL templates per code host; ``{path}`` is repository-relative.
+EDIT_URL_TEMPLATES = {
+    "github": "{repo_url}/edit/{branch}/{path}#L{line}",
+    "gitlab": "{repo_url}/-/edit/{branch}/{path}#L{line}",
+    "bitbucket": "{repo_url}/src/{branch}/{path}?mode=edit&at={branch}#lines-{line}",
+}
+
+# Integrations configurable in the ``integrations`` section.
+INTEGRATIONS = ("confluence", "github", "jira")
+# Settings of an integration that only take a secret reference.
+SECRET_KEYS = ("token",)
+
+# Fields of the ``site.external_links`` URL templates.
+EXTERNAL_LINK_FIELDS = ("distribution", "version", "module", "name", "python")
+
+_HEX_COLOR = re.compile(r"^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$")
+_BASE_URL = re.compile(r"^https?://[^/?#\s]+(?:/[^?#\s]*)?$")
+_BEACON_PATH = re.compile(r"^/[^\s?#]*$")
+_TAG_NAME = re.compile(r"^[A-Za-z][A-Za-z0-9-]*$")
+_ATTRIBUTE_NAME = re.compile(r"^(?:[A-Za-z][A-Za-z0-9-]*\.)?[A-Za-z][\w:-]*$")
+_URL_SCHEME = re.compile(r"^[A-Za-z][A-Za-z0-9+.-]*$")
+
+
+class ProjectConfigError(Exception):
+    """Raised when ``autodoc.yaml`` cannot be read or is invalid."""
+
+
+def _optional_str(data: dict[str, Any], key: str, section: str) -> str | None:
+    value = data.get(key)
+    if value is not None and not isinstance(value, str):
+        raise ProjectConfigError(f"{section}.{key} must be a string")
+    return value
+
+
+def _words(data: dict[str, Any], key: str, section: str) -> list[str]:
+    value = data.get(key, [])
+    if isinstance(value, str):
+        value = [value]
+    if not isinstance(value, list) or not all(isinstance(v, str) for v in value):
+        raise ProjectConfigError(f"{section}.{key} must be a list of words")
+    return value
+
+
+@dataclass
+class SpellingConfig:
+    """The ``lint.spelling`` section: the spell-check and project dictionary.
+
+    The check is on once the section is present (``spelling: true`` or a
+    mapping). ``dictionary`` (relative to the config file) lists accepted
+    words, one per line; ``words`` adds more inline. ``corrections`` maps
+    further misspellings to their fixes.
+    """
+
+    enabled: bool = False
+    dictionary: str | None = None
+    words: list[str] = field(default_factory=list)
+    corrections: dict[str, str] = field(default_factory=dict)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any] | bool) -> SpellingConfig:
+        if isinstance(data, bool):
+            return cls(enabled=data)
+        corrections = data.get("corrections", {})
+        if not isinstance(corrections, dict) or not all(
+            isinstance(k, str) and isinstance(v, str) for k, v in corrections.items()
+        ):
+            raise ProjectConfigError(
+                "lint.spelling.corrections must map misspellings to words",
+            )
+        return cls(
+            enabled=True,
+            dictionary=_optional_str(data, "dictionary", "lint.spelling"),
+            words=_words(data, "words", "lint.spelling"),
+            corrections=corrections,
+        )
+
+
+@dataclass
+class TerminologyConfig:
+    """One ``lint.terminology`` entry: write ``prefer`` instead of ``avoid``."""
+
+    prefer: str
+    avoid: list[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> TerminologyConfig:
+        prefer = data.get("prefer")
+        if not isinstance(prefer, str) or not prefer.strip():
+            raise ProjectConfigError("lint.terminology entries need a prefer term")
+        avoid = _words(data, "avoid", "lint.terminology")
+        if not avoid:
+            raise ProjectConfigError(f"lint.terminology: {prefer!r} needs avoid terms")
+        return cls(prefer=prefer, avoid=avoid)
+
+
+@dataclass
+class LinksConfig:
+    """The ``lint.links`` section: dead references and URLs in docstrings.
+
+    The check is on once the section is present (``links: true`` or a
+    mapping). ``cache`` (relative to the config file) keeps URL results for
+    ``cache_hours``; ``ignore`` holds :mod:`fnmatch` patterns of URLs never
+    fetched. URLs on ``private`` hosts are fetched with the credentials of
+    ``netrc`` (default ``$NETRC`` or ``~/.netrc``) and skipped without them.
+    """
+
+    enabled: bool = False
+    references: bool = True
+    urls: bool = True
+    timeout: float = 10.0
+    cache: str | None = ".autodoc-links.json"
+    cache_hours: float = 24.0
+    ignore: list[str] = field(default_factory=list)
+    private: list[str] = field(default_factory=list)
+    netrc: str | None = None
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any] | bool) -> LinksConfig:
+        if isinstance(data, bool):
+            return cls(enabled=data)
+        flags = {}
+        for key in ("references", "urls"):
+            value = data.get(key, True)
+            if not isinstance(value, bool):
+                raise ProjectConfigError(f"lint.links.{key} must be true or false")
+            flags[key] = value
+        numbers = {}
+        for key in ("timeout", "cache_hours"):
+            value = data.get(key, getattr(cls, key))
+            if (
+                not isinstance(value, (int, float))
+                or isinstance(value, bool)
+                or value <= 0
+            ):
+                raise ProjectConfigError(
+                    f"lint.links.{key} must be a positive number",
+                )
+            numbers[key] = float(value)
+        cache = data.get("cache", cls.cache)
+        if cache is not None and cache is not False and not isinstance(cache, str):
+            raise ProjectConfigError("lint.links.cache must be a path or false")
+        return cls(
+            enabled=True,
+            cache=cache or None,
+            ignore=_words(data, "ignore", "lint.links"),
+            private=_words(data, "private", "lint.links"),
+            netrc=_optional_str(data, "netrc", "lint.links"),
+            **flags,
+            **numbers,
+        )
+
+
+@dataclass
+class LintConfig:
+    """The ``lint`` section: rule toggles, custom, import, and wording rules."""
+
+    disable: list[str] = field(default_factory=list)
+    rules: list[dict[str, Any]] = field(default_factory=list)
+    imports: list[dict[str, Any]] = field(default_factory=list)
+    spelling: SpellingConfig = field(default_factory=SpellingConfig)
+    terminology: list[TerminologyConfig] = field(default_factory=list)
+    links: LinksConfig = field(default_factory=LinksConfig)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> LintConfig:
+        disable = data.get("disable", [])
+        rules = data.get("rules", [])
+        imports = data.get("imports", [])
+        if not isinstance(disable, list) or not all(isinstance(r, str) for r in disable):
+            raise ProjectConfigError("lint.disable must be a list of rule ids")
+        if not isinstance(rules, list) or not all(isinstance(r, dict) for r in rules):
+            raise ProjectConfigError("lint.rules must be a list of mappings")
+        if not isinstance(imports, list) or not all(
+            isinstance(r, dict) for r in imports
+        ):
+            raise ProjectConfigError("lint.imports must be a list of mappings")
+        spelling = data.get("spelling", False)
+        if spelling is None:
+            spelling = True
+        if not isinstance(spelling, (dict, bool)):
+            raise ProjectConfigError("lint.spelling must be a mapping or true/false")
+        terminology = data.get("terminology", [])
+        if not isinstance(terminology, list) or not all(
+            isinstance(t, dict) for t in terminology
+        ):
+            raise ProjectConfigError("lint.terminology must be a list of mappings")
+        links = data.get("links", False)
+        if links is None:
+            links = True
+        if not isinstance(links, (dict, bool)):
+            raise ProjectConfigError("lint.links must be a mapping or true/false")
+        return cls(
+            disable=disable,
+            rules=rules,
+            imports=imports,
+            spelling=SpellingConfig.from_dict(spelling),
+            terminology=[TerminologyConfig.from_dict(t) for t in terminology],
+            links=LinksConfig.from_dict(links),
+        )
+
+
+@dataclass
+class ThemeConfig:
+    """The ``site.theme`` section: colours, logo, and custom CSS/HTML hooks.
+
+    ``logo`` and ``custom_css`` paths are relative to the config file.
+    ``extra_head``, ``header_html`` and ``footer_html`` are injected verbatim
+    into every HTML page.
+    """
+
+    mode: str = "auto"
+    primary_color: str = "#0969da"
+    accent_color: str = "#8250df"
+    logo: str | None = None
+    custom_css: list[str] = field(default_factory=list)
+    extra_head: str | None = None
+    header_html: str | None = None
+    footer_html: str | None = None
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> ThemeConfig:
+        defaults = cls()
+        mode = data.get("mode", defaults.mode)
+        if mode not in THEME_MODES:
+            raise ProjectConfigError(
+                f"site.theme.mode must be one of {', '.join(THEME_MODES)}",
+            )
+        colors = {}
+        for key in ("primary_color", "accent_color"):
+            value = data.get(key, getattr(defaults, key))
+            if not isinstance(value, str) or not _HEX_COLOR.match(value):
+                raise ProjectConfigError(
+                    f"site.theme.{key} must be a hex colour like #1f6feb",
+                )
+            colors[key] = value
+        custom_css = data.get("custom_css", [])
+        if isinstance(custom_css, str):
+            custom_css = [custom_css]
+        if not isinstance(custom_css, list) or not all(
+            isinstance(path, str) for path in custom_css
+        ):
+            raise ProjectConfigError("site.theme.custom_css must be a list of paths")
+        return cls(
+            mode=mode,
+            logo=_optional_str(data, "logo", "site.theme"),
+            custom_css=custom_css,
+            extra_head=_optional_str(data, "extra_head", "site.theme"),
+            header_html=_optional_str(data, "header_html", "site.theme"),
+            footer_html=_optional_str(data, "footer_html", "site.theme"),
+            **colors,
+        )
+
+
+@dataclass
+class EditLinkConfig:
+    """The ``site.edit_links`` section: where "edit" links point.
+
+    ``template`` overrides the built-in URL pattern for ``host`` and may use
+    ``{repo_url}``, ``{branch}``, ``{path}``, and ``{line}``.
+    """
+
+    repo_url: str
+    host: str = "github"
+    branch: str = "main"
+    template: str | None = None
+
+    @property
+    def url_template(self) -> str:
+        return self.template or EDIT_URL_TEMPLATES[self.host]
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> EditLinkConfig:
+        repo_url = _optional_str(data, "repo_url", "site.edit_links")
+        if not repo_url:
+            raise ProjectConfigError("site.edit_links.repo_url is required")
+        host = data.get("host", "github")
+        template = _optional_str(data, "template", "site.edit_links")
+        if template is None and host not in EDIT_URL_TEMPLATES:
+            raise ProjectConfigError(
+                "site.edit_links.host must be one of "
+                f"{', '.join(EDIT_URL_TEMPLATES)} (or set a template)",
+            )
+        if template is not None:
+            try:
+                template.format(repo_url="", branch="", path="", line=1)
+            except (KeyError, IndexError, ValueError) as exc:
+                raise ProjectConfigError(
+                    f"site.edit_links.template is invalid: {exc}",
+                ) from exc
+        return cls(
+            repo_url=repo_url.rstrip("/"),
+            host=host,
+            branch=_optional_str(data, "branch", "site.edit_links") or "main",
+            template=template,
+        )
+
+
+@dataclass
+class HighlightConfig:
+    """The ``site.highlight`` section: server-side syntax highlighting.
+
+    ``style`` and ``dark_style`` are Pygments style names; ``dark_style`` is
+    used by the ``dark`` theme mode and by ``auto`` when the reader prefers
+    dark.
+    """
+
+    enabled: bool = True
+    style: str = "default"
+    dark_style: str = "github-dark"
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> HighlightConfig:
+        enabled = data.get("enabled", True)
+        if not isinstance(enabled, bool):
+            raise ProjectConfigError("site.highlight.enabled must be true or false")
+        return cls(
+            enabled=enabled,
+            style=_optional_str(data, "style", "site.highlight") or cls.style,
+            dark_style=_optional_str(data, "dark_style", "site.highlight")
+            or cls.dark_style,
+        )
+
+
+@dataclass
+class GlossaryConfig:
+    """The ``site.glossary`` section: the generated glossary page.
+
+    ``file`` (relative to the config file) holds hand-written definitions
+    that are merged with the extracted terms. A term is extracted when at
+    least ``min_mentions`` symbols use it; at most ``limit`` extracted terms
+    are kept, and terms in ``exclude`` never are.
+    """
+
+    enabled: bool = True
+    file: str | None = None
+    min_mentions: int = 3
+    limit: int = 50
+    exclude: list[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any] | bool) -> GlossaryConfig:
+        if isinstance(data, bool):
+            return cls(enabled=data)
+        counts = {}
+        for key in ("min_mentions", "limit"):
+            value = data.get(key, getattr(cls, key))
+            if not isinstance(value, int) or isinstance(value, bool) or value < 1:
+                raise ProjectConfigError(
+                    f"site.glossary.{key} must be a positive integer",
+                )
+            counts[key] = value
+        exclude = data.get("exclude", [])
+        if not isinstance(exclude, list) or not all(
+            isinstance(term, str) for term in exclude
+        ):
+            raise ProjectConfigError("site.glossary.exclude must be a list of terms")
+        return cls(
+            file=_optional_str(data, "file", "site.glossary"),
+            exclude=exclude,
+            **counts,
+        )
+
+
+@dataclass
+class DiagramConfig:
+    """The ``site.diagrams`` section: images and diagrams in docstrings.
+
+    PlantUML and Mermaid sources are rendered to ``format`` when the site is
+    generated, with the ``plantuml`` and ``mermaid`` commands. With
+    ``render: false`` diagram directives are left as written; images are
+    copied either way.
+    """
+
+    render: bool = True
+    format: str = "svg"
+    plantuml: str = "plantuml"
+    mermaid: str = "mmdc"
+    # Seconds one diagram may take to render.
+    timeout: float = 60.0
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> DiagramConfig:
+        render = data.get("render", True)
+        if not isinstance(render, bool):
+            raise ProjectConfigError("site.diagrams.render must be true or false")
+        fmt = _optional_str(data, "format", "site.diagrams") or cls.format
+        if fmt not in DIAGRAM_FORMATS:
+            raise ProjectConfigError(
+                f"site.diagrams.format must be one of {', '.join(DIAGRAM_FORMATS)}",
+            )
+        timeout = data.get("timeout", cls.timeout)
+        if (
+            not isinstance(timeout, (int, float))
+            or isinstance(timeout, bool)
+            or timeout <= 0
+        ):
+            raise ProjectConfigError("site.diagrams.timeout must be a positive number")
+        return cls(
+            render=render,
+            format=fmt,
+            plantuml=_optional_str(data, "plantuml", "site.diagrams") or cls.plantuml,
+            mermaid=_optional_str(data, "mermaid", "site.diagrams") or cls.mermaid,
+            timeout=float(timeout),
+        )
+
+
+@dataclass
+class TranslationsConfig:
+    """The ``site.translations`` section: translated docstring catalogs.
+
+    ``dir`` (relative to the config file) holds the ``autodoc.pot`` template
+    and one ``<language>.po`` catalog per entry of ``languages``.
+    """
+
+    dir: str = "locale"
+    languages: list[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> TranslationsConfig:
+        languages = _words(data, "languages", "site.translations")
+        for language in languages:
+            if not LANGUAGE_CODE.match(language):
+                raise ProjectConfigError(
+                    f"site.translations.languages: {language!r} is not a "
+                    "language code (like de or pt_BR)",
+                )
+        return cls(
+            dir=_optional_str(data, "dir", "site.translations") or cls.dir,
+            languages=languages,
+        )
+
+
+@dataclass
+class SitemapConfig:
+    """The ``site.sitemap`` section: files that help search engines index a site.
+
+    Only used when ``site.base_url`` is set. HTML sites then get a
+    ``sitemap.xml`` of every page and, unless ``robots`` is false, a
+    ``robots.txt`` pointing at it. Pages matching an ``exclude`` glob are left
+    out of the sitemap and disallowed in ``robots.txt``.
+    """
+
+    enabled: bool = True
+    robots: bool = True
+    exclude: list[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any] | bool) -> SitemapConfig:
+        if isinstance(data, bool):
+            return cls(enabled=data)
+        robots = data.get("robots", True)
+        if not isinstance(robots, bool):
+            raise ProjectConfigError("site.sitemap.robots must be true or false")
+        return cls(robots=robots, exclude=_patterns(data, "exclude", "site.sitemap"))
+
+
+@dataclass
+class AnalyticsConfig:
+    """The ``site.analytics`` section: page-view analytics of hosted HTML sites.
+
+    ``snippet`` is HTML added to the ``<head>`` of every page as is, such as
+    the script tag of a hosted analytics service. ``beacon`` is an endpoint
+    of the site's own (an absolute path or http(s) URL) that each page view
+    is posted to; see :mod:`services.doc_analytics`.
+    """
+
+    snippet: str | None = None
+    beacon: str | None = None
+
+    @property
+    def enabled(self) -> bool:
+        """Whether pages carry any analytics."""
+        return bool(self.snippet or self.beacon)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> AnalyticsConfig:
+        beacon = _optional_str(data, "beacon", "site.analytics")
+        if beacon is not None and not (
+            _BEACON_PATH.match(beacon) or _BASE_URL.match(beacon)
+        ):
+            raise ProjectConfigError(
+                "site.analytics.beacon must be an absolute path like /beacon "
+                "or an http(s) URL",
+            )
+        return cls(
+            snippet=_optional_str(data, "snippet", "site.analytics"),
+            beacon=beacon,
+        )
+
+
+@dataclass
+class CachingConfig:
+    """The ``site.caching`` section: long-term caching of hosted HTML sites.
+
+    With ``fingerprint``, theme assets get a content hash in their file name,
+    so they can be cached for ``asset_max_age`` seconds; other files are
+    revalidated after ``page_max_age``. ``headers`` lists the manifests of
+    those cache headers to write, any of :data:`HEADER_FORMATS`.
+    """
+
+    fingerprint: bool = False
+    headers: list[str] = field(default_factory=list)
+    asset_max_age: int = 31536000
+    page_max_age: int = 0
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> CachingConfig:
+        fingerprint = data.get("fingerprint", False)
+        if not isinstance(fingerprint, bool):
+            raise ProjectConfigError("site.caching.fingerprint must be true or false")
+        headers = _words(data, "headers", "site.caching")
+        unknown = [name for name in headers if name not in HEADER_FORMATS]
+        if unknown:
+            raise ProjectConfigError(
+                f"site.caching.headers: unknown format {unknown[0]!r} "
+                f"(choose from {', '.join(HEADER_FORMATS)})",
+            )
+        ages = {}
+        for key in ("asset_max_age", "page_max_age"):
+            value = data.get(key, getattr(cls, key))
+            if not isinstance(value, int) or isinstance(value, bool) or value < 0:
+                raise ProjectConfigError(
+                    f"site.caching.{key} must be a non-negative number of seconds",
+                )
+            ages[key] = value
+        return cls(fingerprint=fingerprint, headers=headers, **ages)
+
+
+@dataclass
+class ExternalLinksConfig:
+    """The ``site.external_links`` section: links to the docs of external types.
+
+    Types in signatures that come from the standard library link to
+    docs.python.org for ``python`` (default: the oldest version
+    ``requires-python`` allows). Third-party types link to ``template``, by
+    default their PyPI page at the pinned version. ``packages`` overrides the
+    template per top-level module, and ``distributions`` names the
+    distribution of a module whose import name differs (``yaml: PyYAML``).
+    Templates may use the fields of :data:`EXTERNAL_LINK_FIELDS`.
+    """
+
+    enabled: bool = False
+    python: str | None = None
+    stdlib: str | None = None
+    template: str | None = None
+    packages: dict[str, str] = field(default_factory=dict)
+    distributions: dict[str, str] = field(default_factory=dict)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any] | bool) -> ExternalLinksConfig:
+        if isinstance(data, bool):
+            return cls(enabled=data)
+        python = data.get("python")
+        if isinstance(python, (int, float)) and not isinstance(python, bool):
+            # YAML reads ``python: 3.12`` as a number.
+            python = str(python)
+        if python is not None and not re.fullmatch(r"3(?:\.\d+)?", str(python)):
+            raise ProjectConfigError(
+                "site.external_links.python must be a version like 3.12",
+            )
+        mappings = {}
+        for key in ("packages", "distributions"):
+            value = data.get(key) or {}
+            if not isinstance(value, dict) or not all(
+                isinstance(k, str) and isinstance(v, str) for k, v in value.items()
+            ):
+                raise ProjectConfigError(
+                    f"site.external_links.{key} must map module names to strings",
+                )
+            mappings[key] = value
+        templates = {
+            key: _optional_str(data, key, "site.external_links")
+            for key in ("stdlib", "template")
+        }
+        named = [(f"packages.{k}", v) for k, v in mappings["packages"].items()]
+        for key, template in [*templates.items(), *named]:
+            if template is None:
+                continue
+            try:
+                template.format(**dict.fromkeys(EXTERNAL_LINK_FIELDS, ""))
+            except (KeyError, IndexError, ValueError) as exc:
+                raise ProjectConfigError(
+                    f"site.external_links.{key} is invalid: {exc}",
+                ) from exc
+        return cls(enabled=True, python=python, **templates, **mappings)
+
+
+@dataclass
+class BuildConstantsConfig:
+    """The ``site.build_constants`` section: the build-time configuration page.
+
+    ``names`` holds ``fnmatch`` patterns of variable names to report besides
+    the usual ``__version__``, ``COMMIT``, ``BUILD_*``, and the like.
+    """
+
+    enabled: bool = True
+    names: list[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any] | bool) -> BuildConstantsConfig:
+        if isinstance(data, bool):
+            return cls(enabled=data)
+        return cls(names=_patterns(data, "names", "site.build_constants"))
+
+
+@dataclass
+class SanitizeConfig:
+    """The ``site.sanitize`` section: the HTML docs content may hold.
+
+    ``tags``, ``attributes`` (``name`` on every tag, ``tag.name`` on one), and
+    URL ``schemes`` extend the allowlist of :mod:`services.html_sanitizer`.
+    """
+
+    enabled: bool = True
+    tags: list[str] = field(default_factory=list)
+    attributes: list[str] = field(default_factory=list)
+    schemes: list[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any] | bool) -> SanitizeConfig:
+        if isinstance(data, bool):
+            return cls(enabled=data)
+        names = {}
+        for key, pattern in (
+            ("tags", _TAG_NAME),
+            ("attributes", _ATTRIBUTE_NAME),
+            ("schemes", _URL_SCHEME),
+        ):
+            value = data.get(key, [])
+            if isinstance(value, str):
+                value = [value]
+            if not isinstance(value, list) or not all(
+                isinstance(v, str) and pattern.match(v) for v in value
+            ):
+                raise ProjectConfigError(
+                    f"site.sanitize.{key} must be a list of {key[:-1]} names",
+                )
+            names[key] = [v.lower() for v in value]
+        return cls(enabled=True, **names)
+
+
+def _expressions(data: dict[str, Any], key: str, section: str) -> list[str]:
+    value = data.get(key, [])
+    if isinstance(value, str):
+        value = [value]
+    message = f"{section}.{key} must be a list of regular expressions"
+    if not isinstance(value, list) or not all(isinstance(v, str) for v in value):
+        raise ProjectConfigError(message)
+    for expression in value:
+        try:
+            re.compile(expression)
+        except re.error as exc:
+            raise ProjectConfigError(f"{message}: {expression!r}: {exc}") from exc
+    return value
+
+
+@dataclass
+class RedactConfig:
+    """The ``site.redact`` section: what published docs must not show.
+
+    ``packages`` (:mod:`fnmatch` patterns of package names, subpackages
+    included), ``paths`` (patterns of paths relative to the root), and
+    ``symbols`` (regular expressions searched in qualified names) select
+    symbols, which ``mode`` leaves out or shows with ``mask`` for a
+    docstring. Matches of the ``text`` expressions in docstrings, comments,
+    and READMEs are replaced with ``mask``. See :mod:`services.redaction`.
+    """
+
+    packages: list[str] = field(default_factory=list)
+    paths: list[str] = field(default_factory=list)
+    symbols: list[str] = field(default_factory=list)
+    text: list[str] = field(default_factory=list)
+    mode: str = "exclude"
+    mask: str = "[redacted]"
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> RedactConfig:
+        mode = _optional_str(data, "mode", "site.redact") or cls.mode
+        if mode not in REDACT_MODES:
+            raise ProjectConfigError(
+                f"site.redact.mode must be one of {', '.join(REDACT_MODES)}",
+            )
+        mask = data.get("mask", cls.mask)
+        if not isinstance(mask, str):
+            raise ProjectConfigError("site.redact.mask must be a string")
+        return cls(
+            packages=_patterns(data, "packages", "site.redact"),
+            paths=_patterns(data, "paths", "site.redact"),
+            symbols=_expressions(data, "symbols", "site.redact"),
+            text=_expressions(data, "text", "site.redact"),
+            mode=mode,
+            mask=mask,
+        )
+
+
+@dataclass
+class ThirdPartyConfig:
+    """The ``site.third_party`` section: the third-party dependency appendix.
+
+    ``licenses`` (relative to the config file) is a license scanner's JSON
+    report, consulted before the installed packages' metadata.
+    """
+
+    enabled: bool = False
+    licenses: str | None = None
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any] | bool) -> ThirdPartyConfig:
+        if isinstance(data, bool):
+            return cls(enabled=data)
+        return cls(
+            enabled=True,
+            licenses=_optional_str(data, "licenses", "site.third_party"),
+        )
+
+
+@dataclass
+class SiteConfig:
+    """The ``site`` section: settings for generated documentation."""
+
+    title: str = "API Reference"
+    # Number of "most used" symbols listed on package pages (0 disables).
+    most_used: int = 5
+    # Whether to generate the "Architecture" entry-point overview page.
+    architecture: bool = True
+    # Whether to generate the dependency injection graph page.
+    dependencies: bool = True
+    # Whether to qualify and cross-link same-named symbols of different packages.
+    namesakes: bool = True
+    # ``hide`` leaves mock modules out of the docs; ``show`` documents them.
+    mocks: str = "hide"
+    # Whether package pages list the data files their modules load.
+    assets: bool = True
+    # Whether to generate the code generation page.
+    generation: bool = True
+    # Whether symbols list the linter and type checker pragmas on them.
+    pragmas: bool = True
+    # Whether package pages describe what importing their modules does.
+    side_effects: bool = True
+    # Whether functions state if they handle None for optional parameters.
+    none_safety: bool = True
+    # Whether functions list the exceptions they raise and what those chain.
+    raises: bool = True
+    # Whether Args/Returns/Raises sections of docstrings render as fields.
+    docstring_sections: bool = True
+    # Whether the JSON site also writes spans.json for code browsers.
+    spans: bool = False
+    # Whether undocumented methods show the docstring of the method they override.
+    inherit_docs: bool = False
+    # Whether undocumented symbols show a summary guessed from their signature.
+    placeholder_summaries: bool = False
+    # Whether package pages open with a snippet using their main class.
+    quickstart: bool = False
+    # Whether package pages open with the README.md of their directory.
+    readmes: bool = True
+    # Whether package pages list their FAQ and troubleshooting entries.
+    faq: bool = True
+    # Whether to generate the symbol statistics page.
+    stats: bool = False
+    # Whether to generate the risk appendix of native code and reflection.
+    risks: bool = False
+    # Whether to generate the platform support matrix page.
+    platforms: bool = False
+    # Whether the index lists replaced dependency sources and retracted versions.
+    directives: bool = False
+    # Whether the index states the minimum Python and the syntax needing more.
+    python_version: bool = False
+    # ``compile`` or ``run`` fails the build on broken code examples; ``off``.
+    examples: str = "off"
+    # Directory of Markdown guides (relative to the config file), or None.
+    guides: str | None = "docs/guides"
+    theme: ThemeConfig = field(default_factory=ThemeConfig)
+    edit_links: EditLinkConfig | None = None
+    highlight: HighlightConfig = field(default_factory=HighlightConfig)
+    glossary: GlossaryConfig = field(default_factory=GlossaryConfig)
+    build_constants: BuildConstantsConfig = field(default_factory=BuildConstantsConfig)
+    third_party: ThirdPartyConfig = field(default_factory=ThirdPartyConfig)
+    sanitize: SanitizeConfig = field(default_factory=SanitizeConfig)
+    redact: RedactConfig = field(default_factory=RedactConfig)
+    diagrams: DiagramConfig = field(default_factory=DiagramConfig)
+    translations: TranslationsConfig = field(default_factory=TranslationsConfig)
+    # Public URL the HTML site is served from, always ending in ``/``.
+    base_url: str | None = None
+    sitemap: SitemapConfig = field(default_factory=SitemapConfig)
+    caching: CachingConfig = field(default_factory=CachingConfig)
+    analytics: AnalyticsConfig = field(default_factory=AnalyticsConfig)
+    external_links: ExternalLinksConfig = field(default_factory=ExternalLinksConfig)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> SiteConfig:
+        theme = data.get("theme") or {}
+        if not isinstance(theme, dict):
+            raise ProjectConfigError("site.theme must be a mapping")
+        highlight = data.get("highlight") or {}
+        if not isinstance(highlight, dict):
+            raise ProjectConfigError("site.highlight must be a mapping")
+        edit_links = data.get("edit_links")
+        if edit_links is not None and not isinstance(edit_links, dict):
+            raise ProjectConfigError("site.edit_links must be a mapping")
+        glossary = data.get("glossary", {})
+        if not isinstance(glossary, (dict, bool)):
+            raise ProjectConfigError("site.glossary must be a mapping or true/false")
+        build_constants = data.get("build_constants", {})
+        if not isinstance(build_constants, (dict, bool)):
+            raise ProjectConfigError(
+                "site.build_constants must be a mapping or true/false",
+            )
+        third_party = data.get("third_party", False)
+        if not isinstance(third_party, (dict, bool)):
+            raise ProjectConfigError("site.third_party must be a mapping or true/false")
+        sanitize = data.get("sanitize", True)
+        if not isinstance(sanitize, (dict, bool)):
+            raise ProjectConfigError("site.sanitize must be a mapping or true/false")
+        redact = data.get("redact") or {}
+        if not isinstance(redact, dict):
+            raise ProjectConfigError("site.redact must be a mapping")
+        diagrams = data.get("diagrams") or {}
+        if not isinstance(diagrams, dict):
+            raise ProjectConfigError("site.diagrams must be a mapping")
+        translations = data.get("translations") or {}
+        if not isinstance(translations, dict):
+            raise ProjectConfigError("site.translations must be a mapping")
+        caching = data.get("caching") or {}
+        if not isinstance(caching, dict):
+            raise ProjectConfigError("site.caching must be a mapping")
+        analytics = data.get("analytics") or {}
+        if not isinstance(analytics, dict):
+            raise ProjectConfigError("site.analytics must be a mapping")
+        sitemap = data.get("sitemap", {})
+        if not isinstance(sitemap, (dict, bool)):
+            raise ProjectConfigError("site.sitemap must be a mapping or true/false")
+        external_links = data.get("external_links", False)
+        if not isinstance(external_links, (dict, bool)):
+            raise ProjectConfigError(
+                "site.external_links must be a mapping or true/false",
+            )
+        base_url = _optional_str(data, "base_url", "site")
+        if base_url is not None:
+            if not _BASE_URL.match(base_url):
+                raise ProjectConfigError(
+                    "site.base_url must be an http(s) URL like "
+                    "https://docs.example.com/api/",
+                )
+            base_url = base_url.rstrip("/") + "/"
+        most_used = data.get("most_used", cls.most_used)
+        if not isinstance(most_used, int) or isinstance(most_used, bool) or most_used < 0:
+            raise ProjectConfigError("site.most_used must be a non-negative integer")
+        architecture = data.get("architecture", True)
+        if not isinstance(architecture, bool):
+            raise ProjectConfigError("site.architecture must be true or false")
+        dependencies = data.get("dependencies", True)
+        if not isinstance(dependencies, bool):
+            raise ProjectConfigError("site.dependencies must be true or false")
+        namesakes = data.get("namesakes", True)
+        if not isinstance(namesakes, bool):
+            raise ProjectConfigError("site.namesakes must be true or false")
+        assets = data.get("assets", True)
+        if not isinstance(assets, bool):
+            raise ProjectConfigError("site.assets must be true or false")
+        generation = data.get("generation", True)
+        if not isinstance(generation, bool):
+            raise ProjectConfigError("site.generation must be true or false")
+        pragmas = data.get("pragmas", True)
+        if not isinstance(pragmas, bool):
+            raise ProjectConfigError("site.pragmas must be true or false")
+        side_effects = data.get("side_effects", True)
+        if not isinstance(side_effects, bool):
+            raise ProjectConfigError("site.side_effects must be true or false")
+        none_safety = data.get("none_safety", True)
+        if not isinstance(none_safety, bool):
+            raise ProjectConfigError("site.none_safety must be true or false")
+        raises = data.get("raises", True)
+        if not isinstance(raises, bool):
+            raise ProjectConfigError("site.raises must be true or false")
+        docstring_sections = data.get("docstring_sections", True)
+        if not isinstance(docstring_sections, bool):
+            raise ProjectConfigError("site.docstring_sections must be true or false")
+        spans = data.get("spans", False)
+        if not isinstance(spans, bool):
+            raise ProjectConfigError("site.spans must be true or false")
+        inherit_docs = data.get("inherit_docs", False)
+        if not isinstance(inherit_docs, bool):
+            raise ProjectConfigError("site.inherit_docs must be true or false")
+        placeholder_summaries = data.get("placeholder_summaries", False)
+        if not isinstance(placeholder_summaries, bool):
+            raise ProjectConfigError(
+                "site.placeholder_summaries must be true or false",
+            )
+        quickstart = data.get("quickstart", False)
+        if not isinstance(quickstart, bool):
+            raise ProjectConfigError("site.quickstart must be true or false")
+        readmes = data.get("readmes", True)
+        if not isinstance(readmes, bool):
+            raise ProjectConfigError("site.readmes must be true or false")
+        faq = data.get("faq", True)
+        if not isinstance(faq, bool):
+            raise ProjectConfigError("site.faq must be true or false")
+        stats = data.get("stats", False)
+        if not isinstance(stats, bool):
+            raise ProjectConfigError("site.stats must be true or false")
+        risks = data.get("risks", False)
+        if not isinstance(risks, bool):
+            raise ProjectConfigError("site.risks must be true or false")
+        platforms = data.get("platforms", False)
+        if not isinstance(platforms, bool):
+            raise ProjectConfigError("site.platforms must be true or false")
+        directives = data.get("directives", False)
+        if not isinstance(directives, bool):
+            raise ProjectConfigError("site.directives must be true or false")
+        python_version = data.get("python_version", False)
+        if not isinstance(python_version, bool):
+            raise ProjectConfigError("site.python_version must be true or false")
+        examples = data.get("examples", cls.examples)
+        # YAML reads a bare ``off`` as false.
+        if isinstance(examples, bool):
+            examples = "compile" if examples else "off"
+        if examples not in EXAMPLE_MODES:
+            raise ProjectConfigError(
+                f"site.examples must be one of {', '.join(EXAMPLE_MODES)}",
+            )
+        guides = data.get("guides", cls.guides)
+        if guides is not False and (not isinstance(guides, str) or not guides):
+            raise ProjectConfigError("site.guides must be a directory or false")
+        mocks = _optional_str(data, "mocks", "site") or cls.mocks
+        if mocks not in MOCK_MODES:
+            raise ProjectConfigError(
+                f"site.mocks must be one of {', '.join(MOCK_MODES)}",
+            )
+        return cls(
+            title=_optional_str(data, "title", "site") or cls.title,
+            most_used=most_used,
+            architecture=architecture,
+            dependencies=dependencies,
+            namesakes=namesakes,
+            mocks=mocks,
+            assets=assets,
+            generation=generation,
+            pragmas=pragmas,
+            side_effects=side_effects,
+            none_safety=none_safety,
+            raises=raises,
+            docstring_sections=docstring_sections,
+            spans=spans,
+            inherit_docs=inherit_docs,
+            placeholder_summaries=placeholder_summaries,
+            quickstart=quickstart,
+            readmes=readmes,
+            faq=faq,
+            stats=stats,
+            risks=risks,
+            platforms=platforms,
+            directives=directives,
+            python_version=python_version,
+            examples=examples,
+            guides=guides or None,
+            theme=ThemeConfig.from_dict(theme),
+            edit_links=EditLinkConfig.from_dict(edit_links) if edit_links else None,
+            highlight=HighlightConfig.from_dict(highlight),
+            glossary=GlossaryConfig.from_dict(glossary),
+            build_constants=BuildConstantsConfig.from_dict(build_constants),
+            third_party=ThirdPartyConfig.from_dict(third_party),
+            sanitize=SanitizeConfig.from_dict(sanitize),
+            redact=RedactConfig.from_dict(redact),
+            diagrams=DiagramConfig.from_dict(diagrams),
+            translations=TranslationsConfig.from_dict(translations),
+            base_url=base_url,
+            sitemap=SitemapConfig.from_dict(sitemap),
+            caching=CachingConfig.from_dict(caching),
+            analytics=AnalyticsConfig.from_dict(analytics),
+            external_links=ExternalLinksConfig.from_dict(external_links),
+        )
+
+
+def _patterns(data: dict[str, Any], key: str, section: str) -> list[str]:
+    value = data.get(key, [])
+    if isinstance(value, str):
+        value = [value]
+    if not isinstance(value, list) or not all(isinstance(v, str) for v in value):
+        raise ProjectConfigError(f"{section}.{key} must be a list of patterns")
+    return value
+
+
+@dataclass
+class UnusedConfig:
+    """The ``unused`` section: what the unused-symbol report leaves out.
+
+    All entries are :mod:`fnmatch` patterns. ``packages`` are never
+    reported, ``keep`` matches qualified symbol names, and ``decorators``
+    adds registration decorators to the built-in ones (route handlers,
+    fixtures, CLI commands, ...).
+    """
+
+    packages: list[str] = field(default_factory=list)
+    keep: list[str] = field(default_factory=list)
+    decorators: list[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> UnusedConfig:
+        return cls(
+            packages=_patterns(data, "packages", "unused"),
+            keep=_patterns(data, "keep", "unused"),
+            decorators=_patterns(data, "decorators", "unused"),
+        )
+
+
+@dataclass
+class ApiConfig:
+    """The ``api`` section: packages whose exported API must not change.
+
+    ``frozen`` holds :mod:`fnmatch` patterns of package names; a package
+    freezes its subpackages too. ``governance`` tells whoever changes a
+    frozen API how to get the change approved, such as a URL.
+    """
+
+    frozen: list[str] = field(default_factory=list)
+    governance: str | None = None
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> ApiConfig:
+        return cls(
+            frozen=_patterns(data, "frozen", "api"),
+            governance=_optional_str(data, "governance", "api"),
+        )
+
+
+@dataclass
+class IntegrationsConfig:
+    """The ``integrations`` section: settings of the external integrations.
+
+    ``confluence``, ``jira``, and ``github`` map setting names (``url``,
+    ``token``, ``max_retries``, ...) to values that take precedence over the
+    environment (``CONFLUENCE_URL``, ...). Any string may be a ``${NAME}``
+    or ``file:PATH`` reference (see :mod:`autodoc.config.secrets`), and a
+    ``token`` must be one, so the config file can be committed.
+    """
+
+    confluence: dict[str, Any] = field(default_factory=dict)
+    jira: dict[str, Any] = field(default_factory=dict)
+    github: dict[str, Any] = field(default_factory=dict)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> IntegrationsConfig:
+        unknown = sorted(set(data) - set(INTEGRATIONS))
+        if unknown:
+            raise ProjectConfigError(
+                f"integrations.{unknown[0]} is not one of {', '.join(INTEGRATIONS)}",
+            )
+        sections = {}
+        for name in INTEGRATIONS:
+            section = data.get(name) or {}
+            if not isinstance(section, dict) or not all(
+                isinstance(value, str | int | float) for value in section.values()
+            ):
+                raise ProjectConfigError(
+                    f"integrations.{name} must map setting names to values",
+                )
+            for key in SECRET_KEYS:
+                value = section.get(key)
+                if value is not None and not is_reference(str(value)):
+                    raise ProjectConfigError(
+                        f"integrations.{name}.{key} must be a reference such as "
+                        f"${{{name.upper()}_TOKEN}} or file:PATH, not the secret",
+                    )
+            sections[name] = section
+        return cls(**sections)
+
+    def settings(self, name: str, base_dir: str | Path) -> dict[str, Any]:
+        """The settings of integration ``name``, with references resolved.
+
+        Raises:
+            SecretError: If a reference cannot be resolved
+        """
+        section = getattr(self, name)
+        return {
+            key: resolve_secret(value, base_dir) if isinstance(value, str) else value
+            for key, value in section.items()
+        }
+
+
+@dataclass
+class ProjectConfig:
+    """Parsed ``autodoc.yaml``."""
+
+    path: Path | None = None
+    lint: LintConfig = field(default_factory=LintConfig)
+    site: SiteConfig = field(default_factory=SiteConfig)
+    unused: UnusedConfig = field(default_factory=UnusedConfig)
+    integrations: IntegrationsConfig = field(default_factory=IntegrationsConfig)
+    api: ApiConfig = field(default_factory=ApiConfig)
+    raw: dict[str, Any] = field(default_factory=dict)
+
+    def base_dir(self, root: str | Path) -> Path:
+        """The directory paths in the config are relative to.
+
+        That is the config file's directory, or ``root`` without a file.
+        """
+        return self.path.parent if self.path else Path(root)
+
+    @classmethod
+    def from_dict(
+        cls,
+        data: dict[str, Any],
+        path: Path | None = None,
+    ) -> ProjectConfig:
+        sections = {}
+        for name in ("lint", "site", "unused", "integrations", "api"):
+            section = data.get(name) or {}
+            if not isinstance(section, dict):
+                raise ProjectConfigError(f"{name} must be a mapping")
+            sections[name] = section
+        return cls(
+            path=path,
+            lint=LintConfig.from_dict(sections["lint"]),
+            site=SiteConfig.from_dict(sections["site"]),
+            unused=UnusedConfig.from_dict(sections["unused"]),
+            integrations=IntegrationsConfig.from_dict(sections["integrations"]),
+            api=ApiConfig.from_dict(sections["api"]),
+            raw=data,
+        )
+
+
+def find_project_config(root: str | Path) -> Path | None:
+    """Return the config file in ``root``, if there is one."""
+    root_path = Path(root)
+    if root_path.is_file():
+        root_path = root_path.parent
+    for name in CONFIG_FILENAMES:
+        candidate = root_path / name
+        if candidate.is_file():
+            return candidate
+    return None
+
+
+def load_project_config(
+    root: str | Path = ".",
+    path: str | Path | None = None,
+) -> ProjectConfig:
+    """Load ``path`` (or the config file found in ``root``).
+
+    Raises:
+        ProjectConfigError: If the file is unreadable, not valid YAML, or
+            does not match the expected structure
+    """
+    config_path = Path(path) if path else find_project_config(root)
+    if config_path is None:
+        return ProjectConfig()
+
+    try:
+        data = yaml.safe_load(config_path.read_text(encoding="utf-8")) or {}
+    except (OSError, yaml.YAMLError) as exc:
+        raise ProjectConfigError(f"Cannot read {config_path}: {exc}") from exc
+    if not isinstance(data, dict):
+        raise ProjectConfigError(f"{config_path} must contain a mapping")
+    return ProjectConfig.from_dict(data, path=config_path)
+
+
+__all__ = [
+    "CONFIG_FILENAMES",
+    "DIAGRAM_FORMATS",
+    "EDIT_URL_TEMPLATES",
+    "EXAMPLE_MODES",
+    "EXTERNAL_LINK_FIELDS",
+    "HEADER_FORMATS",
+    "INTEGRATIONS",
+    "LANGUAGE_CODE",
+    "MOCK_MODES",
+    "REDACT_MODES",
+    "SECRET_KEYS",
+    "THEME_MODES",
+    "AnalyticsConfig",
+    "ApiConfig",
+    "BuildConstantsConfig",
+    "CachingConfig",
+    "DiagramConfig",
+    "EditLinkConfig",
+    "ExternalLinksConfig",
+    "GlossaryConfig",
+    "HighlightConfig",
+    "IntegrationsConfig",
+    "LinksConfig",
+    "LintConfig",
+    "ProjectConfig",
+    "ProjectConfigError",
+    "RedactConfig",
+    "SanitizeConfig",
+    "SiteConfig",
+    "SitemapConfig",
+    "SpellingConfig",
+    "TerminologyConfig",
+    "ThemeConfig",
+    "ThirdPartyConfig",
+    "TranslationsConfig",
+    "UnusedConfig",
+    "find_project_config",
+    "load_project_config",
+]
@@ -0,0 +1,123 @@
+"""Secret references in ``autodoc.yaml`` and their redaction from output.
+
+Tokens never belong in a committed config file. Where ``autodoc.yaml`` takes
+a secret, it takes a reference instead:
+
+- ``${NAME}`` is replaced by the environment variable ``NAME``;
+- ``file:PATH`` is replaced by the content of ``PATH`` (relative to the
+  config file, ``~`` expanded), without its trailing newline, which suits
+  mounted secrets such as ``file:/run/secrets/jira_token``.
+
+Every secret resolved here, and every token the integrations read from the
+environment, is registered with :func:`register_secret`. :func:`redact`
+replaces registered values in text, and :class:`RedactingFilter` does so for
+log records, so a token echoed back in an error response or a URL never
+reaches the terminal or a log file.
+"""
+
+from __future__ import annotations
+
+import logging
+import os
+import re
+import threading
+from collections.abc import Mapping
+from pathlib import Path
+
+FILE_PREFIX = "file:"
+REDACTED = "[redacted]"
+
+_ENV_REFERENCE = re.compile(r"^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$")
+# Secrets shorter than this are not redacted: they would match ordinary text.
+_MIN_LENGTH = 4
+
+_secrets: set[str] = set()
+_lock = threading.Lock()
+
+
+class SecretError(Exception):
+    """Raised when a secret reference cannot be resolved."""
+
+
+def is_reference(value: str) -> bool:
+    """Whether ``value`` is a ``${NAME}`` or ``file:PATH`` reference."""
+    return bool(_ENV_REFERENCE.match(value)) or value.startswith(FILE_PREFIX)
+
+
+def resolve_secret(
+    value: str,
+    base_dir: str | Path | None = None,
+    environ: Mapping[str, str] | None = None,
+) -> str:
+    """The secret ``value`` refers to; other values are returned unchanged.
+
+    Raises:
+        SecretError: If the variable is unset or empty, or the file cannot
+            be read
+    """
+    environ = os.environ if environ is None else environ
+    match = _ENV_REFERENCE.match(value)
+    if match:
+        name = match.group(1)
+        secret = environ.get(name)
+        if not secret:
+            raise SecretError(f"Environment variable {name} is not set")
+    elif value.startswith(FILE_PREFIX):
+        path = Path(value[len(FILE_PREFIX) :]).expanduser()
+        if not path.is_absolute() and base_dir is not None:
+            path = Path(base_dir) / path
+        try:
+            secret = path.read_text(encoding="utf-8").rstrip("\r\n")
+        except OSError as exc:
+            message = f"Cannot read secret file {path}: {exc.strerror}"
+            raise SecretError(message) from exc
+        if not secret:
+            raise SecretError(f"Secret file {path} is empty")
+    else:
+        return value
+    register_secret(secret)
+    return secret
+
+
+def register_secret(value: str | None) -> None:
+    """Redact ``value`` from everything :func:`redact` sees from now on."""
+    if value and len(value) >= _MIN_LENGTH:
+        with _lock:
+            _secrets.add(value)
+
+
+def redact(text: str) -> str:
+    """``text`` with every registered secret replaced by :data:`REDACTED`."""
+    with _lock:
+        secrets = sorted(_secrets, key=len, reverse=True)
+    for secret in secrets:
+        if secret in text:
+            text = text.replace(secret, REDACTED)
+    return text
+
+
+class RedactingFilter(logging.Filter):
+    """Redacts registered secrets from the records passing a handler."""
+
+    def filter(self, record: logging.LogRecord) -> bool:
+        message = record.getMessage()
+        redacted = redact(message)
+        if redacted != message:
+            record.msg, record.args = redacted, None
+        if record.exc_info and not record.exc_text:
+            record.exc_text = logging.Formatter().formatException(record.exc_info)
+        if record.exc_text:
+            record.exc_text = redact(record.exc_text)
+        return True
+
+
+__all__ = [
+    "FILE_PREFIX",
+    "REDACTED",
+    "RedactingFilter",
+    "SecretError",
+    "is_reference",
+    "redact",
+    "register_secret",
+    "resolve_secret",
+]
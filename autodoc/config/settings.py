@@ -214,7 +214,35 @@ class SecuritySettings(BaseSettings):
         return v
 
 
-class ConfluenceSettings(BaseSettings):
+class IntegrationSettings(BaseSettings):
+    """Timeout, retry, and rate limits shared by the external integrations.
+
+    Each integration inherits these under its own prefix, such as
+    ``CONFLUENCE_RATE_LIMIT`` or ``GITHUB_MAX_RETRIES``; see
+    :mod:`services.http_client`.
+    """
+
+    timeout: int = Field(default=30, description="Request timeout in seconds")
+    max_retries: int = Field(default=3, description="Maximum retry attempts")
+    retry_backoff: float = Field(
+        default=0.5,
+        description="Seconds before the first retry, doubled for each further one",
+    )
+    max_backoff: float = Field(
+        default=30.0,
+        description="Longest wait between retries in seconds",
+    )
+    rate_limit: float = Field(
+        default=0,
+        description="Maximum requests per second (0 for no limit)",
+    )
+    rate_burst: int = Field(
+        default=1,
+        description="Requests that may be sent at once within the rate limit",
+    )
+
+
+class ConfluenceSettings(IntegrationSettings):
     """Confluence integration settings.
 
     All credentials are read from environment variables only.
@@ -233,8 +261,6 @@ class ConfluenceSettings(BaseSettings):
         description="Default Confluence space key",
     )
     page_prefix: str = Field(default="AutoDoc", description="Page title prefix")
-    timeout: int = Field(default=30, description="Request timeout in seconds")
-    max_retries: int = Field(default=3, description="Maximum retry attempts")
 
     @field_validator("url")
     @classmethod
@@ -250,6 +276,58 @@ class ConfluenceSettings(BaseSettings):
         return all([self.url, self.username, self.token])
 
 
+class JiraSettings(IntegrationSettings):
+    """Jira integration settings used for documentation-debt issues.
+
+    All credentials are read from environment variables only.
+    """
+
+    model_config = SettingsConfigDict(env_prefix="JIRA_")
+
+    url: str | None = Field(default=None, description="Jira base URL")
+    username: str | None = Field(default=None, description="Jira username")
+    token: str | None = Field(default=None, description="Jira API token")
+    project_key: str | None = Field(
+        default=None,
+        description="Project key new issues are filed under",
+    )
+    issue_type: str = Field(default="Task", description="Issue type for new issues")
+
+    @field_validator("url")
+    @classmethod
+    def validate_jira_url(cls, v: str | None) -> str | None:
+        """Validate Jira URL format."""
+        if v and not v.startswith(("http://", "https://")):
+            raise ValueError("Jira URL must start with http:// or https://")
+        return v
+
+    @property
+    def is_configured(self) -> bool:
+        """Check if Jira is properly configured."""
+        return all([self.url, self.username, self.token, self.project_key])
+
+
+class GitHubSettings(IntegrationSettings):
+    """GitHub integration settings used for documentation-debt issues."""
+
+    model_config = SettingsConfigDict(env_prefix="GITHUB_")
+
+    api_url: str = Field(
+        default="https://api.github.com",
+        description="GitHub REST API base URL",
+    )
+    token: str | None = Field(default=None, description="GitHub API token")
+    repository: str | None = Field(
+        default=None,
+        description="Repository issues are filed in (owner/name)",
+    )
+
+    @property
+    def is_configured(self) -> bool:
+        """Check if GitHub is properly configured."""
+        return all([self.token, self.repository])
+
+
 class LoggingSettings(BaseSettings):
     """Logging configuration settings."""
 
@@ -337,6 +415,8 @@ class Settings(BaseSettings):
     api: APISettings = Field(default_factory=lambda: APISettings())
     security: SecuritySettings = Field(default_factory=lambda: SecuritySettings())
     confluence: ConfluenceSettings = Field(default_factory=lambda: ConfluenceSettings())
+    jira: JiraSettings = Field(default_factory=lambda: JiraSettings())
+    github: GitHubSettings = Field(default_factory=lambda: GitHubSettings())
     logging: LoggingSettings = Field(default_factory=lambda: LoggingSettings())
     file: FileSettings = Field(default_factory=lambda: FileSettings())
 
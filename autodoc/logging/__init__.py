@@ -22,6 +22,7 @@ from .correlation import (
     set_run_context,
 )
 from .logger import (
+    cli_logging,
     configure_logging,
     get_logger,
     setup_logging,
@@ -34,6 +35,7 @@ __all__ = [
     # Context management
     "LoggingContext",
     "clear_correlation_context",
+    "cli_logging",
     "configure_logging",
     "get_correlation_id",
     # Logger management
@@ -6,10 +6,14 @@ Provides structured logging with correlation ID support and configurable output
 import json
 import logging
 import sys
+from collections.abc import Iterator
+from contextlib import contextmanager
 from datetime import UTC, datetime
 from pathlib import Path
 from typing import Any
 
+from autodoc.config.secrets import RedactingFilter
+
 from .correlation import get_correlation_context
 
 
@@ -49,7 +53,10 @@ class StructuredFormatter(logging.Formatter):
 
         # Add exception information if present
         if record.exc_info:
-            log_data["exception"] = self.formatException(record.exc_info)
+            # exc_text is set (and redacted) by RedactingFilter.
+            log_data["exception"] = record.exc_text or self.formatException(
+                record.exc_info,
+            )
 
         # Add extra fields from the log record
         for key, value in record.__dict__.items():
@@ -75,6 +82,7 @@ class StructuredFormatter(logging.Formatter):
                 "processName",
                 "process",
                 "getMessage",
+                "taskName",
             }:
                 log_data[key] = value
 
@@ -158,6 +166,7 @@ def setup_logging(
         formatter = TextFormatter(include_correlation=include_correlation)
 
     console_handler.setFormatter(formatter)
+    console_handler.addFilter(RedactingFilter())
     root_logger.addHandler(console_handler)
 
     # Add file handler if specified
@@ -168,6 +177,7 @@ def setup_logging(
         file_handler = logging.FileHandler(log_path)
         file_handler.setLevel(level)
         file_handler.setFormatter(formatter)
+        file_handler.addFilter(RedactingFilter())
         root_logger.addHandler(file_handler)
 
     # Set specific logger levels
@@ -208,6 +218,40 @@ def configure_logging(
             logging.getLogger(logger_name).setLevel(logger_level)
 
 
+# Root logger level per ``-v`` count for the documentation subcommands.
+CLI_LEVELS = (logging.WARNING, logging.INFO, logging.DEBUG)
+
+
+@contextmanager
+def cli_logging(verbosity: int = 0, format_type: str = "text") -> Iterator[None]:
+    """Log to stderr for the duration of a CLI subcommand.
+
+    Unlike :func:`setup_logging` this adds one handler and removes it again on
+    exit, so commands run in-process (tests, the pre-commit hook) leave the
+    caller's logging untouched. stdout stays free for command output.
+
+    Args:
+        verbosity: 0 for warnings only, 1 to add progress and timings, 2 or
+            more to add per-file detail
+        format_type: Log format ("json" or "text")
+    """
+    level = CLI_LEVELS[min(verbosity, len(CLI_LEVELS) - 1)]
+    handler = logging.StreamHandler(sys.stderr)
+    handler.setFormatter(
+        StructuredFormatter() if format_type == "json" else TextFormatter(),
+    )
+    handler.addFilter(RedactingFilter())
+    root_logger = logging.getLogger()
+    previous = root_logger.level
+    root_logger.addHandler(handler)
+    root_logger.setLevel(level)
+    try:
+        yield
+    finally:
+        root_logger.removeHandler(handler)
+        root_logger.setLevel(previous)
+
+
 def get_logger(name: str) -> logging.Logger:
     """Get a logger with the specified name.
 
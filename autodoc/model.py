@@ -0,0 +1,148 @@
+"""Build the page model of a documentation site from a parsed tree.
+
+Part of the library API; see :mod:`autodoc.parser` for an example.
+:func:`build_model` groups the exported symbols of a
+:class:`~autodoc.parser.ParsedTree` into packages, modules, and classes in
+the stable order every renderer uses. It also applies the ``site`` settings
+of ``autodoc.yaml`` that shape content rather than presentation: hidden
+mocks, mock links, the "most used" lists, namesake cross-links, the
+embedded asset inventories, the pragmas on each symbol, the import-time
+side effects of each package, the ``None`` safety of functions, the
+exceptions they raise, the parameter and return sections of docstrings,
+the docstrings methods inherit, the README of each package directory, the
+FAQ and troubleshooting entries of each package, and placeholder summaries
+for undocumented symbols. Release-note fragments
+(:mod:`services.release_notes`), FAQ entries (:mod:`services.faq`), and
+audience tags (:mod:`services.audience`) are always removed from the
+docstrings.
+"""
+
+from __future__ import annotations
+
+from autodoc.config.project import ProjectConfig
+from autodoc.parser import ParsedTree
+from services.audience import strip_audience
+from services.doc_site import (
+    ClassDoc,
+    ModuleDoc,
+    PackageDoc,
+    attach_assets,
+    attach_docstring_sections,
+    attach_faq,
+    attach_inherited_docs,
+    attach_mock_links,
+    attach_most_used,
+    attach_namesakes,
+    attach_none_safety,
+    attach_placeholder_summaries,
+    attach_pragmas,
+    attach_quickstarts,
+    attach_raises,
+    attach_readmes,
+    attach_side_effects,
+    build_site_model,
+    map_docstrings,
+)
+from services.embedded_assets import find_embedded_assets
+from services.exception_chains import find_exception_chains
+from services.faq import find_faq, strip_faq
+from services.import_graph import relative_path, symbol_usage
+from services.inherited_docs import find_inherited_docs
+from services.mock_links import find_mock_links, is_mock_module
+from services.name_collisions import find_collisions
+from services.none_safety import find_none_safety
+from services.pragmas import find_pragmas
+from services.release_notes import strip_release_notes
+from services.side_effects import find_side_effects
+from services.telemetry import span
+
+
+def build_model(
+    tree: ParsedTree,
+    config: ProjectConfig | None = None,
+    include_private: bool = False,
+) -> list[PackageDoc]:
+    """The packages of ``tree``, one per documentation page.
+
+    Args:
+        tree: Result of :func:`~autodoc.parser.parse_tree`
+        config: Project configuration (default: built-in defaults)
+        include_private: Also document private symbols
+    """
+    with span("autodoc.model") as current:
+        site = (config or ProjectConfig()).site
+        documented = tree.symbols
+        if site.mocks == "hide":
+            documented = [
+                s
+                for s in tree.symbols
+                if not is_mock_module(relative_path(s.file_path, tree.root))
+            ]
+        packages = build_site_model(documented, include_private=include_private)
+        # Before the docstrings are inherited and the entries stripped.
+        faq = (
+            find_faq((s for p in packages for s in p.symbols()), tree.root)
+            if site.faq
+            else []
+        )
+        if site.inherit_docs:
+            # First, so the later analyses see the inherited docstrings.
+            attach_inherited_docs(
+                packages,
+                find_inherited_docs(tree.graph, tree.symbols),
+            )
+        packages = map_docstrings(
+            packages,
+            lambda symbol: strip_audience(
+                strip_faq(strip_release_notes(symbol.docstring)),
+            ),
+        )
+        attach_mock_links(
+            packages,
+            [
+                (link.mock, link.interface)
+                for link in find_mock_links(tree.root, tree.graph)
+            ],
+        )
+        if site.most_used:
+            usage = symbol_usage(tree.graph, tree.symbols)
+            attach_most_used(
+                packages,
+                {u.qualified_name: u.count for u in usage},
+                site.most_used,
+            )
+        if site.namesakes:
+            attach_namesakes(
+                packages,
+                [collision.symbols for collision in find_collisions(documented)],
+            )
+        if site.assets:
+            attach_assets(packages, find_embedded_assets(tree.root, tree.graph))
+        if site.pragmas:
+            attach_pragmas(packages, find_pragmas(tree.root, tree.graph))
+        if site.side_effects:
+            attach_side_effects(packages, find_side_effects(tree.root, tree.graph))
+        if site.none_safety:
+            attach_none_safety(packages, find_none_safety(tree.root, tree.graph))
+        if site.raises:
+            attach_raises(packages, find_exception_chains(tree.root, tree.graph))
+        if site.docstring_sections:
+            attach_docstring_sections(packages)
+        if site.readmes:
+            attach_readmes(packages)
+        if faq:
+            attach_faq(packages, faq)
+        if site.placeholder_summaries:
+            attach_placeholder_summaries(packages)
+        if site.quickstart:
+            attach_quickstarts(packages, tree.symbols)
+        current.set(packages=len(packages))
+    return packages
+
+
+__all__ = [
+    "ClassDoc",
+    "ModuleDoc",
+    "PackageDoc",
+    "build_model",
+]
@@ -0,0 +1,100 @@
+"""Parse a source tree for documentation.
+
+Part of the library API (:mod:`autodoc.parser`, :mod:`autodoc.model`,
+:mod:`autodoc.render`), which runs the same pipeline as
+``autodoc generate`` without going through the command line::
+
+    from autodoc.model import build_model
+    from autodoc.parser import parse_tree
+    from autodoc.render import render_site, write_site
+
+    tree = parse_tree("src")
+    packages = build_model(tree)
+    for pages in render_site(tree, packages, ["html"]).values():
+        write_site(pages, "site")
+
+:func:`parse_tree` reads every Python file below the root once, collecting
+the documentable symbols and the import graph that the model and renderers
+need. Unparseable files are skipped with a warning, as on the command line.
+A :class:`~services.progress.Progress` passed as ``progress`` receives the
+same progress events as ``autodoc generate --progress json`` prints.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Sequence
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.cancellation import CancelToken
+from services.doc_symbols import (
+    DocSymbol,
+    WalkOptions,
+    discover_python_files,
+    load_doc_symbols,
+)
+from services.import_graph import ImportGraph, build_import_graph
+from services.progress import DISCOVER, Progress, ProgressEvent
+
+
+@dataclass
+class ParsedTree:
+    """The symbols and imports of every Python file below ``root``."""
+
+    root: Path
+    symbols: Sequence[DocSymbol]
+    graph: ImportGraph
+
+
+def parse_tree(
+    root: str | Path = ".",
+    walk: WalkOptions | None = None,
+    cancel: CancelToken | None = None,
+    max_memory: int | None = None,
+    progress: Progress | None = None,
+) -> ParsedTree:
+    """Parse the tree below ``root``.
+
+    Args:
+        root: Source tree to document
+        walk: Symlink, nested project, and file size options for the walk
+        cancel: Token checked between files; once it is cancelled the
+            remaining files are skipped and counted on it
+        max_memory: Bytes of symbols to keep in memory before spilling
+            the rest to a temporary file (see :mod:`services.symbol_spool`)
+        progress: Told of the files discovered, parsed, and analyzed
+
+    Returns:
+        The parsed tree; partial if ``cancel`` was cancelled
+    """
+    files = discover_python_files(root, walk=walk)
+    if progress is not None:
+        progress.complete(DISCOVER, len(files))
+    symbols = load_doc_symbols(
+        root,
+        files,
+        walk=walk,
+        cancel=cancel,
+        max_memory=max_memory,
+        progress=progress,
+    )
+    graph = build_import_graph(
+        root,
+        files,
+        walk=walk,
+        cancel=cancel,
+        progress=progress,
+    )
+    return ParsedTree(Path(root), symbols, graph)
+
+
+__all__ = [
+    "CancelToken",
+    "DocSymbol",
+    "ImportGraph",
+    "ParsedTree",
+    "Progress",
+    "ProgressEvent",
+    "WalkOptions",
+    "parse_tree",
+]
@@ -0,0 +1,350 @@
+"""Render a documentation site as Markdown, HTML, or JSON pages, or indexes.
+
+Part of the library API; see :mod:`autodoc.parser` for an example.
+Renderers return :class:`SitePage` objects rather than writing files, so
+callers can serve, post-process, or diff them; :func:`write_site` writes
+them the way ``autodoc generate`` does.
+
+Rendering raises :class:`~autodoc.config.project.ProjectConfigError`,
+:class:`~services.doc_theme.ThemeError`,
+:class:`~services.doc_highlight.HighlightError`,
+:class:`~services.glossary.GlossaryError`, or
+:class:`~services.entry_points.ArchitectureError` for invalid ``site``
+settings in ``autodoc.yaml``, and
+:class:`~services.doc_assets.AssetError` for images and diagrams that
+cannot be read or rendered.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Callable, Container, Iterable, Mapping, Sequence
+from pathlib import Path
+
+from autodoc.config.project import ProjectConfig
+from autodoc.parser import ParsedTree
+from services.build_constants import find_build_constants
+from services.code_generation import find_code_generation
+from services.dependency_graph import build_dependency_graph
+from services.doc_assets import resolve_assets
+from services.doc_edit_links import EditLinkFn, build_edit_links
+from services.doc_external_links import ExternalLinkFn, build_external_links
+from services.doc_highlight import Highlighter
+from services.doc_html import HtmlSiteRenderer
+from services.doc_json import render_json_site, render_test_suite_json
+from services.doc_markdown import render_markdown_site, render_test_suite_markdown
+from services.doc_site import PackageDoc, SitePage, stamp_pages, write_site
+from services.doc_symbols import DocSymbol
+from services.doc_theme import build_theme_assets
+from services.entry_points import detect_architecture
+from services.glossary import site_glossary
+from services.guides import load_guides
+from services.lsif import LSIF_PATH, render_lsif
+from services.platform_support import find_platform_support
+from services.progress import RENDER, Progress
+from services.project_directives import read_directives
+from services.python_features import python_version_report
+from services.symbol_spans import find_symbol_spans
+from services.symbol_stats import build_symbol_stats
+from services.tags import (
+    CTAGS_PATH,
+    ETAGS_PATH,
+    find_tags,
+    render_ctags,
+    render_etags,
+)
+from services.telemetry import count, span
+from services.test_docs import build_test_suite_doc
+from services.third_party import find_third_party, load_licenses
+from services.unsafe_usage import find_risks, package_risks
+
+FORMATS = ("markdown", "html", "json", "lsif", "ctags", "etags")
+# Formats of one code navigation file rather than pages.
+INDEX_FORMATS = ("lsif", "ctags", "etags")
+
+# Formats with one page per package, named after the package slug.
+PAGE_SUFFIXES = {"markdown": ".md", "html": ".html"}
+
+
+def html_renderer(
+    config: ProjectConfig,
+    root: str | Path,
+    edit_link: EditLinkFn | None = None,
+    language: str | None = None,
+    external_links: ExternalLinkFn | None = None,
+) -> HtmlSiteRenderer:
+    """An HTML renderer themed by ``config``; theme paths are relative to it."""
+    site = config.site
+    base_dir = config.base_dir(root)
+    assets = build_theme_assets(site.theme, base_dir)
+    highlighter = Highlighter(site.highlight, site.theme.mode)
+    return HtmlSiteRenderer(
+        site,
+        assets,
+        edit_link,
+        highlighter,
+        language,
+        external_links,
+    )
+
+
+def _index_page(fmt: str, tree: ParsedTree, symbols: Iterable[DocSymbol]) -> SitePage:
+    """The file of ``fmt``, one of :data:`INDEX_FORMATS`, for ``symbols``."""
+    if fmt == "lsif":
+        return SitePage(LSIF_PATH, render_lsif(tree.root, tree.graph, symbols))
+    tags = find_tags(tree.root, tree.graph, symbols)
+    if fmt == "ctags":
+        return SitePage(CTAGS_PATH, render_ctags(tags))
+    return SitePage(ETAGS_PATH, render_etags(tags))
+
+
+def render_site(
+    tree: ParsedTree,
+    packages: list[PackageDoc],
+    formats: Sequence[str] = ("markdown",),
+    config: ProjectConfig | None = None,
+    only: Container[str] | None = None,
+    language: str | None = None,
+    documented: Mapping[str, str] | None = None,
+    on_page: Callable[[str, SitePage], None] | None = None,
+    progress: Progress | None = None,
+) -> dict[str, list[SitePage]]:
+    """The API documentation pages of ``packages``, per format.
+
+    Images and diagrams referenced from docstrings are copied or rendered
+    into every page format (see :mod:`services.doc_assets`). The formats of
+    :data:`INDEX_FORMATS` are one code navigation file each (see
+    :mod:`services.lsif` and :mod:`services.tags`). External types in
+    signatures are linked in HTML and Markdown when ``site.external_links``
+    is enabled (see :mod:`services.doc_external_links`). The guides of
+    ``site.guides`` are pages of every format (see :mod:`services.guides`).
+
+    Args:
+        tree: The parsed tree the packages were built from
+        packages: Result of :func:`~autodoc.model.build_model`
+        formats: Any of :data:`FORMATS`
+        config: Project configuration (default: built-in defaults)
+        only: Render package pages just for these slugs (JSON and
+            :data:`INDEX_FORMATS` are always rendered in full)
+        language: Language of the docstrings, for the HTML ``lang`` attribute
+        documented: URLs of names documented by other repositories of a
+            portal, linked from signatures like external types
+        on_page: Called with the format and each package page of HTML and
+            Markdown as soon as it is rendered, such as to checkpoint it
+        progress: Told of each package page rendered, and of each format
+            without package pages as one
+    """
+    config = config or ProjectConfig()
+    site = config.site
+    architecture = (
+        detect_architecture(tree.root, tree.graph) if site.architecture else None
+    )
+    dependencies = (
+        build_dependency_graph(tree.root, tree.graph) if site.dependencies else None
+    )
+    glossary = (
+        site_glossary(
+            [symbol for package in packages for symbol in package.symbols()],
+            site.glossary,
+            config.base_dir(tree.root),
+        )
+        if site.glossary.enabled
+        else None
+    )
+    generation = (
+        find_code_generation(tree.root, tree.graph) if site.generation else None
+    )
+    build_constants = (
+        find_build_constants(tree.root, tree.graph, site.build_constants.names)
+        if site.build_constants.enabled
+        else None
+    )
+    spans = (
+        find_symbol_spans(
+            tree.root,
+            tree.graph,
+            [symbol for package in packages for symbol in package.symbols()],
+        )
+        if site.spans and "json" in formats
+        else None
+    )
+    stats = build_symbol_stats(packages, tree.symbols) if site.stats else None
+    guides = (
+        load_guides(
+            config.base_dir(tree.root) / site.guides,
+            {symbol.qualified_name for p in packages for symbol in p.symbols()},
+        )
+        if site.guides
+        else []
+    )
+    risks = (
+        package_risks(find_risks(tree.root, tree.graph)) if site.risks else None
+    )
+    third_party = None
+    if site.third_party.enabled:
+        base_dir = config.base_dir(tree.root)
+        licenses = site.third_party.licenses
+        third_party = find_third_party(
+            base_dir,
+            tree.graph,
+            load_licenses(base_dir / licenses) if licenses else None,
+            site.external_links.distributions,
+        )
+    directives = (
+        read_directives(config.base_dir(tree.root)) if site.directives else None
+    )
+    python_version = (
+        python_version_report(config.base_dir(tree.root), tree.root, tree.graph)
+        if site.python_version
+        else None
+    )
+    platforms = (
+        find_platform_support(tree.root, tree.graph) if site.platforms else None
+    )
+    packages, images = resolve_assets(packages, tree.root, site.diagrams)
+    edit_link = build_edit_links(site.edit_links, tree.root)
+    external_links = build_external_links(
+        site.external_links,
+        config.base_dir(tree.root),
+        tree.graph,
+        documented,
+    )
+    # Progress units of each format: its package pages, or the whole format.
+    units = {fmt: len(packages) if fmt in PAGE_SUFFIXES else 1 for fmt in formats}
+    if progress is not None:
+        progress.start(RENDER, sum(units.values()))
+    sites = {}
+    for fmt in formats:
+        rendered: list[SitePage] = []
+
+        def saved(page: SitePage, fmt: str = fmt) -> None:
+            rendered.append(page)
+            if on_page is not None:
+                on_page(fmt, page)
+            if progress is not None:
+                progress.advance(RENDER)
+
+        with span("autodoc.render", format=fmt) as current:
+            if fmt == "html":
+                renderer = html_renderer(
+                    config,
+                    tree.root,
+                    edit_link,
+                    language,
+                    external_links,
+                )
+                pages = renderer.render(
+                    packages,
+                    architecture,
+                    dependencies,
+                    only,
+                    glossary,
+                    saved,
+                    generation,
+                    build_constants,
+                    stats,
+                    guides,
+                    risks,
+                    third_party,
+                    directives,
+                    python_version,
+                    platforms,
+                )
+            elif fmt in INDEX_FORMATS:
+                symbols = [s for package in packages for s in package.symbols()]
+                pages = [_index_page(fmt, tree, symbols)]
+            elif fmt == "json":
+                pages = render_json_site(
+                    packages,
+                    site,
+                    edit_link,
+                    architecture,
+                    dependencies,
+                    root=tree.root,
+                    glossary=glossary,
+                    generation=generation,
+                    build_constants=build_constants,
+                    spans=spans,
+                    stats=stats,
+                    guides=guides,
+                    risks=risks,
+                    third_party=third_party,
+                    directives=directives,
+                    python_version=python_version,
+                    platforms=platforms,
+                )
+            else:
+                pages = render_markdown_site(
+                    packages,
+                    site,
+                    edit_link,
+                    architecture,
+                    dependencies,
+                    only,
+                    glossary,
+                    external_links,
+                    saved,
+                    generation,
+                    build_constants,
+                    stats,
+                    guides,
+                    risks,
+                    third_party,
+                    directives,
+                    python_version,
+                    platforms,
+                )
+            current.set(pages=len(pages))
+        count("autodoc.pages.rendered", len(pages), format=fmt)
+        sites[fmt] = pages if fmt in INDEX_FORMATS else pages + images
+        if progress is not None:
+            # Packages left to an incremental run's earlier pages count as done.
+            progress.advance(RENDER, max(units[fmt] - len(rendered), 0))
+    if progress is not None:
+        progress.finish(RENDER)
+    return sites
+
+
+def render_test_site(
+    tree: ParsedTree,
+    formats: Sequence[str] = ("markdown",),
+    config: ProjectConfig | None = None,
+    progress: Progress | None = None,
+) -> dict[str, list[SitePage]]:
+    """The test suite documentation of ``tree``, per format.
+
+    The formats of :data:`INDEX_FORMATS` index every symbol of the tree,
+    tests included. Each format is one unit of the ``render`` stage of
+    ``progress``.
+    """
+    config = config or ProjectConfig()
+    suite = build_test_suite_doc(tree.root, tree.graph, tree.symbols)
+    if progress is not None:
+        progress.start(RENDER, len(formats))
+    sites = {}
+    for fmt in formats:
+        if fmt == "html":
+            sites[fmt] = html_renderer(config, tree.root).render_tests(suite)
+        elif fmt == "json":
+            sites[fmt] = render_test_suite_json(suite)
+        elif fmt in INDEX_FORMATS:
+            sites[fmt] = [_index_page(fmt, tree, tree.symbols)]
+        else:
+            sites[fmt] = [SitePage("index.md", render_test_suite_markdown(suite))]
+        if progress is not None:
+            progress.advance(RENDER)
+    if progress is not None:
+        progress.finish(RENDER)
+    return sites
+
+
+__all__ = [
+    "FORMATS",
+    "INDEX_FORMATS",
+    "PAGE_SUFFIXES",
+    "SitePage",
+    "html_renderer",
+    "render_site",
+    "render_test_site",
+    "stamp_pages",
+    "write_site",
+]
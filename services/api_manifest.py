@@ -0,0 +1,169 @@
+"""Committed manifest of the public API surface.
+
+The manifest is a plain text file with one line per exported function,
+class, and method, in the spirit of Go's ``api/*.txt`` files::
+
+    shop.cart, class Cart
+    shop.cart, def Cart.add(item) -> None
+    shop.cart, def total(items: list[int]) -> int
+
+Lines are sorted, so the file is byte-stable for an unchanged surface and a
+diff of it reads as a list of API changes. ``autodoc api --check`` fails when
+the manifest no longer matches the code.
+
+Packages listed as frozen (``api.frozen`` in ``autodoc.yaml``) keep their
+committed lines: :func:`freeze` carries them over when the manifest is
+written, so a change to their API fails ``--check`` until the package is
+unfrozen through the project's governance process.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from fnmatch import fnmatchcase
+from pathlib import Path
+
+from services.doc_site import build_site_model, signature
+from services.doc_symbols import DocSymbol
+
+DEFAULT_MANIFEST_FILE = "api.txt"
+
+
+class ApiManifestError(Exception):
+    """Raised when a manifest file cannot be read."""
+
+
+def _line(module: str, symbol: DocSymbol, owner: str | None = None) -> str:
+    text = signature(symbol)
+    if owner:
+        # ``def add(...)`` -> ``def Cart.add(...)``
+        text = text.replace(f" {symbol.name}(", f" {owner}.{symbol.name}(", 1)
+    return f"{module}, {text}"
+
+
+def manifest_lines(symbols: Iterable[DocSymbol]) -> list[str]:
+    """The sorted manifest lines for the exported surface of ``symbols``."""
+    lines = []
+    for package in build_site_model(symbols):
+        for module in package.modules:
+            name = module.name
+            lines.extend(_line(name, function) for function in module.functions)
+            for cls in module.classes:
+                lines.append(_line(name, cls.symbol))
+                lines.extend(
+                    _line(name, method, cls.symbol.name) for method in cls.methods
+                )
+    return sorted(set(lines))
+
+
+def render_manifest(lines: Iterable[str]) -> str:
+    return "".join(f"{line}\n" for line in lines)
+
+
+def parse_manifest(text: str) -> list[str]:
+    """Manifest lines of ``text``, ignoring blank lines and ``#`` comments."""
+    return [
+        line.strip()
+        for line in text.splitlines()
+        if line.strip() and not line.lstrip().startswith("#")
+    ]
+
+
+def load_manifest(path: str | Path) -> list[str]:
+    """Read a manifest file.
+
+    Raises:
+        ApiManifestError: If the file is missing or unreadable
+    """
+    try:
+        text = Path(path).read_text(encoding="utf-8")
+    except OSError as exc:
+        raise ApiManifestError(f"Cannot read API manifest {path}: {exc}") from exc
+    return parse_manifest(text)
+
+
+def write_manifest(lines: Iterable[str], path: str | Path) -> Path:
+    target = Path(path)
+    target.write_text(render_manifest(lines), encoding="utf-8")
+    return target
+
+
+def line_module(line: str) -> str:
+    """The module a manifest line belongs to."""
+    return line.partition(",")[0].strip()
+
+
+def frozen_package(module: str, frozen: Iterable[str]) -> str | None:
+    """The package of ``module`` matching a pattern of ``frozen``, if any."""
+    parts = module.split(".")
+    patterns = list(frozen)
+    for end in range(1, len(parts) + 1):
+        package = ".".join(parts[:end])
+        if any(fnmatchcase(package, pattern) for pattern in patterns):
+            return package
+    return None
+
+
+def freeze(
+    committed: Iterable[str],
+    current: Iterable[str],
+    frozen: Iterable[str],
+) -> list[str]:
+    """``current`` with the lines of frozen packages as ``committed`` has them."""
+    frozen = list(frozen)
+
+    def thawed(lines: Iterable[str]) -> set[str]:
+        return {
+            line for line in lines if frozen_package(line_module(line), frozen) is None
+        }
+
+    committed, current = list(committed), list(current)
+    kept = set(committed) - thawed(committed)
+    return sorted(thawed(current) | kept)
+
+
+@dataclass
+class ManifestDiff:
+    """Lines the code adds to, and removes from, a committed manifest."""
+
+    added: list[str] = field(default_factory=list)
+    removed: list[str] = field(default_factory=list)
+
+    def __bool__(self) -> bool:
+        return bool(self.added or self.removed)
+
+    def to_dict(self) -> dict[str, list[str]]:
+        return {"added": list(self.added), "removed": list(self.removed)}
+
+    def frozen(self, frozen: Iterable[str]) -> dict[str, int]:
+        """The frozen packages the diff changes, with their changed line counts."""
+        frozen = list(frozen)
+        changed: dict[str, int] = {}
+        for line in [*self.removed, *self.added]:
+            package = frozen_package(line_module(line), frozen)
+            if package is not None:
+                changed[package] = changed.get(package, 0) + 1
+        return dict(sorted(changed.items()))
+
+
+def diff_manifest(committed: Iterable[str], current: Iterable[str]) -> ManifestDiff:
+    """Compare the ``committed`` manifest with the ``current`` surface."""
+    before, after = set(committed), set(current)
+    return ManifestDiff(added=sorted(after - before), removed=sorted(before - after))
+
+
+__all__ = [
+    "DEFAULT_MANIFEST_FILE",
+    "ApiManifestError",
+    "ManifestDiff",
+    "diff_manifest",
+    "freeze",
+    "frozen_package",
+    "line_module",
+    "load_manifest",
+    "manifest_lines",
+    "parse_manifest",
+    "render_manifest",
+    "write_manifest",
+]
@@ -0,0 +1,229 @@
+"""Attestations tying a generated site to the inputs it was built from.
+
+``autodoc generate`` writes :data:`ATTESTATION_FILE` into every output
+directory. It records what went in, namely the hash of the source tree, the
+config file, the tool version, the commit, and the options that shape the
+output, and it records the SHA-256 of every file that came out.
+
+Because output is byte-stable, anyone with the commit can check a published
+site: ``autodoc generate --verify`` renders the checkout again in memory and
+compares both halves with the attestation in ``--output``
+(:func:`verify_site`). A site passes when its files are the ones recorded, and
+the recorded inputs and outputs are the ones the checkout produces.
+
+The source hash covers the Python files that were documented, by path and
+content; other inputs, such as images or a theme, are covered by comparing
+the rebuilt output.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import json
+from collections.abc import Iterable, Sequence
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+from services.doc_caching import NETLIFY_HEADERS_FILE, S3_METADATA_FILE
+from services.doc_site import SitePage
+
+ATTESTATION_FILE = ".autodoc-attestation.json"
+ATTESTATION_VERSION = 1
+
+
+class AttestationError(Exception):
+    """Raised when an attestation is missing or cannot be read."""
+
+
+def _sha256(data: str | bytes) -> str:
+    if isinstance(data, str):
+        data = data.encode("utf-8")
+    return hashlib.sha256(data).hexdigest()
+
+
+def tree_hash(root: str | Path, files: Iterable[Path]) -> str:
+    """Digest of ``files`` by their path below ``root`` and their content."""
+    base = Path(root)
+    if base.is_file():
+        base = base.parent
+    digest = hashlib.sha256()
+    for path in sorted(files, key=lambda p: p.relative_to(base).as_posix()):
+        relative = path.relative_to(base).as_posix()
+        digest.update(f"{relative}\0{_sha256(path.read_bytes())}\n".encode())
+    return digest.hexdigest()
+
+
+def file_hash(path: str | Path | None) -> str | None:
+    """SHA-256 of the file at ``path``, or ``None`` when there is none."""
+    if path is None or not Path(path).is_file():
+        return None
+    return _sha256(Path(path).read_bytes())
+
+
+@dataclass
+class Attestation:
+    """The inputs and outputs of one generated site."""
+
+    tool_version: str
+    source_tree: str
+    config: str | None = None
+    commit: str | None = None
+    dirty: bool = False
+    # Options that shape the output, such as the formats and the language.
+    settings: dict[str, Any] = field(default_factory=dict)
+    # Output path -> SHA-256, for every generated file but the listings.
+    outputs: dict[str, str] = field(default_factory=dict)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> Attestation:
+        inputs = data.get("inputs", {})
+        return cls(
+            tool_version=data.get("tool", {}).get("version", ""),
+            source_tree=inputs.get("source_tree", ""),
+            config=inputs.get("config"),
+            commit=inputs.get("commit"),
+            dirty=bool(inputs.get("dirty", False)),
+            settings=inputs.get("settings", {}),
+            outputs=data.get("outputs", {}),
+        )
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "schema_version": ATTESTATION_VERSION,
+            "tool": {"name": "autodoc", "version": self.tool_version},
+            "inputs": {
+                "commit": self.commit,
+                "config": self.config,
+                "dirty": self.dirty,
+                "settings": self.settings,
+                "source_tree": self.source_tree,
+            },
+            "outputs": dict(sorted(self.outputs.items())),
+        }
+
+    def with_outputs(self, pages: Sequence[SitePage]) -> Attestation:
+        """A copy recording ``pages`` as the outputs."""
+        outputs = {page.path: _sha256(page.content) for page in pages}
+        return Attestation(
+            self.tool_version,
+            self.source_tree,
+            self.config,
+            self.commit,
+            self.dirty,
+            dict(self.settings),
+            outputs,
+        )
+
+    def page(self) -> SitePage:
+        """The attestation as a page to write beside the site."""
+        text = json.dumps(self.to_dict(), indent=2, sort_keys=True) + "\n"
+        return SitePage(ATTESTATION_FILE, text)
+
+
+def load_attestation(directory: str | Path) -> Attestation:
+    """Read the attestation of the site in ``directory``.
+
+    Raises:
+        AttestationError: If there is none, or it is not valid
+    """
+    path = Path(directory) / ATTESTATION_FILE
+    try:
+        data = json.loads(path.read_text(encoding="utf-8"))
+    except FileNotFoundError as exc:
+        raise AttestationError(
+            f"{directory} has no {ATTESTATION_FILE}; was it written by "
+            "'autodoc generate'?",
+        ) from exc
+    except (OSError, ValueError) as exc:
+        raise AttestationError(f"Cannot read {path}: {exc}") from exc
+    if not isinstance(data, dict) or data.get("schema_version") != ATTESTATION_VERSION:
+        raise AttestationError(
+            f"{path} is not a version {ATTESTATION_VERSION} attestation",
+        )
+    return Attestation.from_dict(data)
+
+
+@dataclass(frozen=True)
+class Mismatch:
+    """One way a site differs from its attestation or from a rebuild."""
+
+    subject: str
+    message: str
+
+    def format(self) -> str:
+        return f"{self.subject}: {self.message}"
+
+
+# Header manifests that ``autodoc publish`` uses but does not upload to a
+# bucket, so a downloaded copy of the site lacks them.
+_NOT_UPLOADED = frozenset({NETLIFY_HEADERS_FILE, S3_METADATA_FILE})
+
+_INPUTS = (
+    ("commit", "commit"),
+    ("source_tree", "source tree"),
+    ("config", "config file"),
+    ("tool_version", "autodoc version"),
+    ("settings", "options"),
+)
+
+
+def verify_site(
+    directory: str | Path,
+    recorded: Attestation,
+    rebuilt: Attestation,
+) -> list[Mismatch]:
+    """Compare the site in ``directory`` with its attestation and a rebuild.
+
+    Args:
+        directory: The published site, as downloaded or checked out
+        recorded: Its attestation, from :func:`load_attestation`
+        rebuilt: The attestation of the site rendered again from a checkout
+    """
+    root = Path(directory)
+    problems = []
+    for name, label in _INPUTS:
+        was, now = getattr(recorded, name), getattr(rebuilt, name)
+        if was != now:
+            message = f"built from {_short(was)}, checkout has {_short(now)}"
+            problems.append(Mismatch(label, message))
+    if rebuilt.dirty:
+        problems.append(Mismatch("commit", "the checkout has uncommitted changes"))
+    for path, digest in sorted(recorded.outputs.items()):
+        target = root / path
+        if not target.is_file() and path in _NOT_UPLOADED:
+            continue
+        if not target.is_file():
+            problems.append(Mismatch(path, "recorded but missing from the site"))
+        elif _sha256(target.read_bytes()) != digest:
+            problems.append(Mismatch(path, "differs from the recorded hash"))
+    for path in sorted(set(recorded.outputs) | set(rebuilt.outputs)):
+        if path not in recorded.outputs:
+            problems.append(Mismatch(path, "rebuilt but not recorded"))
+        elif path not in rebuilt.outputs:
+            problems.append(Mismatch(path, "recorded but not rebuilt"))
+        elif recorded.outputs[path] != rebuilt.outputs[path]:
+            problems.append(Mismatch(path, "the rebuild has different content"))
+    return problems
+
+
+def _short(value: Any) -> str:
+    """``value`` for messages; hashes are shortened like git's."""
+    if value is None:
+        return "none"
+    if not isinstance(value, str):
+        return json.dumps(value, sort_keys=True)
+    return value[:12] if len(value) in (40, 64) else value
+
+
+__all__ = [
+    "ATTESTATION_FILE",
+    "ATTESTATION_VERSION",
+    "Attestation",
+    "AttestationError",
+    "Mismatch",
+    "file_hash",
+    "load_attestation",
+    "tree_hash",
+    "verify_site",
+]
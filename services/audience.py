@@ -0,0 +1,116 @@
+"""Audience tags, for building internal and public docs from one tree.
+
+A symbol meant for some readers only says so in its docstring::
+
+    def rotate_keys():
+        \"\"\"Rotate the signing keys.
+
+        @autodoc:audience internal
+        \"\"\"
+
+The tag names one or more audiences (``@autodoc:audience internal,
+partners``). It applies to the symbols inside the tagged one, too: a tag on
+a class covers its methods, one in a module docstring the module, and one
+in an ``__init__.py`` docstring the whole package, subpackages included.
+A symbol is for an audience only if every tag on it and around it names
+that audience, so a tag inside an ``internal`` module cannot publish part of
+it. Untagged symbols are for everyone.
+
+``autodoc generate`` documents every symbol, for internal readers;
+``autodoc generate --audience public`` leaves out the symbols not tagged for
+``public`` (:func:`for_audience`), for the external site. Tags are stripped
+from the docstrings either way (:func:`strip_audience`).
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+from collections.abc import Iterable
+
+from autodoc.parser import ParsedTree
+from services.doc_symbols import DocSymbol
+
+logger = logging.getLogger(__name__)
+
+# An audience name, as ``--audience`` and the tags give it.
+AUDIENCE_NAME = re.compile(r"^[A-Za-z][\w-]*$")
+_DIRECTIVE = re.compile(r"^\s*@autodoc:audience(?:\s+(?P<names>.*))?$")
+
+
+def parse_audience(docstring: str | None) -> list[str] | None:
+    """The audiences the tags of ``docstring`` name; ``None`` if it has none."""
+    if not docstring or "@autodoc:audience" not in docstring:
+        return None
+    names: list[str] | None = None
+    for line in docstring.split("\n"):
+        match = _DIRECTIVE.match(line)
+        if match is None:
+            continue
+        names = names or []
+        for name in re.split(r"[\s,]+", match["names"] or ""):
+            if AUDIENCE_NAME.match(name) and name.lower() not in names:
+                names.append(name.lower())
+    return names
+
+
+def strip_audience(docstring: str | None) -> str | None:
+    """``docstring`` without its tags; ``None`` if nothing else is left."""
+    if not docstring or "@autodoc:audience" not in docstring:
+        return docstring
+    rest = [line for line in docstring.split("\n") if not _DIRECTIVE.match(line)]
+    text = re.sub(r"\n{3,}", "\n\n", "\n".join(rest)).strip()
+    return text or None
+
+
+def audience_tags(symbols: Iterable[DocSymbol]) -> dict[str, list[str]]:
+    """Qualified name -> the audiences tagged on the symbol, for tagged ones.
+
+    Tags naming no audience are logged and left out.
+    """
+    tags: dict[str, list[str]] = {}
+    for symbol in symbols:
+        names = parse_audience(symbol.docstring)
+        if names:
+            tags[symbol.qualified_name] = names
+        elif names is not None:
+            logger.warning(
+                "Ignoring @autodoc:audience without an audience at %s:%d",
+                symbol.file_path,
+                symbol.lineno,
+            )
+    return tags
+
+
+def is_for(name: str, tags: dict[str, list[str]], audience: str) -> bool:
+    """Whether the symbol ``name`` is for ``audience`` under ``tags``."""
+    parts = name.split(".")
+    return all(
+        audience.lower() in tags.get(".".join(parts[:end]), [audience.lower()])
+        for end in range(1, len(parts) + 1)
+    )
+
+
+def for_audience(tree: ParsedTree, audience: str) -> ParsedTree:
+    """``tree`` without the symbols, and their modules, not for ``audience``."""
+    tags = audience_tags(tree.symbols)
+    if not tags:
+        return tree
+    symbols = [
+        symbol
+        for symbol in tree.symbols
+        if is_for(symbol.qualified_name, tags, audience)
+    ]
+    hidden = {name for name in tree.graph.modules if not is_for(name, tags, audience)}
+    graph = tree.graph.without(hidden) if hidden else tree.graph
+    return ParsedTree(tree.root, symbols, graph)
+
+
+__all__ = [
+    "AUDIENCE_NAME",
+    "audience_tags",
+    "for_audience",
+    "is_for",
+    "parse_audience",
+    "strip_audience",
+]
@@ -0,0 +1,380 @@
+"""Publish a generated site to an S3 or Google Cloud Storage bucket.
+
+:func:`sync_site` makes a bucket prefix mirror a site directory, like
+``aws s3 sync --delete``: files whose content changed are uploaded, keys with
+no local file are deleted, and identical files are left alone. Content is
+compared by MD5, which both stores report for single-part uploads.
+
+Each upload gets a ``Content-Type`` and, when the site was generated with
+``site.caching.headers: [s3]``, the ``Cache-Control`` of ``_s3-metadata.json``
+(see :mod:`services.doc_caching`). The SDKs (``boto3``,
+``google-cloud-storage``) are imported only when a bucket of their kind is
+used, so neither is a dependency of AutoDoc itself.
+"""
+
+from __future__ import annotations
+
+import base64
+import hashlib
+import json
+import logging
+from collections.abc import Callable
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any, Protocol
+
+from services.cancellation import CancelToken
+from services.doc_caching import NETLIFY_HEADERS_FILE, S3_METADATA_FILE, content_type
+from services.doc_site import SITE_FILES
+from services.incremental import STATE_FILE
+from services.telemetry import count, span
+from services.write_plan import CREATE, DELETE, MODIFY, UNCHANGED
+
+logger = logging.getLogger(__name__)
+
+BUCKET_SCHEMES = ("s3", "gs")
+
+# Bookkeeping of ``generate`` and manifests a bucket has no use for.
+_NOT_PUBLISHED = frozenset(
+    {SITE_FILES, STATE_FILE, S3_METADATA_FILE, NETLIFY_HEADERS_FILE},
+)
+
+
+class PublishError(Exception):
+    """Raised when a site cannot be read or a bucket operation fails."""
+
+
+class ObjectStoreProtocol(Protocol):
+    """The bucket operations used by :func:`sync_site`."""
+
+    # Keys are published below this prefix ("" or ending with "/").
+    prefix: str
+
+    def url(self, key: str) -> str:
+        """The ``s3://`` or ``gs://`` URL of ``key``, for messages."""
+        ...
+
+    def list_objects(self) -> dict[str, str]:
+        """Every key below the prefix, with the hex MD5 of its content.
+
+        The MD5 is ``""`` when the store does not know it, as for S3
+        multipart uploads.
+        """
+        ...
+
+    def upload(self, key: str, data: bytes, headers: dict[str, str]) -> None:
+        """Store ``data`` at ``key`` with ``ContentType``/``CacheControl``."""
+        ...
+
+    def delete(self, keys: list[str]) -> None: ...
+
+
+def _prefix(prefix: str) -> str:
+    prefix = prefix.strip("/")
+    return f"{prefix}/" if prefix else ""
+
+
+class S3Store:
+    """A prefix of an S3 bucket; pass ``client`` to use a configured boto3 client."""
+
+    def __init__(self, bucket: str, prefix: str = "", client: Any = None) -> None:
+        self.bucket = bucket
+        self.prefix = _prefix(prefix)
+        self._errors: tuple[type[Exception], ...] = ()
+        if client is None:
+            try:
+                import boto3
+                from botocore.exceptions import BotoCoreError, ClientError
+            except ImportError as exc:
+                raise PublishError(
+                    "Publishing to S3 needs boto3 (pip install boto3)",
+                ) from exc
+            client = boto3.client("s3")
+            self._errors = (BotoCoreError, ClientError)
+        self._client = client
+
+    def url(self, key: str) -> str:
+        return f"s3://{self.bucket}/{key}"
+
+    def list_objects(self) -> dict[str, str]:
+        objects = {}
+        try:
+            pages = self._client.get_paginator("list_objects_v2").paginate(
+                Bucket=self.bucket,
+                Prefix=self.prefix,
+            )
+            for page in pages:
+                for item in page.get("Contents", []):
+                    etag = item.get("ETag", "").strip('"')
+                    # Multipart ETags ("<md5>-<parts>") are not content hashes.
+                    objects[item["Key"]] = "" if "-" in etag else etag
+        except self._errors as exc:
+            raise PublishError(f"Cannot list {self.url(self.prefix)}: {exc}") from exc
+        return objects
+
+    def upload(self, key: str, data: bytes, headers: dict[str, str]) -> None:
+        try:
+            self._client.put_object(Bucket=self.bucket, Key=key, Body=data, **headers)
+        except self._errors as exc:
+            raise PublishError(f"Cannot upload {self.url(key)}: {exc}") from exc
+
+    def delete(self, keys: list[str]) -> None:
+        try:
+            # DeleteObjects takes at most 1000 keys per request.
+            for start in range(0, len(keys), 1000):
+                batch = keys[start : start + 1000]
+                self._client.delete_objects(
+                    Bucket=self.bucket,
+                    Delete={"Objects": [{"Key": key} for key in batch]},
+                )
+        except self._errors as exc:
+            raise PublishError(
+                f"Cannot delete from {self.url(self.prefix)}: {exc}",
+            ) from exc
+
+
+class GcsStore:
+    """A prefix of a Cloud Storage bucket; ``client`` is a ``storage.Client``."""
+
+    def __init__(self, bucket: str, prefix: str = "", client: Any = None) -> None:
+        self.bucket = bucket
+        self.prefix = _prefix(prefix)
+        self._errors: tuple[type[Exception], ...] = ()
+        if client is None:
+            try:
+                from google.api_core.exceptions import GoogleAPIError
+                from google.cloud import storage
+            except ImportError as exc:
+                raise PublishError(
+                    "Publishing to Cloud Storage needs google-cloud-storage "
+                    "(pip install google-cloud-storage)",
+                ) from exc
+            client = storage.Client()
+            self._errors = (GoogleAPIError,)
+        self._client = client
+        self._bucket = client.bucket(bucket)
+
+    def url(self, key: str) -> str:
+        return f"gs://{self.bucket}/{key}"
+
+    def list_objects(self) -> dict[str, str]:
+        try:
+            blobs = self._client.list_blobs(self.bucket, prefix=self.prefix)
+            # Cloud Storage reports the MD5 base64-encoded.
+            return {
+                blob.name: base64.b64decode(blob.md5_hash or "").hex()
+                for blob in blobs
+            }
+        except self._errors as exc:
+            raise PublishError(f"Cannot list {self.url(self.prefix)}: {exc}") from exc
+
+    def upload(self, key: str, data: bytes, headers: dict[str, str]) -> None:
+        blob = self._bucket.blob(key)
+        blob.cache_control = headers.get("CacheControl")
+        try:
+            blob.upload_from_string(data, content_type=headers["ContentType"])
+        except self._errors as exc:
+            raise PublishError(f"Cannot upload {self.url(key)}: {exc}") from exc
+
+    def delete(self, keys: list[str]) -> None:
+        try:
+            for key in keys:
+                self._bucket.blob(key).delete()
+        except self._errors as exc:
+            raise PublishError(
+                f"Cannot delete from {self.url(self.prefix)}: {exc}",
+            ) from exc
+
+
+def open_store(url: str) -> S3Store | GcsStore:
+    """The store for an ``s3://bucket/prefix`` or ``gs://bucket/prefix`` URL."""
+    scheme, sep, rest = url.partition("://")
+    bucket, _, prefix = rest.partition("/")
+    if not sep or scheme not in BUCKET_SCHEMES or not bucket:
+        raise PublishError(
+            f"{url!r} is not a bucket URL like s3://bucket/prefix or "
+            "gs://bucket/prefix",
+        )
+    return S3Store(bucket, prefix) if scheme == "s3" else GcsStore(bucket, prefix)
+
+
+@dataclass(frozen=True)
+class SyncChange:
+    """What a sync does to one key."""
+
+    key: str
+    action: str
+
+    def to_dict(self) -> dict[str, Any]:
+        return {"key": self.key, "action": self.action}
+
+
+@dataclass
+class SyncPlan:
+    """The changes of one sync, in key order."""
+
+    url: str
+    changes: list[SyncChange] = field(default_factory=list)
+
+    def counts(self) -> dict[str, int]:
+        counts = dict.fromkeys((CREATE, MODIFY, DELETE, UNCHANGED), 0)
+        for change in self.changes:
+            counts[change.action] += 1
+        return counts
+
+    def lines(self, url: Callable[[str], str]) -> list[str]:
+        """``would create <url>`` lines for the keys that would change."""
+        return [
+            f"would {change.action} {url(change.key)}"
+            for change in self.changes
+            if change.action != UNCHANGED
+        ]
+
+    def summary(self, dry_run: bool = False) -> str:
+        counts = self.counts()
+        text = (
+            f"{counts[CREATE]} to create, {counts[MODIFY]} to modify, "
+            f"{counts[DELETE]} to delete, {counts[UNCHANGED]} unchanged"
+        )
+        if dry_run:
+            return f"Dry run: {text}; nothing was published"
+        return (
+            f"Published to {self.url}: {counts[CREATE]} created, "
+            f"{counts[MODIFY]} modified, {counts[DELETE]} deleted, "
+            f"{counts[UNCHANGED]} unchanged"
+        )
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "url": self.url,
+            "changes": [change.to_dict() for change in self.changes],
+            "counts": self.counts(),
+        }
+
+
+def site_files(directory: str | Path) -> dict[str, Path]:
+    """The files to publish from ``directory``, by POSIX path relative to it.
+
+    Raises:
+        PublishError: If ``directory`` is not a site written by ``generate``
+    """
+    root = Path(directory)
+    if not (root / SITE_FILES).is_file():
+        raise PublishError(
+            f"{root} is not a generated site (no {SITE_FILES}); run "
+            "'autodoc generate' first, and with several formats pick one "
+            "format's directory",
+        )
+    return {
+        path.relative_to(root).as_posix(): path
+        for path in sorted(root.rglob("*"))
+        if path.is_file() and path.relative_to(root).as_posix() not in _NOT_PUBLISHED
+    }
+
+
+def _object_headers(directory: Path) -> dict[str, dict[str, str]]:
+    """Per-file headers from the site's ``_s3-metadata.json``, if any."""
+    path = directory / S3_METADATA_FILE
+    if not path.is_file():
+        return {}
+    try:
+        return json.loads(path.read_text(encoding="utf-8")).get("objects", {})
+    except (OSError, ValueError, AttributeError) as exc:
+        raise PublishError(f"Cannot read {path}: {exc}") from exc
+
+
+def plan_sync(
+    local: dict[str, bytes],
+    remote: dict[str, str],
+    prefix: str,
+    url: str,
+    delete: bool = True,
+    force: bool = False,
+) -> SyncPlan:
+    """Compare local file contents with the MD5s of a bucket prefix."""
+    changes = []
+    for path, data in local.items():
+        key = prefix + path
+        digest = hashlib.md5(data, usedforsecurity=False).hexdigest()
+        if key not in remote:
+            action = CREATE
+        elif force or remote[key] != digest:
+            action = MODIFY
+        else:
+            action = UNCHANGED
+        changes.append(SyncChange(key, action))
+    if delete:
+        keys = {prefix + path for path in local}
+        changes.extend(SyncChange(key, DELETE) for key in remote if key not in keys)
+    return SyncPlan(url, sorted(changes, key=lambda change: change.key))
+
+
+def sync_site(
+    directory: str | Path,
+    store: ObjectStoreProtocol,
+    delete: bool = True,
+    force: bool = False,
+    dry_run: bool = False,
+    cancel: CancelToken | None = None,
+) -> SyncPlan:
+    """Make the bucket prefix of ``store`` mirror the site in ``directory``.
+
+    Args:
+        directory: Output directory of ``autodoc generate``
+        store: Where to publish
+        delete: Delete keys below the prefix that have no local file
+        force: Upload unchanged files too, to refresh their headers
+        dry_run: Only plan the changes
+        cancel: Stops the sync between two uploads when cancelled
+
+    Raises:
+        PublishError: If the site cannot be read or a bucket operation fails
+    """
+    root = Path(directory)
+    files = site_files(root)
+    headers = _object_headers(root)
+    try:
+        local = {path: file.read_bytes() for path, file in files.items()}
+    except OSError as exc:
+        raise PublishError(f"Cannot read {root}: {exc}") from exc
+    prefix = store.prefix
+    remote = store.list_objects()
+    plan = plan_sync(local, remote, prefix, store.url(prefix), delete, force)
+    if dry_run:
+        return plan
+
+    by_key = {prefix + path: path for path in local}
+    uploads = [change for change in plan.changes if change.action in (CREATE, MODIFY)]
+    deletes = [change.key for change in plan.changes if change.action == DELETE]
+    with span("autodoc.publish", target=plan.url, objects=len(uploads) + len(deletes)):
+        for done, change in enumerate(uploads):
+            if cancel is not None and cancel.cancelled:
+                cancel.skip("publish", len(uploads) - done + len(deletes))
+                return plan
+            path = by_key[change.key]
+            object_headers = {"ContentType": content_type(path)}
+            object_headers.update(headers.get(path, {}))
+            store.upload(change.key, local[path], object_headers)
+            count("autodoc.objects.published", action=change.action)
+            logger.debug("Uploaded %s", store.url(change.key))
+        # Deleting last keeps every link working while the sync runs.
+        if deletes:
+            store.delete(deletes)
+            count("autodoc.objects.published", len(deletes), action=DELETE)
+            logger.debug("Deleted %d object(s) below %s", len(deletes), plan.url)
+    return plan
+
+
+__all__ = [
+    "BUCKET_SCHEMES",
+    "GcsStore",
+    "ObjectStoreProtocol",
+    "PublishError",
+    "S3Store",
+    "SyncChange",
+    "SyncPlan",
+    "open_store",
+    "plan_sync",
+    "site_files",
+    "sync_site",
+]
@@ -0,0 +1,202 @@
+"""Module-level values that are set when the code is built or deployed.
+
+Python's counterpart of ``-ldflags -X main.version=...`` is a module-level
+variable that a release step fills in: a placeholder substituted by the
+build, an environment variable read at start-up, or the version recorded in
+the installed package's metadata::
+
+    __version__ = "0.0.0.dev0"
+    COMMIT = os.environ.get("GIT_COMMIT", "unknown")
+    BUILD_DATE = "@BUILD_DATE@"
+    VERSION = importlib.metadata.version("shop")
+
+:func:`find_build_constants` reports the module-level variables whose name
+matches :data:`DEFAULT_NAMES` (or the extra ``fnmatch`` patterns passed
+in, compared without regard to case) and whose value is one of those, with
+its default: the literal, the fallback of the environment lookup, or none.
+Other values (tuples, computed expressions) are ordinary constants.
+"""
+
+from __future__ import annotations
+
+import ast
+import fnmatch
+import re
+from collections.abc import Callable, Iterable
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+DEFAULT_NAMES = (
+    "__version__",
+    "version",
+    "*_version",
+    "commit",
+    "*_commit",
+    "commit_*",
+    "*_sha",
+    "git_*",
+    "revision",
+    "build_*",
+    "release",
+)
+# How a value gets set, in the order pages explain them.
+SOURCES = ("literal", "placeholder", "environment", "metadata")
+ENVIRONMENT_LOOKUPS = frozenset({"os.environ.get", "os.getenv"})
+METADATA_LOOKUPS = frozenset(
+    {
+        "importlib.metadata.version",
+        "importlib_metadata.version",
+        "pkg_resources.get_distribution",
+    },
+)
+# "@VERSION@", "${GIT_COMMIT}", "{{ version }}", "%VERSION%", "$Format:%H$".
+_PLACEHOLDER = re.compile(
+    r"^(?:@\w+@|\$\{\w+\}|\{\{\s*[\w.]+\s*\}\}|%\w+%|\$Format:[^$]*\$)$",
+)
+
+
+@dataclass(frozen=True)
+class BuildConstant:
+    """A variable filled in at build or deploy time."""
+
+    module: str
+    name: str
+    file_path: str
+    lineno: int
+    # One of :data:`SOURCES`.
+    source: str
+    # The value when nothing is injected, as Python source.
+    default: str | None = None
+    # The environment variable or distribution the value comes from.
+    origin: str | None = None
+
+    @property
+    def qualified_name(self) -> str:
+        return f"{self.module}.{self.name}"
+
+    def setter(self, code: Callable[[str], str]) -> str:
+        """How the value is set, with names formatted by ``code``."""
+        if self.source == "placeholder":
+            return "placeholder substituted by the build"
+        if self.source == "environment":
+            variable = code(self.origin) if self.origin else "an"
+            return f"{variable} environment variable"
+        if self.source == "metadata":
+            distribution = f" of {code(self.origin)}" if self.origin else ""
+            return f"installed package metadata{distribution}"
+        return "literal rewritten by the build"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "module": self.module,
+            "name": self.name,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "source": self.source,
+            "default": self.default,
+            "origin": self.origin,
+        }
+
+
+def matches(name: str, patterns: Iterable[str] = DEFAULT_NAMES) -> bool:
+    """Whether the variable ``name`` matches one of ``patterns``, ignoring case."""
+    return any(fnmatch.fnmatchcase(name.lower(), p.lower()) for p in patterns)
+
+
+def _literal(node: ast.expr | None) -> str | None:
+    if isinstance(node, ast.Constant) and isinstance(node.value, (str, int)):
+        if not isinstance(node.value, bool):
+            return repr(node.value)
+    return None
+
+
+def _value(
+    node: ast.expr,
+    info: ModuleImports,
+) -> tuple[str, str | None, str | None] | None:
+    """``(source, default, origin)`` of a value set at build time, if it is one."""
+    if isinstance(node, ast.Constant):
+        literal = _literal(node)
+        if literal is None:
+            return None
+        if isinstance(node.value, str) and _PLACEHOLDER.match(node.value):
+            return "placeholder", literal, None
+        return "literal", literal, None
+    if isinstance(node, ast.Subscript):
+        if resolve_name(node.value, info) == "os.environ":
+            return "environment", None, _string(node.slice)
+        return None
+    if not isinstance(node, ast.Call) or not node.args:
+        return None
+    func = resolve_name(node.func, info)
+    if func in ENVIRONMENT_LOOKUPS:
+        default = _literal(node.args[1]) if len(node.args) > 1 else None
+        return "environment", default, _string(node.args[0])
+    if func in METADATA_LOOKUPS:
+        return "metadata", None, _string(node.args[0])
+    return None
+
+
+def _string(node: ast.expr) -> str | None:
+    if isinstance(node, ast.Constant) and isinstance(node.value, str):
+        return node.value
+    return None
+
+
+def find_build_constants(
+    root: str | Path,
+    graph: ImportGraph,
+    names: Iterable[str] = (),
+) -> list[BuildConstant]:
+    """Build-time variables of the modules of ``graph`` (built for ``root``).
+
+    ``names`` adds patterns to :data:`DEFAULT_NAMES`. Constants are sorted
+    by module and line.
+    """
+    patterns = (*DEFAULT_NAMES, *names)
+    found = []
+    for info, tree in parse_modules(graph, "build constant detection"):
+        for node in tree.body:
+            if isinstance(node, ast.Assign) and len(node.targets) == 1:
+                target, value = node.targets[0], node.value
+            elif isinstance(node, ast.AnnAssign) and node.value is not None:
+                target, value = node.target, node.value
+            else:
+                continue
+            if not isinstance(target, ast.Name) or not matches(target.id, patterns):
+                continue
+            if isinstance(value, ast.Attribute) and value.attr == "version":
+                # pkg_resources.get_distribution("shop").version
+                value = value.value
+            described = _value(value, info)
+            if described is None:
+                continue
+            found.append(
+                BuildConstant(
+                    info.module,
+                    target.id,
+                    relative_path(info.file_path, root),
+                    node.lineno,
+                    *described,
+                ),
+            )
+    return sorted(found, key=lambda constant: (constant.module, constant.lineno))
+
+
+__all__ = [
+    "DEFAULT_NAMES",
+    "ENVIRONMENT_LOOKUPS",
+    "METADATA_LOOKUPS",
+    "SOURCES",
+    "BuildConstant",
+    "find_build_constants",
+    "matches",
+]
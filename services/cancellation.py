@@ -0,0 +1,114 @@
+"""Cooperative cancellation for the documentation pipeline.
+
+A :class:`CancelToken` is handed to every stage that loops over files (symbol
+loading, import analysis). Each stage checks the token before the next item
+and, once it is cancelled, stops and records how much work it skipped instead
+of raising. Whatever was finished is still reported or written, so a timeout
+or Ctrl-C leaves complete (if partial) results rather than a truncated file.
+
+The token is cancelled by its deadline (``--timeout``) or by SIGINT while
+:func:`handle_interrupts` is active. A second SIGINT raises
+:class:`KeyboardInterrupt` as usual, for when a stage does not get back to the
+token quickly enough.
+"""
+
+from __future__ import annotations
+
+import logging
+import signal
+import threading
+import time
+from collections.abc import Iterator
+from contextlib import contextmanager
+from dataclasses import dataclass, field
+
+logger = logging.getLogger(__name__)
+
+# Exit codes for a partial run, matching the shell (128 + SIGINT) and the
+# coreutils ``timeout`` command.
+EXIT_INTERRUPTED = 130
+EXIT_TIMED_OUT = 124
+
+INTERRUPTED = "interrupted"
+
+
+@dataclass
+class CancelToken:
+    """Cancellation state shared by the stages of one command."""
+
+    timeout: float | None = None
+    started: float = field(default_factory=time.monotonic)
+    reason: str | None = None
+    skipped: dict[str, int] = field(default_factory=dict)
+
+    @property
+    def cancelled(self) -> bool:
+        """Whether work should stop (checks the deadline)."""
+        if (
+            self.reason is None
+            and self.timeout is not None
+            and time.monotonic() - self.started >= self.timeout
+        ):
+            self.reason = f"timed out after {self.timeout:g}s"
+            logger.info("Cancelling: %s", self.reason)
+        return self.reason is not None
+
+    @property
+    def partial(self) -> bool:
+        """Whether any stage skipped work because of cancellation."""
+        return bool(self.skipped)
+
+    @property
+    def exit_code(self) -> int:
+        return EXIT_INTERRUPTED if self.reason == INTERRUPTED else EXIT_TIMED_OUT
+
+    def cancel(self, reason: str = INTERRUPTED) -> None:
+        """Cancel the token; the first reason sticks."""
+        if self.reason is None:
+            self.reason = reason
+            logger.info("Cancelling: %s", reason)
+
+    def skip(self, stage: str, count: int) -> None:
+        """Record that ``stage`` skipped ``count`` items."""
+        if count:
+            self.skipped[stage] = self.skipped.get(stage, 0) + count
+
+    def summary(self) -> str:
+        """One line describing why the run stopped and what it skipped."""
+        skipped = ", ".join(
+            f"{count} file(s) in {stage}" for stage, count in self.skipped.items()
+        )
+        skipped = skipped or "nothing"
+        return f"{(self.reason or 'cancelled').capitalize()}: skipped {skipped}"
+
+
+@contextmanager
+def handle_interrupts(token: CancelToken) -> Iterator[CancelToken]:
+    """Turn the first SIGINT into ``token.cancel()`` for the duration.
+
+    Signal handlers can only be installed from the main thread; elsewhere
+    this is a no-op and SIGINT keeps its default behaviour.
+    """
+    if threading.current_thread() is not threading.main_thread():
+        yield token
+        return
+
+    def _on_sigint(signum: int, frame: object) -> None:
+        if token.reason == INTERRUPTED:
+            raise KeyboardInterrupt
+        token.cancel(INTERRUPTED)
+
+    previous = signal.signal(signal.SIGINT, _on_sigint)
+    try:
+        yield token
+    finally:
+        signal.signal(signal.SIGINT, previous)
+
+
+__all__ = [
+    "EXIT_INTERRUPTED",
+    "EXIT_TIMED_OUT",
+    "INTERRUPTED",
+    "CancelToken",
+    "handle_interrupts",
+]
@@ -0,0 +1,70 @@
+"""Diff-scoped enforcement: restrict checks to symbols touched on a branch.
+
+Legacy documentation debt should not block unrelated pull requests, while new
+or edited code is still held to the standard. A symbol is *touched* when any
+line of its definition (``lineno`` through ``end_lineno``) falls inside a
+changed hunk between the merge base and ``HEAD``.
+"""
+
+from __future__ import annotations
+
+import logging
+import os
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from pathlib import Path, PurePosixPath
+
+from services.doc_symbols import DocSymbol
+from services.git_source import changed_line_ranges
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class ChangedScope:
+    """Changed line ranges per repository-relative file path."""
+
+    repo: Path
+    ranges: dict[str, list[tuple[int, int]]] = field(default_factory=dict)
+
+    @property
+    def files(self) -> list[str]:
+        return sorted(self.ranges)
+
+    def _relative(self, file_path: str) -> str:
+        path = Path(file_path).resolve()
+        try:
+            return path.relative_to(self.repo.resolve()).as_posix()
+        except ValueError:
+            return PurePosixPath(os.path.relpath(path, self.repo.resolve())).as_posix()
+
+    def touches(self, symbol: DocSymbol) -> bool:
+        """Whether any changed line falls within ``symbol``'s definition."""
+        ranges = self.ranges.get(self._relative(symbol.file_path))
+        if not ranges:
+            return False
+        end = max(symbol.end_lineno, symbol.lineno)
+        return any(start <= end and symbol.lineno <= stop for start, stop in ranges)
+
+    def touches_line(self, file_path: str, lineno: int) -> bool:
+        """Whether line ``lineno`` of ``file_path`` was changed."""
+        ranges = self.ranges.get(self._relative(file_path), [])
+        return any(start <= lineno <= stop for start, stop in ranges)
+
+    def filter(self, symbols: Iterable[DocSymbol]) -> list[DocSymbol]:
+        """Keep only the symbols touched by the change."""
+        return [symbol for symbol in symbols if self.touches(symbol)]
+
+
+def load_changed_scope(repo: str | Path, base: str, head: str = "HEAD") -> ChangedScope:
+    """Compute the changed scope of ``head`` relative to its merge base with ``base``."""
+    scope = ChangedScope(repo=Path(repo), ranges=changed_line_ranges(repo, base, head))
+    logger.info(
+        "Changed-only scope against %s: %d file(s)",
+        base,
+        len(scope.ranges),
+    )
+    return scope
+
+
+__all__ = ["ChangedScope", "load_changed_scope"]
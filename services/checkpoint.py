@@ -0,0 +1,204 @@
+"""Checkpoints of long ``autodoc generate`` runs (``--resume``).
+
+A run with ``--resume`` records its progress in a checkpoint directory (by
+default ``<output>/.autodoc-checkpoint``) as it goes::
+
+    checkpoint.json     format version and the fingerprint of the settings
+    analysis.jsonl      one line per analyzed source file: its SHA-256 and
+                        its symbols or imports
+    pages/<format>/     every package page, as soon as it is rendered
+
+Lines are appended and pages written one at a time, so a run that is killed
+(a CI timeout, a reclaimed spot instance) loses at most the file or page it
+was working on; imports, the quicker pass, are recorded once it completes.
+The next run with ``--resume`` reuses the analysis of the files that did not
+change since and renders only the packages whose pages are missing in one of
+the formats; once the site is written the checkpoint is removed. A checkpoint
+written with other settings is discarded, like the incremental state (see
+:mod:`services.incremental`).
+"""
+
+from __future__ import annotations
+
+import json
+import logging
+import os
+import shutil
+from collections.abc import Iterable
+from pathlib import Path
+from typing import Any
+
+from services.doc_site import SitePage
+from services.incremental import SiteState
+
+logger = logging.getLogger(__name__)
+
+CHECKPOINT_DIR = ".autodoc-checkpoint"
+CHECKPOINT_VERSION = 1
+
+_HEADER = "checkpoint.json"
+_ANALYSIS = "analysis.jsonl"
+_PAGES = "pages"
+
+
+class Checkpoint:
+    """The progress of one run, kept in ``directory`` until it succeeds.
+
+    Use :meth:`open` to continue from an earlier checkpoint.
+    """
+
+    def __init__(self, directory: str | Path, fingerprint: str) -> None:
+        self.directory = Path(directory)
+        self.fingerprint = fingerprint
+        self.sources: dict[str, str] = {}
+        self.symbols: dict[str, list[dict[str, Any]]] = {}
+        self.imports: dict[str, dict[str, Any]] = {}
+        self.import_sources: dict[str, str] = {}
+
+    @classmethod
+    def open(cls, directory: str | Path, fingerprint: str) -> Checkpoint:
+        """The checkpoint in ``directory``, or a new one if it is missing or stale."""
+        checkpoint = cls(directory, fingerprint)
+        if not checkpoint._load():
+            checkpoint.reset()
+        return checkpoint
+
+    @property
+    def resumed(self) -> bool:
+        """Whether an earlier run left progress to continue from."""
+        return bool(self.sources or self.imports or self.pages())
+
+    def _load(self) -> bool:
+        header = self.directory / _HEADER
+        if not header.is_file():
+            return False
+        try:
+            data = json.loads(header.read_text(encoding="utf-8"))
+            lines = (self.directory / _ANALYSIS).read_text(encoding="utf-8")
+        except (OSError, ValueError) as exc:
+            logger.warning("Ignoring unreadable checkpoint %s: %s", self.directory, exc)
+            return False
+        if data.get("version") != CHECKPOINT_VERSION:
+            logger.info("Ignoring %s: written by another version", self.directory)
+            return False
+        if data.get("fingerprint") != self.fingerprint:
+            logger.info("Settings changed since %s was written; starting over", header)
+            return False
+        for number, line in enumerate(lines.splitlines(), 1):
+            try:
+                self._apply(json.loads(line))
+            except (ValueError, KeyError, TypeError) as exc:
+                # The run was most likely killed while writing this line.
+                logger.warning(
+                    "Ignoring %s line %d and after: %s",
+                    _ANALYSIS,
+                    number,
+                    exc,
+                )
+                break
+        logger.info(
+            "Resuming from %s: %d analyzed file(s), %d page(s)",
+            self.directory,
+            len(self.sources),
+            sum(len(paths) for paths in self.pages().values()),
+        )
+        return True
+
+    def _apply(self, record: dict[str, Any]) -> None:
+        rel, digest = record["file"], record["digest"]
+        if "symbols" in record:
+            self.sources[rel] = digest
+            self.symbols[rel] = list(record["symbols"])
+        else:
+            self.import_sources[rel] = digest
+            self.imports[rel] = dict(record["imports"])
+
+    def reset(self) -> None:
+        """Start an empty checkpoint, discarding any earlier one."""
+        shutil.rmtree(self.directory, ignore_errors=True)
+        (self.directory / _PAGES).mkdir(parents=True)
+        (self.directory / _ANALYSIS).write_text("", encoding="utf-8")
+        header = {"version": CHECKPOINT_VERSION, "fingerprint": self.fingerprint}
+        (self.directory / _HEADER).write_text(
+            json.dumps(header, sort_keys=True) + "\n",
+            encoding="utf-8",
+        )
+        self.sources.clear()
+        self.symbols.clear()
+        self.imports.clear()
+        self.import_sources.clear()
+
+    def analysis(self) -> SiteState:
+        """The recorded analysis, as the previous state of an analysis cache.
+
+        Imports recorded for another version of a file than its symbols are
+        left out, so that file is analyzed again.
+        """
+        imports = {
+            rel: data
+            for rel, data in self.imports.items()
+            if self.import_sources[rel] == self.sources.get(rel)
+        }
+        return SiteState(self.fingerprint, dict(self.sources), self.symbols, imports)
+
+    def _append(self, record: dict[str, Any]) -> None:
+        with (self.directory / _ANALYSIS).open("a", encoding="utf-8") as handle:
+            handle.write(json.dumps(record, sort_keys=True) + "\n")
+
+    def add_symbols(
+        self,
+        rel: str,
+        digest: str,
+        symbols: list[dict[str, Any]],
+    ) -> None:
+        """Record the symbols of the source file ``rel`` with SHA-256 ``digest``."""
+        self._append({"file": rel, "digest": digest, "symbols": symbols})
+        self.sources[rel] = digest
+        self.symbols[rel] = symbols
+
+    def add_imports(self, rel: str, digest: str, imports: dict[str, Any]) -> None:
+        """Record the imports of the source file ``rel``."""
+        self._append({"file": rel, "digest": digest, "imports": imports})
+        self.import_sources[rel] = digest
+        self.imports[rel] = imports
+
+    def save_page(self, fmt: str, page: SitePage) -> None:
+        """Keep a rendered page of ``fmt``; it is written whole or not at all."""
+        target = self.directory / _PAGES / fmt / page.path
+        target.parent.mkdir(parents=True, exist_ok=True)
+        content = page.content
+        data = content.encode("utf-8") if isinstance(content, str) else content
+        partial = target.with_name(f".{target.name}.partial")
+        partial.write_bytes(data)
+        os.replace(partial, target)
+
+    def pages(self) -> dict[str, set[str]]:
+        """Paths of the saved pages, per format."""
+        root = self.directory / _PAGES
+        if not root.is_dir():
+            return {}
+        return {
+            fmt.name: {
+                path.relative_to(fmt).as_posix()
+                for path in fmt.rglob("*")
+                if path.is_file() and not path.name.endswith(".partial")
+            }
+            for fmt in sorted(root.iterdir())
+            if fmt.is_dir()
+        }
+
+    def load_pages(self, fmt: str, paths: Iterable[str]) -> list[SitePage]:
+        """The saved pages of ``fmt`` at ``paths``."""
+        root = self.directory / _PAGES / fmt
+        return [SitePage(path, (root / path).read_bytes()) for path in paths]
+
+    def remove(self) -> None:
+        """Delete the checkpoint once the run is complete."""
+        shutil.rmtree(self.directory, ignore_errors=True)
+
+
+__all__ = [
+    "CHECKPOINT_DIR",
+    "CHECKPOINT_VERSION",
+    "Checkpoint",
+]
@@ -0,0 +1,373 @@
+"""The Python examples of docstrings and guides, and whether they work.
+
+Examples rot: a parameter is renamed, and the snippet in the docstring that
+shows it keeps the old name for years. With ``site.examples``, ``autodoc
+generate`` checks every example before writing the site and fails the
+build on broken ones. An example is:
+
+- an interactive session, the ``>>>`` lines of a docstring or of a
+  ``pycon`` block;
+- a Markdown code block marked ``python`` (or ``py``, ``python3``);
+- a reST ``.. code-block:: python`` (or ``.. code::``, ``.. sourcecode::``)
+  directive.
+
+Literal ``::`` blocks and unmarked fences may hold anything and are not
+checked; neither are fences whose info string has :data:`NOCHECK`
+(``python nocheck``) or sessions whose examples all carry ``# doctest:
++SKIP``.
+
+``compile`` mode compiles every example. ``run`` mode also runs it, each in
+a fresh interpreter in the root of the tree: a block must exit cleanly, and
+a session must print what it shows, as with :mod:`doctest` (``...``
+matches anything). Sessions of docstrings run in the globals of their
+module, as doctest runs them.
+"""
+
+from __future__ import annotations
+
+import doctest
+import os
+import re
+import subprocess
+import sys
+from collections.abc import Iterable, Iterator
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.doc_site import PackageDoc
+from services.doc_symbols import relative_path
+
+# Info strings of fenced blocks, and languages of code directives, checked.
+EXAMPLE_LANGUAGES = frozenset({"python", "py", "python3", "pycon"})
+# Info string word that leaves a fenced block unchecked.
+NOCHECK = "nocheck"
+# Seconds an example may run for in ``run`` mode.
+EXAMPLE_TIMEOUT = 30
+
+_FENCE = re.compile(r"^(?P<indent>\s*)(?P<fence>```+|~~~+)\s*(?P<info>[^`]*)$")
+_DIRECTIVE = re.compile(
+    r"^(?P<indent>\s*)\.\.\s+(?:code-block|code|sourcecode)::\s*(?P<lang>\S*)",
+)
+_PROMPT = re.compile(r"^\s*>>>(?:\s|$)")
+_TRACEBACK_LINE = re.compile(r'File "<stdin>", line (\d+)')
+
+# Runs the session on stdin in the globals of the module named by its
+# argument, if any; a failure exits with ``<line>:<message>``.
+_DOCTEST_DRIVER = """\
+import doctest
+import importlib
+import sys
+
+
+class Runner(doctest.DocTestRunner):
+    def report_failure(self, out, test, example, got):
+        want, got = example.want.strip(), got.strip()
+        sys.exit(f"{example.lineno}:printed {got!r} instead of {want!r}")
+
+    def report_unexpected_exception(self, out, test, example, exc_info):
+        sys.exit(f"{example.lineno}:raised {exc_info[0].__name__}: {exc_info[1]}")
+
+
+if len(sys.argv) > 1:
+    namespace = dict(vars(importlib.import_module(sys.argv[1])))
+else:
+    namespace = {"__name__": "__main__"}
+test = doctest.DocTestParser().get_doctest(
+    sys.stdin.read(), namespace, "example", None, 0
+)
+Runner(optionflags=doctest.ELLIPSIS).run(test, out=lambda _: None)
+"""
+
+
+@dataclass(frozen=True)
+class CodeExample:
+    """One example of a docstring or guide."""
+
+    # The qualified name of the documented symbol, or the guide's path.
+    source: str
+    file_path: str
+    # Line of the file the code starts on.
+    lineno: int
+    code: str
+    # Whether the code is an interactive session with ``>>>`` prompts.
+    session: bool
+    # The module whose globals a docstring's session runs in; None for guides.
+    module: str | None = None
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "source": self.source,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "code": self.code,
+            "session": self.session,
+            "module": self.module,
+        }
+
+
+@dataclass(frozen=True)
+class ExampleFailure:
+    """Why an example does not compile or run."""
+
+    example: CodeExample
+    # Line of the file the problem is on.
+    lineno: int
+    message: str
+
+    def format(self) -> str:
+        """Render the failure in ``path:line: message`` form."""
+        return (
+            f"{self.example.file_path}:{self.lineno}: example of "
+            f"{self.example.source} {self.message}"
+        )
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "source": self.example.source,
+            "file_path": self.example.file_path,
+            "lineno": self.lineno,
+            "message": self.message,
+        }
+
+
+def _dedent(lines: list[str]) -> str:
+    indents = [len(line) - len(line.lstrip()) for line in lines if line.strip()]
+    cut = min(indents, default=0)
+    return "\n".join(line[cut:] for line in lines).strip("\n") + "\n"
+
+
+def _leading_blank(lines: list[str]) -> int:
+    return next((i for i, line in enumerate(lines) if line.strip()), len(lines))
+
+
+def extract_examples(text: str) -> Iterator[tuple[int, str, bool]]:
+    """The examples of Markdown or reST ``text``.
+
+    Yields the index of the example's first line in ``text``, its code, and
+    whether it is an interactive session.
+    """
+    lines = text.split("\n")
+    index = 0
+    while index < len(lines):
+        line = lines[index]
+        fence = _FENCE.match(line)
+        directive = _DIRECTIVE.match(line)
+        if fence is not None:
+            end = index + 1
+            while end < len(lines) and not lines[end].strip().startswith(
+                fence["fence"],
+            ):
+                end += 1
+            words = fence["info"].split()
+            if words and words[0] in EXAMPLE_LANGUAGES and NOCHECK not in words:
+                body = lines[index + 1 : end]
+                code = _dedent(body)
+                session = words[0] == "pycon" or bool(_PROMPT.match(code))
+                yield index + 1 + _leading_blank(body), code, session
+            index = end + 1
+        elif directive is not None:
+            indent = len(directive["indent"])
+            end = index + 1
+            while end < len(lines) and (
+                not lines[end].strip()
+                or len(lines[end]) - len(lines[end].lstrip()) > indent
+            ):
+                end += 1
+            body = lines[index + 1 : end]
+            # Directive options (``:linenos:``) precede the code.
+            start = _leading_blank(body)
+            while start < len(body) and body[start].strip().startswith(":"):
+                start += 1
+            body = body[start:]
+            if directive["lang"] in EXAMPLE_LANGUAGES and any(s.strip() for s in body):
+                code = _dedent(body)
+                offset = index + 1 + start + _leading_blank(body)
+                yield offset, code, bool(_PROMPT.match(code))
+            index = end
+        elif _PROMPT.match(line):
+            end = index + 1
+            # A session ends at the first blank line, as in doctest.
+            while end < len(lines) and lines[end].strip():
+                end += 1
+            yield index, _dedent(lines[index:end]), True
+            index = end
+        else:
+            index += 1
+
+
+def _docstring_line(source: list[str], symbol_line: int, first: str) -> int:
+    """The line of ``source`` at or after ``symbol_line`` reading ``first``."""
+    first = first.strip()
+    for number in range(symbol_line, len(source) + 1):
+        if source[number - 1].strip() == first:
+            return number
+    return symbol_line
+
+
+def find_examples(
+    packages: Iterable[PackageDoc],
+    guides: str | Path | None,
+    root: str | Path,
+) -> list[CodeExample]:
+    """The examples of the packages' docstrings and of the guides directory.
+
+    Examples are located in the files they come from, with paths relative to
+    ``root``.
+    """
+    examples = []
+    sources: dict[str, list[str]] = {}
+    for package in packages:
+        modules = {m.symbol.file_path: m.symbol.qualified_name for m in package.modules}
+        for symbol in package.symbols():
+            if not symbol.docstring:
+                continue
+            for _, code, session in extract_examples(symbol.docstring):
+                if symbol.file_path not in sources:
+                    try:
+                        text = Path(symbol.file_path).read_text(encoding="utf-8")
+                    except (OSError, UnicodeDecodeError):
+                        text = ""
+                    sources[symbol.file_path] = text.split("\n")
+                first = next(line for line in code.split("\n") if line.strip())
+                examples.append(
+                    CodeExample(
+                        source=symbol.qualified_name,
+                        file_path=relative_path(symbol.file_path, root),
+                        lineno=_docstring_line(
+                            sources[symbol.file_path],
+                            symbol.lineno,
+                            first,
+                        ),
+                        code=code,
+                        session=session,
+                        module=modules.get(symbol.file_path),
+                    ),
+                )
+    directory = Path(guides) if guides else None
+    if directory is not None and directory.is_dir():
+        for path in sorted(directory.rglob("*.md")):
+            try:
+                text = path.read_text(encoding="utf-8")
+            except (OSError, UnicodeDecodeError):
+                # load_guides warns about unreadable guides.
+                continue
+            examples.extend(
+                CodeExample(
+                    source=path.relative_to(directory).as_posix(),
+                    file_path=relative_path(path, root),
+                    lineno=index + 1,
+                    code=code,
+                    session=session,
+                )
+                for index, code, session in extract_examples(text)
+            )
+    return examples
+
+
+def _compile(example: CodeExample) -> ExampleFailure | None:
+    name = f"<example of {example.source}>"
+    if not example.session:
+        try:
+            compile(example.code, name, "exec")
+        except SyntaxError as exc:
+            lineno = example.lineno + (exc.lineno or 1) - 1
+            return ExampleFailure(example, lineno, f"does not compile: {exc.msg}")
+        return None
+    for step in _steps(example):
+        try:
+            compile(step.source, name, "single")
+        except SyntaxError as exc:
+            lineno = example.lineno + step.lineno
+            return ExampleFailure(example, lineno, f"does not compile: {exc.msg}")
+    return None
+
+
+def _steps(example: CodeExample) -> list[doctest.Example]:
+    """The statements of a session that are not skipped."""
+    return [
+        step
+        for step in doctest.DocTestParser().get_examples(example.code)
+        if not step.options.get(doctest.SKIP)
+    ]
+
+
+def _run(
+    example: CodeExample,
+    root: str | Path,
+    timeout: float,
+) -> ExampleFailure | None:
+    environment = dict(os.environ)
+    path = environment.get("PYTHONPATH")
+    environment["PYTHONPATH"] = os.pathsep.join(filter(None, [str(root), path]))
+    if example.session:
+        command = [sys.executable, "-c", _DOCTEST_DRIVER]
+        if example.module is not None:
+            command.append(example.module)
+    else:
+        command = [sys.executable, "-"]
+    try:
+        result = subprocess.run(
+            command,
+            input=example.code,
+            capture_output=True,
+            text=True,
+            cwd=root,
+            env=environment,
+            timeout=timeout,
+        )
+    except subprocess.TimeoutExpired:
+        return ExampleFailure(example, example.lineno, f"timed out after {timeout}s")
+    if result.returncode == 0:
+        return None
+    output = [line for line in result.stderr.splitlines() if line.strip()]
+    last = output[-1].strip() if output else f"exited with {result.returncode}"
+    if example.session:
+        line, _, message = last.partition(":")
+        if line.isdigit():
+            return ExampleFailure(example, example.lineno + int(line), message)
+        return ExampleFailure(example, example.lineno, f"failed: {last}")
+    lines = _TRACEBACK_LINE.findall(result.stderr)
+    lineno = example.lineno + int(lines[-1]) - 1 if lines else example.lineno
+    return ExampleFailure(example, lineno, f"raised {last}")
+
+
+def check_example(
+    example: CodeExample,
+    run: bool = False,
+    root: str | Path = ".",
+    timeout: float = EXAMPLE_TIMEOUT,
+) -> ExampleFailure | None:
+    """Why ``example`` is broken; None if it compiles (and, with ``run``, runs)."""
+    if example.session and not _steps(example):
+        return None
+    failure = _compile(example)
+    if failure is None and run:
+        failure = _run(example, root, timeout)
+    return failure
+
+
+def check_examples(
+    examples: Iterable[CodeExample],
+    run: bool = False,
+    root: str | Path = ".",
+    timeout: float = EXAMPLE_TIMEOUT,
+) -> list[ExampleFailure]:
+    """The failures of ``examples``, in order."""
+    return [
+        failure
+        for example in examples
+        if (failure := check_example(example, run, root, timeout)) is not None
+    ]
+
+
+__all__ = [
+    "EXAMPLE_LANGUAGES",
+    "EXAMPLE_TIMEOUT",
+    "NOCHECK",
+    "CodeExample",
+    "ExampleFailure",
+    "check_example",
+    "check_examples",
+    "extract_examples",
+    "find_examples",
+]
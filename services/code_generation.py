@@ -0,0 +1,239 @@
+"""Inventory of the commands that generate code, and of the generated files.
+
+A module declares how code next to it is regenerated with a directive
+comment, the way Go packages use ``//go:generate``::
+
+    # autodoc:generate protoc --python_out=. --pyi_out=. shop/api.proto
+    # autodoc:generate datamodel-codegen --input schema.json --output models.py
+
+:func:`find_code_generation` collects every directive with the file and line
+it is on, and the outputs the command likely writes, read from its
+arguments: ``-o``/``--output``/``--outfile``/``--output-file`` values,
+protoc-style ``--<lang>_out`` directories, and ``>`` redirects. Relative
+outputs are resolved against the directory of the file the directive is in,
+where the command is meant to run. AutoDoc never runs the commands.
+
+It also lists the modules marked as generated by a comment in their first
+lines (``Code generated by <tool>. DO NOT EDIT.``, ``@generated``, or one
+starting ``Generated by <tool>``), naming the tool when the marker does.
+Docstrings and other prose are not markers: "generated" alone says little. A
+generated module below a directive's output directory, or written to one of
+its output files, is listed under that directive too.
+"""
+
+from __future__ import annotations
+
+import io
+import logging
+import os
+import re
+import shlex
+import tokenize
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from services.import_graph import ImportGraph, relative_path
+
+logger = logging.getLogger(__name__)
+
+DIRECTIVE = "autodoc:generate"
+# How many lines at the top of a module may carry a "generated" marker.
+MARKER_LINES = 10
+OUTPUT_OPTIONS = frozenset({"-o", "--output", "--outfile", "--output-file", "--out"})
+
+_DIRECTIVE = re.compile(rf"^#\s*{re.escape(DIRECTIVE)}\s+(?P<command>.+?)\s*$")
+# Markers the generators write: Go's "Code generated ... DO NOT EDIT.",
+# the @generated tag, and a comment opening with "Generated by <tool>".
+_MARKER = re.compile(
+    r"@generated\b|\bgenerated\b.*\bdo not edit\b"
+    r"|^(?:code\s+)?generated\s+(?:by|with|using|from)\b",
+    re.I,
+)
+_TOOL = re.compile(
+    r"generated\s+(?:by|with|using)\s+(?:the\s+)?(?P<tool>.+?)\s*"
+    r"(?:[.:!,](?:\s|$)|\bdo not edit\b|\bfrom\b|$)",
+    re.I,
+)
+# protoc and its plugins: --python_out=DIR, --grpc_python_out=DIR, ...
+_LANG_OUT = re.compile(r"^--[\w-]+_out=(?P<path>.+)$")
+
+
+@dataclass(frozen=True)
+class GenerateDirective:
+    """One ``# autodoc:generate`` comment."""
+
+    command: str
+    file_path: str
+    lineno: int
+    # Root-relative files or directories the command likely writes.
+    outputs: tuple[str, ...] = ()
+    # Generated modules found among the outputs.
+    generated: tuple[str, ...] = ()
+
+    @property
+    def tool(self) -> str:
+        """The program the command runs (``python -m x`` runs ``x``)."""
+        words = _split(self.command)
+        if len(words) >= 3 and Path(words[0]).name.startswith("python"):
+            if words[1] == "-m":
+                return words[2]
+        return Path(words[0]).name if words else ""
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "command": self.command,
+            "tool": self.tool,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "outputs": list(self.outputs),
+            "generated": list(self.generated),
+        }
+
+
+@dataclass(frozen=True)
+class GeneratedFile:
+    """A module marked as generated."""
+
+    file_path: str
+    module: str
+    lineno: int
+    # The tool the marker names, if it names one.
+    tool: str | None = None
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "file_path": self.file_path,
+            "module": self.module,
+            "lineno": self.lineno,
+            "tool": self.tool,
+        }
+
+
+@dataclass
+class CodeGeneration:
+    """Every directive and generated module of a tree."""
+
+    directives: list[GenerateDirective] = field(default_factory=list)
+    generated: list[GeneratedFile] = field(default_factory=list)
+
+    def __bool__(self) -> bool:
+        return bool(self.directives or self.generated)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "directives": [directive.to_dict() for directive in self.directives],
+            "generated": [generated.to_dict() for generated in self.generated],
+        }
+
+
+def _split(command: str) -> list[str]:
+    try:
+        return shlex.split(command)
+    except ValueError:
+        return command.split()
+
+
+def command_outputs(command: str) -> list[str]:
+    """Paths ``command`` likely writes, as written in it."""
+    words = _split(command)
+    outputs = []
+    for index, word in enumerate(words):
+        following = words[index + 1] if index + 1 < len(words) else None
+        name, _, value = word.partition("=")
+        if word in OUTPUT_OPTIONS or word in (">", ">>"):
+            if following is not None:
+                outputs.append(following)
+        elif name in OUTPUT_OPTIONS and value:
+            outputs.append(value)
+        elif word.startswith(">") and word.lstrip(">"):
+            outputs.append(word.lstrip(">"))
+        elif match := _LANG_OUT.match(word):
+            # Plugin options come first: --python_out=pyi_out:DIR.
+            outputs.append(match.group("path").rpartition(":")[2])
+    return outputs
+
+
+def _resolve(output: str, directory: Path, root: str | Path) -> str:
+    path = Path(os.path.normpath(directory / output))
+    return relative_path(path, root)
+
+
+def _directives(source: str) -> list[tuple[int, str]]:
+    found = []
+    tokens = tokenize.generate_tokens(io.StringIO(source).readline)
+    try:
+        for token in tokens:
+            if token.type != tokenize.COMMENT:
+                continue
+            match = _DIRECTIVE.match(token.string)
+            if match:
+                found.append((token.start[0], match.group("command")))
+    except (tokenize.TokenError, SyntaxError) as exc:
+        logger.warning("Stopped reading directives: %s", exc)
+    return found
+
+
+def _marker(source: str) -> tuple[int, str | None] | None:
+    for lineno, line in enumerate(source.splitlines()[:MARKER_LINES], 1):
+        if not line.lstrip().startswith("#"):
+            continue
+        text = line.strip().lstrip("#").strip()
+        if not _MARKER.search(text):
+            continue
+        tool = _TOOL.search(text)
+        return lineno, tool.group("tool").strip() if tool else None
+    return None
+
+
+def _covers(output: str, path: str) -> bool:
+    return path == output or output in ("", ".") or path.startswith(f"{output}/")
+
+
+def find_code_generation(root: str | Path, graph: ImportGraph) -> CodeGeneration:
+    """The directives and generated modules of ``graph`` (built for ``root``).
+
+    Directives are sorted by file and line, generated modules by path.
+    """
+    directives: list[tuple[str, int, str, tuple[str, ...]]] = []
+    generated = []
+    for name in sorted(graph.modules):
+        info = graph.modules[name]
+        try:
+            source = Path(info.file_path).read_text(encoding="utf-8")
+        except (OSError, UnicodeDecodeError) as exc:
+            logger.warning("Skipping %s in code generation: %s", info.file_path, exc)
+            continue
+        rel = relative_path(info.file_path, root)
+        directory = Path(info.file_path).parent
+        for lineno, command in _directives(source):
+            outputs = tuple(
+                _resolve(output, directory, root) for output in command_outputs(command)
+            )
+            directives.append((rel, lineno, command, outputs))
+        marker = _marker(source)
+        if marker is not None:
+            generated.append(GeneratedFile(rel, info.module, *marker))
+
+    result = CodeGeneration(generated=sorted(generated, key=lambda g: g.file_path))
+    for rel, lineno, command, outputs in sorted(directives):
+        produced = tuple(
+            g.file_path
+            for g in result.generated
+            if any(_covers(output, g.file_path) for output in outputs)
+        )
+        result.directives.append(
+            GenerateDirective(command, rel, lineno, outputs, produced),
+        )
+    return result
+
+
+__all__ = [
+    "DIRECTIVE",
+    "MARKER_LINES",
+    "OUTPUT_OPTIONS",
+    "CodeGeneration",
+    "GenerateDirective",
+    "GeneratedFile",
+    "command_outputs",
+    "find_code_generation",
+]
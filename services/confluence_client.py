@@ -9,11 +9,16 @@ encapsulating authentication, error handling, and payload normalization.
 from __future__ import annotations
 
 from dataclasses import dataclass
+from pathlib import Path
 from typing import Any, Literal
 
 import httpx
+from pydantic import ValidationError
 
+from autodoc.config.project import ProjectConfigError, load_project_config
+from autodoc.config.secrets import SecretError, redact
 from autodoc.config.settings import ConfluenceSettings, get_settings
+from services.http_client import integration_client
 
 
 class ConfluenceError(Exception):
@@ -40,6 +45,31 @@ class ConfluenceLink:
     api: str | None = None
 
 
+def load_confluence_settings(root: str | Path = ".") -> ConfluenceSettings:
+    """The Confluence settings of the environment and ``autodoc.yaml``.
+
+    The ``integrations.confluence`` section of the config file in ``root``,
+    with its references resolved, takes precedence over the environment.
+
+    Raises:
+        ConfluenceConfigurationError: If the config file or a reference in it
+            cannot be read, or the settings are invalid
+    """
+    try:
+        config = load_project_config(root)
+        overrides = config.integrations.settings("confluence", config.base_dir(root))
+    except (ProjectConfigError, SecretError) as exc:
+        raise ConfluenceConfigurationError(redact(str(exc))) from exc
+    if not overrides:
+        return get_settings().confluence
+    try:
+        return ConfluenceSettings(**overrides)
+    except ValidationError as exc:
+        raise ConfluenceConfigurationError(
+            redact(f"Invalid confluence settings: {exc}"),
+        ) from None
+
+
 def _normalize_base_url(url: str) -> str:
     """Ensure the base Confluence URL is ready for REST calls."""
     return url.rstrip("/") + "/wiki/rest/api"
@@ -54,7 +84,7 @@ class ConfluenceClient:
         settings: ConfluenceSettings | None = None,
         client: httpx.Client | None = None,
     ) -> None:
-        self._settings = settings or get_settings().confluence
+        self._settings = settings or load_confluence_settings()
         if not self._settings.is_configured:
             raise ConfluenceConfigurationError(
                 "Confluence credentials are not configured. "
@@ -67,10 +97,12 @@ class ConfluenceClient:
             self._settings.token or "",
         )
 
-        self._client = client or httpx.Client(
+        self._client = integration_client(
+            "confluence",
+            self._settings,
+            client,
             base_url=base_url,
             auth=auth,
-            timeout=self._settings.timeout,
             headers={"Content-Type": "application/json"},
         )
 
@@ -235,10 +267,13 @@ class ConfluenceClient:
         except httpx.HTTPStatusError as exc:
             if exc.response.status_code == 409:
                 raise ConfluenceConflictError(
-                    f"Confluence conflict: {exc.response.status_code} {exc.response.text}",
+                    redact(
+                        f"Confluence conflict: {exc.response.status_code} "
+                        f"{exc.response.text}",
+                    ),
                 ) from exc
             raise ConfluenceHTTPError(
-                f"{detail}: {exc.response.status_code} {exc.response.text}",
+                redact(f"{detail}: {exc.response.status_code} {exc.response.text}"),
             ) from exc
 
     @staticmethod
@@ -297,4 +332,5 @@ __all__ = [
     "ConfluenceConfigurationError",
     "ConfluenceError",
     "ConfluenceHTTPError",
+    "load_confluence_settings",
 ]
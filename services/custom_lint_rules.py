@@ -0,0 +1,196 @@
+"""Lint rules defined in ``autodoc.yaml`` instead of code.
+
+Each entry under ``lint.rules`` becomes an :class:`ExpressionRule`::
+
+    lint:
+      disable: [missing-docstring]
+      rules:
+        - id: document-raises
+          description: Public functions that raise must say so
+          when: exported and kind in ["function", "method"] and raises
+          require: matches(docstring, "Raises:")
+          message: "{qualified_name} raises {raises} but has no Raises: section"
+
+``when`` selects the symbols a rule applies to (default: every symbol) and
+``require`` is the condition they must satisfy; both use the expression
+language in :mod:`services.rule_expressions`. ``message`` is a
+:meth:`str.format` template over the same fields.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+from collections.abc import Iterable
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+from autodoc.config.project import LintConfig
+from services.doc_lint import DEFAULT_RULES, LintFinding, LintRule
+from services.doc_spelling import (
+    SPELLING_RULE_ID,
+    TERMINOLOGY_RULE_ID,
+    SpellingRule,
+    TerminologyRule,
+    load_dictionary,
+)
+from services.doc_symbols import DocSymbol
+from services.rule_expressions import (
+    Expression,
+    ExpressionError,
+    compile_expression,
+    symbol_fields,
+)
+
+logger = logging.getLogger(__name__)
+
+SEVERITIES = ("error", "warning", "info")
+
+RULE_ID = re.compile(r"^[a-z0-9][a-z0-9-]*$")
+_RULE_KEYS = frozenset({"id", "description", "when", "require", "message", "severity"})
+
+
+class RuleConfigError(Exception):
+    """Raised when a configured lint rule is invalid."""
+
+
+@dataclass(frozen=True)
+class ExpressionRule:
+    """A lint rule whose condition is a config-defined expression."""
+
+    id: str
+    description: str
+    require: Expression
+    when: Expression | None = None
+    message: str = "{kind} {qualified_name} violates {rule}"
+    severity: str = "error"
+
+    def _render(self, fields: dict[str, Any]) -> str:
+        try:
+            return self.message.format(rule=self.id, **fields)
+        except (KeyError, IndexError, ValueError) as exc:
+            logger.warning("Bad message template for rule %s: %s", self.id, exc)
+            return f"{fields['kind']} {fields['qualified_name']} violates {self.id}"
+
+    def check(self, symbol: DocSymbol) -> Iterable[LintFinding]:
+        """Yield a finding if ``symbol`` fails the rule.
+
+        Raises:
+            RuleConfigError: If an expression fails to evaluate for ``symbol``
+        """
+        fields = symbol_fields(symbol)
+        try:
+            if self.when is not None and not self.when.evaluate(fields):
+                return
+            if self.require.evaluate(fields):
+                return
+        except ExpressionError as exc:
+            raise RuleConfigError(
+                f"Rule {self.id} on {symbol.qualified_name}: {exc}",
+            ) from exc
+        yield LintFinding(
+            rule=self.id,
+            message=self._render(fields),
+            file_path=symbol.file_path,
+            lineno=symbol.lineno,
+            symbol=symbol.qualified_name,
+            severity=self.severity,
+        )
+
+
+def rule_from_dict(data: dict[str, Any]) -> ExpressionRule:
+    """Build an :class:`ExpressionRule` from one ``lint.rules`` entry.
+
+    Raises:
+        RuleConfigError: If required keys are missing, unknown keys are
+            present, or an expression does not compile
+    """
+    rule_id = data.get("id")
+    if not isinstance(rule_id, str) or not RULE_ID.match(rule_id):
+        raise RuleConfigError(
+            f"Rule id must be lowercase letters, digits and dashes, got {rule_id!r}",
+        )
+    unknown = set(data) - _RULE_KEYS
+    if unknown:
+        raise RuleConfigError(f"Rule {rule_id}: unknown keys {sorted(unknown)}")
+    if not data.get("require"):
+        raise RuleConfigError(f"Rule {rule_id}: 'require' is required")
+    severity = data.get("severity", "error")
+    if severity not in SEVERITIES:
+        raise RuleConfigError(
+            f"Rule {rule_id}: severity must be one of {', '.join(SEVERITIES)}",
+        )
+
+    try:
+        require = compile_expression(str(data["require"]))
+        when = compile_expression(str(data["when"])) if data.get("when") else None
+    except ExpressionError as exc:
+        raise RuleConfigError(f"Rule {rule_id}: {exc}") from exc
+
+    return ExpressionRule(
+        id=rule_id,
+        description=str(data.get("description", "")),
+        require=require,
+        when=when,
+        message=str(data.get("message", ExpressionRule.message)),
+        severity=severity,
+    )
+
+
+def _wording_rules(config: LintConfig, base_dir: Path) -> list[LintRule]:
+    """The spelling and terminology rules, when configured."""
+    rules: list[LintRule] = []
+    if config.spelling.enabled and SPELLING_RULE_ID not in config.disable:
+        spelling = config.spelling
+        accepted = list(spelling.words)
+        if spelling.dictionary:
+            path = base_dir / spelling.dictionary
+            try:
+                accepted.extend(load_dictionary(path))
+            except OSError as exc:
+                raise RuleConfigError(
+                    f"Cannot read spelling dictionary {path}: {exc}",
+                ) from exc
+        rules.append(SpellingRule.from_words(accepted, spelling.corrections))
+    if config.terminology and TERMINOLOGY_RULE_ID not in config.disable:
+        rules.append(TerminologyRule.from_config(config.terminology))
+    return rules
+
+
+def rules_from_config(
+    config: LintConfig,
+    base_dir: str | Path = ".",
+) -> list[LintRule]:
+    """Return the built-in rules not disabled by ``config`` plus its custom rules.
+
+    The spelling dictionary path is relative to ``base_dir``, normally
+    :meth:`ProjectConfig.base_dir <autodoc.config.project.ProjectConfig.base_dir>`.
+
+    Raises:
+        RuleConfigError: If a custom rule is invalid or reuses an existing id,
+            or the spelling dictionary cannot be read
+    """
+    rules: list[LintRule] = [
+        rule for rule in DEFAULT_RULES if rule.id not in set(config.disable)
+    ]
+    rules.extend(_wording_rules(config, Path(base_dir)))
+    seen = {rule.id for rule in DEFAULT_RULES} | {SPELLING_RULE_ID, TERMINOLOGY_RULE_ID}
+    for entry in config.rules:
+        rule = rule_from_dict(entry)
+        if rule.id in seen:
+            raise RuleConfigError(f"Duplicate rule id {rule.id!r}")
+        seen.add(rule.id)
+        if rule.id not in config.disable:
+            rules.append(rule)
+    return rules
+
+
+__all__ = [
+    "RULE_ID",
+    "SEVERITIES",
+    "ExpressionRule",
+    "RuleConfigError",
+    "rule_from_dict",
+    "rules_from_config",
+]
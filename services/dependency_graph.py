@@ -0,0 +1,354 @@
+"""Dependency injection graph of a source tree.
+
+:func:`build_dependency_graph` recognizes two injection styles:
+
+- FastAPI ``Depends``: parameter defaults (``db = Depends(get_db)``),
+  ``Annotated[T, Depends(...)]`` parameters and aliases, and
+  ``dependencies=[Depends(...)]`` on routes, routers and apps. A bare
+  ``Depends()`` injects the parameter's annotated class.
+- ``dependency_injector`` containers: providers declared on a
+  ``DeclarativeContainer`` subclass, the providers they are built from, and
+  ``Provide[Container.name]`` markers on consumers.
+
+Every injection becomes an edge from the consumer to its provider, so the
+graph shows how each component is constructed and where it is used.
+"""
+
+from __future__ import annotations
+
+import ast
+import logging
+from collections import defaultdict
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+logger = logging.getLogger(__name__)
+
+DEPENDS = frozenset({"fastapi.Depends", "fastapi.params.Depends", "fastapi.Security"})
+PROVIDE = frozenset({"dependency_injector.wiring.Provide"})
+CONTAINER_BASES = frozenset(
+    {
+        "dependency_injector.containers.DeclarativeContainer",
+        "dependency_injector.containers.DynamicContainer",
+    },
+)
+
+
+@dataclass(frozen=True)
+class Provider:
+    """Something that constructs an injected value."""
+
+    name: str
+    # ``dependency`` (a FastAPI dependency) or the dependency_injector
+    # provider type (``Factory``, ``Singleton``, ...).
+    kind: str
+    file_path: str | None = None
+    lineno: int = 0
+    # What a container provider builds (its first argument).
+    provides: str | None = None
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "name": self.name,
+            "kind": self.kind,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "provides": self.provides,
+        }
+
+
+@dataclass(frozen=True)
+class Injection:
+    """``consumer`` receives the value built by ``provider``."""
+
+    consumer: str
+    provider: str
+    file_path: str
+    lineno: int
+    parameter: str | None = None
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "consumer": self.consumer,
+            "provider": self.provider,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "parameter": self.parameter,
+        }
+
+
+@dataclass
+class DependencyGraph:
+    """Providers and the injections between components."""
+
+    providers: dict[str, Provider] = field(default_factory=dict)
+    injections: list[Injection] = field(default_factory=list)
+
+    def __bool__(self) -> bool:
+        return bool(self.injections or self.providers)
+
+    def consumers_of(self, provider: str) -> list[Injection]:
+        """Injections of ``provider``, ordered by consumer."""
+        return sorted(
+            (i for i in self.injections if i.provider == provider),
+            key=lambda i: (i.consumer, i.lineno),
+        )
+
+    def dependencies_of(self, consumer: str) -> list[str]:
+        """Providers injected into ``consumer``, without duplicates."""
+        return sorted({i.provider for i in self.injections if i.consumer == consumer})
+
+    def edges(self) -> dict[str, set[str]]:
+        """Map each consumer to the providers it depends on."""
+        edges: dict[str, set[str]] = defaultdict(set)
+        for injection in self.injections:
+            edges[injection.consumer].add(injection.provider)
+        return dict(edges)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "providers": [self.providers[n].to_dict() for n in sorted(self.providers)],
+            "injections": [i.to_dict() for i in self.injections],
+        }
+
+
+def _annotated_depends(node: ast.expr | None) -> ast.Call | None:
+    """The call in the metadata of ``Annotated[T, Depends(...)]``, if any."""
+    if not isinstance(node, ast.Subscript) or not isinstance(node.slice, ast.Tuple):
+        return None
+    for element in node.slice.elts[1:]:
+        if isinstance(element, ast.Call):
+            return element
+    return None
+
+
+def _single_target(node: ast.stmt) -> str | None:
+    if (
+        isinstance(node, ast.Assign)
+        and len(node.targets) == 1
+        and isinstance(node.targets[0], ast.Name)
+    ):
+        return node.targets[0].id
+    return None
+
+
+class _ModuleScanner:
+    def __init__(
+        self,
+        tree: ast.Module,
+        info: ModuleImports,
+        file_path: str,
+        graph: DependencyGraph,
+        aliases: dict[str, tuple[ast.Subscript, _ModuleScanner]],
+    ) -> None:
+        self.tree = tree
+        self.info = info
+        self.file_path = file_path
+        self.graph = graph
+        # Qualified ``X = Annotated[T, Depends(...)]`` alias -> its value and
+        # the scanner of its module, shared by all scanners.
+        self.aliases = aliases
+        self.local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        } | {name for name in map(_single_target, tree.body) if name}
+
+    def resolve(self, node: ast.expr) -> str:
+        return resolve_name(node, self.info, self.local_names)
+
+    def collect_aliases(self) -> None:
+        for node in self.tree.body:
+            name = _single_target(node)
+            if not name or not isinstance(node.value, ast.Subscript):  # type: ignore[attr-defined]
+                continue
+            value: ast.Subscript = node.value  # type: ignore[attr-defined]
+            call = _annotated_depends(value)
+            if call is not None and self.resolve(call.func) in DEPENDS:
+                self.aliases[f"{self.info.module}.{name}"] = (value, self)
+
+    def _provider_of(self, call: ast.Call, annotation: ast.expr | None) -> str | None:
+        """Provider injected by a ``Depends`` call, or None for other calls."""
+        if self.resolve(call.func) not in DEPENDS:
+            return None
+        dependency = call.args[0] if call.args else None
+        if dependency is None:
+            dependency = next(
+                (k.value for k in call.keywords if k.arg == "dependency"),
+                annotation,
+            )
+        return self.resolve(dependency) if dependency is not None else None
+
+    def _inject(
+        self,
+        consumer: str,
+        provider: str,
+        lineno: int,
+        parameter: str | None = None,
+    ) -> None:
+        self.graph.injections.append(
+            Injection(consumer, provider, self.file_path, lineno, parameter),
+        )
+        self.graph.providers.setdefault(provider, Provider(provider, "dependency"))
+
+    def _annotated(self, annotation: ast.expr | None) -> str | None:
+        """Provider of an ``Annotated`` parameter type, inline or aliased."""
+        scanner: _ModuleScanner = self
+        if annotation is not None and not isinstance(annotation, ast.Subscript):
+            alias = self.aliases.get(self.resolve(annotation))
+            if alias is not None:
+                annotation, scanner = alias
+        call = _annotated_depends(annotation)
+        if call is None:
+            return None
+        base = annotation.slice.elts[0]  # type: ignore[union-attr]
+        return scanner._provider_of(call, base)
+
+    def _parameter(self, consumer: str, arg: ast.arg, default: ast.expr | None) -> None:
+        provider = self._annotated(arg.annotation)
+        if provider is None and isinstance(default, ast.Call):
+            provider = self._provider_of(default, arg.annotation)
+        if (
+            provider is None
+            and isinstance(default, ast.Subscript)
+            and self.resolve(default.value) in PROVIDE
+        ):
+            provider = self.resolve(default.slice)
+        if provider:
+            self._inject(consumer, provider, arg.lineno, arg.arg)
+
+    def _function(self, node: ast.FunctionDef | ast.AsyncFunctionDef, qualname: str) -> None:
+        args = node.args
+        positional = [*args.posonlyargs, *args.args]
+        defaults: list[ast.expr | None] = [None] * (
+            len(positional) - len(args.defaults)
+        ) + list(args.defaults)
+        pairs = [*zip(positional, defaults), *zip(args.kwonlyargs, args.kw_defaults)]
+        for arg, default in pairs:
+            self._parameter(qualname, arg, default)
+        for decorator in node.decorator_list:
+            if isinstance(decorator, ast.Call):
+                self._dependencies_keyword(qualname, decorator)
+
+    def _dependencies_keyword(self, consumer: str, call: ast.Call) -> None:
+        for keyword in call.keywords:
+            if keyword.arg != "dependencies" or not isinstance(
+                keyword.value,
+                (ast.List, ast.Tuple),
+            ):
+                continue
+            for element in keyword.value.elts:
+                if isinstance(element, ast.Call):
+                    provider = self._provider_of(element, None)
+                    if provider:
+                        self._inject(consumer, provider, element.lineno)
+
+    def _container(self, node: ast.ClassDef, qualname: str) -> None:
+        members = {name for name in map(_single_target, node.body) if name}
+        for stmt in node.body:
+            member = _single_target(stmt)
+            if not member or not isinstance(stmt.value, ast.Call):  # type: ignore[attr-defined]
+                continue
+            name = f"{qualname}.{member}"
+            call: ast.Call = stmt.value  # type: ignore[attr-defined]
+            kind = self.resolve(call.func).rsplit(".", 1)[-1]
+            provides = self.resolve(call.args[0]) if call.args else None
+            self.graph.providers[name] = Provider(
+                name,
+                kind,
+                self.file_path,
+                stmt.lineno,
+                provides,
+            )
+            arguments = [*call.args[1:], *(k.value for k in call.keywords)]
+            for argument in arguments:
+                for sub in ast.walk(argument):
+                    target = None
+                    if isinstance(sub, ast.Name) and sub.id in members:
+                        target = f"{qualname}.{sub.id}"
+                    elif (
+                        isinstance(sub, ast.Attribute)
+                        and isinstance(sub.value, ast.Name)
+                        and sub.value.id == node.name
+                    ):
+                        target = f"{qualname}.{sub.attr}"
+                    if target is not None and target != name:
+                        self.graph.injections.append(
+                            Injection(name, target, self.file_path, sub.lineno),
+                        )
+
+    def scan(self) -> None:
+        module = self.info.module
+        for node in self.tree.body:
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                qualname = f"{module}.{node.name}"
+                self._function(node, qualname)
+            elif isinstance(node, ast.ClassDef):
+                qualname = f"{module}.{node.name}"
+                bases = {self.resolve(base) for base in node.bases}
+                if bases & CONTAINER_BASES:
+                    self._container(node, qualname)
+                    continue
+                for item in node.body:
+                    if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                        self._function(item, f"{qualname}.{item.name}")
+            elif _single_target(node) and isinstance(node.value, ast.Call):  # type: ignore[attr-defined]
+                self._dependencies_keyword(
+                    f"{module}.{_single_target(node)}",
+                    node.value,  # type: ignore[attr-defined]
+                )
+
+    def definitions(self) -> dict[str, tuple[str, int]]:
+        """Top-level functions, classes and variables with their lines."""
+        found = {}
+        for node in self.tree.body:
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+                found[f"{self.info.module}.{node.name}"] = (self.file_path, node.lineno)
+            elif _single_target(node):
+                name = f"{self.info.module}.{_single_target(node)}"
+                found[name] = (self.file_path, node.lineno)
+        return found
+
+def build_dependency_graph(root: str | Path, graph: ImportGraph) -> DependencyGraph:
+    """Find injections in every module of ``graph`` (built for ``root``)."""
+    root_path = Path(root)
+    result = DependencyGraph()
+    aliases: dict[str, tuple[ast.Subscript, _ModuleScanner]] = {}
+    scanners = []
+    for info, tree in parse_modules(graph, "dependency analysis"):
+        scanner = _ModuleScanner(
+            tree,
+            info,
+            relative_path(info.file_path, root_path),
+            result,
+            aliases,
+        )
+        scanner.collect_aliases()
+        scanners.append(scanner)
+
+    definitions: dict[str, tuple[str, int]] = {}
+    for scanner in scanners:
+        scanner.scan()
+        definitions.update(scanner.definitions())
+
+    for name, provider in list(result.providers.items()):
+        if provider.file_path is None and name in definitions:
+            file_path, lineno = definitions[name]
+            result.providers[name] = Provider(name, provider.kind, file_path, lineno)
+    return result
+
+
+__all__ = [
+    "DependencyGraph",
+    "Injection",
+    "Provider",
+    "build_dependency_graph",
+]
@@ -0,0 +1,500 @@
+"""Accessibility audit of generated HTML sites and their themes.
+
+Checks the parts of WCAG 2.1 AA that static pages can be verified against
+without a browser:
+
+* text, link, and code colours of every palette the theme uses, including the
+  ``--autodoc-*`` variables and hex ``color`` rules of custom stylesheets, must
+  reach a contrast ratio of 4.5:1;
+* custom stylesheets must not remove the focus outline or hide the skip link;
+* images need an ``alt`` attribute, links need text, and ``tabindex`` must not
+  be positive, in the pages and in ``header_html``/``footer_html``;
+* every page needs a ``lang``, a ``main`` landmark reached by a skip link, a
+  single ``h1`` with no skipped heading levels, and unique ids.
+
+Colours written as ``rgb()``, names, or ``var()`` are not checked.
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Iterable
+from dataclasses import asdict, dataclass, field
+from html.parser import HTMLParser
+from pathlib import Path
+from typing import Any
+
+from autodoc.config.project import ThemeConfig
+from services.doc_site import SitePage
+from services.doc_theme import palette_colors
+
+# WCAG AA contrast for normal-sized text.
+MIN_CONTRAST = 4.5
+
+# Pairs of (foreground, background) variables and what they colour.
+_CONTRAST_PAIRS = (
+    ("--autodoc-fg", "--autodoc-bg", "text"),
+    ("--autodoc-muted", "--autodoc-bg", "muted text"),
+    ("--autodoc-primary", "--autodoc-bg", "links"),
+    ("--autodoc-accent", "--autodoc-bg", "symbol kinds"),
+    ("--autodoc-fg", "--autodoc-code-bg", "code"),
+)
+_CONFIG_KEYS = {
+    "--autodoc-primary": "site.theme.primary_color",
+    "--autodoc-accent": "site.theme.accent_color",
+}
+
+_HEX = re.compile(r"^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$")
+_COMMENT = re.compile(r"/\*.*?\*/", re.DOTALL)
+_ROOT_SELECTOR = re.compile(r"^(?::root|html)$")
+_COLOR_SCHEME = re.compile(r"prefers-color-scheme\s*:\s*(light|dark)")
+_NO_OUTLINE = {"none", "0", "0 none", "none 0"}
+
+
+@dataclass
+class AccessibilityFinding:
+    """An accessibility problem in a page, stylesheet, or theme setting."""
+
+    rule: str
+    message: str
+    # ``path:line`` of a page or stylesheet, or a ``site.theme`` key.
+    location: str
+
+    def format(self) -> str:
+        """Render the finding in ``location: [rule] message`` form."""
+        return f"{self.location}: [{self.rule}] {self.message}"
+
+    def to_dict(self) -> dict[str, Any]:
+        return asdict(self)
+
+
+def _rgb(color: str) -> tuple[int, int, int]:
+    digits = color[1:]
+    if len(digits) == 3:
+        digits = "".join(digit * 2 for digit in digits)
+    red, green, blue = (int(digits[i : i + 2], 16) for i in (0, 2, 4))
+    return red, green, blue
+
+
+def _luminance(color: str) -> float:
+    channels = []
+    for channel in _rgb(color):
+        value = channel / 255
+        channels.append(
+            value / 12.92 if value <= 0.04045 else ((value + 0.055) / 1.055) ** 2.4,
+        )
+    red, green, blue = channels
+    return 0.2126 * red + 0.7152 * green + 0.0722 * blue
+
+
+def contrast_ratio(foreground: str, background: str) -> float:
+    """WCAG contrast ratio of two hex colours, from 1 to 21."""
+    lighter, darker = sorted(
+        (_luminance(foreground), _luminance(background)),
+        reverse=True,
+    )
+    return (lighter + 0.05) / (darker + 0.05)
+
+
+def is_hex_color(value: str) -> bool:
+    """Whether ``value`` is a ``#rgb`` or ``#rrggbb`` colour."""
+    return bool(_HEX.match(value))
+
+
+@dataclass
+class _Rule:
+    media: str
+    selector: str
+    declarations: dict[str, str]
+    lineno: int
+
+
+def _css_rules(css: str) -> list[_Rule]:
+    """The style rules of ``css`` with their enclosing at-rules and lines."""
+    # Blank comments out but keep their newlines, so line numbers hold.
+    css = _COMMENT.sub(lambda match: "\n" * match.group().count("\n"), css)
+    rules = []
+    stack: list[tuple[str, int, int]] = []
+    start = 0
+    for index, char in enumerate(css):
+        if char == "{":
+            raw = css[start:index]
+            begin = start + len(raw) - len(raw.lstrip())
+            stack.append((raw.strip(), index + 1, css.count("\n", 0, begin) + 1))
+            start = index + 1
+        elif char == "}" and stack:
+            prelude, body, lineno = stack.pop()
+            if not prelude.startswith("@"):
+                declarations = {}
+                for declaration in css[body:index].split(";"):
+                    name, _, value = declaration.partition(":")
+                    value = value.replace("!important", "").strip().lower()
+                    if name.strip():
+                        declarations[name.strip().lower()] = value
+                media = " ".join(p for p, _, _ in stack if p.startswith("@"))
+                rules.append(_Rule(media, prelude, declarations, lineno))
+            start = index + 1
+    return rules
+
+
+def _palettes_of(rule: _Rule, modes: Iterable[str]) -> list[str]:
+    """The palettes a rule applies to; ``[]`` for print and similar media."""
+    if not rule.media:
+        return list(modes)
+    scheme = _COLOR_SCHEME.search(rule.media)
+    return [mode for mode in modes if scheme and scheme.group(1) == mode]
+
+
+def _contrast(
+    label: str,
+    foreground: str,
+    background: str,
+    mode: str,
+    location: str,
+) -> AccessibilityFinding | None:
+    ratio = contrast_ratio(foreground, background)
+    if ratio >= MIN_CONTRAST:
+        return None
+    return AccessibilityFinding(
+        "contrast",
+        f"contrast of {label} is {ratio:.2f}:1 ({foreground} on {background}, "
+        f"{mode} palette); WCAG AA needs {MIN_CONTRAST}:1",
+        location,
+    )
+
+
+def _audit_colors(
+    theme: ThemeConfig,
+    stylesheets: list[tuple[str, list[_Rule]]],
+) -> list[AccessibilityFinding]:
+    palettes = palette_colors(theme)
+    origins = {
+        (mode, name): _CONFIG_KEYS.get(name, "site.theme")
+        for mode, colors in palettes.items()
+        for name in colors
+    }
+    findings = []
+    for path, rules in stylesheets:
+        for rule in rules:
+            location = f"{path}:{rule.lineno}"
+            modes = _palettes_of(rule, palettes)
+            if _ROOT_SELECTOR.match(rule.selector):
+                for name, value in rule.declarations.items():
+                    if name.startswith("--autodoc-") and is_hex_color(value):
+                        for mode in modes:
+                            palettes[mode][name] = value
+                            origins[mode, name] = location
+                continue
+            color = rule.declarations.get("color", "")
+            if not is_hex_color(color):
+                continue
+            background = rule.declarations.get(
+                "background-color",
+                rule.declarations.get("background", ""),
+            )
+            for mode in modes:
+                finding = _contrast(
+                    f"'{rule.selector}'",
+                    color,
+                    background
+                    if is_hex_color(background)
+                    else palettes[mode]["--autodoc-bg"],
+                    mode,
+                    location,
+                )
+                if finding is not None:
+                    findings.append(finding)
+
+    for mode, colors in palettes.items():
+        for foreground, background, label in _CONTRAST_PAIRS:
+            # Blame whichever of the two colours the theme changed.
+            location = origins[mode, foreground]
+            if location == "site.theme":
+                location = origins[mode, background]
+            finding = _contrast(
+                label,
+                colors[foreground],
+                colors[background],
+                mode,
+                location,
+            )
+            if finding is not None:
+                findings.append(finding)
+    return findings
+
+
+def _audit_focus(path: str, rules: list[_Rule]) -> list[AccessibilityFinding]:
+    findings = []
+    for rule in rules:
+        location = f"{path}:{rule.lineno}"
+        declarations = rule.declarations
+        if (
+            ":focus" in rule.selector
+            and ":not(:focus-visible)" not in rule.selector
+            and declarations.get("outline") in _NO_OUTLINE
+            and "box-shadow" not in declarations
+        ):
+            findings.append(
+                AccessibilityFinding(
+                    "focus-indicator",
+                    f"'{rule.selector}' removes the keyboard focus outline "
+                    "without a replacement such as box-shadow",
+                    location,
+                ),
+            )
+        if "autodoc-skip" in rule.selector and (
+            declarations.get("display") == "none"
+            or declarations.get("visibility") == "hidden"
+        ):
+            findings.append(
+                AccessibilityFinding(
+                    "skip-link",
+                    f"'{rule.selector}' hides the skip link from keyboard users",
+                    location,
+                ),
+            )
+    return findings
+
+
+def _describe(tag: str, attrs: dict[str, str | None]) -> str:
+    shown = "".join(
+        f' {name}="{attrs[name]}"' for name in ("id", "href", "src") if attrs.get(name)
+    )
+    return f"<{tag}{shown}>"
+
+
+@dataclass
+class _Link:
+    description: str
+    lineno: int
+    labelled: bool
+    text: list[str] = field(default_factory=list)
+
+
+class _Scanner(HTMLParser):
+    """Collect the accessibility-relevant facts of one HTML document."""
+
+    def __init__(self, location: str) -> None:
+        super().__init__(convert_charrefs=True)
+        self.location = location
+        self.findings: list[AccessibilityFinding] = []
+        self.lang: str | None = None
+        self.main_ids: list[str | None] = []
+        self.first_link: str | None = None
+        self.in_body = False
+        self.headings: list[tuple[int, int]] = []
+        self.ids: dict[str, int] = {}
+        self.links: list[_Link] = []
+
+    def _report(self, rule: str, message: str, lineno: int | None = None) -> None:
+        line = self.getpos()[0] if lineno is None else lineno
+        self.findings.append(
+            AccessibilityFinding(rule, message, f"{self.location}:{line}"),
+        )
+
+    def handle_starttag(self, tag: str, attrs: list[tuple[str, str | None]]) -> None:
+        values = dict(attrs)
+        if tag == "html":
+            self.lang = values.get("lang") or None
+        elif tag == "body":
+            self.in_body = True
+        elif tag == "main":
+            self.main_ids.append(values.get("id"))
+        elif re.fullmatch(r"h[1-6]", tag):
+            self.headings.append((int(tag[1]), self.getpos()[0]))
+        element_id = values.get("id")
+        if element_id:
+            if element_id in self.ids:
+                self._report(
+                    "duplicate-id",
+                    f"id {element_id!r} is also used on line {self.ids[element_id]}",
+                )
+            else:
+                self.ids[element_id] = self.getpos()[0]
+        tabindex = values.get("tabindex") or ""
+        if tabindex.strip().lstrip("+").isdigit() and int(tabindex) > 0:
+            self._report(
+                "tabindex",
+                f"{_describe(tag, values)} has tabindex={tabindex}, which breaks "
+                "the reading order for keyboard users",
+            )
+        if tag == "img":
+            if values.get("alt") is None:
+                self._report(
+                    "image-alt",
+                    f"{_describe(tag, values)} has no alt text (use alt=\"\" "
+                    "for decorative images)",
+                )
+            elif self.links and values["alt"]:
+                self.links[-1].text.append(values["alt"])
+        elif tag == "a" and values.get("href") is not None:
+            if self.in_body and self.first_link is None:
+                self.first_link = values["href"]
+            self.links.append(
+                _Link(
+                    _describe(tag, values),
+                    self.getpos()[0],
+                    bool(values.get("aria-label") or values.get("title")),
+                ),
+            )
+
+    def handle_startendtag(
+        self,
+        tag: str,
+        attrs: list[tuple[str, str | None]],
+    ) -> None:
+        self.handle_starttag(tag, attrs)
+
+    def handle_endtag(self, tag: str) -> None:
+        if tag == "a" and self.links:
+            link = self.links.pop()
+            if not link.labelled and not "".join(link.text).strip():
+                self._report(
+                    "link-text",
+                    f"{link.description} has no text for screen readers",
+                    link.lineno,
+                )
+
+    def handle_data(self, data: str) -> None:
+        if self.links:
+            self.links[-1].text.append(data)
+
+
+def _scan(html: str, location: str) -> _Scanner:
+    scanner = _Scanner(location)
+    scanner.feed(html)
+    scanner.close()
+    return scanner
+
+
+def audit_page(path: str, html: str) -> list[AccessibilityFinding]:
+    """Audit one generated HTML page; ``path`` names it in the findings."""
+    scanner = _scan(html, path)
+    findings = scanner.findings
+    if not scanner.lang:
+        findings.append(
+            AccessibilityFinding("lang", "<html> has no lang attribute", f"{path}:1"),
+        )
+    if len(scanner.main_ids) != 1:
+        findings.append(
+            AccessibilityFinding(
+                "landmark",
+                f"page has {len(scanner.main_ids)} <main> landmarks instead of one",
+                f"{path}:1",
+            ),
+        )
+    elif not scanner.main_ids[0] or scanner.first_link != f"#{scanner.main_ids[0]}":
+        findings.append(
+            AccessibilityFinding(
+                "skip-link",
+                "the first link of the page does not skip to <main>",
+                f"{path}:1",
+            ),
+        )
+    previous = 0
+    titled = False
+    for level, lineno in scanner.headings:
+        if level == 1 and titled:
+            message = "page has more than one <h1>"
+        elif level > previous + 1:
+            after = f"<h{previous}>" if previous else "no heading"
+            message = f"<h{level}> follows {after}, skipping a level"
+        else:
+            previous = level
+            titled = titled or level == 1
+            continue
+        findings.append(
+            AccessibilityFinding("heading-order", message, f"{path}:{lineno}"),
+        )
+        previous = level
+    return sorted(findings, key=_order)
+
+
+def _order(finding: AccessibilityFinding) -> tuple[str, int, str]:
+    location, _, line = finding.location.rpartition(":")
+    if not line.isdigit():
+        return (finding.location, 0, finding.rule)
+    return (location, int(line), finding.rule)
+
+
+def audit_theme(
+    theme: ThemeConfig,
+    base_dir: str | Path,
+) -> list[AccessibilityFinding]:
+    """Audit the colours, custom stylesheets, and HTML hooks of ``theme``.
+
+    Stylesheets are read relative to ``base_dir``, like
+    :func:`~services.doc_theme.build_theme_assets`; ones that cannot be read
+    are left to it to report.
+    """
+    stylesheets = []
+    for path in theme.custom_css:
+        try:
+            css = (Path(base_dir) / path).read_text(encoding="utf-8")
+        except (OSError, UnicodeDecodeError):
+            continue
+        stylesheets.append((path, _css_rules(css)))
+
+    findings = _audit_colors(theme, stylesheets)
+    for path, rules in stylesheets:
+        findings.extend(_audit_focus(path, rules))
+    for key in ("header_html", "footer_html"):
+        snippet = getattr(theme, key)
+        if not snippet:
+            continue
+        location = f"site.theme.{key}"
+        scanner = _scan(snippet, location)
+        findings.extend(scanner.findings)
+        if scanner.headings:
+            findings.append(
+                AccessibilityFinding(
+                    "heading-order",
+                    "adds a heading to every page, outside the page's outline",
+                    location,
+                ),
+            )
+        if scanner.main_ids:
+            findings.append(
+                AccessibilityFinding(
+                    "landmark",
+                    "adds a second <main> landmark to every page",
+                    location,
+                ),
+            )
+    return sorted(findings, key=_order)
+
+
+def audit_site(
+    pages: list[SitePage],
+    theme: ThemeConfig,
+    base_dir: str | Path,
+) -> list[AccessibilityFinding]:
+    """Audit ``theme`` and every HTML page of a rendered site.
+
+    A problem repeated on many pages, typically from a theme hook, is reported
+    once, at its first occurrence.
+    """
+    findings = audit_theme(theme, base_dir)
+    seen = {(finding.rule, finding.message) for finding in findings}
+    for page in sorted(pages, key=lambda page: page.path):
+        if not page.path.endswith(".html"):
+            continue
+        content = page.content
+        if isinstance(content, bytes):
+            content = content.decode("utf-8")
+        for finding in audit_page(page.path, content):
+            if (finding.rule, finding.message) not in seen:
+                seen.add((finding.rule, finding.message))
+                findings.append(finding)
+    return findings
+
+
+__all__ = [
+    "MIN_CONTRAST",
+    "AccessibilityFinding",
+    "audit_page",
+    "audit_site",
+    "audit_theme",
+    "contrast_ratio",
+    "is_hex_color",
+]
@@ -0,0 +1,243 @@
+"""Page-view analytics for hosted docs, without third-party telemetry.
+
+AutoDoc sends nothing anywhere by itself. A site that wants to know which
+parts of its docs are read sets ``site.analytics`` in ``autodoc.yaml``:
+
+- ``snippet`` is HTML added to the ``<head>`` of every HTML page as is, such
+  as the script tag of whatever analytics service the site already uses. It
+  is the one hook that is not sanitized (see :mod:`services.html_sanitizer`),
+  so review changes to it as code.
+- ``beacon`` is an endpoint of the site's own. Pages load
+  :data:`SCRIPT_PATH`, which posts each view, and each jump to a symbol's
+  anchor, as a JSON object ``{"page": "shop.html", "symbol": "shop.cart.Cart"}``.
+  It sends no cookies, identifiers, or referrers, and nothing at all when the
+  browser asks not to be tracked (Do Not Track, Global Privacy Control).
+
+``autodoc serve --analytics FILE`` answers such posts at :data:`SERVE_BEACON`
+and appends them to ``FILE``, one JSON object per line (:class:`ViewLog`);
+a beacon endpoint of a hosted site can write the same lines. ``autodoc
+analytics`` reads them back and ranks the most viewed symbols
+(:func:`most_viewed`), flagging those without a docstring, so the docs
+people read most are improved first.
+"""
+
+from __future__ import annotations
+
+import json
+import logging
+import threading
+from collections import Counter
+from collections.abc import Iterable, Mapping
+from dataclasses import dataclass
+from datetime import UTC, datetime
+from html import escape
+from pathlib import Path
+from typing import Any
+
+from autodoc.config.project import AnalyticsConfig
+from services.doc_site import SitePage
+from services.doc_theme import ASSETS_DIR
+
+logger = logging.getLogger(__name__)
+
+SCRIPT_PATH = f"{ASSETS_DIR}/analytics.js"
+# Where ``autodoc serve --analytics`` receives page views.
+SERVE_BEACON = "/_autodoc/beacon"
+# Longest page or symbol name recorded; longer posts are discarded.
+MAX_NAME_LENGTH = 300
+
+_SCRIPT = """\
+(function () {
+  var script = document.currentScript;
+  var beacon = script && script.getAttribute("data-beacon");
+  if (!beacon || navigator.doNotTrack === "1" || navigator.globalPrivacyControl) {
+    return;
+  }
+  function send() {
+    var view = JSON.stringify({
+      page: location.pathname.split("/").pop() || "index.html",
+      symbol: decodeURIComponent(location.hash.slice(1))
+    });
+    if (navigator.sendBeacon) {
+      navigator.sendBeacon(beacon, view);
+    } else {
+      fetch(beacon, {method: "POST", body: view, keepalive: true,
+                     credentials: "omit", referrerPolicy: "no-referrer"});
+    }
+  }
+  send();
+  window.addEventListener("hashchange", send);
+})();
+"""
+
+
+def analytics_head(config: AnalyticsConfig) -> str:
+    """The HTML ``config`` adds to the ``<head>`` of a page."""
+    html = f"{config.snippet}\n" if config.snippet else ""
+    if config.beacon:
+        html += (
+            f'<script src="{SCRIPT_PATH}" '
+            f'data-beacon="{escape(config.beacon, quote=True)}" defer></script>\n'
+        )
+    return html
+
+
+def analytics_pages(config: AnalyticsConfig) -> list[SitePage]:
+    """The files of a site with ``config``: the beacon script, if it posts one."""
+    return [SitePage(SCRIPT_PATH, _SCRIPT)] if config.beacon else []
+
+
+@dataclass(frozen=True)
+class PageView:
+    """One view of a page, or of a symbol's anchor on it."""
+
+    page: str
+    # The anchor jumped to, such as a qualified name; empty for the page.
+    symbol: str = ""
+    # When the view was recorded, as ISO 8601 in UTC.
+    time: str = ""
+
+    def to_dict(self) -> dict[str, str]:
+        return {"page": self.page, "symbol": self.symbol, "time": self.time}
+
+
+def parse_view(data: Any) -> PageView | None:
+    """The view a beacon posted as ``data``; None if it is not one."""
+    if not isinstance(data, Mapping):
+        return None
+    page, symbol = data.get("page"), data.get("symbol", "")
+    if not isinstance(page, str) or not isinstance(symbol, str):
+        return None
+    if not page or len(page) > MAX_NAME_LENGTH or len(symbol) > MAX_NAME_LENGTH:
+        return None
+    time = data.get("time")
+    return PageView(page, symbol, time if isinstance(time, str) else "")
+
+
+class ViewLog:
+    """A file of page views, one JSON object per line."""
+
+    def __init__(self, path: str | Path) -> None:
+        self.path = Path(path)
+        self._lock = threading.Lock()
+
+    def record(self, body: bytes) -> bool:
+        """Append the view posted as ``body``; False if it is not one."""
+        try:
+            view = parse_view(json.loads(body.decode("utf-8")))
+        except (UnicodeDecodeError, ValueError):
+            view = None
+        if view is None:
+            return False
+        now = datetime.now(UTC).replace(microsecond=0).isoformat()
+        line = json.dumps(PageView(view.page, view.symbol, now).to_dict())
+        with self._lock, self.path.open("a", encoding="utf-8") as file:
+            file.write(line + "\n")
+        return True
+
+
+def load_views(paths: Iterable[str | Path]) -> list[PageView]:
+    """The views recorded in the files of ``paths``.
+
+    Lines that are not views are logged and skipped.
+    """
+    views = []
+    for path in paths:
+        with Path(path).open(encoding="utf-8") as file:
+            for lineno, line in enumerate(file, start=1):
+                if not line.strip():
+                    continue
+                try:
+                    view = parse_view(json.loads(line))
+                except ValueError:
+                    view = None
+                if view is None:
+                    logger.warning("Skipping %s:%d: not a page view", path, lineno)
+                else:
+                    views.append(view)
+    return views
+
+
+@dataclass(frozen=True)
+class ViewCount:
+    """How often a symbol's anchor, or a page, was viewed."""
+
+    name: str
+    views: int
+    # Whether the symbol has a docstring; None if not known.
+    documented: bool | None = None
+
+    def to_dict(self) -> dict[str, Any]:
+        return {"name": self.name, "views": self.views, "documented": self.documented}
+
+
+def most_viewed(
+    views: Iterable[PageView],
+    documented: Mapping[str, bool] | None = None,
+    top: int = 20,
+) -> tuple[list[ViewCount], list[ViewCount]]:
+    """The ``top`` most viewed symbols and pages, most views first.
+
+    ``documented`` maps qualified names to whether they have a docstring;
+    given, anchors that are not symbols (headings, say) are left out.
+    """
+    views = list(views)
+    symbols = Counter(view.symbol for view in views if view.symbol)
+    pages = Counter(view.page for view in views)
+    if documented is not None:
+        symbols = Counter({k: v for k, v in symbols.items() if k in documented})
+
+    def ranked(counts: Counter[str], known: Mapping[str, bool]) -> list[ViewCount]:
+        order = sorted(counts.items(), key=lambda item: (-item[1], item[0]))
+        return [ViewCount(name, n, known.get(name)) for name, n in order[:top]]
+
+    return ranked(symbols, documented or {}), ranked(pages, {})
+
+
+def render_report(
+    symbols: list[ViewCount],
+    pages: list[ViewCount],
+    total: int,
+    markdown: bool = False,
+) -> str:
+    """The most viewed symbols and pages as text, or as Markdown."""
+    if markdown:
+        lines = ["# Most viewed documentation", "", f"{total} page views.", ""]
+        lines += ["## Symbols", "", "| Symbol | Views | Docstring |", "|---|---|---|"]
+        lines += [
+            f"| `{c.name}` | {c.views} | {_documented(c)} |" for c in symbols
+        ]
+        lines += ["", "## Pages", "", "| Page | Views |", "|---|---|"]
+        lines += [f"| {c.name} | {c.views} |" for c in pages]
+        return "\n".join(lines) + "\n"
+    width = max((len(c.name) for c in [*symbols, *pages]), default=0)
+    lines = [f"{total} page views", "", "Most viewed symbols:"]
+    lines += [
+        f"  {c.views:>6}  {c.name:<{width}}  {_documented(c)}".rstrip()
+        for c in symbols
+    ] or ["  (none)"]
+    lines += ["", "Most viewed pages:"]
+    lines += [f"  {c.views:>6}  {c.name}" for c in pages] or ["  (none)"]
+    return "\n".join(lines) + "\n"
+
+
+def _documented(count: ViewCount) -> str:
+    if count.documented is None:
+        return ""
+    return "documented" if count.documented else "undocumented"
+
+
+__all__ = [
+    "MAX_NAME_LENGTH",
+    "SCRIPT_PATH",
+    "SERVE_BEACON",
+    "PageView",
+    "ViewCount",
+    "ViewLog",
+    "analytics_head",
+    "analytics_pages",
+    "load_views",
+    "most_viewed",
+    "parse_view",
+    "render_report",
+]
@@ -0,0 +1,485 @@
+"""Images and diagrams referenced from docstrings.
+
+Docstrings can show local images and diagrams with the reStructuredText
+directives Sphinx understands, or with Markdown image syntax::
+
+    .. image:: diagrams/flow.png
+       :alt: Request flow
+
+    .. figure:: /docs/architecture.svg
+
+       The services and the queues between them.
+
+    .. uml:: diagrams/login.puml
+
+    .. mermaid::
+
+       graph LR
+         api --> worker
+
+    ![Request flow](diagrams/flow.png)
+
+Paths are relative to the source file; a leading ``/`` makes them relative to
+the tree root, and files outside the tree are refused. :func:`resolve_assets`
+copies each image into :data:`IMAGES_DIR` of the site and rewrites the
+reference to a Markdown image (``![alt](assets/images/...)``), which the
+Markdown pages keep and the HTML renderer turns into a figure. PlantUML
+(``uml``, ``plantuml``, ``.puml`` files) and Mermaid (``mermaid``, ``.mmd``
+files) sources are rendered to images at build time by
+:class:`DiagramRenderer`, configured in ``site.diagrams`` (see
+:class:`~autodoc.config.project.DiagramConfig`).
+
+Package READMEs (see :mod:`services.package_readme`) are rewritten the same
+way, relative to the README. Their links to another package's directory or
+README point at that package's page, and links to other files of the tree
+copy the file into :data:`FILES_DIR`. Links outside the tree, or to missing
+files, are left as written.
+
+Image paths in the site follow the source paths rather than the file
+contents, so pages reused by ``generate --incremental`` keep pointing at the
+current image.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+import shlex
+import subprocess
+import tempfile
+from dataclasses import dataclass, replace
+from pathlib import Path
+from urllib.parse import urlsplit
+
+from autodoc.config.project import DiagramConfig
+from services.doc_site import PackageDoc, SitePage, map_docstrings
+from services.doc_symbols import DocSymbol
+from services.doc_theme import ASSETS_DIR
+from services.package_readme import README_NAME, PackageReadme, outside_fences
+
+logger = logging.getLogger(__name__)
+
+IMAGES_DIR = f"{ASSETS_DIR}/images"
+# Where other files linked from package READMEs are copied.
+FILES_DIR = f"{ASSETS_DIR}/files"
+
+# Diagram languages by source file suffix.
+DIAGRAM_SUFFIXES = {
+    ".puml": "plantuml",
+    ".plantuml": "plantuml",
+    ".pu": "plantuml",
+    ".iuml": "plantuml",
+    ".mmd": "mermaid",
+    ".mermaid": "mermaid",
+}
+# Diagram languages by directive name.
+DIAGRAM_DIRECTIVES = {
+    "uml": "plantuml",
+    "plantuml": "plantuml",
+    "mermaid": "mermaid",
+}
+IMAGE_DIRECTIVES = ("image", "figure")
+
+_DIRECTIVE = re.compile(
+    r"^(?P<indent>[ \t]*)\.\. (?P<name>[a-z]+)::[ \t]*(?P<argument>\S*)[ \t]*$",
+)
+_OPTION = re.compile(r"^:(?P<name>[\w-]+):[ \t]*(?P<value>.*)$")
+_MARKDOWN_IMAGE = re.compile(r"!\[(?P<alt>[^\]]*)\]\((?P<target>[^)\s]+)\)")
+_MARKDOWN_LINK = re.compile(r"(?<!!)\[(?P<text>[^\[\]]*)\]\((?P<target>[^)\s]+)\)")
+_INLINE_CODE = re.compile(r"(``.*?``|`[^`]*`)")
+
+
+class AssetError(Exception):
+    """Raised when a referenced image cannot be read or a diagram rendered."""
+
+
+def _indent(line: str) -> int:
+    return len(line) - len(line.lstrip())
+
+
+def is_local(target: str) -> bool:
+    """Whether ``target`` is a file path rather than a URL or data URI."""
+    return not urlsplit(target).scheme and not target.startswith("#")
+
+
+@dataclass(frozen=True)
+class _Directive:
+    name: str
+    argument: str
+    options: dict[str, str]
+    content: str
+    indent: str
+
+
+def _inside(line: str, indent: int) -> bool:
+    return not line.strip() or _indent(line) > indent
+
+
+def _directive(lines: list[str], start: int) -> tuple[_Directive, int] | None:
+    """The directive starting at ``lines[start]`` and the line after it."""
+    match = _DIRECTIVE.match(lines[start])
+    if match is None:
+        return None
+    indent = _indent(lines[start])
+    end = start + 1
+    while end < len(lines) and _inside(lines[end], indent):
+        end += 1
+    while end > start + 1 and not lines[end - 1].strip():
+        end -= 1
+    body = lines[start + 1 : end]
+    options = {}
+    while body and (option := _OPTION.match(body[0].strip())):
+        options[option.group("name")] = option.group("value").strip()
+        body = body[1:]
+    content_indent = min((_indent(line) for line in body if line.strip()), default=0)
+    content = "\n".join(line[content_indent:] for line in body).strip("\n")
+    directive = _Directive(
+        match.group("name"),
+        match.group("argument"),
+        options,
+        content,
+        match.group("indent"),
+    )
+    return directive, end
+
+
+class DiagramRenderer:
+    """Renders PlantUML and Mermaid sources with their command-line tools.
+
+    PlantUML reads the source on stdin (``plantuml -tsvg -pipe``); Mermaid's
+    ``mmdc`` needs files, so the source goes through a temporary directory.
+    Each distinct source is rendered once per renderer.
+    """
+
+    def __init__(self, config: DiagramConfig | None = None) -> None:
+        self.config = config or DiagramConfig()
+        self._rendered: dict[tuple[str, str], bytes] = {}
+
+    def _run(self, key: str, command: list[str], source: bytes | None) -> bytes:
+        try:
+            result = subprocess.run(
+                command,
+                input=source,
+                capture_output=True,
+                timeout=self.config.timeout,
+                check=True,
+            )
+        except FileNotFoundError as exc:
+            raise AssetError(
+                f"{command[0]} not found; install it or set site.diagrams.{key}",
+            ) from exc
+        except subprocess.TimeoutExpired as exc:
+            raise AssetError(
+                f"{command[0]} did not finish within {self.config.timeout:g}s",
+            ) from exc
+        except subprocess.CalledProcessError as exc:
+            detail = exc.stderr.decode("utf-8", "replace").strip()
+            raise AssetError(f"{command[0]} failed: {detail}") from exc
+        return result.stdout
+
+    def _plantuml(self, source: str) -> bytes:
+        command = [*shlex.split(self.config.plantuml), f"-t{self.config.format}"]
+        return self._run("plantuml", [*command, "-pipe"], source.encode("utf-8"))
+
+    def _mermaid(self, source: str) -> bytes:
+        with tempfile.TemporaryDirectory(prefix="autodoc-mermaid-") as tmp:
+            source_path = Path(tmp) / "diagram.mmd"
+            output_path = Path(tmp) / f"diagram.{self.config.format}"
+            source_path.write_text(source, encoding="utf-8")
+            command = [
+                *shlex.split(self.config.mermaid),
+                "-i",
+                str(source_path),
+                "-o",
+                str(output_path),
+            ]
+            self._run("mermaid", command, None)
+            try:
+                return output_path.read_bytes()
+            except OSError as exc:
+                raise AssetError(f"{command[0]} wrote no {output_path.name}") from exc
+
+    def render(self, language: str, source: str) -> bytes:
+        """The image of ``source``, a ``plantuml`` or ``mermaid`` diagram.
+
+        Raises:
+            AssetError: If the tool is missing, fails, or times out
+        """
+        key = (language, source)
+        if key not in self._rendered:
+            logger.debug("Rendering %s diagram", language)
+            if language == "plantuml":
+                self._rendered[key] = self._plantuml(source)
+            else:
+                self._rendered[key] = self._mermaid(source)
+        return self._rendered[key]
+
+
+class _Collector:
+    """Rewrites the references of one tree and gathers the asset pages."""
+
+    def __init__(
+        self,
+        root: Path,
+        config: DiagramConfig,
+        renderer: DiagramRenderer,
+    ) -> None:
+        self.root = root.resolve()
+        self.config = config
+        self.renderer = renderer
+        self.pages: dict[str, SitePage] = {}
+
+    def _where(self, symbol: DocSymbol | PackageReadme) -> str:
+        if isinstance(symbol, PackageReadme):
+            return symbol.file_path
+        return f"{symbol.file_path}:{symbol.lineno}"
+
+    def _source(
+        self,
+        symbol: DocSymbol | PackageReadme,
+        target: str,
+    ) -> tuple[Path, str]:
+        """The file ``target`` names and its path below the root."""
+        if target.startswith("/"):
+            path = self.root / target.lstrip("/")
+        else:
+            path = Path(symbol.file_path).resolve().parent / target
+        path = path.resolve()
+        if not path.is_relative_to(self.root):
+            raise AssetError(
+                f"{self._where(symbol)}: {target} is outside the source tree",
+            )
+        return path, path.relative_to(self.root).as_posix()
+
+    def _read(
+        self,
+        symbol: DocSymbol | PackageReadme,
+        path: Path,
+        target: str,
+    ) -> bytes:
+        try:
+            return path.read_bytes()
+        except OSError as exc:
+            raise AssetError(
+                f"{self._where(symbol)}: cannot read {target}: "
+                f"{exc.strerror or exc}",
+            ) from exc
+
+    def _add(self, path: str, content: bytes) -> str:
+        self.pages[path] = SitePage(path, content)
+        return path
+
+    def _render(
+        self,
+        symbol: DocSymbol,
+        language: str,
+        source: str,
+        path: str,
+    ) -> str:
+        try:
+            image = self.renderer.render(language, source)
+        except AssetError as exc:
+            raise AssetError(f"{self._where(symbol)}: {exc}") from exc
+        return self._add(f"{path}.{self.config.format}", image)
+
+    def image(self, symbol: DocSymbol | PackageReadme, target: str) -> str:
+        """The site path of the image file ``target``, rendered if a diagram."""
+        path, relative = self._source(symbol, target)
+        content = self._read(symbol, path, target)
+        language = DIAGRAM_SUFFIXES.get(path.suffix.lower())
+        if language is None:
+            return self._add(f"{IMAGES_DIR}/{relative}", content)
+        source = content.decode("utf-8", "replace")
+        return self._render(symbol, language, source, f"{IMAGES_DIR}/{relative}")
+
+    def rewrite(self, symbol: DocSymbol) -> str | None:
+        """``symbol``'s docstring with every local image pointing into the site.
+
+        Literal blocks (the indented lines after ``::``) and doctest lines are
+        left alone, so docstrings can show the directives themselves.
+        """
+        docstring = symbol.docstring
+        if not docstring or not docstring.strip():
+            return docstring
+        lines = docstring.split("\n")
+        output: list[str] = []
+        inline = 0
+        index = 0
+        literal = None
+        while index < len(lines):
+            line = lines[index]
+            if literal is not None and _inside(line, literal):
+                output.append(line)
+                index += 1
+                continue
+            literal = None
+            parsed = _directive(lines, index)
+            if parsed is None:
+                if line.lstrip().startswith((">>>", "...")):
+                    output.append(line)
+                else:
+                    output.append(self._markdown_images(symbol, line))
+                if line.rstrip().endswith("::"):
+                    literal = _indent(line)
+                index += 1
+                continue
+            directive, end = parsed
+            replacement = self._replace(symbol, directive, inline + 1)
+            if replacement is None:
+                output.extend(lines[index:end])
+            else:
+                inline += not directive.argument
+                output.extend(replacement)
+            index = end
+        return "\n".join(output)
+
+    def _markdown_images(self, symbol: DocSymbol | PackageReadme, line: str) -> str:
+        def swap(match: re.Match[str]) -> str:
+            target = match.group("target")
+            if not is_local(target):
+                return match.group()
+            return f"![{match.group('alt')}]({self.image(symbol, target)})"
+
+        # Odd parts are inline code, where an image is only an example.
+        parts = _INLINE_CODE.split(line)
+        return "".join(
+            part if index % 2 else _MARKDOWN_IMAGE.sub(swap, part)
+            for index, part in enumerate(parts)
+        )
+
+    def readme(
+        self,
+        readme: PackageReadme,
+        pages: dict[Path, str],
+    ) -> PackageReadme:
+        """``readme`` with its relative images and links pointing into the site.
+
+        ``pages`` maps the directory of each package to the slug of its page.
+        Fenced code blocks are left alone.
+        """
+        lines = []
+        for line, prose in outside_fences(readme.content):
+            if prose:
+                line = self._markdown_images(readme, line)
+                line = self._markdown_links(readme, line, pages)
+            lines.append(line)
+        return replace(readme, content="\n".join(lines))
+
+    def _markdown_links(
+        self,
+        readme: PackageReadme,
+        line: str,
+        pages: dict[Path, str],
+    ) -> str:
+        def swap(match: re.Match[str]) -> str:
+            target = match.group("target")
+            if not is_local(target):
+                return match.group()
+            return f"[{match.group('text')}]({self._link(readme, target, pages)})"
+
+        parts = _INLINE_CODE.split(line)
+        return "".join(
+            part if index % 2 else _MARKDOWN_LINK.sub(swap, part)
+            for index, part in enumerate(parts)
+        )
+
+    def _link(self, readme: PackageReadme, target: str, pages: dict[Path, str]) -> str:
+        """The site path a README link to the local file ``target`` opens."""
+        name, hashmark, fragment = target.partition("#")
+        try:
+            path, relative = self._source(readme, name)
+        except AssetError as exc:
+            logger.warning("Leaving the link as written: %s", exc)
+            return target
+        directory = path.parent if path.name == README_NAME else path
+        if directory in pages and (path.is_dir() or path.name == README_NAME):
+            return f"{pages[directory]}.md{hashmark}{fragment}"
+        if not path.is_file():
+            logger.warning("%s: no file %s to link to", self._where(readme), target)
+            return target
+        content = self._read(readme, path, target)
+        return self._add(f"{FILES_DIR}/{relative}", content) + hashmark + fragment
+
+    def _replace(
+        self,
+        symbol: DocSymbol,
+        directive: _Directive,
+        number: int,
+    ) -> list[str] | None:
+        """The lines standing in for ``directive``; None to keep it as written."""
+        name, argument = directive.name, directive.argument
+        if name in IMAGE_DIRECTIVES and argument:
+            language = DIAGRAM_SUFFIXES.get(Path(argument).suffix.lower())
+            if language is not None and not self.config.render:
+                return None
+            target = self.image(symbol, argument) if is_local(argument) else argument
+            caption = directive.content if name == "figure" else ""
+        elif name in DIAGRAM_DIRECTIVES and self.config.render:
+            if argument:
+                target = self.image(symbol, argument)
+            elif directive.content:
+                path = f"{IMAGES_DIR}/{symbol.qualified_name}-{number}"
+                language = DIAGRAM_DIRECTIVES[name]
+                target = self._render(symbol, language, directive.content, path)
+            else:
+                return None
+            caption = directive.options.get("caption", "")
+        else:
+            return None
+        alt = directive.options.get("alt") or " ".join(caption.split())
+        lines = [f"{directive.indent}![{alt or Path(target).stem}]({target})"]
+        if caption:
+            lines.append("")
+            lines.extend(f"{directive.indent}{line}" for line in caption.splitlines())
+        return lines
+
+
+def resolve_assets(
+    packages: list[PackageDoc],
+    root: str | Path,
+    config: DiagramConfig | None = None,
+    renderer: DiagramRenderer | None = None,
+) -> tuple[list[PackageDoc], list[SitePage]]:
+    """Copy and render the images ``packages`` reference.
+
+    The READMEs of packages also have their links resolved.
+
+    Returns:
+        Copies of ``packages`` whose docstrings point at the site's images,
+        and the image pages sorted by path
+
+    Raises:
+        AssetError: If an image is missing or outside ``root``, or a diagram
+            cannot be rendered
+    """
+    config = config or DiagramConfig()
+    collector = _Collector(Path(root), config, renderer or DiagramRenderer(config))
+    resolved = map_docstrings(packages, collector.rewrite)
+    directories = {
+        Path(package.modules[0].symbol.file_path).resolve().parent: package.slug
+        for package in packages
+        if package.modules
+    }
+    resolved = [
+        replace(package, readme=collector.readme(package.readme, directories))
+        if package.readme is not None
+        else package
+        for package in resolved
+    ]
+    pages = [collector.pages[path] for path in sorted(collector.pages)]
+    if pages:
+        logger.info("Collected %d image(s) from docstrings", len(pages))
+    return resolved, pages
+
+
+__all__ = [
+    "DIAGRAM_DIRECTIVES",
+    "DIAGRAM_SUFFIXES",
+    "FILES_DIR",
+    "IMAGES_DIR",
+    "IMAGE_DIRECTIVES",
+    "AssetError",
+    "DiagramRenderer",
+    "is_local",
+    "resolve_assets",
+]
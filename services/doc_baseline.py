@@ -0,0 +1,205 @@
+"""Baseline of accepted documentation findings.
+
+A baseline records the lint findings that already exist when enforcement is
+switched on, so checks fail only on *new* problems. Each entry remembers the
+date it was first recorded; rewriting the baseline preserves those dates,
+which powers the aging report used to track how long debt has been tolerated.
+"""
+
+from __future__ import annotations
+
+import json
+from collections import Counter
+from collections.abc import Iterable
+from dataclasses import asdict, dataclass, field
+from datetime import UTC, date, datetime
+from pathlib import Path
+from typing import Any
+
+from services.doc_lint import LintFinding
+from services.schema import BASELINE, SchemaError, stamp_schema, upgrade
+
+DEFAULT_BASELINE_FILE = ".autodoc-baseline.json"
+BASELINE_VERSION = 1
+
+# Upper bounds (in days) of the aging report buckets; the last bucket is open.
+AGE_BUCKETS = (30, 90, 180)
+
+
+class BaselineError(Exception):
+    """Raised when a baseline file cannot be read or is malformed."""
+
+
+@dataclass(frozen=True)
+class BaselineEntry:
+    """A single accepted finding."""
+
+    rule: str
+    symbol: str
+    file_path: str
+    message: str
+    first_seen: str  # ISO date (YYYY-MM-DD)
+
+    @property
+    def key(self) -> tuple[str, str]:
+        return (self.rule, self.symbol)
+
+    def age_days(self, today: date) -> int:
+        return (today - date.fromisoformat(self.first_seen)).days
+
+
+@dataclass
+class Baseline:
+    """The set of accepted findings, keyed like :attr:`LintFinding.key`."""
+
+    entries: list[BaselineEntry] = field(default_factory=list)
+
+    def _keys(self) -> set[tuple[str, str]]:
+        return {entry.key for entry in self.entries}
+
+    def filter_new(self, findings: Iterable[LintFinding]) -> list[LintFinding]:
+        """Return the findings that are not covered by the baseline."""
+        keys = self._keys()
+        return [finding for finding in findings if finding.key not in keys]
+
+    def fixed_entries(self, findings: Iterable[LintFinding]) -> list[BaselineEntry]:
+        """Return baseline entries that no longer occur (candidates to prune)."""
+        current = {finding.key for finding in findings}
+        return [entry for entry in self.entries if entry.key not in current]
+
+    def to_dict(self) -> dict[str, Any]:
+        return stamp_schema(
+            {
+                "version": BASELINE_VERSION,
+                "entries": [asdict(entry) for entry in self.entries],
+            },
+        )
+
+
+def _today() -> date:
+    return datetime.now(UTC).date()
+
+
+def build_baseline(
+    findings: Iterable[LintFinding],
+    previous: Baseline | None = None,
+    today: date | None = None,
+) -> Baseline:
+    """Build a baseline from ``findings``, keeping first-seen dates from ``previous``.
+
+    Findings that were fixed since ``previous`` are dropped.
+    """
+    first_seen = {entry.key: entry.first_seen for entry in (previous or Baseline()).entries}
+    stamp = (today or _today()).isoformat()
+    entries = {
+        finding.key: BaselineEntry(
+            rule=finding.rule,
+            symbol=finding.symbol,
+            file_path=finding.file_path,
+            message=finding.message,
+            first_seen=first_seen.get(finding.key, stamp),
+        )
+        for finding in findings
+    }
+    return Baseline(entries=sorted(entries.values(), key=lambda e: (e.rule, e.symbol)))
+
+
+def load_baseline(path: str | Path) -> Baseline:
+    """Read a baseline file.
+
+    Raises:
+        BaselineError: If the file is missing, unreadable, malformed, or
+            written by a newer AutoDoc
+    """
+    try:
+        data = json.loads(Path(path).read_text(encoding="utf-8"))
+    except (OSError, json.JSONDecodeError) as exc:
+        raise BaselineError(f"Cannot read baseline {path}: {exc}") from exc
+
+    if data.get("version") != BASELINE_VERSION:
+        raise BaselineError(
+            f"Unsupported baseline version {data.get('version')!r} in {path}",
+        )
+    try:
+        data = upgrade(data, BASELINE)
+    except SchemaError as exc:
+        raise BaselineError(f"Cannot read baseline {path}: {exc}") from exc
+    try:
+        entries = [BaselineEntry(**entry) for entry in data.get("entries", [])]
+    except TypeError as exc:
+        raise BaselineError(f"Malformed baseline entry in {path}: {exc}") from exc
+    return Baseline(entries=entries)
+
+
+def render_baseline(baseline: Baseline) -> str:
+    """``baseline`` as stable, diff-friendly JSON."""
+    return json.dumps(baseline.to_dict(), indent=2, sort_keys=True) + "\n"
+
+
+def write_baseline(baseline: Baseline, path: str | Path) -> Path:
+    """Write ``baseline`` to ``path`` (see :func:`render_baseline`)."""
+    target = Path(path)
+    target.write_text(render_baseline(baseline), encoding="utf-8")
+    return target
+
+
+def aging_report(
+    baseline: Baseline,
+    findings: Iterable[LintFinding] | None = None,
+    today: date | None = None,
+    oldest: int = 10,
+) -> dict[str, Any]:
+    """Summarise how long baselined findings have been tolerated.
+
+    Args:
+        baseline: The baseline to report on
+        findings: Current findings; when given, fixed entries are reported
+        today: Reference date (default: today in UTC)
+        oldest: Number of oldest entries to list
+
+    Returns:
+        Counts per age bucket and rule, the oldest entries, and fixed entries
+    """
+    reference = today or _today()
+    buckets: Counter[str] = Counter()
+    labels = [f"<{AGE_BUCKETS[0]}d"]
+    labels += [
+        f"{low}-{high}d" for low, high in zip(AGE_BUCKETS, AGE_BUCKETS[1:], strict=False)
+    ]
+    labels.append(f">={AGE_BUCKETS[-1]}d")
+    for entry in baseline.entries:
+        age = entry.age_days(reference)
+        index = next(
+            (i for i, bound in enumerate(AGE_BUCKETS) if age < bound),
+            len(AGE_BUCKETS),
+        )
+        buckets[labels[index]] += 1
+
+    ordered = sorted(baseline.entries, key=lambda e: (e.first_seen, e.rule, e.symbol))
+    report: dict[str, Any] = {
+        "total": len(baseline.entries),
+        "by_age": {label: buckets.get(label, 0) for label in labels},
+        "by_rule": dict(sorted(Counter(e.rule for e in baseline.entries).items())),
+        "oldest": [
+            {**asdict(entry), "age_days": entry.age_days(reference)}
+            for entry in ordered[:oldest]
+        ],
+    }
+    if findings is not None:
+        report["fixed"] = [asdict(entry) for entry in baseline.fixed_entries(findings)]
+    return report
+
+
+__all__ = [
+    "AGE_BUCKETS",
+    "BASELINE_VERSION",
+    "DEFAULT_BASELINE_FILE",
+    "Baseline",
+    "BaselineEntry",
+    "BaselineError",
+    "aging_report",
+    "build_baseline",
+    "load_baseline",
+    "render_baseline",
+    "write_baseline",
+]
@@ -0,0 +1,304 @@
+"""A terminal browser for the documentation of a tree.
+
+``autodoc browse`` reads the docs of a tree in the terminal, without
+writing a site, which is enough over SSH. :class:`DocBrowser` is the
+browser without the terminal: it takes key names (see :data:`KEYS`) and
+draws screens as lines of text, so the curses front end in
+:mod:`autodoc.cli.browse` only maps keys and paints rows.
+
+There are three kinds of views, stacked as the reader goes deeper:
+
+- the packages, with the number of symbols each documents;
+- the symbols of a package in page order, indented below their module or
+  class, with their summaries;
+- the entry of one symbol, rendered as on the Markdown package page.
+
+``/`` searches every package for symbols whose name or summary holds the
+typed text; ``Backspace`` or ``Left`` goes back a view, and ``q`` quits.
+"""
+
+from __future__ import annotations
+
+import textwrap
+from collections.abc import Sequence
+from dataclasses import dataclass, field
+
+from services.doc_markdown import render_symbol_markdown
+from services.doc_site import PackageDoc, summary
+from services.doc_symbols import DocSymbol
+
+# Key names the browser understands; any other key is a character typed.
+KEYS = (
+    "up",
+    "down",
+    "left",
+    "right",
+    "pageup",
+    "pagedown",
+    "home",
+    "end",
+    "enter",
+    "backspace",
+    "escape",
+)
+# Styles of the rows of a screen.
+STYLES = ("title", "plain", "selected", "status")
+# Most search results listed.
+SEARCH_LIMIT = 200
+_INDENT = {"module": 0, "class": 2, "function": 2, "method": 4}
+_HELP = {
+    "packages": "Enter open  / search  q quit",
+    "symbols": "Enter read  / search  Backspace back  q quit",
+    "doc": "Up/Down scroll  / search  Backspace back  q quit",
+    "search": "Type to search  Enter read  Esc cancel",
+}
+
+
+@dataclass
+class _View:
+    """One view of the stack."""
+
+    kind: str
+    title: str
+    # Rows of a list view, with the package or symbol each one opens.
+    rows: list[str] = field(default_factory=list)
+    targets: list[PackageDoc | tuple[PackageDoc, DocSymbol]] = field(
+        default_factory=list,
+    )
+    # The Markdown of a doc view.
+    text: str = ""
+    # The selected row of a list, or the first line shown of a doc.
+    cursor: int = 0
+    offset: int = 0
+    query: str = ""
+
+
+def search_symbols(
+    packages: Sequence[PackageDoc],
+    query: str,
+    limit: int = SEARCH_LIMIT,
+) -> list[tuple[PackageDoc, DocSymbol]]:
+    """The symbols matching ``query``, best first.
+
+    Matching ignores case. A symbol named ``query`` comes first, then names
+    starting with it, names containing it, qualified names containing it,
+    and last summaries containing it; ties keep page order.
+    """
+    needle = query.strip().lower()
+    if not needle:
+        return []
+    ranked = []
+    order = 0
+    for package in packages:
+        for symbol in package.symbols():
+            name = symbol.name.lower()
+            if name == needle:
+                rank = 0
+            elif name.startswith(needle):
+                rank = 1
+            elif needle in name:
+                rank = 2
+            elif needle in symbol.qualified_name.lower():
+                rank = 3
+            elif needle in summary(symbol.docstring).lower():
+                rank = 4
+            else:
+                continue
+            ranked.append((rank, order, package, symbol))
+            order += 1
+    ranked.sort(key=lambda item: item[:2])
+    return [(package, symbol) for _, _, package, symbol in ranked[:limit]]
+
+
+def _symbol_row(symbol: DocSymbol, qualified: bool = False) -> str:
+    # Modules are known by their full name.
+    name = symbol.qualified_name if qualified else symbol.name
+    if symbol.kind == "module":
+        name = symbol.qualified_name
+    text = summary(symbol.docstring)
+    indent = "" if qualified else " " * _INDENT.get(symbol.kind, 0)
+    row = f"{indent}{symbol.kind} {name}"
+    return f"{row} - {text}" if text else row
+
+
+def wrap_markdown(text: str, width: int) -> list[str]:
+    """The lines of ``text`` wrapped to ``width``; code blocks are not wrapped."""
+    lines = []
+    in_code = False
+    for line in text.splitlines():
+        if line.lstrip().startswith("```"):
+            in_code = not in_code
+            lines.append(line)
+        elif in_code or not line.strip():
+            lines.append(line)
+        else:
+            indent = line[: len(line) - len(line.lstrip())]
+            lines.extend(
+                textwrap.wrap(
+                    line,
+                    max(width, 10),
+                    subsequent_indent=indent,
+                    break_on_hyphens=False,
+                ),
+            )
+    return lines
+
+
+class DocBrowser:
+    """The views of the docs of ``packages`` and the keys that move between them."""
+
+    def __init__(self, packages: Sequence[PackageDoc], title: str) -> None:
+        self.packages = list(packages)
+        self.title = title
+        self.views = [
+            _View(
+                "packages",
+                title,
+                rows=[
+                    f"{p.name} ({len(p.symbols())} symbols)" for p in self.packages
+                ],
+                targets=list(self.packages),
+            ),
+        ]
+
+    @property
+    def view(self) -> _View:
+        return self.views[-1]
+
+    def open_package(self, package: PackageDoc) -> None:
+        symbols = package.symbols()
+        self.views.append(
+            _View(
+                "symbols",
+                package.name,
+                rows=[_symbol_row(symbol) for symbol in symbols],
+                targets=[(package, symbol) for symbol in symbols],
+            ),
+        )
+
+    def open_symbol(self, package: PackageDoc, symbol: DocSymbol) -> None:
+        text = render_symbol_markdown(package, symbol.qualified_name) or ""
+        self.views.append(_View("doc", symbol.qualified_name, text=text))
+
+    def find(self, qualified_name: str) -> tuple[PackageDoc, DocSymbol] | None:
+        """The package and symbol named ``qualified_name``, if documented."""
+        for package in self.packages:
+            for symbol in package.symbols():
+                if symbol.qualified_name == qualified_name:
+                    return package, symbol
+        return None
+
+    def _search(self, query: str) -> None:
+        view = self.view
+        view.query = query
+        results = search_symbols(self.packages, query)
+        view.rows = [_symbol_row(symbol, qualified=True) for _, symbol in results]
+        view.targets = list(results)
+        view.cursor = 0
+        view.offset = 0
+
+    def _open(self) -> None:
+        view = self.view
+        if not view.targets:
+            return
+        target = view.targets[view.cursor]
+        if isinstance(target, tuple):
+            self.open_symbol(*target)
+        else:
+            self.open_package(target)
+
+    def _back(self) -> None:
+        if len(self.views) > 1:
+            self.views.pop()
+
+    def press(self, key: str, height: int = 24) -> bool:
+        """Handle ``key`` on a screen ``height`` rows high; False to quit."""
+        view = self.view
+        page = max(height - 3, 1)
+        if view.kind == "search":
+            if key == "escape":
+                self._back()
+            elif key == "backspace":
+                self._search(view.query[:-1])
+            elif key in ("enter", "right"):
+                self._open()
+            elif key in ("up", "down", "pageup", "pagedown", "home", "end"):
+                self._move(key, page)
+            elif key not in KEYS and key.isprintable():
+                self._search(view.query + key)
+            return True
+        if key == "q":
+            return False
+        if key == "/":
+            self.views.append(_View("search", "Search"))
+        elif key in ("backspace", "left", "escape", "h"):
+            self._back()
+        elif key in ("enter", "right", "l") and view.kind != "doc":
+            self._open()
+        else:
+            aliases = {"k": "up", "j": "down", "g": "home", "G": "end", " ": "pagedown"}
+            self._move(aliases.get(key, key), page)
+        return True
+
+    def _move(self, key: str, page: int) -> None:
+        view = self.view
+        steps = {"up": -1, "down": 1, "pageup": -page, "pagedown": page}
+        if view.kind == "doc":
+            # The length of the wrapped text is only known when drawing.
+            if key in steps:
+                view.offset = max(view.offset + steps[key], 0)
+            elif key == "home":
+                view.offset = 0
+            elif key == "end":
+                view.offset = len(view.text.splitlines())
+            return
+        last = max(len(view.rows) - 1, 0)
+        if key in steps:
+            view.cursor = min(max(view.cursor + steps[key], 0), last)
+        elif key == "home":
+            view.cursor = 0
+        elif key == "end":
+            view.cursor = last
+        rows = max(page, 1)
+        if view.cursor < view.offset:
+            view.offset = view.cursor
+        elif view.cursor >= view.offset + rows:
+            view.offset = view.cursor - rows + 1
+
+    def screen(self, width: int, height: int) -> list[tuple[str, str]]:
+        """The rows of a ``width`` by ``height`` screen, each with a style.
+
+        The first row is the path of views, the last the keys of this view.
+        """
+        view = self.view
+        body = max(height - 2, 0)
+        path = " > ".join(v.title for v in self.views if v.kind != "search")
+        title = f"{path}  /{view.query}" if view.kind == "search" else path
+        rows = [(title[:width], "title")]
+        if view.kind == "doc":
+            lines = wrap_markdown(view.text, width)
+            view.offset = min(view.offset, max(len(lines) - body, 0))
+            shown = lines[view.offset : view.offset + body]
+            rows.extend((line[:width], "plain") for line in shown)
+        else:
+            if view.cursor >= view.offset + body:
+                view.offset = view.cursor - body + 1
+            for index in range(view.offset, min(view.offset + body, len(view.rows))):
+                style = "selected" if index == view.cursor else "plain"
+                rows.append((view.rows[index][:width], style))
+            if not view.rows:
+                empty = "No matches" if view.query else "Nothing to show"
+                rows.append((empty[:width], "plain"))
+        rows.extend(("", "plain") for _ in range(height - 1 - len(rows)))
+        rows.append((_HELP[view.kind][:width], "status"))
+        return rows[:height]
+
+
+__all__ = [
+    "KEYS",
+    "SEARCH_LIMIT",
+    "STYLES",
+    "DocBrowser",
+    "search_symbols",
+    "wrap_markdown",
+]
@@ -0,0 +1,169 @@
+"""Fingerprinted assets and cache header manifests for hosted HTML sites.
+
+Configured by the ``site.caching`` section (see
+:class:`~autodoc.config.project.CachingConfig`). Fingerprinting renames each
+theme asset below ``assets/`` (stylesheets, the logo, and any scripts) to
+``<name>.<hash>.<ext>``, where the hash is taken from the file's content, so a
+changed file always gets a new URL and can be cached forever. References in the
+HTML pages are rewritten to match. Docstring images keep their paths, since the
+Markdown and JSON pages refer to them too.
+
+The cache headers are written as::
+
+    _headers            Netlify and Cloudflare Pages header rules
+    _s3-metadata.json   CacheControl and ContentType per S3 object key
+
+Neither is part of the site; leave ``_s3-metadata.json`` out of the upload and
+pass its values to ``aws s3 cp --cache-control ... --content-type ...``.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import json
+import mimetypes
+import re
+from collections.abc import Iterable, Sequence
+from pathlib import PurePosixPath
+
+from autodoc.config.project import CachingConfig
+from services.doc_assets import IMAGES_DIR
+from services.doc_site import SitePage
+from services.doc_theme import ASSETS_DIR
+from services.schema import stamp_schema
+
+NETLIFY_HEADERS_FILE = "_headers"
+S3_METADATA_FILE = "_s3-metadata.json"
+
+# Length of the content hash put into fingerprinted file names.
+_HASH_LENGTH = 10
+
+
+def _is_theme_asset(path: str) -> bool:
+    return path.startswith(f"{ASSETS_DIR}/") and not path.startswith(
+        f"{IMAGES_DIR}/",
+    )
+
+
+def _content_bytes(page: SitePage) -> bytes:
+    if isinstance(page.content, str):
+        return page.content.encode("utf-8")
+    return page.content
+
+
+def _text(page: SitePage) -> str:
+    if isinstance(page.content, bytes):
+        return page.content.decode("utf-8")
+    return page.content
+
+
+def fingerprinted_path(path: str, content: bytes) -> str:
+    """``path`` with the hash of ``content`` before its suffix."""
+    digest = hashlib.sha256(content).hexdigest()[:_HASH_LENGTH]
+    name = PurePosixPath(path)
+    return str(name.with_name(f"{name.stem}.{digest}{name.suffix}"))
+
+
+def _reference(path: str) -> re.Pattern[str]:
+    """Matches ``path`` as a URL, plain or already fingerprinted.
+
+    Pages carried over by ``generate --incremental`` still name the asset by
+    its previous fingerprint, which has to be replaced too.
+    """
+    name = PurePosixPath(path)
+    stem = re.escape(str(name.with_suffix("")))
+    return re.compile(
+        rf"(?<=[\"']){stem}(?:\.[0-9a-f]{{{_HASH_LENGTH}}})?"
+        rf"{re.escape(name.suffix)}(?=[\"'?#])",
+    )
+
+
+def fingerprint_assets(pages: Sequence[SitePage]) -> list[SitePage]:
+    """Rename theme assets after their content and rewrite their references."""
+    renames = {
+        page.path: fingerprinted_path(page.path, _content_bytes(page))
+        for page in pages
+        if _is_theme_asset(page.path)
+    }
+    patterns = [(_reference(old), new) for old, new in renames.items()]
+    result = []
+    for page in pages:
+        content = page.content
+        if page.path.endswith(".html") and patterns:
+            content = _text(page)
+            for pattern, new in patterns:
+                content = pattern.sub(new, content)
+        result.append(SitePage(renames.get(page.path, page.path), content))
+    return result
+
+
+def cache_control(path: str, config: CachingConfig) -> str:
+    """The ``Cache-Control`` header for the file at ``path``."""
+    if config.fingerprint and _is_theme_asset(path):
+        return f"public, max-age={config.asset_max_age}, immutable"
+    return f"public, max-age={config.page_max_age}, must-revalidate"
+
+
+def content_type(path: str) -> str:
+    """The ``Content-Type`` of the file at ``path``; text is UTF-8."""
+    kind = mimetypes.guess_type(path)[0] or "application/octet-stream"
+    if kind.startswith("text/") or kind in ("image/svg+xml", "application/json"):
+        return f"{kind}; charset=utf-8"
+    return kind
+
+
+def _urls(path: str) -> list[str]:
+    """The URLs a file is served at; ``index.html`` also at its directory."""
+    urls = [f"/{path}"]
+    if path == "index.html" or path.endswith("/index.html"):
+        urls.insert(0, f"/{path[: -len('index.html')]}")
+    return urls
+
+
+def render_headers(
+    paths: Iterable[str],
+    config: CachingConfig,
+) -> list[SitePage]:
+    """The manifests of ``config.headers`` giving the files at ``paths`` headers."""
+    files = sorted(set(paths))
+    manifests = []
+    if "netlify" in config.headers:
+        rules = "".join(
+            f"{url}\n  Cache-Control: {cache_control(path, config)}\n"
+            for path in files
+            for url in _urls(path)
+        )
+        manifests.append(SitePage(NETLIFY_HEADERS_FILE, rules))
+    if "s3" in config.headers:
+        objects = {
+            path: {
+                "CacheControl": cache_control(path, config),
+                "ContentType": content_type(path),
+            }
+            for path in files
+        }
+        manifests.append(
+            SitePage(
+                S3_METADATA_FILE,
+                json.dumps(stamp_schema({"objects": objects}), indent=2) + "\n",
+            ),
+        )
+    return manifests
+
+
+def apply_caching(pages: Sequence[SitePage], config: CachingConfig) -> list[SitePage]:
+    """``pages`` fingerprinted and with their header manifests, per ``config``."""
+    result = fingerprint_assets(pages) if config.fingerprint else list(pages)
+    return result + render_headers((page.path for page in result), config)
+
+
+__all__ = [
+    "NETLIFY_HEADERS_FILE",
+    "S3_METADATA_FILE",
+    "apply_caching",
+    "cache_control",
+    "content_type",
+    "fingerprint_assets",
+    "fingerprinted_path",
+    "render_headers",
+]
@@ -0,0 +1,90 @@
+"""Documentation coverage metrics per package.
+
+Coverage is the share of exported symbols (see
+:func:`services.doc_symbols.is_exported`) that carry a docstring, computed
+independently for every package in the source tree.
+"""
+
+from __future__ import annotations
+
+from collections import defaultdict
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+from services.doc_symbols import DocSymbol, is_exported, load_doc_symbols
+
+
+@dataclass
+class PackageCoverage:
+    """Documentation coverage for a single package."""
+
+    package: str
+    total: int = 0
+    documented: int = 0
+    undocumented: list[str] = field(default_factory=list)
+
+    @property
+    def percent(self) -> float:
+        """Coverage as a percentage; empty packages count as fully covered."""
+        if self.total == 0:
+            return 100.0
+        return round(self.documented / self.total * 100, 2)
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "package": self.package,
+            "total": self.total,
+            "documented": self.documented,
+            "percent": self.percent,
+            "undocumented": self.undocumented,
+        }
+
+
+def compute_coverage(symbols: Iterable[DocSymbol]) -> list[PackageCoverage]:
+    """Aggregate exported symbols into per-package coverage, sorted by package.
+
+    Args:
+        symbols: Symbols produced by the doc symbol loader
+
+    Returns:
+        One :class:`PackageCoverage` per package that has exported symbols
+    """
+    packages: dict[str, PackageCoverage] = defaultdict(lambda: PackageCoverage(""))
+    for symbol in symbols:
+        if not is_exported(symbol):
+            continue
+        coverage = packages[symbol.package]
+        coverage.package = symbol.package
+        coverage.total += 1
+        if symbol.is_documented:
+            coverage.documented += 1
+        else:
+            coverage.undocumented.append(symbol.qualified_name)
+
+    result = sorted(packages.values(), key=lambda c: c.package)
+    for coverage in result:
+        coverage.undocumented.sort()
+    return result
+
+
+def compute_package_coverage(root: str | Path) -> list[PackageCoverage]:
+    """Load every Python file below ``root`` and compute package coverage."""
+    return compute_coverage(load_doc_symbols(root))
+
+
+def packages_below_threshold(
+    coverage: Iterable[PackageCoverage],
+    threshold: float,
+) -> list[PackageCoverage]:
+    """Return packages whose coverage percentage is below ``threshold``."""
+    return [c for c in coverage if c.percent < threshold]
+
+
+__all__ = [
+    "PackageCoverage",
+    "compute_coverage",
+    "compute_package_coverage",
+    "packages_below_threshold",
+]
@@ -0,0 +1,431 @@
+"""Issue auto-filing for documentation debt.
+
+Packages whose documentation coverage falls below a threshold get a tracking
+issue in Jira or GitHub. Each issue is tagged with a stable key derived from
+the package name, so repeated runs update the existing open issue instead of
+filing a new one.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import logging
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from typing import Any, Protocol
+
+import httpx
+from pydantic import ValidationError
+from pydantic_settings import BaseSettings
+
+from autodoc.config.secrets import redact
+from autodoc.config.settings import GitHubSettings, JiraSettings, get_settings
+from services.doc_coverage import PackageCoverage
+from services.http_client import integration_client
+
+logger = logging.getLogger(__name__)
+
+# Label attached to every documentation-debt issue, independent of package.
+DEBT_LABEL = "autodoc-doc-debt"
+
+# Maximum number of undocumented symbols listed in an issue body.
+MAX_LISTED_SYMBOLS = 50
+
+
+class IssueTrackerError(Exception):
+    """Raised when an issue tracker request fails."""
+
+
+class IssueTrackerConfigurationError(IssueTrackerError):
+    """Raised when issue tracker settings are missing or invalid."""
+
+
+@dataclass(slots=True)
+class TrackedIssue:
+    """An issue as reported back by a tracker."""
+
+    id: str
+    url: str | None = None
+
+
+class IssueTracker(Protocol):
+    """Minimal tracker interface used by :class:`DocDebtIssueFiler`."""
+
+    def find_open_issue(self, key: str) -> TrackedIssue | None:
+        """Return the open issue tagged with ``key``, if any."""
+        ...
+
+    def create_issue(self, key: str, title: str, body: str) -> TrackedIssue:
+        """Create a new issue tagged with ``key``."""
+        ...
+
+    def update_issue(self, issue: TrackedIssue, title: str, body: str) -> None:
+        """Replace the title and body of an existing issue."""
+        ...
+
+
+def debt_issue_key(package: str) -> str:
+    """Return the stable deduplication key for a package's debt issue.
+
+    The key is a label-safe hash of the package name so it survives trackers
+    that restrict label characters (Jira forbids spaces, for example).
+    """
+    digest = hashlib.sha1(package.encode("utf-8")).hexdigest()[:12]
+    return f"{DEBT_LABEL}-{digest}"
+
+
+def build_issue_title(coverage: PackageCoverage) -> str:
+    """Build the issue title for a package below the threshold."""
+    return (
+        f"Documentation coverage for {coverage.package} is "
+        f"{coverage.percent:.1f}%"
+    )
+
+
+def build_issue_body(coverage: PackageCoverage, threshold: float) -> str:
+    """Build a Markdown issue body listing the undocumented symbols."""
+    lines = [
+        f"Package `{coverage.package}` is below the documentation coverage "
+        f"threshold of {threshold:.1f}%.",
+        "",
+        f"- Coverage: {coverage.percent:.1f}%",
+        f"- Documented symbols: {coverage.documented} of {coverage.total}",
+        "",
+        "Undocumented exported symbols:",
+        "",
+    ]
+    listed = coverage.undocumented[:MAX_LISTED_SYMBOLS]
+    lines.extend(f"- `{name}`" for name in listed)
+    remaining = len(coverage.undocumented) - len(listed)
+    if remaining > 0:
+        lines.append(f"- ...and {remaining} more")
+    lines.extend(
+        [
+            "",
+            f"_This issue is maintained by AutoDoc (key: "
+            f"`{debt_issue_key(coverage.package)}`)._",
+        ],
+    )
+    return "\n".join(lines)
+
+
+@dataclass
+class IssueFilingResult:
+    """Outcome of a documentation-debt filing pass."""
+
+    created: list[str] = field(default_factory=list)
+    updated: list[str] = field(default_factory=list)
+    skipped: list[str] = field(default_factory=list)
+    errors: list[str] = field(default_factory=list)
+
+    @property
+    def success(self) -> bool:
+        return not self.errors
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "success": self.success,
+            "created": self.created,
+            "updated": self.updated,
+            "skipped": self.skipped,
+            "errors": self.errors,
+        }
+
+
+class DocDebtIssueFiler:
+    """Open or update one tracker issue per package below the threshold.
+
+    ``tracker`` may be ``None`` when the filer is only used for dry runs;
+    without one, every package is reported as created.
+    """
+
+    def __init__(self, tracker: IssueTracker | None, threshold: float) -> None:
+        self._tracker = tracker
+        self.threshold = threshold
+
+    def file_issues(
+        self,
+        coverage: Iterable[PackageCoverage],
+        *,
+        dry_run: bool = False,
+    ) -> IssueFilingResult:
+        """File issues for every package below the threshold.
+
+        Args:
+            coverage: Per-package coverage results
+            dry_run: Report what would be filed, looking up the open issues
+                but neither creating nor updating any
+
+        Returns:
+            Packages grouped by the action taken for them
+        """
+        result = IssueFilingResult()
+        for package_coverage in coverage:
+            package = package_coverage.package
+            if package_coverage.percent >= self.threshold:
+                result.skipped.append(package)
+                continue
+
+            key = debt_issue_key(package)
+            title = build_issue_title(package_coverage)
+            body = build_issue_body(package_coverage, self.threshold)
+
+            try:
+                existing = None
+                if self._tracker is not None:
+                    existing = self._tracker.find_open_issue(key)
+                if dry_run or self._tracker is None:
+                    action = "create" if existing is None else f"update {existing.id}"
+                    logger.info(
+                        "Dry run: would %s documentation-debt issue for %s",
+                        action,
+                        package,
+                        extra={"package": package, "issue_key": key},
+                    )
+                    if existing is None:
+                        result.created.append(package)
+                    else:
+                        result.updated.append(package)
+                elif existing is None:
+                    issue = self._tracker.create_issue(key, title, body)
+                    result.created.append(package)
+                    logger.info(
+                        "Created documentation-debt issue %s for %s",
+                        issue.id,
+                        package,
+                        extra={"package": package, "issue_key": key},
+                    )
+                else:
+                    self._tracker.update_issue(existing, title, body)
+                    result.updated.append(package)
+                    logger.info(
+                        "Updated documentation-debt issue %s for %s",
+                        existing.id,
+                        package,
+                        extra={"package": package, "issue_key": key},
+                    )
+            except IssueTrackerError as exc:
+                logger.exception(
+                    "Failed to file documentation-debt issue for %s",
+                    package,
+                )
+                result.errors.append(f"{package}: {exc}")
+
+        return result
+
+
+def _request(
+    client: Any,
+    method: str,
+    url: str,
+    detail: str,
+    **kwargs: Any,
+) -> httpx.Response:
+    """Send a request, raising :class:`IssueTrackerError` if it fails.
+
+    A request that cannot be sent (refused connection, timeout) fails like
+    an error response, so filing goes on with the next package.
+    """
+    try:
+        response = getattr(client, method)(url, **kwargs)
+    except httpx.RequestError as exc:
+        raise IssueTrackerError(redact(f"{detail}: {exc}")) from exc
+    try:
+        response.raise_for_status()
+    except httpx.HTTPStatusError as exc:
+        raise IssueTrackerError(
+            redact(f"{detail}: {exc.response.status_code} {exc.response.text}"),
+        ) from exc
+    return response
+
+
+class JiraIssueTracker:
+    """Jira REST API (v2) implementation of :class:`IssueTracker`."""
+
+    def __init__(
+        self,
+        *,
+        settings: JiraSettings | None = None,
+        client: httpx.Client | None = None,
+    ) -> None:
+        self._settings = settings or get_settings().jira
+        if not self._settings.is_configured:
+            raise IssueTrackerConfigurationError(
+                "Jira is not configured. Set JIRA_URL, JIRA_USERNAME, "
+                "JIRA_TOKEN, and JIRA_PROJECT_KEY.",
+            )
+        base_url = (self._settings.url or "").rstrip("/") + "/rest/api/2"
+        self._client = integration_client(
+            "jira",
+            self._settings,
+            client,
+            base_url=base_url,
+            auth=httpx.BasicAuth(
+                self._settings.username or "",
+                self._settings.token or "",
+            ),
+            headers={"Content-Type": "application/json"},
+        )
+
+    def find_open_issue(self, key: str) -> TrackedIssue | None:
+        jql = (
+            f'project = "{self._settings.project_key}" AND labels = "{key}" '
+            "AND statusCategory != Done ORDER BY created ASC"
+        )
+        response = _request(
+            self._client,
+            "get",
+            "/search",
+            "Failed to search Jira issues",
+            params={"jql": jql, "maxResults": 1, "fields": "summary"},
+        )
+        issues = response.json().get("issues", [])
+        if not issues:
+            return None
+        return TrackedIssue(id=issues[0]["key"], url=issues[0].get("self"))
+
+    def create_issue(self, key: str, title: str, body: str) -> TrackedIssue:
+        payload = {
+            "fields": {
+                "project": {"key": self._settings.project_key},
+                "issuetype": {"name": self._settings.issue_type},
+                "summary": title,
+                "description": body,
+                "labels": [DEBT_LABEL, key],
+            },
+        }
+        response = _request(
+            self._client,
+            "post",
+            "/issue",
+            "Failed to create Jira issue",
+            json=payload,
+        )
+        data = response.json()
+        return TrackedIssue(id=data["key"], url=data.get("self"))
+
+    def update_issue(self, issue: TrackedIssue, title: str, body: str) -> None:
+        _request(
+            self._client,
+            "put",
+            f"/issue/{issue.id}",
+            f"Failed to update Jira issue {issue.id}",
+            json={"fields": {"summary": title, "description": body}},
+        )
+
+    def close(self) -> None:
+        """Explicitly close the underlying HTTP client."""
+        self._client.close()
+
+
+class GitHubIssueTracker:
+    """GitHub Issues REST API implementation of :class:`IssueTracker`."""
+
+    def __init__(
+        self,
+        *,
+        settings: GitHubSettings | None = None,
+        client: httpx.Client | None = None,
+    ) -> None:
+        self._settings = settings or get_settings().github
+        if not self._settings.is_configured:
+            raise IssueTrackerConfigurationError(
+                "GitHub is not configured. Set GITHUB_TOKEN and GITHUB_REPOSITORY.",
+            )
+        self._repo_path = f"/repos/{self._settings.repository}"
+        self._client = integration_client(
+            "github",
+            self._settings,
+            client,
+            base_url=self._settings.api_url.rstrip("/"),
+            headers={
+                "Accept": "application/vnd.github+json",
+                "Authorization": f"Bearer {self._settings.token}",
+            },
+        )
+
+    def find_open_issue(self, key: str) -> TrackedIssue | None:
+        response = _request(
+            self._client,
+            "get",
+            f"{self._repo_path}/issues",
+            "Failed to search GitHub issues",
+            params={"labels": key, "state": "open", "per_page": 1},
+        )
+        issues = response.json()
+        if not issues:
+            return None
+        return TrackedIssue(id=str(issues[0]["number"]), url=issues[0].get("html_url"))
+
+    def create_issue(self, key: str, title: str, body: str) -> TrackedIssue:
+        response = _request(
+            self._client,
+            "post",
+            f"{self._repo_path}/issues",
+            "Failed to create GitHub issue",
+            json={"title": title, "body": body, "labels": [DEBT_LABEL, key]},
+        )
+        data = response.json()
+        return TrackedIssue(id=str(data["number"]), url=data.get("html_url"))
+
+    def update_issue(self, issue: TrackedIssue, title: str, body: str) -> None:
+        _request(
+            self._client,
+            "patch",
+            f"{self._repo_path}/issues/{issue.id}",
+            f"Failed to update GitHub issue {issue.id}",
+            json={"title": title, "body": body},
+        )
+
+    def close(self) -> None:
+        """Explicitly close the underlying HTTP client."""
+        self._client.close()
+
+
+def get_issue_tracker(
+    name: str,
+    overrides: dict[str, Any] | None = None,
+) -> IssueTracker:
+    """Construct the issue tracker registered under ``name``.
+
+    Args:
+        name: ``jira`` or ``github``
+        overrides: Settings taking precedence over the environment, such as
+            the resolved ``integrations`` section of ``autodoc.yaml``
+    """
+    trackers: dict[str, tuple[Any, type[BaseSettings]]] = {
+        "jira": (JiraIssueTracker, JiraSettings),
+        "github": (GitHubIssueTracker, GitHubSettings),
+    }
+    try:
+        tracker_cls, settings_cls = trackers[name.lower()]
+    except KeyError:
+        raise IssueTrackerConfigurationError(
+            f"Unknown issue tracker '{name}'. Choose one of: {', '.join(trackers)}",
+        ) from None
+    if not overrides:
+        return tracker_cls()
+    try:
+        settings = settings_cls(**overrides)
+    except ValidationError as exc:
+        raise IssueTrackerConfigurationError(
+            redact(f"Invalid {name} settings: {exc}"),
+        ) from None
+    return tracker_cls(settings=settings)
+
+
+__all__ = [
+    "DEBT_LABEL",
+    "DocDebtIssueFiler",
+    "GitHubIssueTracker",
+    "IssueFilingResult",
+    "IssueTracker",
+    "IssueTrackerConfigurationError",
+    "IssueTrackerError",
+    "JiraIssueTracker",
+    "TrackedIssue",
+    "build_issue_body",
+    "build_issue_title",
+    "debt_issue_key",
+    "get_issue_tracker",
+]
@@ -0,0 +1,469 @@
+"""Periodic documentation digests, for ``autodoc digest``.
+
+A digest compares the tree at the last commit before the period started with
+the tree at ``HEAD``, both read from git rather than the working directory,
+and summarizes what changed for the people who do not read every pull
+request:
+
+- API changes: the lines the API manifest (:mod:`services.api_manifest`)
+  gained and lost, classified the way ``autodoc api --bump`` classifies
+  them (:func:`services.semver.classify`);
+- new documentation: exported symbols that gained a docstring;
+- coverage movement, overall and per package;
+- the top undocumented additions: new exported symbols without a
+  docstring, classes first, larger definitions before smaller ones.
+
+Digests render as Markdown or as a self-contained HTML page with inline
+styles that can be pasted into, or sent as, an email.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+from collections.abc import Iterable, Sequence
+from dataclasses import dataclass, field
+from datetime import UTC, datetime, timedelta
+from html import escape
+from pathlib import Path, PurePosixPath
+from typing import Any
+
+from services.api_manifest import ManifestDiff, diff_manifest, manifest_lines
+from services.doc_coverage import PackageCoverage, compute_coverage
+from services.doc_site import summary
+from services.doc_symbols import (
+    DEFAULT_EXCLUDED_DIRS,
+    DocSymbol,
+    DocSymbolLoader,
+    is_exported,
+)
+from services.git_source import (
+    commit_before,
+    commit_count,
+    head_commit,
+    repo_root,
+    show_file,
+    tracked_files,
+)
+from services.ignore_file import load_ignore_file
+from services.semver import classify
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_PERIOD = "7d"
+DEFAULT_TOP = 10
+
+_PERIOD = re.compile(r"^(\d+)([hdw])$")
+_UNITS = {"h": "hours", "d": "days", "w": "weeks"}
+# Undocumented additions are ranked by kind first.
+_KIND_ORDER = {"class": 0, "function": 1, "method": 2, "module": 3}
+
+
+class DigestError(Exception):
+    """Raised when a digest cannot be put together."""
+
+
+def parse_period(text: str) -> timedelta:
+    """The length of a period such as ``7d``, ``12h``, or ``2w``.
+
+    Raises:
+        DigestError: If ``text`` is not a positive number of hours, days, or
+            weeks
+    """
+    match = _PERIOD.match(text.strip())
+    if match is None or int(match.group(1)) == 0:
+        raise DigestError(
+            f"Invalid period {text!r}: use a number of hours, days, or weeks "
+            "such as 12h, 7d, or 2w",
+        )
+    return timedelta(**{_UNITS[match.group(2)]: int(match.group(1))})
+
+
+@dataclass
+class CoverageChange:
+    """The coverage of one package at the start and end of the period."""
+
+    package: str
+    before: PackageCoverage | None
+    after: PackageCoverage | None
+
+    @property
+    def before_percent(self) -> float | None:
+        return self.before.percent if self.before is not None else None
+
+    @property
+    def after_percent(self) -> float | None:
+        return self.after.percent if self.after is not None else None
+
+    @property
+    def delta(self) -> float:
+        """Percentage points gained; a new package counts from zero."""
+        return round((self.after_percent or 0.0) - (self.before_percent or 0.0), 2)
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "package": self.package,
+            "before": self.before_percent,
+            "after": self.after_percent,
+            "delta": self.delta,
+        }
+
+
+def _percent(coverage: Iterable[PackageCoverage]) -> float:
+    coverage = list(coverage)
+    total = sum(c.total for c in coverage)
+    if total == 0:
+        return 100.0
+    return round(sum(c.documented for c in coverage) / total * 100, 2)
+
+
+@dataclass
+class Digest:
+    """What changed in the documentation between two commits."""
+
+    since: datetime
+    until: datetime
+    base: str | None
+    head: str
+    commits: int
+    api: ManifestDiff = field(default_factory=ManifestDiff)
+    documented: list[DocSymbol] = field(default_factory=list)
+    coverage: list[CoverageChange] = field(default_factory=list)
+    undocumented: list[DocSymbol] = field(default_factory=list)
+    coverage_before: float = 100.0
+    coverage_after: float = 100.0
+
+    @property
+    def change(self) -> str:
+        """The semver class of the API changes."""
+        return classify(self.api)
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "since": self.since.isoformat(),
+            "until": self.until.isoformat(),
+            "base": self.base,
+            "head": self.head,
+            "commits": self.commits,
+            "api": {**self.api.to_dict(), "change": self.change},
+            "documented": [_entry(s) for s in self.documented],
+            "coverage": {
+                "before": self.coverage_before,
+                "after": self.coverage_after,
+                "packages": [c.to_dict() for c in self.coverage],
+            },
+            "undocumented": [_entry(s) for s in self.undocumented],
+        }
+
+
+def _entry(symbol: DocSymbol) -> dict[str, Any]:
+    return {
+        "qualified_name": symbol.qualified_name,
+        "kind": symbol.kind,
+        "file_path": symbol.file_path,
+        "lineno": symbol.lineno,
+        "summary": summary(symbol.docstring),
+    }
+
+
+def _rank(symbol: DocSymbol) -> tuple[int, int, str]:
+    size = max(symbol.end_lineno - symbol.lineno, 0)
+    kind = _KIND_ORDER.get(symbol.kind, len(_KIND_ORDER))
+    return (kind, -size, symbol.qualified_name)
+
+
+def build_digest(
+    before: Sequence[DocSymbol],
+    after: Sequence[DocSymbol],
+    since: datetime,
+    until: datetime,
+    base: str | None,
+    head: str,
+    commits: int = 0,
+    top: int = DEFAULT_TOP,
+) -> Digest:
+    """Compare the symbols ``before`` and ``after`` the period.
+
+    Args:
+        before: Symbols of the tree at ``base``; empty if the tree is newer
+            than the period
+        after: Symbols of the tree at ``head``
+        top: How many undocumented additions to list
+    """
+    exported_before = {s.qualified_name: s for s in before if is_exported(s)}
+    exported_after = [s for s in after if is_exported(s)]
+    documented = [
+        symbol
+        for symbol in exported_after
+        if symbol.is_documented
+        and not (
+            symbol.qualified_name in exported_before
+            and exported_before[symbol.qualified_name].is_documented
+        )
+    ]
+    additions = [
+        symbol
+        for symbol in exported_after
+        if symbol.qualified_name not in exported_before and not symbol.is_documented
+    ]
+
+    old = {c.package: c for c in compute_coverage(before)}
+    new = {c.package: c for c in compute_coverage(after)}
+    coverage = [
+        CoverageChange(package, old.get(package), new.get(package))
+        for package in sorted(old.keys() | new.keys())
+    ]
+    moved = [
+        change
+        for change in coverage
+        if change.before is None
+        or change.after is None
+        or (change.before.total, change.before.documented)
+        != (change.after.total, change.after.documented)
+    ]
+    return Digest(
+        since=since,
+        until=until,
+        base=base,
+        head=head,
+        commits=commits,
+        api=diff_manifest(manifest_lines(before), manifest_lines(after)),
+        documented=sorted(documented, key=lambda s: s.qualified_name),
+        coverage=moved,
+        undocumented=sorted(additions, key=_rank)[: max(top, 0)],
+        coverage_before=_percent(old.values()),
+        coverage_after=_percent(new.values()),
+    )
+
+
+def symbols_at(root: str | Path, revision: str) -> list[DocSymbol]:
+    """The symbols of the Python files below ``root`` as of ``revision``.
+
+    Files are read from git, so uncommitted changes do not count. Excluded
+    directories and paths ignored by the working tree's ``.autodocignore``
+    are skipped.
+
+    Raises:
+        GitError: If ``root`` is not in a git repository or ``revision`` is
+            unknown
+    """
+    root_path = Path(root)
+    repo = repo_root(root_path)
+    prefix = root_path.resolve().relative_to(repo.resolve()).as_posix()
+    prefix = "" if prefix == "." else prefix
+    ignore = load_ignore_file(root_path)
+    loader = DocSymbolLoader(root_path)
+    symbols = []
+    for path in tracked_files(repo, revision, prefix):
+        relative = PurePosixPath(path)
+        if prefix:
+            relative = relative.relative_to(prefix)
+        if any(part in DEFAULT_EXCLUDED_DIRS for part in relative.parts[:-1]):
+            continue
+        if ignore.ignores(relative):
+            continue
+        source = show_file(repo, revision, path)
+        if source is not None:
+            symbols.extend(loader.load_source(source, relative.as_posix()))
+    return symbols
+
+
+def collect_digest(
+    root: str | Path,
+    period: timedelta,
+    now: datetime | None = None,
+    top: int = DEFAULT_TOP,
+) -> Digest:
+    """The digest of the ``period`` up to ``now`` of the tree below ``root``.
+
+    Raises:
+        GitError: If the history cannot be read
+    """
+    until = now or datetime.now(UTC)
+    since = until - period
+    repo = repo_root(root)
+    head = head_commit(repo)
+    base = commit_before(repo, since.isoformat(), head)
+    logger.info("Digest of %s since %s (base %s)", root, since, base or "none")
+    before = symbols_at(root, base) if base else []
+    after = symbols_at(root, head)
+    return build_digest(
+        before,
+        after,
+        since,
+        until,
+        base,
+        head,
+        commit_count(repo, base, head),
+        top,
+    )
+
+
+def _short(commit: str | None) -> str:
+    return commit[:7] if commit else "the first commit"
+
+
+def _points(delta: float) -> str:
+    return f"{delta:+.1f} points"
+
+
+def _pct(value: float | None) -> str:
+    return "-" if value is None else f"{value:.1f}%"
+
+
+def _title(digest: Digest) -> str:
+    return (
+        f"Documentation digest: {digest.since:%Y-%m-%d} to {digest.until:%Y-%m-%d}"
+    )
+
+
+def _overview(digest: Digest) -> str:
+    delta = round(digest.coverage_after - digest.coverage_before, 2)
+    return (
+        f"{digest.commits} commit(s) since {_short(digest.base)}, up to "
+        f"{_short(digest.head)}. Documentation coverage "
+        f"{_pct(digest.coverage_before)} -> {_pct(digest.coverage_after)} "
+        f"({_points(delta)})."
+    )
+
+
+def render_markdown(digest: Digest) -> str:
+    """The digest as Markdown."""
+    lines = [f"# {_title(digest)}", "", _overview(digest), ""]
+
+    lines += [f"## API changes ({digest.change})", ""]
+    if digest.api:
+        lines += [f"- Added: `{line}`" for line in digest.api.added]
+        lines += [f"- Removed: `{line}`" for line in digest.api.removed]
+    else:
+        lines.append("No changes to the public API.")
+    lines.append("")
+
+    lines += ["## New documentation", ""]
+    if digest.documented:
+        for symbol in digest.documented:
+            text = summary(symbol.docstring)
+            lines.append(f"- `{symbol.qualified_name}` ({symbol.kind}): {text}")
+    else:
+        lines.append("No symbols were documented.")
+    lines.append("")
+
+    lines += ["## Coverage by package", ""]
+    if digest.coverage:
+        lines += ["| Package | Before | After | Change |", "|---|---|---|---|"]
+        lines += [
+            f"| {c.package} | {_pct(c.before_percent)} | "
+            f"{_pct(c.after_percent)} | {_points(c.delta)} |"
+            for c in digest.coverage
+        ]
+    else:
+        lines.append("No package's coverage changed.")
+    lines.append("")
+
+    lines += ["## Top undocumented additions", ""]
+    if digest.undocumented:
+        lines += [
+            f"- `{s.qualified_name}` ({s.kind}), {s.file_path}:{s.lineno}"
+            for s in digest.undocumented
+        ]
+    else:
+        lines.append("Every new symbol is documented.")
+    return "\n".join(lines) + "\n"
+
+
+# Inline styles: mail clients drop <style> blocks and external stylesheets.
+_BODY = (
+    "font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; "
+    "color: #1f2328; max-width: 720px; margin: 0 auto; padding: 16px;"
+)
+_H2 = "font-size: 18px; border-bottom: 1px solid #d0d7de; padding-bottom: 4px;"
+_CODE = "font-family: SFMono-Regular, Consolas, monospace; font-size: 13px;"
+_CELL = "border: 1px solid #d0d7de; padding: 4px 8px; text-align: left;"
+
+
+def _code(text: str) -> str:
+    return f'<code style="{_CODE}">{escape(text)}</code>'
+
+
+def _list(items: list[str], empty: str) -> list[str]:
+    if not items:
+        return [f"<p>{escape(empty)}</p>"]
+    return ["<ul>", *(f"<li>{item}</li>" for item in items), "</ul>"]
+
+
+def render_html(digest: Digest) -> str:
+    """The digest as a standalone HTML page, styled inline for email."""
+    title = escape(_title(digest))
+    parts = [
+        "<!DOCTYPE html>",
+        '<html lang="en">',
+        '<head><meta charset="utf-8">',
+        f"<title>{title}</title></head>",
+        f'<body style="{_BODY}">',
+        f'<h1 style="font-size: 22px;">{title}</h1>',
+        f"<p>{escape(_overview(digest))}</p>",
+        f'<h2 style="{_H2}">API changes ({escape(digest.change)})</h2>',
+    ]
+    api = [f"Added: {_code(line)}" for line in digest.api.added]
+    api += [f"Removed: {_code(line)}" for line in digest.api.removed]
+    parts += _list(api, "No changes to the public API.")
+
+    parts.append(f'<h2 style="{_H2}">New documentation</h2>')
+    parts += _list(
+        [
+            f"{_code(s.qualified_name)} ({escape(s.kind)}): "
+            f"{escape(summary(s.docstring))}"
+            for s in digest.documented
+        ],
+        "No symbols were documented.",
+    )
+
+    parts.append(f'<h2 style="{_H2}">Coverage by package</h2>')
+    if digest.coverage:
+        header = "".join(
+            f'<th style="{_CELL}">{name}</th>'
+            for name in ("Package", "Before", "After", "Change")
+        )
+        parts += [
+            '<table style="border-collapse: collapse;">',
+            f"<tr>{header}</tr>",
+        ]
+        for change in digest.coverage:
+            cells = (
+                escape(change.package),
+                _pct(change.before_percent),
+                _pct(change.after_percent),
+                _points(change.delta),
+            )
+            row = "".join(f'<td style="{_CELL}">{cell}</td>' for cell in cells)
+            parts.append(f"<tr>{row}</tr>")
+        parts.append("</table>")
+    else:
+        parts += _list([], "No package's coverage changed.")
+
+    parts.append(f'<h2 style="{_H2}">Top undocumented additions</h2>')
+    parts += _list(
+        [
+            f"{_code(s.qualified_name)} ({escape(s.kind)}), "
+            f"{escape(s.file_path)}:{s.lineno}"
+            for s in digest.undocumented
+        ],
+        "Every new symbol is documented.",
+    )
+    parts += ["</body>", "</html>"]
+    return "\n".join(parts) + "\n"
+
+
+__all__ = [
+    "DEFAULT_PERIOD",
+    "DEFAULT_TOP",
+    "CoverageChange",
+    "Digest",
+    "DigestError",
+    "build_digest",
+    "collect_digest",
+    "parse_period",
+    "render_html",
+    "render_markdown",
+    "symbols_at",
+]
@@ -0,0 +1,75 @@
+"""Per-symbol "edit this doc comment" links to the code host.
+
+Every rendered symbol can link to the exact file and line that defines it on
+GitHub, GitLab, Bitbucket, or any host with a configurable URL template (see
+:class:`~autodoc.config.project.EditLinkConfig`). Host URLs need
+repository-relative paths, so symbol paths are re-anchored at the repository
+root rather than the directory AutoDoc was pointed at.
+"""
+
+from __future__ import annotations
+
+import logging
+import os
+from collections.abc import Callable
+from pathlib import Path, PurePosixPath
+from urllib.parse import quote
+
+from autodoc.config.project import EditLinkConfig
+from services.doc_symbols import DocSymbol
+from services.git_source import GitError, repo_root
+
+logger = logging.getLogger(__name__)
+
+# Signature shared by the renderers: symbol -> URL, or None for no link.
+EditLinkFn = Callable[[DocSymbol], str | None]
+
+
+class EditLinkBuilder:
+    """Build edit URLs for symbols from an :class:`EditLinkConfig`."""
+
+    def __init__(self, config: EditLinkConfig, repo_dir: str | Path) -> None:
+        self.config = config
+        self.repo_dir = Path(repo_dir).resolve()
+
+    def repo_path(self, file_path: str) -> str | None:
+        """Return ``file_path`` relative to the repository, or ``None`` if outside it."""
+        relative = os.path.relpath(Path(file_path).resolve(), self.repo_dir)
+        posix = PurePosixPath(Path(relative).as_posix())
+        if posix.parts and posix.parts[0] == "..":
+            return None
+        return posix.as_posix()
+
+    def __call__(self, symbol: DocSymbol) -> str | None:
+        path = self.repo_path(symbol.file_path)
+        if path is None:
+            logger.debug("No edit link for %s: outside repository", symbol.file_path)
+            return None
+        return self.config.url_template.format(
+            repo_url=self.config.repo_url,
+            branch=quote(self.config.branch, safe="/"),
+            path=quote(path, safe="/"),
+            line=symbol.lineno,
+        )
+
+
+def build_edit_links(
+    config: EditLinkConfig | None,
+    root: str | Path,
+) -> EditLinkFn | None:
+    """Return an edit-link function for ``root``, or ``None`` if not configured.
+
+    The repository root is discovered with git; outside a repository ``root``
+    itself is treated as the repository root.
+    """
+    if config is None:
+        return None
+    try:
+        repo_dir = repo_root(root)
+    except GitError:
+        logger.info("%s is not in a git repository; edit links relative to it", root)
+        repo_dir = Path(root)
+    return EditLinkBuilder(config, repo_dir)
+
+
+__all__ = ["EditLinkBuilder", "EditLinkFn", "build_edit_links"]
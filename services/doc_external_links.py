@@ -0,0 +1,259 @@
+"""Links from signatures to the documentation of external types.
+
+A signature such as ``def load(path: Path) -> httpx.Response`` names types the
+site does not document. With ``site.external_links`` enabled (see
+:class:`~autodoc.config.project.ExternalLinksConfig`), each such name is
+resolved through the imports of the symbol's module and linked:
+
+- standard library names to docs.python.org, for the Python version the
+  project supports;
+- third-party names to the docs of their distribution at the version pinned
+  by the project, by default its PyPI release page.
+
+Pins are read from ``requirements*.txt`` and the ``pyproject.toml``
+dependencies next to the config file, never from the environment AutoDoc runs
+in, so the links do not change with whatever happens to be installed.
+Unpinned distributions link to their unversioned page. Import names are
+mapped to distributions by ``distributions``, else to the declared
+requirement of the same name, ignoring a ``py``/``python-`` prefix or a
+``-python`` suffix (``yaml`` -> ``PyYAML``), else taken as they are.
+
+Names that are not imported (string annotations of undeclared names,
+builtins) and names defined in the tree are not linked.
+
+A portal of several repositories (see :mod:`services.doc_portal`) passes the
+names the other repositories document as ``documented``: those link to their
+pages in the portal instead, whether or not ``site.external_links`` is on.
+"""
+
+from __future__ import annotations
+
+import builtins
+import logging
+import re
+import sys
+import tomllib
+from collections.abc import Callable, Mapping, Sequence
+from pathlib import Path
+
+from autodoc.config.project import ExternalLinksConfig
+from services.doc_symbols import DocSymbol
+from services.import_graph import ImportGraph
+
+logger = logging.getLogger(__name__)
+
+STDLIB_TEMPLATE = "https://docs.python.org/{python}/library/{module}.html#{name}"
+PYPI_TEMPLATE = "https://pypi.org/project/{distribution}/{version}/"
+# Used instead of the default template for distributions that are not pinned.
+PYPI_UNPINNED_TEMPLATE = "https://pypi.org/project/{distribution}/"
+# ``{version}`` of a configured template when the distribution is not pinned.
+UNPINNED_VERSION = "latest"
+
+# Signature shared by the renderers: symbol -> {external name: URL}.
+ExternalLinkFn = Callable[[DocSymbol], dict[str, str]]
+
+_NAME = re.compile(r"(?<![\w.])[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*")
+_PIN = re.compile(
+    r"^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*==\s*([^\s;,#]+)",
+)
+_REQUIREMENT = re.compile(r"^\s*([A-Za-z0-9][A-Za-z0-9._-]*)")
+_AFFIXES = re.compile(r"^(?:python-|py-?)|-python$")
+_PYTHON = re.compile(r"(?:>=|~=|==)\s*(3\.\d+)")
+_BUILTINS = frozenset(dir(builtins))
+
+
+def normalize(distribution: str) -> str:
+    """``distribution`` as PEP 503 compares names (``PyYAML`` -> ``pyyaml``)."""
+    return re.sub(r"[-_.]+", "-", distribution).lower()
+
+
+def pyproject_data(base_dir: str | Path) -> dict:
+    """The parsed ``pyproject.toml`` of ``base_dir``; empty if missing or invalid."""
+    path = Path(base_dir) / "pyproject.toml"
+    if not path.is_file():
+        return {}
+    try:
+        return tomllib.loads(path.read_text(encoding="utf-8"))
+    except (OSError, tomllib.TOMLDecodeError) as exc:
+        logger.warning("Ignoring unreadable %s: %s", path, exc)
+        return {}
+
+
+def requirement_lines(base_dir: str | Path) -> list[str]:
+    """The lines of ``requirements*.txt`` and the ``pyproject.toml`` dependencies.
+
+    Requirements files come first, in name order, then the project's
+    dependencies and its optional dependencies.
+    """
+    base = Path(base_dir)
+    lines: list[str] = []
+    for path in sorted(base.glob("requirements*.txt")):
+        try:
+            lines.extend(path.read_text(encoding="utf-8").splitlines())
+        except OSError as exc:
+            logger.warning("Ignoring unreadable %s: %s", path, exc)
+    project = pyproject_data(base).get("project", {})
+    lines.extend(project.get("dependencies", []))
+    for extra in project.get("optional-dependencies", {}).values():
+        lines.extend(extra)
+    return lines
+
+
+def project_pins(base_dir: str | Path) -> dict[str, str]:
+    """Pinned versions (``name==version``) by normalized distribution name."""
+    pins = {}
+    for line in requirement_lines(base_dir):
+        match = _PIN.match(line)
+        if match:
+            pins.setdefault(normalize(match.group(1)), match.group(2))
+    return pins
+
+
+def project_distributions(base_dir: str | Path) -> list[str]:
+    """The distributions the project requires, in declaration order."""
+    names = []
+    for line in requirement_lines(base_dir):
+        match = _REQUIREMENT.match(line)
+        if match:
+            names.append(match.group(1))
+    return list(dict.fromkeys(names))
+
+
+def python_version(base_dir: str | Path, configured: str | None = None) -> str:
+    """The Python docs version: ``configured``, else from ``requires-python``."""
+    if configured:
+        return configured
+    project = pyproject_data(base_dir).get("project", {})
+    match = _PYTHON.search(project.get("requires-python", ""))
+    return match.group(1) if match else "3"
+
+
+def signature_names(symbol: DocSymbol) -> list[str]:
+    """The dotted names in the annotations and bases of ``symbol``."""
+    metadata = symbol.metadata or {}
+    texts = [param.get("annotation") or "" for param in metadata.get("parameters", [])]
+    texts.append(metadata.get("return_type") or "")
+    texts.extend(metadata.get("base_classes") or [])
+    names = (match.group(0) for text in texts for match in _NAME.finditer(text))
+    return list(dict.fromkeys(names))
+
+
+class ExternalLinkResolver:
+    """Resolve the external names in signatures to documentation URLs."""
+
+    def __init__(
+        self,
+        config: ExternalLinksConfig,
+        graph: ImportGraph,
+        pins: dict[str, str] | None = None,
+        python: str = "3",
+        documented: Mapping[str, str] | None = None,
+        declared: Sequence[str] = (),
+    ) -> None:
+        self.config = config
+        self.graph = graph
+        self.pins = pins or {}
+        self.python = python
+        self.documented = documented or {}
+        self.roots = {module.split(".", 1)[0] for module in graph.modules}
+        # Import-like name -> declared distribution (``yaml`` -> ``PyYAML``).
+        self._declared: dict[str, str] = {}
+        for name in declared:
+            normalized = normalize(name)
+            self._declared.setdefault(normalized, name)
+            self._declared.setdefault(_AFFIXES.sub("", normalized), name)
+
+    def qualified(self, name: str, symbol: DocSymbol) -> str | None:
+        """``name`` as its module imports it, or None when it is not imported."""
+        module = self.graph.internal_module(symbol.qualified_name)
+        info = self.graph.modules.get(module) if module else None
+        head, _, rest = name.partition(".")
+        if info is None or head not in info.aliases:
+            return None
+        target = info.aliases[head]
+        return f"{target}.{rest}" if rest else target
+
+    def distribution(self, top: str) -> str:
+        """The distribution providing the top-level module ``top``."""
+        if top in self.config.distributions:
+            return self.config.distributions[top]
+        return self._declared.get(normalize(top), top)
+
+    def url(self, name: str) -> str | None:
+        """The documentation URL of the fully qualified ``name``."""
+        top = name.split(".", 1)[0]
+        if top in self.roots or "." not in name:
+            return None
+        if name in self.documented:
+            return self.documented[name]
+        if not self.config.enabled:
+            return None
+        module = name.rsplit(".", 1)[0]
+        fields = {"module": module, "name": name, "python": self.python}
+        if top in sys.stdlib_module_names:
+            template = self.config.stdlib or STDLIB_TEMPLATE
+            return template.format(distribution="", version=self.python, **fields)
+        distribution = self.distribution(top)
+        version = self.pins.get(normalize(distribution))
+        template = self.config.packages.get(top) or self.config.template
+        if template is None:
+            template = PYPI_TEMPLATE if version else PYPI_UNPINNED_TEMPLATE
+        return template.format(
+            distribution=distribution,
+            version=version or UNPINNED_VERSION,
+            **fields,
+        )
+
+    def __call__(self, symbol: DocSymbol) -> dict[str, str]:
+        links = {}
+        for name in signature_names(symbol):
+            if name.split(".", 1)[0] in _BUILTINS:
+                continue
+            qualified = self.qualified(name, symbol)
+            url = self.url(qualified) if qualified else None
+            if url:
+                links[qualified] = url
+        return links
+
+
+def build_external_links(
+    config: ExternalLinksConfig,
+    base_dir: str | Path,
+    graph: ImportGraph | None,
+    documented: Mapping[str, str] | None = None,
+) -> ExternalLinkFn | None:
+    """Return the external-link function, or ``None`` if not configured.
+
+    ``base_dir`` holds the ``pyproject.toml`` and requirements files;
+    ``documented`` maps names documented elsewhere in a portal to their URLs.
+    """
+    if not (config.enabled or documented) or graph is None:
+        return None
+    pins = project_pins(base_dir)
+    logger.debug("External links use %d pinned distribution(s)", len(pins))
+    return ExternalLinkResolver(
+        config,
+        graph,
+        pins,
+        python_version(base_dir, config.python),
+        documented,
+        project_distributions(base_dir),
+    )
+
+
+__all__ = [
+    "PYPI_TEMPLATE",
+    "PYPI_UNPINNED_TEMPLATE",
+    "STDLIB_TEMPLATE",
+    "UNPINNED_VERSION",
+    "ExternalLinkFn",
+    "ExternalLinkResolver",
+    "build_external_links",
+    "normalize",
+    "project_distributions",
+    "project_pins",
+    "pyproject_data",
+    "python_version",
+    "requirement_lines",
+    "signature_names",
+]
@@ -0,0 +1,538 @@
+"""Mechanical docstring repairs for ``autodoc fix``.
+
+Each fix rewrites the literal of a docstring in place, touching nothing else
+in the file:
+
+- ``summary-capital``: the summary starts with a capital letter. Python
+  docstrings do not repeat the symbol's name (PEP 257), so a summary starting
+  with an identifier of the module, such as a parameter name, is left alone;
+- ``summary-period``: the summary paragraph ends with a period;
+- ``blank-after-summary``: a one-line summary is followed by a blank line
+  before the description;
+- ``blank-before-section``: a blank line precedes Google and NumPy sections
+  (``Args:``, ``Returns`` above a dashed line), reST directives
+  (``.. note::``), and field lists (``:param x:``);
+- ``tag-sections``: Javadoc and Epytext tags (``@param``, ``@return``,
+  ``@throws``) that end a docstring are rewritten as Google ``Args:``,
+  ``Returns:``, and ``Raises:`` sections. It changes the docstring's
+  convention rather than repairing it, so it only runs when selected.
+
+Rewrites are AST-aware. Docstrings are found through :mod:`ast`, only plain
+and raw single-literal strings are rewritten (never bytes, f-strings, or
+implicit concatenations), and lines inside ``::`` blocks and doctests are
+skipped. Before a file is returned, it is parsed again and compared with the
+original with docstrings masked out, so a fix can never change code.
+"""
+
+from __future__ import annotations
+
+import ast
+import difflib
+import io
+import re
+import textwrap
+import tokenize
+from collections.abc import Iterator, Sequence
+from dataclasses import dataclass, field
+from typing import Any
+
+from services.docstring_sections import is_tag, parse_docstring
+
+SUMMARY_CAPITAL = "summary-capital"
+SUMMARY_PERIOD = "summary-period"
+BLANK_AFTER_SUMMARY = "blank-after-summary"
+BLANK_BEFORE_SECTION = "blank-before-section"
+TAG_SECTIONS = "tag-sections"
+FIX_RULES = (
+    SUMMARY_CAPITAL,
+    SUMMARY_PERIOD,
+    BLANK_AFTER_SUMMARY,
+    BLANK_BEFORE_SECTION,
+    TAG_SECTIONS,
+)
+# The rules applied when none are selected.
+DEFAULT_FIX_RULES = FIX_RULES[:4]
+# Google section heading for each parsed section, in the order they are written.
+_GOOGLE_HEADINGS = (
+    ("parameters", "Args"),
+    ("returns", "Returns"),
+    ("yields", "Yields"),
+    ("raises", "Raises"),
+)
+_WIDTH = 88
+
+_SECTION = re.compile(
+    r"^(?:Args|Arguments|Attributes|Examples?|Keyword Args|Keyword Arguments|"
+    r"Methods|Notes?|Other Parameters|Parameters|Raises|References|Returns?|"
+    r"See Also|Todo|Warnings?|Yields?):\s*$",
+)
+_UNDERLINE = re.compile(r"^-{3,}\s*$")
+_DIRECTIVE = re.compile(r"^\.\. [\w:-]+::")
+_FIELD = re.compile(
+    r"^:(?:param|parameter|arg|argument|key|keyword|type|returns?|rtype|"
+    r"raises?|except|yields?|ytype|ivar|var|cvar|vartype|meta)\b[^:]*:",
+)
+_FIRST_WORD = re.compile(r"^([a-z]+)(?=\s|$)")
+_ABBREVIATION = re.compile(r"\b(?:e\.g|i\.e|etc|vs|cf)\.$")
+_ENDS_OPEN = re.compile(r"[\w)\]`'\"*]$")
+_PREFIX = re.compile(r"^([rRuU]?)('''|\"\"\"|'|\")")
+
+
+class DocFixError(Exception):
+    """Raised when a file cannot be fixed safely."""
+
+
+@dataclass(frozen=True)
+class DocFix:
+    """One repair made to a docstring."""
+
+    rule: str
+    message: str
+    file_path: str
+    lineno: int
+    symbol: str
+
+    def format(self) -> str:
+        return f"{self.file_path}:{self.lineno}: [{self.rule}] {self.message}"
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "rule": self.rule,
+            "message": self.message,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "symbol": self.symbol,
+        }
+
+
+@dataclass
+class FixResult:
+    """The fixed source of one file and the repairs that produced it."""
+
+    path: str
+    original: str
+    fixed: str
+    fixes: list[DocFix] = field(default_factory=list)
+
+    @property
+    def changed(self) -> bool:
+        return self.fixed != self.original
+
+    def diff(self) -> str:
+        """A unified diff from the original to the fixed source."""
+        return "".join(
+            difflib.unified_diff(
+                self.original.splitlines(keepends=True),
+                self.fixed.splitlines(keepends=True),
+                f"a/{self.path}",
+                f"b/{self.path}",
+            ),
+        )
+
+
+@dataclass
+class _Docstring:
+    node: ast.Constant
+    symbol: str
+
+
+def _docstrings(tree: ast.Module) -> Iterator[_Docstring]:
+    def visit(node: ast.AST, prefix: str) -> Iterator[_Docstring]:
+        body = getattr(node, "body", [])
+        if (
+            body
+            and isinstance(body[0], ast.Expr)
+            and isinstance(body[0].value, ast.Constant)
+            and isinstance(body[0].value.value, str)
+        ):
+            yield _Docstring(body[0].value, prefix or "<module>")
+        for child in body:
+            if isinstance(child, ast.ClassDef | ast.FunctionDef | ast.AsyncFunctionDef):
+                name = f"{prefix}.{child.name}" if prefix else child.name
+                yield from visit(child, name)
+
+    yield from visit(tree, "")
+
+
+def _identifiers(tree: ast.Module) -> set[str]:
+    """Every name the module defines, binds, or reads."""
+    names = set()
+    for node in ast.walk(tree):
+        if isinstance(node, ast.Name):
+            names.add(node.id)
+        elif isinstance(node, ast.arg):
+            names.add(node.arg)
+        elif isinstance(node, ast.Attribute):
+            names.add(node.attr)
+        elif isinstance(node, ast.ClassDef | ast.FunctionDef | ast.AsyncFunctionDef):
+            names.add(node.name)
+        elif isinstance(node, ast.alias):
+            names.add((node.asname or node.name).split(".")[0])
+    return names
+
+
+def _offset(lines: Sequence[str], lineno: int, col: int) -> int:
+    """Character offset in the source of an AST (line, UTF-8 byte) position."""
+    before = sum(len(line) for line in lines[: lineno - 1])
+    return before + len(lines[lineno - 1].encode("utf-8")[:col].decode("utf-8"))
+
+
+def _single_literal(segment: str) -> bool:
+    try:
+        tokens = list(tokenize.generate_tokens(io.StringIO(segment).readline))
+    except (tokenize.TokenError, SyntaxError):
+        return False
+    ignored = (tokenize.NEWLINE, tokenize.NL, tokenize.ENDMARKER)
+    kinds = [token.type for token in tokens if token.type not in ignored]
+    return kinds == [tokenize.STRING]
+
+
+def _indent(line: str) -> int:
+    return len(line) - len(line.lstrip())
+
+
+def _blocked(lines: list[str]) -> set[int]:
+    """Indices of lines inside ``::`` blocks and doctests."""
+    blocked = set()
+    block_indent: int | None = None
+    for index, line in enumerate(lines):
+        stripped = line.strip()
+        if block_indent is not None:
+            if not stripped or _indent(line) > block_indent:
+                blocked.add(index)
+                continue
+            block_indent = None
+        if stripped.startswith((">>>", "...")):
+            blocked.add(index)
+        elif stripped.endswith("::") and not _DIRECTIVE.match(stripped):
+            block_indent = _indent(line)
+    return blocked
+
+
+def _section_start(lines: list[str], index: int, base: int) -> str | None:
+    """What kind of section starts at ``lines[index]``, if one does."""
+    line = lines[index]
+    if _indent(line) != base:
+        return None
+    stripped = line.strip()
+    if _SECTION.match(stripped):
+        return "section"
+    nxt = lines[index + 1] if index + 1 < len(lines) else ""
+    if stripped and _UNDERLINE.match(nxt.strip()) and _indent(nxt) == base:
+        return "section"
+    if _DIRECTIVE.match(stripped):
+        return "directive"
+    if _FIELD.match(stripped):
+        return "field"
+    return None
+
+
+class _Fixer:
+    def __init__(self, rules: Sequence[str], identifiers: set[str]) -> None:
+        self.rules = set(rules)
+        self.identifiers = identifiers
+        self.applied: list[tuple[str, str]] = []
+
+    def fix(self, inner: str) -> str:
+        lines = inner.split("\n")
+        start = next((i for i, line in enumerate(lines) if line.strip()), None)
+        if start is None:
+            return inner
+        # The indentation inspect.cleandoc removes: that of the lines after
+        # the first.
+        body = [line for line in lines[1:] if line.strip()]
+        base = min((_indent(line) for line in body), default=0)
+        if SUMMARY_CAPITAL in self.rules:
+            self._capital(lines, start)
+        if TAG_SECTIONS in self.rules:
+            lines = self._tag_sections(lines, start, base)
+        if BLANK_BEFORE_SECTION in self.rules:
+            lines = self._blank_before_sections(lines, start, base)
+        end = self._summary_end(lines, start, base)
+        if SUMMARY_PERIOD in self.rules:
+            self._period(lines, start, end)
+        if BLANK_AFTER_SUMMARY in self.rules and end > start:
+            lines = self._blank_after_summary(lines, start)
+        return "\n".join(lines)
+
+    def _blank_before_sections(
+        self,
+        lines: list[str],
+        start: int,
+        base: int,
+    ) -> list[str]:
+        blocked = _blocked(lines)
+        fixed = list(lines[: start + 1])
+        for index in range(start + 1, len(lines)):
+            line = lines[index]
+            kind = None if index in blocked else _section_start(lines, index, base)
+            previous = lines[index - 1]
+            if kind is not None and previous.strip():
+                # A field list is one block: no blank between its fields.
+                in_fields = _indent(previous) > base or _FIELD.match(previous.strip())
+                if kind != "field" or not in_fields:
+                    fixed.append("")
+                    name = line.strip()
+                    if kind == "section":
+                        name = name.rstrip(":")
+                    self.applied.append(
+                        (BLANK_BEFORE_SECTION, f"added a blank line before {name!r}"),
+                    )
+            fixed.append(line)
+        return fixed
+
+    def _tag_sections(self, lines: list[str], start: int, base: int) -> list[str]:
+        blocked = _blocked(lines)
+        first = next(
+            (
+                index
+                for index in range(start + 1, len(lines))
+                if index not in blocked
+                and _indent(lines[index]) == base
+                and is_tag(lines[index])
+            ),
+            None,
+        )
+        if first is None:
+            return lines
+        end = len(lines)
+        while end > first and not lines[end - 1].strip():
+            end -= 1
+        block = lines[first:end]
+        # Only tags that end the docstring, with nothing else between them.
+        if any(index in blocked for index in range(first, end)) or any(
+            not line.strip() and not is_tag(block[number + 1])
+            for number, line in enumerate(block)
+        ):
+            return lines
+        parsed = parse_docstring("\n".join(line[base:] for line in block))
+        if parsed.description:
+            return lines
+        pad = " " * base
+        sections: list[str] = []
+        for kind, heading in _GOOGLE_HEADINGS:
+            fields = parsed.section(kind)
+            if not fields:
+                continue
+            if sections:
+                sections.append("")
+            sections.append(f"{pad}{heading}:")
+            for doc_field in fields:
+                if kind == "parameters" and doc_field.type:
+                    entry = f"{doc_field.name} ({doc_field.type})"
+                elif kind in ("parameters", "raises"):
+                    entry = doc_field.name
+                else:
+                    entry = doc_field.type
+                text = doc_field.description
+                if entry:
+                    text = f"{entry}: {text}" if text else f"{entry}:"
+                sections.extend(
+                    textwrap.wrap(
+                        text,
+                        _WIDTH,
+                        initial_indent=f"{pad}    ",
+                        subsequent_indent=f"{pad}        ",
+                        break_on_hyphens=False,
+                    ),
+                )
+        if lines[first - 1].strip():
+            sections.insert(0, "")
+        self.applied.append(
+            (TAG_SECTIONS, "rewrote @param/@return/@throws tags as Google sections"),
+        )
+        return [*lines[:first], *sections, *lines[end:]]
+
+    def _summary_end(self, lines: list[str], start: int, base: int) -> int:
+        """Index of the last line of the summary paragraph."""
+        end = start
+        while end + 1 < len(lines):
+            nxt = lines[end + 1]
+            if not nxt.strip() or _section_start(lines, end + 1, base):
+                break
+            end += 1
+        return end
+
+    def _period(self, lines: list[str], start: int, end: int) -> None:
+        first = lines[start].strip()
+        last = lines[end].rstrip()
+        if (
+            first.startswith((">>>", "..", ":"))
+            or _SECTION.match(first)
+            or last.endswith("::")
+            or "://" in last.split()[-1]
+            or not _ENDS_OPEN.search(last)
+        ):
+            return
+        lines[end] = last + "." + lines[end][len(last) :]
+        self.applied.append((SUMMARY_PERIOD, "ended the summary with a period"))
+
+    def _blank_after_summary(self, lines: list[str], start: int) -> list[str]:
+        summary = lines[start].rstrip()
+        following = lines[start + 1].strip()
+        if (
+            not summary.endswith(".")
+            or _ABBREVIATION.search(summary)
+            or (following[:1].isalpha() and not following[:1].isupper())
+        ):
+            return lines
+        self.applied.append(
+            (BLANK_AFTER_SUMMARY, "separated the summary from the description"),
+        )
+        return [*lines[: start + 1], "", *lines[start + 1 :]]
+
+    def _capital(self, lines: list[str], start: int) -> None:
+        line = lines[start]
+        text = line.lstrip()
+        match = _FIRST_WORD.match(text)
+        if match is None or match.group(1) in self.identifiers:
+            return
+        lead = line[: len(line) - len(text)]
+        lines[start] = lead + text[0].upper() + text[1:]
+        self.applied.append((SUMMARY_CAPITAL, "capitalized the summary"))
+
+
+def _masked(tree: ast.Module) -> str:
+    for docstring in _docstrings(tree):
+        docstring.node.value = ""
+    return ast.dump(tree)
+
+
+@dataclass(frozen=True)
+class DocstringLiteral:
+    """Where a docstring literal sits in its file, split at its quotes."""
+
+    symbol: str
+    lineno: int
+    begin: int
+    end: int
+    opening: str
+    inner: str
+    quote: str
+
+
+def _parse(source: str, path: str) -> ast.Module:
+    try:
+        return ast.parse(source)
+    except SyntaxError as exc:
+        message = f"{path}: cannot parse: {exc.msg} (line {exc.lineno})"
+        raise DocFixError(message) from exc
+
+
+def docstring_literals(
+    source: str,
+    path: str = "<string>",
+) -> list[DocstringLiteral]:
+    """The docstrings of ``source`` that can be rewritten safely.
+
+    Symbols are qualified within the file (``Cart.total``), ``<module>`` for
+    the module docstring.
+
+    Raises:
+        DocFixError: If ``source`` does not parse
+    """
+    tree = _parse(source, path)
+    # Lines as the parser counts them (str.splitlines also splits at \f).
+    lines = io.StringIO(source, newline="").readlines()
+    literals = []
+    for docstring in _docstrings(tree):
+        node = docstring.node
+        begin = _offset(lines, node.lineno, node.col_offset)
+        end_lineno, end_col = node.end_lineno or node.lineno, node.end_col_offset or 0
+        finish = _offset(lines, end_lineno, end_col)
+        segment = source[begin:finish]
+        match = _PREFIX.match(segment)
+        if match is None or not _single_literal(segment):
+            continue
+        opening = match.group(0)
+        quote = match.group(2)
+        inner = segment[len(opening) : len(segment) - len(quote)]
+        if "\\\n" in inner and match.group(1).lower() != "r":
+            # A line continuation inside the literal.
+            continue
+        literals.append(
+            DocstringLiteral(
+                docstring.symbol,
+                node.lineno,
+                begin,
+                finish,
+                opening,
+                inner,
+                quote,
+            ),
+        )
+    return literals
+
+
+def rewrite_literals(
+    source: str,
+    edits: Sequence[tuple[DocstringLiteral, str]],
+    path: str = "<string>",
+) -> str:
+    """``source`` with the inner text of each literal replaced.
+
+    Raises:
+        DocFixError: If the rewritten source would differ in more than its
+            docstrings
+    """
+    rewritten = source
+    ordered = sorted(edits, key=lambda edit: edit[0].begin, reverse=True)
+    for literal, inner in ordered:
+        text = literal.opening + inner + literal.quote
+        rewritten = rewritten[: literal.begin] + text + rewritten[literal.end :]
+    if edits:
+        try:
+            changed = _masked(ast.parse(rewritten)) != _masked(ast.parse(source))
+        except SyntaxError:
+            changed = True
+        if changed:
+            raise DocFixError(f"{path}: the rewrite would change code; left unchanged")
+    return rewritten
+
+
+def fix_source(
+    source: str,
+    path: str = "<string>",
+    rules: Sequence[str] = DEFAULT_FIX_RULES,
+) -> FixResult:
+    """Fix the docstrings of ``source`` with ``rules``.
+
+    Raises:
+        DocFixError: If ``source`` does not parse, or the fixes would change
+            more than its docstrings
+    """
+    identifiers = _identifiers(_parse(source, path))
+    edits = []
+    fixes = []
+    for literal in docstring_literals(source, path):
+        inner = literal.inner
+        fixer = _Fixer(rules, identifiers)
+        newline = "\r\n" if "\r\n" in inner else "\n"
+        fixed = fixer.fix(inner.replace(newline, "\n")).replace("\n", newline)
+        if fixed == inner:
+            continue
+        edits.append((literal, fixed))
+        fixes.extend(
+            DocFix(rule, message, path, literal.lineno, literal.symbol)
+            for rule, message in fixer.applied
+        )
+    fixed_source = rewrite_literals(source, edits, path)
+    return FixResult(path, source, fixed_source, fixes)
+
+
+__all__ = [
+    "BLANK_AFTER_SUMMARY",
+    "BLANK_BEFORE_SECTION",
+    "DEFAULT_FIX_RULES",
+    "FIX_RULES",
+    "SUMMARY_CAPITAL",
+    "SUMMARY_PERIOD",
+    "TAG_SECTIONS",
+    "DocFix",
+    "DocFixError",
+    "DocstringLiteral",
+    "FixResult",
+    "docstring_literals",
+    "fix_source",
+    "rewrite_literals",
+]
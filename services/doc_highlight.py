@@ -0,0 +1,99 @@
+"""Server-side syntax highlighting for HTML documentation.
+
+Signatures and docstring examples are highlighted with Pygments when the site
+is generated, so pages need no client-side JavaScript and highlight the same
+way in every browser. Token colours come from Pygments styles chosen in the
+``site.highlight`` section of ``autodoc.yaml`` and ship as a separate
+stylesheet scoped to :data:`CSS_CLASS`.
+"""
+
+from __future__ import annotations
+
+from html import escape
+
+from pygments import highlight as pygments_highlight
+from pygments.formatters import HtmlFormatter
+from pygments.lexers import get_lexer_by_name
+from pygments.styles import get_style_by_name
+from pygments.util import ClassNotFound
+
+from autodoc.config.project import HighlightConfig
+
+CSS_CLASS = "autodoc-hl"
+STYLESHEET_PATH = "assets/highlight.css"
+
+
+class HighlightError(Exception):
+    """Raised when a configured highlighting style does not exist."""
+
+
+def _formatter(style: str) -> HtmlFormatter:
+    try:
+        get_style_by_name(style)
+    except ClassNotFound as exc:
+        raise HighlightError(f"Unknown highlight style {style!r}") from exc
+    return HtmlFormatter(style=style, nowrap=True)
+
+
+class Highlighter:
+    """Highlight code snippets and produce the matching stylesheet."""
+
+    def __init__(self, config: HighlightConfig, mode: str = "auto") -> None:
+        """Create a highlighter.
+
+        Args:
+            config: Highlight settings
+            mode: Theme mode (``light``, ``dark`` or ``auto``)
+
+        Raises:
+            HighlightError: If a configured style does not exist
+        """
+        self.config = config
+        self.mode = mode
+        style = config.dark_style if mode == "dark" else config.style
+        self._formatter = _formatter(style)
+        self._dark_formatter = (
+            _formatter(config.dark_style) if mode == "auto" else None
+        )
+
+    @property
+    def enabled(self) -> bool:
+        return self.config.enabled
+
+    def highlight(self, code: str, language: str = "python") -> str:
+        """Return ``code`` as HTML markup (without the enclosing ``<pre>``)."""
+        if not self.enabled:
+            return escape(code)
+        try:
+            lexer = get_lexer_by_name(language)
+        except ClassNotFound:
+            return escape(code)
+        return pygments_highlight(code, lexer, self._formatter).rstrip("\n")
+
+    def stylesheet(self) -> str:
+        """CSS for highlighted code, honouring ``prefers-color-scheme`` in auto mode."""
+        if not self.enabled:
+            return ""
+        selector = f".{CSS_CLASS}"
+        css = self._formatter.get_style_defs(selector) + "\n"
+        if self._dark_formatter is not None:
+            css += (
+                "@media (prefers-color-scheme: dark) {\n"
+                f"{self._dark_formatter.get_style_defs(selector)}\n"
+                "}\n"
+            )
+        return css
+
+
+def snippet_language(code: str) -> str:
+    """Guess the lexer for a docstring snippet: doctest sessions or plain Python."""
+    return "pycon" if code.lstrip().startswith(">>>") else "python"
+
+
+__all__ = [
+    "CSS_CLASS",
+    "STYLESHEET_PATH",
+    "HighlightError",
+    "Highlighter",
+    "snippet_language",
+]
@@ -0,0 +1,1659 @@
+"""Static HTML rendering of the documentation site model.
+
+Produces ``index.html``, one page per package, an optional ``architecture.html``
+entry-point overview, and the theme assets from :mod:`services.doc_theme`. Pages are self-contained static files with no
+JavaScript, so the output can be served from any web server or object store.
+
+Every page has a skip link, ``header``/``main``/``footer`` landmarks, and one
+``h1`` with no skipped heading levels below it; package pages open with a
+table of contents. :mod:`services.doc_accessibility` audits the result.
+Charts are inline SVG beside the tables they draw, hidden from screen readers.
+"""
+
+from __future__ import annotations
+
+import re
+import textwrap
+from collections.abc import Callable, Container
+from html import escape
+
+from autodoc.config.project import SiteConfig
+from services.build_constants import BuildConstant
+from services.code_generation import CodeGeneration
+from services.dependency_graph import DependencyGraph
+from services.doc_analytics import analytics_head, analytics_pages
+from services.doc_edit_links import EditLinkFn
+from services.doc_external_links import ExternalLinkFn
+from services.doc_highlight import (
+    CSS_CLASS,
+    STYLESHEET_PATH as HIGHLIGHT_STYLESHEET_PATH,
+    Highlighter,
+    snippet_language,
+)
+from services.doc_site import (
+    ARCHITECTURE_SLUG,
+    ARCHITECTURE_TITLE,
+    ASSETS_HEADING,
+    BUILD_SLUG,
+    BUILD_TITLE,
+    DEPENDENCIES_SLUG,
+    DEPENDENCIES_TITLE,
+    FUZZ_TARGETS_HEADING,
+    GENERATION_SLUG,
+    GENERATION_TITLE,
+    GLOSSARY_SLUG,
+    GLOSSARY_TITLE,
+    GUIDES_HEADING,
+    NEWER_SYNTAX_HEADING,
+    PLATFORMS_SLUG,
+    PLATFORMS_TITLE,
+    REPLACEMENTS_HEADING,
+    RETRACTIONS_HEADING,
+    RISKS_SLUG,
+    RISKS_TITLE,
+    SIDE_EFFECTS_HEADING,
+    STATS_SLUG,
+    STATS_TITLE,
+    TEST_SUITE_TITLE,
+    THIRD_PARTY_SLUG,
+    THIRD_PARTY_TITLE,
+    ClassDoc,
+    PackageDoc,
+    SitePage,
+    signature,
+    summary,
+)
+from services.doc_symbols import DocSymbol
+from services.docstring_sections import SECTION_KINDS, ParsedDocstring
+from services.entry_points import ENTRY_POINT_KINDS, ArchitectureOverview
+from services.exception_chains import RaisedError
+from services.faq import FAQ_KINDS
+from services.glossary import GlossaryTerm
+from services.guides import Guide, expand_shortcodes
+from services.html_sanitizer import (
+    HEAD_ATTRIBUTES,
+    HEAD_TAGS,
+    HtmlFragment,
+    HtmlSanitizer,
+    build_sanitizer,
+)
+from services.inherited_docs import InheritedDoc
+from services.none_safety import NoneCheck
+from services.package_readme import demote_headings, outside_fences, parse_heading
+from services.pragmas import Pragma
+from services.platform_support import PLATFORM_TITLES, PLATFORMS, PackagePlatforms
+from services.project_directives import ProjectDirectives
+from services.python_features import PythonVersionReport, version_label
+from services.symbol_stats import STATS_HEADINGS, PackageStats, TypeStats
+from services.telemetry import span
+from services.test_docs import UNGROUPED, SuiteDoc
+from services.third_party import Dependency
+from services.unsafe_usage import RISK_KINDS, PackageRisks
+from services.doc_sitemap import canonical_url, render_sitemap
+from services.doc_theme import ThemeAssets
+
+SKIP_TARGET = "content"
+# Widths of the label column and of the longest bar of charts, in pixels.
+_CHART_LABEL = 160
+_CHART_BAR = 200
+
+# A paragraph that is a single Markdown image, as rewritten by
+# :func:`services.doc_assets.resolve_assets`.
+_IMAGE = re.compile(r"!\[(?P<alt>[^\]]*)\]\((?P<src>[^)\s]+)\)")
+# The Markdown of READMEs and guides that render_markdown turns into HTML.
+_MARKDOWN_INLINE = re.compile(
+    r"`(?P<code>[^`]+)`"
+    r"|!\[(?P<alt>[^\]]*)\]\((?P<src>[^)\s]+)\)"
+    r"|\[(?P<text>[^\[\]]*)\]\((?P<href>[^)\s]+)\)"
+    r"|\*\*(?P<bold>.+?)\*\*"
+    r"|(?<!\w)[*_](?P<italic>[^*_]+)[*_](?!\w)",
+)
+_MARKDOWN_ITEM = re.compile(r"^[ \t]*(?:(?P<bullet>[-*+])|\d+[.)])[ \t]+(?P<text>.*)$")
+_MARKDOWN_RULE = re.compile(r"^[ \t]*([-*_])(?:[ \t]*\1){2,}[ \t]*$")
+# A link to another package page, as resolve_assets rewrites README links.
+_PAGE_LINK = re.compile(r"(?P<slug>[^/#:]+)\.md(?P<fragment>#.*)?")
+
+
+def _is_code_block(paragraph: str) -> bool:
+    lines = [line for line in paragraph.splitlines() if line.strip()]
+    return bool(lines) and all(
+        line.startswith(("    ", "\t")) or line.lstrip().startswith(">>>")
+        for line in lines
+    )
+
+
+def _code(code: str, highlighter: Highlighter | None, language: str) -> str:
+    if highlighter is None or not highlighter.enabled:
+        return f"<code>{escape(code)}</code>"
+    return f'<code class="{CSS_CLASS}">{highlighter.highlight(code, language)}</code>'
+
+
+def render_docstring(
+    docstring: str | None,
+    highlighter: Highlighter | None = None,
+    sanitizer: HtmlSanitizer | None = None,
+) -> str:
+    """Render a docstring as paragraphs, example blocks, and figures.
+
+    A paragraph that is just a Markdown image (``![alt](src)``) becomes a
+    figure. Without ``sanitizer`` every tag is escaped; with it, the HTML it
+    allows is kept, and a paragraph starting with an allowed tag is an HTML
+    block rather than text.
+    """
+    if not docstring or not docstring.strip():
+        return '<p class="autodoc-undocumented">No documentation.</p>'
+    fragment = sanitizer.fragment() if sanitizer is not None else HtmlFragment()
+    for index, paragraph in enumerate(docstring.strip().split("\n\n")):
+        if index:
+            fragment.markup("\n")
+        image = _IMAGE.fullmatch(paragraph.strip())
+        if image is not None:
+            fragment.markup(_figure(image, sanitizer))
+        elif _is_code_block(paragraph):
+            code = textwrap.dedent(paragraph).strip("\n")
+            fragment.markup(
+                f"<pre>{_code(code, highlighter, snippet_language(code))}</pre>",
+            )
+        elif sanitizer is not None and sanitizer.is_html(paragraph):
+            fragment.text(paragraph.strip())
+        else:
+            fragment.markup("<p>")
+            fragment.text(" ".join(line.strip() for line in paragraph.splitlines()))
+            fragment.markup("</p>")
+    return fragment.html()
+
+
+def _figure(image: re.Match[str], sanitizer: HtmlSanitizer | None) -> str:
+    """The figure of a paragraph that is a Markdown image; its alt text if unsafe."""
+    if sanitizer is not None and not sanitizer.safe_url(image["src"]):
+        return f"<p>{escape(image['alt'])}</p>"
+    return (
+        '<figure class="autodoc-figure">'
+        f'<img src="{escape(image["src"], quote=True)}" '
+        f'alt="{escape(image["alt"], quote=True)}"></figure>'
+    )
+
+
+def _markdown_inline(text: str, sanitizer: HtmlSanitizer | None = None) -> str:
+    fragment = sanitizer.fragment() if sanitizer is not None else HtmlFragment()
+    _write_inline(text, fragment, sanitizer)
+    return fragment.html()
+
+
+def _write_inline(
+    text: str,
+    fragment: HtmlFragment,
+    sanitizer: HtmlSanitizer | None,
+) -> None:
+    position = 0
+    for match in _MARKDOWN_INLINE.finditer(text):
+        fragment.text(text[position : match.start()])
+        position = match.end()
+        if match["code"] is not None:
+            fragment.markup(f"<code>{escape(match['code'])}</code>")
+        elif match["src"] is not None:
+            if sanitizer is not None and not sanitizer.safe_url(match["src"]):
+                fragment.text(match["alt"])
+                continue
+            fragment.markup(
+                f'<img src="{escape(match["src"], quote=True)}" '
+                f'alt="{escape(match["alt"], quote=True)}">',
+            )
+        elif match["href"] is not None:
+            href = match["href"]
+            if sanitizer is not None and not sanitizer.safe_url(href):
+                _write_inline(match["text"], fragment, sanitizer)
+                continue
+            page = _PAGE_LINK.fullmatch(href)
+            if page is not None:
+                href = f"{page['slug']}.html{page['fragment'] or ''}"
+            fragment.markup(f'<a href="{escape(href, quote=True)}">')
+            _write_inline(match["text"], fragment, sanitizer)
+            fragment.markup("</a>")
+        else:
+            tag = "strong" if match["bold"] is not None else "em"
+            fragment.markup(f"<{tag}>")
+            _write_inline(match["bold"] or match["italic"], fragment, sanitizer)
+            fragment.markup(f"</{tag}>")
+    fragment.text(text[position:])
+
+
+def render_markdown(
+    markdown: str,
+    highlighter: Highlighter | None = None,
+    sanitizer: HtmlSanitizer | None = None,
+) -> str:
+    """Render a README or guide: headings, lists, rules, code, and paragraphs.
+
+    Headings start at ``h2`` (see
+    :func:`~services.package_readme.demote_headings`) and have no ids, so
+    they cannot clash with the anchors of symbols. Links to other package
+    pages (``slug.md``) point at their HTML pages. HTML is escaped, or with
+    ``sanitizer`` kept as far as it allows, as in :func:`render_docstring`;
+    an HTML block may span paragraphs, such as a ``<details>`` around them.
+    """
+    fragment = sanitizer.fragment() if sanitizer is not None else HtmlFragment()
+    started = False
+    paragraph: list[str] = []
+    items: list[str] = []
+    ordered = False
+    code: list[str] = []
+    fence: str | None = None
+    language = ""
+
+    def block(html: str = "") -> None:
+        nonlocal started
+        if started:
+            fragment.markup("\n")
+        started = True
+        fragment.markup(html)
+
+    def inline(tag: str, text: str) -> None:
+        fragment.markup(f"<{tag}>")
+        _write_inline(text, fragment, sanitizer)
+        fragment.markup(f"</{tag}>")
+
+    def flush() -> None:
+        if paragraph:
+            text = " ".join(paragraph)
+            image = _IMAGE.fullmatch(text)
+            if image is not None:
+                block(_figure(image, sanitizer))
+            elif sanitizer is not None and sanitizer.is_html(text):
+                block()
+                fragment.text("\n".join(paragraph))
+            else:
+                block()
+                inline("p", text)
+            paragraph.clear()
+        if items:
+            tag = "ol" if ordered else "ul"
+            block(f"<{tag}>\n")
+            for index, item in enumerate(items):
+                if index:
+                    fragment.markup("\n")
+                inline("li", item)
+            fragment.markup(f"\n</{tag}>")
+            items.clear()
+
+    def close() -> None:
+        source = "\n".join(code)
+        block(f"<pre>{_code(source, highlighter, language or 'text')}</pre>")
+        code.clear()
+
+    for line, prose in outside_fences(demote_headings(markdown)):
+        if not prose:
+            stripped = line.strip()
+            if fence is None:
+                flush()
+                fence = stripped[: len(stripped) - len(stripped.lstrip(stripped[0]))]
+                words = stripped[len(fence) :].split()
+                language = words[0] if words else ""
+            elif stripped.startswith(fence):
+                close()
+                fence = None
+            else:
+                code.append(line)
+            continue
+        if fence is not None:
+            close()
+            fence = None
+        heading = parse_heading(line)
+        item = _MARKDOWN_ITEM.match(line)
+        if not line.strip():
+            flush()
+        elif heading is not None:
+            flush()
+            level, text = heading
+            block()
+            inline(f"h{level}", text)
+        elif _MARKDOWN_RULE.match(line):
+            flush()
+            block("<hr>")
+        elif item is not None and not paragraph:
+            if items and ordered != (item["bullet"] is None):
+                flush()
+            ordered = item["bullet"] is None
+            items.append(item["text"])
+        elif items and line[:1] in (" ", "\t"):
+            items[-1] += " " + line.strip()
+        else:
+            if items:
+                flush()
+            paragraph.append(line.strip())
+    if fence is not None:
+        close()
+    flush()
+    return fragment.html()
+
+
+class HtmlSiteRenderer:
+    """Render packages into HTML pages with a theme and optional extras."""
+
+    def __init__(
+        self,
+        site: SiteConfig,
+        assets: ThemeAssets,
+        edit_link: EditLinkFn | None = None,
+        highlighter: Highlighter | None = None,
+        language: str | None = None,
+        external_links: ExternalLinkFn | None = None,
+    ) -> None:
+        self.site = site
+        self.assets = assets
+        self.edit_link = edit_link
+        self.highlighter = highlighter
+        self.external_links = external_links
+        self.sanitizer = build_sanitizer(site.sanitize)
+        # HTML wants BCP 47 tags (pt-BR) where catalogs are named pt_BR.
+        self.language = (language or "en").replace("_", "-")
+        self.stylesheets = list(assets.stylesheets)
+        if highlighter is not None and highlighter.enabled:
+            # After the base stylesheet, before custom CSS so overrides still win.
+            self.stylesheets.insert(1, HIGHLIGHT_STYLESHEET_PATH)
+
+    def _edit(self, symbol: DocSymbol) -> str:
+        url = self.edit_link(symbol) if self.edit_link else None
+        if not url:
+            return ""
+        return (
+            f' <a class="autodoc-edit" href="{escape(url, quote=True)}" '
+            'title="Edit this doc comment">edit</a>'
+        )
+
+    def _external(self, symbol: DocSymbol) -> str:
+        links = self.external_links(symbol) if self.external_links else {}
+        if not links:
+            return ""
+        names = ", ".join(
+            f'<a href="{escape(url, quote=True)}"><code>{escape(name)}</code></a>'
+            for name, url in links.items()
+        )
+        return f'<p class="autodoc-external">External types: {names}</p>\n'
+
+    def _text(self, text: str) -> str:
+        """User-authored ``text`` as HTML, sanitized unless turned off."""
+        return self.sanitizer.clean(text) if self.sanitizer else escape(text)
+
+    def _hook(self, html: str | None, head: bool = False) -> str:
+        """A theme hook: sanitized, or verbatim if sanitizing is turned off."""
+        if not html or self.sanitizer is None:
+            return html or ""
+        if head:
+            return self.sanitizer.extended(HEAD_TAGS, HEAD_ATTRIBUTES).clean(html)
+        return self.sanitizer.clean(html)
+
+    @staticmethod
+    def _pragmas(pragmas: list[Pragma] | None) -> str:
+        if not pragmas:
+            return ""
+        texts = ", ".join(
+            f"<code>{escape(p.text)}</code>"
+            + (" (private access)" if p.private_access else "")
+            for p in pragmas
+        )
+        return f'<p class="autodoc-pragmas">Pragmas: {texts}</p>\n'
+
+    def _docstring(
+        self,
+        symbol: DocSymbol,
+        parsed: ParsedDocstring | None,
+        placeholder: str | None = None,
+    ) -> str:
+        if placeholder is not None:
+            return (
+                '<p class="autodoc-undocumented autodoc-generated">'
+                f"Auto-generated summary: {escape(placeholder)}</p>"
+            )
+        if parsed is None:
+            return render_docstring(symbol.docstring, self.highlighter, self.sanitizer)
+        html = (
+            render_docstring(parsed.description, self.highlighter, self.sanitizer)
+            if parsed.description
+            else ""
+        )
+        for kind in SECTION_KINDS:
+            items = []
+            for field in parsed.section(kind):
+                term = f"<code>{escape(field.name)}</code>" if field.name else ""
+                if field.type:
+                    type_ = f"<code>{escape(field.type)}</code>"
+                    term += f" ({type_})" if term else type_
+                description = self._text(field.description)
+                items.append(f"<dt>{term}</dt><dd>{description}</dd>")
+            if items:
+                html += (
+                    f'\n<div class="autodoc-{kind}">\n'
+                    f"<p><strong>{kind.capitalize()}</strong></p>\n<dl>\n"
+                    + "\n".join(items)
+                    + "\n</dl>\n</div>"
+                )
+        return html
+
+    @staticmethod
+    def _raises(errors: list[RaisedError] | None) -> str:
+        if not errors:
+            return ""
+        texts = ", ".join(
+            error.phrase(lambda name: f"<code>{escape(name)}</code>")
+            for error in errors
+        )
+        return f'<p class="autodoc-raises">Raises: {texts}</p>\n'
+
+    @staticmethod
+    def _none_safety(checks: list[NoneCheck] | None) -> str:
+        html = ""
+        for safe, label in ((True, "None-safe"), (False, "Not None-safe")):
+            names = [
+                f"<code>{escape(c.parameter)}</code>"
+                for c in checks or []
+                if c.safe == safe
+            ]
+            if names:
+                html += (
+                    f'<p class="autodoc-none-safety">{label}: {", ".join(names)}</p>\n'
+                )
+        return html
+
+    def _symbol_section(
+        self,
+        symbol: DocSymbol,
+        level: int,
+        cls: ClassDoc | None = None,
+        link: Callable[[str], str] | None = None,
+        namesakes: list[DocSymbol] | None = None,
+        pragmas: list[Pragma] | None = None,
+        none_safety: list[NoneCheck] | None = None,
+        raises: list[RaisedError] | None = None,
+        docstring: ParsedDocstring | None = None,
+        inherited: InheritedDoc | None = None,
+        placeholder: str | None = None,
+    ) -> str:
+        anchor = escape(symbol.qualified_name, quote=True)
+        code = _code(signature(symbol), self.highlighter, "python")
+        link = link or self._linker([])
+        mocks = ""
+        if cls is not None:
+            if cls.mocks:
+                names = ", ".join(link(name) for name in cls.mocks)
+                mocks += f'<p class="autodoc-mocks">Mocked by {names}</p>\n'
+            if cls.mocked:
+                names = ", ".join(link(name) for name in cls.mocked)
+                mocks += f'<p class="autodoc-mocks">Mocks {names}</p>\n'
+        source = ""
+        if inherited is not None:
+            source = (
+                '<p class="autodoc-inherited">'
+                f"Inherited from {link(inherited.source)}</p>\n"
+            )
+        # Namesakes are always shown with their full path.
+        title = symbol.qualified_name if namesakes else symbol.name
+        others = ""
+        if namesakes:
+            items = []
+            for other in namesakes:
+                text = summary(other.docstring)
+                items.append(
+                    f"<li>{link(other.qualified_name)}"
+                    + (f" - {escape(text)}" if text else "")
+                    + "</li>",
+                )
+            others = (
+                '<div class="autodoc-namesakes">\n'
+                f"<p>Not to be confused with the other <code>{escape(symbol.name)}"
+                "</code>:</p>\n<ul>\n" + "\n".join(items) + "\n</ul>\n</div>\n"
+            )
+        return (
+            f'<section class="autodoc-symbol" id="{anchor}">\n'
+            f'<h{level}><span class="autodoc-kind">{escape(symbol.kind)}</span> '
+            f'<a href="#{anchor}">{escape(title)}</a>'
+            f"{self._edit(symbol)}</h{level}>\n"
+            f'<pre class="autodoc-signature">{code}</pre>\n'
+            f"{self._external(symbol)}"
+            f"{source}"
+            f"{self._docstring(symbol, docstring, placeholder)}\n"
+            f"{mocks}"
+            f"{others}"
+            f"{self._raises(raises)}"
+            f"{self._none_safety(none_safety)}"
+            f"{self._pragmas(pragmas)}"
+            "</section>"
+        )
+
+    def _most_used(self, package: PackageDoc) -> str:
+        items = []
+        for symbol, count in package.most_used:
+            noun = "package" if count == 1 else "packages"
+            items.append(
+                f'<li><a href="#{escape(symbol.qualified_name, quote=True)}">'
+                f"<code>{escape(symbol.qualified_name)}</code></a> "
+                f"used by {count} {noun}</li>",
+            )
+        return (
+            '<section class="autodoc-most-used">\n<h2>Most used</h2>\n<ol>\n'
+            + "\n".join(items)
+            + "\n</ol>\n</section>"
+        )
+
+    @staticmethod
+    def _assets(package: PackageDoc) -> str:
+        items = []
+        for asset in package.assets:
+            item = (
+                f"<li><code>{escape(asset.qualified_name)}</code> loads "
+                f"<code>{escape(asset.pattern)}</code>"
+            )
+            if not asset.files:
+                item += " (no matching file)"
+            elif len(asset.files) > 1 or asset.files[0] != asset.pattern:
+                files = "\n".join(
+                    f"<li><code>{escape(path)}</code></li>" for path in asset.files
+                )
+                item += f"\n<ul>\n{files}\n</ul>\n"
+            items.append(f"{item}</li>")
+        return (
+            f'<section class="autodoc-assets">\n<h2>{ASSETS_HEADING}</h2>\n<ul>\n'
+            + "\n".join(items)
+            + "\n</ul>\n</section>"
+        )
+
+    @staticmethod
+    def _side_effects(package: PackageDoc) -> str:
+        modules: dict[str, list[str]] = {}
+        for effect in package.side_effects:
+            text = effect.sentence(lambda name: f"<code>{escape(name)}</code>")
+            modules.setdefault(effect.module, []).append(
+                f"<li>{text} (line {effect.lineno})</li>",
+            )
+        items = [
+            f"<li><code>{escape(module)}</code>\n<ul>\n"
+            + "\n".join(effects)
+            + "\n</ul>\n</li>"
+            for module, effects in modules.items()
+        ]
+        return (
+            '<section class="autodoc-side-effects">\n'
+            f"<h2>{SIDE_EFFECTS_HEADING}</h2>\n"
+            "<p>Importing these modules also:</p>\n<ul>\n"
+            + "\n".join(items)
+            + "\n</ul>\n</section>"
+        )
+
+    def _faq(self, package: PackageDoc) -> list[str]:
+        anchors = {symbol.qualified_name for symbol in package.symbols()}
+        sections = []
+        for kind, title in FAQ_KINDS.items():
+            entries = []
+            for entry in package.faq:
+                if entry.kind != kind:
+                    continue
+                entries.append(
+                    f"<h3>{_markdown_inline(entry.question, self.sanitizer)}</h3>\n"
+                    + render_markdown(entry.answer, self.highlighter, self.sanitizer),
+                )
+                if entry.symbol in anchors:
+                    href = escape(f"#{entry.symbol}", quote=True)
+                    entries.append(
+                        f'<p>See <a href="{href}">'
+                        f"<code>{escape(entry.symbol)}</code></a>.</p>",
+                    )
+            if entries:
+                sections.append(
+                    f'<section class="autodoc-{kind}">\n<h2>{title}</h2>\n'
+                    + "\n".join(entries)
+                    + "\n</section>",
+                )
+        return sections
+
+    @staticmethod
+    def _toc(package: PackageDoc) -> str:
+        """Nested links to every module and its top-level symbols."""
+
+        def item(anchor: str, text: str, children: list[str]) -> str:
+            nested = "\n<ul>\n" + "\n".join(children) + "\n</ul>\n" if children else ""
+            return (
+                f'<li><a href="#{escape(anchor, quote=True)}">'
+                f"<code>{escape(text)}</code></a>{nested}</li>"
+            )
+
+        modules = []
+        for module in package.modules:
+            symbols = [
+                item(func.qualified_name, func.name, []) for func in module.functions
+            ]
+            symbols.extend(
+                item(cls.symbol.qualified_name, cls.symbol.name, [])
+                for cls in module.classes
+            )
+            modules.append(item(module.name, module.name, symbols))
+        return (
+            '<nav class="autodoc-toc" aria-label="Contents">\n<ul>\n'
+            + "\n".join(modules)
+            + "\n</ul>\n</nav>"
+        )
+
+    def package_body(
+        self,
+        package: PackageDoc,
+        link: Callable[[str], str] | None = None,
+    ) -> str:
+        parts = [f"<h1>{escape(package.name)}</h1>"]
+        if package.readme is not None and package.readme.content.strip():
+            readme = render_markdown(
+                package.readme.content,
+                self.highlighter,
+                self.sanitizer,
+            )
+            parts.append(f'<div class="autodoc-readme">\n{readme}\n</div>')
+        parts.append(self._toc(package))
+        if package.quickstart is not None:
+            code = _code(package.quickstart.code.rstrip(), self.highlighter, "python")
+            parts.append(
+                '<section class="autodoc-quickstart">\n<h2>Quickstart</h2>\n'
+                f"<pre>{code}</pre>\n</section>",
+            )
+        if package.most_used:
+            parts.append(self._most_used(package))
+        for module in package.modules:
+            anchor = escape(module.name, quote=True)
+            docstring = self._docstring(
+                module.symbol,
+                package.docstrings.get(module.name),
+                package.placeholders.get(module.name),
+            )
+            parts.append(
+                f'<section class="autodoc-module" id="{anchor}">\n'
+                f"<h2>{escape(module.name)}{self._edit(module.symbol)}</h2>\n"
+                f"{docstring}\n"
+                f"{self._pragmas(package.pragmas.get(module.name))}"
+                "</section>",
+            )
+            parts.extend(
+                self._symbol_section(
+                    func,
+                    3,
+                    link=link,
+                    namesakes=package.namesakes.get(func.qualified_name),
+                    pragmas=package.pragmas.get(func.qualified_name),
+                    none_safety=package.none_safety.get(func.qualified_name),
+                    raises=package.raises.get(func.qualified_name),
+                    docstring=package.docstrings.get(func.qualified_name),
+                    placeholder=package.placeholders.get(func.qualified_name),
+                )
+                for func in module.functions
+            )
+            for cls in module.classes:
+                name = cls.symbol.qualified_name
+                parts.append(
+                    self._symbol_section(
+                        cls.symbol,
+                        3,
+                        cls,
+                        link,
+                        package.namesakes.get(name),
+                        package.pragmas.get(name),
+                        docstring=package.docstrings.get(name),
+                        placeholder=package.placeholders.get(name),
+                    ),
+                )
+                parts.extend(
+                    self._symbol_section(
+                        method,
+                        4,
+                        link=link,
+                        pragmas=package.pragmas.get(method.qualified_name),
+                        none_safety=package.none_safety.get(method.qualified_name),
+                        raises=package.raises.get(method.qualified_name),
+                        docstring=package.docstrings.get(method.qualified_name),
+                        inherited=package.inherited.get(method.qualified_name),
+                        placeholder=package.placeholders.get(method.qualified_name),
+                    )
+                    for method in cls.methods
+                )
+        if package.assets:
+            parts.append(self._assets(package))
+        if package.side_effects:
+            parts.append(self._side_effects(package))
+        if package.faq:
+            parts.extend(self._faq(package))
+        return "\n".join(parts)
+
+    @staticmethod
+    def _linker(packages: list[PackageDoc]) -> Callable[[str], str]:
+        """Return a function rendering a qualified name, linked when documented."""
+        pages = {
+            symbol.qualified_name: package.slug
+            for package in packages
+            for symbol in package.symbols()
+        }
+
+        def link(name: str) -> str:
+            code = f"<code>{escape(name)}</code>"
+            if name not in pages:
+                return code
+            href = escape(f"{pages[name]}.html#{name}", quote=True)
+            return f'<a href="{href}">{code}</a>'
+
+        return link
+
+    def architecture_body(
+        self,
+        overview: ArchitectureOverview,
+        packages: list[PackageDoc],
+    ) -> str:
+        link = self._linker(packages)
+        parts = [
+            f"<h1>{ARCHITECTURE_TITLE}</h1>",
+            "<p>How the application starts and how its application objects "
+            "are wired.</p>",
+        ]
+        if overview.entry_points:
+            rows = []
+            for entry in overview.entry_points:
+                runs = ", ".join(link(name) for name in entry.runs) or "-"
+                rows.append(
+                    f"<tr><td>{escape(ENTRY_POINT_KINDS[entry.kind])}</td>"
+                    f"<td><code>{escape(entry.name)}</code></td><td>{runs}</td>"
+                    f"<td><code>{escape(entry.location)}</code></td></tr>",
+                )
+            parts.append(
+                '<section class="autodoc-entry-points">\n<h2>Entry points</h2>\n'
+                '<table>\n<thead><tr><th scope="col">Kind</th>'
+                '<th scope="col">Name</th><th scope="col">Runs</th>'
+                '<th scope="col">Location</th></tr></thead>\n<tbody>\n'
+                + "\n".join(rows)
+                + "\n</tbody>\n</table>\n</section>",
+            )
+        if overview.apps:
+            parts.append("<h2>Application wiring</h2>")
+        for app in overview.apps:
+            intro = (
+                f"{escape(app.framework)} application created at "
+                f"<code>{escape(app.file_path)}:{app.lineno}</code>"
+            )
+            if app.factory:
+                intro += f" and returned by {link(app.factory)}"
+            steps = []
+            for step in app.steps:
+                detail = f" ({escape(step.detail)})" if step.detail else ""
+                steps.append(
+                    f"<li>{escape(step.kind.capitalize())}: {link(step.target)}"
+                    f"{detail}, line {step.lineno}</li>",
+                )
+            section = (
+                '<section class="autodoc-app">\n'
+                f"<h3><code>{escape(app.name)}</code></h3>\n<p>{intro}.</p>"
+            )
+            if steps:
+                section += "\n<ol>\n" + "\n".join(steps) + "\n</ol>"
+            parts.append(section + "\n</section>")
+        return "\n".join(parts)
+
+    def dependencies_body(
+        self,
+        graph: DependencyGraph,
+        packages: list[PackageDoc],
+    ) -> str:
+        link = self._linker(packages)
+        parts = [
+            f"<h1>{DEPENDENCIES_TITLE}</h1>",
+            "<p>How components are constructed and injected.</p>",
+        ]
+        for name in sorted(graph.providers):
+            provider = graph.providers[name]
+            if provider.provides:
+                description = (
+                    f"{escape(provider.kind)} provider building "
+                    f"{link(provider.provides)}"
+                )
+            else:
+                description = escape(provider.kind.capitalize())
+            if provider.file_path:
+                description += (
+                    f" defined at <code>{escape(provider.file_path)}:"
+                    f"{provider.lineno}</code>"
+                )
+            section = [
+                f'<section class="autodoc-provider" id="{escape(name, quote=True)}">',
+                f"<h2>{link(name)}</h2>",
+                f"<p>{description}.</p>",
+            ]
+            needs = graph.dependencies_of(name)
+            if needs:
+                section.append(
+                    f"<p>Depends on {', '.join(link(n) for n in needs)}.</p>",
+                )
+            consumers = graph.consumers_of(name)
+            if consumers:
+                section.append("<p>Injected into:</p>\n<ul>")
+                for injection in consumers:
+                    parameter = (
+                        f" as <code>{escape(injection.parameter)}</code>"
+                        if injection.parameter
+                        else ""
+                    )
+                    section.append(
+                        f"<li>{link(injection.consumer)}{parameter}, "
+                        f"<code>{escape(injection.file_path)}:{injection.lineno}</code>"
+                        "</li>",
+                    )
+                section.append("</ul>")
+            parts.append("\n".join([*section, "</section>"]))
+        return "\n".join(parts)
+
+    def glossary_body(
+        self,
+        terms: list[GlossaryTerm],
+        packages: list[PackageDoc],
+    ) -> str:
+        link = self._linker(packages)
+        entries = []
+        for term in terms:
+            definition = escape(term.definition)
+            if term.defined_by:
+                see = ", ".join(link(name) for name in term.defined_by)
+                definition += f" See {see}."
+            entries.append(
+                f'<dt id="{escape(term.term.replace(" ", "-"), quote=True)}">'
+                f"{escape(term.term)}</dt>\n<dd>{definition.strip()}</dd>",
+            )
+        return (
+            f"<h1>{GLOSSARY_TITLE}</h1>\n"
+            "<p>Domain terms used across the code base and where they are "
+            'defined.</p>\n<dl class="autodoc-glossary">\n'
+            + "\n".join(entries)
+            + "\n</dl>"
+        )
+
+    def generation_body(
+        self,
+        generation: CodeGeneration,
+        packages: list[PackageDoc],
+    ) -> str:
+        link = self._linker(packages)
+        parts = [
+            f"<h1>{GENERATION_TITLE}</h1>",
+            "<p>Commands that generate code, where they are declared, and the "
+            "files they produce.</p>",
+        ]
+        if generation.directives:
+            items = []
+            for directive in generation.directives:
+                item = (
+                    f"<li><code>{escape(directive.command)}</code> "
+                    f"(<code>{escape(directive.file_path)}:{directive.lineno}</code>)"
+                )
+                if directive.outputs:
+                    outputs = ", ".join(
+                        f"<code>{escape(output)}</code>" for output in directive.outputs
+                    )
+                    item += f"\n<p>Writes {outputs}</p>"
+                if directive.generated:
+                    files = ", ".join(
+                        f"<code>{escape(path)}</code>" for path in directive.generated
+                    )
+                    item += f"\n<p>Generated files: {files}</p>"
+                items.append(item + "</li>")
+            parts.append(
+                '<section class="autodoc-generation" id="commands">\n'
+                "<h2>Commands</h2>\n<ul>\n" + "\n".join(items) + "\n</ul>\n</section>",
+            )
+        if generation.generated:
+            items = []
+            for generated in generation.generated:
+                item = (
+                    f"<li><code>{escape(generated.file_path)}</code> "
+                    f"({link(generated.module)})"
+                )
+                if generated.tool:
+                    item += f" - generated by {escape(generated.tool)}"
+                items.append(item + "</li>")
+            parts.append(
+                '<section class="autodoc-generated" id="generated-files">\n'
+                "<h2>Generated files</h2>\n<ul>\n"
+                + "\n".join(items)
+                + "\n</ul>\n</section>",
+            )
+        return "\n".join(parts)
+
+    def build_body(self, constants: list[BuildConstant]) -> str:
+        rows = []
+        for constant in constants:
+            default = (
+                f"<code>{escape(constant.default)}</code>"
+                if constant.default is not None
+                else "-"
+            )
+            setter = constant.setter(lambda name: f"<code>{escape(name)}</code>")
+            rows.append(
+                f"<tr><td><code>{escape(constant.qualified_name)}</code></td>"
+                f"<td>{default}</td><td>{setter}</td>"
+                f"<td><code>{escape(constant.file_path)}:{constant.lineno}</code>"
+                "</td></tr>",
+            )
+        return (
+            f"<h1>{BUILD_TITLE}</h1>\n"
+            "<p>Values filled in when the code is built or deployed, and what "
+            "they are when nothing is injected.</p>\n"
+            '<table class="autodoc-build">\n<thead><tr><th>Variable</th>'
+            "<th>Default</th><th>Set by</th><th>Location</th></tr></thead>\n"
+            "<tbody>\n" + "\n".join(rows) + "\n</tbody>\n</table>"
+        )
+
+    @staticmethod
+    def _chart(bars: list[tuple[str, int]]) -> str:
+        """A horizontal bar chart of ``(label, value)`` pairs."""
+        peak = max((value for _, value in bars), default=0) or 1
+        width = _CHART_LABEL + _CHART_BAR + 40
+        height = 20 * len(bars)
+        rows = []
+        for index, (label, value) in enumerate(bars):
+            y = 20 * index
+            bar = round(_CHART_BAR * value / peak)
+            rows.append(
+                f'<text x="0" y="{y + 14}">{escape(label)}</text>'
+                f'<rect x="{_CHART_LABEL}" y="{y + 3}" width="{bar}" height="14"/>'
+                f'<text x="{_CHART_LABEL + bar + 4}" y="{y + 14}">{value}</text>',
+            )
+        return (
+            f'<svg class="autodoc-chart" width="{width}" height="{height}" '
+            f'viewBox="0 0 {width} {height}" aria-hidden="true">\n'
+            + "\n".join(rows)
+            + "\n</svg>"
+        )
+
+    def _types(
+        self,
+        heading: str,
+        types: tuple[TypeStats, ...],
+        value: Callable[[TypeStats], int],
+        unit: str,
+        link: Callable[[str], str],
+    ) -> str:
+        items = "\n".join(
+            f"<li>{link(t.qualified_name)} ({value(t)} {unit})</li>" for t in types
+        )
+        bars = [(t.qualified_name.rpartition(".")[2], value(t)) for t in types]
+        return f"<h3>{heading}</h3>\n<ol>\n{items}\n</ol>\n{self._chart(bars)}"
+
+    def stats_body(self, stats: list[PackageStats], packages: list[PackageDoc]) -> str:
+        link = self._linker(packages)
+        headings = "".join(f"<th>{heading}</th>" for heading in STATS_HEADINGS)
+        rows = []
+        for package in stats:
+            counts = "".join(f"<td>{count}</td>" for count in package.kinds)
+            rows.append(
+                f'<tr><td><a href="{escape(package.slug, quote=True)}.html">'
+                f"{escape(package.name)}</a></td>{counts}"
+                f"<td>{package.exported} of {package.total} "
+                f"({package.exported_percent}%)</td>"
+                f"<td>{package.doc_length} characters</td></tr>",
+            )
+        parts = [
+            f"<h1>{STATS_TITLE}</h1>",
+            "<p>The size and documentation of each package. Counts include "
+            "unexported symbols.</p>",
+            f'<table class="autodoc-stats">\n<thead><tr><th>Package</th>{headings}'
+            "<th>Exported</th><th>Average docstring</th></tr></thead>\n"
+            "<tbody>\n" + "\n".join(rows) + "\n</tbody>\n</table>",
+        ]
+        for package in stats:
+            kinds = list(zip(STATS_HEADINGS, package.kinds))
+            anchor = escape(package.slug, quote=True)
+            section = [
+                f'<section class="autodoc-stats" id="{anchor}">',
+                f"<h2>{escape(package.name)}</h2>",
+                self._chart(kinds),
+            ]
+            if package.largest:
+                section.append(
+                    self._types(
+                        "Largest types",
+                        package.largest,
+                        lambda t: t.lines,
+                        "line(s)",
+                        link,
+                    ),
+                )
+            if package.most_methods:
+                section.append(
+                    self._types(
+                        "Most methods",
+                        package.most_methods,
+                        lambda t: t.methods,
+                        "method(s)",
+                        link,
+                    ),
+                )
+            parts.append("\n".join(section) + "\n</section>")
+        return "\n".join(parts)
+
+    def test_suite_body(self, suite: SuiteDoc) -> str:
+        parts = [
+            f"<h1>{TEST_SUITE_TITLE}</h1>",
+            f"<p>{len(suite.tests)} test(s), grouped by the symbol under test.</p>",
+        ]
+        fuzz_targets = suite.fuzz_targets()
+        if fuzz_targets:
+            items = []
+            for test in fuzz_targets:
+                item = (
+                    f"<li><code>{escape(test.qualified_name)}</code> "
+                    f"({escape(test.fuzz or '')}, <code>{escape(test.file_path)}:"
+                    f"{test.lineno}</code>) - {test.seeds} seed(s): "
+                    f"{test.examples} example(s), {test.corpus} corpus file(s)"
+                )
+                if test.subjects:
+                    names = ", ".join(
+                        f"<code>{escape(name)}</code>" for name in test.subjects
+                    )
+                    item += f'\n<p class="autodoc-cases">Exercises: {names}</p>'
+                items.append(item + "</li>")
+            parts.append(
+                '<section class="autodoc-fuzz" id="fuzz-targets">\n'
+                f"<h2>{FUZZ_TARGETS_HEADING}</h2>\n<ul>\n"
+                + "\n".join(items)
+                + "\n</ul>\n</section>",
+            )
+        for subject, tests in suite.groups():
+            items = []
+            for test in tests:
+                text = escape(test.sentence)
+                if test.docstring:
+                    text += f" - {escape(summary(test.docstring))}"
+                owner = f"{escape(test.test_class)}, " if test.test_class else ""
+                item = (
+                    f"<li>{text} ({owner}<code>{escape(test.file_path)}:"
+                    f"{test.lineno}</code>)"
+                )
+                if test.cases:
+                    cases = "; ".join(f"<code>{escape(c)}</code>" for c in test.cases)
+                    item += f'\n<p class="autodoc-cases">Cases: {cases}</p>'
+                items.append(item + "</li>")
+            anchor = escape(subject, quote=True)
+            title = (
+                escape(subject)
+                if subject == UNGROUPED
+                else f"<code>{escape(subject)}</code>"
+            )
+            parts.append(
+                f'<section class="autodoc-tests" id="{anchor}">\n'
+                f"<h2>{title}</h2>\n<ul>\n" + "\n".join(items) + "\n</ul>\n</section>",
+            )
+        return "\n".join(parts)
+
+    def render_tests(self, suite: SuiteDoc) -> list[SitePage]:
+        """Render the test suite page as ``index.html`` plus assets."""
+        pages = [
+            SitePage(
+                "index.html",
+                self.layout(
+                    f"{TEST_SUITE_TITLE} - {self.site.title}",
+                    self.test_suite_body(suite),
+                    "index.html",
+                ),
+            ),
+            *render_sitemap(self.site, ["index.html"]),
+        ]
+        if self.highlighter is not None and self.highlighter.enabled:
+            pages.append(
+                SitePage(HIGHLIGHT_STYLESHEET_PATH, self.highlighter.stylesheet()),
+            )
+        return pages + analytics_pages(self.site.analytics) + self.assets.pages
+
+    def risks_body(self, risks: list[PackageRisks], packages: list[PackageDoc]) -> str:
+        link = self._linker(packages)
+        headings = "".join(f"<th>{kind.capitalize()}</th>" for kind in RISK_KINDS)
+        rows = [
+            f"<tr><td>{escape(package.package)}</td>"
+            + "".join(f"<td>{package.count(kind)}</td>" for kind in RISK_KINDS)
+            + "</tr>"
+            for package in risks
+        ]
+        parts = [
+            f"<h1>{RISKS_TITLE}</h1>",
+            "<p>Native code, unsafe calls, and reflection, where the "
+            "interpreter's guarantees stop.</p>",
+            f'<table class="autodoc-risks">\n<thead><tr><th>Package</th>{headings}'
+            "</tr></thead>\n<tbody>\n" + "\n".join(rows) + "\n</tbody>\n</table>",
+        ]
+        for package in risks:
+            items = []
+            for usage in package.usages:
+                line = f":{usage.lineno}" if usage.lineno else ""
+                where = f"<code>{escape(usage.file_path)}{line}</code>"
+                if usage.symbol is not None:
+                    where += f" in {link(usage.symbol)}"
+                sentence = usage.sentence(lambda name: f"<code>{escape(name)}</code>")
+                items.append(
+                    f'<li><span class="autodoc-kind">{usage.kind}</span> '
+                    f"{where} {sentence}</li>",
+                )
+            parts.append(
+                f"<h2>{escape(package.package)}</h2>\n<ul>\n"
+                + "\n".join(items)
+                + "\n</ul>",
+            )
+        return "\n".join(parts)
+
+    def platforms_body(
+        self,
+        platforms: list[PackagePlatforms],
+        packages: list[PackageDoc],
+    ) -> str:
+        link = self._linker(packages)
+        headings = "".join(
+            f"<th>{PLATFORM_TITLES[platform]}</th>" for platform in PLATFORMS
+        )
+        rows = [
+            f"<tr><td>{escape(package.package)}</td>"
+            + "".join(f"<td>{package.support(p)}</td>" for p in PLATFORMS)
+            + "</tr>"
+            for package in platforms
+        ]
+        parts = [
+            f"<h1>{PLATFORMS_TITLE}</h1>",
+            "<p>Which packages exist on each platform: <code>partial</code> when "
+            "some of their modules or symbols do not.</p>",
+            f'<table class="autodoc-platforms">\n<thead><tr><th>Package</th>'
+            f"{headings}</tr></thead>\n<tbody>\n"
+            + "\n".join(rows)
+            + "\n</tbody>\n</table>",
+        ]
+        restrictions = [r for package in platforms for r in package.restrictions]
+        if not restrictions:
+            parts.append("<p>No module or symbol is specific to a platform.</p>")
+            return "\n".join(parts)
+        rows = []
+        for restriction in restrictions:
+            available = "".join(
+                f"<td>{'yes' if p in restriction.platforms else 'no'}</td>"
+                for p in PLATFORMS
+            )
+            reason = restriction.reason(lambda name: f"<code>{escape(name)}</code>")
+            where = f"<code>{escape(restriction.file_path)}:{restriction.lineno}</code>"
+            rows.append(
+                f"<tr><td>{link(restriction.name)}</td>{available}"
+                f"<td>{reason} ({where})</td></tr>",
+            )
+        parts.append(
+            "<h2>Platform-specific modules and symbols</h2>\n"
+            f'<table class="autodoc-platforms">\n<thead><tr><th>Name</th>{headings}'
+            "<th>Why</th></tr></thead>\n<tbody>\n"
+            + "\n".join(rows)
+            + "\n</tbody>\n</table>",
+        )
+        return "\n".join(parts)
+
+    def third_party_body(
+        self,
+        dependencies: list[Dependency],
+        packages: list[PackageDoc],
+    ) -> str:
+        slugs = {package.name: package.slug for package in packages}
+        rows = []
+        for dependency in dependencies:
+            importers = ", ".join(
+                f'<a href="{escape(slugs[name], quote=True)}.html">{escape(name)}</a>'
+                if name in slugs
+                else escape(name)
+                for name in dependency.importers
+            )
+            rows.append(
+                f"<tr><td>{escape(dependency.distribution)}</td>"
+                f"<td>{escape(dependency.version_label())}</td>"
+                f"<td>{escape(dependency.license or 'unknown')}</td>"
+                f"<td>{importers or 'not imported'}</td></tr>",
+            )
+        return (
+            f"<h1>{THIRD_PARTY_TITLE}</h1>\n"
+            "<p>The distributions the project declares, with the packages that "
+            "import them.</p>\n"
+            '<table class="autodoc-third-party">\n<thead><tr><th>Distribution</th>'
+            "<th>Version</th><th>License</th><th>Imported by</th></tr></thead>\n"
+            "<tbody>\n" + "\n".join(rows) + "\n</tbody>\n</table>"
+        )
+
+    def directives_body(self, directives: ProjectDirectives) -> str:
+        """The replaced dependencies and retracted versions of the index."""
+        parts = []
+        if directives.replacements:
+            rows = "\n".join(
+                f"<tr><td>{escape(r.distribution)}</td><td>{escape(r.kind)}</td>"
+                f"<td><code>{escape(r.target)}</code></td></tr>"
+                for r in directives.replacements
+            )
+            parts.append(
+                f"<h2>{REPLACEMENTS_HEADING}</h2>\n"
+                "<p>These dependencies are not installed from the package index.</p>\n"
+                '<table class="autodoc-replacements">\n<thead><tr><th>Distribution'
+                "</th><th>Source</th><th>Target</th></tr></thead>\n"
+                f"<tbody>\n{rows}\n</tbody>\n</table>",
+            )
+        if directives.retractions:
+            items = "\n".join(
+                f"<li><code>{escape(r.label())}</code>"
+                + (f": {escape(r.rationale)}" if r.rationale else "")
+                + "</li>"
+                for r in directives.retractions
+            )
+            parts.append(
+                f"<h2>{RETRACTIONS_HEADING}</h2>\n"
+                "<p>These releases should not be used.</p>\n"
+                f'<ul class="autodoc-retractions">\n{items}\n</ul>',
+            )
+        return "\n".join(parts)
+
+    def newer_syntax_body(self, report: PythonVersionReport) -> str:
+        """The index section on syntax ``requires-python`` does not allow."""
+        rows = "\n".join(
+            f"<tr><td>{escape(usage.description)}</td>"
+            f"<td>{version_label(usage.version)}</td>"
+            f"<td><code>{escape(usage.file_path)}:{usage.lineno}</code></td></tr>"
+            for usage in report.newer()
+        )
+        minimum = version_label(report.minimum) if report.minimum else ""
+        return (
+            f"<h2>{NEWER_SYNTAX_HEADING}</h2>\n"
+            f"<p><code>requires-python</code> allows Python {minimum}, but these "
+            "need a newer one.</p>\n"
+            '<table class="autodoc-newer-syntax">\n<thead><tr><th>Feature</th>'
+            "<th>Since</th><th>Where</th></tr></thead>\n"
+            f"<tbody>\n{rows}\n</tbody>\n</table>"
+        )
+
+    def index_body(
+        self,
+        packages: list[PackageDoc],
+        architecture: ArchitectureOverview | None = None,
+        dependencies: DependencyGraph | None = None,
+        glossary: list[GlossaryTerm] | None = None,
+        generation: CodeGeneration | None = None,
+        build_constants: list[BuildConstant] | None = None,
+        stats: list[PackageStats] | None = None,
+        guides: list[Guide] | None = None,
+        risks: list[PackageRisks] | None = None,
+        third_party: list[Dependency] | None = None,
+        directives: ProjectDirectives | None = None,
+        python_version: PythonVersionReport | None = None,
+        platforms: list[PackagePlatforms] | None = None,
+    ) -> str:
+        rows = []
+        for package in packages:
+            doc = next((m.symbol.docstring for m in package.modules), None)
+            rows.append(
+                f'<li><a href="{escape(package.slug, quote=True)}.html">'
+                f"{escape(package.name)}</a> {escape(summary(doc))}</li>",
+            )
+        overview = ""
+        retracted = directives.retracted() if directives else None
+        if retracted is not None:
+            reason = f": {escape(retracted.rationale)}" if retracted.rationale else "."
+            overview += (
+                '<p class="autodoc-retracted" role="alert"><strong>Warning:</strong> '
+                f"version {escape(directives.version)} is retracted{reason}</p>\n"
+            )
+        if python_version is not None:
+            sentence = python_version.sentence(lambda name: f"<code>{name}</code>")
+            overview += f'<p class="autodoc-python-version">{sentence}</p>\n'
+        if architecture:
+            overview += (
+                f'<p><a href="{ARCHITECTURE_SLUG}.html">{ARCHITECTURE_TITLE}</a>: '
+                "entry points and how the application boots.</p>\n"
+            )
+        if dependencies:
+            overview += (
+                f'<p><a href="{DEPENDENCIES_SLUG}.html">{DEPENDENCIES_TITLE}</a>: '
+                "how components are constructed and injected.</p>\n"
+            )
+        if glossary:
+            overview += (
+                f'<p><a href="{GLOSSARY_SLUG}.html">{GLOSSARY_TITLE}</a>: '
+                "the domain terms of the code base.</p>\n"
+            )
+        if generation:
+            overview += (
+                f'<p><a href="{GENERATION_SLUG}.html">{GENERATION_TITLE}</a>: '
+                "commands that generate code and the files they produce.</p>\n"
+            )
+        if build_constants:
+            overview += (
+                f'<p><a href="{BUILD_SLUG}.html">{BUILD_TITLE}</a>: '
+                "values set when the code is built or deployed.</p>\n"
+            )
+        if stats:
+            overview += (
+                f'<p><a href="{STATS_SLUG}.html">{STATS_TITLE}</a>: '
+                "the size and documentation of each package.</p>\n"
+            )
+        if risks:
+            overview += (
+                f'<p><a href="{RISKS_SLUG}.html">{RISKS_TITLE}</a>: '
+                "native code, unsafe calls, and reflection for security review.</p>\n"
+            )
+        if third_party:
+            overview += (
+                f'<p><a href="{THIRD_PARTY_SLUG}.html">{THIRD_PARTY_TITLE}</a>: '
+                "the declared dependencies, their licenses, and who imports them.</p>\n"
+            )
+        if platforms:
+            overview += (
+                f'<p><a href="{PLATFORMS_SLUG}.html">{PLATFORMS_TITLE}</a>: '
+                "which packages and symbols exist on Linux, macOS, and Windows.</p>\n"
+            )
+        listed = ""
+        if python_version is not None and python_version.newer():
+            listed += "\n" + self.newer_syntax_body(python_version)
+        if directives:
+            listed += "\n" + self.directives_body(directives)
+        if guides:
+            items = "\n".join(
+                f'<li><a href="{escape(guide.slug, quote=True)}.html">'
+                f"{escape(guide.title)}</a></li>"
+                for guide in guides
+            )
+            listed += f"\n<h2>{GUIDES_HEADING}</h2>\n<ul>\n{items}\n</ul>"
+        return (
+            f"<h1>{escape(self.site.title)}</h1>\n{overview}<ul>\n"
+            + "\n".join(rows)
+            + "\n</ul>"
+            + listed
+        )
+
+    def guide_body(self, guide: Guide, packages: list[PackageDoc]) -> str:
+        """A guide, its shortcodes linked to the symbols of ``packages``."""
+        links = {
+            symbol.qualified_name: f"{package.slug}.html#{symbol.qualified_name}"
+            for package in packages
+            for symbol in package.symbols()
+        }
+        content = expand_shortcodes(guide.content, links)
+        body = render_markdown(content, self.highlighter, self.sanitizer)
+        return f"<h1>{escape(guide.title)}</h1>\n{body}"
+
+    def layout(self, title: str, body: str, path: str | None = None) -> str:
+        """Wrap ``body`` in a themed page; ``path`` gives it a canonical URL."""
+        theme = self.site.theme
+        links = "".join(
+            f'<link rel="stylesheet" href="{escape(href, quote=True)}">\n'
+            for href in self.stylesheets
+        )
+        if path is not None and self.site.base_url:
+            url = canonical_url(self.site.base_url, path)
+            links = f'<link rel="canonical" href="{escape(url, quote=True)}">\n' + links
+        logo = (
+            f'<img class="autodoc-logo" src="{escape(self.assets.logo, quote=True)}" alt="">'
+            if self.assets.logo
+            else ""
+        )
+        color_scheme = "light dark" if theme.mode == "auto" else theme.mode
+        return (
+            "<!DOCTYPE html>\n"
+            f'<html lang="{escape(self.language, quote=True)}">\n'
+            '<head>\n<meta charset="utf-8">\n'
+            '<meta name="viewport" content="width=device-width, initial-scale=1">\n'
+            f'<meta name="color-scheme" content="{color_scheme}">\n'
+            f"<title>{escape(title)}</title>\n"
+            f"{links}"
+            f"{self._hook(theme.extra_head, head=True)}"
+            f"{analytics_head(self.site.analytics)}"
+            "</head>\n<body>\n"
+            f'<a class="autodoc-skip" href="#{SKIP_TARGET}">Skip to content</a>\n'
+            f'<header class="autodoc-header">{logo}'
+            f'<a class="autodoc-title" href="index.html">{escape(self.site.title)}</a>'
+            f"{self._hook(theme.header_html)}</header>\n"
+            f'<main class="autodoc-main" id="{SKIP_TARGET}" tabindex="-1">\n'
+            f"{body}\n</main>\n"
+            f'<footer class="autodoc-footer">{self._hook(theme.footer_html)}</footer>\n'
+            "</body>\n</html>\n"
+        )
+
+    def render(
+        self,
+        packages: list[PackageDoc],
+        architecture: ArchitectureOverview | None = None,
+        dependencies: DependencyGraph | None = None,
+        only: Container[str] | None = None,
+        glossary: list[GlossaryTerm] | None = None,
+        on_page: Callable[[SitePage], None] | None = None,
+        generation: CodeGeneration | None = None,
+        build_constants: list[BuildConstant] | None = None,
+        stats: list[PackageStats] | None = None,
+        guides: list[Guide] | None = None,
+        risks: list[PackageRisks] | None = None,
+        third_party: list[Dependency] | None = None,
+        directives: ProjectDirectives | None = None,
+        python_version: PythonVersionReport | None = None,
+        platforms: list[PackagePlatforms] | None = None,
+    ) -> list[SitePage]:
+        """Render the site; with ``only``, package pages just for those slugs.
+
+        ``on_page`` is called with each package page as soon as it is rendered.
+        """
+        index = self.index_body(
+            packages,
+            architecture,
+            dependencies,
+            glossary,
+            generation,
+            build_constants,
+            stats,
+            guides,
+            risks,
+            third_party,
+            directives,
+            python_version,
+            platforms,
+        )
+        pages = [
+            SitePage("index.html", self.layout(self.site.title, index, "index.html")),
+        ]
+        link = self._linker(packages)
+        for package in packages:
+            if only is not None and package.slug not in only:
+                continue
+            with span("autodoc.render.package", package=package.name, format="html"):
+                body = self.package_body(package, link)
+            page = SitePage(
+                f"{package.slug}.html",
+                self.layout(
+                    f"{package.name} - {self.site.title}",
+                    body,
+                    f"{package.slug}.html",
+                ),
+            )
+            if on_page is not None:
+                on_page(page)
+            pages.append(page)
+        if architecture:
+            pages.append(
+                SitePage(
+                    f"{ARCHITECTURE_SLUG}.html",
+                    self.layout(
+                        f"{ARCHITECTURE_TITLE} - {self.site.title}",
+                        self.architecture_body(architecture, packages),
+                        f"{ARCHITECTURE_SLUG}.html",
+                    ),
+                ),
+            )
+        if dependencies:
+            pages.append(
+                SitePage(
+                    f"{DEPENDENCIES_SLUG}.html",
+                    self.layout(
+                        f"{DEPENDENCIES_TITLE} - {self.site.title}",
+                        self.dependencies_body(dependencies, packages),
+                        f"{DEPENDENCIES_SLUG}.html",
+                    ),
+                ),
+            )
+        if glossary:
+            pages.append(
+                SitePage(
+                    f"{GLOSSARY_SLUG}.html",
+                    self.layout(
+                        f"{GLOSSARY_TITLE} - {self.site.title}",
+                        self.glossary_body(glossary, packages),
+                        f"{GLOSSARY_SLUG}.html",
+                    ),
+                ),
+            )
+        if generation:
+            pages.append(
+                SitePage(
+                    f"{GENERATION_SLUG}.html",
+                    self.layout(
+                        f"{GENERATION_TITLE} - {self.site.title}",
+                        self.generation_body(generation, packages),
+                        f"{GENERATION_SLUG}.html",
+                    ),
+                ),
+            )
+        if build_constants:
+            pages.append(
+                SitePage(
+                    f"{BUILD_SLUG}.html",
+                    self.layout(
+                        f"{BUILD_TITLE} - {self.site.title}",
+                        self.build_body(build_constants),
+                        f"{BUILD_SLUG}.html",
+                    ),
+                ),
+            )
+        if stats:
+            pages.append(
+                SitePage(
+                    f"{STATS_SLUG}.html",
+                    self.layout(
+                        f"{STATS_TITLE} - {self.site.title}",
+                        self.stats_body(stats, packages),
+                        f"{STATS_SLUG}.html",
+                    ),
+                ),
+            )
+        if risks:
+            pages.append(
+                SitePage(
+                    f"{RISKS_SLUG}.html",
+                    self.layout(
+                        f"{RISKS_TITLE} - {self.site.title}",
+                        self.risks_body(risks, packages),
+                        f"{RISKS_SLUG}.html",
+                    ),
+                ),
+            )
+        if third_party:
+            pages.append(
+                SitePage(
+                    f"{THIRD_PARTY_SLUG}.html",
+                    self.layout(
+                        f"{THIRD_PARTY_TITLE} - {self.site.title}",
+                        self.third_party_body(third_party, packages),
+                        f"{THIRD_PARTY_SLUG}.html",
+                    ),
+                ),
+            )
+        if platforms:
+            pages.append(
+                SitePage(
+                    f"{PLATFORMS_SLUG}.html",
+                    self.layout(
+                        f"{PLATFORMS_TITLE} - {self.site.title}",
+                        self.platforms_body(platforms, packages),
+                        f"{PLATFORMS_SLUG}.html",
+                    ),
+                ),
+            )
+        for guide in guides or []:
+            pages.append(
+                SitePage(
+                    f"{guide.slug}.html",
+                    self.layout(
+                        f"{guide.title} - {self.site.title}",
+                        self.guide_body(guide, packages),
+                        f"{guide.slug}.html",
+                    ),
+                ),
+            )
+        # The sitemap lists every package page, rendered this time or not.
+        skipped = [
+            f"{package.slug}.html"
+            for package in packages
+            if only is not None and package.slug not in only
+        ]
+        pages.extend(render_sitemap(self.site, [p.path for p in pages] + skipped))
+        if self.highlighter is not None and self.highlighter.enabled:
+            pages.append(
+                SitePage(HIGHLIGHT_STYLESHEET_PATH, self.highlighter.stylesheet()),
+            )
+        return pages + analytics_pages(self.site.analytics) + self.assets.pages
+
+
+def render_html_site(
+    packages: list[PackageDoc],
+    site: SiteConfig,
+    assets: ThemeAssets,
+    edit_link: EditLinkFn | None = None,
+    highlighter: Highlighter | None = None,
+    architecture: ArchitectureOverview | None = None,
+    dependencies: DependencyGraph | None = None,
+    glossary: list[GlossaryTerm] | None = None,
+    language: str | None = None,
+    external_links: ExternalLinkFn | None = None,
+    generation: CodeGeneration | None = None,
+    build_constants: list[BuildConstant] | None = None,
+    stats: list[PackageStats] | None = None,
+    guides: list[Guide] | None = None,
+    risks: list[PackageRisks] | None = None,
+    third_party: list[Dependency] | None = None,
+    directives: ProjectDirectives | None = None,
+    python_version: PythonVersionReport | None = None,
+    platforms: list[PackagePlatforms] | None = None,
+) -> list[SitePage]:
+    """Render ``packages`` into HTML pages plus the theme's asset files.
+
+    ``edit_link`` maps a symbol to its "edit on the code host" URL;
+    ``highlighter`` enables server-side highlighting of code; non-empty
+    ``architecture``, ``dependencies``, and ``glossary`` add the entry-point,
+    dependency injection, and glossary pages. ``language`` is the pages'
+    ``lang`` (default: ``en``); ``external_links`` links the external types
+    of signatures to their documentation; a non-empty ``generation``
+    inventory and ``build_constants`` add the code generation and build-time
+    configuration pages, non-empty ``stats`` the symbol statistics page,
+    non-empty ``risks`` the risk appendix, non-empty ``third_party`` the
+    dependency appendix, and non-empty ``platforms`` the platform support
+    matrix. ``directives`` and ``python_version`` are stated on the index.
+    Each of ``guides`` adds its page.
+    """
+    renderer = HtmlSiteRenderer(
+        site,
+        assets,
+        edit_link,
+        highlighter,
+        language,
+        external_links,
+    )
+    return renderer.render(
+        packages,
+        architecture,
+        dependencies,
+        glossary=glossary,
+        generation=generation,
+        build_constants=build_constants,
+        stats=stats,
+        guides=guides,
+        risks=risks,
+        third_party=third_party,
+        directives=directives,
+        python_version=python_version,
+        platforms=platforms,
+    )
+
+
+__all__ = [
+    "HtmlSiteRenderer",
+    "SKIP_TARGET",
+    "render_docstring",
+    "render_html_site",
+    "render_markdown",
+]
@@ -0,0 +1,217 @@
+"""JSON rendering of the documentation site model.
+
+Produces an ``index.json`` holding every package, module, class, and
+function with its signature, for tools that consume the documentation rather
+than display it (search indexes, custom front ends, API review bots). The
+document mirrors the Markdown and HTML sites: the architecture overview,
+dependency graph, glossary, code generation inventory, and build-time
+constants are included when the site enables them. With ``site.spans``, a
+compact ``spans.json`` of definition byte ranges is written alongside for
+code browsers (see :mod:`services.symbol_spans`).
+
+Each symbol carries a ``content_hash`` of its signature and docstring (see
+:func:`~services.doc_site.content_hash`), so a consumer can tell which
+symbols changed between two documents without diffing them.
+
+File paths are relative to the source root, which is recorded once as
+``root`` (relative to the repository top level), so the document is the same
+on every machine.
+"""
+
+from __future__ import annotations
+
+import json
+import os
+from pathlib import Path
+from typing import Any
+
+from autodoc.config.project import SiteConfig
+from services.build_constants import BuildConstant
+from services.code_generation import CodeGeneration
+from services.dependency_graph import DependencyGraph
+from services.doc_edit_links import EditLinkFn
+from services.doc_site import ClassDoc, PackageDoc, SitePage, content_hash, signature
+from services.doc_symbols import DocSymbol, relative_path
+from services.entry_points import ArchitectureOverview
+from services.git_source import GitError, repo_root
+from services.glossary import GlossaryTerm
+from services.guides import Guide
+from services.platform_support import PackagePlatforms
+from services.project_directives import ProjectDirectives
+from services.python_features import PythonVersionReport
+from services.schema import stamp_schema
+from services.symbol_spans import SPANS_PATH, SymbolSpan, spans_index
+from services.symbol_stats import PackageStats
+from services.test_docs import SuiteDoc
+from services.third_party import Dependency
+from services.unsafe_usage import PackageRisks
+
+INDEX_PATH = "index.json"
+
+
+def source_root(root: str | Path) -> str:
+    """``root`` relative to its repository's top level (``.`` outside git)."""
+    try:
+        top = repo_root(root)
+    except GitError:
+        return "."
+    return Path(os.path.relpath(Path(root).resolve(), top.resolve())).as_posix()
+
+
+class _JsonSite:
+    def __init__(self, edit_link: EditLinkFn | None, root: str | Path | None) -> None:
+        self.edit_link = edit_link
+        self.root = root
+
+    def symbol(self, symbol: DocSymbol) -> dict[str, Any]:
+        data = symbol.to_dict()
+        if self.root is not None:
+            data["file_path"] = relative_path(symbol.file_path, self.root)
+        data["signature"] = signature(symbol)
+        data["content_hash"] = content_hash(symbol)
+        data["edit_url"] = self.edit_link(symbol) if self.edit_link else None
+        return data
+
+    def cls(self, cls: ClassDoc) -> dict[str, Any]:
+        return {
+            **self.symbol(cls.symbol),
+            "methods": [self.symbol(method) for method in cls.methods],
+            "mocks": list(cls.mocks),
+            "mocked": list(cls.mocked),
+        }
+
+    def package(self, package: PackageDoc) -> dict[str, Any]:
+        return {
+            "name": package.name,
+            "slug": package.slug,
+            "readme": package.readme.content if package.readme else None,
+            "modules": [
+                {
+                    **self.symbol(module.symbol),
+                    "functions": [self.symbol(f) for f in module.functions],
+                    "classes": [self.cls(cls) for cls in module.classes],
+                }
+                for module in package.modules
+            ],
+            "most_used": [
+                {"qualified_name": symbol.qualified_name, "packages": count}
+                for symbol, count in package.most_used
+            ],
+            "assets": [asset.to_dict() for asset in package.assets],
+            "side_effects": [effect.to_dict() for effect in package.side_effects],
+            "docstrings": [
+                {"symbol": name, **parsed.to_dict()}
+                for name, parsed in package.docstrings.items()
+            ],
+            "raises": [
+                error.to_dict()
+                for errors in package.raises.values()
+                for error in errors
+            ],
+            "none_safety": [
+                check.to_dict()
+                for checks in package.none_safety.values()
+                for check in checks
+            ],
+            "pragmas": [
+                pragma.to_dict()
+                for pragmas in package.pragmas.values()
+                for pragma in pragmas
+            ],
+            "inherited": [doc.to_dict() for doc in package.inherited.values()],
+            "placeholders": [
+                {"symbol": name, "summary": text}
+                for name, text in package.placeholders.items()
+            ],
+            "quickstart": (
+                package.quickstart.to_dict() if package.quickstart else None
+            ),
+            "faq": [entry.to_dict() for entry in package.faq],
+        }
+
+
+def _dump(data: dict[str, Any]) -> str:
+    return json.dumps(stamp_schema(data), indent=2, sort_keys=True) + "\n"
+
+
+def render_json_site(
+    packages: list[PackageDoc],
+    site: SiteConfig,
+    edit_link: EditLinkFn | None = None,
+    architecture: ArchitectureOverview | None = None,
+    dependencies: DependencyGraph | None = None,
+    root: str | Path | None = None,
+    glossary: list[GlossaryTerm] | None = None,
+    generation: CodeGeneration | None = None,
+    build_constants: list[BuildConstant] | None = None,
+    spans: list[SymbolSpan] | None = None,
+    stats: list[PackageStats] | None = None,
+    guides: list[Guide] | None = None,
+    risks: list[PackageRisks] | None = None,
+    third_party: list[Dependency] | None = None,
+    directives: ProjectDirectives | None = None,
+    python_version: PythonVersionReport | None = None,
+    platforms: list[PackagePlatforms] | None = None,
+) -> list[SitePage]:
+    """Render ``packages`` into an ``index.json`` page.
+
+    With ``root``, symbol paths are made relative to it and the root itself
+    is recorded (see :func:`source_root`). With ``spans``, the compact
+    ``spans.json`` index for code browsers is written next to it (see
+    :mod:`services.symbol_spans`). ``stats`` fills the package statistics
+    (see :mod:`services.symbol_stats`), ``guides`` the guides with the
+    symbols they link to (see :mod:`services.guides`), ``risks`` the risk
+    appendix (see :mod:`services.unsafe_usage`), ``third_party`` the
+    declared dependencies (see :mod:`services.third_party`),
+    ``directives`` the replaced dependencies and retracted versions (see
+    :mod:`services.project_directives`), ``python_version`` the minimum
+    Python and the syntax newer than it (see :mod:`services.python_features`),
+    and ``platforms`` the platform support matrix (see
+    :mod:`services.platform_support`).
+    """
+    renderer = _JsonSite(edit_link, root)
+    data: dict[str, Any] = {
+        "title": site.title,
+        "root": source_root(root) if root is not None else None,
+        "packages": [renderer.package(package) for package in packages],
+        "architecture": architecture.to_dict() if architecture else None,
+        "dependencies": dependencies.to_dict() if dependencies else None,
+        "glossary": [term.to_dict() for term in glossary] if glossary else None,
+        "generation": generation.to_dict() if generation else None,
+        "build_constants": (
+            [constant.to_dict() for constant in build_constants]
+            if build_constants
+            else None
+        ),
+        "stats": [package.to_dict() for package in stats] if stats else None,
+        "guides": [guide.to_dict() for guide in guides or []],
+        "risks": [package.to_dict() for package in risks] if risks else None,
+        "third_party": (
+            [dependency.to_dict() for dependency in third_party]
+            if third_party
+            else None
+        ),
+        "directives": directives.to_dict() if directives else None,
+        "python_version": python_version.to_dict() if python_version else None,
+        "platforms": (
+            [package.to_dict() for package in platforms] if platforms else None
+        ),
+    }
+    pages = [SitePage(INDEX_PATH, _dump(data))]
+    if spans is not None:
+        index = stamp_schema(spans_index(spans))
+        pages.append(SitePage(SPANS_PATH, json.dumps(index, separators=(",", ":"))))
+    return pages
+
+
+def render_test_suite_json(suite: SuiteDoc) -> list[SitePage]:
+    """Render the test suite as ``index.json``."""
+    return [SitePage(INDEX_PATH, _dump(suite.to_dict()))]
+
+
+__all__ = [
+    "INDEX_PATH",
+    "render_json_site",
+    "render_test_suite_json",
+    "source_root",
+]
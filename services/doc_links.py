@@ -0,0 +1,579 @@
+"""Dead symbol references and URLs in docstrings.
+
+Two checks, reported as :class:`~services.doc_lint.LintFinding` records:
+
+- ``dead-reference``: a Sphinx role (``:func:`render_site```,
+  ``:class:`~services.doc_lint.LintFinding```) or a bracketed name
+  (``[DocSymbol]``, ``[doc_site.summary]``) naming a symbol the tree does not
+  define. :class:`SymbolIndex` resolves names the way a reader would: as
+  written, relative to the enclosing class and module, through the module's
+  imports, or by the tail of a qualified name. References that start outside
+  the tree (``:class:`pathlib.Path```, builtins) cannot be checked and pass.
+- ``dead-link``: an ``http(s)`` URL that does not resolve. :class:`UrlChecker`
+  fetches each URL once per run, keeps results in a :class:`LinkCache` so
+  repeated runs stay fast, and in offline mode only reports what the cache
+  already knows. Placeholder hosts (:data:`PLACEHOLDER_HOSTS`) are never
+  fetched. URLs on private hosts (see :func:`is_private`) are fetched with
+  the host's credentials from a netrc file, and skipped when it has none,
+  since an intranet page without them can only ever answer 401.
+
+Finding symbols are ``"<qualified name>: <target>"``, so each dead
+reference or link is baselined on its own.
+"""
+
+from __future__ import annotations
+
+import builtins
+import json
+import logging
+import netrc
+import os
+import re
+import sys
+import time
+from collections import defaultdict
+from collections.abc import Iterable, Mapping
+from concurrent.futures import ThreadPoolExecutor
+from dataclasses import dataclass
+from fnmatch import fnmatchcase
+from pathlib import Path
+from typing import Any
+from urllib.parse import urlsplit
+
+import httpx
+
+from services.doc_lint import LintFinding
+from services.doc_spelling import prose
+from services.doc_symbols import DocSymbol, is_exported
+from services.import_graph import ImportGraph
+
+logger = logging.getLogger(__name__)
+
+REFERENCE_RULE_ID = "dead-reference"
+URL_RULE_ID = "dead-link"
+
+# Roles naming something AutoDoc models as a symbol.
+SYMBOL_ROLES = frozenset({"func", "class", "meth", "mod", "exc"})
+# Roles naming attributes and constants, which are not symbols: only the
+# owner (``LintFinding`` in ``:attr:`LintFinding.key```) is checked.
+OWNER_ROLES = frozenset({"attr", "data", "const", "obj"})
+
+# Hosts used in examples, never fetched.
+PLACEHOLDER_HOSTS = (
+    "example.com",
+    "example.org",
+    "example.net",
+    "*.example",
+    "*.example.com",
+    "*.invalid",
+    "*.test",
+    "localhost",
+    "127.0.0.1",
+    "0.0.0.0",
+)
+
+# Answers from a server that has the page but will not show it to a robot.
+ALIVE_STATUSES = frozenset({401, 403, 429})
+
+CACHE_VERSION = 1
+
+# Comma-separated patterns of private hosts, added to ``lint.links.private``
+# in the syntax of Go's GOPRIVATE.
+PRIVATE_ENV = "AUTODOC_PRIVATE"
+
+_ROLE = re.compile(
+    r":(?:py:)?(?P<role>[a-z]+):`(?P<target>[^`]+)`",
+)
+_BRACKET = re.compile(r"(?<![\w\]\[`])\[(?P<target>[A-Za-z_][\w.]*)\](?![(\[:])")
+_URL = re.compile(r"https?://[^\s<>`'\"()\[\]{}]+")
+
+_BUILTINS = frozenset(dir(builtins))
+
+
+def _role_target(text: str) -> str:
+    """The target of a role body: ``title <target>``, ``~target``, ``!target``."""
+    text = text.strip()
+    match = re.fullmatch(r".*<(?P<target>[^<>]+)>", text, re.DOTALL)
+    if match:
+        text = match.group("target")
+    return text.lstrip("~!").removesuffix("()")
+
+
+def _is_bracket_reference(target: str) -> bool:
+    """Whether ``[target]`` names a symbol rather than a word (``[optional]``)."""
+    if target.isupper():
+        return False
+    return "." in target or "_" in target or target[0].isupper()
+
+
+@dataclass(frozen=True)
+class SymbolReference:
+    """A reference in a docstring; ``start:end`` spans the target as written."""
+
+    role: str
+    target: str
+    start: int
+    end: int
+
+
+def find_references(docstring: str | None) -> list[SymbolReference]:
+    """The references in ``docstring``: roles first, then bracketed names.
+
+    Bracketed names have the empty role. Roles AutoDoc cannot check
+    (``:ref:``, ``:doc:``) are left out.
+    """
+    text = docstring or ""
+    references = []
+    for match in _ROLE.finditer(text):
+        role = match.group("role")
+        if role in SYMBOL_ROLES or role in OWNER_ROLES:
+            target = _role_target(match.group("target"))
+            start = match.start("target") + match.group("target").rfind(target)
+            references.append(SymbolReference(role, target, start, start + len(target)))
+    for match in _BRACKET.finditer(prose(text)):
+        target = match.group("target")
+        if _is_bracket_reference(target):
+            start, end = match.span("target")
+            references.append(SymbolReference("", target, start, end))
+    return references
+
+
+def symbol_references(docstring: str | None) -> list[tuple[str, str]]:
+    """The ``(role, target)`` references in ``docstring``, in order.
+
+    See :func:`find_references`.
+    """
+    return [(ref.role, ref.target) for ref in find_references(docstring)]
+
+
+def docstring_urls(docstring: str | None) -> list[str]:
+    """The distinct ``http(s)`` URLs in ``docstring``, in order."""
+    urls = []
+    for match in _URL.finditer(docstring or ""):
+        url = match.group().rstrip(".,;:!?")
+        if url not in urls:
+            urls.append(url)
+    return urls
+
+
+def is_placeholder(url: str) -> bool:
+    """Whether ``url`` is an example that should not be fetched."""
+    host = (urlsplit(url).hostname or "").lower()
+    return not host or any(fnmatchcase(host, pattern) for pattern in PLACEHOLDER_HOSTS)
+
+
+class SymbolIndex:
+    """Resolves names in docstrings against the symbols of a tree."""
+
+    def __init__(
+        self,
+        symbols: Iterable[DocSymbol],
+        graph: ImportGraph | None = None,
+    ) -> None:
+        self.graph = graph
+        self.names: set[str] = set()
+        self._tails: dict[str, set[str]] = defaultdict(set)
+        for name in [s.qualified_name for s in symbols]:
+            self._add(name)
+        for module in (graph.modules if graph is not None else {}):
+            self._add(module)
+        self.roots = {name.split(".", 1)[0] for name in self.names}
+
+    def _add(self, name: str) -> None:
+        self.names.add(name)
+        parts = name.split(".")
+        for start in range(len(parts)):
+            self._tails[".".join(parts[start:])].add(name)
+
+    def _known(self, name: str) -> bool:
+        return name in self.names or name in self._tails
+
+    def _expand(self, target: str, context: DocSymbol) -> str:
+        """``target`` with its first part replaced by what the module imports."""
+        if self.graph is None:
+            return target
+        module = self.graph.internal_module(context.qualified_name)
+        info = self.graph.modules.get(module) if module else None
+        head, _, rest = target.partition(".")
+        if info is None or head not in info.aliases:
+            return target
+        expanded = info.aliases[head]
+        return f"{expanded}.{rest}" if rest else expanded
+
+    def _resolves(self, target: str, context: DocSymbol) -> bool:
+        target = target.lstrip(".")
+        scopes = context.qualified_name.split(".")
+        for end in range(len(scopes), 0, -1):
+            if ".".join([*scopes[:end], target]) in self.names:
+                return True
+        return self._known(target) or self._known(self._expand(target, context))
+
+    def resolve(self, target: str, context: DocSymbol) -> str | None:
+        """The qualified name ``target`` stands for in ``context``, if just one.
+
+        Tried in order: relative to the enclosing scopes, as written, through
+        the module's imports, and as the tail of exactly one qualified name.
+        """
+        target = target.lstrip(".")
+        scopes = context.qualified_name.split(".")
+        for end in range(len(scopes), 0, -1):
+            name = ".".join([*scopes[:end], target])
+            if name in self.names:
+                return name
+        for candidate in dict.fromkeys([target, self._expand(target, context)]):
+            if candidate in self.names:
+                return candidate
+            matches = self._tails.get(candidate, set())
+            if len(matches) == 1:
+                return next(iter(matches))
+        return None
+
+    def _is_internal(self, target: str, context: DocSymbol) -> bool:
+        """Whether ``target`` points into the tree, so it must resolve."""
+        head = target.lstrip(".").split(".", 1)[0]
+        expanded = self._expand(target.lstrip("."), context)
+        if expanded != target.lstrip("."):
+            return expanded.split(".", 1)[0] in self.roots
+        if "." not in target:
+            return head not in _BUILTINS and head not in sys.stdlib_module_names
+        return head in self.roots or head in self._tails
+
+    def resolves(self, role: str, target: str, context: DocSymbol) -> bool:
+        """Whether the reference ``target`` of ``context`` names a real symbol."""
+        if role in OWNER_ROLES:
+            if "." not in target:
+                return True
+            target = target.rsplit(".", 1)[0]
+        if self._resolves(target, context):
+            return True
+        return not self._is_internal(target, context)
+
+
+@dataclass(frozen=True)
+class LinkStatus:
+    """The result of fetching a URL."""
+
+    url: str
+    ok: bool
+    detail: str
+    checked: float
+
+    def to_dict(self) -> dict[str, Any]:
+        return {"ok": self.ok, "detail": self.detail, "checked": self.checked}
+
+    @classmethod
+    def from_dict(cls, url: str, data: dict[str, Any]) -> LinkStatus:
+        return cls(url, bool(data["ok"]), str(data["detail"]), float(data["checked"]))
+
+
+class LinkCache:
+    """URL results kept between runs in a JSON file."""
+
+    def __init__(
+        self,
+        path: str | Path | None = None,
+        entries: Mapping[str, LinkStatus] | None = None,
+    ) -> None:
+        self.path = Path(path) if path is not None else None
+        self.entries = dict(entries or {})
+
+    @classmethod
+    def load(cls, path: str | Path) -> LinkCache:
+        """The cache at ``path``; empty when it is missing or unreadable."""
+        target = Path(path)
+        if not target.is_file():
+            return cls(target)
+        try:
+            data = json.loads(target.read_text(encoding="utf-8"))
+            if data.get("version") != CACHE_VERSION:
+                logger.info("Ignoring %s: written by another version", target)
+                return cls(target)
+            entries = {
+                url: LinkStatus.from_dict(url, entry)
+                for url, entry in data["links"].items()
+            }
+        except (OSError, ValueError, KeyError, TypeError, AttributeError) as exc:
+            logger.warning("Ignoring unreadable link cache %s: %s", target, exc)
+            return cls(target)
+        return cls(target, entries)
+
+    def get(self, url: str, max_age: float | None = None) -> LinkStatus | None:
+        """The cached result for ``url``, unless older than ``max_age`` seconds."""
+        status = self.entries.get(url)
+        if status is None:
+            return None
+        if max_age is not None and time.time() - status.checked > max_age:
+            return None
+        return status
+
+    def put(self, status: LinkStatus) -> None:
+        self.entries[status.url] = status
+
+    def save(self) -> None:
+        """Write the cache back to its file, if it has one."""
+        if self.path is None:
+            return
+        data = {
+            "version": CACHE_VERSION,
+            "links": {url: self.entries[url].to_dict() for url in sorted(self.entries)},
+        }
+        try:
+            self.path.parent.mkdir(parents=True, exist_ok=True)
+            self.path.write_text(json.dumps(data, indent=2) + "\n", encoding="utf-8")
+        except OSError as exc:
+            logger.warning("Cannot write link cache %s: %s", self.path, exc)
+
+
+def private_patterns(
+    configured: Iterable[str] = (),
+    environ: Mapping[str, str] | None = None,
+) -> list[str]:
+    """``configured`` private host patterns plus those of :data:`PRIVATE_ENV`."""
+    value = (os.environ if environ is None else environ).get(PRIVATE_ENV, "")
+    extra = [pattern.strip() for pattern in value.split(",") if pattern.strip()]
+    return list(dict.fromkeys([*configured, *extra]))
+
+
+def is_private(url: str, patterns: Iterable[str]) -> bool:
+    """Whether ``url`` is on a private host.
+
+    As with GOPRIVATE, a pattern is a glob matched against the host and as
+    many leading path elements as the pattern has: ``*.corp.internal``
+    matches every host of that domain, ``git.acme.io/platform`` only that
+    group's pages.
+    """
+    parts = urlsplit(url)
+    elements = [(parts.hostname or "").lower(), *parts.path.strip("/").split("/")]
+    for pattern in patterns:
+        wanted = pattern.lower().strip("/").split("/")
+        if len(elements) >= len(wanted) and all(
+            fnmatchcase(element, glob)
+            for element, glob in zip(elements, wanted, strict=False)
+        ):
+            return True
+    return False
+
+
+class NetrcCredentials:
+    """Logins for private hosts, read from a netrc file.
+
+    The file is ``path``, else ``$NETRC``, else ``~/.netrc``, as for curl and
+    pip. A missing or malformed file holds no logins.
+    """
+
+    def __init__(self, path: str | Path | None = None) -> None:
+        default = os.environ.get("NETRC") or Path.home() / ".netrc"
+        self.path = Path(path or default)
+        self._hosts: dict[str, tuple[str, str]] = {}
+        if not self.path.is_file():
+            return
+        try:
+            parsed = netrc.netrc(str(self.path))
+        except (OSError, netrc.NetrcParseError) as exc:
+            logger.warning("Ignoring unreadable netrc file %s: %s", self.path, exc)
+            return
+        for host, (login, account, password) in parsed.hosts.items():
+            self._hosts[host.lower()] = (login or account or "", password or "")
+
+    def login(self, host: str) -> tuple[str, str] | None:
+        """The ``(login, password)`` for ``host``, if the file has one."""
+        return self._hosts.get(host.lower())
+
+
+def _alive(status: int, private: bool = False) -> bool:
+    # A private host that still refuses our credentials has not served the page.
+    return status < 400 or (status in ALIVE_STATUSES and not private)
+
+
+class UrlChecker:
+    """Fetches URLs, through a :class:`LinkCache`, to see whether they resolve.
+
+    Offline, nothing is fetched: only cached results, however old, are
+    returned. URLs matching a ``private`` pattern are fetched with their
+    host's login from ``credentials`` and are not checked without one.
+    Credentials are only sent to the host they belong to.
+    """
+
+    def __init__(
+        self,
+        client: httpx.Client | None = None,
+        *,
+        timeout: float = 10.0,
+        cache: LinkCache | None = None,
+        max_age: float = 24 * 3600,
+        offline: bool = False,
+        workers: int = 8,
+        private: Iterable[str] = (),
+        credentials: NetrcCredentials | None = None,
+    ) -> None:
+        self._client = client
+        self.timeout = timeout
+        self.cache = cache or LinkCache()
+        self.max_age = max_age
+        self.offline = offline
+        self.workers = workers
+        self.private = tuple(private)
+        self.credentials = credentials
+
+    def _login(self, url: str) -> tuple[str, str] | None:
+        if self.credentials is None:
+            return None
+        return self.credentials.login(urlsplit(url).hostname or "")
+
+    def _http(self) -> httpx.Client:
+        if self._client is None:
+            self._client = httpx.Client(
+                follow_redirects=True,
+                timeout=self.timeout,
+                headers={"User-Agent": "autodoc-link-check"},
+            )
+        return self._client
+
+    def fetch(self, url: str) -> LinkStatus:
+        """Fetch ``url``: ``HEAD`` first, then ``GET`` when that is refused."""
+        client = self._http()
+        private = is_private(url, self.private)
+        login = self._login(url) if private else None
+        # httpx drops the header when a redirect leaves the host.
+        auth = {"auth": httpx.BasicAuth(*login)} if login else {}
+        try:
+            response = client.head(url, **auth)
+            if not _alive(response.status_code, private):
+                response = client.get(url, **auth)
+        except httpx.HTTPError as exc:
+            detail = str(exc) or type(exc).__name__
+            return LinkStatus(url, False, detail, time.time())
+        status = response.status_code
+        detail = f"HTTP {status}"
+        if login and status in (401, 403):
+            detail += f" with the login for {urlsplit(url).hostname} from netrc"
+        return LinkStatus(url, _alive(status, private), detail, time.time())
+
+    def check(self, urls: Iterable[str]) -> dict[str, LinkStatus]:
+        """The status of each of ``urls`` that is cached or could be fetched."""
+        results: dict[str, LinkStatus] = {}
+        pending = []
+        for url in dict.fromkeys(urls):
+            cached = self.cache.get(url, None if self.offline else self.max_age)
+            if cached is not None:
+                results[url] = cached
+            elif self.offline:
+                continue
+            elif is_private(url, self.private) and self._login(url) is None:
+                logger.debug("Not checking %s: no netrc login for its host", url)
+            else:
+                pending.append(url)
+        if pending:
+            logger.info("Checking %d URL(s)", len(pending))
+            with ThreadPoolExecutor(max_workers=self.workers) as pool:
+                for status in pool.map(self.fetch, pending):
+                    results[status.url] = status
+                    self.cache.put(status)
+            self.cache.save()
+        return results
+
+    def close(self) -> None:
+        """Close the HTTP client, if one was opened."""
+        if self._client is not None:
+            self._client.close()
+
+
+def check_links(
+    symbols: Iterable[DocSymbol],
+    index: SymbolIndex | None = None,
+    checker: UrlChecker | None = None,
+    ignore: Iterable[str] = (),
+) -> list[LintFinding]:
+    """Report dead references and URLs in the docstrings of ``symbols``.
+
+    References are checked against ``index`` and URLs with ``checker``;
+    either check is skipped when its argument is None. URLs matching an
+    ``ignore`` pattern are not fetched.
+
+    Returns:
+        Findings sorted by file, line, and rule
+    """
+    symbols = [s for s in symbols if is_exported(s) and s.is_documented]
+    ignore = tuple(ignore)
+    findings: dict[tuple[str, str], LintFinding] = {}
+
+    def report(rule: str, symbol: DocSymbol, target: str, message: str, level: str):
+        key = (rule, f"{symbol.qualified_name}: {target}")
+        findings.setdefault(
+            key,
+            LintFinding(
+                rule=rule,
+                message=message,
+                file_path=symbol.file_path,
+                lineno=symbol.lineno,
+                symbol=key[1],
+                severity=level,
+            ),
+        )
+
+    if index is not None:
+        for symbol in symbols:
+            for role, target in symbol_references(symbol.docstring):
+                if not index.resolves(role, target, symbol):
+                    report(
+                        REFERENCE_RULE_ID,
+                        symbol,
+                        target,
+                        f"{symbol.kind} {symbol.qualified_name} references "
+                        f"{target}, which is not defined",
+                        "error",
+                    )
+
+    if checker is not None:
+        links = {
+            symbol.qualified_name: [
+                url
+                for url in docstring_urls(symbol.docstring)
+                if not is_placeholder(url)
+                and not any(fnmatchcase(url, pattern) for pattern in ignore)
+            ]
+            for symbol in symbols
+        }
+        statuses = checker.check(url for urls in links.values() for url in urls)
+        for symbol in symbols:
+            for url in links[symbol.qualified_name]:
+                status = statuses.get(url)
+                if status is not None and not status.ok:
+                    report(
+                        URL_RULE_ID,
+                        symbol,
+                        url,
+                        f"{symbol.kind} {symbol.qualified_name} links to {url}: "
+                        f"{status.detail}",
+                        "warning",
+                    )
+
+    return sorted(
+        findings.values(),
+        key=lambda f: (f.file_path, f.lineno, f.rule, f.symbol),
+    )
+
+
+__all__ = [
+    "ALIVE_STATUSES",
+    "CACHE_VERSION",
+    "OWNER_ROLES",
+    "PLACEHOLDER_HOSTS",
+    "PRIVATE_ENV",
+    "REFERENCE_RULE_ID",
+    "SYMBOL_ROLES",
+    "URL_RULE_ID",
+    "LinkCache",
+    "LinkStatus",
+    "NetrcCredentials",
+    "SymbolIndex",
+    "SymbolReference",
+    "UrlChecker",
+    "check_links",
+    "docstring_urls",
+    "find_references",
+    "is_placeholder",
+    "is_private",
+    "private_patterns",
+    "symbol_references",
+]
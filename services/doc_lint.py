@@ -0,0 +1,111 @@
+"""Documentation lint rules over the doc symbol model.
+
+Rules inspect one :class:`~services.doc_symbols.DocSymbol` at a time and yield
+:class:`LintFinding` records. Findings are identified by their rule and symbol
+(not by line number), so the same problem is recognised across revisions even
+when surrounding code moves.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Iterable, Sequence
+from dataclasses import asdict, dataclass
+from typing import Any, Protocol
+
+from services.doc_symbols import DocSymbol, is_exported
+
+
+@dataclass(frozen=True)
+class LintFinding:
+    """A single documentation problem reported by a lint rule."""
+
+    rule: str
+    message: str
+    file_path: str
+    lineno: int
+    symbol: str
+    severity: str = "error"
+    # Replacement text for findings that come with a suggested fix.
+    suggestion: str | None = None
+
+    @property
+    def key(self) -> tuple[str, str]:
+        """Position-independent identity of the finding."""
+        return (self.rule, self.symbol)
+
+    def format(self) -> str:
+        """Render the finding in ``path:line: [rule] message`` form."""
+        return f"{self.file_path}:{self.lineno}: [{self.rule}] {self.message}"
+
+    def to_dict(self) -> dict[str, Any]:
+        return asdict(self)
+
+
+class LintRule(Protocol):
+    """Interface implemented by every documentation lint rule."""
+
+    id: str
+    description: str
+
+    def check(self, symbol: DocSymbol) -> Iterable[LintFinding]:
+        """Yield findings for ``symbol``."""
+        ...
+
+
+class MissingDocstringRule:
+    """Exported symbols must carry a docstring."""
+
+    id = "missing-docstring"
+    description = "Exported modules, classes, functions, and methods need a docstring"
+
+    def check(self, symbol: DocSymbol) -> Iterable[LintFinding]:
+        if not is_exported(symbol) or symbol.is_documented:
+            return
+        yield LintFinding(
+            rule=self.id,
+            message=f"exported {symbol.kind} {symbol.qualified_name} has no docstring",
+            file_path=symbol.file_path,
+            lineno=symbol.lineno,
+            symbol=symbol.qualified_name,
+        )
+
+
+DEFAULT_RULES: tuple[LintRule, ...] = (MissingDocstringRule(),)
+
+
+def lint_symbols(
+    symbols: Iterable[DocSymbol],
+    rules: Sequence[LintRule] | None = None,
+) -> list[LintFinding]:
+    """Run ``rules`` (default: :data:`DEFAULT_RULES`) over ``symbols``.
+
+    Returns:
+        Findings sorted by file, line, and rule
+    """
+    active_rules = DEFAULT_RULES if rules is None else rules
+    findings = [
+        finding
+        for symbol in symbols
+        for rule in active_rules
+        for finding in rule.check(symbol)
+    ]
+    return sorted(findings, key=lambda f: (f.file_path, f.lineno, f.rule, f.symbol))
+
+
+def new_findings(
+    current: Iterable[LintFinding],
+    previous: Iterable[LintFinding],
+) -> list[LintFinding]:
+    """Return findings in ``current`` that were not already in ``previous``."""
+    known = {finding.key for finding in previous}
+    return [finding for finding in current if finding.key not in known]
+
+
+__all__ = [
+    "DEFAULT_RULES",
+    "LintFinding",
+    "LintRule",
+    "MissingDocstringRule",
+    "lint_symbols",
+    "new_findings",
+]
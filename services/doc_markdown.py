@@ -0,0 +1,1047 @@
+"""Markdown rendering of the documentation site model.
+
+Produces ``index.md`` plus one page per package, suitable for committing next
+to the code or publishing through the Confluence pipeline. Each package page
+opens with a nested table of contents, and ``index.md`` links every package
+and module, so the output is navigable in plain Git hosting views.
+
+Anchors follow GitHub's heading-slug rules (see :func:`heading_slug`), which
+GitLab and Bitbucket also accept, so no raw HTML anchors are needed.
+
+When an :class:`~services.entry_points.ArchitectureOverview` is passed, an
+``architecture.md`` page describes how the application boots; a
+:class:`~services.dependency_graph.DependencyGraph` adds ``dependencies.md``
+with a Mermaid diagram of what is injected where, a glossary adds
+``glossary.md``, and a :class:`~services.code_generation.CodeGeneration`
+inventory adds ``generation.md``, and build-time constants add ``build.md``.
+Package statistics add ``stats.md``, risky code ``risks.md``, the
+third-party dependencies ``third-party.md``, and the platform support
+matrix ``platforms.md``. Replaced dependency sources and
+retracted releases are listed on the index itself, which opens with a
+warning when the documented version is retracted, and so is the minimum
+Python version, with the syntax that needs a newer one.
+"""
+
+from __future__ import annotations
+
+import re
+from collections import Counter
+from collections.abc import Callable, Container
+from dataclasses import dataclass
+
+from autodoc.config.project import SiteConfig
+from services.build_constants import BuildConstant
+from services.code_generation import CodeGeneration
+from services.doc_edit_links import EditLinkFn
+from services.doc_external_links import ExternalLinkFn
+from services.doc_site import (
+    ARCHITECTURE_SLUG,
+    ARCHITECTURE_TITLE,
+    ASSETS_HEADING,
+    BUILD_SLUG,
+    BUILD_TITLE,
+    DEPENDENCIES_SLUG,
+    DEPENDENCIES_TITLE,
+    FUZZ_TARGETS_HEADING,
+    GENERATION_SLUG,
+    GENERATION_TITLE,
+    GLOSSARY_SLUG,
+    GLOSSARY_TITLE,
+    GUIDES_HEADING,
+    NEWER_SYNTAX_HEADING,
+    PLATFORMS_SLUG,
+    PLATFORMS_TITLE,
+    REPLACEMENTS_HEADING,
+    RETRACTIONS_HEADING,
+    RISKS_SLUG,
+    RISKS_TITLE,
+    SIDE_EFFECTS_HEADING,
+    STATS_SLUG,
+    STATS_TITLE,
+    TEST_SUITE_TITLE,
+    THIRD_PARTY_SLUG,
+    THIRD_PARTY_TITLE,
+    ClassDoc,
+    PackageDoc,
+    SitePage,
+    signature,
+    summary,
+)
+from services.dependency_graph import DependencyGraph
+from services.doc_symbols import DocSymbol
+from services.docstring_sections import SECTION_KINDS, DocField
+from services.entry_points import ENTRY_POINT_KINDS, ArchitectureOverview
+from services.glossary import GlossaryTerm
+from services.guides import Guide, expand_shortcodes
+from services.faq import FAQ_KINDS
+from services.package_readme import demote_headings, readme_headings
+from services.platform_support import PLATFORM_TITLES, PLATFORMS, PackagePlatforms
+from services.project_directives import ProjectDirectives
+from services.python_features import PythonVersionReport, version_label
+from services.symbol_stats import STATS_HEADINGS, STATS_KINDS, PackageStats
+from services.telemetry import span
+from services.test_docs import UNGROUPED, SuiteDoc
+from services.third_party import Dependency
+from services.unsafe_usage import RISK_KINDS, PackageRisks
+
+CONTENTS_HEADING = "Contents"
+MOST_USED_HEADING = "Most used"
+QUICKSTART_HEADING = "Quickstart"
+
+_SLUG_STRIP = re.compile(r"[^\w\- ]")
+
+
+def heading_slug(text: str, seen: Counter[str] | None = None) -> str:
+    """Return the anchor a Git host generates for a heading with ``text``.
+
+    Lowercases, drops punctuation (including backticks and dots), and turns
+    spaces into hyphens. When ``seen`` is given, repeated slugs get ``-1``,
+    ``-2``, ... suffixes the way GitHub disambiguates duplicate headings.
+    """
+    slug = _SLUG_STRIP.sub("", text.strip().lower()).replace(" ", "-")
+    if seen is None:
+        return slug
+    count = seen[slug]
+    seen[slug] += 1
+    return f"{slug}-{count}" if count else slug
+
+
+@dataclass(frozen=True)
+class _Heading:
+    level: int
+    text: str
+    anchor: str
+    symbol: DocSymbol
+
+
+def _headings(package: PackageDoc) -> list[_Heading]:
+    """Headings of a package page in page order, with their anchors."""
+    seen: Counter[str] = Counter()
+    heading_slug(package.name, seen)
+    if package.readme is not None:
+        for _, text in readme_headings(package.readme.content):
+            heading_slug(text, seen)
+    if package.quickstart is not None:
+        heading_slug(QUICKSTART_HEADING, seen)
+    if package.most_used:
+        heading_slug(MOST_USED_HEADING, seen)
+    heading_slug(CONTENTS_HEADING, seen)
+
+    headings = []
+
+    def add(level: int, text: str, symbol: DocSymbol) -> None:
+        headings.append(_Heading(level, text, heading_slug(text, seen), symbol))
+
+    def title(symbol: DocSymbol) -> str:
+        # Namesakes are always shown with their full path.
+        if symbol.qualified_name in package.namesakes:
+            return f"`{symbol.qualified_name}`"
+        return f"`{symbol.name}`"
+
+    for module in package.modules:
+        add(2, f"`{module.name}`", module.symbol)
+        for func in module.functions:
+            add(3, title(func), func)
+        for cls in module.classes:
+            add(3, title(cls.symbol), cls.symbol)
+            for method in cls.methods:
+                add(4, f"`{cls.symbol.name}.{method.name}`", method)
+    return headings
+
+
+def package_anchors(package: PackageDoc) -> dict[str, str]:
+    """Map each symbol's qualified name to its anchor on the package page."""
+    return {h.symbol.qualified_name: h.anchor for h in _headings(package)}
+
+
+def _docstring(docstring: str | None) -> str:
+    if not docstring or not docstring.strip():
+        return "_No documentation._"
+    return docstring.strip()
+
+
+def _field(field: DocField) -> str:
+    parts = [f"`{field.name}`"] if field.name else []
+    if field.type:
+        parts.append(f"(`{field.type}`)" if field.name else f"`{field.type}`")
+    if field.description:
+        parts.append(f"- {field.description}" if parts else field.description)
+    return "- " + " ".join(parts)
+
+
+def _docstring_chunks(package: PackageDoc, symbol: DocSymbol) -> list[str]:
+    placeholder = package.placeholders.get(symbol.qualified_name)
+    if placeholder is not None:
+        return [f"_Auto-generated summary:_ {placeholder}"]
+    parsed = package.docstrings.get(symbol.qualified_name)
+    if parsed is None:
+        return [_docstring(symbol.docstring)]
+    chunks = [parsed.description] if parsed.description else []
+    for kind in SECTION_KINDS:
+        fields = parsed.section(kind)
+        if fields:
+            lines = [f"**{kind.capitalize()}**", "", *map(_field, fields)]
+            chunks.append("\n".join(lines))
+    return chunks
+
+
+def _inherited_line(
+    package: PackageDoc,
+    symbol: DocSymbol,
+    links: dict[str, str],
+) -> list[str]:
+    inherited = package.inherited.get(symbol.qualified_name)
+    if inherited is None:
+        return []
+    return [f"_Inherited from {_symbol_link(inherited.source, links)}._"]
+
+
+def _edit_line(symbol: DocSymbol, edit_link: EditLinkFn | None) -> list[str]:
+    url = edit_link(symbol) if edit_link else None
+    return [f"[Edit this doc comment]({url})"] if url else []
+
+
+def _external_line(
+    symbol: DocSymbol,
+    external_links: ExternalLinkFn | None,
+) -> list[str]:
+    links = external_links(symbol) if external_links else {}
+    if not links:
+        return []
+    names = ", ".join(f"[`{name}`]({url})" for name, url in links.items())
+    return [f"External types: {names}"]
+
+
+def _block(*chunks: str) -> str:
+    return "\n\n".join(chunks) + "\n"
+
+
+def _toc(headings: list[_Heading]) -> str:
+    lines = [
+        f"{'  ' * (h.level - 2)}- [{h.text}](#{h.anchor})" for h in headings
+    ]
+    return _block(f"## {CONTENTS_HEADING}", "\n".join(lines))
+
+
+def _most_used(package: PackageDoc, anchors: dict[str, str]) -> str:
+    lines = []
+    for symbol, count in package.most_used:
+        noun = "package" if count == 1 else "packages"
+        lines.append(
+            f"1. [`{symbol.qualified_name}`](#{anchors[symbol.qualified_name]})"
+            f" - used by {count} {noun}",
+        )
+    return _block(f"## {MOST_USED_HEADING}", "\n".join(lines))
+
+
+def _symbol_link(name: str, links: dict[str, str]) -> str:
+    return f"[`{name}`]({links[name]})" if name in links else f"`{name}`"
+
+
+def _mock_lines(cls: ClassDoc | None, links: dict[str, str]) -> list[str]:
+    if cls is None:
+        return []
+    lines = []
+    if cls.mocks:
+        mocks = ", ".join(_symbol_link(m, links) for m in cls.mocks)
+        lines.append(f"Mocked by {mocks}")
+    if cls.mocked:
+        lines.append("Mocks " + ", ".join(_symbol_link(i, links) for i in cls.mocked))
+    return lines
+
+
+def _namesake_lines(
+    package: PackageDoc,
+    symbol: DocSymbol,
+    links: dict[str, str],
+) -> list[str]:
+    others = package.namesakes.get(symbol.qualified_name)
+    if not others:
+        return []
+    lines = [f"Not to be confused with the other `{symbol.name}`:"]
+    for other in others:
+        text = summary(other.docstring)
+        entry = f"- {_symbol_link(other.qualified_name, links)}"
+        lines.append(f"{entry} - {text}" if text else entry)
+    return ["\n".join(lines)]
+
+
+def _pragma_lines(package: PackageDoc, symbol: DocSymbol) -> list[str]:
+    pragmas = package.pragmas.get(symbol.qualified_name)
+    if not pragmas:
+        return []
+    texts = [
+        f"`{p.text}`" + (" (private access)" if p.private_access else "")
+        for p in pragmas
+    ]
+    return ["Pragmas: " + ", ".join(texts)]
+
+
+def _none_safety_lines(package: PackageDoc, symbol: DocSymbol) -> list[str]:
+    checks = package.none_safety.get(symbol.qualified_name, [])
+    lines = []
+    for safe, label in ((True, "None-safe"), (False, "Not None-safe")):
+        names = [f"`{c.parameter}`" for c in checks if c.safe == safe]
+        if names:
+            lines.append(f"{label}: {', '.join(names)}")
+    return ["\n".join(lines)] if lines else []
+
+
+def _raises_lines(package: PackageDoc, symbol: DocSymbol) -> list[str]:
+    errors = package.raises.get(symbol.qualified_name)
+    if not errors:
+        return []
+    return ["Raises: " + ", ".join(e.phrase(lambda name: f"`{name}`") for e in errors)]
+
+
+def _assets(package: PackageDoc) -> str:
+    items = []
+    for asset in package.assets:
+        item = f"- `{asset.qualified_name}` loads `{asset.pattern}`"
+        if not asset.files:
+            items.append(f"{item} (no matching file)")
+            continue
+        if len(asset.files) > 1 or asset.files[0] != asset.pattern:
+            item += "".join(f"\n  - `{path}`" for path in asset.files)
+        items.append(item)
+    return _block(f"## {ASSETS_HEADING}", "\n".join(items))
+
+
+def _side_effects(package: PackageDoc) -> str:
+    items = []
+    module = None
+    for effect in package.side_effects:
+        if effect.module != module:
+            module = effect.module
+            items.append(f"- `{module}`")
+        text = effect.sentence(lambda name: f"`{name}`")
+        items.append(f"  - {text} (line {effect.lineno})")
+    return _block(
+        f"## {SIDE_EFFECTS_HEADING}",
+        "Importing these modules also:",
+        "\n".join(items),
+    )
+
+
+def _faq(package: PackageDoc, anchors: dict[str, str]) -> list[str]:
+    """One section per kind of entry, each question a heading."""
+    sections = []
+    for kind, title in FAQ_KINDS.items():
+        chunks = [f"## {title}"]
+        for entry in package.faq:
+            if entry.kind != kind:
+                continue
+            chunks += [f"### {entry.question}", demote_headings(entry.answer, 4)]
+            if entry.symbol in anchors:
+                chunks.append(f"See [`{entry.symbol}`](#{anchors[entry.symbol]}).")
+        if len(chunks) > 1:
+            sections.append(_block(*chunks))
+    return sections
+
+
+def _entry(
+    package: PackageDoc,
+    heading: _Heading,
+    classes: dict[str, ClassDoc],
+    edit_link: EditLinkFn | None,
+    links: dict[str, str],
+    external_links: ExternalLinkFn | None,
+) -> str:
+    """The section of one symbol of a package page."""
+    symbol = heading.symbol
+    chunks = [f"{'#' * heading.level} {heading.text}"]
+    if symbol.kind != "module":
+        chunks.append(f"```python\n{signature(symbol)}\n```")
+        chunks.extend(_external_line(symbol, external_links))
+    chunks.extend(_inherited_line(package, symbol, links))
+    chunks.extend(_docstring_chunks(package, symbol))
+    chunks.extend(_mock_lines(classes.get(symbol.qualified_name), links))
+    chunks.extend(_namesake_lines(package, symbol, links))
+    chunks.extend(_raises_lines(package, symbol))
+    chunks.extend(_none_safety_lines(package, symbol))
+    chunks.extend(_pragma_lines(package, symbol))
+    chunks.extend(_edit_line(symbol, edit_link))
+    return _block(*chunks)
+
+
+def render_package_markdown(
+    package: PackageDoc,
+    edit_link: EditLinkFn | None = None,
+    links: dict[str, str] | None = None,
+    external_links: ExternalLinkFn | None = None,
+) -> str:
+    """Render one package page with a table of contents.
+
+    ``links`` (see :func:`site_links`) lets references to symbols on other
+    pages, such as mocks of an interface, link to them; ``external_links``
+    links the external types of signatures.
+    """
+    links = links or {}
+    classes = {
+        cls.symbol.qualified_name: cls
+        for module in package.modules
+        for cls in module.classes
+    }
+    headings = _headings(package)
+    parts = [f"# {package.name}\n"]
+    if package.readme is not None and package.readme.content.strip():
+        parts.append(_block(demote_headings(package.readme.content).strip()))
+    if package.quickstart is not None:
+        code = package.quickstart.code.rstrip()
+        parts.append(_block(f"## {QUICKSTART_HEADING}", f"```python\n{code}\n```"))
+    if package.most_used:
+        anchors = {h.symbol.qualified_name: h.anchor for h in headings}
+        parts.append(_most_used(package, anchors))
+    if headings:
+        parts.append(_toc(headings))
+    for heading in headings:
+        parts.append(
+            _entry(package, heading, classes, edit_link, links, external_links),
+        )
+    if package.assets:
+        # Last on the page, so the anchors of the symbols do not change.
+        parts.append(_assets(package))
+    if package.side_effects:
+        parts.append(_side_effects(package))
+    if package.faq:
+        anchors = {h.symbol.qualified_name: h.anchor for h in headings}
+        parts.extend(_faq(package, anchors))
+    return "\n".join(parts)
+
+
+def render_symbol_markdown(
+    package: PackageDoc,
+    qualified_name: str,
+    links: dict[str, str] | None = None,
+) -> str | None:
+    """The section of one symbol, as its package page renders it.
+
+    None if the page has no entry for ``qualified_name``.
+    """
+    heading = next(
+        (h for h in _headings(package) if h.symbol.qualified_name == qualified_name),
+        None,
+    )
+    if heading is None:
+        return None
+    classes = {
+        cls.symbol.qualified_name: cls
+        for module in package.modules
+        for cls in module.classes
+    }
+    return _entry(package, heading, classes, None, links or {}, None)
+
+
+def render_architecture_markdown(
+    overview: ArchitectureOverview,
+    links: dict[str, str] | None = None,
+) -> str:
+    """Render the entry-point overview page.
+
+    ``links`` maps qualified names to their place in the site, so entry points
+    and wiring targets link to their documentation.
+    """
+    links = links or {}
+    parts = [
+        f"# {ARCHITECTURE_TITLE}\n",
+        "How the application starts and how its application objects are wired.\n",
+    ]
+    if overview.entry_points:
+        rows = ["| Kind | Name | Runs | Location |", "| --- | --- | --- | --- |"]
+        for entry in overview.entry_points:
+            runs = ", ".join(_symbol_link(name, links) for name in entry.runs)
+            rows.append(
+                f"| {ENTRY_POINT_KINDS[entry.kind]} | `{entry.name}` "
+                f"| {runs or '-'} | `{entry.location}` |",
+            )
+        parts.append(_block("## Entry points", "\n".join(rows)))
+    if overview.apps:
+        parts.append("## Application wiring\n")
+    for app in overview.apps:
+        intro = f"{app.framework} application created at `{app.file_path}:{app.lineno}`"
+        if app.factory:
+            intro += f" and returned by {_symbol_link(app.factory, links)}"
+        chunks = [f"### `{app.name}`", intro + "."]
+        steps = []
+        for step in app.steps:
+            detail = f" ({step.detail})" if step.detail else ""
+            steps.append(
+                f"1. {step.kind.capitalize()}: {_symbol_link(step.target, links)}"
+                f"{detail}, line {step.lineno}",
+            )
+        if steps:
+            chunks.append("\n".join(steps))
+        parts.append(_block(*chunks))
+    return "\n".join(parts)
+
+
+def _mermaid(graph: DependencyGraph) -> str:
+    ids: dict[str, str] = {}
+
+    def node(name: str) -> str:
+        if name in ids:
+            return ids[name]
+        ids[name] = f"n{len(ids)}"
+        return f'{ids[name]}["{name}"]'
+
+    lines = ["graph LR"]
+    for consumer, providers in sorted(graph.edges().items()):
+        for provider in sorted(providers):
+            lines.append(f"  {node(consumer)} --> {node(provider)}")
+    return "```mermaid\n" + "\n".join(lines) + "\n```"
+
+
+def render_dependencies_markdown(
+    graph: DependencyGraph,
+    links: dict[str, str] | None = None,
+) -> str:
+    """Render the dependency injection page: a diagram, then every provider."""
+    links = links or {}
+    parts = [
+        f"# {DEPENDENCIES_TITLE}\n",
+        "How components are constructed and injected. Arrows point from a "
+        "consumer to the provider it receives.\n",
+    ]
+    if graph.injections:
+        parts.append(_block(_mermaid(graph)))
+    parts.append("## Providers\n")
+    for name in sorted(graph.providers):
+        provider = graph.providers[name]
+        description = (
+            f"{provider.kind} provider building "
+            f"{_symbol_link(provider.provides, links)}"
+            if provider.provides
+            else f"{provider.kind.capitalize()}"
+        )
+        if provider.file_path:
+            description += f" defined at `{provider.file_path}:{provider.lineno}`"
+        chunks = [f"### `{name}`", description + "."]
+        needs = graph.dependencies_of(name)
+        if needs:
+            chunks.append(
+                "Depends on " + ", ".join(_symbol_link(n, links) for n in needs) + ".",
+            )
+        consumers = []
+        for injection in graph.consumers_of(name):
+            parameter = f" as `{injection.parameter}`" if injection.parameter else ""
+            consumers.append(
+                f"- {_symbol_link(injection.consumer, links)}{parameter}, "
+                f"`{injection.file_path}:{injection.lineno}`",
+            )
+        if consumers:
+            chunks.append("Injected into:\n\n" + "\n".join(consumers))
+        parts.append(_block(*chunks))
+    return "\n".join(parts)
+
+
+def render_test_suite_markdown(suite: SuiteDoc) -> str:
+    """Render the test suite page: tests grouped by the symbol they exercise."""
+    groups = suite.groups()
+    seen: Counter[str] = Counter()
+    heading_slug(TEST_SUITE_TITLE, seen)
+    heading_slug(CONTENTS_HEADING, seen)
+    fuzz_targets = suite.fuzz_targets()
+    fuzz_anchor = heading_slug(FUZZ_TARGETS_HEADING, seen) if fuzz_targets else ""
+    headings = []
+    for subject, _ in groups:
+        text = subject if subject == UNGROUPED else f"`{subject}`"
+        headings.append((text, heading_slug(text, seen)))
+
+    parts = [
+        f"# {TEST_SUITE_TITLE}\n",
+        f"{len(suite.tests)} test(s), grouped by the symbol under test.\n",
+    ]
+    if groups:
+        toc = [
+            f"- [{text}](#{anchor}) ({len(tests)})"
+            for (text, anchor), (_, tests) in zip(headings, groups)
+        ]
+        if fuzz_targets:
+            toc.insert(
+                0,
+                f"- [{FUZZ_TARGETS_HEADING}](#{fuzz_anchor}) ({len(fuzz_targets)})",
+            )
+        parts.append(_block(f"## {CONTENTS_HEADING}", "\n".join(toc)))
+    if fuzz_targets:
+        lines = []
+        for test in fuzz_targets:
+            lines.append(
+                f"- `{test.qualified_name}` ({test.fuzz}, "
+                f"`{test.file_path}:{test.lineno}`) - {test.seeds} seed(s): "
+                f"{test.examples} example(s), {test.corpus} corpus file(s)",
+            )
+            if test.subjects:
+                names = ", ".join(f"`{name}`" for name in test.subjects)
+                lines.append(f"  - Exercises: {names}")
+        parts.append(_block(f"## {FUZZ_TARGETS_HEADING}", "\n".join(lines)))
+    for (text, _), (_, tests) in zip(headings, groups):
+        lines = []
+        for test in tests:
+            line = f"- {test.sentence}"
+            if test.docstring:
+                line += f" - {summary(test.docstring)}"
+            owner = f"{test.test_class}, " if test.test_class else ""
+            lines.append(f"{line} ({owner}`{test.file_path}:{test.lineno}`)")
+            if test.cases:
+                cases = "; ".join(f"`{case}`" for case in test.cases)
+                lines.append(f"  - Cases: {cases}")
+        parts.append(_block(f"## {text}", "\n".join(lines)))
+    return "\n".join(parts)
+
+
+def site_links(packages: list[PackageDoc]) -> dict[str, str]:
+    """Map every symbol's qualified name to its ``page.md#anchor`` link."""
+    links = {}
+    for package in packages:
+        for name, anchor in package_anchors(package).items():
+            links[name] = f"{package.slug}.md#{anchor}"
+    return links
+
+
+def render_guide_markdown(guide: Guide, links: dict[str, str] | None = None) -> str:
+    """Render a guide page, its shortcodes linked to the entries in ``links``."""
+    body = demote_headings(expand_shortcodes(guide.content, links or {})).strip()
+    return _block(f"# {guide.title}", body) if body else f"# {guide.title}\n"
+
+
+def render_glossary_markdown(
+    terms: list[GlossaryTerm],
+    links: dict[str, str] | None = None,
+) -> str:
+    """Render the glossary page: one entry per term, linked to its definers."""
+    links = links or {}
+    parts = [
+        f"# {GLOSSARY_TITLE}\n",
+        "Domain terms used across the code base and where they are defined.\n",
+    ]
+    for term in terms:
+        line = f"- **{term.term}**"
+        if term.definition:
+            line += f" - {term.definition}"
+        if term.defined_by:
+            line += " See " + ", ".join(_symbol_link(n, links) for n in term.defined_by)
+            line += "."
+        parts.append(line)
+    return "\n".join(parts) + "\n"
+
+
+def render_generation_markdown(
+    generation: CodeGeneration,
+    links: dict[str, str] | None = None,
+) -> str:
+    """Render the code generation page: the commands, then the generated files."""
+    links = links or {}
+    parts = [
+        f"# {GENERATION_TITLE}\n",
+        "Commands that generate code, where they are declared, and the files "
+        "they produce.\n",
+    ]
+    if generation.directives:
+        parts.append("## Commands\n")
+        for directive in generation.directives:
+            parts.append(
+                f"- `{directive.command}` "
+                f"(`{directive.file_path}:{directive.lineno}`)",
+            )
+            if directive.outputs:
+                outputs = ", ".join(f"`{output}`" for output in directive.outputs)
+                parts.append(f"  - Writes {outputs}")
+            if directive.generated:
+                files = ", ".join(f"`{path}`" for path in directive.generated)
+                parts.append(f"  - Generated files: {files}")
+        parts.append("")
+    if generation.generated:
+        parts.append("## Generated files\n")
+        for generated in generation.generated:
+            module = _symbol_link(generated.module, links)
+            line = f"- `{generated.file_path}` ({module})"
+            if generated.tool:
+                line += f" - generated by {generated.tool}"
+            parts.append(line)
+    return "\n".join(parts).rstrip("\n") + "\n"
+
+
+def render_build_markdown(constants: list[BuildConstant]) -> str:
+    """Render the build-time configuration page: one row per variable."""
+    parts = [
+        f"# {BUILD_TITLE}\n",
+        "Values filled in when the code is built or deployed, and what they "
+        "are when nothing is injected.\n",
+        "| Variable | Default | Set by | Location |",
+        "| --- | --- | --- | --- |",
+    ]
+    for constant in constants:
+        default = f"`{constant.default}`" if constant.default is not None else "-"
+        setter = constant.setter(lambda name: f"`{name}`")
+        parts.append(
+            f"| `{constant.qualified_name}` | {default} | {setter} "
+            f"| `{constant.file_path}:{constant.lineno}` |",
+        )
+    return "\n".join(parts) + "\n"
+
+
+def render_stats_markdown(
+    stats: list[PackageStats],
+    links: dict[str, str],
+) -> str:
+    """Render the symbol statistics page: a table row per package."""
+    headings = " | ".join(STATS_HEADINGS)
+    parts = [
+        f"# {STATS_TITLE}\n",
+        "The size and documentation of each package. Counts include "
+        "unexported symbols.\n",
+        f"| Package | {headings} | Exported | Average docstring |",
+        "| --- |" + " ---: |" * (len(STATS_KINDS) + 2),
+    ]
+    for package in stats:
+        counts = " | ".join(str(count) for count in package.kinds)
+        parts.append(
+            f"| [{package.name}]({package.slug}.md) | {counts} "
+            f"| {package.exported} of {package.total} "
+            f"({package.exported_percent}%) | {package.doc_length} characters |",
+        )
+    for package in stats:
+        if not package.largest:
+            continue
+        parts.append(f"\n## {package.name}\n")
+        largest = ", ".join(
+            f"{_symbol_link(t.qualified_name, links)} ({t.lines} line(s))"
+            for t in package.largest
+        )
+        parts.append(f"- Largest types: {largest}")
+        if package.most_methods:
+            most = ", ".join(
+                f"{_symbol_link(t.qualified_name, links)} ({t.methods} method(s))"
+                for t in package.most_methods
+            )
+            parts.append(f"- Most methods: {most}")
+    return "\n".join(parts) + "\n"
+
+
+def render_risks_markdown(
+    risks: list[PackageRisks],
+    links: dict[str, str],
+) -> str:
+    """Render the risk appendix: counts per package, then each usage."""
+    headings = " | ".join(kind.capitalize() for kind in RISK_KINDS)
+    parts = [
+        f"# {RISKS_TITLE}\n",
+        "Native code, unsafe calls, and reflection, where the interpreter's "
+        "guarantees stop.\n",
+        f"| Package | {headings} |",
+        "| --- |" + " ---: |" * len(RISK_KINDS),
+    ]
+    for package in risks:
+        counts = " | ".join(str(package.count(kind)) for kind in RISK_KINDS)
+        parts.append(f"| {package.package} | {counts} |")
+    for package in risks:
+        parts.append(f"\n## {package.package}\n")
+        for usage in package.usages:
+            line = f":{usage.lineno}" if usage.lineno else ""
+            where = f"`{usage.file_path}{line}`"
+            if usage.symbol is not None:
+                where += f" in {_symbol_link(usage.symbol, links)}"
+            sentence = usage.sentence(lambda name: f"`{name}`")
+            parts.append(f"- {usage.kind}: {where} {sentence}")
+    return "\n".join(parts) + "\n"
+
+
+def render_platforms_markdown(
+    platforms: list[PackagePlatforms],
+    links: dict[str, str],
+) -> str:
+    """Render the platform matrix: support per package, then each restriction."""
+    headings = " | ".join(PLATFORM_TITLES[platform] for platform in PLATFORMS)
+    parts = [
+        f"# {PLATFORMS_TITLE}\n",
+        "Which packages exist on each platform: `partial` when some of their "
+        "modules or symbols do not.\n",
+        f"| Package | {headings} |",
+        "| --- |" + " --- |" * len(PLATFORMS),
+    ]
+    for package in platforms:
+        support = " | ".join(package.support(platform) for platform in PLATFORMS)
+        parts.append(f"| {package.package} | {support} |")
+    restrictions = [r for package in platforms for r in package.restrictions]
+    if not restrictions:
+        parts.append("\nNo module or symbol is specific to a platform.")
+        return "\n".join(parts) + "\n"
+    parts.extend(
+        [
+            "\n## Platform-specific modules and symbols\n",
+            f"| Name | {headings} | Why |",
+            "| --- |" + " --- |" * len(PLATFORMS) + " --- |",
+        ],
+    )
+    for restriction in restrictions:
+        available = " | ".join(
+            "yes" if platform in restriction.platforms else "no"
+            for platform in PLATFORMS
+        )
+        reason = restriction.reason(lambda name: f"`{name}`")
+        where = f"`{restriction.file_path}:{restriction.lineno}`"
+        parts.append(
+            f"| {_symbol_link(restriction.name, links)} | {available} "
+            f"| {reason} ({where}) |",
+        )
+    return "\n".join(parts) + "\n"
+
+
+def render_third_party_markdown(
+    dependencies: list[Dependency],
+    packages: list[PackageDoc],
+) -> str:
+    """Render the dependency appendix: a table row per declared distribution."""
+    slugs = {package.name: package.slug for package in packages}
+    parts = [
+        f"# {THIRD_PARTY_TITLE}\n",
+        "The distributions the project declares, with the packages that "
+        "import them.\n",
+        "| Distribution | Version | License | Imported by |",
+        "| --- | --- | --- | --- |",
+    ]
+    for dependency in dependencies:
+        importers = ", ".join(
+            f"[{name}]({slugs[name]}.md)" if name in slugs else name
+            for name in dependency.importers
+        )
+        parts.append(
+            f"| {dependency.distribution} | {dependency.version_label()} "
+            f"| {dependency.license or 'unknown'} | {importers or 'not imported'} |",
+        )
+    return "\n".join(parts) + "\n"
+
+
+def _directives_markdown(directives: ProjectDirectives) -> str:
+    """The replaced dependencies and retracted versions sections of the index."""
+    parts = []
+    if directives.replacements:
+        rows = "\n".join(
+            f"| {r.distribution} | {r.kind} | `{r.target}` |"
+            for r in directives.replacements
+        )
+        parts.append(
+            f"\n## {REPLACEMENTS_HEADING}\n\n"
+            "These dependencies are not installed from the package index.\n\n"
+            f"| Distribution | Source | Target |\n| --- | --- | --- |\n{rows}",
+        )
+    if directives.retractions:
+        items = "\n".join(
+            f"- `{r.label()}`: {r.rationale}" if r.rationale else f"- `{r.label()}`"
+            for r in directives.retractions
+        )
+        parts.append(
+            f"\n## {RETRACTIONS_HEADING}\n\n"
+            f"These releases should not be used.\n\n{items}",
+        )
+    return "\n".join(parts)
+
+
+def _newer_syntax_markdown(report: PythonVersionReport) -> str:
+    """The index section on syntax ``requires-python`` does not allow."""
+    rows = "\n".join(
+        f"| {usage.description} | {version_label(usage.version)} "
+        f"| `{usage.file_path}:{usage.lineno}` |"
+        for usage in report.newer()
+    )
+    minimum = version_label(report.minimum) if report.minimum else ""
+    return (
+        f"\n## {NEWER_SYNTAX_HEADING}\n\n"
+        f"`requires-python` allows Python {minimum}, but these need a newer one.\n\n"
+        f"| Feature | Since | Where |\n| --- | --- | --- |\n{rows}"
+    )
+
+
+def render_markdown_site(
+    packages: list[PackageDoc],
+    site: SiteConfig,
+    edit_link: EditLinkFn | None = None,
+    architecture: ArchitectureOverview | None = None,
+    dependencies: DependencyGraph | None = None,
+    only: Container[str] | None = None,
+    glossary: list[GlossaryTerm] | None = None,
+    external_links: ExternalLinkFn | None = None,
+    on_page: Callable[[SitePage], None] | None = None,
+    generation: CodeGeneration | None = None,
+    build_constants: list[BuildConstant] | None = None,
+    stats: list[PackageStats] | None = None,
+    guides: list[Guide] | None = None,
+    risks: list[PackageRisks] | None = None,
+    third_party: list[Dependency] | None = None,
+    directives: ProjectDirectives | None = None,
+    python_version: PythonVersionReport | None = None,
+    platforms: list[PackagePlatforms] | None = None,
+) -> list[SitePage]:
+    """Render ``packages`` into ``index.md`` and per-package pages.
+
+    A non-empty ``architecture`` overview adds ``architecture.md``, a
+    non-empty ``dependencies`` graph adds ``dependencies.md``, a non-empty
+    ``glossary`` adds ``glossary.md``, a non-empty ``generation`` inventory
+    adds ``generation.md``, non-empty ``build_constants`` add ``build.md``,
+    non-empty ``stats`` add ``stats.md``, non-empty ``risks`` add
+    ``risks.md``, non-empty ``third_party`` adds ``third-party.md``, and
+    non-empty ``platforms`` add ``platforms.md``, all linked from the index.
+    Non-empty ``directives`` list the replaced dependencies and retracted
+    versions on the index, after the packages. ``python_version`` states the
+    minimum Python under the title, and lists the syntax newer than it after
+    the packages.
+    Each of ``guides`` adds its page, listed at the end of the index.
+    With ``only``, package pages are rendered just for those package slugs
+    (the index still lists every package). ``on_page`` is called with each
+    package page as soon as it is rendered.
+    """
+    index = [f"# {site.title}\n"]
+    retracted = directives.retracted() if directives else None
+    if retracted is not None:
+        reason = f": {retracted.rationale}" if retracted.rationale else "."
+        index.append(
+            f"> **Warning:** version {directives.version} is retracted{reason}\n",
+        )
+    if python_version is not None:
+        index.append(python_version.sentence(lambda name: f"`{name}`") + "\n")
+    if architecture:
+        index.append(
+            f"[{ARCHITECTURE_TITLE}]({ARCHITECTURE_SLUG}.md): "
+            "entry points and how the application boots.\n",
+        )
+    if dependencies:
+        index.append(
+            f"[{DEPENDENCIES_TITLE}]({DEPENDENCIES_SLUG}.md): "
+            "how components are constructed and injected.\n",
+        )
+    if glossary:
+        index.append(
+            f"[{GLOSSARY_TITLE}]({GLOSSARY_SLUG}.md): "
+            "the domain terms of the code base.\n",
+        )
+    if generation:
+        index.append(
+            f"[{GENERATION_TITLE}]({GENERATION_SLUG}.md): "
+            "commands that generate code and the files they produce.\n",
+        )
+    if build_constants:
+        index.append(
+            f"[{BUILD_TITLE}]({BUILD_SLUG}.md): "
+            "values set when the code is built or deployed.\n",
+        )
+    if stats:
+        index.append(
+            f"[{STATS_TITLE}]({STATS_SLUG}.md): "
+            "the size and documentation of each package.\n",
+        )
+    if risks:
+        index.append(
+            f"[{RISKS_TITLE}]({RISKS_SLUG}.md): "
+            "native code, unsafe calls, and reflection for security review.\n",
+        )
+    if third_party:
+        index.append(
+            f"[{THIRD_PARTY_TITLE}]({THIRD_PARTY_SLUG}.md): "
+            "the declared dependencies, their licenses, and who imports them.\n",
+        )
+    if platforms:
+        index.append(
+            f"[{PLATFORMS_TITLE}]({PLATFORMS_SLUG}.md): "
+            "which packages and symbols exist on Linux, macOS, and Windows.\n",
+        )
+    for package in packages:
+        doc = next((m.symbol.docstring for m in package.modules), None)
+        line = f"- [{package.name}]({package.slug}.md)"
+        index.append(f"{line} - {summary(doc)}" if summary(doc) else line)
+        anchors = package_anchors(package)
+        for module in package.modules:
+            link = f"{package.slug}.md#{anchors[module.name]}"
+            text = summary(module.symbol.docstring)
+            entry = f"  - [`{module.name}`]({link})"
+            index.append(f"{entry} - {text}" if text else entry)
+    if python_version is not None and python_version.newer():
+        index.append(_newer_syntax_markdown(python_version))
+    if directives:
+        index.append(_directives_markdown(directives))
+    if guides:
+        items = "\n".join(f"- [{guide.title}]({guide.slug}.md)" for guide in guides)
+        index.append(f"\n## {GUIDES_HEADING}\n\n{items}")
+    links = site_links(packages)
+    pages = [SitePage("index.md", "\n".join(index) + "\n")]
+    for package in packages:
+        if only is not None and package.slug not in only:
+            continue
+        with span("autodoc.render.package", package=package.name, format="markdown"):
+            content = render_package_markdown(package, edit_link, links, external_links)
+        page = SitePage(f"{package.slug}.md", content)
+        if on_page is not None:
+            on_page(page)
+        pages.append(page)
+    if architecture:
+        pages.append(
+            SitePage(
+                f"{ARCHITECTURE_SLUG}.md",
+                render_architecture_markdown(architecture, links),
+            ),
+        )
+    if dependencies:
+        pages.append(
+            SitePage(
+                f"{DEPENDENCIES_SLUG}.md",
+                render_dependencies_markdown(dependencies, links),
+            ),
+        )
+    if glossary:
+        pages.append(
+            SitePage(f"{GLOSSARY_SLUG}.md", render_glossary_markdown(glossary, links)),
+        )
+    if generation:
+        pages.append(
+            SitePage(
+                f"{GENERATION_SLUG}.md",
+                render_generation_markdown(generation, links),
+            ),
+        )
+    if build_constants:
+        pages.append(
+            SitePage(f"{BUILD_SLUG}.md", render_build_markdown(build_constants)),
+        )
+    if stats:
+        pages.append(SitePage(f"{STATS_SLUG}.md", render_stats_markdown(stats, links)))
+    if risks:
+        pages.append(SitePage(f"{RISKS_SLUG}.md", render_risks_markdown(risks, links)))
+    if third_party:
+        pages.append(
+            SitePage(
+                f"{THIRD_PARTY_SLUG}.md",
+                render_third_party_markdown(third_party, packages),
+            ),
+        )
+    if platforms:
+        pages.append(
+            SitePage(
+                f"{PLATFORMS_SLUG}.md",
+                render_platforms_markdown(platforms, links),
+            ),
+        )
+    pages.extend(
+        SitePage(f"{guide.slug}.md", render_guide_markdown(guide, links))
+        for guide in guides or []
+    )
+    return pages
+
+
+__all__ = [
+    "heading_slug",
+    "package_anchors",
+    "render_architecture_markdown",
+    "render_build_markdown",
+    "render_dependencies_markdown",
+    "render_generation_markdown",
+    "render_glossary_markdown",
+    "render_guide_markdown",
+    "render_markdown_site",
+    "render_package_markdown",
+    "render_platforms_markdown",
+    "render_risks_markdown",
+    "render_stats_markdown",
+    "render_symbol_markdown",
+    "render_test_suite_markdown",
+    "render_third_party_markdown",
+    "site_links",
+]
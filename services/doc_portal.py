@@ -0,0 +1,447 @@
+"""A combined documentation portal for many repositories.
+
+Organizations with many small services want one place to read all of their
+API docs. ``autodoc portal`` reads a manifest of repositories, local paths
+or git URLs::
+
+    title: Acme services
+    repositories:
+      - path: ../billing              # relative to the manifest
+      - url: https://git.acme.io/shop.git
+        ref: v2.3.0                    # branch or tag; default: remote HEAD
+        root: src                      # directory to document in the checkout
+        name: storefront               # default: the directory or URL name
+
+Each repository is documented as ``autodoc generate --format html`` would,
+with its own ``autodoc.yaml``, into ``<name>/`` of the portal. Git URLs are
+cloned shallowly into a cache and fetched again on later runs. The portal's
+``index.html`` lists every repository and package, and searches the symbols
+of all of them: ``search-index.js`` holds one entry per exported symbol and
+``search.js`` filters them in the browser, so the portal works from a static
+host or straight from disk.
+
+The portal's ``sanitize`` setting (as ``site.sanitize`` of ``autodoc.yaml``,
+see :mod:`services.html_sanitizer`) replaces that of every repository, so
+a repository cannot turn sanitizing off to publish scripts in the portal.
+Each repository's ``site.redact`` applies (see :mod:`services.redaction`),
+and the portal's ``audience`` setting leaves out the symbols tagged for
+other readers (see :mod:`services.audience`).
+
+Repositories that import one another link across: a type in a signature that
+another repository of the portal documents links to its page there, instead
+of to PyPI or not at all (see :mod:`services.doc_external_links`).
+"""
+
+from __future__ import annotations
+
+import json
+import logging
+import re
+from collections.abc import Mapping
+from dataclasses import dataclass, field
+from html import escape
+from pathlib import Path
+from typing import Any
+
+import yaml
+
+from autodoc.config.project import (
+    AnalyticsConfig,
+    ProjectConfig,
+    ProjectConfigError,
+    SanitizeConfig,
+    load_project_config,
+)
+from autodoc.model import build_model
+from autodoc.parser import ParsedTree, parse_tree
+from autodoc.render import render_site
+from services.audience import AUDIENCE_NAME, for_audience
+from services.doc_site import PackageDoc, SitePage, summary
+from services.doc_symbols import is_exported
+from services.git_source import GitError, clone
+from services.redaction import redact_packages, redact_tree
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_MANIFEST = "portal.yaml"
+DEFAULT_CACHE = ".autodoc-cache/repos"
+DEFAULT_TITLE = "API documentation"
+SEARCH_INDEX = "search-index.js"
+SEARCH_SCRIPT = "search.js"
+
+_NAME = re.compile(r"^[A-Za-z0-9][A-Za-z0-9._-]*$")
+_REPO_KEYS = frozenset({"name", "path", "url", "ref", "root"})
+
+# Filters window.AUTODOC_SEARCH (set by search-index.js) as the user types.
+_SEARCH_JS = """(function () {
+  var input = document.getElementById("portal-search");
+  var results = document.getElementById("portal-results");
+  var entries = window.AUTODOC_SEARCH || [];
+  function show(query) {
+    results.innerHTML = "";
+    query = query.trim().toLowerCase();
+    if (!query) { return; }
+    var shown = 0;
+    for (var i = 0; i < entries.length && shown < 50; i++) {
+      var entry = entries[i];
+      var haystack = (entry.name + " " + entry.summary).toLowerCase();
+      if (haystack.indexOf(query) === -1) { continue; }
+      var item = document.createElement("li");
+      var link = document.createElement("a");
+      link.href = entry.url;
+      link.textContent = entry.name;
+      item.appendChild(link);
+      item.appendChild(document.createTextNode(
+        " (" + entry.kind + ", " + entry.repo + ")" +
+        (entry.summary ? " - " + entry.summary : "")));
+      results.appendChild(item);
+      shown++;
+    }
+  }
+  input.addEventListener("input", function () { show(input.value); });
+  show(input.value);
+})();
+"""
+
+
+class PortalError(Exception):
+    """Raised when the manifest is invalid or a repository cannot be documented."""
+
+
+@dataclass(frozen=True)
+class PortalRepo:
+    """One repository of the portal: a directory, or a git URL to clone."""
+
+    name: str
+    path: Path | None = None
+    url: str | None = None
+    ref: str | None = None
+    root: str = ""
+
+    @property
+    def label(self) -> str:
+        if self.url is None:
+            return str(self.path)
+        return f"{self.url}@{self.ref}" if self.ref else self.url
+
+    def checkout(self, cache: Path) -> Path:
+        """The directory holding the repository, cloned or fetched if needed.
+
+        Raises:
+            PortalError: If the directory is missing or the clone fails
+        """
+        if self.url is None:
+            if self.path is None or not self.path.is_dir():
+                raise PortalError(f"{self.name}: {self.path} is not a directory")
+            return self.path
+        logger.info("Fetching %s", self.label)
+        try:
+            return clone(self.url, cache / self.name, self.ref)
+        except GitError as exc:
+            raise PortalError(f"{self.name}: cannot fetch {self.label}: {exc}") from exc
+
+
+def _default_name(value: str) -> str:
+    name = value.rstrip("/").rsplit("/", 1)[-1].rsplit(":", 1)[-1]
+    return name.removesuffix(".git")
+
+
+def _repo(index: int, data: Any, base_dir: Path) -> PortalRepo:
+    where = f"repositories[{index}]"
+    if isinstance(data, str):
+        is_url = "://" in data or data.endswith(".git")
+        data = {"url": data} if is_url else {"path": data}
+    if not isinstance(data, dict):
+        raise PortalError(f"{where} must be a path, a URL, or a mapping")
+    unknown = sorted(set(data) - _REPO_KEYS)
+    if unknown:
+        raise PortalError(f"{where} has unknown key(s): {', '.join(unknown)}")
+    if ("path" in data) == ("url" in data):
+        raise PortalError(f"{where} needs exactly one of path and url")
+    values = {key: data[key] for key in _REPO_KEYS & set(data)}
+    for key, value in values.items():
+        if not isinstance(value, str) or not value.strip():
+            raise PortalError(f"{where}.{key} must be a non-empty string")
+    source = values.get("url") or values["path"]
+    name = values.get("name") or _default_name(source)
+    if not _NAME.match(name):
+        raise PortalError(
+            f"{where}: {name!r} is not a valid name (letters, digits, '.', '_', '-')",
+        )
+    path = None
+    if "path" in values:
+        path = Path(values["path"]).expanduser()
+        path = path if path.is_absolute() else base_dir / path
+    root = values.get("root", "").strip("/")
+    return PortalRepo(name, path, values.get("url"), values.get("ref"), root)
+
+
+@dataclass
+class PortalManifest:
+    """The repositories of a portal, its title, HTML allowlist, and audience."""
+
+    title: str = DEFAULT_TITLE
+    repositories: list[PortalRepo] = field(default_factory=list)
+    sanitize: SanitizeConfig = field(default_factory=SanitizeConfig)
+    # The audience the portal is for (see :mod:`services.audience`), or None.
+    audience: str | None = None
+
+    @classmethod
+    def from_dict(cls, data: Any, base_dir: Path) -> PortalManifest:
+        """Validate manifest ``data``; relative paths are below ``base_dir``.
+
+        Raises:
+            PortalError: If the manifest is malformed
+        """
+        if not isinstance(data, dict):
+            raise PortalError("The portal manifest must be a mapping")
+        unknown = sorted(
+            set(data) - {"title", "repositories", "sanitize", "audience"},
+        )
+        if unknown:
+            raise PortalError(f"Unknown manifest key(s): {', '.join(unknown)}")
+        title = data.get("title", DEFAULT_TITLE)
+        if not isinstance(title, str):
+            raise PortalError("title must be a string")
+        entries = data.get("repositories")
+        if not isinstance(entries, list) or not entries:
+            raise PortalError("repositories must be a non-empty list")
+        repositories = [_repo(i, entry, base_dir) for i, entry in enumerate(entries)]
+        names = [repo.name for repo in repositories]
+        duplicates = sorted({name for name in names if names.count(name) > 1})
+        if duplicates:
+            raise PortalError(
+                f"Two repositories are named {', '.join(duplicates)}; set name",
+            )
+        sanitize = data.get("sanitize", True)
+        if not isinstance(sanitize, (dict, bool)):
+            raise PortalError("sanitize must be a mapping or true/false")
+        try:
+            policy = SanitizeConfig.from_dict(sanitize)
+        except ProjectConfigError as exc:
+            raise PortalError(str(exc).removeprefix("site.")) from exc
+        audience = data.get("audience")
+        if audience is not None and (
+            not isinstance(audience, str) or not AUDIENCE_NAME.match(audience)
+        ):
+            raise PortalError("audience must be an audience name such as public")
+        return cls(
+            title,
+            repositories,
+            policy,
+            audience.lower() if audience is not None else None,
+        )
+
+
+def load_manifest(path: str | Path) -> PortalManifest:
+    """Read a portal manifest file.
+
+    Raises:
+        PortalError: If the file is unreadable or malformed
+    """
+    manifest = Path(path)
+    try:
+        data = yaml.safe_load(manifest.read_text(encoding="utf-8"))
+    except (OSError, yaml.YAMLError) as exc:
+        raise PortalError(f"Cannot read {manifest}: {exc}") from exc
+    try:
+        return PortalManifest.from_dict(data, manifest.resolve().parent)
+    except PortalError as exc:
+        raise PortalError(f"{manifest}: {exc}") from exc
+
+
+@dataclass
+class PortalSite:
+    """The documentation of one repository within the portal."""
+
+    repo: PortalRepo
+    tree: ParsedTree
+    config: ProjectConfig
+    packages: list[PackageDoc]
+    pages: list[SitePage] = field(default_factory=list)
+
+    def urls(self) -> dict[str, str]:
+        """The portal URL of every exported symbol, by qualified name."""
+        return {
+            symbol.qualified_name: (
+                f"{self.repo.name}/{package.slug}.html#{symbol.qualified_name}"
+            )
+            for package in self.packages
+            for symbol in package.symbols()
+            if is_exported(symbol)
+        }
+
+    def search_entries(self) -> list[dict[str, str]]:
+        """One search entry per exported symbol, linking into the portal."""
+        entries = []
+        for package in self.packages:
+            page = f"{self.repo.name}/{package.slug}.html"
+            for symbol in package.symbols():
+                if not is_exported(symbol):
+                    continue
+                entries.append(
+                    {
+                        "name": symbol.qualified_name,
+                        "kind": symbol.kind,
+                        "repo": self.repo.name,
+                        "url": f"{page}#{symbol.qualified_name}",
+                        "summary": summary(symbol.docstring),
+                    },
+                )
+        return entries
+
+    def render(self, documented: Mapping[str, str] | None = None) -> None:
+        """Render the pages below ``<name>/``, linking to ``documented`` names.
+
+        Raises:
+            PortalError: If the ``site`` settings of the repository are invalid
+        """
+        try:
+            pages = render_site(
+                self.tree,
+                self.packages,
+                ("html",),
+                self.config,
+                documented=documented,
+            )["html"]
+        except (OSError, ValueError) as exc:
+            raise PortalError(f"Cannot document {self.repo.name}: {exc}") from exc
+        self.pages = [
+            SitePage(f"{self.repo.name}/{page.path}", page.content) for page in pages
+        ]
+
+
+def _config(project_dir: Path, name: str) -> ProjectConfig:
+    try:
+        return load_project_config(project_dir)
+    except ProjectConfigError as exc:
+        raise PortalError(f"{name}: {exc}") from exc
+
+
+def document_repo(
+    repo: PortalRepo,
+    cache: str | Path = DEFAULT_CACHE,
+    sanitize: SanitizeConfig | None = None,
+    audience: str | None = None,
+) -> PortalSite:
+    """Check out, parse, and model ``repo``; :meth:`PortalSite.render` renders it.
+
+    ``sanitize``, if given, replaces the repository's own ``site.sanitize``;
+    ``audience``, if given, leaves out the symbols not for it. The
+    repository's ``site.analytics`` is dropped: its snippet is not sanitized,
+    and the portal's pages are not the repository's to track.
+
+    Raises:
+        PortalError: If the repository cannot be fetched, configured, or read
+    """
+    checkout = repo.checkout(Path(cache))
+    root = checkout / repo.root if repo.root else checkout
+    if not root.is_dir():
+        raise PortalError(f"{repo.name}: {repo.root} is not a directory")
+    config = _config(checkout, repo.name)
+    if sanitize is not None:
+        config.site.sanitize = sanitize
+    config.site.analytics = AnalyticsConfig()
+    logger.info("Documenting %s from %s", repo.name, root)
+    try:
+        tree = parse_tree(root)
+        if audience is not None:
+            tree = for_audience(tree, audience)
+        tree = redact_tree(tree, config.site.redact)
+        packages = redact_packages(build_model(tree, config), config.site.redact)
+    except (OSError, ValueError) as exc:
+        raise PortalError(f"Cannot document {repo.name}: {exc}") from exc
+    return PortalSite(repo, tree, config, packages)
+
+
+def cross_links(sites: list[PortalSite], site: PortalSite) -> dict[str, str]:
+    """URLs, relative to the pages of ``site``, of what the other sites document.
+
+    A name two other repositories document links to the one listed first.
+    """
+    links: dict[str, str] = {}
+    for other in sites:
+        if other is site:
+            continue
+        for name, url in other.urls().items():
+            links.setdefault(name, f"../{url}")
+    return links
+
+
+def render_index(title: str, sites: list[PortalSite]) -> str:
+    """The portal's start page: a search box and every repository's packages."""
+    sections = []
+    for site in sites:
+        name = escape(site.repo.name)
+        items = "\n".join(
+            f'<li><a href="{escape(site.repo.name, quote=True)}/'
+            f'{escape(package.slug, quote=True)}.html"><code>'
+            f"{escape(package.name)}</code></a> - "
+            f"{len([s for s in package.symbols() if is_exported(s)])} symbol(s)</li>"
+            for package in site.packages
+        )
+        sections.append(
+            f'<section id="repo-{name}">\n'
+            f'<h2><a href="{name}/index.html">{name}</a></h2>\n'
+            f"<p><small>{escape(site.repo.label)}</small></p>\n"
+            f"<ul>\n{items}\n</ul>\n</section>",
+        )
+    return (
+        '<!DOCTYPE html>\n<html lang="en">\n<head>\n<meta charset="utf-8">\n'
+        '<meta name="viewport" content="width=device-width, initial-scale=1">\n'
+        f"<title>{escape(title)}</title>\n</head>\n<body>\n<main>\n"
+        f"<h1>{escape(title)}</h1>\n"
+        '<p><label for="portal-search">Search all repositories</label>\n'
+        '<input id="portal-search" type="search" placeholder="Cart.total" '
+        'autocomplete="off"></p>\n'
+        '<ul id="portal-results" aria-live="polite"></ul>\n'
+        + "\n".join(sections)
+        + f'\n</main>\n<script src="{SEARCH_INDEX}"></script>\n'
+        f'<script src="{SEARCH_SCRIPT}"></script>\n</body>\n</html>\n'
+    )
+
+
+def build_portal(
+    manifest: PortalManifest,
+    cache: str | Path = DEFAULT_CACHE,
+) -> tuple[list[SitePage], list[PortalSite]]:
+    """Every page of the portal of ``manifest``, and the sites of its repositories.
+
+    Raises:
+        PortalError: If one of the repositories cannot be documented
+    """
+    sites = [
+        document_repo(repo, cache, manifest.sanitize, manifest.audience)
+        for repo in manifest.repositories
+    ]
+    for site in sites:
+        site.render(cross_links(sites, site))
+    entries = [entry for site in sites for entry in site.search_entries()]
+    entries.sort(key=lambda entry: (entry["name"], entry["repo"]))
+    search = json.dumps(entries, indent=1, sort_keys=True)
+    pages = [
+        SitePage("index.html", render_index(manifest.title, sites)),
+        SitePage(SEARCH_INDEX, f"window.AUTODOC_SEARCH = {search};\n"),
+        SitePage(SEARCH_SCRIPT, _SEARCH_JS),
+    ]
+    for site in sites:
+        pages.extend(site.pages)
+    return pages, sites
+
+
+__all__ = [
+    "DEFAULT_CACHE",
+    "DEFAULT_MANIFEST",
+    "DEFAULT_TITLE",
+    "SEARCH_INDEX",
+    "SEARCH_SCRIPT",
+    "PortalError",
+    "PortalManifest",
+    "PortalRepo",
+    "PortalSite",
+    "build_portal",
+    "cross_links",
+    "document_repo",
+    "load_manifest",
+    "render_index",
+]
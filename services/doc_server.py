@@ -0,0 +1,338 @@
+"""A documentation server for a private set of Python packages.
+
+``autodoc serve`` browses the API docs of several packages at once, by import
+path: ``/shop/cart`` shows the page documenting module ``shop.cart``, and
+``/shop/cart/Cart`` jumps to the class. Each package comes from a
+:class:`CheckoutSource`, a directory on disk such as a git checkout, or an
+:class:`~services.package_index.IndexRelease`, a release downloaded from a
+PyPI-compatible index (the Python counterpart of a Go module proxy).
+
+Nothing is rendered up front. A source is parsed and rendered as HTML the
+first time one of its pages is requested, and again once one of its Python
+files changes, so a checkout can be edited while the server runs. Rendered
+sites are served below ``/_sites/<source>/``, where their relative links and
+assets work unchanged.
+
+Rendered pages carry no analytics of their own config, so browsing them does
+not count as views of the hosted docs. With :class:`ViewLog` the server
+records the views of its pages instead, answering their beacon at
+:data:`~services.doc_analytics.SERVE_BEACON` (see
+:mod:`services.doc_analytics`).
+"""
+
+from __future__ import annotations
+
+import html
+import logging
+import re
+import threading
+from collections.abc import Iterable
+from dataclasses import dataclass
+from http import HTTPStatus
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from pathlib import Path
+from typing import Any
+from urllib.parse import unquote, urlsplit
+
+from autodoc.config.project import (
+    AnalyticsConfig,
+    ProjectConfig,
+    ProjectConfigError,
+    load_project_config,
+)
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.doc_analytics import SERVE_BEACON, ViewLog
+from services.doc_caching import content_type
+from services.doc_site import SitePage
+from services.doc_symbols import discover_python_files
+from services.package_index import IndexRelease, PackageIndexError
+
+logger = logging.getLogger(__name__)
+
+SITES_PREFIX = "/_sites/"
+# Largest page view post read, in bytes.
+_MAX_BEACON = 4096
+_IMPORT_PATH = re.compile(r"^[A-Za-z_]\w*(?:[/.][A-Za-z_]\w*)*$")
+
+
+class ServeError(Exception):
+    """Raised when a source cannot be read or rendered."""
+
+
+@dataclass
+class CheckoutSource:
+    """Packages documented from a directory on disk."""
+
+    path: Path
+    name: str
+
+    @property
+    def label(self) -> str:
+        return str(self.path)
+
+    @property
+    def project_dir(self) -> Path:
+        return self.path
+
+    def root(self) -> Path:
+        if not self.path.is_dir():
+            raise ServeError(f"{self.path} is not a directory")
+        return self.path
+
+    def import_names(self) -> list[str]:
+        """Top-level import names, known without rendering."""
+        if not self.path.is_dir():
+            return []
+        return sorted(
+            path.stem if path.is_file() else path.name
+            for path in self.path.iterdir()
+            if (path / "__init__.py").is_file() or path.suffix == ".py"
+        )
+
+
+
+
+@dataclass
+class _Rendered:
+    stamp: tuple[int, float]
+    pages: dict[str, SitePage]
+    # Module name -> (page path, anchors on that page).
+    modules: dict[str, tuple[str, set[str]]]
+
+
+class DocServer:
+    """Renders the sources on demand and answers requests by URL path."""
+
+    def __init__(
+        self,
+        sources: Iterable[CheckoutSource | IndexRelease],
+        title: str = "Package documentation",
+        analytics: ViewLog | None = None,
+    ) -> None:
+        self.sources = {source.name: source for source in sources}
+        self.title = title
+        self.analytics = analytics
+        self._rendered: dict[str, _Rendered] = {}
+        self._lock = threading.Lock()
+
+    def _stamp(self, root: Path) -> tuple[int, float]:
+        files = discover_python_files(root)
+        newest = max((path.stat().st_mtime for path in files), default=0.0)
+        return len(files), newest
+
+    def _config(self, root: Path) -> ProjectConfig:
+        try:
+            return load_project_config(root)
+        except ProjectConfigError as exc:
+            logger.warning("Ignoring the config of %s: %s", root, exc)
+            return ProjectConfig()
+
+    def rendered(self, name: str) -> _Rendered:
+        """The HTML site of source ``name``, rendered again when it changed."""
+        source = self.sources[name]
+        with self._lock:
+            root = source.root()
+            stamp = self._stamp(root)
+            cached = self._rendered.get(name)
+            if cached is not None and cached.stamp == stamp:
+                return cached
+            logger.info("Rendering %s", source.label)
+            try:
+                config = self._config(source.project_dir or root)
+                config.site.analytics = AnalyticsConfig(
+                    beacon=SERVE_BEACON if self.analytics is not None else None,
+                )
+                tree = parse_tree(root)
+                packages = build_model(tree, config)
+                pages = render_site(tree, packages, ("html",), config)["html"]
+            except (OSError, ValueError) as exc:
+                raise ServeError(f"Cannot render {source.label}: {exc}") from exc
+            modules = {}
+            for package in packages:
+                anchors = {symbol.qualified_name for symbol in package.symbols()}
+                for module in package.modules:
+                    modules[module.name] = (f"{package.slug}.html", anchors)
+            cached = _Rendered(stamp, {page.path: page for page in pages}, modules)
+            self._rendered[name] = cached
+            return cached
+
+    def locate(self, import_path: str) -> str | None:
+        """The URL documenting ``import_path`` (``shop/cart`` or ``shop.cart.Cart``)."""
+        dotted = import_path.strip("/").replace("/", ".")
+        top = dotted.split(".", 1)[0]
+        # Sources known to provide the name first; the others are rendered
+        # only when they must be searched.
+        names = sorted(
+            self.sources,
+            key=lambda name: top not in self.sources[name].import_names(),
+        )
+        for name in names:
+            modules = self.rendered(name).modules
+            parts = dotted.split(".")
+            while parts:
+                module = ".".join(parts)
+                if module in modules:
+                    page, anchors = modules[module]
+                    anchor = dotted if dotted in anchors else module
+                    return f"{SITES_PREFIX}{name}/{page}#{anchor}"
+                parts.pop()
+        return None
+
+    def index_page(self) -> str:
+        """The start page, listing every source and the packages rendered so far."""
+        items = []
+        for name, source in sorted(self.sources.items()):
+            rendered = self._rendered.get(name)
+            modules = ""
+            if rendered is not None:
+                tops = sorted({m for m in rendered.modules if "." not in m})
+                modules = " - " + ", ".join(
+                    f'<a href="/{html.escape(top, quote=True)}">'
+                    f"<code>{html.escape(top)}</code></a>"
+                    for top in tops
+                )
+            items.append(
+                f'<li><a href="{SITES_PREFIX}{html.escape(name, quote=True)}/">'
+                f"{html.escape(source.label)}</a>{modules}</li>",
+            )
+        return _page(
+            self.title,
+            f"<h1>{html.escape(self.title)}</h1>\n"
+            '<form action="/_go"><label>Import path '
+            '<input name="path" placeholder="shop.cart"></label> '
+            '<button>Go</button></form>\n'
+            f"<ul>\n{chr(10).join(items)}\n</ul>",
+        )
+
+    def respond(self, path: str) -> tuple[int, dict[str, str], bytes]:
+        """Status, headers, and body for a GET of ``path`` (with query)."""
+        parts = urlsplit(path)
+        route = unquote(parts.path)
+        if route == "/":
+            return _html(HTTPStatus.OK, self.index_page())
+        if route == "/_go":
+            query = dict(
+                pair.split("=", 1) for pair in parts.query.split("&") if "=" in pair
+            )
+            route = "/" + unquote(query.get("path", "").replace("+", " ")).strip()
+        try:
+            if route.startswith(SITES_PREFIX):
+                return self._site_file(route[len(SITES_PREFIX) :])
+            if not _IMPORT_PATH.match(route.strip("/")):
+                return _not_found(route)
+            url = self.locate(route)
+        except (ServeError, PackageIndexError) as exc:
+            logger.warning("%s", exc)
+            body = _page(
+                "Error",
+                f"<h1>Cannot show {html.escape(route)}</h1>\n"
+                f"<p>{html.escape(str(exc))}</p>",
+            )
+            return _html(HTTPStatus.BAD_GATEWAY, body)
+        if url is None:
+            return _not_found(route)
+        return HTTPStatus.FOUND, {"Location": url}, b""
+
+    def respond_post(
+        self,
+        path: str,
+        body: bytes,
+    ) -> tuple[int, dict[str, str], bytes]:
+        """Status, headers, and body for a POST of ``body`` to ``path``."""
+        if urlsplit(path).path != SERVE_BEACON or self.analytics is None:
+            return _not_found(unquote(urlsplit(path).path))
+        if not self.analytics.record(body):
+            return HTTPStatus.BAD_REQUEST, {}, b""
+        return HTTPStatus.NO_CONTENT, {}, b""
+
+    def _site_file(self, rest: str) -> tuple[int, dict[str, str], bytes]:
+        name, _, path = rest.partition("/")
+        if name not in self.sources:
+            return _not_found(f"{SITES_PREFIX}{rest}")
+        if not rest.partition("/")[1]:
+            location = f"{SITES_PREFIX}{name}/"
+            return HTTPStatus.MOVED_PERMANENTLY, {"Location": location}, b""
+        page = self.rendered(name).pages.get(path or "index.html")
+        if page is None:
+            return _not_found(f"{SITES_PREFIX}{rest}")
+        content = page.content
+        body = content.encode("utf-8") if isinstance(content, str) else content
+        return HTTPStatus.OK, {"Content-Type": content_type(page.path)}, body
+
+
+def _page(title: str, body: str) -> str:
+    return (
+        '<!DOCTYPE html>\n<html lang="en">\n<head>\n<meta charset="utf-8">\n'
+        f"<title>{html.escape(title)}</title>\n</head>\n<body>\n<main>\n{body}\n"
+        "</main>\n</body>\n</html>\n"
+    )
+
+
+def _html(status: int, text: str) -> tuple[int, dict[str, str], bytes]:
+    return status, {"Content-Type": "text/html; charset=utf-8"}, text.encode("utf-8")
+
+
+def _not_found(route: str) -> tuple[int, dict[str, str], bytes]:
+    body = _page(
+        "Not found",
+        f"<h1>Nothing documents {html.escape(route)}</h1>\n"
+        '<p><a href="/">All packages</a></p>',
+    )
+    return _html(HTTPStatus.NOT_FOUND, body)
+
+
+def make_handler(server: DocServer) -> type[BaseHTTPRequestHandler]:
+    """A request handler class answering from ``server``."""
+
+    class Handler(BaseHTTPRequestHandler):
+        server_version = "autodoc-serve"
+
+        def do_GET(self) -> None:  # noqa: N802 - http.server naming
+            self._send(*server.respond(self.path))
+
+        def do_POST(self) -> None:  # noqa: N802 - http.server naming
+            try:
+                length = int(self.headers.get("Content-Length") or 0)
+            except ValueError:
+                length = -1
+            if length > _MAX_BEACON:
+                self._send(HTTPStatus.REQUEST_ENTITY_TOO_LARGE, {}, b"")
+                return
+            if length < 0:
+                self._send(HTTPStatus.BAD_REQUEST, {}, b"")
+                return
+            self._send(*server.respond_post(self.path, self.rfile.read(length)))
+
+        def _send(self, status: int, headers: dict[str, str], body: bytes) -> None:
+            self.send_response(status)
+            for key, value in headers.items():
+                self.send_header(key, value)
+            self.send_header("Content-Length", str(len(body)))
+            self.end_headers()
+            self.wfile.write(body)
+
+        def log_message(self, format: str, *args: Any) -> None:  # noqa: A002
+            logger.debug("%s %s", self.address_string(), format % args)
+
+    return Handler
+
+
+def serve(server: DocServer, host: str = "127.0.0.1", port: int = 8000) -> None:
+    """Serve until interrupted."""
+    httpd = ThreadingHTTPServer((host, port), make_handler(server))
+    try:
+        httpd.serve_forever()
+    finally:
+        httpd.server_close()
+
+
+__all__ = [
+    "SITES_PREFIX",
+    "CheckoutSource",
+    "DocServer",
+    "ServeError",
+    "make_handler",
+    "serve",
+]
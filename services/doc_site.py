@@ -0,0 +1,736 @@
+"""Page model for generated documentation sites.
+
+The renderers (:mod:`services.doc_markdown`, :mod:`services.doc_html`) do not
+work on the flat symbol list directly. :func:`build_site_model` first groups
+exported :class:`~services.doc_symbols.DocSymbol` records into packages,
+modules, and classes in a stable order, so every output format lays out the
+same content the same way.
+
+Generated output is byte-stable: the same sources produce the same files on
+every run and machine, so sites can be committed and diffed. Nothing
+time-dependent is written unless asked for with :func:`stamp_pages`.
+
+:func:`write_site` replaces the output directory as a whole: pages are
+written to a staging directory next to it, which is then swapped in, so an
+interrupted run leaves the previous site intact. The generated files are
+listed in :data:`SITE_FILES`; files the previous run generated but this one
+does not (a removed package, say) disappear, while files added by hand are
+carried over.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import inspect
+import json
+import logging
+import os
+import shutil
+import uuid
+from collections import defaultdict
+from collections.abc import Callable, Iterable, Mapping, Sequence
+from dataclasses import dataclass, field, replace
+from datetime import UTC, datetime
+from pathlib import Path
+
+from services.doc_symbols import DocSymbol, is_exported
+from services.docstring_sections import ParsedDocstring, parse_docstring
+from services.embedded_assets import EmbeddedAsset
+from services.exception_chains import RaisedError
+from services.faq import FaqEntry
+from services.inherited_docs import InheritedDoc
+from services.none_safety import NoneCheck
+from services.package_readme import PackageReadme, load_readme
+from services.placeholder_summaries import placeholder_summary
+from services.pragmas import Pragma
+from services.progress import PUBLISH, Progress
+from services.quickstart import Quickstart, build_quickstart, verify_quickstart
+from services.side_effects import SideEffect
+from services.write_plan import DELETE, PlannedWrite, WritePlan, plan_writes
+
+logger = logging.getLogger(__name__)
+
+# Page name (without extension) and title of the entry-point overview.
+ARCHITECTURE_SLUG = "architecture"
+ARCHITECTURE_TITLE = "Architecture"
+# Page name and title of the dependency injection graph.
+DEPENDENCIES_SLUG = "dependencies"
+DEPENDENCIES_TITLE = "Dependency graph"
+# Page name and title of the domain term glossary.
+GLOSSARY_SLUG = "glossary"
+GLOSSARY_TITLE = "Glossary"
+# Page name and title of the code generation reference.
+GENERATION_SLUG = "generation"
+GENERATION_TITLE = "Code generation"
+# Page name and title of the values set when the code is built or deployed.
+BUILD_SLUG = "build"
+BUILD_TITLE = "Build-time configuration"
+# Page name and title of the per-package symbol statistics.
+STATS_SLUG = "stats"
+STATS_TITLE = "Symbol statistics"
+# Page name and title of the native code, unsafe calls, and reflection.
+RISKS_SLUG = "risks"
+RISKS_TITLE = "Risk appendix"
+# Page name and title of the packages and symbols available per platform.
+PLATFORMS_SLUG = "platforms"
+PLATFORMS_TITLE = "Platform support"
+# Page name and title of the declared third-party dependencies.
+THIRD_PARTY_SLUG = "third-party"
+THIRD_PARTY_TITLE = "Third-party dependencies"
+# Headings of the replaced dependencies and retracted releases on the index.
+REPLACEMENTS_HEADING = "Replaced dependencies"
+RETRACTIONS_HEADING = "Retracted versions"
+# Heading of the syntax that needs more than ``requires-python`` on the index.
+NEWER_SYNTAX_HEADING = "Syntax newer than the minimum Python"
+# Heading of the list of guides on the index (see :mod:`services.guides`).
+GUIDES_HEADING = "Guides"
+# Title of the page written by ``autodoc generate --tests``.
+TEST_SUITE_TITLE = "Test suite"
+# Heading of the fuzz target section on that page.
+FUZZ_TARGETS_HEADING = "Fuzz targets"
+# Heading of the data files section of a package page.
+ASSETS_HEADING = "Embedded assets"
+# Heading of the section on what importing a package's modules does.
+SIDE_EFFECTS_HEADING = "Import side effects"
+
+
+@dataclass
+class ClassDoc:
+    """A class and its documented methods."""
+
+    symbol: DocSymbol
+    methods: list[DocSymbol] = field(default_factory=list)
+    # Qualified names of mocks standing in for this interface.
+    mocks: list[str] = field(default_factory=list)
+    # Qualified names of the interfaces this class mocks.
+    mocked: list[str] = field(default_factory=list)
+
+
+@dataclass
+class ModuleDoc:
+    """A module and the symbols defined at its top level."""
+
+    symbol: DocSymbol
+    functions: list[DocSymbol] = field(default_factory=list)
+    classes: list[ClassDoc] = field(default_factory=list)
+
+    @property
+    def name(self) -> str:
+        return self.symbol.qualified_name
+
+
+@dataclass
+class PackageDoc:
+    """One package page: every module below a single directory."""
+
+    name: str
+    modules: list[ModuleDoc] = field(default_factory=list)
+    # (symbol, number of other packages using it), most used first.
+    most_used: list[tuple[DocSymbol, int]] = field(default_factory=list)
+    # Qualified name -> same-named symbols of other packages.
+    namesakes: dict[str, list[DocSymbol]] = field(default_factory=dict)
+    # Data files the package's modules load, in module and line order.
+    assets: list[EmbeddedAsset] = field(default_factory=list)
+    # Qualified name -> tool pragmas on the symbol, in line order.
+    pragmas: dict[str, list[Pragma]] = field(default_factory=dict)
+    # What importing the package's modules does, in module and line order.
+    side_effects: list[SideEffect] = field(default_factory=list)
+    # Qualified name -> how the function treats None for optional parameters.
+    none_safety: dict[str, list[NoneCheck]] = field(default_factory=dict)
+    # Qualified name -> the exceptions the function can raise to callers.
+    raises: dict[str, list[RaisedError]] = field(default_factory=dict)
+    # Qualified name -> docstring with its parameter and return sections, for
+    # the docstrings that have any.
+    docstrings: dict[str, ParsedDocstring] = field(default_factory=dict)
+    # The README.md of the package directory, shown above the API reference.
+    readme: PackageReadme | None = None
+    # Qualified name -> where the method's docstring is inherited from.
+    inherited: dict[str, InheritedDoc] = field(default_factory=dict)
+    # Qualified name -> auto-generated summary shown for an undocumented symbol.
+    placeholders: dict[str, str] = field(default_factory=dict)
+    # Verified snippet constructing the main class and calling its method.
+    quickstart: Quickstart | None = None
+    # FAQ and troubleshooting entries, those of the sidecar files first.
+    faq: list[FaqEntry] = field(default_factory=list)
+
+    @property
+    def slug(self) -> str:
+        """File-name-safe identifier used for the package's page."""
+        return self.name.replace("/", "-")
+
+    def symbols(self) -> list[DocSymbol]:
+        """Every symbol on the page, in page order."""
+        result: list[DocSymbol] = []
+        for module in self.modules:
+            result.append(module.symbol)
+            result.extend(module.functions)
+            for cls in module.classes:
+                result.append(cls.symbol)
+                result.extend(cls.methods)
+        return result
+
+
+@dataclass(frozen=True)
+class SitePage:
+    """A file to write into the output directory."""
+
+    path: str
+    content: str | bytes
+
+
+def build_site_model(
+    symbols: Iterable[DocSymbol],
+    include_private: bool = False,
+) -> list[PackageDoc]:
+    """Group ``symbols`` into packages sorted by name.
+
+    Only exported symbols are included unless ``include_private`` is set.
+    Within a module, functions and classes keep source order.
+    """
+    visible = [s for s in symbols if include_private or is_exported(s)]
+    modules: dict[str, ModuleDoc] = {}
+    classes: dict[str, ClassDoc] = {}
+
+    for symbol in visible:
+        if symbol.kind == "module":
+            modules[symbol.qualified_name] = ModuleDoc(symbol=symbol)
+    for symbol in visible:
+        if symbol.kind == "class" and symbol.parent in modules:
+            doc = ClassDoc(symbol=symbol)
+            classes[symbol.qualified_name] = doc
+            modules[symbol.parent].classes.append(doc)
+        elif symbol.kind == "function" and symbol.parent in modules:
+            modules[symbol.parent].functions.append(symbol)
+    for symbol in visible:
+        if symbol.kind == "method" and symbol.parent in classes:
+            classes[symbol.parent].methods.append(symbol)
+
+    packages: dict[str, PackageDoc] = defaultdict(lambda: PackageDoc(name=""))
+    for module in sorted(modules.values(), key=lambda m: m.name):
+        module.functions.sort(key=lambda s: (s.lineno, s.name))
+        module.classes.sort(key=lambda c: (c.symbol.lineno, c.symbol.name))
+        for cls in module.classes:
+            cls.methods.sort(key=lambda s: (s.lineno, s.name))
+        package = packages[module.symbol.package]
+        package.name = module.symbol.package
+        package.modules.append(module)
+    return [packages[name] for name in sorted(packages)]
+
+
+def attach_most_used(
+    packages: Iterable[PackageDoc],
+    counts: Mapping[str, int],
+    limit: int = 5,
+) -> None:
+    """Fill :attr:`PackageDoc.most_used` from per-symbol usage ``counts``.
+
+    Modules and symbols with no outside users are never listed.
+    """
+    for package in packages:
+        ranked = [
+            (symbol, counts.get(symbol.qualified_name, 0))
+            for symbol in package.symbols()
+            if symbol.kind != "module"
+        ]
+        ranked = [entry for entry in ranked if entry[1] > 0]
+        ranked.sort(key=lambda entry: (-entry[1], entry[0].qualified_name))
+        package.most_used = ranked[:limit]
+
+
+def attach_mock_links(
+    packages: Iterable[PackageDoc],
+    links: Iterable[tuple[str, str]],
+) -> None:
+    """Fill :attr:`ClassDoc.mocks` and :attr:`ClassDoc.mocked`.
+
+    ``links`` holds ``(mock, interface)`` qualified-name pairs; either side
+    may be absent from the site, e.g. when mock modules are hidden.
+    """
+    classes = {
+        cls.symbol.qualified_name: cls
+        for package in packages
+        for module in package.modules
+        for cls in module.classes
+    }
+    for mock, interface in links:
+        if interface in classes and mock not in classes[interface].mocks:
+            classes[interface].mocks.append(mock)
+        if mock in classes and interface not in classes[mock].mocked:
+            classes[mock].mocked.append(interface)
+
+
+def attach_assets(
+    packages: Iterable[PackageDoc],
+    assets: Iterable[EmbeddedAsset],
+) -> None:
+    """Fill :attr:`PackageDoc.assets` with the assets of each package."""
+    pages = {package.name: package for package in packages}
+    for asset in assets:
+        if asset.package in pages:
+            pages[asset.package].assets.append(asset)
+
+
+def attach_side_effects(
+    packages: Iterable[PackageDoc],
+    effects: Iterable[SideEffect],
+) -> None:
+    """Fill :attr:`PackageDoc.side_effects` with the effects of each package."""
+    pages = {package.name: package for package in packages}
+    for effect in effects:
+        if effect.package in pages:
+            pages[effect.package].side_effects.append(effect)
+
+
+def attach_none_safety(
+    packages: Iterable[PackageDoc],
+    checks: Iterable[NoneCheck],
+) -> None:
+    """Fill :attr:`PackageDoc.none_safety` for the documented functions."""
+    pages = {
+        symbol.qualified_name: package
+        for package in packages
+        for symbol in package.symbols()
+    }
+    for check in checks:
+        package = pages.get(check.symbol)
+        if package is not None:
+            package.none_safety.setdefault(check.symbol, []).append(check)
+
+
+def attach_docstring_sections(packages: Iterable[PackageDoc]) -> None:
+    """Fill :attr:`PackageDoc.docstrings` from the symbols' docstrings."""
+    for package in packages:
+        for symbol in package.symbols():
+            parsed = parse_docstring(symbol.docstring)
+            if parsed.structured:
+                package.docstrings[symbol.qualified_name] = parsed
+
+
+def attach_inherited_docs(
+    packages: Iterable[PackageDoc],
+    inherited: Iterable[InheritedDoc],
+) -> None:
+    """Give the undocumented methods of ``packages`` the docstrings they inherit.
+
+    The methods are replaced by copies with the inherited docstring, so every
+    renderer shows it, and :attr:`PackageDoc.inherited` records its source.
+    """
+    docs = {doc.symbol: doc for doc in inherited}
+    for package in packages:
+        for module in package.modules:
+            for cls in module.classes:
+                for index, method in enumerate(cls.methods):
+                    doc = docs.get(method.qualified_name)
+                    if doc is not None:
+                        cls.methods[index] = replace(method, docstring=doc.docstring)
+                        package.inherited[method.qualified_name] = doc
+
+
+def attach_placeholder_summaries(packages: Iterable[PackageDoc]) -> None:
+    """Fill :attr:`PackageDoc.placeholders` for the undocumented symbols."""
+    for package in packages:
+        for symbol in package.symbols():
+            text = placeholder_summary(symbol)
+            if text is not None:
+                package.placeholders[symbol.qualified_name] = text
+
+
+def attach_quickstarts(
+    packages: Iterable[PackageDoc],
+    symbols: Iterable[DocSymbol],
+) -> None:
+    """Fill :attr:`PackageDoc.quickstart` from the documented API.
+
+    ``symbols`` are all the symbols of the tree, for the ``__init__``
+    methods the pages leave out. Snippets that fail
+    :func:`~services.quickstart.verify_quickstart` are logged and left out.
+    """
+    initializers = {
+        symbol.parent: symbol
+        for symbol in symbols
+        if symbol.kind == "method" and symbol.name == "__init__" and symbol.parent
+    }
+    for package in packages:
+        documented = package.symbols()
+        used = {symbol.qualified_name: count for symbol, count in package.most_used}
+        quickstart = build_quickstart(package.name, documented, initializers, used)
+        if quickstart is None:
+            continue
+        problems = verify_quickstart(quickstart, [s.qualified_name for s in documented])
+        if problems:
+            logger.warning(
+                "Leaving out the quickstart of %s: %s",
+                package.name,
+                "; ".join(problems),
+            )
+            continue
+        package.quickstart = quickstart
+
+
+def attach_faq(packages: Iterable[PackageDoc], entries: Iterable[FaqEntry]) -> None:
+    """Fill :attr:`PackageDoc.faq` with the entries of each package."""
+    pages = {package.name: package for package in packages}
+    for entry in entries:
+        if entry.package in pages:
+            pages[entry.package].faq.append(entry)
+
+
+def attach_readmes(packages: Iterable[PackageDoc]) -> None:
+    """Fill :attr:`PackageDoc.readme` from the directory of each package."""
+    for package in packages:
+        if package.modules:
+            directory = Path(package.modules[0].symbol.file_path).parent
+            package.readme = load_readme(directory)
+
+
+def attach_raises(
+    packages: Iterable[PackageDoc],
+    errors: Iterable[RaisedError],
+) -> None:
+    """Fill :attr:`PackageDoc.raises` for the documented functions."""
+    pages = {
+        symbol.qualified_name: package
+        for package in packages
+        for symbol in package.symbols()
+    }
+    for error in errors:
+        package = pages.get(error.symbol)
+        if package is not None:
+            package.raises.setdefault(error.symbol, []).append(error)
+
+
+def attach_pragmas(
+    packages: Iterable[PackageDoc],
+    pragmas: Iterable[Pragma],
+) -> None:
+    """Fill :attr:`PackageDoc.pragmas` with the pragmas of documented symbols."""
+    pages = {
+        symbol.qualified_name: package
+        for package in packages
+        for symbol in package.symbols()
+    }
+    for pragma in pragmas:
+        package = pages.get(pragma.symbol)
+        if package is not None:
+            package.pragmas.setdefault(pragma.symbol, []).append(pragma)
+
+
+def attach_namesakes(
+    packages: Iterable[PackageDoc],
+    groups: Iterable[Sequence[DocSymbol]],
+) -> None:
+    """Fill :attr:`PackageDoc.namesakes` from groups of same-named symbols.
+
+    Only symbols of other packages count as namesakes; groups are usually
+    the collisions of :func:`services.name_collisions.find_collisions`.
+    """
+    pages = {
+        symbol.qualified_name: package
+        for package in packages
+        for symbol in package.symbols()
+    }
+    for group in groups:
+        for symbol in group:
+            package = pages.get(symbol.qualified_name)
+            others = [s for s in group if s.package != symbol.package]
+            if package is not None and others:
+                package.namesakes[symbol.qualified_name] = others
+
+
+def map_docstrings(
+    packages: Iterable[PackageDoc],
+    rewrite: Callable[[DocSymbol], str | None],
+) -> list[PackageDoc]:
+    """Copies of ``packages`` with each docstring replaced by ``rewrite``.
+
+    Symbols whose docstring does not change are shared with ``packages``;
+    the namesake and "most used" lists keep the original symbols.
+    """
+
+    def swap(symbol: DocSymbol) -> DocSymbol:
+        docstring = rewrite(symbol)
+        if docstring == symbol.docstring:
+            return symbol
+        return symbol.with_docstring(docstring)
+
+    return [
+        replace(
+            package,
+            modules=[
+                replace(
+                    module,
+                    symbol=swap(module.symbol),
+                    functions=[swap(function) for function in module.functions],
+                    classes=[
+                        replace(
+                            cls,
+                            symbol=swap(cls.symbol),
+                            methods=[swap(method) for method in cls.methods],
+                        )
+                        for cls in module.classes
+                    ],
+                )
+                for module in package.modules
+            ],
+        )
+        for package in packages
+    ]
+
+
+def _parameter(param: dict) -> str:
+    text = {"*args": "*", "**kwargs": "**"}.get(param.get("kind", ""), "") + param["name"]
+    if param.get("annotation"):
+        text += f": {param['annotation']}"
+        if param.get("default") is not None:
+            text += f" = {param['default']}"
+    elif param.get("default") is not None:
+        text += f"={param['default']}"
+    return text
+
+
+def signature(symbol: DocSymbol) -> str:
+    """Render a Python-style signature line for ``symbol``."""
+    metadata = symbol.metadata or {}
+    if symbol.kind == "class":
+        bases = metadata.get("base_classes") or []
+        return f"class {symbol.name}({', '.join(bases)})" if bases else f"class {symbol.name}"
+    if symbol.kind in ("function", "method"):
+        params = [_parameter(param) for param in metadata.get("parameters", [])]
+        prefix = "async def" if metadata.get("is_async") else "def"
+        returns = metadata.get("return_type")
+        arrow = f" -> {returns}" if returns else ""
+        return f"{prefix} {symbol.name}({', '.join(params)}){arrow}"
+    return f"module {symbol.qualified_name}"
+
+
+def summary(docstring: str | None) -> str:
+    """First paragraph of ``docstring`` collapsed onto one line."""
+    if not docstring:
+        return ""
+    paragraph = docstring.strip().split("\n\n", 1)[0]
+    return " ".join(line.strip() for line in paragraph.splitlines())
+
+
+def content_hash(symbol: DocSymbol) -> str:
+    """SHA-256 of the kind, signature, and docstring of ``symbol``.
+
+    The docstring is normalized first (indentation and trailing whitespace
+    removed), and where the symbol is defined does not count, so the hash
+    changes only when its documented contract does: comparing hashes tells a
+    downstream tool which symbols changed without diffing the docs.
+    """
+    lines = inspect.cleandoc(symbol.docstring or "").splitlines()
+    docstring = "\n".join(line.rstrip() for line in lines)
+    text = f"{symbol.kind}\n{signature(symbol)}\n{docstring}"
+    return hashlib.sha256(text.encode("utf-8")).hexdigest()
+
+
+def generation_time(environ: Mapping[str, str] = os.environ) -> datetime:
+    """When the site is generated: ``SOURCE_DATE_EPOCH`` if set, else now.
+
+    Honoring ``SOURCE_DATE_EPOCH`` keeps stamped builds reproducible.
+    """
+    epoch = environ.get("SOURCE_DATE_EPOCH", "").strip()
+    if epoch.isdigit():
+        return datetime.fromtimestamp(int(epoch), UTC)
+    return datetime.now(UTC).replace(microsecond=0)
+
+
+def stamp_pages(pages: Sequence[SitePage], when: datetime) -> list[SitePage]:
+    """Add a "Generated" note with ``when`` to the index page of each format."""
+    stamp = when.astimezone(UTC).strftime("%Y-%m-%dT%H:%M:%SZ")
+    stamped = []
+    for page in pages:
+        content = page.content
+        if page.path == "index.md" and isinstance(content, str):
+            content = f"{content.rstrip()}\n\n_Generated {stamp}._\n"
+        elif page.path == "index.html" and isinstance(content, str):
+            note = (
+                f'<p class="autodoc-generated">Generated '
+                f'<time datetime="{stamp}">{stamp}</time></p>\n'
+            )
+            content = content.replace("</main>", f"{note}</main>", 1)
+        elif page.path == "index.json" and isinstance(content, str):
+            data = json.loads(content)
+            data["generated_at"] = stamp
+            content = json.dumps(data, indent=2, sort_keys=True) + "\n"
+        stamped.append(SitePage(page.path, content))
+    return stamped
+
+
+# Written into every output directory; lists the files the run generated.
+SITE_FILES = ".autodoc-files"
+
+
+class SiteWriteError(Exception):
+    """Raised when the output directory cannot be replaced."""
+
+
+def generated_files(output_dir: str | Path) -> set[str]:
+    """Paths the previous :func:`write_site` generated below ``output_dir``."""
+    listing = Path(output_dir) / SITE_FILES
+    if not listing.is_file():
+        return set()
+    return set(listing.read_text(encoding="utf-8").split("\n")) - {""}
+
+
+def plan_site(pages: Sequence[SitePage], output_dir: str | Path) -> WritePlan:
+    """What :func:`write_site` would do with ``pages``, without writing."""
+    root = Path(output_dir)
+    plan = plan_writes((root / page.path, page.content) for page in pages)
+    stale = generated_files(root) - {page.path for page in pages}
+    plan.writes.extend(PlannedWrite(root / path, DELETE) for path in sorted(stale))
+    return plan
+
+
+def _check_replaceable(root: Path) -> None:
+    if root.exists() and not root.is_dir():
+        raise SiteWriteError(f"Output {root} exists and is not a directory")
+    cwd = Path.cwd().resolve()
+    if root.resolve() in (cwd, *cwd.parents):
+        raise SiteWriteError(
+            f"Output {root} contains the working directory; choose a subdirectory",
+        )
+
+
+def _write_page(root: Path, page: SitePage) -> None:
+    target = root / page.path
+    target.parent.mkdir(parents=True, exist_ok=True)
+    if isinstance(page.content, bytes):
+        target.write_bytes(page.content)
+    else:
+        target.write_text(page.content, encoding="utf-8")
+
+
+def _carry_over(previous: Path, staging: Path, skip: set[str]) -> int:
+    """Copy files from ``previous`` that no run generated; return the count."""
+    carried = 0
+    for path in sorted(previous.rglob("*")):
+        relative = path.relative_to(previous).as_posix()
+        if path.is_dir() or relative in skip or (staging / relative).exists():
+            continue
+        (staging / relative).parent.mkdir(parents=True, exist_ok=True)
+        shutil.copy2(path, staging / relative)
+        carried += 1
+    return carried
+
+
+def _swap(staging: Path, root: Path) -> None:
+    if not root.exists():
+        staging.rename(root)
+        return
+    retired = root.with_name(f".{root.name}.old-{uuid.uuid4().hex[:8]}")
+    root.rename(retired)
+    try:
+        staging.rename(root)
+    except OSError:
+        retired.rename(root)
+        raise
+    shutil.rmtree(retired, ignore_errors=True)
+
+
+def write_site(
+    pages: Sequence[SitePage],
+    output_dir: str | Path,
+    progress: Progress | None = None,
+) -> list[Path]:
+    """Replace ``output_dir`` with ``pages`` and return the written paths.
+
+    Each page written advances the ``publish`` stage of ``progress``, which
+    the caller starts with the pages of every directory it writes.
+
+    Raises:
+        SiteWriteError: If ``output_dir`` is a file, or the working directory
+            or one of its parents
+    """
+    root = Path(output_dir)
+    _check_replaceable(root)
+    root.parent.mkdir(parents=True, exist_ok=True)
+    staging = root.with_name(f".{root.name}.new-{uuid.uuid4().hex[:8]}")
+    staging.mkdir()
+    try:
+        for page in pages:
+            _write_page(staging, page)
+            if progress is not None:
+                progress.advance(PUBLISH)
+        paths = sorted(page.path for page in pages)
+        (staging / SITE_FILES).write_text("\n".join(paths) + "\n", encoding="utf-8")
+        if root.exists():
+            previous = generated_files(root)
+            carried = _carry_over(root, staging, previous | {SITE_FILES})
+            stale = previous - set(paths)
+            logger.info(
+                "Pruned %d stale file(s) from %s, kept %d added by hand",
+                len(stale),
+                root,
+                carried,
+                extra={"pruned": len(stale), "kept": carried},
+            )
+            for path in sorted(stale):
+                logger.debug("Pruned %s", root / path)
+        _swap(staging, root)
+    except BaseException:
+        shutil.rmtree(staging, ignore_errors=True)
+        raise
+    logger.info("Wrote %d page(s) to %s", len(pages), root)
+    return [root / page.path for page in pages]
+
+
+__all__ = [
+    "ARCHITECTURE_SLUG",
+    "ARCHITECTURE_TITLE",
+    "ASSETS_HEADING",
+    "BUILD_SLUG",
+    "BUILD_TITLE",
+    "DEPENDENCIES_SLUG",
+    "DEPENDENCIES_TITLE",
+    "GENERATION_SLUG",
+    "GENERATION_TITLE",
+    "GLOSSARY_SLUG",
+    "GLOSSARY_TITLE",
+    "GUIDES_HEADING",
+    "FUZZ_TARGETS_HEADING",
+    "NEWER_SYNTAX_HEADING",
+    "PLATFORMS_SLUG",
+    "PLATFORMS_TITLE",
+    "REPLACEMENTS_HEADING",
+    "RETRACTIONS_HEADING",
+    "RISKS_SLUG",
+    "RISKS_TITLE",
+    "SIDE_EFFECTS_HEADING",
+    "SITE_FILES",
+    "STATS_SLUG",
+    "STATS_TITLE",
+    "TEST_SUITE_TITLE",
+    "THIRD_PARTY_SLUG",
+    "THIRD_PARTY_TITLE",
+    "ClassDoc",
+    "ModuleDoc",
+    "PackageDoc",
+    "SitePage",
+    "SiteWriteError",
+    "attach_assets",
+    "attach_docstring_sections",
+    "attach_faq",
+    "attach_inherited_docs",
+    "attach_mock_links",
+    "attach_most_used",
+    "attach_namesakes",
+    "attach_none_safety",
+    "attach_placeholder_summaries",
+    "attach_pragmas",
+    "attach_quickstarts",
+    "attach_raises",
+    "attach_readmes",
+    "attach_side_effects",
+    "build_site_model",
+    "content_hash",
+    "generated_files",
+    "generation_time",
+    "map_docstrings",
+    "plan_site",
+    "signature",
+    "stamp_pages",
+    "summary",
+    "write_site",
+]
@@ -0,0 +1,93 @@
+"""``sitemap.xml``, ``robots.txt``, and canonical URLs for hosted HTML sites.
+
+Internal search engines find a documentation site through its sitemap. With
+``site.base_url`` set (see :class:`~autodoc.config.project.SitemapConfig`),
+every HTML page is listed under its public URL, and ``robots.txt`` points
+crawlers at the sitemap. ``index.html`` pages are listed by their directory
+URL, which is also their canonical URL.
+
+Nothing time-dependent is written, so the files are as byte-stable as the
+pages.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Iterable
+from fnmatch import fnmatch
+from urllib.parse import urlsplit
+from xml.sax.saxutils import escape
+
+from autodoc.config.project import SiteConfig
+from services.doc_site import SitePage
+
+SITEMAP_FILE = "sitemap.xml"
+ROBOTS_FILE = "robots.txt"
+SITEMAP_NAMESPACE = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+
+def page_url(path: str) -> str:
+    """The URL of page ``path`` relative to the site root."""
+    if path == "index.html":
+        return ""
+    if path.endswith("/index.html"):
+        return path[: -len("index.html")]
+    return path
+
+
+def canonical_url(base_url: str, path: str) -> str:
+    """The public URL of page ``path`` of a site served from ``base_url``."""
+    return base_url + page_url(path)
+
+
+def _excluded(path: str, patterns: list[str]) -> bool:
+    return any(fnmatch(path, pattern) for pattern in patterns)
+
+
+def render_sitemap(site: SiteConfig, paths: Iterable[str]) -> list[SitePage]:
+    """``sitemap.xml`` and ``robots.txt`` for the HTML pages at ``paths``.
+
+    Returns no files unless ``site.base_url`` is set and the sitemap is
+    enabled.
+    """
+    if site.base_url is None or not site.sitemap.enabled:
+        return []
+    patterns = site.sitemap.exclude
+    pages = sorted({path for path in paths if path.endswith(".html")})
+    urls = "".join(
+        f"  <url><loc>{escape(canonical_url(site.base_url, path))}</loc></url>\n"
+        for path in pages
+        if not _excluded(path, patterns)
+    )
+    files = [
+        SitePage(
+            SITEMAP_FILE,
+            '<?xml version="1.0" encoding="UTF-8"?>\n'
+            f'<urlset xmlns="{SITEMAP_NAMESPACE}">\n{urls}</urlset>\n',
+        ),
+    ]
+    if site.sitemap.robots:
+        prefix = urlsplit(site.base_url).path
+        disallow = [
+            f"Disallow: {prefix}{page_url(path)}\n"
+            for path in pages
+            if _excluded(path, patterns)
+        ]
+        files.append(
+            SitePage(
+                ROBOTS_FILE,
+                "User-agent: *\n"
+                + ("".join(disallow) or "Disallow:\n")
+                + f"\nSitemap: {site.base_url}{SITEMAP_FILE}\n",
+            ),
+        )
+    return files
+
+
+__all__ = [
+    "ROBOTS_FILE",
+    "SITEMAP_FILE",
+    "SITEMAP_NAMESPACE",
+    "canonical_url",
+    "page_url",
+    "render_sitemap",
+]
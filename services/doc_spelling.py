@@ -0,0 +1,375 @@
+"""Spelling and terminology lint rules over docstrings.
+
+:class:`SpellingRule` flags words from a list of common misspellings
+(:data:`MISSPELLINGS`), the approach codespell takes: a general dictionary
+would trip over every identifier, abbreviation, and product name in
+technical prose, while a known misspelling is almost never intended. The
+rule is on when ``autodoc.yaml`` has a ``lint.spelling`` section, whose
+project dictionary accepts words the list would flag and adds misspellings of
+its own::
+
+    lint:
+      spelling:
+        dictionary: docs/words.txt
+        words: [teh]
+        corrections: {serialise: serialize}
+
+:class:`TerminologyRule` enforces the project's preferred wording, one
+``lint.terminology`` entry per term::
+
+    lint:
+      terminology:
+        - prefer: user ID
+          avoid: [userid, user-id]
+
+Both rules skip inline code (``like this``), URLs, doctest lines, and ``::``
+code blocks, and report one :class:`~services.doc_lint.LintFinding` per
+symbol and word with the replacement as its ``suggestion``. The finding's
+symbol is ``"<qualified name>: <word>"``, so each word is baselined on its
+own.
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Iterable, Iterator, Mapping
+from dataclasses import dataclass
+from pathlib import Path
+
+from autodoc.config.project import TerminologyConfig
+from services.doc_lint import LintFinding
+from services.doc_symbols import DocSymbol, is_exported
+
+SPELLING_RULE_ID = "spelling"
+TERMINOLOGY_RULE_ID = "terminology"
+
+# Common misspellings and their corrections, lowercase.
+MISSPELLINGS = {
+    "accross": "across",
+    "acess": "access",
+    "accomodate": "accommodate",
+    "acheive": "achieve",
+    "adress": "address",
+    "agressive": "aggressive",
+    "allready": "already",
+    "alot": "a lot",
+    "alway": "always",
+    "ammount": "amount",
+    "anual": "annual",
+    "apparantly": "apparently",
+    "appearence": "appearance",
+    "arguement": "argument",
+    "asynchronus": "asynchronous",
+    "attribte": "attribute",
+    "availabe": "available",
+    "availible": "available",
+    "becasue": "because",
+    "beggining": "beginning",
+    "begining": "beginning",
+    "beleive": "believe",
+    "calback": "callback",
+    "calulate": "calculate",
+    "charactor": "character",
+    "comparision": "comparison",
+    "compatability": "compatibility",
+    "compatable": "compatible",
+    "completly": "completely",
+    "concious": "conscious",
+    "configuraiton": "configuration",
+    "conjuction": "conjunction",
+    "consistant": "consistent",
+    "containes": "contains",
+    "convertion": "conversion",
+    "correspondance": "correspondence",
+    "curent": "current",
+    "defintion": "definition",
+    "definately": "definitely",
+    "dependancy": "dependency",
+    "depricated": "deprecated",
+    "desireable": "desirable",
+    "determin": "determine",
+    "diffrent": "different",
+    "disapear": "disappear",
+    "documention": "documentation",
+    "doesnt": "doesn't",
+    "embarass": "embarrass",
+    "enviroment": "environment",
+    "equivelant": "equivalent",
+    "exection": "execution",
+    "existance": "existence",
+    "existant": "existent",
+    "explicitely": "explicitly",
+    "extention": "extension",
+    "familar": "familiar",
+    "finaly": "finally",
+    "foward": "forward",
+    "fucntion": "function",
+    "funtion": "function",
+    "garantee": "guarantee",
+    "gaurd": "guard",
+    "goverment": "government",
+    "grammer": "grammar",
+    "happend": "happened",
+    "heirarchy": "hierarchy",
+    "identifer": "identifier",
+    "immediatly": "immediately",
+    "implemention": "implementation",
+    "implmentation": "implementation",
+    "independant": "independent",
+    "infomation": "information",
+    "initalize": "initialize",
+    "instace": "instance",
+    "intial": "initial",
+    "isnt": "isn't",
+    "lenght": "length",
+    "libary": "library",
+    "maintainance": "maintenance",
+    "managment": "management",
+    "mesage": "message",
+    "millenium": "millennium",
+    "mispell": "misspell",
+    "neccessary": "necessary",
+    "necesary": "necessary",
+    "noticable": "noticeable",
+    "occassion": "occasion",
+    "occured": "occurred",
+    "occurence": "occurrence",
+    "occuring": "occurring",
+    "ommit": "omit",
+    "optionnal": "optional",
+    "overriden": "overridden",
+    "paramter": "parameter",
+    "parrallel": "parallel",
+    "particuler": "particular",
+    "performace": "performance",
+    "permanant": "permanent",
+    "persistant": "persistent",
+    "posible": "possible",
+    "preceed": "precede",
+    "prefered": "preferred",
+    "presense": "presence",
+    "previouly": "previously",
+    "priviledge": "privilege",
+    "probaly": "probably",
+    "proccess": "process",
+    "programatically": "programmatically",
+    "propery": "property",
+    "publically": "publicly",
+    "recieve": "receive",
+    "recieved": "received",
+    "recomend": "recommend",
+    "refered": "referred",
+    "relevent": "relevant",
+    "remaing": "remaining",
+    "repositry": "repository",
+    "responsability": "responsibility",
+    "retreive": "retrieve",
+    "retured": "returned",
+    "seperate": "separate",
+    "seperated": "separated",
+    "seperator": "separator",
+    "sequencial": "sequential",
+    "shoud": "should",
+    "similiar": "similar",
+    "sucess": "success",
+    "succesful": "successful",
+    "successfull": "successful",
+    "suport": "support",
+    "supress": "suppress",
+    "suprise": "surprise",
+    "syncronous": "synchronous",
+    "teh": "the",
+    "threshhold": "threshold",
+    "tommorow": "tomorrow",
+    "transfered": "transferred",
+    "truely": "truly",
+    "unecessary": "unnecessary",
+    "untill": "until",
+    "usefull": "useful",
+    "usualy": "usually",
+    "validaton": "validation",
+    "verison": "version",
+    "wether": "whether",
+    "wich": "which",
+    "writen": "written",
+}
+
+_WORD = re.compile(r"[A-Za-z]+(?:'[A-Za-z]+)?")
+_INLINE_CODE = re.compile(r"``.*?``|`[^`]*`|https?://\S+")
+
+
+def _indent(line: str) -> int:
+    return len(line) - len(line.lstrip())
+
+
+def prose(docstring: str | None) -> str:
+    """``docstring`` without inline code, URLs, doctests, and code blocks.
+
+    Code blocks are the indented lines after a line ending in ``::``. What
+    is left out is blanked rather than removed, so an offset into the result
+    is the same offset into ``docstring``.
+    """
+    lines = []
+    block_indent = None
+    for line in (docstring or "").split("\n"):
+        if block_indent is not None:
+            if not line.strip() or _indent(line) > block_indent:
+                lines.append(" " * len(line))
+                continue
+            block_indent = None
+        if line.lstrip().startswith((">>>", "...")):
+            lines.append(" " * len(line))
+            continue
+        if line.rstrip().endswith("::"):
+            block_indent = _indent(line)
+        lines.append(_INLINE_CODE.sub(lambda m: " " * len(m.group()), line))
+    return "\n".join(lines)
+
+
+def _match_case(word: str, fix: str) -> str:
+    if word.isupper() and len(word) > 1:
+        return fix.upper()
+    if word[0].isupper():
+        return fix[0].upper() + fix[1:]
+    return fix
+
+
+def load_dictionary(path: str | Path) -> list[str]:
+    """The words of a project dictionary file, one per line.
+
+    Blank lines and lines starting with ``#`` are skipped.
+
+    Raises:
+        OSError: If the file cannot be read
+    """
+    words = []
+    for line in Path(path).read_text(encoding="utf-8").splitlines():
+        line = line.strip()
+        if line and not line.startswith("#"):
+            words.append(line)
+    return words
+
+
+def _finding(
+    rule: str,
+    symbol: DocSymbol,
+    word: str,
+    message: str,
+    suggestion: str,
+    severity: str,
+) -> LintFinding:
+    return LintFinding(
+        rule=rule,
+        message=message,
+        file_path=symbol.file_path,
+        lineno=symbol.lineno,
+        symbol=f"{symbol.qualified_name}: {word}",
+        severity=severity,
+        suggestion=suggestion,
+    )
+
+
+@dataclass(frozen=True)
+class SpellingRule:
+    """Docstrings of exported symbols must not contain known misspellings."""
+
+    id = SPELLING_RULE_ID
+    description = "Docstrings must not contain common misspellings"
+
+    corrections: Mapping[str, str]
+    severity: str = "warning"
+
+    @classmethod
+    def from_words(
+        cls,
+        accepted: Iterable[str] = (),
+        corrections: Mapping[str, str] | None = None,
+    ) -> SpellingRule:
+        """The built-in list plus ``corrections``, without ``accepted`` words."""
+        merged = dict(MISSPELLINGS)
+        merged.update((k.lower(), v) for k, v in (corrections or {}).items())
+        for word in accepted:
+            merged.pop(word.lower(), None)
+        return cls(merged)
+
+    def check(self, symbol: DocSymbol) -> Iterator[LintFinding]:
+        if not is_exported(symbol) or not symbol.is_documented:
+            return
+        seen = set()
+        for match in _WORD.finditer(prose(symbol.docstring)):
+            word = match.group()
+            fix = self.corrections.get(word.lower())
+            if fix is None or word.lower() in seen:
+                continue
+            seen.add(word.lower())
+            fix = _match_case(word, fix)
+            yield _finding(
+                self.id,
+                symbol,
+                word,
+                f"{symbol.kind} {symbol.qualified_name}: {word!r} is misspelled, "
+                f"use {fix!r}",
+                fix,
+                self.severity,
+            )
+
+
+def _avoid_pattern(term: str) -> re.Pattern[str]:
+    words = [re.escape(word) for word in term.split()]
+    return re.compile(r"\b" + r"\s+".join(words) + r"\b", re.IGNORECASE)
+
+
+@dataclass(frozen=True)
+class TerminologyRule:
+    """Docstrings of exported symbols must use the preferred terms."""
+
+    id = TERMINOLOGY_RULE_ID
+    description = "Docstrings must use the project's preferred terminology"
+
+    terms: tuple[tuple[str, tuple[re.Pattern[str], ...]], ...]
+    severity: str = "warning"
+
+    @classmethod
+    def from_config(cls, entries: Iterable[TerminologyConfig]) -> TerminologyRule:
+        """The rule enforcing the ``lint.terminology`` ``entries``."""
+        return cls(
+            tuple(
+                (entry.prefer, tuple(_avoid_pattern(term) for term in entry.avoid))
+                for entry in entries
+            ),
+        )
+
+    def check(self, symbol: DocSymbol) -> Iterator[LintFinding]:
+        if not is_exported(symbol) or not symbol.is_documented:
+            return
+        text = prose(symbol.docstring)
+        seen = set()
+        for prefer, patterns in self.terms:
+            for pattern in patterns:
+                for match in pattern.finditer(text):
+                    used = match.group()
+                    if used in (prefer, _match_case(used, prefer)):
+                        continue
+                    if used.lower() in seen:
+                        continue
+                    seen.add(used.lower())
+                    yield _finding(
+                        self.id,
+                        symbol,
+                        used,
+                        f"{symbol.kind} {symbol.qualified_name}: "
+                        f"write {prefer!r} instead of {used!r}",
+                        prefer,
+                        self.severity,
+                    )
+
+
+__all__ = [
+    "MISSPELLINGS",
+    "SPELLING_RULE_ID",
+    "TERMINOLOGY_RULE_ID",
+    "SpellingRule",
+    "TerminologyRule",
+    "load_dictionary",
+    "prose",
+]
@@ -0,0 +1,593 @@
+"""Documentation symbol model for Python source trees.
+
+This module walks a source tree, parses each Python file with the analyzer
+parser/extractor pair, and flattens the result into :class:`DocSymbol` records
+grouped by package. The documentation coverage, lint, and rendering services
+all consume this model so they agree on what counts as a public symbol.
+
+``DocSymbol.file_path`` is the path the file was loaded from, so it can be
+opened directly. Output meant to be committed or compared across machines
+uses :func:`root_relative`, which rewrites paths relative to the source root.
+"""
+
+from __future__ import annotations
+
+import ast
+import logging
+import os
+import time
+from collections.abc import Iterable, Sequence
+from dataclasses import dataclass, field, replace
+from pathlib import Path
+from typing import Any
+
+from services.cancellation import CancelToken
+from services.ignore_file import IGNORE_FILE, IgnoreFile, load_ignore_file
+from services.progress import PARSE, Progress
+from services.symbol_spool import SymbolSpool
+from services.telemetry import count, span
+from src.analyzer.extractor import (
+    ClassInfo,
+    FunctionInfo,
+    ModuleInfo,
+    SymbolExtractor,
+)
+from src.analyzer.parser import PythonParser, parse_python_code
+
+logger = logging.getLogger(__name__)
+
+
+class _ParserLog(logging.LoggerAdapter):
+    """Demotes the parser's per-file records to DEBUG.
+
+    The loader reports unparseable files itself, once, as a warning.
+    """
+
+    def log(self, level: int, msg: object, *args: Any, **kwargs: Any) -> None:
+        super().log(logging.DEBUG, msg, *args, **kwargs)
+
+# Directories that never contain first-party source worth documenting.
+DEFAULT_EXCLUDED_DIRS = frozenset(
+    {
+        ".git",
+        ".hg",
+        ".mypy_cache",
+        ".pytest_cache",
+        ".ruff_cache",
+        ".tox",
+        ".venv",
+        "__pycache__",
+        "build",
+        "dist",
+        "node_modules",
+        "venv",
+    },
+)
+
+# Files that mark a directory as the root of a separate Python project.
+NESTED_PROJECT_MARKERS = ("pyproject.toml", "setup.py", "setup.cfg")
+
+
+# Larger Python files are almost always generated; parsing them is slow and
+# memory hungry, so they are skipped unless the limit is raised.
+DEFAULT_MAX_FILE_SIZE = 1024 * 1024
+
+# How much of a file is sniffed for NUL bytes (git uses the same window).
+BINARY_SNIFF_BYTES = 8000
+
+
+@dataclass(frozen=True)
+class WalkOptions:
+    """How :func:`discover_python_files` selects files.
+
+    The defaults keep the walk inside the tree being documented: symlinked
+    files and directories are skipped, and so is any subdirectory that is a
+    project of its own (it contains one of :data:`NESTED_PROJECT_MARKERS`).
+    Files larger than ``max_file_size`` bytes (``None`` for no limit) are
+    skipped too.
+    """
+
+    follow_symlinks: bool = False
+    nested_projects: bool = False
+    max_file_size: int | None = DEFAULT_MAX_FILE_SIZE
+
+
+def format_size(size: int) -> str:
+    """Human-readable ``size`` in bytes, e.g. ``1.5 MiB``."""
+    value = float(size)
+    for unit in ("B", "KiB", "MiB"):
+        if value < 1024:
+            return f"{value:.0f} {unit}" if unit == "B" else f"{value:.1f} {unit}"
+        value /= 1024
+    return f"{value:.1f} GiB"
+
+
+def unreadable_reason(path: Path, max_file_size: int | None) -> str | None:
+    """Why ``path`` should not be parsed, or None if it is fine.
+
+    A file is refused when it exceeds ``max_file_size`` or when its first
+    :data:`BINARY_SNIFF_BYTES` contain a NUL byte (Python source never does).
+    """
+    try:
+        size = path.stat().st_size
+        if max_file_size is not None and size > max_file_size:
+            return (
+                f"{format_size(size)} exceeds the {format_size(max_file_size)} "
+                "limit (raise it with --max-file-size)"
+            )
+        with path.open("rb") as handle:
+            head = handle.read(BINARY_SNIFF_BYTES)
+    except OSError as exc:
+        return f"cannot read file: {exc.strerror or exc}"
+    if b"\0" in head:
+        return "contains NUL bytes; not a Python source file"
+    return None
+
+
+@dataclass
+class DocSymbol:
+    """A documentable symbol (module, class, function, or method)."""
+
+    package: str
+    name: str
+    qualified_name: str
+    kind: str  # 'module', 'class', 'function', 'method'
+    file_path: str
+    lineno: int
+    end_lineno: int = 0
+    docstring: str | None = None
+    is_public: bool = True
+    parent: str | None = None
+    metadata: dict[str, Any] = field(default_factory=dict)
+
+    @property
+    def is_documented(self) -> bool:
+        """Whether the symbol carries a non-empty docstring."""
+        return bool(self.docstring and self.docstring.strip())
+
+    def with_docstring(self, docstring: str | None) -> DocSymbol:
+        """A copy documented by ``docstring``, in its metadata too."""
+        metadata = self.metadata
+        if "docstring" in metadata:
+            metadata = {**metadata, "docstring": docstring}
+        return replace(self, docstring=docstring, metadata=metadata)
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "package": self.package,
+            "name": self.name,
+            "qualified_name": self.qualified_name,
+            "kind": self.kind,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "end_lineno": self.end_lineno,
+            "docstring": self.docstring,
+            "is_public": self.is_public,
+            "parent": self.parent,
+            "metadata": self.metadata,
+        }
+
+
+def discover_python_files(
+    root: str | Path,
+    excluded_dirs: Iterable[str] = DEFAULT_EXCLUDED_DIRS,
+    ignore: IgnoreFile | None = None,
+    walk: WalkOptions | None = None,
+) -> list[Path]:
+    """Return all Python files below ``root`` in a stable, sorted order.
+
+    Oversized and binary files are skipped with a warning (see
+    :func:`unreadable_reason`). Paths are reported below ``root`` even when
+    reached through a symlink. With ``walk.follow_symlinks`` each directory is
+    visited once, so symlink cycles terminate.
+
+    Args:
+        root: Directory to walk (a single file is returned as-is)
+        excluded_dirs: Directory names that are never descended into
+        ignore: Paths to skip (default: ``<root>/.autodocignore``)
+        walk: Symlink and nested-project handling (default: :class:`WalkOptions`)
+
+    Returns:
+        Sorted list of Python file paths
+    """
+    root_path = Path(root)
+    if root_path.is_file():
+        return [root_path] if root_path.suffix == ".py" else []
+
+    excluded = set(excluded_dirs)
+    if ignore is None:
+        ignore = load_ignore_file(root_path)
+    walk = walk or WalkOptions()
+    files: list[Path] = []
+    visited: set[str] = set()
+    for dirpath, dirnames, filenames in os.walk(
+        root_path,
+        followlinks=walk.follow_symlinks,
+    ):
+        current = Path(dirpath)
+        real = os.path.realpath(current)
+        if real in visited:
+            logger.debug("Skipping %s: already visited as %s", current, real)
+            dirnames.clear()
+            continue
+        visited.add(real)
+
+        relative_dir = current.relative_to(root_path)
+        dirnames[:] = [
+            name
+            for name in sorted(dirnames)
+            if name not in excluded
+            and _descend(current / name, relative_dir / name, ignore, walk)
+        ]
+        for name in filenames:
+            path = current / name
+            if path.suffix != ".py":
+                continue
+            if path.is_symlink() and not walk.follow_symlinks:
+                logger.debug("Skipping symlink %s", path)
+                continue
+            if ignore and ignore.ignores((relative_dir / name).as_posix()):
+                logger.debug("Ignoring %s (matched %s)", path, IGNORE_FILE)
+                continue
+            reason = unreadable_reason(path, walk.max_file_size)
+            if reason is not None:
+                logger.warning("Skipping %s: %s", path, reason)
+                continue
+            files.append(path)
+    logger.info(
+        "Found %d Python file(s) below %s",
+        len(files),
+        root_path,
+        extra={"root": str(root_path), "files": len(files)},
+    )
+    return sorted(files)
+
+
+def _descend(
+    directory: Path,
+    relative: Path,
+    ignore: IgnoreFile,
+    walk: WalkOptions,
+) -> bool:
+    """Whether the walk should enter ``directory``."""
+    if ignore and ignore.ignores(relative.as_posix(), is_dir=True):
+        return False
+    if directory.is_symlink() and not walk.follow_symlinks:
+        logger.debug("Skipping symlinked directory %s", directory)
+        return False
+    if not walk.nested_projects and any(
+        (directory / marker).is_file() for marker in NESTED_PROJECT_MARKERS
+    ):
+        logger.debug("Skipping nested project %s", directory)
+        return False
+    return True
+
+
+def _relative_to(path: Path, root: Path) -> Path:
+    """``path`` below ``root``, lexically first so symlinked paths stay put.
+
+    Raises:
+        ValueError: If ``path`` is not below ``root`` either way
+    """
+    try:
+        return Path(os.path.abspath(path)).relative_to(os.path.abspath(root))
+    except ValueError:
+        return path.resolve().relative_to(root.resolve())
+
+
+def relative_path(path: str | Path, root: str | Path) -> str:
+    """``path`` relative to ``root`` in POSIX form, or as given if outside it."""
+    try:
+        return _relative_to(Path(path), Path(root)).as_posix()
+    except ValueError:
+        return Path(path).as_posix()
+
+
+def root_relative(
+    symbols: Iterable[DocSymbol],
+    root: str | Path,
+) -> Sequence[DocSymbol]:
+    """Copies of ``symbols`` with ``file_path`` relative to ``root``.
+
+    Findings, baselines, and JSON output built from the copies are identical
+    whether the tool ran with ``--root .``, an absolute root, or on another
+    machine. Copies of a :class:`SymbolSpool` are spooled the same way.
+    """
+    copies = (
+        replace(symbol, file_path=relative_path(symbol.file_path, root))
+        for symbol in symbols
+    )
+    if isinstance(symbols, SymbolSpool):
+        spool = SymbolSpool(symbols.max_memory)
+        spool.extend(copies)
+        return spool
+    return list(copies)
+
+
+def _anchor(file_path: str | Path, root: Path) -> Path:
+    path = Path(file_path)
+    return path if path.is_absolute() else root / path
+
+
+def module_name_for(file_path: str | Path, root: str | Path) -> str:
+    """Build the dotted module name for ``file_path`` relative to ``root``.
+
+    Relative paths are interpreted relative to ``root``.
+    """
+    root_path = Path(root)
+    path = _anchor(file_path, root_path)
+    try:
+        relative = _relative_to(path, root_path)
+    except ValueError:
+        relative = Path(path.name)
+
+    parts = list(relative.with_suffix("").parts)
+    if parts and parts[-1] == "__init__":
+        parts.pop()
+    if not parts:
+        return root_path.resolve().name
+    return ".".join(parts)
+
+
+def package_name_for(file_path: str | Path, root: str | Path) -> str:
+    """Return the package (directory) a file belongs to, as a dotted name."""
+    root_path = Path(root)
+    path = _anchor(file_path, root_path)
+    try:
+        relative_dir = _relative_to(path, root_path).parent
+    except ValueError:
+        relative_dir = Path()
+
+    if not relative_dir.parts:
+        return root_path.resolve().name
+    return ".".join(relative_dir.parts)
+
+
+def is_exported(symbol: DocSymbol) -> bool:
+    """Whether a symbol is part of the documented public surface.
+
+    Dunder methods are public by Python convention but are not expected to
+    carry their own docstrings, so they are excluded from the surface.
+    """
+    if not symbol.is_public:
+        return False
+    return not (
+        symbol.kind == "method"
+        and symbol.name.startswith("__")
+        and symbol.name.endswith("__")
+    )
+
+
+class DocSymbolLoader:
+    """Parse Python files and flatten them into :class:`DocSymbol` records."""
+
+    def __init__(
+        self,
+        root: str | Path,
+        parser: PythonParser | None = None,
+        extractor: SymbolExtractor | None = None,
+        walk: WalkOptions | None = None,
+        max_memory: int | None = None,
+    ) -> None:
+        self.root = Path(root)
+        self.walk = walk
+        # Past this many bytes of symbols, load() spills them to disk.
+        self.max_memory = max_memory
+        self._parser = parser or PythonParser(logger=_ParserLog(logger, {}))
+        self._extractor = extractor or SymbolExtractor()
+
+    def load(
+        self,
+        files: Sequence[str | Path] | None = None,
+        cancel: CancelToken | None = None,
+        progress: Progress | None = None,
+    ) -> Sequence[DocSymbol]:
+        """Load symbols for ``files`` (or every Python file below the root).
+
+        Once ``cancel`` is cancelled the remaining files are skipped and
+        counted on the token; the symbols loaded so far are returned. With
+        ``max_memory`` they come back as a :class:`SymbolSpool`, otherwise
+        as a list. Each file read advances the ``parse`` stage of
+        ``progress``.
+        """
+        if files is None:
+            targets = discover_python_files(self.root, walk=self.walk)
+        else:
+            targets = files
+        started = time.perf_counter()
+        symbols: list[DocSymbol] | SymbolSpool = []
+        if self.max_memory:
+            symbols = SymbolSpool(self.max_memory)
+        parsed = 0
+        if progress is not None:
+            progress.start(PARSE, len(targets))
+        with span("autodoc.parse") as current:
+            for index, file_path in enumerate(targets):
+                if cancel is not None and cancel.cancelled:
+                    cancel.skip("parse", len(targets) - index)
+                    break
+                symbols.extend(self.load_file(file_path))
+                parsed += 1
+                if progress is not None:
+                    progress.advance(PARSE)
+            current.set(files=parsed, symbols=len(symbols))
+        if progress is not None:
+            progress.finish(PARSE)
+        count("autodoc.files.parsed", parsed)
+        elapsed = time.perf_counter() - started
+        logger.info(
+            "Parsed %d file(s) into %d symbol(s) in %.2fs",
+            parsed,
+            len(symbols),
+            elapsed,
+            extra={"files": parsed, "symbols": len(symbols), "seconds": elapsed},
+        )
+        return symbols
+
+    def load_file(self, file_path: str | Path) -> list[DocSymbol]:
+        """Load symbols from a single file; unparseable files yield nothing."""
+        parse_result = self._parser.parse(str(file_path))
+        if not parse_result.success or parse_result.ast_tree is None:
+            logger.warning(
+                "Skipping %s: %s",
+                file_path,
+                parse_result.error,
+            )
+            return []
+
+        module_info = self._extractor.extract(
+            parse_result.ast_tree,
+            parse_result.file_path,
+        )
+        return self._module_symbols(
+            module_info,
+            file_path,
+            location=Path(os.path.abspath(file_path)),
+        )
+
+    def load_source(self, source: str, file_path: str | Path) -> list[DocSymbol]:
+        """Load symbols from in-memory ``source`` attributed to ``file_path``.
+
+        Used when the content to check is not the working-tree file, e.g. the
+        staged blob of a file in a pre-commit hook. A relative ``file_path``
+        is interpreted relative to the loader root.
+        """
+        try:
+            tree = parse_python_code(source, filename=str(file_path))
+        except SyntaxError as exc:
+            logger.warning("Skipping %s: syntax error: %s", file_path, exc.msg)
+            return []
+        return self.load_tree(tree, file_path)
+
+    def load_tree(self, tree: ast.Module, file_path: str | Path) -> list[DocSymbol]:
+        """Load symbols from an already-parsed module (see :meth:`load_source`)."""
+        module_info = self._extractor.extract(tree, str(file_path))
+        return self._module_symbols(
+            module_info,
+            file_path,
+            location=_anchor(file_path, self.root),
+        )
+
+    def _module_symbols(
+        self,
+        module_info: ModuleInfo,
+        file_path: str | Path,
+        location: Path,
+    ) -> list[DocSymbol]:
+        package = package_name_for(location, self.root)
+        module = module_name_for(location, self.root)
+        display_path = str(file_path)
+
+        symbols = [
+            DocSymbol(
+                package=package,
+                name=module.rsplit(".", 1)[-1],
+                qualified_name=module,
+                kind="module",
+                file_path=display_path,
+                lineno=1,
+                end_lineno=1,
+                docstring=module_info.module_docstring,
+                is_public=not Path(file_path).stem.startswith("_")
+                or Path(file_path).stem == "__init__",
+            ),
+        ]
+
+        for func in module_info.functions:
+            symbols.append(
+                self._function_symbol(package, module, display_path, func),
+            )
+
+        for cls in module_info.classes:
+            symbols.append(self._class_symbol(package, module, display_path, cls))
+            class_name = f"{module}.{cls.name}"
+            for method in cls.methods:
+                method_symbol = self._function_symbol(
+                    package,
+                    class_name,
+                    display_path,
+                    method,
+                    kind="method",
+                )
+                method_symbol.is_public = method.is_public and cls.is_public
+                symbols.append(method_symbol)
+
+        return symbols
+
+    @staticmethod
+    def _function_symbol(
+        package: str,
+        parent: str,
+        file_path: str,
+        func: FunctionInfo,
+        kind: str = "function",
+    ) -> DocSymbol:
+        return DocSymbol(
+            package=package,
+            name=func.name,
+            qualified_name=f"{parent}.{func.name}",
+            kind=kind,
+            file_path=file_path,
+            lineno=func.lineno,
+            end_lineno=func.end_lineno or func.lineno,
+            docstring=func.docstring,
+            is_public=func.is_public,
+            parent=parent,
+            metadata=func.to_dict(),
+        )
+
+    @staticmethod
+    def _class_symbol(
+        package: str,
+        module: str,
+        file_path: str,
+        cls: ClassInfo,
+    ) -> DocSymbol:
+        metadata = cls.to_dict()
+        metadata.pop("methods", None)
+        return DocSymbol(
+            package=package,
+            name=cls.name,
+            qualified_name=f"{module}.{cls.name}",
+            kind="class",
+            file_path=file_path,
+            lineno=cls.lineno,
+            end_lineno=cls.end_lineno or cls.lineno,
+            docstring=cls.docstring,
+            is_public=cls.is_public,
+            parent=module,
+            metadata=metadata,
+        )
+
+
+def load_doc_symbols(
+    root: str | Path,
+    files: Sequence[str | Path] | None = None,
+    walk: WalkOptions | None = None,
+    cancel: CancelToken | None = None,
+    max_memory: int | None = None,
+    progress: Progress | None = None,
+) -> Sequence[DocSymbol]:
+    """Convenience wrapper around :class:`DocSymbolLoader`."""
+    loader = DocSymbolLoader(root, walk=walk, max_memory=max_memory)
+    return loader.load(files, cancel=cancel, progress=progress)
+
+
+__all__ = [
+    "BINARY_SNIFF_BYTES",
+    "DEFAULT_EXCLUDED_DIRS",
+    "DEFAULT_MAX_FILE_SIZE",
+    "DocSymbol",
+    "DocSymbolLoader",
+    "NESTED_PROJECT_MARKERS",
+    "WalkOptions",
+    "discover_python_files",
+    "format_size",
+    "is_exported",
+    "load_doc_symbols",
+    "module_name_for",
+    "package_name_for",
+    "relative_path",
+    "root_relative",
+    "unreadable_reason",
+]
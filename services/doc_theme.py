@@ -0,0 +1,191 @@
+"""Themes for HTML documentation output.
+
+A theme is configured in the ``site.theme`` section of ``autodoc.yaml`` (see
+:class:`~autodoc.config.project.ThemeConfig`). This module turns it into the
+stylesheet and asset files shipped with the site. Colours are exposed as CSS
+custom properties so custom stylesheets can restyle the site without
+overriding individual selectors::
+
+    :root { --autodoc-primary: #d4351c; }
+
+Custom stylesheets load after the built-in one, so their rules win.
+
+The default colours meet the WCAG AA contrast ratio of 4.5:1 for text on both
+palettes; the default brand colours are swapped for lighter shades on the dark
+one. ``autodoc generate --audit`` checks custom colours and stylesheets (see
+:mod:`services.doc_accessibility`).
+"""
+
+from __future__ import annotations
+
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from autodoc.config.project import ThemeConfig
+from services.doc_site import SitePage
+
+ASSETS_DIR = "assets"
+STYLESHEET_PATH = f"{ASSETS_DIR}/autodoc.css"
+
+_PALETTES = {
+    "light": {
+        "--autodoc-bg": "#ffffff",
+        "--autodoc-fg": "#1f2328",
+        "--autodoc-muted": "#656d76",
+        "--autodoc-border": "#d0d7de",
+        "--autodoc-code-bg": "#f6f8fa",
+    },
+    "dark": {
+        "--autodoc-bg": "#0d1117",
+        "--autodoc-fg": "#e6edf3",
+        "--autodoc-muted": "#8d96a0",
+        "--autodoc-border": "#30363d",
+        "--autodoc-code-bg": "#161b22",
+    },
+}
+
+# Lighter shades of the default brand colours, readable on the dark palette.
+_DARK_BRAND = {"#0969da": "#4493f8", "#8250df": "#ab7df8"}
+
+_BASE_CSS = """\
+body {
+  margin: 0;
+  background: var(--autodoc-bg);
+  color: var(--autodoc-fg);
+  font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif;
+  line-height: 1.5;
+}
+a { color: var(--autodoc-primary); }
+:focus-visible { outline: 2px solid var(--autodoc-primary); outline-offset: 2px; }
+a.autodoc-skip {
+  position: absolute;
+  left: 1rem;
+  top: -3rem;
+  padding: 0.5rem 1rem;
+  background: var(--autodoc-bg);
+  z-index: 1;
+}
+a.autodoc-skip:focus { top: 0.5rem; }
+header.autodoc-header {
+  display: flex;
+  align-items: center;
+  gap: 0.75rem;
+  padding: 0.75rem 1.5rem;
+  border-bottom: 3px solid var(--autodoc-primary);
+}
+header.autodoc-header img.autodoc-logo { height: 2rem; }
+header.autodoc-header a.autodoc-title { color: inherit; font-weight: 600; text-decoration: none; }
+nav.autodoc-nav { padding: 0.5rem 1.5rem; border-bottom: 1px solid var(--autodoc-border); }
+main.autodoc-main { max-width: 60rem; padding: 1rem 1.5rem 3rem; }
+main.autodoc-main:focus { outline: none; }
+nav.autodoc-toc ul { padding-left: 1.25rem; }
+nav.autodoc-toc a { display: inline-block; padding: 0.125rem 0; }
+footer.autodoc-footer { padding: 1rem 1.5rem; color: var(--autodoc-muted); font-size: 0.875rem; }
+section.autodoc-symbol { border-top: 1px solid var(--autodoc-border); padding-top: 0.5rem; }
+h2, h3, h4 { scroll-margin-top: 1rem; }
+.autodoc-kind { color: var(--autodoc-accent); font-size: 0.75rem; text-transform: uppercase; }
+pre, code { background: var(--autodoc-code-bg); border-radius: 6px; }
+pre { padding: 0.75rem; overflow-x: auto; }
+pre.autodoc-signature { border-left: 3px solid var(--autodoc-accent); }
+a.autodoc-edit { font-size: 0.75rem; font-weight: normal; margin-left: 0.5rem; }
+.autodoc-undocumented { color: var(--autodoc-muted); font-style: italic; }
+dl.autodoc-glossary dt { font-weight: 600; margin-top: 0.75rem; }
+svg.autodoc-chart { display: block; max-width: 100%; height: auto; margin: 0.5rem 0; }
+svg.autodoc-chart rect { fill: var(--autodoc-primary); }
+svg.autodoc-chart text { fill: var(--autodoc-fg); font-size: 12px; }
+figure.autodoc-figure { margin: 1rem 0; }
+figure.autodoc-figure img { max-width: 100%; height: auto; }
+"""
+
+
+class ThemeError(Exception):
+    """Raised when a theme references files that cannot be read."""
+
+
+@dataclass
+class ThemeAssets:
+    """Asset files produced for a theme, plus the paths pages must link."""
+
+    pages: list[SitePage] = field(default_factory=list)
+    stylesheets: list[str] = field(default_factory=list)
+    logo: str | None = None
+
+
+def _variables(values: dict[str, str]) -> str:
+    return "".join(f"  {name}: {value};\n" for name, value in values.items())
+
+
+def palette_colors(theme: ThemeConfig) -> dict[str, dict[str, str]]:
+    """The colour variables of each palette ``theme`` uses, by palette name."""
+    modes = ("light", "dark") if theme.mode == "auto" else (theme.mode,)
+    palettes = {}
+    for mode in modes:
+        brand = {
+            "--autodoc-primary": theme.primary_color,
+            "--autodoc-accent": theme.accent_color,
+        }
+        if mode == "dark":
+            brand = {name: _DARK_BRAND.get(c, c) for name, c in brand.items()}
+        palettes[mode] = {**_PALETTES[mode], **brand}
+    return palettes
+
+
+def stylesheet(theme: ThemeConfig) -> str:
+    """Render the built-in stylesheet for ``theme``."""
+    palettes = palette_colors(theme)
+    if theme.mode == "auto":
+        light, dark = palettes["light"], palettes["dark"]
+        palette = (
+            f":root {{\n{_variables(light)}}}\n"
+            "@media (prefers-color-scheme: dark) {\n"
+            f":root {{\n{_variables(dark)}}}\n"
+            "}\n"
+        )
+    else:
+        palette = f":root {{\n{_variables(palettes[theme.mode])}}}\n"
+    return palette + _BASE_CSS
+
+
+def _read_asset(base_dir: Path, relative: str) -> bytes:
+    path = base_dir / relative
+    try:
+        return path.read_bytes()
+    except OSError as exc:
+        raise ThemeError(f"Cannot read theme asset {path}: {exc}") from exc
+
+
+def build_theme_assets(theme: ThemeConfig, base_dir: str | Path) -> ThemeAssets:
+    """Collect the stylesheet, logo, and custom CSS files for ``theme``.
+
+    Args:
+        theme: Theme configuration
+        base_dir: Directory that ``logo`` and ``custom_css`` are relative to
+
+    Raises:
+        ThemeError: If the logo or a custom stylesheet cannot be read
+    """
+    base = Path(base_dir)
+    assets = ThemeAssets(
+        pages=[SitePage(STYLESHEET_PATH, stylesheet(theme))],
+        stylesheets=[STYLESHEET_PATH],
+    )
+    if theme.logo:
+        logo_path = f"{ASSETS_DIR}/logo{Path(theme.logo).suffix}"
+        assets.pages.append(SitePage(logo_path, _read_asset(base, theme.logo)))
+        assets.logo = logo_path
+    for index, css in enumerate(theme.custom_css, start=1):
+        css_path = f"{ASSETS_DIR}/custom-{index}.css"
+        assets.pages.append(SitePage(css_path, _read_asset(base, css)))
+        assets.stylesheets.append(css_path)
+    return assets
+
+
+__all__ = [
+    "ASSETS_DIR",
+    "STYLESHEET_PATH",
+    "ThemeAssets",
+    "ThemeError",
+    "build_theme_assets",
+    "palette_colors",
+    "stylesheet",
+]
@@ -0,0 +1,430 @@
+"""Translation catalogs of docstrings.
+
+Docstrings are translated in gettext catalogs, so teams can use any PO
+editor or translation platform. :func:`extract_messages` turns the
+documented symbols of a site into a template (``autodoc.pot``), one message
+per docstring::
+
+    #. source-hash: 3f2a9c1b7e04
+    #: billing/invoice.py:12
+    msgctxt "billing.invoice.Invoice"
+    msgid "A bill sent to a customer."
+    msgstr ""
+
+The symbol's qualified name is the message context, so two symbols with the
+same docstring are translated separately and a message follows its symbol
+when the text changes. The source hash fingerprints the text a translation
+was made from; it survives PO editors re-wrapping ``msgid``.
+
+:func:`merge_catalog` brings a ``<language>.po`` catalog up to date with a
+fresh template: new symbols get empty messages, removed ones are dropped,
+and translations of changed docstrings are kept but marked ``fuzzy``, with
+the text they were made from as the previous ``msgid`` (``#|``). When a
+site is generated in a language, :func:`localize` swaps in the
+translations that are current; fuzzy, empty, and stale ones fall back to
+the source docstring.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import logging
+import re
+from collections.abc import Iterable
+from dataclasses import dataclass, field, replace
+from pathlib import Path
+from typing import Any
+
+from autodoc.config.project import LANGUAGE_CODE
+from services.doc_site import PackageDoc, map_docstrings
+from services.doc_symbols import DocSymbol
+from services.import_graph import relative_path
+
+logger = logging.getLogger(__name__)
+
+TEMPLATE_FILE = "autodoc.pot"
+HASH_COMMENT = "source-hash:"
+
+_ESCAPES = {"\\": "\\\\", '"': '\\"', "\n": "\\n", "\t": "\\t", "\r": "\\r"}
+_UNESCAPES = {"\\": "\\", '"': '"', "n": "\n", "t": "\t", "r": "\r"}
+_STRING = re.compile(r'^"((?:[^"\\]|\\.)*)"$')
+_KEYWORD = re.compile(r"^(msgctxt|msgid|msgstr)\s+(\".*\")$")
+
+
+class TranslationError(Exception):
+    """Raised when a catalog cannot be read or is malformed."""
+
+
+def source_hash(text: str) -> str:
+    """Short fingerprint of the source ``text`` of a message."""
+    return hashlib.sha256(text.encode("utf-8")).hexdigest()[:12]
+
+
+@dataclass
+class Message:
+    """One translatable docstring."""
+
+    symbol: str
+    source: str
+    translation: str = ""
+    source_hash: str = ""
+    fuzzy: bool = False
+    # Source text the fuzzy translation was made from.
+    previous: str | None = None
+    references: list[str] = field(default_factory=list)
+    # Translator comments (``# ...``), kept across merges.
+    comments: list[str] = field(default_factory=list)
+
+    def __post_init__(self) -> None:
+        if not self.source_hash:
+            self.source_hash = source_hash(self.source)
+
+    @property
+    def is_translated(self) -> bool:
+        return bool(self.translation) and not self.fuzzy
+
+
+@dataclass
+class Catalog:
+    """The messages of a template (no language) or a ``<language>.po`` file."""
+
+    language: str | None = None
+    messages: list[Message] = field(default_factory=list)
+
+    def by_symbol(self) -> dict[str, Message]:
+        return {message.symbol: message for message in self.messages}
+
+    def status(self) -> dict[str, Any]:
+        """Message counts: ``total``, ``translated``, ``fuzzy``, ``untranslated``."""
+        fuzzy = sum(1 for m in self.messages if m.fuzzy and m.translation)
+        translated = sum(1 for m in self.messages if m.is_translated)
+        return {
+            "language": self.language,
+            "total": len(self.messages),
+            "translated": translated,
+            "fuzzy": fuzzy,
+            "untranslated": len(self.messages) - translated - fuzzy,
+        }
+
+
+def catalog_path(directory: str | Path, language: str | None = None) -> Path:
+    """The template (no ``language``) or catalog file in ``directory``.
+
+    Raises:
+        TranslationError: If ``language`` is not a language code
+    """
+    if language is None:
+        return Path(directory) / TEMPLATE_FILE
+    if not LANGUAGE_CODE.match(language):
+        raise TranslationError(f"{language!r} is not a language code (like de)")
+    return Path(directory) / f"{language}.po"
+
+
+def extract_messages(packages: Iterable[PackageDoc], root: str | Path) -> Catalog:
+    """The template catalog: every documented symbol of ``packages``, in order."""
+    messages = []
+    for package in packages:
+        for symbol in package.symbols():
+            if not symbol.is_documented:
+                continue
+            path = relative_path(symbol.file_path, root)
+            messages.append(
+                Message(
+                    symbol=symbol.qualified_name,
+                    source=symbol.docstring.strip(),
+                    references=[f"{path}:{symbol.lineno}"],
+                ),
+            )
+    return Catalog(messages=messages)
+
+
+def merge_catalog(
+    template: Catalog,
+    existing: Catalog | None,
+    language: str,
+) -> tuple[Catalog, int]:
+    """``existing`` brought up to date with ``template``.
+
+    Returns:
+        The merged catalog and the number of messages dropped because their
+        symbol no longer exists
+    """
+    previous = existing.by_symbol() if existing is not None else {}
+    messages = []
+    for message in template.messages:
+        old = previous.get(message.symbol)
+        if old is None or not old.translation:
+            merged = replace(message)
+        elif old.source_hash == message.source_hash:
+            merged = replace(
+                message,
+                translation=old.translation,
+                fuzzy=old.fuzzy,
+                previous=old.previous,
+            )
+        else:
+            made_from = old.previous if old.fuzzy and old.previous else old.source
+            merged = replace(
+                message,
+                translation=old.translation,
+                fuzzy=True,
+                previous=made_from,
+            )
+        if old is not None:
+            merged.comments = list(old.comments)
+        messages.append(merged)
+    kept = {message.symbol for message in template.messages}
+    removed = sum(1 for symbol in previous if symbol not in kept)
+    return Catalog(language, messages), removed
+
+
+def _quote(text: str) -> str:
+    return '"' + "".join(_ESCAPES.get(char, char) for char in text) + '"'
+
+
+def _field(keyword: str, text: str, prefix: str = "") -> list[str]:
+    """``keyword "text"``, split after each newline the way gettext does."""
+    if "\n" not in text:
+        return [f"{prefix}{keyword} {_quote(text)}"]
+    pieces = text.splitlines(keepends=True)
+    return [f'{prefix}{keyword} ""', *(f"{prefix}{_quote(p)}" for p in pieces)]
+
+
+def render_catalog(catalog: Catalog) -> str:
+    """``catalog`` as a PO file; no dates, so output is byte-stable."""
+    header = "Content-Type: text/plain; charset=UTF-8\n"
+    if catalog.language:
+        header += f"Language: {catalog.language}\n"
+    lines = [
+        "# Docstring translations for the AutoDoc site.",
+        *_field("msgid", ""),
+        *_field("msgstr", header),
+    ]
+    for message in catalog.messages:
+        lines.append("")
+        lines.extend(f"# {comment}".rstrip() for comment in message.comments)
+        lines.append(f"#. {HASH_COMMENT} {message.source_hash}")
+        lines.extend(f"#: {reference}" for reference in message.references)
+        if message.fuzzy:
+            lines.append("#, fuzzy")
+        if message.fuzzy and message.previous is not None:
+            lines.extend(_field("msgid", message.previous, "#| "))
+        lines.extend(_field("msgctxt", message.symbol))
+        lines.extend(_field("msgid", message.source))
+        lines.extend(_field("msgstr", message.translation))
+    return "\n".join(lines) + "\n"
+
+
+def write_catalog(catalog: Catalog, path: str | Path) -> Path:
+    """Write ``catalog`` to ``path`` (see :func:`render_catalog`)."""
+    target = Path(path)
+    target.parent.mkdir(parents=True, exist_ok=True)
+    target.write_text(render_catalog(catalog), encoding="utf-8")
+    return target
+
+
+def _unquote(text: str, where: str) -> str:
+    match = _STRING.match(text.strip())
+    if match is None:
+        raise TranslationError(f"{where}: expected a quoted string")
+    return re.sub(
+        r"\\(.)",
+        lambda m: _UNESCAPES.get(m.group(1), m.group(1)),
+        match.group(1),
+    )
+
+
+class _Entry:
+    """The fields of one PO entry while it is being parsed."""
+
+    def __init__(self) -> None:
+        self.fields: dict[str, str] = {}
+        self.previous: str | None = None
+        self.flags: set[str] = set()
+        self.extracted: list[str] = []
+        self.references: list[str] = []
+        self.comments: list[str] = []
+        self.last: str | None = None
+
+    @property
+    def empty(self) -> bool:
+        return not (
+            self.fields
+            or self.previous is not None
+            or self.flags
+            or self.extracted
+            or self.references
+            or self.comments
+        )
+
+    def message(self, where: str) -> Message | None:
+        if "msgid" not in self.fields or "msgstr" not in self.fields:
+            raise TranslationError(f"{where}: entry needs a msgid and a msgstr")
+        if self.fields["msgid"] == "":
+            return None  # the header
+        if "msgctxt" not in self.fields:
+            raise TranslationError(
+                f"{where}: entry has no msgctxt naming its symbol",
+            )
+        hashes = [
+            line.split(HASH_COMMENT, 1)[1].strip()
+            for line in self.extracted
+            if line.startswith(HASH_COMMENT)
+        ]
+        return Message(
+            symbol=self.fields["msgctxt"],
+            source=self.fields["msgid"],
+            translation=self.fields["msgstr"],
+            source_hash=hashes[0] if hashes else "",
+            fuzzy="fuzzy" in self.flags,
+            previous=self.previous,
+            references=self.references,
+            comments=self.comments,
+        )
+
+
+def _comment(entry: _Entry, line: str, where: str) -> None:
+    """Record the comment ``line`` of a PO entry; obsolete ones are skipped."""
+    if line.startswith("#~"):
+        return
+    if line.startswith("#|"):
+        previous = line[2:].strip()
+        if previous.startswith("msgid "):
+            entry.previous = _unquote(previous[len("msgid ") :], where)
+        elif previous.startswith('"') and entry.previous is not None:
+            entry.previous += _unquote(previous, where)
+    elif line.startswith("#."):
+        entry.extracted.append(line[2:].strip())
+    elif line.startswith("#:"):
+        entry.references.extend(line[2:].split())
+    elif line.startswith("#,"):
+        entry.flags.update(flag.strip() for flag in line[2:].split(","))
+    else:
+        entry.comments.append(line[1:].strip())
+
+
+def parse_catalog(text: str, name: str = "<catalog>") -> Catalog:
+    """Parse PO ``text``; ``name`` is used in error messages.
+
+    Plural forms and obsolete (``#~``) entries are not used by docstrings
+    and are skipped.
+
+    Raises:
+        TranslationError: If the text is not a valid catalog
+    """
+    language = None
+    messages = []
+    entry = _Entry()
+    start = 1
+
+    def finish() -> None:
+        nonlocal entry, language
+        if not entry.empty:
+            message = entry.message(f"{name}:{start}")
+            if message is None:
+                header = entry.fields["msgstr"]
+                match = re.search(r"^Language:\s*(\S+)", header, re.MULTILINE)
+                language = match.group(1) if match else None
+            else:
+                messages.append(message)
+        entry = _Entry()
+
+    for lineno, raw in enumerate(text.splitlines(), start=1):
+        line = raw.strip()
+        where = f"{name}:{lineno}"
+        if not line:
+            finish()
+            continue
+        keyword = _KEYWORD.match(line)
+        new_entry = line.startswith("#") or (
+            keyword is not None and keyword.group(1) != "msgstr"
+        )
+        if new_entry and "msgstr" in entry.fields:
+            finish()
+        if entry.empty:
+            start = lineno
+        if line.startswith("#"):
+            _comment(entry, line, where)
+        elif line.startswith('"'):
+            if entry.last is None:
+                raise TranslationError(f"{where}: string outside an entry")
+            entry.fields[entry.last] += _unquote(line, where)
+        elif keyword is not None:
+            entry.fields[keyword.group(1)] = _unquote(keyword.group(2), where)
+            entry.last = keyword.group(1)
+        elif line.startswith(("msgid_plural", "msgstr[")):
+            entry.last = None
+            entry.fields.setdefault("msgstr", "")
+        else:
+            raise TranslationError(f"{where}: cannot parse {line!r}")
+    finish()
+    return Catalog(language, messages)
+
+
+def load_catalog(path: str | Path) -> Catalog:
+    """Read the catalog at ``path``.
+
+    Raises:
+        TranslationError: If the file cannot be read or is malformed
+    """
+    target = Path(path)
+    try:
+        text = target.read_text(encoding="utf-8")
+    except OSError as exc:
+        raise TranslationError(f"Cannot read catalog {target}: {exc}") from exc
+    return parse_catalog(text, str(target))
+
+
+def localize(
+    packages: Iterable[PackageDoc],
+    catalog: Catalog,
+) -> tuple[list[PackageDoc], int]:
+    """Copies of ``packages`` with their docstrings translated from ``catalog``.
+
+    A translation is used when it is not fuzzy and was made from the
+    docstring as it is now (same source hash); otherwise the source stays.
+
+    Returns:
+        The translated packages and the number of stale translations that
+        were skipped because the docstring changed since
+    """
+    messages = catalog.by_symbol()
+    stale = 0
+
+    def translate(symbol: DocSymbol) -> str | None:
+        nonlocal stale
+        message = messages.get(symbol.qualified_name)
+        if message is None or not message.is_translated or not symbol.is_documented:
+            return symbol.docstring
+        if message.source_hash != source_hash(symbol.docstring.strip()):
+            stale += 1
+            return symbol.docstring
+        return message.translation
+
+    translated = map_docstrings(packages, translate)
+    if stale:
+        logger.warning(
+            "%d %s translation(s) are out of date; run 'autodoc translate update'",
+            stale,
+            catalog.language,
+            extra={"stale": stale},
+        )
+    return translated, stale
+
+
+__all__ = [
+    "HASH_COMMENT",
+    "TEMPLATE_FILE",
+    "Catalog",
+    "Message",
+    "TranslationError",
+    "catalog_path",
+    "extract_messages",
+    "load_catalog",
+    "localize",
+    "merge_catalog",
+    "parse_catalog",
+    "render_catalog",
+    "source_hash",
+    "write_catalog",
+]
@@ -0,0 +1,300 @@
+"""Structured parameter, return, and exception sections of docstrings.
+
+Docstrings commonly describe parameters and return values in one of three
+conventions, which pages would otherwise show as a block of text:
+
+- Google: ``Args:``, ``Returns:``, ``Yields:``, and ``Raises:`` headings
+  with indented ``name (type): description`` entries;
+- NumPy: ``Parameters``, ``Returns``, ``Yields``, and ``Raises`` headings
+  underlined with dashes, and ``name : type`` entries with the description
+  indented below;
+- reST field lists: ``:param name:``, ``:type name:``, ``:returns:``,
+  ``:rtype:``, and ``:raises Error:``;
+- Javadoc and Epytext tags, as written by teams coming from Java:
+  ``@param name description`` (or ``@param name: description``, with an
+  optional JSDoc ``{type}``), ``@type``, ``@return``, ``@rtype``, and
+  ``@throws``, ``@exception``, or ``@raise``. A tag's description runs until
+  a blank line or the next tag.
+
+:func:`parse_docstring` returns the fields of those sections and the rest
+of the docstring as the description. Other sections (``Examples:``,
+``Notes``) stay in the description as written.
+"""
+
+from __future__ import annotations
+
+import inspect
+import re
+from dataclasses import dataclass
+
+SECTION_KINDS = ("parameters", "returns", "yields", "raises")
+# Heading (without the colon or underline) -> section kind.
+SECTION_HEADINGS = {
+    "Args": "parameters",
+    "Arguments": "parameters",
+    "Keyword Args": "parameters",
+    "Keyword Arguments": "parameters",
+    "Other Parameters": "parameters",
+    "Parameters": "parameters",
+    "Params": "parameters",
+    "Return": "returns",
+    "Returns": "returns",
+    "Yield": "yields",
+    "Yields": "yields",
+    "Exceptions": "raises",
+    "Raises": "raises",
+}
+_GOOGLE = re.compile(r"^(?P<heading>[A-Z][A-Za-z ]+):\s*$")
+_UNDERLINE = re.compile(r"^-{3,}\s*$")
+_GOOGLE_ENTRY = re.compile(
+    r"^(?P<name>\*{0,2}\w+)\s*(?:\((?P<type>[^)]*)\))?\s*:(?:\s+(?P<text>.*))?$",
+)
+# "int: ...", "list[str]: ...", "str | None: ..." but not prose with a colon.
+_TYPE = r"[\w.]+(?:\[[^\]]*\])?"
+_GOOGLE_RETURN = re.compile(
+    rf"^(?P<type>{_TYPE}(?:\s*\|\s*{_TYPE})*):\s+(?P<text>.*)$",
+)
+_NUMPY_ENTRY = re.compile(r"^(?P<name>\*{0,2}\w+)(?:\s+:\s*(?P<type>.*))?$")
+_FIELD = re.compile(
+    r"^:(?P<key>param|parameter|arg|argument|key|keyword|type|returns?|rtype|"
+    r"yields?|ytype|raises?|except)(?:\s+(?P<args>[^:]+))?:\s*(?P<text>.*)$",
+)
+_TAG = re.compile(
+    r"^@(?P<key>param|arg|type|returns?|rtype|yields?|ytype|throws|raises?|"
+    r"exception)\b\s*(?P<rest>.*)$",
+)
+# "{int} count", as JSDoc writes the type.
+_TAG_TYPE = re.compile(r"^\{(?P<type>[^}]*)\}\s*")
+_PARAM_FIELDS = frozenset({"param", "parameter", "arg", "argument", "key", "keyword"})
+
+
+@dataclass(frozen=True)
+class DocField:
+    """One entry of a docstring section."""
+
+    # The parameter or exception; None for an unnamed return value.
+    name: str | None
+    type: str | None
+    description: str
+
+    def to_dict(self) -> dict[str, object]:
+        return {"name": self.name, "type": self.type, "description": self.description}
+
+
+@dataclass(frozen=True)
+class ParsedDocstring:
+    """A docstring split into its description and structured sections."""
+
+    description: str
+    parameters: tuple[DocField, ...] = ()
+    returns: tuple[DocField, ...] = ()
+    yields: tuple[DocField, ...] = ()
+    raises: tuple[DocField, ...] = ()
+
+    @property
+    def structured(self) -> bool:
+        """Whether any section was found."""
+        return any(self.section(kind) for kind in SECTION_KINDS)
+
+    def section(self, kind: str) -> tuple[DocField, ...]:
+        """The fields of the section ``kind``, one of :data:`SECTION_KINDS`."""
+        return getattr(self, kind)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "description": self.description,
+            **{
+                kind: [field.to_dict() for field in self.section(kind)]
+                for kind in SECTION_KINDS
+            },
+        }
+
+
+def _join(lines: list[str]) -> str:
+    return " ".join(line.strip() for line in lines if line.strip())
+
+
+def _entries(lines: list[str]) -> list[tuple[str, list[str]]]:
+    """Split section lines into (first line, continuation lines) by indentation."""
+    entries: list[tuple[str, list[str]]] = []
+    indent = min(
+        (len(line) - len(line.lstrip()) for line in lines if line.strip()),
+        default=0,
+    )
+    for line in lines:
+        if not line.strip():
+            continue
+        if len(line) - len(line.lstrip()) <= indent or not entries:
+            entries.append((line.strip(), []))
+        else:
+            entries[-1][1].append(line)
+    return entries
+
+
+def _google_fields(kind: str, lines: list[str]) -> list[DocField]:
+    text = _join(lines)
+    if kind in ("returns", "yields"):
+        # One value, written "type: description" or as prose.
+        match = _GOOGLE_RETURN.match(text)
+        if match is not None:
+            return [DocField(None, match["type"].strip(), match["text"])]
+        return [DocField(None, None, text)] if text else []
+    fields: list[DocField] = []
+    for first, rest in _entries(lines):
+        match = _GOOGLE_ENTRY.match(first)
+        if match is None:
+            if fields:
+                # A wrapped description that is not indented further.
+                last = fields.pop()
+                text = _join([last.description, first, *rest])
+                fields.append(DocField(last.name, last.type, text))
+            continue
+        type_ = match["type"] if kind == "parameters" else None
+        text = _join([match["text"] or "", *rest])
+        fields.append(DocField(match["name"], type_, text))
+    return fields
+
+
+def _numpy_fields(kind: str, lines: list[str]) -> list[DocField]:
+    fields = []
+    for first, rest in _entries(lines):
+        match = _NUMPY_ENTRY.match(first)
+        if match is None:
+            fields.append(DocField(None, first, _join(rest)))
+        elif kind == "parameters":
+            fields.append(DocField(match["name"], match["type"], _join(rest)))
+        elif kind == "raises":
+            fields.append(DocField(match["name"], None, _join(rest)))
+        elif match["type"] is not None:
+            fields.append(DocField(match["name"], match["type"], _join(rest)))
+        else:
+            # A bare line in "Returns" is the type of an unnamed value.
+            fields.append(DocField(None, match["name"], _join(rest)))
+    return fields
+
+
+def parse_docstring(docstring: str | None) -> ParsedDocstring:
+    """Split ``docstring`` into its description and sections."""
+    lines = inspect.cleandoc(docstring or "").splitlines()
+    description: list[str] = []
+    sections: dict[str, list[DocField]] = {kind: [] for kind in SECTION_KINDS}
+    types: dict[str, str] = {}
+    index = 0
+    while index < len(lines):
+        line = lines[index]
+        heading = _GOOGLE.match(line)
+        if heading is not None and heading["heading"] in SECTION_HEADINGS:
+            kind = SECTION_HEADINGS[heading["heading"]]
+            end = index + 1
+            while end < len(lines) and (
+                not lines[end].strip() or lines[end][0].isspace()
+            ):
+                end += 1
+            sections[kind].extend(_google_fields(kind, lines[index + 1 : end]))
+            index = end
+            continue
+        underlined = index + 1 < len(lines) and _UNDERLINE.match(lines[index + 1])
+        if underlined and line.strip() in SECTION_HEADINGS:
+            kind = SECTION_HEADINGS[line.strip()]
+            end = index + 2
+            while end < len(lines) and not (
+                end + 1 < len(lines) and _UNDERLINE.match(lines[end + 1])
+            ):
+                end += 1
+            sections[kind].extend(_numpy_fields(kind, lines[index + 2 : end]))
+            index = end
+            continue
+        field = _FIELD.match(line)
+        if field is not None:
+            end = index + 1
+            while end < len(lines) and lines[end][:1].isspace():
+                end += 1
+            text = _join([field["text"], *lines[index + 1 : end]])
+            _add_field(sections, types, field["key"], field["args"], text)
+            index = end
+            continue
+        tag = _TAG.match(line)
+        if tag is not None:
+            end = index + 1
+            while end < len(lines) and lines[end].strip() and not (
+                _TAG.match(lines[end].strip()) or _FIELD.match(lines[end].strip())
+            ):
+                end += 1
+            rest = _join([tag["rest"], *lines[index + 1 : end]])
+            for key, args, text in _tag_fields(tag["key"], rest):
+                _add_field(sections, types, key, args, text)
+            index = end
+            continue
+        description.append(line)
+        index += 1
+    # ":type name:" may come before or after ":param name:".
+    sections["parameters"] = [
+        DocField(f.name, f.type or types.get(f.name or ""), f.description)
+        for f in sections["parameters"]
+    ]
+    return ParsedDocstring(
+        re.sub(r"\n{3,}", "\n\n", "\n".join(description)).strip(),
+        **{kind: tuple(fields) for kind, fields in sections.items()},
+    )
+
+
+def is_tag(line: str) -> bool:
+    """Whether ``line`` starts a Javadoc or Epytext tag (``@param``)."""
+    return _TAG.match(line.strip()) is not None
+
+
+def _tag_fields(key: str, rest: str) -> list[tuple[str, str | None, str]]:
+    """The reST fields ``(key, args, text)`` that a tag stands for."""
+    match = _TAG_TYPE.match(rest)
+    type_ = match["type"].strip() if match else None
+    rest = rest[match.end() :] if match else rest
+    if key in ("returns", "return", "yields", "yield", "rtype", "ytype"):
+        fields = [(key, None, rest.lstrip(":").strip())]
+        if type_ is not None:
+            type_key = "rtype" if key.startswith("return") else "ytype"
+            fields.append((type_key, None, type_))
+        return fields
+    name, _, text = rest.partition(" ")
+    name, text = name.rstrip(":"), text.strip().lstrip(":").strip()
+    if key in ("throws", "exception", "raise", "raises"):
+        return [("raises", name, text)]
+    if key == "type":
+        return [("type", name, text)]
+    return [("param", f"{type_} {name}" if type_ else name, text)]
+
+
+def _add_field(
+    sections: dict[str, list[DocField]],
+    types: dict[str, str],
+    key: str,
+    args: str | None,
+    text: str,
+) -> None:
+    """Record the reST field ``:key args: text``."""
+    words = (args or "").split()
+    if key in _PARAM_FIELDS and words:
+        # ":param str name:" carries the type before the name.
+        type_ = " ".join(words[:-1]) or None
+        sections["parameters"].append(DocField(words[-1], type_, text))
+    elif key == "type" and words:
+        types[words[-1]] = text
+    elif key in ("returns", "return", "yields", "yield"):
+        kind = "returns" if key.startswith("return") else "yields"
+        last = sections[kind].pop() if sections[kind] else DocField(None, None, "")
+        sections[kind].append(DocField(None, last.type, text))
+    elif key in ("rtype", "ytype"):
+        kind = "returns" if key == "rtype" else "yields"
+        last = sections[kind].pop() if sections[kind] else DocField(None, None, "")
+        sections[kind].append(DocField(None, text, last.description))
+    elif key in ("raises", "raise", "except"):
+        sections["raises"].append(DocField(args.strip() if args else None, None, text))
+
+
+__all__ = [
+    "SECTION_HEADINGS",
+    "SECTION_KINDS",
+    "DocField",
+    "ParsedDocstring",
+    "is_tag",
+    "parse_docstring",
+]
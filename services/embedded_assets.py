@@ -0,0 +1,294 @@
+"""Data files a package loads at run time, and the variables holding them.
+
+Python's counterpart of an embedded asset is package data read through the
+import system or next to the module's own file. :func:`find_embedded_assets`
+finds module-level variables bound to such a load, in any of the usual
+spellings::
+
+    TEMPLATE = files(__package__).joinpath("templates/page.html").read_text()
+    SCHEMA = pkgutil.get_data(__name__, "schema.json")
+    STATIC = Path(__file__).parent / "static"
+    ICONS = (HERE / "icons").glob("*.svg")
+
+``importlib.resources.files`` (and ``importlib_resources``), the older
+``importlib.resources`` functions (``read_text``, ``open_binary``, ``path``,
+...), ``pkgutil.get_data``, and ``Path(__file__)`` or
+``os.path.dirname(__file__)`` with ``/``, ``joinpath``, ``os.path.join``,
+``parent``, and ``glob`` are understood, as are module-level variables that
+name a directory for later use (``HERE`` above). Anchors must be literal:
+``__name__``, ``__package__``, ``__spec__.parent``, or a dotted package name
+of the tree; a path built from anything else is not reported.
+
+Each :class:`EmbeddedAsset` records the pattern (a file, a directory, or a
+glob below one) and the files it matches on disk, which package pages list
+as their asset inventory. A pattern that matches nothing is still reported,
+with no files, since the load fails at run time.
+"""
+
+from __future__ import annotations
+
+import ast
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+RESOURCE_TRAVERSABLES = frozenset(
+    {"importlib.resources.files", "importlib_resources.files"},
+)
+# Older importlib.resources functions taking (package, resource).
+RESOURCE_FUNCTIONS = frozenset(
+    f"{module}.{name}"
+    for module in ("importlib.resources", "importlib_resources")
+    for name in ("open_binary", "open_text", "path", "read_binary", "read_text")
+)
+GET_DATA = frozenset({"pkgutil.get_data"})
+PATH_TYPES = frozenset({"pathlib.Path", "pathlib.PurePath"})
+# Methods and functions that return (or use) the path they are given.
+_SAME_PATH_METHODS = frozenset(
+    {
+        "absolute",
+        "expanduser",
+        "iterdir",
+        "open",
+        "read_bytes",
+        "read_text",
+        "resolve",
+    },
+)
+_SAME_PATH_FUNCTIONS = frozenset(
+    {
+        "importlib.resources.as_file",
+        "importlib_resources.as_file",
+        "os.path.abspath",
+        "os.path.realpath",
+    },
+)
+_PACKAGE_ANCHORS = ("__name__", "__package__", "__spec__.parent")
+# Directories never listed in an inventory.
+_SKIPPED_DIRS = frozenset({"__pycache__"})
+
+
+@dataclass(frozen=True)
+class EmbeddedAsset:
+    """A module-level variable holding data files of its package."""
+
+    module: str
+    package: str
+    variable: str
+    file_path: str
+    lineno: int
+    # Root-relative file, directory, or glob the variable loads.
+    pattern: str
+    # Root-relative files matching ``pattern``, sorted.
+    files: tuple[str, ...]
+
+    @property
+    def qualified_name(self) -> str:
+        return f"{self.module}.{self.variable}"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "module": self.module,
+            "package": self.package,
+            "variable": self.variable,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "pattern": self.pattern,
+            "files": list(self.files),
+        }
+
+
+@dataclass(frozen=True)
+class _Location:
+    path: Path
+    glob: str | None = None
+    # Whether a literal file name or pattern was applied to the anchor.
+    named: bool = False
+
+
+class _ModuleScanner:
+    def __init__(
+        self,
+        info: ModuleImports,
+        graph: ImportGraph,
+        tree: ast.Module,
+    ) -> None:
+        self.info = info
+        self.graph = graph
+        self.tree = tree
+        self.file = Path(info.file_path)
+        # Module-level names bound to a path, for ``HERE / "static"``.
+        self.names: dict[str, _Location] = {}
+
+    def scan(self) -> list[tuple[str, int, _Location]]:
+        found = []
+        for node in self.tree.body:
+            if isinstance(node, ast.Assign):
+                targets, value = node.targets, node.value
+            elif isinstance(node, ast.AnnAssign) and node.value is not None:
+                targets, value = [node.target], node.value
+            else:
+                continue
+            if len(targets) != 1 or not isinstance(targets[0], ast.Name):
+                continue
+            location = self._find(value)
+            if location is None:
+                continue
+            self.names[targets[0].id] = location
+            if location.named:
+                found.append((targets[0].id, node.lineno, location))
+        return found
+
+    def _find(self, node: ast.expr) -> _Location | None:
+        """The outermost path ``node`` or one of its parts evaluates to."""
+        location = self._location(node)
+        if location is not None:
+            return location
+        for child in ast.iter_child_nodes(node):
+            if isinstance(child, ast.expr):
+                location = self._find(child)
+                if location is not None:
+                    return location
+        return None
+
+    def _anchor(self, node: ast.expr) -> Path | None:
+        """Directory of the package a resource anchor names."""
+        if ast.unparse(node) in _PACKAGE_ANCHORS:
+            return self.file.parent
+        if isinstance(node, ast.Constant) and isinstance(node.value, str):
+            module = self.graph.modules.get(node.value)
+            return None if module is None else Path(module.file_path).parent
+        return None
+
+    def _location(self, node: ast.expr) -> _Location | None:
+        if isinstance(node, ast.Name):
+            if node.id == "__file__":
+                return _Location(self.file)
+            return self.names.get(node.id)
+        if isinstance(node, ast.Attribute) and node.attr == "parent":
+            base = self._location(node.value)
+            return None if base is None else _Location(base.path.parent)
+        if isinstance(node, ast.BinOp) and isinstance(node.op, ast.Div):
+            base = self._location(node.left)
+            name = _literal(node.right)
+            if base is None or name is None:
+                return None
+            return _Location(base.path / name, named=True)
+        if isinstance(node, ast.Call):
+            return self._call(node)
+        return None
+
+    def _call(self, call: ast.Call) -> _Location | None:
+        func = resolve_name(call.func, self.info)
+        literals = [_literal(arg) for arg in call.args]
+        if func in RESOURCE_TRAVERSABLES:
+            anchor = self._anchor(call.args[0]) if call.args else self.file.parent
+            return None if anchor is None else _Location(anchor)
+        if func in RESOURCE_FUNCTIONS | GET_DATA:
+            if len(call.args) < 2 or literals[1] is None:
+                return None
+            anchor = self._anchor(call.args[0])
+            if anchor is None:
+                return None
+            return _Location(anchor / literals[1], named=True)
+        if func in PATH_TYPES or func == "os.path.join":
+            if not call.args:
+                return None
+            base = self._location(call.args[0])
+            if base is None or None in literals[1:]:
+                return None
+            rest = [name for name in literals[1:] if name is not None]
+            return _Location(
+                base.path.joinpath(*rest),
+                named=base.named or bool(rest),
+            )
+        if func == "os.path.dirname" and call.args:
+            base = self._location(call.args[0])
+            return None if base is None else _Location(base.path.parent)
+        if func in _SAME_PATH_FUNCTIONS and call.args:
+            return self._location(call.args[0])
+        if not isinstance(call.func, ast.Attribute):
+            return None
+        base = self._location(call.func.value)
+        method = call.func.attr
+        if base is None:
+            return None
+        if method == "joinpath":
+            if not literals or None in literals:
+                return None
+            names = [name for name in literals if name is not None]
+            return _Location(base.path.joinpath(*names), named=True)
+        if method in ("glob", "rglob") and literals and literals[0] is not None:
+            pattern = literals[0] if method == "glob" else f"**/{literals[0]}"
+            return _Location(base.path, pattern, named=True)
+        if method in _SAME_PATH_METHODS:
+            return base
+        return None
+
+
+def _literal(node: ast.expr) -> str | None:
+    if isinstance(node, ast.Constant) and isinstance(node.value, str):
+        return node.value
+    return None
+
+
+def _inventory(location: _Location, root: str | Path) -> tuple[str, list[str]]:
+    """The root-relative pattern of ``location`` and the files it matches."""
+    pattern = relative_path(location.path, root)
+    if location.glob is not None:
+        matches = location.path.glob(location.glob)
+        pattern = f"{pattern}/{location.glob}"
+    elif location.path.is_dir():
+        matches = location.path.rglob("*")
+        pattern += "/"
+    else:
+        matches = iter([location.path])
+    files = [
+        relative_path(path, root)
+        for path in matches
+        if path.is_file() and not _SKIPPED_DIRS.intersection(path.parts)
+    ]
+    return pattern, sorted(files)
+
+
+def find_embedded_assets(
+    root: str | Path,
+    graph: ImportGraph,
+) -> list[EmbeddedAsset]:
+    """Find the data files loaded by the modules of ``graph`` (built for ``root``).
+
+    Assets are sorted by module and line.
+    """
+    assets = []
+    for info, tree in parse_modules(graph, "asset detection"):
+        for variable, lineno, location in _ModuleScanner(info, graph, tree).scan():
+            pattern, files = _inventory(location, root)
+            assets.append(
+                EmbeddedAsset(
+                    module=info.module,
+                    package=info.package,
+                    variable=variable,
+                    file_path=relative_path(info.file_path, root),
+                    lineno=lineno,
+                    pattern=pattern,
+                    files=tuple(files),
+                ),
+            )
+    return sorted(assets, key=lambda asset: (asset.module, asset.lineno))
+
+
+__all__ = [
+    "GET_DATA",
+    "PATH_TYPES",
+    "RESOURCE_FUNCTIONS",
+    "RESOURCE_TRAVERSABLES",
+    "EmbeddedAsset",
+    "find_embedded_assets",
+]
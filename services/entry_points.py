@@ -0,0 +1,505 @@
+"""Entry-point detection and application wiring analysis.
+
+:func:`detect_architecture` finds the places a Python application starts:
+
+- console and GUI scripts declared in ``pyproject.toml``;
+- ``__main__.py`` modules and ``if __name__ == "__main__":`` guards;
+- module-level application objects (``app = FastAPI()``, ``app = create_app()``);
+- calls that run at import time, the closest Python has to an ``init`` hook.
+
+For every web application object it also records the top-level wiring, in
+source order: routers, middleware, mounts, exception and event handlers, and
+setup functions the app is passed to. The result feeds the "Architecture"
+page of the generated site.
+"""
+
+from __future__ import annotations
+
+import ast
+import builtins
+import logging
+import tomllib
+from collections.abc import Iterator
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    build_import_graph,
+    dotted_parts,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+logger = logging.getLogger(__name__)
+
+# Resolved constructor -> framework name.
+FRAMEWORKS = {
+    "fastapi.FastAPI": "FastAPI",
+    "fastapi.applications.FastAPI": "FastAPI",
+    "starlette.applications.Starlette": "Starlette",
+    "flask.Flask": "Flask",
+}
+
+# App method -> wiring step kind.
+WIRING_METHODS = {
+    "include_router": "router",
+    "register_blueprint": "router",
+    "add_middleware": "middleware",
+    "middleware": "middleware",
+    "mount": "mount",
+    "add_exception_handler": "exception handler",
+    "exception_handler": "exception handler",
+    "errorhandler": "exception handler",
+    "add_event_handler": "event handler",
+    "on_event": "event handler",
+}
+
+ENTRY_POINT_KINDS = {
+    "console-script": "Console script",
+    "main-module": "__main__ module",
+    "main-guard": "__main__ guard",
+    "app": "Application object",
+    "import-time": "Import-time setup",
+    "plugin": "Plugin entry point",
+}
+
+
+class ArchitectureError(Exception):
+    """Raised when project metadata needed for entry points cannot be read."""
+
+
+@dataclass(frozen=True)
+class EntryPoint:
+    """A place where execution of the application can begin."""
+
+    kind: str
+    name: str
+    file_path: str
+    lineno: int
+    # Dotted callable or object the entry point runs, when there is one.
+    target: str | None = None
+    # Resolved calls made, in source order.
+    calls: tuple[str, ...] = ()
+
+    @property
+    def location(self) -> str:
+        return f"{self.file_path}:{self.lineno}" if self.lineno else self.file_path
+
+    @property
+    def runs(self) -> tuple[str, ...]:
+        """What starting here executes: the target, else the calls made."""
+        if self.kind in ("console-script", "app") and self.target:
+            return (self.target,)
+        return self.calls
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "kind": self.kind,
+            "name": self.name,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "target": self.target,
+            "calls": list(self.calls),
+        }
+
+
+@dataclass(frozen=True)
+class WiringStep:
+    """One thing done to an application object while it is set up."""
+
+    kind: str
+    target: str
+    lineno: int
+    detail: str | None = None
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "kind": self.kind,
+            "target": self.target,
+            "lineno": self.lineno,
+            "detail": self.detail,
+        }
+
+
+@dataclass
+class AppWiring:
+    """A web application object and how it is assembled."""
+
+    name: str
+    framework: str
+    module: str
+    file_path: str
+    lineno: int
+    # Function that builds and returns the app, if it is not built at top level.
+    factory: str | None = None
+    steps: list[WiringStep] = field(default_factory=list)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "name": self.name,
+            "framework": self.framework,
+            "module": self.module,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "factory": self.factory,
+            "steps": [step.to_dict() for step in self.steps],
+        }
+
+
+@dataclass
+class ArchitectureOverview:
+    """Entry points and application wiring of a source tree."""
+
+    entry_points: list[EntryPoint] = field(default_factory=list)
+    apps: list[AppWiring] = field(default_factory=list)
+
+    def __bool__(self) -> bool:
+        return bool(self.entry_points or self.apps)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "entry_points": [entry.to_dict() for entry in self.entry_points],
+            "apps": [app.to_dict() for app in self.apps],
+        }
+
+
+def _scope_nodes(body: list[ast.stmt]) -> Iterator[ast.AST]:
+    """Walk ``body`` without descending into nested functions or classes."""
+    stack: list[ast.AST] = list(reversed(body))
+    while stack:
+        node = stack.pop()
+        yield node
+        if isinstance(
+            node,
+            (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef, ast.Lambda),
+        ):
+            continue
+        stack.extend(reversed(list(ast.iter_child_nodes(node))))
+
+
+def _is_main_guard(node: ast.stmt) -> bool:
+    if not isinstance(node, ast.If) or not isinstance(node.test, ast.Compare):
+        return False
+    test = node.test
+    operands = [test.left, *test.comparators]
+    return (
+        len(test.ops) == 1
+        and isinstance(test.ops[0], ast.Eq)
+        and any(isinstance(o, ast.Name) and o.id == "__name__" for o in operands)
+        and any(
+            isinstance(o, ast.Constant) and o.value == "__main__" for o in operands
+        )
+    )
+
+
+class _ModuleAnalyzer:
+    """Entry points and app wiring of one module."""
+
+    def __init__(self, tree: ast.Module, info: ModuleImports, file_path: str) -> None:
+        self.tree = tree
+        self.info = info
+        self.file_path = file_path
+        self.local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        }
+        self.apps: list[AppWiring] = []
+        # String constants assigned in the scope being analyzed.
+        self.constants: dict[str, str] = {}
+        # (variable, resolved callable, line) for top-level ``name = call()``.
+        self.assignments: list[tuple[str, str, int]] = []
+
+    def resolve(self, node: ast.expr) -> str:
+        return resolve_name(node, self.info, self.local_names)
+
+    def describe(self, node: ast.expr) -> str:
+        if isinstance(node, ast.Constant) and isinstance(node.value, str):
+            return node.value
+        if isinstance(node, ast.Name) and node.id in self.constants:
+            return self.constants[node.id]
+        return self.resolve(node)
+
+    def calls(self, body: list[ast.stmt]) -> tuple[str, ...]:
+        """Resolved names of the calls in ``body``, skipping builtins."""
+        found: list[str] = []
+        for node in _scope_nodes(body):
+            if not isinstance(node, ast.Call) or dotted_parts(node.func) is None:
+                continue
+            name = self.resolve(node.func)
+            if name not in found and not hasattr(builtins, name):
+                found.append(name)
+        return tuple(found)
+
+    def analyze(self) -> None:
+        self._analyze_scope(self.tree.body, owner=None)
+        for node in self.tree.body:
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                self._analyze_scope(node.body, owner=f"{self.info.module}.{node.name}")
+            if (
+                isinstance(node, ast.Assign)
+                and len(node.targets) == 1
+                and isinstance(node.targets[0], ast.Name)
+                and isinstance(node.value, ast.Call)
+            ):
+                self.assignments.append(
+                    (node.targets[0].id, self.resolve(node.value.func), node.lineno),
+                )
+
+    def _analyze_scope(self, body: list[ast.stmt], owner: str | None) -> None:
+        nodes = list(_scope_nodes(body))
+        self.constants = {
+            node.targets[0].id: node.value.value
+            for node in nodes
+            if isinstance(node, ast.Assign)
+            and len(node.targets) == 1
+            and isinstance(node.targets[0], ast.Name)
+            and isinstance(node.value, ast.Constant)
+            and isinstance(node.value.value, str)
+        }
+        returned = {
+            node.value.id
+            for node in nodes
+            if isinstance(node, ast.Return) and isinstance(node.value, ast.Name)
+        }
+        apps: dict[str, AppWiring] = {}
+        for node in nodes:
+            if not (
+                isinstance(node, ast.Assign)
+                and len(node.targets) == 1
+                and isinstance(node.targets[0], ast.Name)
+                and isinstance(node.value, ast.Call)
+            ):
+                continue
+            framework = FRAMEWORKS.get(self.resolve(node.value.func))
+            if framework is None:
+                continue
+            variable = node.targets[0].id
+            factory = owner if owner and variable in returned else None
+            apps[variable] = AppWiring(
+                name=factory or f"{owner or self.info.module}.{variable}",
+                framework=framework,
+                module=self.info.module,
+                file_path=self.file_path,
+                lineno=node.lineno,
+                factory=factory,
+            )
+        if not apps:
+            return
+
+        for node in nodes:
+            if isinstance(node, ast.Call):
+                self._wiring_call(node, apps)
+            elif isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                self._wiring_decorators(node, apps, owner)
+        for app in apps.values():
+            app.steps.sort(key=lambda step: step.lineno)
+            self.apps.append(app)
+
+    def _wiring_call(self, node: ast.Call, apps: dict[str, AppWiring]) -> None:
+        func = node.func
+        if (
+            isinstance(func, ast.Attribute)
+            and isinstance(func.value, ast.Name)
+            and func.value.id in apps
+        ):
+            kind = WIRING_METHODS.get(func.attr)
+            if kind is None or not node.args:
+                return
+            args = node.args
+            detail = None
+            if func.attr == "include_router":
+                prefix = next((k.value for k in node.keywords if k.arg == "prefix"), None)
+                detail = f"prefix {self.describe(prefix)}" if prefix is not None else None
+                target = self.resolve(args[0])
+            elif func.attr in ("mount", "add_exception_handler", "add_event_handler"):
+                target = self.resolve(args[1]) if len(args) > 1 else self.describe(args[0])
+                detail = self.describe(args[0])
+            else:
+                target = self.resolve(args[0])
+            apps[func.value.id].steps.append(
+                WiringStep(kind, target, node.lineno, detail),
+            )
+            return
+        for arg in node.args:
+            if isinstance(arg, ast.Name) and arg.id in apps:
+                apps[arg.id].steps.append(
+                    WiringStep("setup", self.resolve(func), node.lineno),
+                )
+
+    def _wiring_decorators(
+        self,
+        node: ast.FunctionDef | ast.AsyncFunctionDef,
+        apps: dict[str, AppWiring],
+        owner: str | None,
+    ) -> None:
+        handler = f"{owner or self.info.module}.{node.name}"
+        for decorator in node.decorator_list:
+            if not (
+                isinstance(decorator, ast.Call)
+                and isinstance(decorator.func, ast.Attribute)
+                and isinstance(decorator.func.value, ast.Name)
+                and decorator.func.value.id in apps
+            ):
+                continue
+            kind = WIRING_METHODS.get(decorator.func.attr)
+            if kind is None:
+                continue
+            detail = self.describe(decorator.args[0]) if decorator.args else None
+            apps[decorator.func.value.id].steps.append(
+                WiringStep(kind, handler, decorator.lineno, detail),
+            )
+
+    def entry_points(self) -> list[EntryPoint]:
+        found = []
+        module = self.info.module
+        if Path(self.file_path).name == "__main__.py":
+            body = [node for node in self.tree.body if not _is_main_guard(node)]
+            found.append(
+                EntryPoint("main-module", module, self.file_path, 1, None, self.calls(body)),
+            )
+        else:
+            import_time = [
+                node
+                for node in self.tree.body
+                if isinstance(node, ast.Expr) and isinstance(node.value, ast.Call)
+            ]
+            if import_time:
+                found.append(
+                    EntryPoint(
+                        "import-time",
+                        module,
+                        self.file_path,
+                        import_time[0].lineno,
+                        None,
+                        self.calls(import_time),
+                    ),
+                )
+        for node in self.tree.body:
+            if _is_main_guard(node):
+                calls = self.calls(node.body)
+                found.append(
+                    EntryPoint(
+                        "main-guard",
+                        module,
+                        self.file_path,
+                        node.lineno,
+                        calls[0] if calls else None,
+                        calls,
+                    ),
+                )
+        return found
+
+
+def _project_table(root: str | Path) -> dict[str, Any]:
+    path = Path(root) / "pyproject.toml"
+    if not path.is_file():
+        return {}
+    try:
+        data = tomllib.loads(path.read_text(encoding="utf-8"))
+    except (OSError, tomllib.TOMLDecodeError) as exc:
+        raise ArchitectureError(f"Cannot read {path}: {exc}") from exc
+    return data.get("project", {})
+
+
+def _spec_target(spec: object) -> str:
+    """``pkg.mod:attr [extra]`` -> ``pkg.mod.attr``."""
+    return str(spec).split("[", 1)[0].strip().replace(":", ".")
+
+
+def read_scripts(root: str | Path) -> list[EntryPoint]:
+    """Console and GUI scripts declared in ``root/pyproject.toml``.
+
+    Raises:
+        ArchitectureError: If ``pyproject.toml`` exists but is not valid TOML
+    """
+    project = _project_table(root)
+    found = []
+    for table in ("scripts", "gui-scripts"):
+        for name, spec in sorted(project.get(table, {}).items()):
+            target = _spec_target(spec)
+            found.append(EntryPoint("console-script", name, "pyproject.toml", 0, target))
+    return found
+
+
+def read_plugins(root: str | Path) -> list[EntryPoint]:
+    """Plugin entry points (``[project.entry-points.<group>]``) of ``root``.
+
+    Plugins are loaded by name at run time, so the objects they name are
+    used even though no module imports them.
+
+    Raises:
+        ArchitectureError: If ``pyproject.toml`` exists but is not valid TOML
+    """
+    groups = _project_table(root).get("entry-points", {})
+    found = []
+    for group, entries in sorted(groups.items()):
+        for name, spec in sorted(entries.items()):
+            found.append(
+                EntryPoint(
+                    "plugin",
+                    f"{group}:{name}",
+                    "pyproject.toml",
+                    0,
+                    _spec_target(spec),
+                ),
+            )
+    return found
+
+
+def detect_architecture(
+    root: str | Path,
+    graph: ImportGraph | None = None,
+) -> ArchitectureOverview:
+    """Find entry points and application wiring below ``root``.
+
+    ``graph`` is reused when the caller has already built one for ``root``.
+
+    Raises:
+        ArchitectureError: If ``pyproject.toml`` cannot be parsed
+    """
+    root_path = Path(root)
+    graph = graph if graph is not None else build_import_graph(root_path)
+    overview = ArchitectureOverview(entry_points=read_scripts(root_path))
+    assignments: list[tuple[_ModuleAnalyzer, str, str, int]] = []
+
+    for info, tree in parse_modules(graph, "entry-point analysis"):
+        file_path = relative_path(info.file_path, root_path)
+        analyzer = _ModuleAnalyzer(tree, info, file_path)
+        analyzer.analyze()
+        overview.entry_points.extend(analyzer.entry_points())
+        overview.apps.extend(analyzer.apps)
+        assignments.extend((analyzer, *entry) for entry in analyzer.assignments)
+
+    # Module-level app objects, built directly or by a detected factory.
+    factories = {app.factory for app in overview.apps if app.factory}
+    for analyzer, variable, func, lineno in assignments:
+        app_name = f"{analyzer.info.module}.{variable}"
+        if func in factories or func in FRAMEWORKS:
+            overview.entry_points.append(
+                EntryPoint(
+                    "app",
+                    f"{analyzer.info.module}:{variable}",
+                    analyzer.file_path,
+                    lineno,
+                    func if func in factories else app_name,
+                ),
+            )
+    return overview
+
+
+__all__ = [
+    "ENTRY_POINT_KINDS",
+    "ArchitectureError",
+    "ArchitectureOverview",
+    "AppWiring",
+    "EntryPoint",
+    "WiringStep",
+    "detect_architecture",
+    "read_plugins",
+    "read_scripts",
+]
@@ -0,0 +1,319 @@
+"""The exceptions callers of a function can catch, and what they chain.
+
+Python's counterpart of wrapping with ``%w`` is exception chaining, and of
+``errors.Join`` an exception group::
+
+    try:
+        return self.items[key]
+    except KeyError as err:
+        raise CartError(key) from err
+
+    raise ExceptionGroup("invalid cart", [PriceError(), StockError()])
+
+Unlike ``errors.Is``, ``except`` matches only the raised class, not its
+cause: callers catch ``CartError``, and find the ``KeyError`` on its
+``__cause__``. Group members are matched with ``except*``.
+:func:`find_exception_chains` records, for every function and method:
+
+- each ``raise`` in its body, with the chained cause (the handled
+  exception for ``from err``) or whether the cause is hidden (``from None``);
+- a bare ``raise`` or ``raise err`` in an ``except`` block as re-raising
+  the handled classes;
+- the exceptions of the functions of the tree it calls (by name, or as
+  ``self.method()``), as raised ``via`` the callee.
+
+Exceptions caught by an enclosing ``try`` in the same function are left
+out. Catching is by name: ``Exception`` and ``BaseException`` catch
+everything, and other class hierarchies are not followed.
+"""
+
+from __future__ import annotations
+
+import ast
+from collections.abc import Callable, Iterator
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    dotted_parts,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+CATCH_ALL = frozenset({"BaseException", "Exception"})
+GROUP_CLASSES = frozenset({"BaseExceptionGroup", "ExceptionGroup"})
+
+
+@dataclass(frozen=True)
+class RaisedError:
+    """An exception a function can raise to its caller."""
+
+    symbol: str
+    exception: str
+    file_path: str
+    # Line of the ``raise``, or of the call it propagates from.
+    lineno: int
+    # Classes chained as ``__cause__`` with ``raise ... from err``.
+    causes: tuple[str, ...] = ()
+    # ``raise ... from None``: the original exception is hidden.
+    suppressed: bool = False
+    # Classes grouped in an ``ExceptionGroup``, matched with ``except*``.
+    members: tuple[str, ...] = ()
+    # The function of the tree it propagates from, when not raised directly.
+    via: str | None = None
+
+    def phrase(self, code: Callable[[str], str]) -> str:
+        """Describe the exception, with names formatted by ``code``."""
+        text = code(self.exception)
+        if self.members:
+            text += f" of {', '.join(code(member) for member in self.members)}"
+        if self.causes:
+            text += f" from {', '.join(code(cause) for cause in self.causes)}"
+        elif self.suppressed:
+            text += " (cause hidden)"
+        if self.via is not None:
+            text += f" via {code(self.via)}"
+        return text
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "symbol": self.symbol,
+            "exception": self.exception,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "causes": list(self.causes),
+            "suppressed": self.suppressed,
+            "members": list(self.members),
+            "via": self.via,
+        }
+
+
+def is_caught(exception: str, caught: frozenset[str]) -> bool:
+    """Whether an ``except`` for the classes ``caught`` catches ``exception``."""
+    return bool(caught & CATCH_ALL) or exception in caught
+
+
+_Raise = tuple[str, int, tuple[str, ...], bool, tuple[str, ...]]
+
+
+@dataclass(frozen=True)
+class _Call:
+    callee: str
+    lineno: int
+    caught: frozenset[str]
+
+
+class _FunctionScanner:
+    """The raises and calls of one function body."""
+
+    def __init__(
+        self,
+        info: ModuleImports,
+        local_names: set[str],
+        owner: str | None,
+    ) -> None:
+        self.info = info
+        self.local_names = local_names
+        # Qualified name of the class of a method, for ``self.method()``.
+        self.owner = owner
+        # (exception, line, causes, suppressed, members) in source order.
+        self.raises: list[_Raise] = []
+        self.calls: list[_Call] = []
+
+    def resolve(self, node: ast.expr) -> str | None:
+        parts = dotted_parts(node)
+        if parts is None:
+            return None
+        if self.owner is not None and len(parts) == 2 and parts[0] in ("self", "cls"):
+            return f"{self.owner}.{parts[1]}"
+        return resolve_name(node, self.info, self.local_names)
+
+    def _types(self, handler: ast.ExceptHandler) -> tuple[str, ...]:
+        if handler.type is None:
+            return ("BaseException",)
+        nodes = handler.type.elts if isinstance(handler.type, ast.Tuple) else [
+            handler.type,
+        ]
+        return tuple(name for name in map(self.resolve, nodes) if name is not None)
+
+    def scan(
+        self,
+        body: list[ast.stmt],
+        caught: frozenset[str] = frozenset(),
+        handled: tuple[tuple[str, ...], str | None] | None = None,
+    ) -> None:
+        for node in body:
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+                continue
+            if isinstance(node, (ast.Try, ast.TryStar)):
+                inner = caught.union(*(self._types(h) for h in node.handlers))
+                self.scan(node.body, inner, handled)
+                for handler in node.handlers:
+                    handled_here = (self._types(handler), handler.name)
+                    self.scan(handler.body, caught, handled_here)
+                self.scan(node.orelse, caught, handled)
+                self.scan(node.finalbody, caught, handled)
+                continue
+            if isinstance(node, ast.Raise):
+                self._raise(node, caught, handled)
+            for child in ast.iter_child_nodes(node):
+                if isinstance(child, ast.withitem):
+                    child = child.context_expr
+                if isinstance(child, ast.expr):
+                    self._calls(child, caught)
+            for field in ("body", "orelse", "finalbody"):
+                block = getattr(node, field, None)
+                if isinstance(block, list):
+                    self.scan(block, caught, handled)
+            if isinstance(node, ast.Match):
+                for case in node.cases:
+                    self.scan(case.body, caught, handled)
+
+    def _calls(self, node: ast.expr, caught: frozenset[str]) -> None:
+        for sub in ast.walk(node):
+            if isinstance(sub, ast.Call):
+                callee = self.resolve(sub.func)
+                if callee is not None:
+                    self.calls.append(_Call(callee, sub.lineno, caught))
+
+    def _class(self, node: ast.expr) -> str | None:
+        """The exception class ``node`` raises or names, if it looks like one."""
+        if isinstance(node, ast.Call):
+            node = node.func
+        name = self.resolve(node)
+        if name is None or not name.rpartition(".")[2][:1].isupper():
+            return None
+        return name
+
+    def _raise(
+        self,
+        node: ast.Raise,
+        caught: frozenset[str],
+        handled: tuple[tuple[str, ...], str | None] | None,
+    ) -> None:
+        handled_types, handled_name = handled or ((), None)
+        exc = node.exc
+        if exc is None or (handled_name is not None and _is(exc, handled_name)):
+            # Re-raising what the except block handles.
+            for name in handled_types:
+                if not is_caught(name, caught):
+                    self.raises.append((name, node.lineno, (), False, ()))
+            return
+        exception = self._class(exc)
+        if exception is None or is_caught(exception, caught):
+            return
+        causes: tuple[str, ...] = ()
+        suppressed = False
+        if isinstance(node.cause, ast.Constant) and node.cause.value is None:
+            suppressed = True
+        elif node.cause is not None:
+            if handled_name is not None and _is(node.cause, handled_name):
+                causes = handled_types
+            else:
+                cause = self._class(node.cause)
+                causes = () if cause is None else (cause,)
+        members: tuple[str, ...] = ()
+        if exception.rpartition(".")[2] in GROUP_CLASSES and isinstance(exc, ast.Call):
+            if len(exc.args) > 1 and isinstance(exc.args[1], (ast.List, ast.Tuple)):
+                members = tuple(
+                    name
+                    for name in map(self._class, exc.args[1].elts)
+                    if name is not None
+                )
+        self.raises.append((exception, node.lineno, causes, suppressed, members))
+
+
+def _is(node: ast.expr, name: str) -> bool:
+    return isinstance(node, ast.Name) and node.id == name
+
+
+def _functions(
+    body: list[ast.stmt],
+    prefix: str,
+    owner: str | None = None,
+) -> Iterator[tuple[str, str | None, ast.FunctionDef | ast.AsyncFunctionDef]]:
+    for node in body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+            yield f"{prefix}.{node.name}", owner, node
+        elif isinstance(node, ast.ClassDef):
+            qualified = f"{prefix}.{node.name}"
+            yield from _functions(node.body, qualified, qualified)
+
+
+def find_exception_chains(
+    root: str | Path,
+    graph: ImportGraph,
+) -> list[RaisedError]:
+    """What the functions of ``graph`` (built for ``root``) can raise.
+
+    Results are in module and function order, each function's exceptions
+    in line order. An exception both raised directly and propagated from a
+    callee is listed once, as raised directly.
+    """
+    order: list[str] = []
+    direct: dict[str, list[RaisedError]] = {}
+    calls: dict[str, list[_Call]] = {}
+    files: dict[str, str] = {}
+    for info, tree in parse_modules(graph, "exception chain analysis"):
+        rel = relative_path(info.file_path, root)
+        local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        }
+        for name, owner, func in _functions(tree.body, info.module):
+            scanner = _FunctionScanner(info, local_names, owner)
+            scanner.scan(func.body)
+            order.append(name)
+            files[name] = rel
+            calls[name] = scanner.calls
+            direct[name] = []
+            seen = set()
+            for exception, lineno, causes, suppressed, members in scanner.raises:
+                if (exception, causes, suppressed, members) not in seen:
+                    seen.add((exception, causes, suppressed, members))
+                    direct[name].append(
+                        RaisedError(
+                            name, exception, rel, lineno, causes, suppressed, members,
+                        ),
+                    )
+    raised = {name: list(errors) for name, errors in direct.items()}
+    changed = True
+    while changed:
+        changed = False
+        for name in order:
+            known = {error.exception for error in raised[name]}
+            for call in calls[name]:
+                for error in raised.get(call.callee, ()):
+                    if error.exception in known:
+                        continue
+                    if is_caught(error.exception, call.caught):
+                        continue
+                    known.add(error.exception)
+                    raised[name].append(
+                        RaisedError(
+                            name,
+                            error.exception,
+                            files[name],
+                            call.lineno,
+                            via=call.callee,
+                        ),
+                    )
+                    changed = True
+    return [
+        error
+        for name in order
+        for error in sorted(raised[name], key=lambda error: error.lineno)
+    ]
+
+
+__all__ = [
+    "CATCH_ALL",
+    "GROUP_CLASSES",
+    "RaisedError",
+    "find_exception_chains",
+    "is_caught",
+]
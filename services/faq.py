@@ -0,0 +1,286 @@
+"""FAQ and troubleshooting entries, gathered into the package pages.
+
+The answer to a question users keep asking is best kept next to the code
+it is about, in a docstring or a comment::
+
+    def total(items, discount=0):
+        \"\"\"Sum of the items less ``discount``.
+
+        @autodoc:faq Why can the total be negative?
+        A discount larger than the sum is not clamped; pass ``clamp=True``.
+        \"\"\"
+
+    # @autodoc:troubleshooting `ValueError: empty cart` on checkout
+    # Add an item to the cart before checking it out.
+
+The text after ``@autodoc:faq`` is the question, the text after
+``@autodoc:troubleshooting`` the symptom. The answer runs on over the
+following lines up to a blank line or the next directive, as release-note
+fragments do (see :mod:`services.release_notes`); in a comment, over the
+comment lines below. Directives in code blocks of a docstring (fenced,
+``::`` literal blocks, or indented below a blank line) are examples, like
+the one above, and are left alone. Longer answers go in sidecar files next
+to a package's modules, :data:`FAQ_FILES`: each ``##`` heading of a
+``FAQ.md`` or ``TROUBLESHOOTING.md`` is a question or symptom, and the
+Markdown below it, up to the next such heading, the answer.
+
+:func:`find_faq` collects the entries of each package, those of the
+sidecar files first; the package pages list them under the headings of
+:data:`FAQ_KINDS`. Entries are stripped from the docstrings
+(:func:`strip_faq`), so they are not shown twice.
+"""
+
+from __future__ import annotations
+
+import io
+import logging
+import re
+import tokenize
+from collections.abc import Iterable
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.doc_symbols import DocSymbol, relative_path
+from services.package_readme import outside_fences, parse_heading
+
+logger = logging.getLogger(__name__)
+
+# Kinds of entries, in page order, with the headings of their sections.
+FAQ_KINDS = {
+    "faq": "Frequently asked questions",
+    "troubleshooting": "Troubleshooting",
+}
+# Sidecar file of each kind, read from the directory of a package.
+FAQ_FILES = {"faq": "FAQ.md", "troubleshooting": "TROUBLESHOOTING.md"}
+_DIRECTIVE = re.compile(
+    r"^\s*@autodoc:(?P<kind>faq|troubleshooting)(?:\s+(?P<text>.*))?$",
+)
+
+
+@dataclass(frozen=True)
+class FaqEntry:
+    """One question, or troubleshooting symptom, and its answer."""
+
+    package: str
+    kind: str
+    question: str
+    # Markdown.
+    answer: str
+    file_path: str
+    lineno: int
+    # The symbol whose docstring or module holds the entry; None in a sidecar.
+    symbol: str | None = None
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "package": self.package,
+            "kind": self.kind,
+            "question": self.question,
+            "answer": self.answer,
+            "symbol": self.symbol,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+        }
+
+
+def _docstring_lines(docstring: str) -> Iterable[tuple[str, bool]]:
+    """Each line of ``docstring`` and whether it is prose (not a code block).
+
+    Code is fenced, the indented block after a line ending in ``::``, or a
+    block indented by four or more below a blank line.
+    """
+    # Indentation of the prose around the open code block.
+    block: int | None = None
+    indent = 0
+    literal = False
+    blank = True
+    for line, prose in outside_fences(docstring):
+        if not prose or not line.strip():
+            blank = not line.strip()
+            yield line, prose and block is None
+            continue
+        width = len(line) - len(line.lstrip())
+        if block is not None and width > block:
+            yield line, False
+            continue
+        block = None
+        if width > indent and (literal or (blank and width >= indent + 4)):
+            block = indent
+            yield line, False
+        else:
+            indent = width
+            literal = line.rstrip().endswith("::")
+            yield line, True
+        blank = False
+
+
+def _split(
+    lines: Iterable[tuple[str, bool]],
+) -> tuple[list[tuple[str, str, str]], list[str]]:
+    """The ``(kind, question, answer)`` entries of ``lines`` and the other lines.
+
+    ``lines`` are each line and whether it is prose; code is never an entry.
+    """
+    entries: list[tuple[str, str, list[str]]] = []
+    rest: list[str] = []
+    current: list[str] | None = None
+    for line, prose in lines:
+        match = _DIRECTIVE.match(line) if prose else None
+        if match is not None:
+            current = []
+            entries.append((match["kind"], (match["text"] or "").strip(), current))
+        elif current is not None and prose and line.strip():
+            current.append(line.strip())
+        else:
+            current = None
+            rest.append(line)
+    return [(kind, text, " ".join(answer)) for kind, text, answer in entries], rest
+
+
+def parse_faq(docstring: str | None) -> list[tuple[str, str, str]]:
+    """The ``(kind, question, answer)`` of each entry of ``docstring``."""
+    return _split(_docstring_lines(docstring))[0] if docstring else []
+
+
+def strip_faq(docstring: str | None) -> str | None:
+    """``docstring`` without its entries; ``None`` if nothing else is left."""
+    if not docstring or "@autodoc:" not in docstring:
+        return docstring
+    rest = _split(_docstring_lines(docstring))[1]
+    text = re.sub(r"\n{3,}", "\n\n", "\n".join(rest)).strip()
+    return text or None
+
+
+def comment_faq(source: str) -> list[tuple[str, str, str, int]]:
+    """The ``(kind, question, answer, line)`` entries of the comments of ``source``.
+
+    Only comments on lines of their own count; an entry's answer is the
+    comment lines right below it, up to an empty comment.
+    """
+    comments: dict[int, str] = {}
+    try:
+        for token in tokenize.generate_tokens(io.StringIO(source).readline):
+            column = token.start[1]
+            if token.type == tokenize.COMMENT and not token.line[:column].strip():
+                comments[token.start[0]] = token.string[1:]
+    except (tokenize.TokenError, SyntaxError):
+        return []
+    entries = []
+    for lineno in sorted(comments):
+        if _DIRECTIVE.match(comments[lineno]) is None:
+            continue
+        block = [comments[lineno]]
+        after = lineno + 1
+        while after in comments and _DIRECTIVE.match(comments[after]) is None:
+            block.append(comments[after])
+            after += 1
+        [(kind, question, answer)] = _split((line, True) for line in block)[0]
+        entries.append((kind, question, answer, lineno))
+    return entries
+
+
+def load_faq_file(path: str | Path) -> list[tuple[str, str, int]]:
+    """The ``(question, answer, line)`` sections of a sidecar file.
+
+    Text below a ``#`` heading, such as a title, is not part of an entry.
+    Missing or unreadable files have none.
+    """
+    path = Path(path)
+    if not path.is_file():
+        return []
+    try:
+        content = path.read_text(encoding="utf-8")
+    except (OSError, UnicodeDecodeError) as exc:
+        logger.warning("Skipping %s: %s", path, exc)
+        return []
+    sections: list[tuple[str, list[str], int]] = []
+    current: list[str] | None = None
+    for lineno, (line, prose) in enumerate(outside_fences(content), start=1):
+        heading = parse_heading(line) if prose else None
+        if heading is not None and heading[0] <= 2:
+            current = [] if heading[0] == 2 else None
+            if current is not None:
+                sections.append((heading[1].strip(), current, lineno))
+        elif current is not None:
+            current.append(line)
+    return [
+        (question, "\n".join(answer).strip(), lineno)
+        for question, answer, lineno in sections
+    ]
+
+
+def find_faq(symbols: Iterable[DocSymbol], root: str | Path) -> list[FaqEntry]:
+    """The entries of the packages of ``symbols``, given in page order.
+
+    Each package has the entries of its sidecar files first, then those of
+    its modules' docstrings and comments in line order; their paths are
+    relative to ``root``. Entries without a question or an answer are logged
+    and left out.
+    """
+    modules: dict[str, list[DocSymbol]] = {}
+    files: dict[str, list[DocSymbol]] = {}
+    for symbol in symbols:
+        if symbol.kind == "module":
+            modules.setdefault(symbol.package, []).append(symbol)
+        files.setdefault(symbol.file_path, []).append(symbol)
+    entries: list[FaqEntry] = []
+
+    def add(entry: FaqEntry) -> None:
+        if entry.question and entry.answer:
+            entries.append(entry)
+            return
+        logger.warning(
+            "Ignoring @autodoc:%s without %s at %s:%d",
+            entry.kind,
+            "an answer" if entry.question else "a question",
+            entry.file_path,
+            entry.lineno,
+        )
+
+    for package, members in modules.items():
+        directory = Path(members[0].file_path).parent
+        for kind, name in FAQ_FILES.items():
+            path = directory / name
+            for question, answer, lineno in load_faq_file(path):
+                rel = relative_path(path, root)
+                add(FaqEntry(package, kind, question, answer, rel, lineno))
+        for module in members:
+            found = [
+                (symbol.lineno, kind, question, answer, symbol.qualified_name)
+                for symbol in files[module.file_path]
+                for kind, question, answer in parse_faq(symbol.docstring)
+            ]
+            try:
+                source = Path(module.file_path).read_text(encoding="utf-8")
+            except (OSError, UnicodeDecodeError):
+                source = ""
+            found.extend(
+                (lineno, kind, question, answer, module.qualified_name)
+                for kind, question, answer, lineno in comment_faq(source)
+            )
+            found.sort(key=lambda item: item[0])
+            for lineno, kind, question, answer, name in found:
+                add(
+                    FaqEntry(
+                        package,
+                        kind,
+                        question,
+                        answer,
+                        relative_path(module.file_path, root),
+                        lineno,
+                        name,
+                    ),
+                )
+    return entries
+
+
+__all__ = [
+    "FAQ_FILES",
+    "FAQ_KINDS",
+    "FaqEntry",
+    "comment_faq",
+    "find_faq",
+    "load_faq_file",
+    "parse_faq",
+    "strip_faq",
+]
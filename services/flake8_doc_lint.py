@@ -0,0 +1,95 @@
+"""flake8 plugin that runs the documentation lint rules.
+
+Registering the rules as a flake8 extension lets editors and existing lint
+pipelines surface documentation findings next to style errors, using exactly
+the rules (built-in and ``autodoc.yaml`` custom ones) that ``autodoc lint``
+applies. Install AutoDoc into the flake8 environment and select ``ADC``::
+
+    flake8 --select ADC src/
+
+Built-in rules have fixed codes (see :data:`RULE_CODES`); custom rules all
+report as :data:`CUSTOM_RULE_CODE` with the rule id in the message.
+"""
+
+from __future__ import annotations
+
+import ast
+import logging
+from collections.abc import Iterator, Sequence
+from functools import lru_cache
+from pathlib import Path
+from typing import Any
+
+from autodoc.config.project import load_project_config
+from services.custom_lint_rules import rules_from_config
+from services.doc_lint import LintRule, lint_symbols
+from services.doc_symbols import DocSymbolLoader
+
+logger = logging.getLogger(__name__)
+
+PLUGIN_NAME = "autodoc"
+PLUGIN_VERSION = "1.0.0"
+
+RULE_CODES = {
+    "missing-docstring": "ADC100",
+    "spelling": "ADC200",
+    "terminology": "ADC201",
+}
+CUSTOM_RULE_CODE = "ADC900"
+
+
+@lru_cache(maxsize=8)
+def _configured_rules(root: str, config_path: str | None) -> tuple[LintRule, ...]:
+    config = load_project_config(root, config_path)
+    return tuple(rules_from_config(config.lint, config.base_dir(root)))
+
+
+class DocLintPlugin:
+    """flake8 AST checker wrapping :func:`services.doc_lint.lint_symbols`."""
+
+    name = PLUGIN_NAME
+    version = PLUGIN_VERSION
+
+    config_path: str | None = None
+
+    def __init__(
+        self,
+        tree: ast.Module,
+        filename: str,
+        rules: Sequence[LintRule] | None = None,
+    ) -> None:
+        self.tree = tree
+        self.filename = filename
+        self._rules = rules
+
+    @classmethod
+    def add_options(cls, option_manager: Any) -> None:
+        option_manager.add_option(
+            "--autodoc-config",
+            default=None,
+            parse_from_config=True,
+            help="AutoDoc project config (default: autodoc.yaml in the working directory)",
+        )
+
+    @classmethod
+    def parse_options(cls, options: Any) -> None:
+        cls.config_path = options.autodoc_config
+
+    def _rules_for_run(self) -> Sequence[LintRule]:
+        if self._rules is not None:
+            return self._rules
+        return _configured_rules(str(Path.cwd()), self.config_path)
+
+    def run(self) -> Iterator[tuple[int, int, str, type]]:
+        """Yield ``(line, column, message, type)`` tuples as flake8 expects."""
+        symbols = DocSymbolLoader(Path.cwd()).load_tree(self.tree, self.filename)
+        for finding in lint_symbols(symbols, self._rules_for_run()):
+            code = RULE_CODES.get(finding.rule)
+            if code is None:
+                message = f"{CUSTOM_RULE_CODE} [{finding.rule}] {finding.message}"
+            else:
+                message = f"{code} {finding.message}"
+            yield finding.lineno, 0, message, type(self)
+
+
+__all__ = ["CUSTOM_RULE_CODE", "RULE_CODES", "DocLintPlugin"]
@@ -0,0 +1,238 @@
+"""Thin wrappers around the git CLI for reading sources at specific revisions.
+
+The documentation checks need file contents from the index (pre-commit hooks)
+and from historical commits (branch comparisons). These helpers shell out to
+``git`` and return text, raising :class:`GitError` on failure.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+import subprocess
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+# Revision marker meaning "the staged version in the index".
+INDEX = ":"
+
+_HUNK_HEADER = re.compile(r"^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@")
+
+
+class GitError(Exception):
+    """Raised when a git command fails."""
+
+
+def _run_git(repo: str | Path, *args: str) -> str:
+    try:
+        result = subprocess.run(
+            ["git", *args],
+            cwd=str(repo),
+            capture_output=True,
+            text=True,
+            check=True,
+        )
+    except FileNotFoundError as exc:
+        raise GitError("git executable not found") from exc
+    except subprocess.CalledProcessError as exc:
+        raise GitError(
+            f"git {' '.join(args)} failed: {exc.stderr.strip()}",
+        ) from exc
+    return result.stdout
+
+
+def repo_root(path: str | Path = ".") -> Path:
+    """Return the top-level directory of the repository containing ``path``."""
+    return Path(_run_git(path, "rev-parse", "--show-toplevel").strip())
+
+
+def _split_paths(output: str) -> list[str]:
+    return sorted(line for line in output.splitlines() if line.strip())
+
+
+def staged_files(repo: str | Path, suffix: str = ".py") -> list[str]:
+    """List added/copied/modified/renamed files in the index ending in ``suffix``.
+
+    Paths are repository-relative in POSIX form.
+    """
+    output = _run_git(
+        repo,
+        "diff",
+        "--cached",
+        "--name-only",
+        "--diff-filter=ACMR",
+    )
+    return [path for path in _split_paths(output) if path.endswith(suffix)]
+
+
+def changed_files(
+    repo: str | Path,
+    base: str,
+    head: str = "HEAD",
+    suffix: str = ".py",
+) -> list[str]:
+    """List files changed on ``head`` since it diverged from ``base``.
+
+    Uses the merge base (``base...head``), matching what a pull request shows.
+    """
+    output = _run_git(
+        repo,
+        "diff",
+        "--name-only",
+        "--diff-filter=ACMR",
+        f"{base}...{head}",
+    )
+    return [path for path in _split_paths(output) if path.endswith(suffix)]
+
+
+def changed_line_ranges(
+    repo: str | Path,
+    base: str,
+    head: str = "HEAD",
+    suffix: str = ".py",
+) -> dict[str, list[tuple[int, int]]]:
+    """Map each changed file to the line ranges (in ``head``) touched since ``base``.
+
+    Ranges are inclusive ``(start, end)`` pairs. Pure deletions are reported
+    as a single-line range on the line preceding the deletion, so the symbol
+    that lost lines still counts as touched. Added files span every line.
+    """
+    output = _run_git(
+        repo,
+        "diff",
+        "--unified=0",
+        "--no-color",
+        "--diff-filter=ACMR",
+        f"{base}...{head}",
+    )
+    ranges: dict[str, list[tuple[int, int]]] = {}
+    current: str | None = None
+    for line in output.splitlines():
+        if line.startswith("+++ "):
+            target = line[4:].strip()
+            current = target[2:] if target.startswith("b/") else None
+            if current is not None and not current.endswith(suffix):
+                current = None
+            if current is not None:
+                ranges.setdefault(current, [])
+            continue
+        if current is None:
+            continue
+        match = _HUNK_HEADER.match(line)
+        if not match:
+            continue
+        start = int(match.group(1))
+        count = int(match.group(2)) if match.group(2) is not None else 1
+        if count == 0:
+            ranges[current].append((max(start, 1), max(start, 1)))
+        else:
+            ranges[current].append((start, start + count - 1))
+    return ranges
+
+
+def merge_base(repo: str | Path, base: str, head: str = "HEAD") -> str:
+    """Return the merge-base commit of ``base`` and ``head``."""
+    return _run_git(repo, "merge-base", base, head).strip()
+
+
+def show_file(repo: str | Path, revision: str, path: str) -> str | None:
+    """Return ``path`` as of ``revision``, or ``None`` if it does not exist there.
+
+    Pass :data:`INDEX` as the revision to read the staged blob.
+    """
+    spec = f":{path}" if revision == INDEX else f"{revision}:{path}"
+    try:
+        return _run_git(repo, "show", spec)
+    except GitError:
+        logger.debug("%s does not exist at %s", path, revision or "index")
+        return None
+
+
+def tags(repo: str | Path) -> list[str]:
+    """List the repository's tags."""
+    return _split_paths(_run_git(repo, "tag", "--list"))
+
+
+def head_commit(path: str | Path = ".") -> str:
+    """Return the full hash of the commit checked out at ``path``."""
+    return _run_git(path, "rev-parse", "--verify", "HEAD").strip()
+
+
+def commit_before(repo: str | Path, when: str, head: str = "HEAD") -> str | None:
+    """The last commit of ``head`` made before ``when``, if there is one.
+
+    ``when`` is anything ``git log --before`` accepts, such as an ISO date.
+    """
+    output = _run_git(repo, "rev-list", "-1", f"--before={when}", head)
+    return output.strip() or None
+
+
+def commit_count(repo: str | Path, base: str | None, head: str = "HEAD") -> int:
+    """Number of commits on ``head`` since ``base`` (all of them without one)."""
+    spec = f"{base}..{head}" if base else head
+    return int(_run_git(repo, "rev-list", "--count", spec).strip() or 0)
+
+
+def tracked_files(
+    repo: str | Path,
+    revision: str,
+    prefix: str = "",
+    suffix: str = ".py",
+) -> list[str]:
+    """List the files of ``revision`` below ``prefix`` ending in ``suffix``.
+
+    Paths are repository-relative in POSIX form.
+    """
+    args = ["ls-tree", "-r", "--name-only", revision]
+    if prefix:
+        args += ["--", prefix]
+    output = _run_git(repo, *args)
+    return [path for path in _split_paths(output) if path.endswith(suffix)]
+
+
+def clone(url: str, dest: str | Path, ref: str | None = None) -> Path:
+    """A shallow checkout of ``ref`` (default: the remote HEAD) of ``url``.
+
+    An existing checkout at ``dest`` is fetched and moved to ``ref`` rather
+    than cloned again.
+    """
+    target = Path(dest)
+    if (target / ".git").exists():
+        _run_git(target, "fetch", "--quiet", "--depth", "1", "origin", ref or "HEAD")
+        _run_git(target, "checkout", "--quiet", "--detach", "FETCH_HEAD")
+        return target
+    target.parent.mkdir(parents=True, exist_ok=True)
+    args = ["clone", "--quiet", "--depth", "1"]
+    if ref:
+        args += ["--branch", ref]
+    _run_git(target.parent, *args, url, str(target.resolve()))
+    return target
+
+
+def has_changes(path: str | Path = ".", *pathspecs: str) -> bool:
+    """Whether files below ``path`` differ from ``HEAD`` or are untracked.
+
+    ``pathspecs`` (relative to ``path``) narrow the check, e.g. to ``*.py``.
+    """
+    output = _run_git(path, "status", "--porcelain", "--", *(pathspecs or ["."]))
+    return bool(output.strip())
+
+
+__all__ = [
+    "INDEX",
+    "GitError",
+    "changed_files",
+    "changed_line_ranges",
+    "clone",
+    "commit_before",
+    "commit_count",
+    "has_changes",
+    "head_commit",
+    "merge_base",
+    "repo_root",
+    "show_file",
+    "staged_files",
+    "tags",
+    "tracked_files",
+]
@@ -0,0 +1,277 @@
+"""Glossary of the domain terms a code base uses.
+
+:func:`build_glossary` extracts terms from the names of classes and
+modules, which name the things a code base deals in: every word of a
+``snake_case`` or ``CamelCase`` name, plus the whole phrase of a multi-word
+one (``PaymentProvider`` gives "payment provider"). A term's mentions are the
+number of symbols whose name or docstring uses it, so function names and
+prose never supply a term but still count towards one.
+Plurals are folded onto the singular, and :data:`STOPWORDS` drops the
+vocabulary every Python code base shares (``get``, ``value``, ``self``).
+
+Each term links to its defining symbols, the classes, functions, and modules
+named exactly after it, and takes its definition from the first one's
+summary. A glossary file (``site.glossary.file`` in ``autodoc.yaml``) adds
+hand-written terms and definitions, which always appear and take precedence::
+
+    invoice: A bill sent to a customer once an order ships.
+    settlement:
+      definition: Moving captured payments to the merchant's account.
+      see: [billing.payouts.settle]
+"""
+
+from __future__ import annotations
+
+import re
+from collections import Counter, defaultdict
+from collections.abc import Iterable, Mapping
+from dataclasses import dataclass
+from pathlib import Path
+
+import yaml
+
+from autodoc.config.project import GlossaryConfig
+from services.doc_site import summary
+from services.doc_symbols import DocSymbol
+
+# Symbols whose exact name defines a term, most authoritative first.
+DEFINING_KINDS = ("class", "function", "module")
+# Symbols whose names supply terms. Function names are mostly verbs, so they
+# only count as mentions.
+NAMING_KINDS = ("class", "module")
+
+STOPWORDS = frozenset(
+    """
+    about above after again all also and any are arg args base been before being
+    between bool both build but call can class cls config could create data default
+    delete dict does each else error false file first for from function get has have
+    helper how impl init int into invalid is item items its key kwargs list load
+    make may method missing module more must name new none not obj object one only
+    option other our out over param parse path read result return returns run same
+    self set should some str such than that the their them then there these they
+    this those through tmp too true type under update use used uses using util utils
+    val value values was way were what when where which while who will with without
+    would write yield you your
+    """.split(),
+)
+
+_CAMEL = re.compile(r"[A-Z]+(?=[A-Z][a-z])|[A-Z]?[a-z]+|[A-Z]+|\d+")
+_WORD = re.compile(r"[A-Za-z]+")
+
+
+class GlossaryError(Exception):
+    """Raised for a glossary file that cannot be read or has a bad entry."""
+
+
+@dataclass(frozen=True)
+class GlossaryTerm:
+    """A domain term with its definition and the symbols defining it."""
+
+    term: str
+    definition: str
+    mentions: int
+    # Qualified names of the defining symbols, plus ``see`` references.
+    defined_by: tuple[str, ...]
+    # ``file`` for terms from the glossary file, else ``extracted``.
+    source: str
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "term": self.term,
+            "definition": self.definition,
+            "mentions": self.mentions,
+            "defined_by": list(self.defined_by),
+            "source": self.source,
+        }
+
+
+@dataclass(frozen=True)
+class GlossaryEntry:
+    """A hand-written glossary file entry."""
+
+    definition: str
+    see: tuple[str, ...] = ()
+
+
+def singular(word: str) -> str:
+    """``word`` with a regular English plural ending removed."""
+    if len(word) > 4 and word.endswith("ies"):
+        return word[:-3] + "y"
+    if len(word) > 4 and word.endswith(("sses", "xes", "ches", "shes")):
+        return word[:-2]
+    if len(word) > 3 and word.endswith("s") and not word.endswith(("ss", "us", "is")):
+        return word[:-1]
+    return word
+
+
+def name_words(name: str) -> list[str]:
+    """The lowercase, singular words of an identifier."""
+    words = []
+    for part in name.split("_"):
+        words.extend(singular(word.lower()) for word in _CAMEL.findall(part))
+    return [word for word in words if not word.isdigit()]
+
+
+def _prose_words(text: str | None) -> list[str]:
+    return [singular(word.lower()) for word in _WORD.findall(text or "")]
+
+
+def _contains(words: list[str], phrase: tuple[str, ...]) -> bool:
+    size = len(phrase)
+    return any(
+        tuple(words[i : i + size]) == phrase for i in range(len(words) - size + 1)
+    )
+
+
+def _is_candidate(phrase: tuple[str, ...], exclude: set[str]) -> bool:
+    return (
+        " ".join(phrase) not in exclude
+        and all(len(word) > 2 and word not in STOPWORDS for word in phrase)
+    )
+
+
+def load_glossary_file(path: str | Path) -> dict[str, GlossaryEntry]:
+    """Read a glossary file mapping terms to definitions.
+
+    Each term maps to its definition, or to a mapping with ``definition`` and
+    an optional ``see`` list of qualified names to link.
+
+    Raises:
+        GlossaryError: If the file cannot be read or an entry is malformed
+    """
+    path = Path(path)
+    try:
+        data = yaml.safe_load(path.read_text(encoding="utf-8")) or {}
+    except (OSError, yaml.YAMLError) as exc:
+        raise GlossaryError(f"Cannot read glossary file {path}: {exc}") from exc
+    if not isinstance(data, dict):
+        raise GlossaryError(f"{path}: the glossary must map terms to definitions")
+
+    terms = {}
+    for term, entry in data.items():
+        if isinstance(entry, str):
+            entry = {"definition": entry}
+        if not isinstance(entry, dict) or not isinstance(entry.get("definition"), str):
+            raise GlossaryError(f"{path}: term {term!r} needs a definition")
+        see = entry.get("see", [])
+        if isinstance(see, str):
+            see = [see]
+        if not isinstance(see, list) or not all(isinstance(name, str) for name in see):
+            raise GlossaryError(f"{path}: see of {term!r} must be a list of names")
+        terms[" ".join(name_words(str(term).replace(" ", "_")))] = GlossaryEntry(
+            entry["definition"].strip(),
+            tuple(see),
+        )
+    return terms
+
+
+def build_glossary(
+    symbols: Iterable[DocSymbol],
+    config: GlossaryConfig | None = None,
+    user_terms: Mapping[str, GlossaryEntry] | None = None,
+) -> list[GlossaryTerm]:
+    """The glossary of ``symbols`` merged with ``user_terms``.
+
+    Extracted terms need at least ``config.min_mentions`` mentions; the
+    ``config.limit`` most mentioned are kept. Terms from the glossary file are
+    always kept.
+
+    Returns:
+        Terms sorted alphabetically
+    """
+    config = config or GlossaryConfig()
+    user_terms = user_terms or {}
+    exclude = {" ".join(name_words(term.replace(" ", "_"))) for term in config.exclude}
+    symbols = list(symbols)
+    names = {symbol.qualified_name: name_words(symbol.name) for symbol in symbols}
+
+    candidates: set[tuple[str, ...]] = {
+        tuple(term.split()) for term in user_terms
+    }
+    for symbol in symbols:
+        words = names[symbol.qualified_name]
+        if symbol.kind in NAMING_KINDS:
+            candidates.update((word,) for word in words)
+            if len(words) > 1:
+                candidates.add(tuple(words))
+
+    phrases = [phrase for phrase in candidates if len(phrase) > 1]
+    mentions: Counter[tuple[str, ...]] = Counter()
+    defined_by: dict[tuple[str, ...], list[DocSymbol]] = defaultdict(list)
+    for symbol in symbols:
+        words = names[symbol.qualified_name]
+        prose = _prose_words(symbol.docstring)
+        mentions.update((word,) for word in {*words, *prose})
+        for phrase in phrases:
+            if _contains(words, phrase) or _contains(prose, phrase):
+                mentions[phrase] += 1
+        if symbol.kind in DEFINING_KINDS:
+            defined_by[tuple(words)].append(symbol)
+
+    extracted = sorted(
+        (
+            phrase
+            for phrase in candidates
+            if " ".join(phrase) not in user_terms
+            and _is_candidate(phrase, exclude)
+            and mentions[phrase] >= config.min_mentions
+        ),
+        key=lambda phrase: (-mentions[phrase], phrase),
+    )[: config.limit]
+
+    terms = []
+    for phrase in [*(tuple(term.split()) for term in user_terms), *extracted]:
+        term = " ".join(phrase)
+        definers = sorted(
+            defined_by.get(phrase, []),
+            key=lambda s: (DEFINING_KINDS.index(s.kind), s.qualified_name),
+        )
+        user = user_terms.get(term)
+        definition = user.definition if user else ""
+        if not definition:
+            definition = next(
+                (summary(s.docstring) for s in definers if summary(s.docstring)),
+                "",
+            )
+        links = [s.qualified_name for s in definers]
+        if user:
+            links.extend(name for name in user.see if name not in links)
+        terms.append(
+            GlossaryTerm(
+                term=term,
+                definition=definition,
+                mentions=mentions[phrase],
+                defined_by=tuple(links),
+                source="file" if user else "extracted",
+            ),
+        )
+    return sorted(terms, key=lambda t: t.term)
+
+
+def site_glossary(
+    symbols: Iterable[DocSymbol],
+    config: GlossaryConfig,
+    base_dir: str | Path,
+) -> list[GlossaryTerm]:
+    """The site's glossary, reading ``config.file`` relative to ``base_dir``.
+
+    Raises:
+        GlossaryError: If the glossary file cannot be read
+    """
+    user_terms = load_glossary_file(Path(base_dir) / config.file) if config.file else {}
+    return build_glossary(symbols, config, user_terms)
+
+
+__all__ = [
+    "DEFINING_KINDS",
+    "NAMING_KINDS",
+    "STOPWORDS",
+    "GlossaryEntry",
+    "GlossaryError",
+    "GlossaryTerm",
+    "build_glossary",
+    "load_glossary_file",
+    "name_words",
+    "singular",
+    "site_glossary",
+]
@@ -0,0 +1,147 @@
+"""Export a :class:`~services.relation_graph.RelationGraph` for graph tooling.
+
+Three formats are supported:
+
+- ``graphml``: one GraphML document, for yEd, Gephi, NetworkX, and similar;
+- ``dot``: one Graphviz digraph, with modules, classes, and functions drawn
+  as different shapes;
+- ``neo4j``: ``nodes.csv`` and ``relationships.csv`` with the headers
+  ``neo4j-admin database import`` expects. Node labels are the symbol kinds
+  (``Module``, ``Class``, ...) and relationship types the upper-cased edge
+  kinds (``CALLS``, ``IMPORTS``, ...).
+
+Nodes and edges are written in graph order, so exports are byte-stable and
+can be committed and diffed.
+"""
+
+from __future__ import annotations
+
+import csv
+import io
+from xml.sax.saxutils import escape, quoteattr
+
+from services.relation_graph import RelationGraph
+
+GRAPH_FORMATS = ("graphml", "dot", "neo4j")
+NEO4J_NODES = "nodes.csv"
+NEO4J_RELATIONSHIPS = "relationships.csv"
+
+# GraphML attribute keys: (id, name, type), in the order of the values below.
+_NODE_KEYS = (
+    ("kind", "kind", "string"),
+    ("package", "package", "string"),
+    ("file", "file_path", "string"),
+    ("line", "lineno", "int"),
+    ("public", "is_public", "boolean"),
+)
+_EDGE_KEYS = (
+    ("rel", "kind", "string"),
+    ("efile", "file_path", "string"),
+    ("eline", "lineno", "int"),
+)
+_DOT_SHAPES = {
+    "module": "folder",
+    "class": "box",
+    "function": "ellipse",
+    "method": "ellipse",
+}
+
+
+def _data(keys: tuple[tuple[str, str, str], ...], values: tuple[object, ...]) -> str:
+    parts = []
+    for (key, _, _), value in zip(keys, values, strict=True):
+        text = str(value).lower() if isinstance(value, bool) else str(value)
+        parts.append(f'<data key="{key}">{escape(text)}</data>')
+    return "".join(parts)
+
+
+def render_graphml(graph: RelationGraph) -> str:
+    """``graph`` as a GraphML document."""
+    lines = [
+        '<?xml version="1.0" encoding="UTF-8"?>',
+        '<graphml xmlns="http://graphml.graphdrawing.org/xmlns">',
+    ]
+    for scope, keys in (("node", _NODE_KEYS), ("edge", _EDGE_KEYS)):
+        for key, name, kind in keys:
+            lines.append(
+                f'  <key id="{key}" for="{scope}" attr.name="{name}" '
+                f'attr.type="{kind}"/>',
+            )
+    lines.append('  <graph id="autodoc" edgedefault="directed">')
+    for node in graph.nodes:
+        data = _data(
+            _NODE_KEYS,
+            (node.kind, node.package, node.file_path, node.lineno, node.is_public),
+        )
+        lines.append(f"    <node id={quoteattr(node.id)}>{data}</node>")
+    for index, edge in enumerate(graph.edges):
+        data = _data(_EDGE_KEYS, (edge.kind, edge.file_path, edge.lineno))
+        lines.append(
+            f'    <edge id="e{index}" source={quoteattr(edge.source)} '
+            f"target={quoteattr(edge.target)}>{data}</edge>",
+        )
+    lines.extend(["  </graph>", "</graphml>"])
+    return "\n".join(lines) + "\n"
+
+
+def _dot_id(value: str) -> str:
+    escaped = value.replace("\\", "\\\\").replace('"', '\\"')
+    return f'"{escaped}"'
+
+
+def render_dot(graph: RelationGraph) -> str:
+    """``graph`` as a Graphviz digraph."""
+    lines = ["digraph autodoc {", "  rankdir=LR;", "  node [fontsize=10];"]
+    for node in graph.nodes:
+        shape = _DOT_SHAPES.get(node.kind, "ellipse")
+        lines.append(f"  {_dot_id(node.id)} [shape={shape}, kind={node.kind}];")
+    for edge in graph.edges:
+        lines.append(
+            f"  {_dot_id(edge.source)} -> {_dot_id(edge.target)} "
+            f"[label={edge.kind}];",
+        )
+    lines.append("}")
+    return "\n".join(lines) + "\n"
+
+
+def _csv(rows: list[list[object]]) -> str:
+    buffer = io.StringIO()
+    writer = csv.writer(buffer, lineterminator="\n")
+    writer.writerows(rows)
+    return buffer.getvalue()
+
+
+def render_neo4j_csv(graph: RelationGraph) -> dict[str, str]:
+    """``graph`` as Neo4j bulk-import CSV files, keyed by file name."""
+    nodes: list[list[object]] = [
+        ["id:ID", ":LABEL", "package", "file_path", "lineno:int", "is_public:boolean"],
+    ]
+    nodes.extend(
+        [
+            node.id,
+            node.kind.capitalize(),
+            node.package,
+            node.file_path,
+            node.lineno,
+            str(node.is_public).lower(),
+        ]
+        for node in graph.nodes
+    )
+    relationships: list[list[object]] = [
+        [":START_ID", ":END_ID", ":TYPE", "file_path", "lineno:int"],
+    ]
+    relationships.extend(
+        [edge.source, edge.target, edge.kind.upper(), edge.file_path, edge.lineno]
+        for edge in graph.edges
+    )
+    return {NEO4J_NODES: _csv(nodes), NEO4J_RELATIONSHIPS: _csv(relationships)}
+
+
+__all__ = [
+    "GRAPH_FORMATS",
+    "NEO4J_NODES",
+    "NEO4J_RELATIONSHIPS",
+    "render_dot",
+    "render_graphml",
+    "render_neo4j_csv",
+]
@@ -0,0 +1,158 @@
+"""Hand-written guides built into the site next to the API reference.
+
+Every Markdown file under the guides directory (``site.guides`` in
+``autodoc.yaml``, ``docs/guides`` by default) becomes a page of the site,
+named :data:`GUIDE_SLUG_PREFIX` plus its path (``guides/deploy/aws.md`` is
+``guide-deploy-aws``) and listed on the index under
+:data:`~services.doc_site.GUIDES_HEADING`. The first ``#`` heading is the
+page title; the other headings move down a level below it.
+
+Guides link into the reference with a shortcode naming a documented
+symbol, which :func:`expand_shortcodes` turns into a link to its entry::
+
+    Start a checkout with {{symbol "shop.cart.Cart"}}.
+
+Links between guides (``[Deploying](deploy/aws.md)``) point at the other
+guide's page. Shortcodes and links in code blocks are left alone.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+from collections.abc import Container, Mapping
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.package_readme import outside_fences, parse_heading
+
+logger = logging.getLogger(__name__)
+
+GUIDE_SLUG_PREFIX = "guide-"
+_SHORTCODE = re.compile(r"\{\{\s*symbol\s+\"(?P<name>[^\"]+)\"\s*\}\}")
+_GUIDE_LINK = re.compile(
+    r"(?<!!)\[(?P<text>[^\[\]]*)\]"
+    r"\((?P<target>[^)\s#:]+\.md)(?P<fragment>#[^)\s]*)?\)",
+)
+
+
+@dataclass(frozen=True)
+class Guide:
+    """One guide page."""
+
+    slug: str
+    title: str
+    # Path of the source file, relative to the guides directory.
+    path: str
+    # The Markdown after the title, with links to other guides resolved.
+    content: str
+
+    def symbols(self) -> list[str]:
+        """The qualified names the guide's shortcodes refer to, in order."""
+        return list(
+            dict.fromkeys(
+                match["name"]
+                for line, prose in outside_fences(self.content)
+                if prose
+                for match in _SHORTCODE.finditer(line)
+            ),
+        )
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "slug": self.slug,
+            "title": self.title,
+            "path": self.path,
+            "content": self.content,
+            "symbols": self.symbols(),
+        }
+
+
+def _split_title(markdown: str, fallback: str) -> tuple[str, str]:
+    """The title of a guide and the Markdown after it."""
+    lines = markdown.split("\n")
+    for index, (line, prose) in enumerate(outside_fences(markdown)):
+        heading = parse_heading(line) if prose else None
+        if heading is not None and heading[0] == 1:
+            rest = lines[:index] + lines[index + 1 :]
+            return heading[1], "\n".join(rest).strip("\n")
+        if line.strip():
+            break
+    return fallback, markdown.strip("\n")
+
+
+def _link_guides(markdown: str, source: Path, slugs: Mapping[Path, str]) -> str:
+    def swap(match: re.Match[str]) -> str:
+        slug = slugs.get((source.parent / match["target"]).resolve())
+        if slug is None:
+            return match.group()
+        return f"[{match['text']}]({slug}.md{match['fragment'] or ''})"
+
+    return "\n".join(
+        _GUIDE_LINK.sub(swap, line) if prose else line
+        for line, prose in outside_fences(markdown)
+    )
+
+
+def load_guides(directory: str | Path, documented: Container[str]) -> list[Guide]:
+    """The guides of ``directory``, in path order; none if it does not exist.
+
+    Shortcodes naming symbols not in ``documented`` are logged, and files
+    that cannot be read are skipped with a warning.
+    """
+    directory = Path(directory)
+    if not directory.is_dir():
+        return []
+    sources: dict[Path, tuple[str, str]] = {}
+    for path in sorted(directory.rglob("*.md")):
+        relative = path.relative_to(directory).with_suffix("")
+        try:
+            text = path.read_text(encoding="utf-8")
+        except (OSError, UnicodeDecodeError) as exc:
+            logger.warning("Skipping guide %s: %s", path, exc)
+            continue
+        fallback = relative.name.replace("-", " ").replace("_", " ").capitalize()
+        sources[path] = _split_title(text, fallback)
+    slugs = {
+        path.resolve(): GUIDE_SLUG_PREFIX
+        + "-".join(path.relative_to(directory).with_suffix("").parts)
+        for path in sources
+    }
+    guides = []
+    for path, (title, content) in sources.items():
+        guide = Guide(
+            slugs[path.resolve()],
+            title,
+            path.relative_to(directory).as_posix(),
+            _link_guides(content, path, slugs),
+        )
+        for name in guide.symbols():
+            if name not in documented:
+                logger.warning("%s: no documented symbol %s", path, name)
+        guides.append(guide)
+    return guides
+
+
+def expand_shortcodes(markdown: str, links: Mapping[str, str]) -> str:
+    """``markdown`` with each shortcode a link to the page in ``links``.
+
+    ``links`` maps qualified names to URLs; other names are shown as code.
+    """
+
+    def swap(match: re.Match[str]) -> str:
+        name = match["name"]
+        url = links.get(name)
+        return f"[`{name}`]({url})" if url else f"`{name}`"
+
+    return "\n".join(
+        _SHORTCODE.sub(swap, line) if prose else line
+        for line, prose in outside_fences(markdown)
+    )
+
+
+__all__ = [
+    "GUIDE_SLUG_PREFIX",
+    "Guide",
+    "expand_shortcodes",
+    "load_guides",
+]
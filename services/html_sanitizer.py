@@ -0,0 +1,315 @@
+"""Sanitizing the HTML of user-authored doc content.
+
+Docstrings, READMEs, guides, and the theme's HTML hooks (``extra_head``,
+``header_html``, ``footer_html``) are written by whoever contributes to a
+tree, and the HTML site (or a portal of many, see :mod:`services.doc_portal`)
+publishes them to everyone reading it. :class:`HtmlSanitizer` lets the HTML
+in them through only as far as an allowlist does:
+
+- tags of :data:`DEFAULT_TAGS` are kept, with the attributes of
+  :data:`DEFAULT_ATTRIBUTES`; any other attribute is dropped;
+- any other tag is escaped, so ``Add <item>.`` still reads as written and a
+  ``<script>`` shows as text instead of running;
+- URL attributes (``href``, ``src``, ...) must be relative or use a scheme of
+  :data:`DEFAULT_SCHEMES`, else they are dropped;
+- comments, declarations, and processing instructions are dropped;
+- tags left open are closed at the end of the fragment, and end tags
+  without a start are escaped, so a fragment cannot break the page around
+  it.
+
+``extra_head`` may also hold the :data:`HEAD_TAGS`. ``site.sanitize`` in
+``autodoc.yaml`` extends the allowlist (see :func:`build_sanitizer`).
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Iterable
+from html import escape
+from html.parser import HTMLParser
+
+from autodoc.config.project import SanitizeConfig
+
+DEFAULT_TAGS = frozenset(
+    {
+        "a",
+        "abbr",
+        "b",
+        "blockquote",
+        "br",
+        "caption",
+        "code",
+        "dd",
+        "del",
+        "details",
+        "div",
+        "dl",
+        "dt",
+        "em",
+        "figcaption",
+        "figure",
+        "h1",
+        "h2",
+        "h3",
+        "h4",
+        "h5",
+        "h6",
+        "hr",
+        "i",
+        "img",
+        "ins",
+        "kbd",
+        "li",
+        "mark",
+        "ol",
+        "p",
+        "pre",
+        "q",
+        "s",
+        "samp",
+        "small",
+        "span",
+        "strong",
+        "sub",
+        "summary",
+        "sup",
+        "table",
+        "tbody",
+        "td",
+        "tfoot",
+        "th",
+        "thead",
+        "tr",
+        "u",
+        "ul",
+        "var",
+    },
+)
+# ``name`` is allowed on every kept tag, ``tag.name`` on that tag only.
+DEFAULT_ATTRIBUTES = frozenset(
+    {
+        "align",
+        "class",
+        "dir",
+        "lang",
+        "title",
+        "a.href",
+        "a.name",
+        "blockquote.cite",
+        "del.cite",
+        "details.open",
+        "img.alt",
+        "img.height",
+        "img.src",
+        "img.width",
+        "ins.cite",
+        "ol.start",
+        "q.cite",
+        "td.colspan",
+        "td.rowspan",
+        "th.colspan",
+        "th.rowspan",
+        "th.scope",
+    },
+)
+DEFAULT_SCHEMES = ("http", "https", "mailto")
+# What ``extra_head`` may hold besides: icons, stylesheets, and metadata.
+HEAD_TAGS = frozenset({"link", "meta"})
+HEAD_ATTRIBUTES = frozenset(
+    {
+        "link.href",
+        "link.rel",
+        "link.sizes",
+        "link.type",
+        "meta.content",
+        "meta.name",
+        "meta.property",
+    },
+)
+# Attributes holding a URL, whose scheme is checked.
+URL_ATTRIBUTES = frozenset(
+    {"action", "background", "cite", "formaction", "href", "poster", "src"},
+)
+_VOID = frozenset(
+    {"area", "br", "col", "hr", "img", "input", "link", "meta", "source", "wbr"},
+)
+# Elements whose content is not HTML.
+_RAW_TEXT = frozenset({"script", "style"})
+_SCHEME = re.compile(r"^(?P<scheme>[A-Za-z][A-Za-z0-9+.-]*):")
+# Browsers ignore control characters and spaces in a URL's scheme.
+_IGNORED = re.compile(r"[\x00-\x20]")
+_TAG = re.compile(r"^\s*</?(?P<name>[A-Za-z][A-Za-z0-9-]*)")
+
+
+class HtmlFragment:
+    """Escaped text and trusted markup, joined into HTML.
+
+    The renderers write a fragment the same way whether its user text is
+    escaped (this class) or sanitized (:meth:`HtmlSanitizer.fragment`).
+    """
+
+    def __init__(self) -> None:
+        self.parts: list[str] = []
+
+    def text(self, text: str) -> None:
+        """Add user-authored ``text``."""
+        self.parts.append(escape(text))
+
+    def markup(self, html: str) -> None:
+        """Add ``html`` generated by the renderer, as is."""
+        self.parts.append(html)
+
+    def html(self) -> str:
+        """The HTML of the fragment."""
+        return "".join(self.parts)
+
+
+class _Cleaner(HTMLParser, HtmlFragment):
+    """A fragment whose text is parsed and cleaned by ``sanitizer``."""
+
+    def __init__(self, sanitizer: HtmlSanitizer) -> None:
+        HTMLParser.__init__(self, convert_charrefs=True)
+        HtmlFragment.__init__(self)
+        self.sanitizer = sanitizer
+        self.open: list[str] = []
+
+    def text(self, text: str) -> None:
+        self.feed(text)
+
+    def markup(self, html: str) -> None:
+        # An incomplete tag before markup is text.
+        if self.rawdata:
+            self.parts.append(escape(self.rawdata))
+            self.rawdata = ""
+        self.parts.append(html)
+
+    def html(self) -> str:
+        self.close()
+        self.parts.extend(f"</{tag}>" for tag in reversed(self.open))
+        self.open.clear()
+        return "".join(self.parts)
+
+    def _start(self, tag: str, attrs: list[tuple[str, str | None]]) -> str | None:
+        if tag not in self.sanitizer.tags:
+            return None
+        rendered = [tag]
+        for name, value in attrs:
+            if not self.sanitizer.allows(tag, name):
+                continue
+            if value is None:
+                rendered.append(name)
+            elif name not in URL_ATTRIBUTES or self.sanitizer.safe_url(value):
+                rendered.append(f'{name}="{escape(value, quote=True)}"')
+        return f"<{' '.join(rendered)}>"
+
+    def handle_starttag(self, tag: str, attrs: list[tuple[str, str | None]]) -> None:
+        start = self._start(tag, attrs)
+        if start is None:
+            self.parts.append(escape(self.get_starttag_text() or ""))
+            return
+        self.parts.append(start)
+        if tag not in _VOID:
+            self.open.append(tag)
+
+    def handle_startendtag(
+        self,
+        tag: str,
+        attrs: list[tuple[str, str | None]],
+    ) -> None:
+        start = self._start(tag, attrs)
+        if start is None:
+            self.parts.append(escape(self.get_starttag_text() or ""))
+        else:
+            self.parts.append(start if tag in _VOID else f"{start}</{tag}>")
+
+    def handle_endtag(self, tag: str) -> None:
+        if tag in _VOID and tag in self.sanitizer.tags:
+            return
+        if tag not in self.open:
+            self.parts.append(escape(f"</{tag}>"))
+            return
+        while self.open:
+            last = self.open.pop()
+            self.parts.append(f"</{last}>")
+            if last == tag:
+                break
+
+    def handle_data(self, data: str) -> None:
+        raw = bool(self.open) and self.open[-1] in _RAW_TEXT
+        self.parts.append(data if raw else escape(data))
+
+
+class HtmlSanitizer:
+    """Keeps the tags, attributes, and URL schemes of an allowlist."""
+
+    def __init__(
+        self,
+        tags: Iterable[str] = DEFAULT_TAGS,
+        attributes: Iterable[str] = DEFAULT_ATTRIBUTES,
+        schemes: Iterable[str] = DEFAULT_SCHEMES,
+    ) -> None:
+        self.tags = frozenset(tag.lower() for tag in tags)
+        self.attributes = frozenset(name.lower() for name in attributes)
+        self.schemes = frozenset(scheme.lower() for scheme in schemes)
+
+    def extended(
+        self,
+        tags: Iterable[str] = (),
+        attributes: Iterable[str] = (),
+        schemes: Iterable[str] = (),
+    ) -> HtmlSanitizer:
+        """A sanitizer also allowing ``tags``, ``attributes``, and ``schemes``."""
+        return HtmlSanitizer(
+            self.tags | set(tags),
+            self.attributes | set(attributes),
+            self.schemes | set(schemes),
+        )
+
+    def allows(self, tag: str, attribute: str) -> bool:
+        """Whether ``attribute`` is kept on ``tag``."""
+        return attribute in self.attributes or f"{tag}.{attribute}" in self.attributes
+
+    def safe_url(self, url: str) -> bool:
+        """Whether ``url`` is relative or uses an allowed scheme."""
+        match = _SCHEME.match(_IGNORED.sub("", url))
+        return match is None or match["scheme"].lower() in self.schemes
+
+    def is_html(self, text: str) -> bool:
+        """Whether ``text`` starts with an allowed tag, as an HTML block does."""
+        match = _TAG.match(text)
+        return match is not None and match["name"].lower() in self.tags
+
+    def fragment(self) -> HtmlFragment:
+        """A fragment sanitizing the text written to it."""
+        return _Cleaner(self)
+
+    def clean(self, html: str) -> str:
+        """``html`` with what the allowlist does not allow escaped or dropped."""
+        if "<" not in html and "&" not in html:
+            return escape(html)
+        fragment = self.fragment()
+        fragment.text(html)
+        return fragment.html()
+
+
+def build_sanitizer(config: SanitizeConfig) -> HtmlSanitizer | None:
+    """The sanitizer of ``site.sanitize``: the defaults and what it adds.
+
+    None when sanitizing is turned off.
+    """
+    if not config.enabled:
+        return None
+    return HtmlSanitizer().extended(config.tags, config.attributes, config.schemes)
+
+
+__all__ = [
+    "DEFAULT_ATTRIBUTES",
+    "DEFAULT_SCHEMES",
+    "DEFAULT_TAGS",
+    "HEAD_ATTRIBUTES",
+    "HEAD_TAGS",
+    "URL_ATTRIBUTES",
+    "HtmlFragment",
+    "HtmlSanitizer",
+    "build_sanitizer",
+]
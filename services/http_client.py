@@ -0,0 +1,262 @@
+"""Retries, backoff, and rate limiting for the external integrations.
+
+Every integration that talks to another service over HTTP, namely the
+Confluence client and the Jira and GitHub issue trackers, sends its requests
+through an :class:`IntegrationClient`. It wraps an ``httpx.Client`` (or any
+object with the same ``get``/``post``/... methods) and adds what the services
+expect of a well-behaved client:
+
+- a :class:`RateLimiter` spaces requests out to the configured rate, so a
+  large publish does not trip the server's own limits;
+- transient failures are retried with exponential backoff: connection errors,
+  429, and the gateway errors 502, 503, and 504. ``Retry-After`` is honoured,
+  as is GitHub's exhausted rate limit (403 with ``X-RateLimit-Remaining: 0``);
+- requests that are not idempotent (POST, PATCH) are retried only when the
+  server cannot have acted on them: a refused connection or a 429.
+
+Each integration configures its own limits through
+:class:`~autodoc.config.settings.IntegrationSettings`, for example
+``JIRA_MAX_RETRIES`` or ``GITHUB_RATE_LIMIT``. The integration's token is
+registered for redaction (:mod:`autodoc.config.secrets`), so it is masked in
+logs and error messages.
+"""
+
+from __future__ import annotations
+
+import logging
+import threading
+import time
+from collections.abc import Callable
+from dataclasses import dataclass
+from email.utils import parsedate_to_datetime
+from typing import Any
+
+import httpx
+
+from autodoc.config.secrets import redact, register_secret
+from autodoc.config.settings import IntegrationSettings
+
+logger = logging.getLogger(__name__)
+
+RETRY_STATUSES = frozenset({429, 502, 503, 504})
+IDEMPOTENT_METHODS = frozenset({"DELETE", "GET", "HEAD", "OPTIONS", "PUT"})
+
+
+def _rate_limited(response: httpx.Response) -> bool:
+    if response.status_code == 429:
+        return True
+    return (
+        response.status_code == 403
+        and response.headers.get("x-ratelimit-remaining") == "0"
+    )
+
+
+@dataclass(frozen=True)
+class RetryPolicy:
+    """How often and how long to wait before a failed request is sent again."""
+
+    max_retries: int = 3
+    # Seconds before the first retry; doubled for every further one.
+    backoff: float = 0.5
+    max_backoff: float = 30.0
+    statuses: frozenset[int] = RETRY_STATUSES
+
+    def should_retry(self, method: str, response: httpx.Response) -> bool:
+        """Whether ``response`` to a ``method`` request is worth retrying."""
+        if _rate_limited(response):
+            return True
+        return method in IDEMPOTENT_METHODS and response.status_code in self.statuses
+
+    def delay(
+        self,
+        attempt: int,
+        response: httpx.Response | None = None,
+        now: float | None = None,
+    ) -> float:
+        """Seconds to wait before retry number ``attempt`` (from 0).
+
+        The server's ``Retry-After`` (seconds or a date) or
+        ``X-RateLimit-Reset`` (epoch seconds) wins over the backoff.
+        """
+        wait = self.backoff * 2**attempt
+        if response is not None:
+            hinted = _hinted_wait(response, time.time() if now is None else now)
+            if hinted is not None:
+                wait = hinted
+        return max(0.0, min(wait, self.max_backoff))
+
+
+def _hinted_wait(response: httpx.Response, now: float) -> float | None:
+    retry_after = response.headers.get("retry-after")
+    if retry_after:
+        try:
+            return float(retry_after)
+        except ValueError:
+            pass
+        try:
+            return parsedate_to_datetime(retry_after).timestamp() - now
+        except (TypeError, ValueError):
+            return None
+    reset = response.headers.get("x-ratelimit-reset")
+    if reset and _rate_limited(response):
+        try:
+            return float(reset) - now
+        except ValueError:
+            return None
+    return None
+
+
+class RateLimiter:
+    """Token bucket allowing ``rate`` requests per second, ``burst`` at once."""
+
+    def __init__(
+        self,
+        rate: float,
+        burst: int = 1,
+        clock: Callable[[], float] = time.monotonic,
+        sleep: Callable[[float], None] = time.sleep,
+    ) -> None:
+        if rate <= 0:
+            raise ValueError("rate must be positive")
+        self.rate = rate
+        self.burst = max(burst, 1)
+        self._clock = clock
+        self._sleep = sleep
+        self._tokens = float(self.burst)
+        self._updated = clock()
+        self._lock = threading.Lock()
+
+    def acquire(self) -> None:
+        """Wait until a request may be sent."""
+        with self._lock:
+            now = self._clock()
+            elapsed = now - self._updated
+            self._tokens = min(self.burst, self._tokens + elapsed * self.rate)
+            self._updated = now
+            if self._tokens < 1:
+                wait = (1 - self._tokens) / self.rate
+                self._sleep(wait)
+                self._updated = self._clock()
+                self._tokens = 0.0
+            else:
+                self._tokens -= 1
+
+
+class IntegrationClient:
+    """An HTTP client for one integration, with retries and rate limiting.
+
+    Offers the request methods of ``httpx.Client`` the integrations use, and
+    returns the last response once retries are exhausted, so callers check
+    the status as before.
+    """
+
+    def __init__(
+        self,
+        name: str,
+        client: Any,
+        policy: RetryPolicy | None = None,
+        limiter: RateLimiter | None = None,
+        sleep: Callable[[float], None] = time.sleep,
+    ) -> None:
+        self.name = name
+        self.policy = policy or RetryPolicy()
+        self.limiter = limiter
+        self._client = client
+        self._sleep = sleep
+
+    def request(self, method: str, url: str, **kwargs: Any) -> httpx.Response:
+        """Send a request, retrying it as the policy allows.
+
+        Raises:
+            httpx.TransportError: If the last attempt could not be sent
+        """
+        method = method.upper()
+        send = getattr(self._client, method.lower())
+        attempt = 0
+        while True:
+            if self.limiter is not None:
+                self.limiter.acquire()
+            try:
+                response = send(url, **kwargs)
+            except httpx.TransportError as exc:
+                # A refused connection has not reached the server.
+                refused = isinstance(exc, httpx.ConnectError)
+                safe = refused or method in IDEMPOTENT_METHODS
+                if not safe or attempt >= self.policy.max_retries:
+                    raise
+                reason, response = type(exc).__name__, None
+            else:
+                retry = self.policy.should_retry(method, response)
+                if not retry or attempt >= self.policy.max_retries:
+                    return response
+                reason = str(response.status_code)
+            wait = self.policy.delay(attempt, response)
+            attempt += 1
+            logger.warning(
+                "%s: %s %s failed (%s); retry %d of %d in %.1fs",
+                self.name,
+                method,
+                redact(url),
+                reason,
+                attempt,
+                self.policy.max_retries,
+                wait,
+            )
+            self._sleep(wait)
+
+    def get(self, url: str, **kwargs: Any) -> httpx.Response:
+        return self.request("GET", url, **kwargs)
+
+    def post(self, url: str, **kwargs: Any) -> httpx.Response:
+        return self.request("POST", url, **kwargs)
+
+    def put(self, url: str, **kwargs: Any) -> httpx.Response:
+        return self.request("PUT", url, **kwargs)
+
+    def patch(self, url: str, **kwargs: Any) -> httpx.Response:
+        return self.request("PATCH", url, **kwargs)
+
+    def delete(self, url: str, **kwargs: Any) -> httpx.Response:
+        return self.request("DELETE", url, **kwargs)
+
+    def close(self) -> None:
+        """Close the wrapped client."""
+        self._client.close()
+
+
+def integration_client(
+    name: str,
+    settings: IntegrationSettings,
+    client: Any = None,
+    **options: Any,
+) -> IntegrationClient:
+    """The client of integration ``name``, configured from ``settings``.
+
+    Args:
+        name: Integration name, used in log messages
+        settings: Timeout, retry, and rate limits of the integration
+        client: Client to wrap; by default an ``httpx.Client`` built with the
+            settings' timeout and ``options`` (``base_url``, ``auth``, ...)
+    """
+    register_secret(getattr(settings, "token", None))
+    if client is None:
+        client = httpx.Client(timeout=settings.timeout, **options)
+    policy = RetryPolicy(
+        max_retries=max(settings.max_retries, 0),
+        backoff=settings.retry_backoff,
+        max_backoff=settings.max_backoff,
+    )
+    limiter = None
+    if settings.rate_limit > 0:
+        limiter = RateLimiter(settings.rate_limit, burst=settings.rate_burst)
+    return IntegrationClient(name, client, policy, limiter)
+
+
+__all__ = [
+    "IDEMPOTENT_METHODS",
+    "RETRY_STATUSES",
+    "IntegrationClient",
+    "RateLimiter",
+    "RetryPolicy",
+    "integration_client",
+]
@@ -0,0 +1,159 @@
+"""``.autodocignore``: gitignore-style control over which files are parsed.
+
+The ignore file lives in the source root and uses gitignore syntax:
+
+- blank lines and lines starting with ``#`` are skipped (``\\#`` escapes);
+- ``*`` and ``?`` match within a path segment, ``**`` across segments, and
+  ``[...]`` is a character class;
+- a pattern containing a ``/`` (other than a trailing one) is anchored to the
+  root, otherwise it matches at any depth;
+- a trailing ``/`` matches directories only;
+- ``!pattern`` re-includes a previously ignored path. As in git, a file
+  inside an ignored directory cannot be re-included.
+
+The last matching pattern wins. Every command that walks the tree (``lint``,
+``coverage``, ``baseline``, ``generate``, ``api``) and the pre-commit hook
+skip ignored paths.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from pathlib import Path, PurePosixPath
+
+logger = logging.getLogger(__name__)
+
+IGNORE_FILE = ".autodocignore"
+
+
+def _translate(pattern: str) -> str:
+    """Regex source for a gitignore glob (without anchoring)."""
+    out = []
+    i = 0
+    while i < len(pattern):
+        char = pattern[i]
+        if pattern.startswith("**/", i):
+            out.append("(?:.*/)?")
+            i += 3
+            continue
+        if pattern.startswith("**", i):
+            out.append(".*")
+            i += 2
+            continue
+        if char == "*":
+            out.append("[^/]*")
+        elif char == "?":
+            out.append("[^/]")
+        elif char == "[" and "]" in pattern[i + 1 :]:
+            end = pattern.index("]", i + 1)
+            body = pattern[i + 1 : end]
+            if body.startswith("!"):
+                body = "^" + body[1:]
+            out.append(f"[{body}]")
+            i = end + 1
+            continue
+        elif char == "\\" and i + 1 < len(pattern):
+            out.append(re.escape(pattern[i + 1]))
+            i += 2
+            continue
+        else:
+            out.append(re.escape(char))
+        i += 1
+    return "".join(out)
+
+
+@dataclass(frozen=True)
+class IgnoreRule:
+    """One pattern line of an ignore file."""
+
+    pattern: str
+    regex: re.Pattern[str]
+    negate: bool = False
+    dir_only: bool = False
+
+    @classmethod
+    def parse(cls, line: str) -> IgnoreRule | None:
+        """Parse a line, or return None for blank lines and comments."""
+        text = line.rstrip()
+        if not text or text.startswith("#"):
+            return None
+        negate = text.startswith("!")
+        if negate or text.startswith(("\\!", "\\#")):
+            text = text[1:]
+        dir_only = text.endswith("/")
+        body = text.rstrip("/")
+        # A slash anywhere but the end anchors the pattern to the root.
+        prefix = "^" if "/" in body else "^(?:.*/)?"
+        regex = re.compile(f"{prefix}{_translate(body.lstrip('/'))}$")
+        return cls(text, regex, negate=negate, dir_only=dir_only)
+
+    def matches(self, path: str, is_dir: bool) -> bool:
+        if self.dir_only and not is_dir:
+            return False
+        return self.regex.match(path) is not None
+
+
+@dataclass
+class IgnoreFile:
+    """The rules of an ignore file, in file order."""
+
+    rules: list[IgnoreRule] = field(default_factory=list)
+
+    def __bool__(self) -> bool:
+        return bool(self.rules)
+
+    def _verdict(self, path: str, is_dir: bool) -> bool:
+        ignored = False
+        for rule in self.rules:
+            if rule.matches(path, is_dir):
+                ignored = not rule.negate
+        return ignored
+
+    def ignores(self, path: str | PurePosixPath, is_dir: bool = False) -> bool:
+        """Whether the root-relative POSIX ``path`` is ignored.
+
+        A path is ignored when it matches, or when any directory above it is
+        ignored.
+        """
+        parts = PurePosixPath(path).parts
+        for depth in range(1, len(parts)):
+            if self._verdict("/".join(parts[:depth]), is_dir=True):
+                return True
+        return self._verdict("/".join(parts), is_dir)
+
+    def filter(self, paths: Iterable[str]) -> list[str]:
+        """Root-relative file ``paths`` that are not ignored."""
+        return [path for path in paths if not self.ignores(path)]
+
+
+def parse_ignore_file(text: str) -> IgnoreFile:
+    """Parse the contents of an ignore file."""
+    rules = [IgnoreRule.parse(line) for line in text.splitlines()]
+    return IgnoreFile([rule for rule in rules if rule is not None])
+
+
+def load_ignore_file(root: str | Path) -> IgnoreFile:
+    """Read ``<root>/.autodocignore``; a missing file ignores nothing."""
+    path = Path(root) / IGNORE_FILE
+    if not path.is_file():
+        return IgnoreFile()
+    try:
+        text = path.read_text(encoding="utf-8")
+    except OSError as exc:
+        logger.warning("Cannot read %s: %s", path, exc)
+        return IgnoreFile()
+    ignore = parse_ignore_file(text)
+    logger.debug("Loaded %d ignore pattern(s) from %s", len(ignore.rules), path)
+    return ignore
+
+
+__all__ = [
+    "IGNORE_FILE",
+    "IgnoreFile",
+    "IgnoreRule",
+    "load_ignore_file",
+    "parse_ignore_file",
+]
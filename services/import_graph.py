@@ -0,0 +1,358 @@
+"""Import and reference analysis across a source tree.
+
+For every module, :func:`build_import_graph` records what it imports and
+which dotted names it references, with import aliases resolved (``import
+shop.cart as c`` followed by ``c.Cart()`` references ``shop.cart.Cart``).
+Only static imports and attribute chains are seen; ``importlib`` and other
+dynamic lookups are invisible to the analysis.
+
+The graph answers two kinds of question: which internal modules and packages
+depend on which (:meth:`ImportGraph.module_edges`,
+:meth:`ImportGraph.package_edges`), and which packages use a given symbol
+(:func:`symbol_usage`).
+"""
+
+from __future__ import annotations
+
+import ast
+import logging
+import os
+import time
+from collections import defaultdict
+from collections.abc import Container, Iterable, Iterator, Sequence
+from dataclasses import dataclass, field, replace
+from pathlib import Path
+
+from services.cancellation import CancelToken
+from services.doc_symbols import (
+    DocSymbol,
+    WalkOptions,
+    discover_python_files,
+    module_name_for,
+    package_name_for,
+    relative_path,
+)
+from services.progress import ANALYZE, Progress
+from services.telemetry import span
+from src.analyzer.parser import parse_python_code
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class ModuleImports:
+    """Imports and resolved references of a single module."""
+
+    module: str
+    package: str
+    file_path: str
+    # Local name -> dotted target (``{"c": "shop.cart"}``).
+    aliases: dict[str, str] = field(default_factory=dict)
+    # Dotted import target -> line of the (first) import statement.
+    imports: dict[str, int] = field(default_factory=dict)
+    references: set[str] = field(default_factory=set)
+
+
+@dataclass(frozen=True)
+class UnparsedModule:
+    """A module the running interpreter cannot parse."""
+
+    module: str
+    package: str
+    file_path: str
+    # Line of the syntax error.
+    lineno: int
+
+
+def dotted_parts(node: ast.expr) -> list[str] | None:
+    parts: list[str] = []
+    while isinstance(node, ast.Attribute):
+        parts.append(node.attr)
+        node = node.value
+    if not isinstance(node, ast.Name):
+        return None
+    parts.append(node.id)
+    return parts[::-1]
+
+
+def resolve_name(
+    node: ast.expr,
+    info: ModuleImports,
+    local_names: Container[str] = (),
+) -> str:
+    """Resolve a name or attribute chain used in ``info``'s module.
+
+    Imported names expand to their import target and ``local_names`` (names
+    defined in the module) get the module prefix. Anything that is not a
+    dotted chain comes back as source text.
+    """
+    parts = dotted_parts(node)
+    if parts is None:
+        return ast.unparse(node)
+    target = info.aliases.get(parts[0])
+    if target is not None:
+        return ".".join([target, *parts[1:]])
+    if parts[0] in local_names:
+        return ".".join([info.module, *parts])
+    return ".".join(parts)
+
+
+class _ReferenceCollector(ast.NodeVisitor):
+    def __init__(self, info: ModuleImports, is_package: bool) -> None:
+        self.info = info
+        parts = info.module.split(".")
+        self.package_parts = parts if is_package else parts[:-1]
+
+    def _add_import(self, local: str, target: str, lineno: int) -> None:
+        self.info.aliases[local] = target
+        self.info.imports.setdefault(target, lineno)
+        self.info.references.add(target)
+
+    def visit_Import(self, node: ast.Import) -> None:
+        for alias in node.names:
+            if alias.asname:
+                self._add_import(alias.asname, alias.name, node.lineno)
+            else:
+                head = alias.name.split(".", 1)[0]
+                self.info.aliases.setdefault(head, head)
+                self.info.imports.setdefault(alias.name, node.lineno)
+                self.info.references.add(alias.name)
+
+    def _resolve_from(self, node: ast.ImportFrom) -> str | None:
+        if not node.level:
+            return node.module
+        keep = len(self.package_parts) - (node.level - 1)
+        if keep < 0:
+            logger.debug("Relative import beyond root in %s", self.info.module)
+            return None
+        base = self.package_parts[:keep]
+        if node.module:
+            base = [*base, node.module]
+        return ".".join(base) or None
+
+    def visit_ImportFrom(self, node: ast.ImportFrom) -> None:
+        base = self._resolve_from(node)
+        if base is None:
+            return
+        for alias in node.names:
+            if alias.name == "*":
+                self.info.imports.setdefault(base, node.lineno)
+                self.info.references.add(base)
+                continue
+            local = alias.asname or alias.name
+            self._add_import(local, f"{base}.{alias.name}", node.lineno)
+
+    def _reference(self, parts: list[str]) -> None:
+        target = self.info.aliases.get(parts[0])
+        if target is not None:
+            self.info.references.add(".".join([target, *parts[1:]]))
+
+    def visit_Name(self, node: ast.Name) -> None:
+        if isinstance(node.ctx, ast.Load):
+            self._reference([node.id])
+
+    def visit_Attribute(self, node: ast.Attribute) -> None:
+        parts = dotted_parts(node)
+        if parts is None:
+            self.generic_visit(node)
+        else:
+            self._reference(parts)
+
+
+@dataclass
+class ImportGraph:
+    """Imports and references of every module below a root."""
+
+    modules: dict[str, ModuleImports] = field(default_factory=dict)
+    # Modules left out of ``modules`` for a syntax error.
+    unparsed: list[UnparsedModule] = field(default_factory=list)
+
+    def internal_module(self, dotted: str) -> str | None:
+        """Return the longest known module that ``dotted`` names or lies inside."""
+        parts = dotted.split(".")
+        while parts:
+            candidate = ".".join(parts)
+            if candidate in self.modules:
+                return candidate
+            parts.pop()
+        return None
+
+    def module_edges(self) -> dict[str, set[str]]:
+        """Map each module to the internal modules it imports."""
+        edges: dict[str, set[str]] = {}
+        for name, info in self.modules.items():
+            targets = {self.internal_module(target) for target in info.imports}
+            edges[name] = {t for t in targets if t is not None and t != name}
+        return edges
+
+    def package_edges(self) -> dict[str, set[str]]:
+        """Map each package to the other internal packages it imports."""
+        edges: dict[str, set[str]] = defaultdict(set)
+        for name, targets in self.module_edges().items():
+            package = self.modules[name].package
+            edges[package].update(
+                self.modules[t].package
+                for t in targets
+                if self.modules[t].package != package
+            )
+        return dict(edges)
+
+    def without(self, modules: Container[str]) -> ImportGraph:
+        """A copy without ``modules`` and the imports and references of them.
+
+        Pages built from the copy read as if the modules did not exist.
+        """
+
+        def kept(target: str) -> bool:
+            return self.internal_module(target) not in modules
+
+        return ImportGraph(
+            {
+                name: replace(
+                    info,
+                    aliases={k: v for k, v in info.aliases.items() if kept(v)},
+                    imports={k: v for k, v in info.imports.items() if kept(k)},
+                    references={t for t in info.references if kept(t)},
+                )
+                for name, info in self.modules.items()
+                if name not in modules
+            },
+            [module for module in self.unparsed if module.module not in modules],
+        )
+
+
+def _analyze(source: str, module: str, package: str, file_path: str) -> ModuleImports:
+    info = ModuleImports(module=module, package=package, file_path=file_path)
+    tree = parse_python_code(source, filename=file_path)
+    is_package = Path(file_path).name == "__init__.py"
+    _ReferenceCollector(info, is_package).visit(tree)
+    return info
+
+
+def build_import_graph(
+    root: str | Path,
+    files: Sequence[str | Path] | None = None,
+    walk: WalkOptions | None = None,
+    cancel: CancelToken | None = None,
+    progress: Progress | None = None,
+) -> ImportGraph:
+    """Analyze ``files`` (default: every Python file below ``root``).
+
+    Stops early, counting the skipped files on ``cancel``, once it is
+    cancelled. Each file read advances the ``analyze`` stage of ``progress``.
+    """
+    targets = discover_python_files(root, walk=walk) if files is None else files
+    started = time.perf_counter()
+    graph = ImportGraph()
+    if progress is not None:
+        progress.start(ANALYZE, len(targets))
+    with span("autodoc.analyze") as current:
+        for index, path in enumerate(targets):
+            if cancel is not None and cancel.cancelled:
+                cancel.skip("import analysis", len(targets) - index)
+                break
+            if progress is not None:
+                progress.advance(ANALYZE)
+            # Discovered paths are relative to the working directory, not root.
+            location = Path(os.path.abspath(path))
+            module = module_name_for(location, root)
+            package = package_name_for(location, root)
+            try:
+                source = Path(path).read_text(encoding="utf-8")
+                info = _analyze(source, module, package, str(path))
+            except SyntaxError as exc:
+                logger.warning("Skipping %s in import analysis: %s", path, exc)
+                unparsed = UnparsedModule(module, package, str(path), exc.lineno or 1)
+                graph.unparsed.append(unparsed)
+                continue
+            except (OSError, UnicodeDecodeError) as exc:
+                logger.warning("Skipping %s in import analysis: %s", path, exc)
+                continue
+            graph.modules[info.module] = info
+        current.set(modules=len(graph.modules))
+    if progress is not None:
+        progress.finish(ANALYZE)
+    elapsed = time.perf_counter() - started
+    logger.info(
+        "Analyzed imports of %d module(s) in %.2fs",
+        len(graph.modules),
+        elapsed,
+        extra={"modules": len(graph.modules), "seconds": elapsed},
+    )
+    return graph
+
+
+def parse_modules(
+    graph: ImportGraph,
+    purpose: str = "analysis",
+) -> Iterator[tuple[ModuleImports, ast.Module]]:
+    """Re-parse every module of ``graph`` in name order for further analysis.
+
+    Files that can no longer be read or parsed are logged and skipped.
+    """
+    for name in sorted(graph.modules):
+        info = graph.modules[name]
+        try:
+            source = Path(info.file_path).read_text(encoding="utf-8")
+            tree = ast.parse(source, filename=info.file_path)
+        except (OSError, UnicodeDecodeError, SyntaxError) as exc:
+            logger.warning("Skipping %s in %s: %s", info.file_path, purpose, exc)
+            continue
+        yield info, tree
+
+
+@dataclass(frozen=True)
+class SymbolUsage:
+    """How widely a symbol is used outside its own package."""
+
+    qualified_name: str
+    package: str
+    used_by: tuple[str, ...]
+
+    @property
+    def count(self) -> int:
+        return len(self.used_by)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "qualified_name": self.qualified_name,
+            "package": self.package,
+            "used_by": list(self.used_by),
+            "count": self.count,
+        }
+
+
+def symbol_usage(graph: ImportGraph, symbols: Iterable[DocSymbol]) -> list[SymbolUsage]:
+    """Count, for every symbol, the other packages that reference it.
+
+    A reference to ``a.b.C.method`` also counts as using ``a.b.C`` and the
+    module ``a.b``. Results are sorted most-used first, then by name.
+    """
+    by_name = {symbol.qualified_name: symbol for symbol in symbols}
+    users: dict[str, set[str]] = {name: set() for name in by_name}
+    for info in graph.modules.values():
+        for reference in info.references:
+            parts = reference.split(".")
+            for end in range(len(parts), 0, -1):
+                symbol = by_name.get(".".join(parts[:end]))
+                if symbol is not None and symbol.package != info.package:
+                    users[symbol.qualified_name].add(info.package)
+    usage = [
+        SymbolUsage(name, by_name[name].package, tuple(sorted(packages)))
+        for name, packages in users.items()
+    ]
+    return sorted(usage, key=lambda u: (-u.count, u.qualified_name))
+
+
+__all__ = [
+    "ImportGraph",
+    "ModuleImports",
+    "SymbolUsage",
+    "UnparsedModule",
+    "build_import_graph",
+    "dotted_parts",
+    "parse_modules",
+    "relative_path",
+    "resolve_name",
+    "symbol_usage",
+]
@@ -0,0 +1,217 @@
+"""Layering rules over the import graph, defined in ``autodoc.yaml``.
+
+Each entry under ``lint.imports`` becomes an :class:`ImportRule`::
+
+    lint:
+      imports:
+        - id: api-not-db
+          description: The API layer goes through the services
+          modules: [app.api]
+          forbid: [app.db]
+          allow: [app.db.schemas]
+
+``modules``, ``forbid`` and ``allow`` are dotted module patterns. A pattern
+matches the module it names and every module inside it, and may use
+:mod:`fnmatch` wildcards (``app.*.internal``). An import from a module
+matching ``modules`` of a module matching ``forbid`` but not ``allow`` is a
+violation. Imports of modules outside the tree are matched as written, so
+rules can also keep third-party packages out of a layer (``forbid:
+[sqlalchemy]``).
+
+Violations are :class:`~services.doc_lint.LintFinding` records whose symbol is
+``"<module> -> <imported module>"``, so they are reported, baselined, and
+filtered exactly like documentation findings.
+"""
+
+from __future__ import annotations
+
+import logging
+from collections.abc import Iterable, Sequence
+from dataclasses import dataclass
+from fnmatch import fnmatchcase
+from pathlib import Path
+from typing import Any
+
+from autodoc.config.project import LintConfig
+from services.custom_lint_rules import RULE_ID, SEVERITIES, RuleConfigError
+from services.doc_lint import DEFAULT_RULES, LintFinding
+from services.doc_spelling import SPELLING_RULE_ID, TERMINOLOGY_RULE_ID
+from services.doc_symbols import relative_path
+from services.import_graph import ImportGraph
+
+logger = logging.getLogger(__name__)
+
+_RULE_KEYS = frozenset(
+    {"id", "description", "modules", "forbid", "allow", "message", "severity"},
+)
+
+
+def matches_module(name: str, patterns: Iterable[str]) -> bool:
+    """Whether ``name`` is, or lies inside, a module matching one of ``patterns``."""
+    return any(
+        fnmatchcase(name, pattern) or fnmatchcase(name, f"{pattern}.*")
+        for pattern in patterns
+    )
+
+
+@dataclass(frozen=True)
+class ImportRule:
+    """Modules matching ``modules`` must not import modules matching ``forbid``."""
+
+    id: str
+    description: str
+    modules: tuple[str, ...]
+    forbid: tuple[str, ...]
+    allow: tuple[str, ...] = ()
+    message: str = "{module} must not import {target} ({rule})"
+    severity: str = "error"
+
+    def applies_to(self, module: str) -> bool:
+        """Whether the rule constrains the imports of ``module``."""
+        return matches_module(module, self.modules)
+
+    def forbids(self, target: str) -> bool:
+        """Whether importing ``target`` violates the rule."""
+        return matches_module(target, self.forbid) and not matches_module(
+            target,
+            self.allow,
+        )
+
+    def render(self, module: str, target: str, imported: str) -> str:
+        """The finding message for an import of ``target`` from ``module``."""
+        fields = {"module": module, "target": target, "imported": imported}
+        try:
+            return self.message.format(
+                rule=self.id,
+                description=self.description,
+                **fields,
+            )
+        except (KeyError, IndexError, ValueError) as exc:
+            logger.warning("Bad message template for rule %s: %s", self.id, exc)
+            return ImportRule.message.format(rule=self.id, **fields)
+
+
+def _patterns(rule_id: str, data: dict[str, Any], key: str) -> tuple[str, ...]:
+    value = data.get(key, [])
+    if isinstance(value, str):
+        value = [value]
+    if not isinstance(value, list) or not all(
+        isinstance(item, str) and item for item in value
+    ):
+        raise RuleConfigError(
+            f"Import rule {rule_id}: {key!r} must be a module pattern or a list",
+        )
+    return tuple(value)
+
+
+def import_rule_from_dict(data: dict[str, Any]) -> ImportRule:
+    """Build an :class:`ImportRule` from one ``lint.imports`` entry.
+
+    Raises:
+        RuleConfigError: If required keys are missing, unknown keys are
+            present, or a pattern or the severity is invalid
+    """
+    rule_id = data.get("id")
+    if not isinstance(rule_id, str) or not RULE_ID.match(rule_id):
+        raise RuleConfigError(
+            f"Rule id must be lowercase letters, digits and dashes, got {rule_id!r}",
+        )
+    unknown = set(data) - _RULE_KEYS
+    if unknown:
+        raise RuleConfigError(f"Import rule {rule_id}: unknown keys {sorted(unknown)}")
+    modules = _patterns(rule_id, data, "modules")
+    forbid = _patterns(rule_id, data, "forbid")
+    if not modules or not forbid:
+        raise RuleConfigError(
+            f"Import rule {rule_id}: 'modules' and 'forbid' are required",
+        )
+    severity = data.get("severity", "error")
+    if severity not in SEVERITIES:
+        raise RuleConfigError(
+            f"Import rule {rule_id}: severity must be one of {', '.join(SEVERITIES)}",
+        )
+
+    return ImportRule(
+        id=rule_id,
+        description=str(data.get("description", "")),
+        modules=modules,
+        forbid=forbid,
+        allow=_patterns(rule_id, data, "allow"),
+        message=str(data.get("message", ImportRule.message)),
+        severity=severity,
+    )
+
+
+def import_rules_from_config(config: LintConfig) -> list[ImportRule]:
+    """Return the import rules of ``config`` that are not disabled.
+
+    Import rules share their ids with the lint rules, so ``lint.disable``
+    turns them off too.
+
+    Raises:
+        RuleConfigError: If a rule is invalid or reuses the id of another rule
+    """
+    seen = {rule.id for rule in DEFAULT_RULES} | {SPELLING_RULE_ID, TERMINOLOGY_RULE_ID}
+    seen.update(str(entry.get("id")) for entry in config.rules)
+    rules = []
+    for entry in config.imports:
+        rule = import_rule_from_dict(entry)
+        if rule.id in seen:
+            raise RuleConfigError(f"Duplicate rule id {rule.id!r}")
+        seen.add(rule.id)
+        if rule.id not in config.disable:
+            rules.append(rule)
+    return rules
+
+
+def check_imports(
+    graph: ImportGraph,
+    rules: Sequence[ImportRule],
+    root: str | Path,
+) -> list[LintFinding]:
+    """Report the imports in ``graph`` that violate ``rules``.
+
+    Imports of the tree are resolved to the module they name, so ``from
+    app.db import Session`` is checked as an import of ``app.db``. Each
+    module is reported once per rule and imported module, at the first
+    offending line.
+
+    Returns:
+        Findings sorted by file, line, and rule, with file paths relative
+        to ``root``
+    """
+    findings: dict[tuple[str, str], LintFinding] = {}
+    for name in sorted(graph.modules):
+        info = graph.modules[name]
+        active = [rule for rule in rules if rule.applies_to(name)]
+        if not active:
+            continue
+        for imported, lineno in sorted(info.imports.items(), key=lambda item: item[1]):
+            target = graph.internal_module(imported) or imported
+            if target == name:
+                continue
+            symbol = f"{name} -> {target}"
+            for rule in active:
+                if (rule.id, symbol) in findings or not rule.forbids(target):
+                    continue
+                findings[(rule.id, symbol)] = LintFinding(
+                    rule=rule.id,
+                    message=rule.render(name, target, imported),
+                    file_path=relative_path(info.file_path, root),
+                    lineno=lineno,
+                    symbol=symbol,
+                    severity=rule.severity,
+                )
+    return sorted(
+        findings.values(),
+        key=lambda f: (f.file_path, f.lineno, f.rule, f.symbol),
+    )
+
+
+__all__ = [
+    "ImportRule",
+    "check_imports",
+    "import_rule_from_dict",
+    "import_rules_from_config",
+    "matches_module",
+]
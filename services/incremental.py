@@ -0,0 +1,356 @@
+"""Incremental site generation (``autodoc generate --incremental``).
+
+A run records, in ``<output>/.autodoc-state.json``:
+
+- the SHA-256 of every source file it analyzed;
+- the per-file analysis (documentation symbols and imports), so unchanged
+  files are not parsed again;
+- which source files each package page depends on: the package's own files
+  plus the files of packages it imports or is imported by, since those feed
+  its "most used" list and mock links, and the files defining its namesakes.
+
+The next run re-parses only changed files and re-renders only the package
+pages whose dependencies changed; the other pages are carried over from the
+previous output. The state is discarded (and everything regenerated) when the
+project configuration, the generate options, or the state format changes.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import json
+import logging
+from collections import defaultdict
+from collections.abc import Iterable, Sequence
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import TYPE_CHECKING, Any
+
+from services.cancellation import CancelToken
+from services.doc_site import PackageDoc
+from services.doc_symbols import DocSymbol, DocSymbolLoader, relative_path
+from services.import_graph import ImportGraph, ModuleImports, build_import_graph
+from services.progress import PARSE, Progress
+from services.telemetry import count, span
+
+if TYPE_CHECKING:
+    from services.checkpoint import Checkpoint
+
+logger = logging.getLogger(__name__)
+
+STATE_FILE = ".autodoc-state.json"
+STATE_VERSION = 1
+
+
+def file_digest(path: str | Path) -> str:
+    """SHA-256 of the contents of ``path``."""
+    return hashlib.sha256(Path(path).read_bytes()).hexdigest()
+
+
+def fingerprint(settings: Any) -> str:
+    """Stable digest of ``settings`` (anything JSON-serializable)."""
+    text = json.dumps(settings, sort_keys=True, default=str)
+    return hashlib.sha256(text.encode("utf-8")).hexdigest()
+
+
+@dataclass
+class SiteState:
+    """What one incremental run analyzed and rendered."""
+
+    fingerprint: str
+    sources: dict[str, str] = field(default_factory=dict)
+    symbols: dict[str, list[dict[str, Any]]] = field(default_factory=dict)
+    imports: dict[str, dict[str, Any]] = field(default_factory=dict)
+    packages: dict[str, list[str]] = field(default_factory=dict)
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "version": STATE_VERSION,
+            "fingerprint": self.fingerprint,
+            "sources": self.sources,
+            "symbols": self.symbols,
+            "imports": self.imports,
+            "packages": self.packages,
+        }
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> SiteState:
+        return cls(
+            fingerprint=data["fingerprint"],
+            sources=dict(data["sources"]),
+            symbols=dict(data["symbols"]),
+            imports=dict(data["imports"]),
+            packages=dict(data["packages"]),
+        )
+
+
+def load_state(path: str | Path, expected: str) -> SiteState | None:
+    """The state at ``path``, or None when it is missing, unreadable, or stale."""
+    target = Path(path)
+    if not target.is_file():
+        logger.info("No incremental state at %s; generating everything", target)
+        return None
+    try:
+        data = json.loads(target.read_text(encoding="utf-8"))
+        if data.get("version") != STATE_VERSION:
+            logger.info("Ignoring %s: written by another version", target)
+            return None
+        state = SiteState.from_dict(data)
+    except (OSError, ValueError, KeyError, TypeError, AttributeError) as exc:
+        logger.warning("Ignoring unreadable incremental state %s: %s", target, exc)
+        return None
+    if state.fingerprint != expected:
+        logger.info("Settings changed since the last run; generating everything")
+        return None
+    return state
+
+
+def write_state(state: SiteState, path: str | Path) -> Path:
+    target = Path(path)
+    target.parent.mkdir(parents=True, exist_ok=True)
+    target.write_text(
+        json.dumps(state.to_dict(), sort_keys=True) + "\n",
+        encoding="utf-8",
+    )
+    return target
+
+
+def _imports_to_dict(info: ModuleImports) -> dict[str, Any]:
+    return {
+        "module": info.module,
+        "package": info.package,
+        "aliases": info.aliases,
+        "imports": info.imports,
+        "references": sorted(info.references),
+    }
+
+
+def _imports_from_dict(data: dict[str, Any], file_path: str) -> ModuleImports:
+    return ModuleImports(
+        module=data["module"],
+        package=data["package"],
+        file_path=file_path,
+        aliases=dict(data["aliases"]),
+        imports=dict(data["imports"]),
+        references=set(data["references"]),
+    )
+
+
+class AnalysisCache:
+    """Per-file analysis of ``files``, reusing ``previous`` for unchanged ones.
+
+    Paths in the state are relative to ``root``; cached results are restored
+    with the paths the files were discovered under, so they are
+    indistinguishable from freshly parsed ones. Fresh results are also
+    recorded in ``checkpoint``, file by file, when one is given.
+    """
+
+    def __init__(
+        self,
+        root: str | Path,
+        files: Sequence[Path],
+        previous: SiteState | None,
+        checkpoint: Checkpoint | None = None,
+    ) -> None:
+        self.root = Path(root)
+        self.previous = previous
+        self.checkpoint = checkpoint
+        self.files = {relative_path(path, self.root): path for path in files}
+        self.digests = {rel: file_digest(path) for rel, path in self.files.items()}
+        old = previous.sources if previous else {}
+        self.changed = {
+            rel for rel, digest in self.digests.items() if old.get(rel) != digest
+        }
+        self.changed |= set(old) - set(self.digests)
+        self.symbols: dict[str, list[dict[str, Any]]] = {}
+        self.imports: dict[str, dict[str, Any]] = {}
+
+    def _reusable(self, rel: str, cached: dict[str, Any]) -> bool:
+        return rel not in self.changed and rel in cached
+
+    def load_symbols(
+        self,
+        loader: DocSymbolLoader,
+        cancel: CancelToken | None = None,
+        progress: Progress | None = None,
+    ) -> list[DocSymbol]:
+        """Symbols of every file, parsing only changed ones.
+
+        The changed files are the ``parse`` stage of ``progress``.
+        """
+        cached = self.previous.symbols if self.previous else {}
+        stale = [rel for rel in self.files if not self._reusable(rel, cached)]
+        hits = len(self.files) - len(stale)
+        logger.info(
+            "Reusing symbols of %d unchanged file(s); parsing %d",
+            hits,
+            len(stale),
+            extra={"cache_hits": hits, "cache_misses": len(stale)},
+        )
+        count("autodoc.cache.hits", hits, cache="symbols")
+        count("autodoc.cache.misses", len(stale), cache="symbols")
+        if progress is not None:
+            progress.start(PARSE, len(stale))
+        with span("autodoc.parse", files=len(stale), cache_hits=hits):
+            for index, rel in enumerate(stale):
+                if cancel is not None and cancel.cancelled:
+                    cancel.skip("parse", len(stale) - index)
+                    break
+                loaded = loader.load_file(self.files[rel])
+                self.symbols[rel] = [symbol.to_dict() for symbol in loaded]
+                if self.checkpoint is not None:
+                    self.checkpoint.add_symbols(
+                        rel,
+                        self.digests[rel],
+                        self.symbols[rel],
+                    )
+                count("autodoc.files.parsed")
+                if progress is not None:
+                    progress.advance(PARSE)
+        if progress is not None:
+            progress.finish(PARSE)
+        symbols = []
+        for rel, path in self.files.items():
+            if rel not in self.symbols and self._reusable(rel, cached):
+                self.symbols[rel] = cached[rel]
+            for data in self.symbols.get(rel, []):
+                symbols.append(DocSymbol(**{**data, "file_path": str(path)}))
+        return symbols
+
+    def build_graph(
+        self,
+        cancel: CancelToken | None = None,
+        progress: Progress | None = None,
+    ) -> ImportGraph:
+        """The import graph of every file, analyzing only changed ones.
+
+        The changed files are the ``analyze`` stage of ``progress``.
+        """
+        cached = self.previous.imports if self.previous else {}
+        stale = [rel for rel in self.files if not self._reusable(rel, cached)]
+        count("autodoc.cache.hits", len(self.files) - len(stale), cache="imports")
+        count("autodoc.cache.misses", len(stale), cache="imports")
+        fresh = build_import_graph(
+            self.root,
+            files=[self.files[rel] for rel in stale],
+            cancel=cancel,
+            progress=progress,
+        )
+        for info in fresh.modules.values():
+            rel = relative_path(info.file_path, self.root)
+            self.imports[rel] = _imports_to_dict(info)
+            if self.checkpoint is not None:
+                self.checkpoint.add_imports(rel, self.digests[rel], self.imports[rel])
+        # Unparsed files are never cached, so ``fresh`` has all of them.
+        graph = ImportGraph(unparsed=fresh.unparsed)
+        for rel, path in self.files.items():
+            if rel not in self.imports and self._reusable(rel, cached):
+                self.imports[rel] = cached[rel]
+            if rel in self.imports:
+                info = _imports_from_dict(self.imports[rel], str(path))
+                graph.modules[info.module] = info
+        return graph
+
+    def state(self, settings: str, packages: dict[str, list[str]]) -> SiteState:
+        """State for the next run, covering only the files analyzed in full."""
+        analyzed = set(self.symbols) & set(self.imports)
+        return SiteState(
+            fingerprint=settings,
+            sources={rel: self.digests[rel] for rel in sorted(analyzed)},
+            symbols={rel: self.symbols[rel] for rel in sorted(analyzed)},
+            imports={rel: self.imports[rel] for rel in sorted(analyzed)},
+            packages=packages,
+        )
+
+
+def _owner(target: str, modules: dict[str, ModuleImports]) -> ModuleImports | None:
+    """The first-party module ``target`` (a module or a name in one) refers to."""
+    parts = target.split(".")
+    for end in range(len(parts), 0, -1):
+        info = modules.get(".".join(parts[:end]))
+        if info is not None:
+            return info
+    return None
+
+
+def package_dependencies(
+    graph: ImportGraph,
+    packages: Iterable[PackageDoc],
+    root: str | Path,
+) -> dict[str, list[str]]:
+    """Map each package slug to the source files its page depends on.
+
+    The files of the package's embedded assets count too: only their names
+    are on the page, so a page is rendered again when the list changes (see
+    :func:`affected_packages`), not when an asset is edited. A package
+    README is listed with the digest of its content, as
+    ``README.md@<sha256>``, so editing it changes the list; so are the FAQ
+    sidecar files, with the digest of their entries.
+    """
+    files: dict[str, set[str]] = defaultdict(set)
+    related: dict[str, set[str]] = defaultdict(set)
+    for info in graph.modules.values():
+        files[info.package].add(relative_path(info.file_path, root))
+        for target in info.imports:
+            owner = _owner(target, graph.modules)
+            if owner is not None and owner.package != info.package:
+                related[info.package].add(owner.package)
+                related[owner.package].add(info.package)
+    dependencies = {}
+    for package in packages:
+        sources = set(files[package.name])
+        for other in related[package.name]:
+            sources |= files[other]
+        sources.update(
+            relative_path(symbol.file_path, root)
+            for namesakes in package.namesakes.values()
+            for symbol in namesakes
+        )
+        sources.update(path for asset in package.assets for path in asset.files)
+        if package.readme is not None:
+            digest = fingerprint(package.readme.content)
+            sources.add(f"{relative_path(package.readme.file_path, root)}@{digest}")
+        sidecars: dict[str, list[str]] = defaultdict(list)
+        for entry in package.faq:
+            if entry.symbol is None:
+                sidecars[entry.file_path] += [entry.question, entry.answer]
+        sources.update(
+            f"{relative_path(path, root)}@{fingerprint(texts)}"
+            for path, texts in sidecars.items()
+        )
+        dependencies[package.slug] = sorted(sources)
+    return dependencies
+
+
+def affected_packages(
+    current: dict[str, list[str]],
+    previous: SiteState | None,
+    changed: set[str],
+) -> set[str]:
+    """Slugs of the package pages that must be rendered again.
+
+    A page is affected when one of the files it depends on changed, or when
+    it depends on other files than recorded in ``previous``.
+    """
+    if previous is None:
+        return set(current)
+    affected = set()
+    for slug, sources in current.items():
+        before = previous.packages.get(slug)
+        if before is None or set(before) != set(sources) or changed & set(sources):
+            affected.add(slug)
+    return affected
+
+
+__all__ = [
+    "STATE_FILE",
+    "STATE_VERSION",
+    "AnalysisCache",
+    "SiteState",
+    "affected_packages",
+    "file_digest",
+    "fingerprint",
+    "load_state",
+    "package_dependencies",
+    "write_state",
+]
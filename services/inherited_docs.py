@@ -0,0 +1,112 @@
+"""Docstrings that methods inherit from the interfaces they implement.
+
+With ``site.inherit_docs``, a method without a docstring that overrides a
+documented method of a base class of the tree shows that docstring instead,
+marked as inherited from it. That is the usual case of an interface (a
+``Protocol`` or ABC, see :mod:`services.mock_links`) documenting what each
+implementation does, which ``help()`` and ``inspect.getdoc`` already show.
+
+:func:`find_inherited_docs` searches the bases of a class in order, depth
+first, so the nearest documented override wins; a base that overrides the
+method without a docstring passes the search on to its own bases. Bases are
+resolved through the module's imports, as the import graph records them.
+"""
+
+from __future__ import annotations
+
+import ast
+from collections.abc import Iterable
+from dataclasses import dataclass
+
+from services.doc_symbols import DocSymbol
+from services.import_graph import ImportGraph, parse_modules, resolve_name
+
+
+@dataclass(frozen=True)
+class InheritedDoc:
+    """``symbol`` has no docstring and shows the one of ``source``."""
+
+    symbol: str
+    source: str
+    docstring: str
+
+    def to_dict(self) -> dict[str, object]:
+        return {"symbol": self.symbol, "source": self.source}
+
+
+def _class_bases(graph: ImportGraph) -> dict[str, tuple[str, ...]]:
+    """The resolved bases of every top-level class of ``graph``."""
+    bases: dict[str, tuple[str, ...]] = {}
+    for info, tree in parse_modules(graph, "docstring inheritance"):
+        local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        }
+        for node in tree.body:
+            if isinstance(node, ast.ClassDef):
+                bases[f"{info.module}.{node.name}"] = tuple(
+                    resolve_name(
+                        base.value if isinstance(base, ast.Subscript) else base,
+                        info,
+                        local_names,
+                    )
+                    for base in node.bases
+                )
+    return bases
+
+
+def _inherited(
+    cls: str,
+    name: str,
+    bases: dict[str, tuple[str, ...]],
+    methods: dict[str, DocSymbol],
+    seen: set[str],
+) -> DocSymbol | None:
+    for base in bases.get(cls, ()):
+        if base in seen:
+            continue
+        seen.add(base)
+        method = methods.get(f"{base}.{name}")
+        if method is not None and method.is_documented:
+            return method
+        found = _inherited(base, name, bases, methods, seen)
+        if found is not None:
+            return found
+    return None
+
+
+def find_inherited_docs(
+    graph: ImportGraph,
+    symbols: Iterable[DocSymbol],
+) -> list[InheritedDoc]:
+    """The docstrings the undocumented methods of ``symbols`` inherit.
+
+    Class bases are read from the modules of ``graph``.
+    """
+    methods = {
+        symbol.qualified_name: symbol
+        for symbol in symbols
+        if symbol.kind == "method"
+    }
+    bases = _class_bases(graph)
+    inherited = []
+    for symbol in methods.values():
+        if symbol.is_documented or symbol.parent is None:
+            continue
+        source = _inherited(symbol.parent, symbol.name, bases, methods, set())
+        if source is not None and source.docstring is not None:
+            inherited.append(
+                InheritedDoc(
+                    symbol.qualified_name,
+                    source.qualified_name,
+                    source.docstring,
+                ),
+            )
+    return inherited
+
+
+__all__ = [
+    "InheritedDoc",
+    "find_inherited_docs",
+]
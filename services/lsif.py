@@ -0,0 +1,273 @@
+"""LSIF index of the documented symbols, for precise code navigation.
+
+The ``lsif`` format of ``autodoc generate`` writes :data:`LSIF_PATH`, a
+`Language Server Index Format <https://lsif.dev>`_ dump that Sourcegraph and
+other code browsers upload to offer "go to definition", "find references",
+and hover documentation, from the same run that builds the docs.
+
+:func:`render_lsif` emits, for every documented symbol:
+
+- a definition range on its name (see :mod:`services.symbol_spans`), or
+  the start of the file for a module;
+- a hover of its signature and docstring;
+- an ``export`` moniker of its qualified name, so other repositories' dumps
+  can link to it;
+- reference ranges wherever a module of the tree names it: imports, and
+  names and attribute chains that import aliases and module-level
+  definitions resolve to (see :func:`~services.import_graph.resolve_name`).
+  Local variables that shadow a name are not told apart.
+
+Positions are 0-based lines and UTF-16 characters, as LSP counts them. URIs
+are ``file:///`` URIs relative to the repository's top level, so the dump
+is the same on every machine; upload it from the top level.
+"""
+
+from __future__ import annotations
+
+import ast
+import bisect
+import json
+from collections import defaultdict
+from collections.abc import Iterable, Iterator
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+from urllib.parse import quote
+
+from autodoc import __version__
+from services.doc_json import source_root
+from services.doc_site import signature
+from services.doc_symbols import DocSymbol
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    dotted_parts,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+from services.symbol_spans import SymbolSpan, find_symbol_spans, line_offsets
+
+LSIF_PATH = "dump.lsif"
+LSIF_VERSION = "0.4.3"
+MONIKER_SCHEME = "python"
+
+
+@dataclass(frozen=True)
+class Reference:
+    """A place where a module of the tree names a documented symbol."""
+
+    symbol: str
+    file_path: str
+    # 1-based line and UTF-8 byte columns of the name, as ``ast`` reports.
+    lineno: int
+    col_offset: int
+    end_col_offset: int
+
+
+def _references(
+    info: ModuleImports,
+    tree: ast.Module,
+    symbols: set[str],
+    file_path: str,
+) -> Iterator[Reference]:
+    local_names = {
+        node.name
+        for node in tree.body
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+    }
+    for node in ast.walk(tree):
+        if isinstance(node, (ast.Import, ast.ImportFrom)):
+            for alias in node.names:
+                local = alias.asname or alias.name
+                target = alias.name if isinstance(node, ast.Import) else None
+                target = target or info.aliases.get(local)
+                if target in symbols:
+                    end = alias.col_offset + len(alias.name.encode("utf-8"))
+                    yield Reference(
+                        target,
+                        file_path,
+                        alias.lineno,
+                        alias.col_offset,
+                        end,
+                    )
+        elif isinstance(node, (ast.Name, ast.Attribute)):
+            if not isinstance(node.ctx, ast.Load) or dotted_parts(node) is None:
+                continue
+            name = resolve_name(node, info, local_names)
+            if name not in symbols or node.end_col_offset is None:
+                continue
+            start = node.col_offset
+            if isinstance(node, ast.Attribute):
+                # Only the attribute itself: its own chain is visited too.
+                start = node.end_col_offset - len(node.attr.encode("utf-8"))
+            yield Reference(
+                name,
+                file_path,
+                node.end_lineno or node.lineno,
+                start,
+                node.end_col_offset,
+            )
+
+
+def find_references(
+    root: str | Path,
+    graph: ImportGraph,
+    symbols: Iterable[str],
+) -> list[Reference]:
+    """Where the modules of ``graph`` (built for ``root``) name ``symbols``."""
+    wanted = set(symbols)
+    found = []
+    for info, tree in parse_modules(graph, "LSIF references"):
+        rel = relative_path(info.file_path, root)
+        found.extend(_references(info, tree, wanted, rel))
+    return sorted(found, key=lambda r: (r.file_path, r.lineno, r.col_offset))
+
+
+class _Dump:
+    """LSIF vertices and edges, numbered in the order they are emitted."""
+
+    def __init__(self) -> None:
+        self.lines: list[str] = []
+
+    def _emit(self, kind: str, label: str, data: dict[str, Any]) -> int:
+        identifier = len(self.lines) + 1
+        element = {"id": identifier, "type": kind, "label": label, **data}
+        self.lines.append(json.dumps(element, separators=(",", ":")))
+        return identifier
+
+    def vertex(self, label: str, **data: Any) -> int:
+        return self._emit("vertex", label, data)
+
+    def edge(self, label: str, out: int, to: int | list[int], **data: Any) -> int:
+        target = {"inVs": to} if isinstance(to, list) else {"inV": to}
+        return self._emit("edge", label, {"outV": out, **target, **data})
+
+
+class _Positions:
+    """LSP positions of the byte offsets of one file."""
+
+    def __init__(self, data: bytes) -> None:
+        self.data = data
+        self.offsets = line_offsets(data.decode("utf-8"))
+
+    def at(self, offset: int) -> dict[str, int]:
+        line = bisect.bisect_right(self.offsets, offset) - 1
+        text = self.data[self.offsets[line] : offset].decode("utf-8", "replace")
+        return {"line": line, "character": len(text.encode("utf-16-le")) // 2}
+
+    def at_column(self, lineno: int, col: int) -> dict[str, int]:
+        return self.at(self.offsets[lineno - 1] + col)
+
+
+def _uri(top: str, path: str) -> str:
+    prefix = "" if top == "." else f"{top}/"
+    return "file:///" + quote(f"{prefix}{path}")
+
+
+def _hover(symbol: DocSymbol) -> dict[str, Any]:
+    contents: list[Any] = [{"language": "python", "value": signature(symbol)}]
+    if symbol.is_documented and symbol.docstring:
+        contents.append(symbol.docstring.strip())
+    return {"contents": contents}
+
+
+def render_lsif(
+    root: str | Path,
+    graph: ImportGraph,
+    symbols: Iterable[DocSymbol],
+) -> str:
+    """The LSIF dump, one JSON element per line, of ``symbols`` below ``root``."""
+    symbols = list(symbols)
+    spans = find_symbol_spans(root, graph, symbols)
+    by_name = {symbol.qualified_name: symbol for symbol in symbols}
+    references = find_references(root, graph, [span.symbol for span in spans])
+    top = source_root(root)
+    files: dict[str, tuple[list[SymbolSpan], list[Reference]]] = defaultdict(
+        lambda: ([], []),
+    )
+    for span in spans:
+        files[span.file_path][0].append(span)
+    for reference in references:
+        files[reference.file_path][1].append(reference)
+
+    dump = _Dump()
+    dump.vertex(
+        "metaData",
+        version=LSIF_VERSION,
+        projectRoot=_uri(top, ""),
+        positionEncoding="utf-16",
+        toolInfo={"name": "autodoc", "version": __version__},
+    )
+    project = dump.vertex("project", kind="python")
+    result_sets = {}
+    for span in spans:
+        result_set = dump.vertex("resultSet")
+        result_sets[span.symbol] = result_set
+        moniker = dump.vertex(
+            "moniker",
+            kind="export",
+            scheme=MONIKER_SCHEME,
+            identifier=span.symbol,
+        )
+        dump.edge("moniker", result_set, moniker)
+        hover = dump.vertex("hoverResult", result=_hover(by_name[span.symbol]))
+        dump.edge("textDocument/hover", result_set, hover)
+
+    definitions: dict[str, tuple[int, int]] = {}
+    uses: dict[str, dict[int, list[int]]] = defaultdict(lambda: defaultdict(list))
+    for path in sorted(files):
+        defined, named = files[path]
+        document = dump.vertex("document", uri=_uri(top, path), languageId="python")
+        dump.edge("contains", project, [document])
+        positions = _Positions((Path(root) / path).read_bytes())
+        ranges = []
+        for span in defined:
+            identifier = dump.vertex(
+                "range",
+                start=positions.at(span.name_start or 0),
+                end=positions.at(span.name_end or 0),
+            )
+            dump.edge("next", identifier, result_sets[span.symbol])
+            definitions[span.symbol] = (document, identifier)
+            ranges.append(identifier)
+        for reference in named:
+            identifier = dump.vertex(
+                "range",
+                start=positions.at_column(reference.lineno, reference.col_offset),
+                end=positions.at_column(reference.lineno, reference.end_col_offset),
+            )
+            dump.edge("next", identifier, result_sets[reference.symbol])
+            uses[reference.symbol][document].append(identifier)
+            ranges.append(identifier)
+        if ranges:
+            dump.edge("contains", document, ranges)
+
+    for span in spans:
+        result_set = result_sets[span.symbol]
+        document, identifier = definitions[span.symbol]
+        result = dump.vertex("definitionResult")
+        dump.edge("textDocument/definition", result_set, result)
+        dump.edge("item", result, [identifier], document=document)
+        result = dump.vertex("referenceResult")
+        dump.edge("textDocument/references", result_set, result)
+        dump.edge(
+            "item",
+            result,
+            [identifier],
+            document=document,
+            property="definitions",
+        )
+        for used_in, ranges in uses[span.symbol].items():
+            dump.edge("item", result, ranges, document=used_in, property="references")
+    return "\n".join(dump.lines) + "\n"
+
+
+__all__ = [
+    "LSIF_PATH",
+    "LSIF_VERSION",
+    "MONIKER_SCHEME",
+    "Reference",
+    "find_references",
+    "render_lsif",
+]
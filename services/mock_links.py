@@ -0,0 +1,234 @@
+"""Links between interfaces and the mocks that stand in for them.
+
+An *interface* is a class that declares ``typing.Protocol`` or ``abc.ABC``
+as a base (or uses ``ABCMeta`` as its metaclass). :func:`find_mock_links`
+pairs interfaces with their mocks, found three ways:
+
+- ``subclass``: a mock class (see :func:`is_mock_class`) deriving from the
+  interface;
+- ``name``: a class in a mock module named after the interface, the layout
+  mock generators use (``mocks/store.py: class MockStore`` for ``Store``);
+- ``spec``: ``Mock(spec=Store)``, ``MagicMock(spec_set=Store)``, or
+  ``create_autospec(Store)`` assigned to a name. The mock is the assigned
+  module-level name, or the function that builds it.
+
+Mock modules live in ``mocks``/``fakes`` directories or are named
+``mock_*.py``/``*_mock.py``; the site hides them by default.
+"""
+
+from __future__ import annotations
+
+import ast
+import logging
+import re
+from collections import defaultdict
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+logger = logging.getLogger(__name__)
+
+MOCK_DIRS = frozenset({"mock", "mocks", "fake", "fakes"})
+INTERFACE_BASES = frozenset(
+    {
+        "typing.Protocol",
+        "typing_extensions.Protocol",
+        "abc.ABC",
+    },
+)
+INTERFACE_METACLASSES = frozenset({"abc.ABCMeta"})
+MOCK_FACTORIES = frozenset(
+    {
+        "unittest.mock.Mock",
+        "unittest.mock.MagicMock",
+        "unittest.mock.AsyncMock",
+        "unittest.mock.NonCallableMock",
+        "unittest.mock.NonCallableMagicMock",
+    },
+)
+AUTOSPEC = frozenset({"unittest.mock.create_autospec"})
+
+_MOCK_NAME = re.compile(
+    r"^(?:Mock|Fake|Stub)(?P<prefixed>[A-Z]\w*)$|^(?P<suffixed>\w+?)(?:Mock|Fake|Stub)$",
+)
+
+
+def is_mock_module(file_path: str | Path) -> bool:
+    """Whether ``file_path`` belongs to a mock module or mock directory."""
+    path = Path(file_path)
+    if any(part in MOCK_DIRS for part in path.parent.parts):
+        return True
+    stem = path.stem
+    return stem.startswith("mock_") or stem.endswith(("_mock", "_mocks"))
+
+
+def mocked_name(class_name: str) -> str | None:
+    """Interface name a mock class name refers to (``MockStore`` -> ``Store``)."""
+    match = _MOCK_NAME.match(class_name)
+    if match is None:
+        return None
+    return match.group("prefixed") or match.group("suffixed")
+
+
+def is_mock_class(class_name: str, file_path: str | Path) -> bool:
+    """Whether a class looks like a mock by its name or its module."""
+    return mocked_name(class_name) is not None or is_mock_module(file_path)
+
+
+@dataclass(frozen=True)
+class MockLink:
+    """``mock`` stands in for ``interface`` in tests."""
+
+    mock: str
+    interface: str
+    file_path: str
+    lineno: int
+    via: str  # 'subclass', 'name', or 'spec'
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "mock": self.mock,
+            "interface": self.interface,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "via": self.via,
+        }
+
+
+@dataclass(frozen=True)
+class _Class:
+    qualified_name: str
+    name: str
+    file_path: str
+    lineno: int
+    bases: tuple[str, ...]
+    is_interface: bool
+
+
+def _base_name(node: ast.expr) -> ast.expr:
+    # ``Protocol[T]`` and ``Generic[T]`` are the subscripted class.
+    return node.value if isinstance(node, ast.Subscript) else node
+
+
+class _ModuleScanner:
+    def __init__(self, tree: ast.Module, info: ModuleImports, file_path: str) -> None:
+        self.tree = tree
+        self.info = info
+        self.file_path = file_path
+        self.local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        }
+        self.classes: list[_Class] = []
+        # (mock name, resolved spec, line)
+        self.specs: list[tuple[str, str, int]] = []
+
+    def resolve(self, node: ast.expr) -> str:
+        return resolve_name(node, self.info, self.local_names)
+
+    def scan(self) -> None:
+        for node in self.tree.body:
+            if isinstance(node, ast.ClassDef):
+                self._class(node)
+            elif isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                owner = f"{self.info.module}.{node.name}"
+                for sub in ast.walk(node):
+                    if isinstance(sub, ast.Call):
+                        self._spec(owner, sub)
+            elif isinstance(node, (ast.Assign, ast.AnnAssign)) and isinstance(
+                node.value,
+                ast.Call,
+            ):
+                targets = node.targets if isinstance(node, ast.Assign) else [node.target]
+                if len(targets) == 1 and isinstance(targets[0], ast.Name):
+                    self._spec(f"{self.info.module}.{targets[0].id}", node.value)
+
+    def _class(self, node: ast.ClassDef) -> None:
+        bases = tuple(self.resolve(_base_name(base)) for base in node.bases)
+        metaclass = next(
+            (self.resolve(k.value) for k in node.keywords if k.arg == "metaclass"),
+            None,
+        )
+        self.classes.append(
+            _Class(
+                qualified_name=f"{self.info.module}.{node.name}",
+                name=node.name,
+                file_path=self.file_path,
+                lineno=node.lineno,
+                bases=bases,
+                is_interface=bool(INTERFACE_BASES.intersection(bases))
+                or metaclass in INTERFACE_METACLASSES,
+            ),
+        )
+
+    def _spec(self, mock: str, call: ast.Call) -> None:
+        func = self.resolve(call.func)
+        spec = None
+        if func in MOCK_FACTORIES:
+            spec = next(
+                (k.value for k in call.keywords if k.arg in ("spec", "spec_set")),
+                None,
+            )
+            if spec is None and call.args:
+                spec = call.args[0]
+        elif func in AUTOSPEC and call.args:
+            spec = call.args[0]
+        if spec is not None and not isinstance(spec, ast.Constant):
+            self.specs.append((mock, self.resolve(spec), call.lineno))
+
+
+def _class_link(cls: _Class, interface: str, via: str) -> MockLink:
+    return MockLink(cls.qualified_name, interface, cls.file_path, cls.lineno, via)
+
+
+def find_mock_links(root: str | Path, graph: ImportGraph) -> list[MockLink]:
+    """Find interface/mock pairs in every module of ``graph`` (built for ``root``)."""
+    scanners = []
+    for info, tree in parse_modules(graph, "mock analysis"):
+        scanner = _ModuleScanner(tree, info, relative_path(info.file_path, root))
+        scanner.scan()
+        scanners.append(scanner)
+
+    classes = [cls for scanner in scanners for cls in scanner.classes]
+    interfaces = {cls.qualified_name for cls in classes if cls.is_interface}
+    by_name: dict[str, list[str]] = defaultdict(list)
+    for cls in classes:
+        if cls.is_interface:
+            by_name[cls.name].append(cls.qualified_name)
+
+    links: set[MockLink] = set()
+    for cls in classes:
+        if cls.is_interface or not is_mock_class(cls.name, cls.file_path):
+            continue
+        implemented = [base for base in cls.bases if base in interfaces]
+        for interface in implemented:
+            links.add(_class_link(cls, interface, "subclass"))
+        if implemented or not is_mock_module(cls.file_path):
+            continue
+        # Generated mocks often share no base with the interface; match by
+        # name, but only when the name is unambiguous.
+        candidates = by_name.get(mocked_name(cls.name) or cls.name, [])
+        if len(candidates) == 1:
+            links.add(_class_link(cls, candidates[0], "name"))
+    for scanner in scanners:
+        for mock, spec, lineno in scanner.specs:
+            if spec in interfaces:
+                links.add(MockLink(mock, spec, scanner.file_path, lineno, "spec"))
+    return sorted(links, key=lambda link: (link.interface, link.mock, link.lineno))
+
+
+__all__ = [
+    "MockLink",
+    "find_mock_links",
+    "is_mock_class",
+    "is_mock_module",
+    "mocked_name",
+]
@@ -0,0 +1,107 @@
+"""Exported names that are easy to confuse.
+
+:func:`find_collisions` groups the exported classes and functions of a tree
+by their unqualified name and reports:
+
+- collisions: the same name defined in two or more packages (three different
+  ``Config`` classes), where an unqualified mention in docs, reviews, or an
+  import completion is ambiguous;
+- shadowing: an exported name that hides a Python builtin (``filter``,
+  ``type``, ``id``), which breaks the builtin for ``from x import *`` users
+  and for readers who assume the usual meaning.
+
+Generated sites use the same groups to qualify each namesake's heading with
+its full path and to cross-link the others with their summaries (see
+:func:`services.doc_site.attach_namesakes`).
+"""
+
+from __future__ import annotations
+
+import builtins
+from collections import defaultdict
+from collections.abc import Iterable
+from dataclasses import dataclass
+
+from services.doc_site import summary
+from services.doc_symbols import DocSymbol, is_exported
+
+CANDIDATE_KINDS = ("class", "function")
+
+BUILTIN_NAMES = frozenset(name for name in dir(builtins) if not name.startswith("_"))
+
+
+@dataclass(frozen=True)
+class NameCollision:
+    """Exported classes and functions sharing one unqualified name."""
+
+    name: str
+    symbols: tuple[DocSymbol, ...]
+
+    @property
+    def packages(self) -> list[str]:
+        return sorted({symbol.package for symbol in self.symbols})
+
+    @property
+    def shadows_builtin(self) -> bool:
+        return self.name in BUILTIN_NAMES
+
+    @property
+    def indistinct(self) -> list[str]:
+        """Namesakes whose summary is missing or repeated by another one."""
+        summaries = [summary(symbol.docstring) for symbol in self.symbols]
+        return [
+            symbol.qualified_name
+            for symbol, text in zip(self.symbols, summaries, strict=True)
+            if not text or summaries.count(text) > 1
+        ]
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "name": self.name,
+            "packages": self.packages,
+            "shadows_builtin": self.shadows_builtin,
+            "symbols": [
+                {
+                    "qualified_name": symbol.qualified_name,
+                    "kind": symbol.kind,
+                    "package": symbol.package,
+                    "file_path": symbol.file_path,
+                    "lineno": symbol.lineno,
+                    "summary": summary(symbol.docstring),
+                }
+                for symbol in self.symbols
+            ],
+            "indistinct": self.indistinct,
+        }
+
+
+def find_collisions(symbols: Iterable[DocSymbol]) -> list[NameCollision]:
+    """Group exported classes and functions that collide or shadow a builtin.
+
+    A name defined in several modules of one package is not a collision: the
+    package page already shows them side by side.
+
+    Returns:
+        Collisions sorted by name, each with its symbols sorted by
+        qualified name
+    """
+    by_name: dict[str, list[DocSymbol]] = defaultdict(list)
+    for symbol in symbols:
+        if symbol.kind in CANDIDATE_KINDS and is_exported(symbol):
+            by_name[symbol.name].append(symbol)
+
+    collisions = []
+    for name in sorted(by_name):
+        group = sorted(by_name[name], key=lambda s: s.qualified_name)
+        collision = NameCollision(name, tuple(group))
+        if collision.shadows_builtin or len(collision.packages) > 1:
+            collisions.append(collision)
+    return collisions
+
+
+__all__ = [
+    "BUILTIN_NAMES",
+    "CANDIDATE_KINDS",
+    "NameCollision",
+    "find_collisions",
+]
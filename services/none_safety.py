@@ -0,0 +1,228 @@
+"""Whether functions handle ``None`` for the parameters that allow it.
+
+Python's counterpart of a nil receiver is an optional parameter: one
+annotated ``X | None`` or ``Optional[X]``, or defaulting to ``None``.
+Library users want to know whether passing ``None`` is handled or only
+tolerated by the signature. :func:`find_none_safety` looks at the body of
+every function and method for each optional parameter and records:
+
+- ``checked`` when the body tests it (``is None``, ``is not None``,
+  ``== None``, truthiness in ``if``/``while``/``and``/``or``/conditional
+  expressions, or ``isinstance``) before any other use;
+- ``unchecked`` when the body uses it as a value that cannot be ``None``
+  (attributes, subscripts, calls, iteration, arithmetic, ``len``, ``in``,
+  unpacking) before testing it, or when its first test rejects ``None``:
+  an ``assert``, or an ``if`` with a branch that raises.
+
+Parameters that are only passed on to other calls, or not used, are
+reported as neither: whoever receives them decides. Nested functions and
+classes are not looked into.
+"""
+
+from __future__ import annotations
+
+import ast
+import re
+from collections.abc import Iterator
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import ImportGraph, parse_modules, relative_path
+
+NONE_SAFETY = ("checked", "unchecked")
+# Builtins that fail on None.
+_STRICT_BUILTINS = frozenset({"iter", "len", "next", "sorted", "sum"})
+_OPTIONAL = re.compile(r"\bNone\b|\bOptional\[")
+
+
+@dataclass(frozen=True)
+class NoneCheck:
+    """How a function treats ``None`` for one optional parameter."""
+
+    symbol: str
+    parameter: str
+    # One of :data:`NONE_SAFETY`.
+    status: str
+    file_path: str
+    # Line of the first test, or of the first unguarded use.
+    lineno: int
+
+    @property
+    def safe(self) -> bool:
+        return self.status == "checked"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "symbol": self.symbol,
+            "parameter": self.parameter,
+            "status": self.status,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+        }
+
+
+def optional_parameters(
+    func: ast.FunctionDef | ast.AsyncFunctionDef,
+) -> list[str]:
+    """Names of the parameters of ``func`` that may be ``None``, in order."""
+    args = func.args
+    positional = [*args.posonlyargs, *args.args]
+    defaults: list[ast.expr | None] = [None] * (len(positional) - len(args.defaults))
+    defaults.extend(args.defaults)
+    names = []
+    for arg, default in [
+        *zip(positional, defaults),
+        *zip(args.kwonlyargs, args.kw_defaults),
+    ]:
+        none_default = isinstance(default, ast.Constant) and default.value is None
+        annotated = arg.annotation is not None and _OPTIONAL.search(
+            ast.unparse(arg.annotation),
+        )
+        if none_default or annotated:
+            names.append(arg.arg)
+    return names
+
+
+def _body_nodes(body: list[ast.stmt]) -> Iterator[ast.AST]:
+    """Walk ``body`` without descending into nested functions or classes."""
+    stack: list[ast.AST] = list(reversed(body))
+    while stack:
+        node = stack.pop()
+        yield node
+        if isinstance(
+            node,
+            (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef, ast.Lambda),
+        ):
+            continue
+        stack.extend(reversed(list(ast.iter_child_nodes(node))))
+
+
+def _is(node: ast.AST | None, name: str) -> bool:
+    return isinstance(node, ast.Name) and node.id == name
+
+
+def _truth_test(node: ast.expr, name: str) -> bool:
+    """Whether ``node`` tests the truth of ``name`` (``x``, ``not x``)."""
+    if isinstance(node, ast.UnaryOp) and isinstance(node.op, ast.Not):
+        node = node.operand
+    return _is(node, name)
+
+
+def _tests(node: ast.AST, name: str) -> bool:
+    """Whether ``node`` tests ``name`` for ``None``."""
+    if isinstance(node, ast.Compare) and len(node.ops) == 1:
+        operands = [node.left, *node.comparators]
+        none = any(isinstance(o, ast.Constant) and o.value is None for o in operands)
+        if none and any(_is(o, name) for o in operands):
+            return isinstance(node.ops[0], (ast.Is, ast.IsNot, ast.Eq, ast.NotEq))
+    if isinstance(node, ast.BoolOp):
+        return any(_truth_test(value, name) for value in node.values)
+    if isinstance(node, (ast.If, ast.While, ast.IfExp, ast.Assert)):
+        return _truth_test(node.test, name)
+    if isinstance(node, ast.Call) and _is(node.func, "isinstance") and node.args:
+        return _is(node.args[0], name)
+    return False
+
+
+def _rejects(node: ast.AST, name: str) -> bool:
+    """Whether ``node`` is a guard that fails for ``name`` being ``None``.
+
+    ``assert x is not None`` and ``if x is None: raise ...`` test ``x``, but
+    only to refuse ``None``, so they do not make the parameter None-safe.
+    """
+    if isinstance(node, ast.Assert):
+        return _guards(node.test, name)
+    if isinstance(node, ast.If) and _guards(node.test, name):
+        return any(
+            isinstance(statement, ast.Raise)
+            for statement in [*node.body, *node.orelse]
+        )
+    return False
+
+
+def _guards(test: ast.expr, name: str) -> bool:
+    """Whether the condition ``test`` tests ``name``, on its own or in part."""
+    return _truth_test(test, name) or any(
+        _tests(node, name) for node in ast.walk(test)
+    )
+
+
+def _uses(node: ast.AST, name: str) -> bool:
+    """Whether ``node`` uses ``name`` in a way that fails for ``None``."""
+    if isinstance(node, (ast.Attribute, ast.Subscript)):
+        return _is(node.value, name)
+    if isinstance(node, ast.Call):
+        if _is(node.func, name):
+            return True
+        if isinstance(node.func, ast.Name) and node.func.id in _STRICT_BUILTINS:
+            return any(_is(arg, name) for arg in node.args)
+        return False
+    if isinstance(node, (ast.For, ast.AsyncFor, ast.comprehension)):
+        return _is(node.iter, name)
+    if isinstance(node, ast.BinOp):
+        return _is(node.left, name) or _is(node.right, name)
+    if isinstance(node, ast.Compare):
+        return any(
+            isinstance(op, (ast.In, ast.NotIn)) and _is(right, name)
+            for op, right in zip(node.ops, node.comparators)
+        )
+    if isinstance(node, ast.Starred):
+        return _is(node.value, name)
+    if isinstance(node, ast.keyword) and node.arg is None:
+        return _is(node.value, name)
+    return False
+
+
+def check_parameter(
+    func: ast.FunctionDef | ast.AsyncFunctionDef,
+    name: str,
+) -> tuple[str, int] | None:
+    """``(status, line)`` for the optional parameter ``name`` of ``func``.
+
+    The first test or use of ``name`` in source order decides.
+    """
+    for node in _body_nodes(func.body):
+        line = getattr(node, "lineno", func.lineno)
+        if _rejects(node, name):
+            return "unchecked", line
+        if _tests(node, name):
+            return "checked", line
+        if _uses(node, name):
+            return "unchecked", line
+    return None
+
+
+def _functions(
+    body: list[ast.stmt],
+    prefix: str,
+) -> Iterator[tuple[str, ast.FunctionDef | ast.AsyncFunctionDef]]:
+    for node in body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+            yield f"{prefix}.{node.name}", node
+        elif isinstance(node, ast.ClassDef):
+            yield from _functions(node.body, f"{prefix}.{node.name}")
+
+
+def find_none_safety(root: str | Path, graph: ImportGraph) -> list[NoneCheck]:
+    """How the functions of ``graph`` (built for ``root``) treat ``None``.
+
+    Results are in module order, then source and parameter order.
+    """
+    found = []
+    for info, tree in parse_modules(graph, "None safety analysis"):
+        rel = relative_path(info.file_path, root)
+        for name, func in _functions(tree.body, info.module):
+            for parameter in optional_parameters(func):
+                result = check_parameter(func, parameter)
+                if result is not None:
+                    found.append(NoneCheck(name, parameter, result[0], rel, result[1]))
+    return found
+
+
+__all__ = [
+    "NONE_SAFETY",
+    "NoneCheck",
+    "check_parameter",
+    "find_none_safety",
+    "optional_parameters",
+]
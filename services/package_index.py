@@ -0,0 +1,274 @@
+"""Download releases from a Python package index.
+
+An :class:`IndexRelease` is a distribution at a version, such as
+``billing==1.2.0``, fetched from a simple repository (PEP 503 HTML or PEP 691
+JSON): PyPI, or a private index such as devpi, Artifactory, or a GitLab
+package registry. It is the Python counterpart of fetching a Go module
+through ``GOPROXY``, and lets ``autodoc generate`` and ``autodoc serve``
+document published versions without a checkout.
+
+The index is ``--index``, else ``$PIP_INDEX_URL``, else PyPI (see
+:func:`index_url`). Releases are unpacked once into a cache directory and
+reused by later runs.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import html
+import io
+import logging
+import os
+import re
+import shutil
+import tarfile
+import zipfile
+from collections.abc import Mapping
+from pathlib import Path, PurePosixPath
+from typing import Any
+from urllib.parse import urljoin, urlsplit
+
+import httpx
+
+from services.doc_external_links import normalize
+from services.doc_links import NetrcCredentials
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_INDEX = "https://pypi.org/simple"
+INDEX_ENV = "PIP_INDEX_URL"
+DEFAULT_CACHE = ".autodoc-cache/releases"
+SIMPLE_JSON = "application/vnd.pypi.simple.v1+json"
+
+_REQUIREMENT = re.compile(r"^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:==\s*([^\s]+))?$")
+_ANCHOR = re.compile(r"<a\s([^>]*)>([^<]+)</a>", re.I)
+_HREF = re.compile(r"""href=["']([^"']+)["']""", re.I)
+
+
+class PackageIndexError(Exception):
+    """Raised when a release cannot be found, downloaded, or unpacked."""
+
+
+def index_url(
+    configured: str | None = None,
+    environ: Mapping[str, str] | None = None,
+) -> str:
+    """The index to use: ``configured``, else ``$PIP_INDEX_URL``, else PyPI."""
+    environ = os.environ if environ is None else environ
+    return configured or environ.get(INDEX_ENV) or DEFAULT_INDEX
+
+
+def _version_key(version: str) -> tuple[tuple[int, str], ...]:
+    """Sorts ``1.10.0`` after ``1.9.2``; good enough to pick the newest release."""
+    return tuple(
+        (int(part), "") if part.isdigit() else (-1, part)
+        for part in re.findall(r"\d+|[a-z]+", version.lower())
+    )
+
+
+def _has_prerelease(version: str) -> bool:
+    return bool(re.search(r"(a|b|rc|dev)\d*", version.lower()))
+
+
+class IndexRelease:
+    """A release of a distribution, downloaded from a package index on first use.
+
+    ``requirement`` is ``name`` (the newest final release) or
+    ``name==version``. Wheels are preferred to source distributions. Hashes
+    published by the index are checked, and a login for the index host is
+    taken from netrc, as for link checks (:mod:`services.doc_links`).
+    """
+
+    def __init__(
+        self,
+        index_url: str,
+        requirement: str,
+        cache_dir: Path,
+        client: httpx.Client | None = None,
+        credentials: NetrcCredentials | None = None,
+    ) -> None:
+        match = _REQUIREMENT.match(requirement.strip())
+        if match is None:
+            raise PackageIndexError(
+                f"{requirement!r} is not a release like billing or billing==1.2.0",
+            )
+        self.index_url = index_url.rstrip("/") + "/"
+        self.distribution = match.group(1)
+        self.version = match.group(2)
+        self.cache_dir = cache_dir
+        self.name = normalize(self.distribution)
+        self._client = client
+        self._credentials = credentials
+        self._root: Path | None = None
+        # The unpacked release, holding its config file; set by root().
+        self.project_dir: Path | None = None
+
+    @property
+    def label(self) -> str:
+        version = self.version or "latest"
+        return f"{self.distribution} {version} from {self.index_url}"
+
+    def import_names(self) -> list[str]:
+        return [self.distribution.replace("-", "_").lower()]
+
+    def _http(self) -> httpx.Client:
+        if self._client is None:
+            host = urlsplit(self.index_url).hostname or ""
+            login = self._credentials.login(host) if self._credentials else None
+            self._client = httpx.Client(
+                follow_redirects=True,
+                timeout=30.0,
+                auth=httpx.BasicAuth(*login) if login else None,
+                headers={"User-Agent": "autodoc"},
+            )
+        return self._client
+
+    def files(self) -> list[dict[str, Any]]:
+        """The project's files: ``filename``, ``url``, and ``hashes``."""
+        url = urljoin(self.index_url, f"{self.name}/")
+        try:
+            response = self._http().get(
+                url,
+                headers={"Accept": f"{SIMPLE_JSON}, text/html;q=0.1"},
+            )
+            response.raise_for_status()
+        except httpx.HTTPError as exc:
+            message = f"Cannot list {self.distribution} at {url}: {exc}"
+            raise PackageIndexError(message) from exc
+        if response.headers.get("content-type", "").startswith(SIMPLE_JSON):
+            return [
+                {**item, "url": urljoin(url, item["url"])}
+                for item in response.json().get("files", [])
+            ]
+        files = []
+        for attributes, text in _ANCHOR.findall(response.text):
+            href = _HREF.search(attributes)
+            if href is None:
+                continue
+            link, _, fragment = html.unescape(href.group(1)).partition("#")
+            algorithm, _, digest = fragment.partition("=")
+            files.append(
+                {
+                    "filename": text.strip(),
+                    "url": urljoin(url, link),
+                    "hashes": {algorithm: digest} if digest else {},
+                    "yanked": "data-yanked" in attributes,
+                },
+            )
+        return files
+
+    def _release(self, files: list[dict[str, Any]]) -> tuple[str, dict[str, Any]]:
+        prefix = self.name.replace("-", "_")
+        releases: dict[str, list[dict[str, Any]]] = {}
+        for item in files:
+            filename = item["filename"]
+            if filename.endswith(".whl"):
+                parts = filename.split("-")
+            elif filename.endswith(".tar.gz"):
+                parts = filename[: -len(".tar.gz")].rsplit("-", 1)
+            else:
+                continue
+            if len(parts) < 2 or normalize(parts[0]).replace("-", "_") != prefix:
+                continue
+            if item.get("yanked") and self.version is None:
+                continue
+            releases.setdefault(parts[1], []).append(item)
+        if self.version is not None:
+            version = self.version
+        else:
+            finals = [v for v in releases if not _has_prerelease(v)]
+            version = max(finals or releases, key=_version_key, default="")
+        candidates = releases.get(version, [])
+        # Pure-Python wheels first, then any wheel, then the sdist.
+        candidates.sort(
+            key=lambda f: (
+                not f["filename"].endswith("-none-any.whl"),
+                not f["filename"].endswith(".whl"),
+            ),
+        )
+        if not candidates:
+            wanted = self.distribution
+            if self.version:
+                wanted += f"=={self.version}"
+            raise PackageIndexError(f"{self.index_url} has no release of {wanted}")
+        return version, candidates[0]
+
+    def _download(self, item: dict[str, Any]) -> bytes:
+        try:
+            response = self._http().get(item["url"])
+            response.raise_for_status()
+        except httpx.HTTPError as exc:
+            message = f"Cannot download {item['filename']}: {exc}"
+            raise PackageIndexError(message) from exc
+        data = response.content
+        expected = (item.get("hashes") or {}).get("sha256")
+        if expected and hashlib.sha256(data).hexdigest() != expected:
+            message = f"{item['filename']} does not match its sha256 hash"
+            raise PackageIndexError(message)
+        return data
+
+    def root(self) -> Path:
+        """The import root of the release, downloaded and unpacked if needed.
+
+        Raises:
+            PackageIndexError: If the index has no such release, or it cannot
+                be downloaded or unpacked
+        """
+        if self._root is not None:
+            return self._root
+        version, item = self._release(self.files())
+        target = self.cache_dir / f"{self.name}-{version}"
+        if not target.is_dir():
+            logger.info("Downloading %s", item["filename"])
+            _extract(item["filename"], self._download(item), target)
+        self.version = version
+        self.project_dir = _project_dir(target)
+        src = self.project_dir / "src"
+        self._root = src if src.is_dir() else self.project_dir
+        return self._root
+
+
+def _extract(filename: str, data: bytes, target: Path) -> None:
+    """Unpack a wheel or sdist into ``target``, keeping paths inside it."""
+    partial = target.with_name(target.name + ".partial")
+    # Left over by an interrupted download.
+    shutil.rmtree(partial, ignore_errors=True)
+    partial.mkdir(parents=True)
+    try:
+        if filename.endswith(".whl"):
+            with zipfile.ZipFile(io.BytesIO(data)) as archive:
+                for member in archive.namelist():
+                    parts = PurePosixPath(member).parts
+                    if ".." in parts or member.startswith("/"):
+                        message = f"{filename} has an unsafe path {member!r}"
+                        raise PackageIndexError(message)
+                archive.extractall(partial)
+        else:
+            with tarfile.open(fileobj=io.BytesIO(data), mode="r:gz") as archive:
+                archive.extractall(partial, filter="data")
+    except (OSError, zipfile.BadZipFile, tarfile.TarError, PackageIndexError) as exc:
+        shutil.rmtree(partial, ignore_errors=True)
+        if isinstance(exc, PackageIndexError):
+            raise
+        raise PackageIndexError(f"Cannot unpack {filename}: {exc}") from exc
+    partial.rename(target)
+
+
+def _project_dir(directory: Path) -> Path:
+    """The top directory of an unpacked release."""
+    entries = [path for path in directory.iterdir() if path.is_dir()]
+    if len(entries) == 1 and "-" in entries[0].name:
+        # An sdist unpacks into a single ``name-version/`` directory.
+        return entries[0]
+    return directory
+
+
+__all__ = [
+    "DEFAULT_CACHE",
+    "DEFAULT_INDEX",
+    "INDEX_ENV",
+    "SIMPLE_JSON",
+    "IndexRelease",
+    "PackageIndexError",
+    "index_url",
+]
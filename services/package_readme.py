@@ -0,0 +1,113 @@
+"""README files of package directories, merged into the package pages.
+
+A ``README.md`` (:data:`README_NAME`) next to a package's modules is shown at
+the top of its page, above the API reference, so the overview maintainers
+already write for the code host is part of the docs too.
+
+The README's headings are moved one level down (see :func:`demote_headings`),
+below the page's own title. :func:`~services.doc_assets.resolve_assets`
+points its relative links into the site: images are copied like the images
+of docstrings, links to another package's directory or README open that
+package's page, and links to other files of the tree copy the file into
+:data:`~services.doc_assets.FILES_DIR`.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+from collections.abc import Iterator
+from dataclasses import dataclass
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+README_NAME = "README.md"
+_HEADING = re.compile(r"^(?P<marks>#{1,6})[ \t]+(?P<text>.*?)(?:[ \t]+#+)?[ \t]*$")
+_FENCE = re.compile(r"^[ \t]{0,3}(?P<fence>`{3,}|~{3,})")
+
+
+@dataclass(frozen=True)
+class PackageReadme:
+    """The README of one package directory."""
+
+    # Path of the README, as the package's modules are given.
+    file_path: str
+    content: str
+
+
+def load_readme(directory: str | Path) -> PackageReadme | None:
+    """The :data:`README_NAME` of ``directory``, if it has a readable one."""
+    path = Path(directory) / README_NAME
+    if not path.is_file():
+        return None
+    try:
+        content = path.read_text(encoding="utf-8")
+    except (OSError, UnicodeDecodeError) as exc:
+        logger.warning("Skipping %s: %s", path, exc)
+        return None
+    return PackageReadme(str(path), content)
+
+
+def parse_heading(line: str) -> tuple[int, str] | None:
+    """The ``(level, text)`` of an ATX heading line, or ``None``."""
+    match = _HEADING.match(line)
+    return (len(match["marks"]), match["text"]) if match else None
+
+
+def outside_fences(markdown: str) -> Iterator[tuple[str, bool]]:
+    """Each line of ``markdown`` and whether it is prose (not fenced code)."""
+    fence: str | None = None
+    for line in markdown.split("\n"):
+        match = _FENCE.match(line)
+        if fence is None and match is not None:
+            fence = match["fence"]
+            yield line, False
+        elif fence is not None:
+            if line.strip().startswith(fence[0] * len(fence)):
+                fence = None
+            yield line, False
+        else:
+            yield line, True
+
+
+def demote_headings(markdown: str, top: int = 2) -> str:
+    """``markdown`` with its headings starting at level ``top``.
+
+    Levels are shifted so the highest heading becomes ``top``, without
+    skipping a level below the heading before, and stop at 6.
+    """
+    levels = [level for level, _ in readme_headings(markdown)]
+    if not levels:
+        return markdown
+    shift = top - min(levels)
+    lines = []
+    previous = top - 1
+    for line, prose in outside_fences(markdown):
+        heading = parse_heading(line) if prose else None
+        if heading is not None:
+            level = min(heading[0] + shift, previous + 1, 6)
+            previous = level
+            line = f"{'#' * level} {heading[1]}"
+        lines.append(line)
+    return "\n".join(lines)
+
+
+def readme_headings(markdown: str) -> list[tuple[int, str]]:
+    """The ``(level, text)`` of each heading of ``markdown``, in order."""
+    return [
+        heading
+        for line, prose in outside_fences(markdown)
+        if prose and (heading := parse_heading(line)) is not None
+    ]
+
+
+__all__ = [
+    "README_NAME",
+    "PackageReadme",
+    "demote_headings",
+    "load_readme",
+    "outside_fences",
+    "parse_heading",
+    "readme_headings",
+]
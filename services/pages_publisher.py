@@ -0,0 +1,283 @@
+"""Publish a generated site to a git branch for GitHub Pages.
+
+:func:`publish_to_branch` commits a site directory to a branch such as
+``gh-pages``, or to a folder such as ``docs/`` of another branch, as a single
+commit per run. It works on git objects only: the site is hashed into a
+temporary index and committed with ``commit-tree``, so the working tree, the
+real index, and the checked-out branch are never touched. A branch that does
+not exist yet is created as an orphan branch with no history from the source
+branches.
+
+A ``.nojekyll`` file is added so GitHub Pages serves the site as is, including
+files whose names start with an underscore.
+"""
+
+from __future__ import annotations
+
+import logging
+import os
+import subprocess
+import tempfile
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+from services.bucket_publisher import PublishError, SyncChange, SyncPlan, site_files
+from services.cancellation import CancelToken
+from services.telemetry import count, span
+from services.write_plan import CREATE, DELETE, MODIFY, UNCHANGED
+
+logger = logging.getLogger(__name__)
+
+GIT_SCHEME = "git"
+DEFAULT_MESSAGE = "Update documentation for {commit}"
+NOJEKYLL_FILE = ".nojekyll"
+
+_FILE_MODE = "100644"
+
+
+def _git(
+    repo: Path,
+    *args: str,
+    stdin: str | None = None,
+    index: Path | None = None,
+) -> str:
+    env = None
+    if index is not None:
+        env = {**os.environ, "GIT_INDEX_FILE": str(index)}
+    try:
+        result = subprocess.run(
+            ["git", *args],
+            cwd=str(repo),
+            input=stdin,
+            env=env,
+            capture_output=True,
+            text=True,
+            check=True,
+        )
+    except FileNotFoundError as exc:
+        raise PublishError("git executable not found") from exc
+    except subprocess.CalledProcessError as exc:
+        raise PublishError(f"git {args[0]} failed: {exc.stderr.strip()}") from exc
+    return result.stdout
+
+
+@dataclass(frozen=True)
+class PagesTarget:
+    """A branch, and optionally a folder of it, that holds a site."""
+
+    branch: str
+    folder: str = ""
+
+    @classmethod
+    def parse(cls, destination: str) -> PagesTarget:
+        """Parse ``git:<branch>`` or ``git:<branch>:<folder>``."""
+        scheme, _, rest = destination.partition(":")
+        branch, _, folder = rest.partition(":")
+        folder = folder.strip("/")
+        if scheme != GIT_SCHEME or not branch or ".." in folder.split("/"):
+            raise PublishError(
+                f"{destination!r} is not a branch like git:gh-pages or "
+                "git:main:docs",
+            )
+        return cls(branch, folder)
+
+    @property
+    def prefix(self) -> str:
+        return f"{self.folder}/" if self.folder else ""
+
+    def url(self, path: str) -> str:
+        """``<branch>:<path>``, for messages."""
+        return f"{self.branch}:{path}"
+
+
+@dataclass
+class PagesCommit:
+    """The outcome of :func:`publish_to_branch`."""
+
+    plan: SyncPlan
+    commit: str | None = None
+
+    def summary(self, dry_run: bool = False) -> str:
+        if dry_run:
+            return self.plan.summary(dry_run=True)
+        if self.commit is None:
+            return f"{self.plan.url} is up to date; nothing was committed"
+        return f"{self.plan.summary()} (commit {self.commit[:12]})"
+
+    def to_dict(self) -> dict[str, Any]:
+        return {**self.plan.to_dict(), "commit": self.commit}
+
+
+def _tree_entries(repo: Path, revision: str | None) -> dict[str, tuple[str, str]]:
+    """``path -> (mode, object)`` for every file of ``revision``."""
+    if revision is None:
+        return {}
+    entries = {}
+    for record in _git(repo, "ls-tree", "-r", "-z", revision).split("\0"):
+        if record:
+            info, path = record.split("\t", 1)
+            mode, _, sha = info.split(" ")
+            entries[path] = (mode, sha)
+    return entries
+
+
+def _hash_files(repo: Path, paths: list[Path], write: bool) -> list[str]:
+    if not paths:
+        return []
+    args = ["hash-object", *(["-w"] if write else []), "--stdin-paths"]
+    stdin = "".join(f"{path.resolve()}\n" for path in paths)
+    return _git(repo, *args, stdin=stdin).split()
+
+
+def _checked_out(repo: Path) -> str | None:
+    """The ref of the checked-out branch; ``None`` for a detached HEAD."""
+    try:
+        return _git(repo, "symbolic-ref", "-q", "HEAD").strip()
+    except PublishError:
+        return None
+
+
+def commit_message(template: str, repo: Path, target: PagesTarget) -> str:
+    """``template`` with ``{commit}`` and ``{branch}`` filled in.
+
+    ``{commit}`` is the short hash of the commit the site was built from.
+    """
+    try:
+        commit = _git(repo, "rev-parse", "--short", "HEAD").strip()
+    except PublishError:
+        commit = "an unborn branch"
+    try:
+        return template.format(commit=commit, branch=target.branch)
+    except (KeyError, IndexError, ValueError) as exc:
+        raise PublishError(
+            f"Commit message {template!r} is not valid: use {{commit}} and "
+            "{branch} only",
+        ) from exc
+
+
+def publish_to_branch(
+    directory: str | Path,
+    target: PagesTarget,
+    repo: str | Path = ".",
+    message: str = DEFAULT_MESSAGE,
+    delete: bool = True,
+    single_commit: bool = False,
+    dry_run: bool = False,
+    cancel: CancelToken | None = None,
+) -> PagesCommit:
+    """Commit the site in ``directory`` to ``target`` in one commit.
+
+    Args:
+        directory: Output directory of ``autodoc generate``
+        target: Branch and folder to publish to
+        repo: Any directory of the repository
+        message: Commit message template, see :func:`commit_message`
+        delete: Remove files of the folder that are no longer part of the site
+        single_commit: Replace the branch's history by the new commit, so the
+            branch holds one commit only
+        dry_run: Only plan the changes
+        cancel: Stops before committing when cancelled
+
+    Raises:
+        PublishError: If the site cannot be read or a git command fails
+    """
+    files = site_files(directory)
+    repo = Path(_git(Path(repo), "rev-parse", "--show-toplevel").strip())
+    text = commit_message(message, repo, target)
+    ref = f"refs/heads/{target.branch}"
+    if _checked_out(repo) == ref:
+        # Moving the ref would leave the working tree behind the branch.
+        raise PublishError(
+            f"{target.branch} is checked out; switch branches, or generate "
+            "into the repository and commit the site yourself",
+        )
+    try:
+        parent: str | None = _git(repo, "rev-parse", "--verify", "-q", ref).strip()
+    except PublishError:
+        # The branch does not exist yet: bootstrap it as an orphan branch.
+        parent = None
+    old = _tree_entries(repo, parent)
+
+    shas = _hash_files(repo, list(files.values()), write=not dry_run)
+    local = dict(
+        zip((target.prefix + path for path in files), shas, strict=True),
+    )
+    nojekyll = target.prefix + NOJEKYLL_FILE
+    if nojekyll not in local:
+        args = ["hash-object", *([] if dry_run else ["-w"]), "--stdin"]
+        local[nojekyll] = _git(repo, *args, stdin="").strip()
+
+    changes = []
+    new = {}
+    for path, entry in old.items():
+        if delete and path.startswith(target.prefix) and path not in local:
+            changes.append(SyncChange(path, DELETE))
+        else:
+            new[path] = entry
+    for path, sha in local.items():
+        if path not in old:
+            action = CREATE
+        elif old[path][1] != sha:
+            action = MODIFY
+        else:
+            action = UNCHANGED
+        changes.append(SyncChange(path, action))
+        new[path] = (_FILE_MODE, sha)
+    url = target.url(target.prefix) if target.folder else target.branch
+    result = PagesCommit(SyncPlan(url, sorted(changes, key=lambda c: c.key)))
+    changed = any(change.action != UNCHANGED for change in changes)
+    if not changed and single_commit and parent is not None:
+        # Squash a branch that still has history, even with nothing new.
+        changed = _git(repo, "rev-list", "--count", parent).strip() != "1"
+    if dry_run or not changed:
+        return result
+    if cancel is not None and cancel.cancelled:
+        cancel.skip("publish", len(files))
+        return result
+
+    published = [change for change in changes if change.action != UNCHANGED]
+    with span("autodoc.publish", target=url, objects=len(published)):
+        with tempfile.TemporaryDirectory() as scratch:
+            index = Path(scratch) / "index"
+            info = "".join(
+                f"{mode} {sha}\t{path}\0" for path, (mode, sha) in sorted(new.items())
+            )
+            args = ["update-index", "--add", "-z", "--index-info"]
+            _git(repo, *args, stdin=info, index=index)
+            tree = _git(repo, "write-tree", index=index).strip()
+
+        parents = ["-p", parent] if parent is not None and not single_commit else []
+        commit = _git(repo, "commit-tree", tree, *parents, stdin=text).strip()
+        # Compare-and-swap, so a concurrent publish is not silently overwritten.
+        _git(repo, "update-ref", "-m", "autodoc publish", ref, commit, parent or "")
+    for change in published:
+        count("autodoc.objects.published", action=change.action)
+    logger.info("Committed %s to %s", commit, target.branch)
+    result.commit = commit
+    return result
+
+
+def push_branch(
+    target: PagesTarget,
+    remote: str,
+    repo: str | Path = ".",
+    force: bool = False,
+) -> None:
+    """Push ``target.branch`` to ``remote``; ``force`` after a single commit."""
+    ref = f"refs/heads/{target.branch}"
+    flags = ["--force"] if force else []
+    with span("autodoc.publish.push", target=target.branch, remote=remote):
+        _git(Path(repo), "push", *flags, remote, f"{ref}:{ref}")
+
+
+__all__ = [
+    "DEFAULT_MESSAGE",
+    "GIT_SCHEME",
+    "NOJEKYLL_FILE",
+    "PagesCommit",
+    "PagesTarget",
+    "commit_message",
+    "publish_to_branch",
+    "push_branch",
+]
@@ -12,7 +12,7 @@ from sqlalchemy.orm import Session
 from sqlalchemy import select
 
 from db.models import Patch, Rule, Run
-from services.confluence_client import ConfluenceClient
+from services.confluence_client import ConfluenceClient, load_confluence_settings
 from services.confluence_publisher import ConfluencePublisher
 
 logger = logging.getLogger(__name__)
@@ -83,7 +83,7 @@ def publish_patches_to_confluence(
         # Load connection from database
         from db.models import Connection
         from core.encryption import decrypt_token
-        from autodoc.config.settings import ConfluenceSettings, get_settings
+        from autodoc.config.settings import ConfluenceSettings
 
         connection = db.execute(select(Connection).limit(1)).scalar_one_or_none()
         if not connection:
@@ -97,12 +97,12 @@ def publish_patches_to_confluence(
         except Exception as e:
             raise ValueError(f"Failed to decrypt connection token: {e}") from e
 
-        # Get app settings for timeout and max_retries
-        app_settings = get_settings()
+        # Get the environment and autodoc.yaml settings for timeout and max_retries
+        defaults = load_confluence_settings()
 
         # Create ConfluenceSettings from database connection
         # For Confluence API tokens, username must be an email address, not the token
-        username = app_settings.confluence.username
+        username = defaults.username
         if not username:
             raise ValueError(
                 "CONFLUENCE_USERNAME is required for Confluence API authentication. "
@@ -114,8 +114,8 @@ def publish_patches_to_confluence(
             username=username,
             token=decrypted_token,
             space_key=connection.space_key,
-            timeout=app_settings.confluence.timeout,
-            max_retries=app_settings.confluence.max_retries,
+            timeout=defaults.timeout,
+            max_retries=defaults.max_retries,
         )
 
         # Get Confluence client and publisher with database connection settings
@@ -0,0 +1,127 @@
+"""Placeholder summaries for symbols without a docstring.
+
+With ``site.placeholder_summaries``, the site shows a one-line summary
+guessed from the name and signature of each undocumented symbol instead of
+an empty entry, marked as auto-generated wherever it appears. The guess is
+deliberately plain: the leading verb of a name is conjugated
+(``get_user(username) -> User`` is "Retrieves a user by ``username``."), an
+``is_``/``has_`` predicate reads "Whether ...", and a class reads as the
+noun it is named after. Names outside :data:`VERBS` only repeat the name's
+words and list what the function takes and returns, never what it does.
+
+The symbol itself stays undocumented: coverage, lint, and the docstring
+analyses never see a placeholder.
+"""
+
+from __future__ import annotations
+
+import re
+
+from services.doc_symbols import DocSymbol
+
+# A leading name word -> the verb of the summary, and the preposition that
+# introduces the parameters.
+VERBS = {
+    "add": ("adds", "to"),
+    "build": ("builds", "from"),
+    "calculate": ("calculates", "from"),
+    "check": ("checks", "against"),
+    "compute": ("computes", "from"),
+    "create": ("creates", "from"),
+    "delete": ("deletes", "by"),
+    "fetch": ("fetches", "by"),
+    "find": ("finds", "by"),
+    "get": ("retrieves", "by"),
+    "handle": ("handles", "with"),
+    "list": ("lists", "by"),
+    "load": ("loads", "from"),
+    "lookup": ("looks up", "by"),
+    "make": ("makes", "from"),
+    "parse": ("parses", "from"),
+    "read": ("reads", "from"),
+    "remove": ("removes", "by"),
+    "render": ("renders", "from"),
+    "run": ("runs", "with"),
+    "save": ("saves", "to"),
+    "send": ("sends", "to"),
+    "set": ("sets", "to"),
+    "update": ("updates", "with"),
+    "validate": ("validates", "against"),
+    "write": ("writes", "to"),
+}
+# Leading words of predicates, read as "Whether ...".
+PREDICATES = ("is", "has", "can", "should")
+_WORDS = re.compile(r"[A-Z]+(?=[A-Z][a-z])|[A-Z]?[a-z]+|[A-Z]+|\d+")
+_SKIPPED = {"self", "cls"}
+
+
+def _words(name: str) -> list[str]:
+    return [word.lower() for word in _WORDS.findall(name)]
+
+
+def _noun(words: list[str]) -> str:
+    """``words`` with an article, unless they read as a plural."""
+    phrase = " ".join(words)
+    if words[-1].endswith("s") and not words[-1].endswith(("ss", "us", "is")):
+        return phrase
+    return f"{'an' if phrase[0] in 'aeio' else 'a'} {phrase}"
+
+
+def _listing(names: list[str]) -> str:
+    names = [f"``{name}``" for name in names]
+    if len(names) < 3:
+        return " and ".join(names)
+    return f"{', '.join(names[:-1])}, and {names[-1]}"
+
+
+def _callable(symbol: DocSymbol) -> str | None:
+    metadata = symbol.metadata or {}
+    params = [
+        param["name"]
+        for param in metadata.get("parameters", [])
+        if param["name"] not in _SKIPPED
+    ]
+    words = _words(symbol.name)
+    if not words:
+        return None
+    if words[0] in PREDICATES and len(words) > 1:
+        return f"Whether it {' '.join(words)}."
+    if words[0] in VERBS:
+        verb, preposition = VERBS[words[0]]
+        returns = metadata.get("return_type")
+        target = words[1:] or _words(returns or "")
+        text = f"{verb.capitalize()} {_noun(target)}" if target else verb.capitalize()
+        if params:
+            text += f" {preposition} {_listing(params)}"
+        return text + "."
+    text = " ".join(words).capitalize() + "."
+    if params:
+        text += f" Takes {_listing(params)}."
+    if metadata.get("return_type"):
+        text += f" Returns ``{metadata['return_type']}``."
+    return text
+
+
+def placeholder_summary(symbol: DocSymbol) -> str | None:
+    """The placeholder for ``symbol``; ``None`` if it has a docstring.
+
+    Functions and methods are summarized from their name, parameters, and
+    return annotation, classes and modules from their name.
+    """
+    if symbol.is_documented:
+        return None
+    if symbol.kind in ("function", "method"):
+        return _callable(symbol)
+    words = _words(symbol.name)
+    if not words:
+        return None
+    if symbol.kind == "class":
+        return _noun(words).capitalize() + "."
+    return f"The ``{symbol.name}`` module."
+
+
+__all__ = [
+    "PREDICATES",
+    "VERBS",
+    "placeholder_summary",
+]
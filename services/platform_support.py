@@ -0,0 +1,371 @@
+"""Which modules and symbols of a tree exist on which operating systems.
+
+Python has no build tags, but platform-specific code shows in three ways,
+each restricting a module or symbol to some of :data:`PLATFORMS`:
+
+- the module is named for a platform, as in ``windows_events`` or
+  ``_posix`` (see :data:`NAME_PLATFORMS`);
+- the module imports, at its top level, a standard library module that
+  only exists there, such as ``winreg`` or ``fcntl`` (see
+  :data:`MODULE_PLATFORMS`);
+- a top-level function, class, or variable is defined under a platform
+  check (``if sys.platform == "win32":``, ``os.name``, or
+  ``platform.system()``); its ``else`` branch gets the other platforms.
+
+Checks the analysis cannot read, such as a call of a helper, leave both
+branches on every platform. :func:`find_platform_support` returns every
+package with its restrictions, which ``site.platforms`` renders as the
+"Platform support" page.
+"""
+
+from __future__ import annotations
+
+import ast
+from collections.abc import Callable, Iterator
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+PLATFORMS = ("linux", "macos", "windows")
+PLATFORM_TITLES = {"linux": "Linux", "macos": "macOS", "windows": "Windows"}
+_POSIX = ("linux", "macos")
+# Words of module names -> the platforms the module is for.
+NAME_PLATFORMS = {
+    "darwin": ("macos",),
+    "linux": ("linux",),
+    "macos": ("macos",),
+    "nt": ("windows",),
+    "osx": ("macos",),
+    "posix": _POSIX,
+    "unix": _POSIX,
+    "win": ("windows",),
+    "win32": ("windows",),
+    "windows": ("windows",),
+}
+# Standard library modules that exist on some platforms only.
+MODULE_PLATFORMS = {
+    "_overlapped": ("windows",),
+    "_winapi": ("windows",),
+    "crypt": _POSIX,
+    "fcntl": _POSIX,
+    "grp": _POSIX,
+    "msvcrt": ("windows",),
+    "posix": _POSIX,
+    "pty": _POSIX,
+    "pwd": _POSIX,
+    "resource": _POSIX,
+    "syslog": _POSIX,
+    "termios": _POSIX,
+    "tty": _POSIX,
+    "winreg": ("windows",),
+    "winsound": ("windows",),
+}
+# Values of the platform checks -> the platforms they select.
+_PLATFORM_VALUES = {
+    "sys.platform": {
+        "cygwin": ("windows",),
+        "darwin": ("macos",),
+        "linux": ("linux",),
+        "win": ("windows",),
+    },
+    "os.name": {"nt": ("windows",), "posix": _POSIX},
+    "platform.system": {
+        "Darwin": ("macos",),
+        "Linux": ("linux",),
+        "Windows": ("windows",),
+    },
+}
+# Why a module or symbol is restricted.
+SOURCES = ("name", "import", "guard")
+
+
+@dataclass(frozen=True)
+class PlatformRestriction:
+    """A module or top-level symbol that exists on ``platforms`` only."""
+
+    # The module, or the qualified name of the symbol.
+    name: str
+    module: str
+    package: str
+    platforms: tuple[str, ...]
+    # One of SOURCES: the module name, a platform-only import, or a check.
+    source: str
+    # The platform word, the imported module, or the check's source code.
+    detail: str
+    file_path: str
+    lineno: int
+
+    @property
+    def is_module(self) -> bool:
+        return self.name == self.module
+
+    def reason(self, code: Callable[[str], str]) -> str:
+        """Why the restriction applies, with names formatted by ``code``."""
+        if self.source == "name":
+            return f"named for {code(self.detail)}"
+        if self.source == "import":
+            return f"imports {code(self.detail)}"
+        return f"defined under {code(self.detail)}"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "name": self.name,
+            "module": self.module,
+            "package": self.package,
+            "platforms": list(self.platforms),
+            "source": self.source,
+            "detail": self.detail,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+        }
+
+
+@dataclass(frozen=True)
+class PackagePlatforms:
+    """The modules of one package and their platform restrictions."""
+
+    package: str
+    modules: tuple[str, ...]
+    restrictions: tuple[PlatformRestriction, ...]
+
+    def support(self, platform: str) -> str:
+        """``yes``, ``partial`` (some modules or symbols missing), or ``no``."""
+        missing = [r for r in self.restrictions if platform not in r.platforms]
+        if not missing:
+            return "yes"
+        unavailable = {r.module for r in missing if r.is_module}
+        return "no" if unavailable.issuperset(self.modules) else "partial"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "package": self.package,
+            "support": {platform: self.support(platform) for platform in PLATFORMS},
+            "restrictions": [r.to_dict() for r in self.restrictions],
+        }
+
+
+def _selected(
+    test: ast.expr,
+    info: ModuleImports,
+    local_names: set[str],
+) -> frozenset[str] | None:
+    """The platforms on which ``test`` is true; None if it is not a check."""
+    if isinstance(test, ast.UnaryOp) and isinstance(test.op, ast.Not):
+        inner = _selected(test.operand, info, local_names)
+        return None if inner is None else frozenset(PLATFORMS) - inner
+    if isinstance(test, ast.BoolOp):
+        parts = [_selected(value, info, local_names) for value in test.values]
+        if any(part is None for part in parts):
+            return None
+        if isinstance(test.op, ast.And):
+            return frozenset.intersection(*parts)
+        return frozenset.union(*parts)
+    if isinstance(test, ast.Call) and isinstance(test.func, ast.Attribute):
+        # ``sys.platform.startswith("win")``
+        if test.func.attr != "startswith" or len(test.args) != 1:
+            return None
+        return _compared(test.func.value, "==", test.args[0], info, local_names)
+    if isinstance(test, ast.Compare) and len(test.ops) == 1:
+        op = test.ops[0]
+        symbol = {ast.Eq: "==", ast.NotEq: "!=", ast.In: "in", ast.NotIn: "not in"}
+        if type(op) not in symbol:
+            return None
+        return _compared(
+            test.left,
+            symbol[type(op)],
+            test.comparators[0],
+            info,
+            local_names,
+        )
+    return None
+
+
+def _compared(
+    subject: ast.expr,
+    op: str,
+    value: ast.expr,
+    info: ModuleImports,
+    local_names: set[str],
+) -> frozenset[str] | None:
+    if isinstance(subject, ast.Call) and not subject.args:
+        subject = subject.func
+    values = _PLATFORM_VALUES.get(resolve_name(subject, info, local_names) or "")
+    if values is None:
+        return None
+    literals = [value]
+    if op in ("in", "not in"):
+        if not isinstance(value, (ast.Tuple, ast.List, ast.Set)):
+            return None
+        literals = value.elts
+    selected: set[str] = set()
+    for literal in literals:
+        if not isinstance(literal, ast.Constant) or not isinstance(literal.value, str):
+            return None
+        # Values are compared by prefix: ``win32``, and ``linux2`` on old Pythons.
+        key = next((k for k in values if literal.value.startswith(k)), None)
+        selected.update(values.get(key, ()) if key else ())
+    result = frozenset(selected)
+    return frozenset(PLATFORMS) - result if op in ("!=", "not in") else result
+
+
+def _defined(statement: ast.stmt) -> Iterator[tuple[str, int]]:
+    """The names ``statement`` defines at the top level of its module."""
+    if isinstance(statement, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+        yield statement.name, statement.lineno
+    elif isinstance(statement, ast.Assign):
+        for target in statement.targets:
+            if isinstance(target, ast.Name):
+                yield target.id, statement.lineno
+    elif isinstance(statement, ast.AnnAssign):
+        if isinstance(statement.target, ast.Name):
+            yield statement.target.id, statement.lineno
+
+
+def _guarded(
+    body: list[ast.stmt],
+    platforms: frozenset[str],
+    condition: str | None,
+    info: ModuleImports,
+    local_names: set[str],
+) -> Iterator[tuple[str, int, frozenset[str], str]]:
+    """The names defined in ``body`` with the platforms and check they need."""
+    for statement in body:
+        if condition is not None:
+            for name, lineno in _defined(statement):
+                yield name, lineno, platforms, condition
+        if not isinstance(statement, ast.If):
+            continue
+        selected = _selected(statement.test, info, local_names)
+        if selected is None:
+            continue
+        test = ast.unparse(statement.test)
+        yield from _guarded(
+            statement.body,
+            platforms & selected,
+            test,
+            info,
+            local_names,
+        )
+        yield from _guarded(
+            statement.orelse,
+            platforms - selected,
+            f"not ({test})",
+            info,
+            local_names,
+        )
+
+
+def _module_restriction(
+    info: ModuleImports,
+    tree: ast.Module,
+    root: str | Path,
+) -> PlatformRestriction | None:
+    path = relative_path(info.file_path, root)
+    words = info.module.rsplit(".", 1)[-1].strip("_").split("_")
+    word = next((word for word in words if word in NAME_PLATFORMS), None)
+    if word is not None:
+        return PlatformRestriction(
+            name=info.module,
+            module=info.module,
+            package=info.package,
+            platforms=NAME_PLATFORMS[word],
+            source="name",
+            detail=word,
+            file_path=path,
+            lineno=1,
+        )
+    for statement in tree.body:
+        names = []
+        if isinstance(statement, ast.Import):
+            names = [alias.name for alias in statement.names]
+        elif isinstance(statement, ast.ImportFrom) and statement.level == 0:
+            names = [statement.module or ""]
+        for name in names:
+            top = name.split(".", 1)[0]
+            if top in MODULE_PLATFORMS:
+                return PlatformRestriction(
+                    name=info.module,
+                    module=info.module,
+                    package=info.package,
+                    platforms=MODULE_PLATFORMS[top],
+                    source="import",
+                    detail=top,
+                    file_path=path,
+                    lineno=statement.lineno,
+                )
+    return None
+
+
+def find_platform_support(
+    root: str | Path,
+    graph: ImportGraph,
+) -> list[PackagePlatforms]:
+    """Every package of ``graph`` with its restrictions, in package order.
+
+    A symbol defined in several branches (a Windows and a POSIX ``def
+    read_key``) is available wherever one of them is; it is listed only if
+    some platform has none.
+    """
+    modules: dict[str, list[str]] = {}
+    restrictions: dict[str, list[PlatformRestriction]] = {}
+    for info, tree in parse_modules(graph, "platform detection"):
+        modules.setdefault(info.package, []).append(info.module)
+        found = restrictions.setdefault(info.package, [])
+        restriction = _module_restriction(info, tree, root)
+        if restriction is not None:
+            found.append(restriction)
+        local_names = {name for s in tree.body for name, _ in _defined(s)}
+        branches: dict[str, list[tuple[int, frozenset[str], str]]] = {}
+        everywhere = frozenset(PLATFORMS)
+        for name, lineno, platforms, condition in _guarded(
+            tree.body,
+            everywhere,
+            None,
+            info,
+            local_names,
+        ):
+            branches.setdefault(name, []).append((lineno, platforms, condition))
+        for name, defined in branches.items():
+            platforms = frozenset().union(*(p for _, p, _ in defined))
+            if name in local_names or platforms == everywhere:
+                continue
+            lineno, _, condition = defined[0]
+            found.append(
+                PlatformRestriction(
+                    name=f"{info.module}.{name}",
+                    module=info.module,
+                    package=info.package,
+                    platforms=tuple(p for p in PLATFORMS if p in platforms),
+                    source="guard",
+                    detail=condition if len(defined) == 1 else "platform checks",
+                    file_path=relative_path(info.file_path, root),
+                    lineno=lineno,
+                ),
+            )
+    return [
+        PackagePlatforms(
+            package,
+            tuple(modules[package]),
+            tuple(sorted(restrictions[package], key=lambda r: (r.module, r.lineno))),
+        )
+        for package in sorted(modules)
+    ]
+
+
+__all__ = [
+    "MODULE_PLATFORMS",
+    "NAME_PLATFORMS",
+    "PLATFORMS",
+    "PLATFORM_TITLES",
+    "SOURCES",
+    "PackagePlatforms",
+    "PlatformRestriction",
+    "find_platform_support",
+]
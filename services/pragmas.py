@@ -0,0 +1,233 @@
+"""Tool pragmas on the classes, functions, and modules they apply to.
+
+Python's counterpart of a compiler directive is a comment addressed to a
+linter, type checker, formatter, or coverage tool::
+
+    def total(cart):  # noqa: C901
+    def peek(cart):  # pylint: disable=protected-access
+    # mypy: ignore-errors
+
+:func:`find_pragmas` reads the comments of every module and keeps the
+pragmas it recognizes (:data:`PRAGMA_KINDS`). A pragma belongs to a class or
+function when it is on its ``def``/``class`` line, one of its decorators,
+the rest of its signature, or the comment lines right above them; file-wide
+pragmas (:data:`FILE_PRAGMAS`) and the comments above a module's first
+statement belong to the module. Pragmas inside bodies apply to single
+statements and are not reported.
+
+Pragmas that silence warnings about another module's private names
+(:data:`PRIVATE_ACCESS_CODES`) are flagged: code that reaches into
+internals breaks without notice when they change, so they are a
+maintenance risk worth listing on their own.
+"""
+
+from __future__ import annotations
+
+import ast
+import io
+import logging
+import re
+import tokenize
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import ImportGraph, relative_path
+
+logger = logging.getLogger(__name__)
+
+# Pragma kinds, each with the rest of its comment (codes, settings).
+_PATTERNS = (
+    r"(?P<kind>noqa)\b(?::\s*(?P<codes>[A-Z]+\d+(?:[,\s]+[A-Z]+\d+)*))?",
+    r"(?P<kind>flake8:\s*noqa)\b",
+    r"(?P<kind>(?:type|pyright):\s*ignore)\b(?:\[(?P<codes>[^\]]*)\])?",
+    r"(?P<kind>pylint:\s*(?:disable-next|disable|enable|skip-file))"
+    r"(?:\s*=\s*(?P<codes>[\w,\s-]+))?",
+    r"(?P<kind>mypy):\s*(?P<codes>.+)",
+    r"(?P<kind>pragma:\s*no\s+(?:cover|branch))\b",
+    r"(?P<kind>nosec)\b(?::?\s*(?P<codes>B\d+(?:[,\s]+B\d+)*))?",
+    r"(?P<kind>(?:fmt|isort):\s*(?:off|on|skip|skip_file))\b",
+)
+_PRAGMAS = tuple(re.compile(pattern, re.I) for pattern in _PATTERNS)
+PRAGMA_KINDS = frozenset(
+    {
+        "flake8: noqa",
+        "fmt: off",
+        "fmt: on",
+        "fmt: skip",
+        "isort: off",
+        "isort: on",
+        "isort: skip",
+        "isort: skip_file",
+        "mypy",
+        "noqa",
+        "nosec",
+        "pragma: no branch",
+        "pragma: no cover",
+        "pylint: disable",
+        "pylint: disable-next",
+        "pylint: enable",
+        "pylint: skip-file",
+        "pyright: ignore",
+        "type: ignore",
+    },
+)
+# Pragmas that apply to the whole file wherever they are.
+FILE_PRAGMAS = frozenset(
+    {"flake8: noqa", "isort: skip_file", "mypy", "pylint: skip-file"},
+)
+# Warning codes about private names, as pylint, ruff, and pyright spell them.
+PRIVATE_ACCESS_CODES = frozenset(
+    {
+        "c2701",
+        "import-private-name",
+        "plc2701",
+        "protected-access",
+        "reportprivateimportusage",
+        "reportprivateusage",
+        "slf001",
+        "w0212",
+    },
+)
+
+
+@dataclass(frozen=True)
+class Pragma:
+    """One pragma and the symbol it applies to."""
+
+    symbol: str
+    kind: str
+    # The pragma as written, with its whitespace collapsed.
+    text: str
+    file_path: str
+    lineno: int
+    codes: tuple[str, ...] = ()
+
+    @property
+    def private_access(self) -> bool:
+        """Whether the pragma silences a warning about a private name."""
+        return any(code.lower() in PRIVATE_ACCESS_CODES for code in self.codes)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "symbol": self.symbol,
+            "kind": self.kind,
+            "text": self.text,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "codes": list(self.codes),
+            "private_access": self.private_access,
+        }
+
+
+def parse_pragmas(comment: str) -> list[tuple[str, str, tuple[str, ...]]]:
+    """The ``(kind, text, codes)`` of each pragma in one ``#`` comment.
+
+    A comment may hold several: ``# type: ignore[attr-defined]  # noqa``.
+    """
+    found = []
+    for part in comment.split("#"):
+        text = " ".join(part.split())
+        for pattern in _PRAGMAS:
+            match = pattern.match(text)
+            if match is None:
+                continue
+            kind = re.sub(r":\s*", ": ", " ".join(match.group("kind").split()))
+            codes = (match.groupdict().get("codes") or "").strip("\"' ")
+            names = tuple(code for code in re.split(r"[,\s]+", codes) if code)
+            found.append((kind.lower(), text, names))
+            break
+    return found
+
+
+def _comments(source: str) -> tuple[dict[int, str], set[int]]:
+    """Every comment by line, and the lines holding nothing but a comment."""
+    comments = {}
+    own_lines = set()
+    tokens = tokenize.generate_tokens(io.StringIO(source).readline)
+    try:
+        for token in tokens:
+            if token.type == tokenize.COMMENT:
+                comments[token.start[0]] = token.string
+                if token.line.lstrip().startswith("#"):
+                    own_lines.add(token.start[0])
+    except (tokenize.TokenError, SyntaxError) as exc:
+        logger.warning("Stopped reading comments: %s", exc)
+    return comments, own_lines
+
+
+def _headers(
+    body: list[ast.stmt],
+    prefix: str,
+) -> list[tuple[str, int, int]]:
+    """``(qualified name, first line, last line)`` of each def and class header."""
+    headers = []
+    for node in body:
+        if not isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+            continue
+        name = f"{prefix}.{node.name}"
+        first = min([node.lineno, *(d.lineno for d in node.decorator_list)])
+        last = max(node.lineno, node.body[0].lineno - 1)
+        headers.append((name, first, last))
+        if isinstance(node, ast.ClassDef):
+            headers.extend(_headers(node.body, name))
+    return headers
+
+
+def _owners(
+    tree: ast.Module,
+    module: str,
+    comments: dict[int, str],
+    own_lines: set[int],
+) -> dict[int, str]:
+    """The symbol each comment line belongs to, for the lines that have one."""
+    owners = {}
+    for name, first, last in _headers(tree.body, module):
+        above = first - 1
+        # Own-line comments directly above the header belong to it too.
+        while above in own_lines and above not in owners:
+            above -= 1
+        for lineno in range(above + 1, last + 1):
+            if lineno in comments:
+                owners[lineno] = name
+    first_statement = tree.body[0].lineno if tree.body else None
+    for lineno in comments:
+        if lineno not in owners and (
+            first_statement is None or lineno < first_statement
+        ):
+            owners[lineno] = module
+    return owners
+
+
+def find_pragmas(root: str | Path, graph: ImportGraph) -> list[Pragma]:
+    """The pragmas of the modules of ``graph`` (built for ``root``).
+
+    Pragmas are sorted by file and line.
+    """
+    pragmas = []
+    for name in sorted(graph.modules):
+        info = graph.modules[name]
+        try:
+            source = Path(info.file_path).read_text(encoding="utf-8")
+            tree = ast.parse(source, filename=info.file_path)
+        except (OSError, UnicodeDecodeError, SyntaxError) as exc:
+            logger.warning("Skipping %s in pragma detection: %s", info.file_path, exc)
+            continue
+        comments, own_lines = _comments(source)
+        owners = _owners(tree, info.module, comments, own_lines)
+        rel = relative_path(info.file_path, root)
+        for lineno, comment in sorted(comments.items()):
+            for kind, text, codes in parse_pragmas(comment):
+                owner = info.module if kind in FILE_PRAGMAS else owners.get(lineno)
+                if owner is not None:
+                    pragmas.append(Pragma(owner, kind, text, rel, lineno, codes))
+    return pragmas
+
+
+__all__ = [
+    "FILE_PRAGMAS",
+    "PRAGMA_KINDS",
+    "PRIVATE_ACCESS_CODES",
+    "Pragma",
+    "find_pragmas",
+    "parse_pragmas",
+]
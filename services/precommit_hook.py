@@ -0,0 +1,97 @@
+"""Pre-commit documentation check over staged Python files.
+
+Only files in the index are parsed, and the staged blob (not the working tree
+copy) is what gets checked. A commit is blocked when it *introduces* lint
+findings: problems that already existed in ``HEAD`` are not held against it.
+"""
+
+from __future__ import annotations
+
+import logging
+import time
+from collections.abc import Sequence
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+from services.doc_coverage import PackageCoverage, compute_coverage
+from services.doc_lint import LintFinding, LintRule, lint_symbols, new_findings
+from services.doc_symbols import DocSymbolLoader
+from services.git_source import INDEX, show_file, staged_files
+from services.ignore_file import load_ignore_file
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class HookResult:
+    """Outcome of a staged-files documentation check."""
+
+    files_checked: list[str] = field(default_factory=list)
+    findings: list[LintFinding] = field(default_factory=list)
+    coverage: list[PackageCoverage] = field(default_factory=list)
+    elapsed_seconds: float = 0.0
+
+    @property
+    def passed(self) -> bool:
+        return not self.findings
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "passed": self.passed,
+            "files_checked": self.files_checked,
+            "findings": [f.to_dict() for f in self.findings],
+            "coverage": [c.to_dict() for c in self.coverage],
+            "elapsed_seconds": self.elapsed_seconds,
+        }
+
+
+def check_staged(
+    repo: str | Path,
+    rules: Sequence[LintRule] | None = None,
+) -> HookResult:
+    """Lint the staged version of every staged Python file.
+
+    Args:
+        repo: Repository root
+        rules: Lint rules to apply (default: the built-in rules)
+
+    Returns:
+        Findings introduced by the staged changes, plus coverage of the
+        staged files for information
+    """
+    started = time.perf_counter()
+    loader = DocSymbolLoader(repo)
+    ignore = load_ignore_file(repo)
+    result = HookResult(files_checked=ignore.filter(staged_files(repo)))
+
+    staged_symbols = []
+    for path in result.files_checked:
+        staged_source = show_file(repo, INDEX, path)
+        if staged_source is None:
+            continue
+        symbols = loader.load_source(staged_source, path)
+        staged_symbols.extend(symbols)
+
+        head_source = show_file(repo, "HEAD", path)
+        head_findings = (
+            lint_symbols(loader.load_source(head_source, path), rules)
+            if head_source is not None
+            else []
+        )
+        result.findings.extend(
+            new_findings(lint_symbols(symbols, rules), head_findings),
+        )
+
+    result.coverage = compute_coverage(staged_symbols)
+    result.elapsed_seconds = round(time.perf_counter() - started, 3)
+    logger.info(
+        "Checked %d staged file(s) in %.3fs: %d new finding(s)",
+        len(result.files_checked),
+        result.elapsed_seconds,
+        len(result.findings),
+    )
+    return result
+
+
+__all__ = ["HookResult", "check_staged"]
@@ -0,0 +1,157 @@
+"""Structured progress events for the documentation pipeline.
+
+A :class:`Progress` is handed to the stages of a run the way a
+:class:`~services.cancellation.CancelToken` is, and each stage reports what
+it has done to the listener as :class:`ProgressEvent` objects, so a UI that
+wraps AutoDoc (an editor plugin, a CI dashboard) can show real progress bars
+instead of parsing log lines. The stages of ``autodoc generate``, in order:
+
+- :data:`DISCOVER`: the Python files found below the root, in one event;
+- :data:`PARSE`: files read for their symbols;
+- :data:`ANALYZE`: files read for their imports;
+- :data:`RENDER`: package pages rendered, counting each format without
+  package pages (JSON, the indexes) as one;
+- :data:`PUBLISH`: files written to the output directories.
+
+Every stage reports when it starts and finishes, and at most every
+``interval`` seconds in between. An event carries the count done, the total
+when it is known, and an ETA extrapolated from the stage's rate so far.
+Incremental and resumed runs count only the files they parse again.
+
+``generate --progress json`` writes the events to stderr as JSON lines
+(:func:`json_listener`), next to but distinct from the log records: each has
+``"event": "progress"``.
+"""
+
+from __future__ import annotations
+
+import json
+import time
+from collections.abc import Callable
+from dataclasses import dataclass
+from typing import Any, TextIO
+
+from services.schema import stamp_schema
+
+DISCOVER = "discover"
+PARSE = "parse"
+ANALYZE = "analyze"
+RENDER = "render"
+PUBLISH = "publish"
+# Stages in the order a run reaches them.
+STAGES = (DISCOVER, PARSE, ANALYZE, RENDER, PUBLISH)
+# Seconds between two events of a stage, other than its first and last.
+DEFAULT_INTERVAL = 0.1
+
+
+@dataclass(frozen=True)
+class ProgressEvent:
+    """How far one stage of a run has got."""
+
+    stage: str
+    done: int
+    # Items the stage will process; None while not known.
+    total: int | None
+    # Seconds since the stage started.
+    elapsed: float
+    # Estimated seconds until the stage finishes; None while not known.
+    eta: float | None
+    finished: bool = False
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "event": "progress",
+            "stage": self.stage,
+            "done": self.done,
+            "total": self.total,
+            "elapsed": round(self.elapsed, 3),
+            "eta": None if self.eta is None else round(self.eta, 3),
+            "finished": self.finished,
+        }
+
+
+@dataclass
+class _Stage:
+    total: int | None
+    started: float
+    done: int = 0
+    reported: float = 0.0
+
+
+class Progress:
+    """The stages of one run, reported to ``listener`` as they advance."""
+
+    def __init__(
+        self,
+        listener: Callable[[ProgressEvent], None],
+        interval: float = DEFAULT_INTERVAL,
+        clock: Callable[[], float] = time.monotonic,
+    ) -> None:
+        self.listener = listener
+        self.interval = interval
+        self.clock = clock
+        self._stages: dict[str, _Stage] = {}
+
+    def start(self, stage: str, total: int | None = None) -> None:
+        """Start ``stage``, which will process ``total`` items if known."""
+        self._stages[stage] = _Stage(total, self.clock())
+        self._emit(stage, self._stages[stage])
+
+    def advance(self, stage: str, count: int = 1) -> None:
+        """Record that ``stage`` processed ``count`` more items."""
+        current = self._stages.get(stage)
+        if current is None:
+            current = self._stages[stage] = _Stage(None, self.clock())
+        current.done += count
+        if self.clock() - current.reported >= self.interval:
+            self._emit(stage, current)
+
+    def finish(self, stage: str) -> None:
+        """Report ``stage`` as finished, even if it stopped short of its total."""
+        current = self._stages.get(stage)
+        if current is None:
+            current = self._stages[stage] = _Stage(0, self.clock())
+        self._emit(stage, current, finished=True)
+
+    def complete(self, stage: str, count: int) -> None:
+        """Report ``stage`` as done with ``count`` items, in one event."""
+        self._stages[stage] = _Stage(count, self.clock(), count)
+        self._emit(stage, self._stages[stage], finished=True)
+
+    def _emit(self, stage: str, current: _Stage, finished: bool = False) -> None:
+        now = self.clock()
+        current.reported = now
+        elapsed = now - current.started
+        eta = None
+        if finished:
+            eta = 0.0
+        elif current.total is not None and current.done:
+            remaining = max(current.total - current.done, 0)
+            eta = elapsed / current.done * remaining
+        self.listener(
+            ProgressEvent(stage, current.done, current.total, elapsed, eta, finished),
+        )
+
+
+def json_listener(stream: TextIO) -> Callable[[ProgressEvent], None]:
+    """A listener that writes each event to ``stream`` as a line of JSON."""
+
+    def write(event: ProgressEvent) -> None:
+        stream.write(json.dumps(stamp_schema(event.to_dict())) + "\n")
+        stream.flush()
+
+    return write
+
+
+__all__ = [
+    "ANALYZE",
+    "DEFAULT_INTERVAL",
+    "DISCOVER",
+    "PARSE",
+    "PUBLISH",
+    "RENDER",
+    "STAGES",
+    "Progress",
+    "ProgressEvent",
+    "json_listener",
+]
@@ -0,0 +1,237 @@
+"""Replaced dependency sources and retracted releases of a project.
+
+Some packaging settings change what users of the documentation get
+without touching a line of code, so ``site.directives`` lists them at the
+top of the site's index:
+
+- replacements: dependencies installed from somewhere other than the
+  package index, through ``[tool.uv.sources]`` (a path, a git repository,
+  a URL, another index, or a workspace member), an editable ``-e``
+  requirement, or a direct ``name @ url`` reference;
+- retractions: releases of the project that should not be used, declared
+  under ``[tool.autodoc]`` in ``pyproject.toml`` as single versions or
+  inclusive ranges, each with an optional rationale::
+
+      [tool.autodoc]
+      retract = [
+          "1.2.0",
+          {versions = "[1.3.0, 1.3.2]", rationale = "Corrupts the cache."},
+      ]
+
+When the documented version, ``project.version`` of ``pyproject.toml``,
+is retracted, the index opens with a warning and the build logs one.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.doc_external_links import (
+    normalize,
+    pyproject_data,
+    requirement_lines,
+)
+from services.semver import Version, VersionError, parse_version
+
+logger = logging.getLogger(__name__)
+
+# Where a replaced dependency comes from instead of the package index.
+REPLACEMENT_KINDS = ("path", "git", "url", "index", "workspace", "editable")
+_GIT_REFS = ("rev", "tag", "branch")
+_DIRECT = re.compile(
+    r"^\s*(?P<name>[A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*@\s*(?P<url>\S+)",
+)
+_EGG = re.compile(r"#egg=(?P<name>[A-Za-z0-9][A-Za-z0-9._-]*)")
+_RANGE = re.compile(r"^\[\s*(?P<low>[^,\s]+)\s*,\s*(?P<high>[^\]\s]+)\s*\]$")
+
+
+@dataclass(frozen=True)
+class Replacement:
+    """A dependency installed from ``target`` rather than the package index."""
+
+    distribution: str
+    # One of REPLACEMENT_KINDS.
+    kind: str
+    target: str
+
+    def to_dict(self) -> dict[str, str]:
+        return {
+            "distribution": self.distribution,
+            "kind": self.kind,
+            "target": self.target,
+        }
+
+
+@dataclass(frozen=True)
+class Retraction:
+    """Releases ``low`` through ``high`` (inclusive) of the project."""
+
+    low: Version
+    high: Version
+    rationale: str | None = None
+
+    def covers(self, version: Version) -> bool:
+        return self.low <= version <= self.high
+
+    def label(self) -> str:
+        """``1.2.0``, or ``1.3.0 - 1.3.2`` for a range."""
+        if self.low == self.high:
+            return str(self.low)
+        return f"{self.low} - {self.high}"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "low": str(self.low),
+            "high": str(self.high),
+            "rationale": self.rationale,
+        }
+
+
+@dataclass(frozen=True)
+class ProjectDirectives:
+    """The replacements and retractions of a project, and its version."""
+
+    # ``project.version``, or None when it is dynamic or missing.
+    version: str | None
+    replacements: tuple[Replacement, ...]
+    retractions: tuple[Retraction, ...]
+
+    def __bool__(self) -> bool:
+        return bool(self.replacements or self.retractions)
+
+    def retracted(self) -> Retraction | None:
+        """The retraction covering the documented version, if any."""
+        if self.version is None:
+            return None
+        try:
+            version = parse_version(self.version)
+        except VersionError:
+            return None
+        return next((r for r in self.retractions if r.covers(version)), None)
+
+    def to_dict(self) -> dict[str, object]:
+        retracted = self.retracted()
+        return {
+            "version": self.version,
+            "retracted": retracted.to_dict() if retracted else None,
+            "replacements": [r.to_dict() for r in self.replacements],
+            "retractions": [r.to_dict() for r in self.retractions],
+        }
+
+
+def _source(name: str, source: dict) -> Replacement | None:
+    if source.get("workspace"):
+        return Replacement(name, "workspace", "workspace member")
+    if "git" in source:
+        ref = next((source[key] for key in _GIT_REFS if key in source), None)
+        target = f"{source['git']}@{ref}" if ref else str(source["git"])
+        return Replacement(name, "git", target)
+    for kind in ("path", "url", "index"):
+        if kind in source:
+            return Replacement(name, kind, str(source[kind]))
+    return None
+
+
+def _uv_sources(data: dict) -> list[Replacement]:
+    sources = data.get("tool", {}).get("uv", {}).get("sources", {})
+    replacements = []
+    for name, entry in sources.items():
+        # A list of sources selects one per platform or extra; list each.
+        for source in entry if isinstance(entry, list) else [entry]:
+            replacement = _source(name, source) if isinstance(source, dict) else None
+            if replacement is not None:
+                replacements.append(replacement)
+    return replacements
+
+
+def _requirement_replacement(line: str) -> Replacement | None:
+    line = line.split(" #", 1)[0].strip()
+    if line.startswith(("-e ", "--editable ")):
+        target = line.split(None, 1)[1].strip()
+        egg = _EGG.search(target)
+        name = egg["name"] if egg else Path(target.split("#", 1)[0]).name or target
+        return Replacement(name, "editable", target.split("#", 1)[0])
+    match = _DIRECT.match(line)
+    if match is None:
+        return None
+    url = match["url"]
+    if url.startswith("git+"):
+        kind = "git"
+    else:
+        kind = "path" if url.startswith("file:") else "url"
+    return Replacement(match["name"], kind, url)
+
+
+def _retraction(entry: object) -> Retraction | None:
+    rationale = None
+    if isinstance(entry, dict):
+        rationale = entry.get("rationale")
+        entry = entry.get("versions")
+    if not isinstance(entry, str):
+        logger.warning("Ignoring retraction %r: not a version or range", entry)
+        return None
+    match = _RANGE.match(entry.strip())
+    try:
+        if match is None:
+            low = high = parse_version(entry.strip())
+        else:
+            low, high = parse_version(match["low"]), parse_version(match["high"])
+    except VersionError as exc:
+        logger.warning("Ignoring retraction %r: %s", entry, exc)
+        return None
+    rationale = rationale if isinstance(rationale, str) and rationale else None
+    return Retraction(min(low, high), max(low, high), rationale)
+
+
+def read_directives(base_dir: str | Path) -> ProjectDirectives:
+    """The replacements and retractions declared under ``base_dir``.
+
+    Replacements are listed in declaration order, ``[tool.uv.sources]``
+    first, one per distribution and target. A retracted documented version
+    is logged as a warning.
+    """
+    data = pyproject_data(base_dir)
+    replacements: dict[tuple[str, str], Replacement] = {}
+    found = _uv_sources(data)
+    found.extend(
+        replacement
+        for line in requirement_lines(base_dir)
+        if (replacement := _requirement_replacement(line)) is not None
+    )
+    for replacement in found:
+        replacements.setdefault(
+            (normalize(replacement.distribution), replacement.target),
+            replacement,
+        )
+    entries = data.get("tool", {}).get("autodoc", {}).get("retract", [])
+    retractions = [
+        retraction
+        for entry in (entries if isinstance(entries, list) else [entries])
+        if (retraction := _retraction(entry)) is not None
+    ]
+    version = data.get("project", {}).get("version")
+    directives = ProjectDirectives(
+        version=version if isinstance(version, str) else None,
+        replacements=tuple(replacements.values()),
+        retractions=tuple(retractions),
+    )
+    retracted = directives.retracted()
+    if retracted is not None:
+        logger.warning(
+            "Documenting retracted version %s%s",
+            directives.version,
+            f": {retracted.rationale}" if retracted.rationale else "",
+        )
+    return directives
+
+
+__all__ = [
+    "REPLACEMENT_KINDS",
+    "ProjectDirectives",
+    "Replacement",
+    "Retraction",
+    "read_directives",
+]
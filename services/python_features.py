@@ -0,0 +1,248 @@
+"""The minimum Python version of a project and the syntax that needs newer.
+
+A project states the oldest Python it supports in ``requires-python``
+(``>=3.9``); the interpreter it is developed with is often pinned in
+``.python-version``. Nothing checks that the code keeps to the former: a
+``match`` statement parses fine on the developer's 3.12 and is a syntax
+error for every 3.9 user.
+
+:func:`find_language_features` flags the syntax of :data:`LANGUAGE_FEATURES`
+with the version that introduced it, and a :class:`PythonVersionReport`
+compares them with the declared minimum. A module the running interpreter
+cannot parse is flagged as needing a newer Python than it. Only syntax is
+checked: a call of a function added to the standard library later is not a
+language feature.
+``autodoc syntax`` reports the newer features, and ``site.python_version``
+states the minimum on the index of the site, with the features that need
+more than it.
+"""
+
+from __future__ import annotations
+
+import ast
+import re
+import sys
+from collections.abc import Callable, Iterable
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.doc_external_links import pyproject_data
+from services.import_graph import ImportGraph, parse_modules, relative_path
+
+# Feature -> (the Python version that introduced it, what it is).
+LANGUAGE_FEATURES = {
+    "walrus": ((3, 8), "assignment expressions (:=)"),
+    "positional-only": ((3, 8), "positional-only parameters (/)"),
+    "decorator-expression": ((3, 9), "arbitrary decorator expressions"),
+    "match": ((3, 10), "match statements"),
+    "except-star": ((3, 11), "exception groups (except*)"),
+    "starred-subscript": ((3, 11), "starred expressions in subscripts"),
+    "type-alias": ((3, 12), "type statements"),
+    "type-parameters": ((3, 12), "type parameter lists"),
+    "template-string": ((3, 14), "template strings (t-strings)"),
+    # A module this interpreter cannot parse uses syntax of a newer one, or
+    # none at all; either way it does not run here.
+    "newer-python": (
+        (3, sys.version_info.minor + 1),
+        f"constructs Python {sys.version_info.major}.{sys.version_info.minor} "
+        "cannot parse",
+    ),
+}
+# Where the toolchain version is pinned, relative to the project directory.
+TOOLCHAIN_FILE = ".python-version"
+_REQUIRES = re.compile(r"(?:>=|~=|==)\s*3\.(\d+)")
+_TOOLCHAIN = re.compile(r"^(?:cpython-|python)?(3\.\d+)")
+
+
+def version_label(version: tuple[int, int]) -> str:
+    """``(3, 10)`` as ``3.10``."""
+    return ".".join(str(part) for part in version)
+
+
+@dataclass(frozen=True)
+class FeatureUsage:
+    """One use of a feature of :data:`LANGUAGE_FEATURES`."""
+
+    module: str
+    package: str
+    feature: str
+    file_path: str
+    lineno: int
+
+    @property
+    def version(self) -> tuple[int, int]:
+        return LANGUAGE_FEATURES[self.feature][0]
+
+    @property
+    def description(self) -> str:
+        return LANGUAGE_FEATURES[self.feature][1]
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "module": self.module,
+            "package": self.package,
+            "feature": self.feature,
+            "version": version_label(self.version),
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+        }
+
+
+@dataclass(frozen=True)
+class PythonVersionReport:
+    """The declared minimum Python and the features used above it."""
+
+    # Lower bound of ``requires-python``, or None if the project declares none.
+    minimum: tuple[int, int] | None
+    # The version of ``.python-version``, if pinned.
+    toolchain: str | None
+    usages: tuple[FeatureUsage, ...]
+
+    @property
+    def required(self) -> tuple[int, int] | None:
+        """The oldest Python that parses every module, if any feature needs one."""
+        return max((usage.version for usage in self.usages), default=None)
+
+    def sentence(self, code: Callable[[str], str]) -> str:
+        """State the minimum Python, with names formatted by ``code``."""
+        required = self.required
+        if self.minimum is None:
+            text = f"No minimum Python version is declared in {code('requires-python')}"
+            if required is not None:
+                text += f"; the code needs Python {version_label(required)} or newer"
+        else:
+            text = f"Requires Python {version_label(self.minimum)} or newer"
+        if self.toolchain is not None:
+            text += f" (developed with {self.toolchain}, see {code(TOOLCHAIN_FILE)})"
+        return text + "."
+
+    def newer(self) -> list[FeatureUsage]:
+        """The usages of features the declared minimum does not have."""
+        if self.minimum is None:
+            return []
+        return [usage for usage in self.usages if usage.version > self.minimum]
+
+    def to_dict(self) -> dict[str, object]:
+        required = self.required
+        return {
+            "minimum": version_label(self.minimum) if self.minimum else None,
+            "toolchain": self.toolchain,
+            "required": version_label(required) if required else None,
+            "newer": [usage.to_dict() for usage in self.newer()],
+        }
+
+
+def minimum_python(base_dir: str | Path) -> tuple[int, int] | None:
+    """The lower bound of ``requires-python`` in ``base_dir``'s pyproject."""
+    requires = pyproject_data(base_dir).get("project", {}).get("requires-python")
+    if not isinstance(requires, str):
+        return None
+    minors = [int(minor) for minor in _REQUIRES.findall(requires)]
+    return (3, min(minors)) if minors else None
+
+
+def toolchain_python(base_dir: str | Path) -> str | None:
+    """The ``3.X`` version pinned in ``.python-version``, if any."""
+    try:
+        text = (Path(base_dir) / TOOLCHAIN_FILE).read_text(encoding="utf-8")
+    except (OSError, UnicodeDecodeError):
+        return None
+    for line in text.splitlines():
+        match = _TOOLCHAIN.match(line.strip())
+        if match is not None:
+            return match.group(1)
+    return None
+
+
+def _plain_decorator(node: ast.expr) -> bool:
+    """Whether ``node`` is a dotted name, optionally called (the pre-3.9 form)."""
+    if isinstance(node, ast.Call):
+        node = node.func
+    while isinstance(node, ast.Attribute):
+        node = node.value
+    return isinstance(node, ast.Name)
+
+
+def _features(node: ast.AST) -> Iterable[str]:
+    if isinstance(node, ast.NamedExpr):
+        yield "walrus"
+    elif isinstance(node, ast.arguments) and node.posonlyargs:
+        yield "positional-only"
+    elif isinstance(node, ast.Match):
+        yield "match"
+    elif type(node).__name__ == "TryStar":
+        yield "except-star"
+    elif type(node).__name__ == "TypeAlias":
+        yield "type-alias"
+    elif type(node).__name__ == "TemplateStr":
+        yield "template-string"
+    elif isinstance(node, ast.Subscript):
+        elements = node.slice.elts if isinstance(node.slice, ast.Tuple) else []
+        if any(isinstance(element, ast.Starred) for element in elements):
+            yield "starred-subscript"
+    if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+        if any(not _plain_decorator(d) for d in node.decorator_list):
+            yield "decorator-expression"
+        if getattr(node, "type_params", None):
+            yield "type-parameters"
+
+
+def find_language_features(root: str | Path, graph: ImportGraph) -> list[FeatureUsage]:
+    """The uses of :data:`LANGUAGE_FEATURES` in ``graph``, by module and line.
+
+    Syntax newer than the running interpreter cannot be parsed; such modules
+    are reported as ``newer-python`` at the line of the syntax error.
+    """
+    usages = [
+        FeatureUsage(
+            module=module.module,
+            package=module.package,
+            feature="newer-python",
+            file_path=relative_path(module.file_path, root),
+            lineno=module.lineno,
+        )
+        for module in graph.unparsed
+    ]
+    for info, tree in parse_modules(graph, "language feature detection"):
+        for node in ast.walk(tree):
+            for feature in _features(node):
+                lineno = getattr(node, "lineno", None)
+                if lineno is None:
+                    # ``arguments`` have no position; use their first parameter.
+                    lineno = node.posonlyargs[0].lineno
+                usages.append(
+                    FeatureUsage(
+                        module=info.module,
+                        package=info.package,
+                        feature=feature,
+                        file_path=relative_path(info.file_path, root),
+                        lineno=lineno,
+                    ),
+                )
+    return sorted(usages, key=lambda u: (u.module, u.lineno, u.feature))
+
+
+def python_version_report(
+    base_dir: str | Path,
+    root: str | Path,
+    graph: ImportGraph,
+) -> PythonVersionReport:
+    """The report of the project configured in ``base_dir`` for the tree ``root``."""
+    return PythonVersionReport(
+        minimum=minimum_python(base_dir),
+        toolchain=toolchain_python(base_dir),
+        usages=tuple(find_language_features(root, graph)),
+    )
+
+
+__all__ = [
+    "LANGUAGE_FEATURES",
+    "TOOLCHAIN_FILE",
+    "FeatureUsage",
+    "PythonVersionReport",
+    "find_language_features",
+    "minimum_python",
+    "python_version_report",
+    "toolchain_python",
+    "version_label",
+]
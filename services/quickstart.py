@@ -0,0 +1,255 @@
+"""Minimal usage snippets synthesized from the API of a package.
+
+With ``site.quickstart``, each package page opens its reference with a
+snippet that imports the package's main class, constructs it, and calls its
+primary method::
+
+    from shop.cart import Cart
+
+    cart = Cart("...")
+    cart.total(0)
+
+The main class is the public class other packages use most (see
+:attr:`~services.doc_site.PackageDoc.most_used`), else the one with the
+most public methods; exceptions are never picked. It is constructed
+through a factory function of its module annotated to return it (``def
+open_cart(...) -> Cart``) if there is one, else by calling the class with
+the required parameters of its ``__init__``. The primary method is again
+the most used one, else the first. Required arguments get a placeholder
+value for their annotation (see :data:`PLACEHOLDERS`); parameters with
+defaults are left out.
+
+Every snippet is checked before it is shown (see :func:`verify_quickstart`):
+it must compile, and every name it uses must be one the package documents.
+"""
+
+from __future__ import annotations
+
+import ast
+import builtins
+import keyword
+import re
+from collections.abc import Iterable, Mapping, Sequence
+from dataclasses import dataclass
+
+from services.doc_symbols import DocSymbol
+
+# The head of an annotation -> the source of an argument of that type.
+PLACEHOLDERS = {
+    "str": '"..."',
+    "int": "0",
+    "float": "0.0",
+    "bool": "False",
+    "bytes": 'b""',
+    "list": "[]",
+    "List": "[]",
+    "Sequence": "[]",
+    "Iterable": "[]",
+    "dict": "{}",
+    "Dict": "{}",
+    "Mapping": "{}",
+    "set": "set()",
+    "Set": "set()",
+    "tuple": "()",
+    "Tuple": "()",
+    "Path": '"."',
+    "None": "None",
+    "Optional": "None",
+}
+# Placeholder of parameters without a known annotation.
+DEFAULT_PLACEHOLDER = "..."
+_ERROR_BASES = ("Exception", "Error", "Warning")
+_SKIPPED = {"self", "cls"}
+# ``X | None`` and ``None | X``.
+_OPTIONAL = re.compile(r"(?:^|\|)\s*None\s*(?:$|\|)")
+_CAMEL = re.compile(r"(?<=[a-z0-9])(?=[A-Z])|(?<=[A-Z])(?=[A-Z][a-z])")
+
+
+@dataclass(frozen=True)
+class Quickstart:
+    """The snippet of one package, and the symbols it uses."""
+
+    package: str
+    # Qualified names of the main class, what constructs it, and the method.
+    main_type: str
+    constructor: str
+    method: str | None
+    code: str
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "package": self.package,
+            "main_type": self.main_type,
+            "constructor": self.constructor,
+            "method": self.method,
+            "code": self.code,
+        }
+
+
+def placeholder(annotation: str | None) -> str:
+    """The argument source for a parameter annotated ``annotation``."""
+    if not annotation:
+        return DEFAULT_PLACEHOLDER
+    annotation = annotation.strip().strip("'\"")
+    if _OPTIONAL.search(annotation):
+        return "None"
+    head = annotation.split("[", 1)[0].rsplit(".", 1)[-1].strip()
+    return PLACEHOLDERS.get(head, DEFAULT_PLACEHOLDER)
+
+
+def _arguments(symbol: DocSymbol | None) -> str:
+    """The required arguments of a call of ``symbol``."""
+    if symbol is None:
+        return ""
+    arguments = []
+    for param in (symbol.metadata or {}).get("parameters", []):
+        if param["name"] in _SKIPPED or param.get("default") is not None:
+            continue
+        value = placeholder(param.get("annotation"))
+        if param.get("kind") == "keyword-only":
+            arguments.append(f"{param['name']}={value}")
+        elif param.get("kind") == "positional":
+            arguments.append(value)
+    return ", ".join(arguments)
+
+
+def _variable(name: str) -> str:
+    """``HTTPClient`` as ``http_client``; never a keyword or the class name."""
+    variable = _CAMEL.sub("_", name).lower()
+    if keyword.iskeyword(variable) or variable == name:
+        return f"{variable}_"
+    return variable
+
+
+def _is_error(cls: DocSymbol) -> bool:
+    bases = (cls.metadata or {}).get("base_classes") or []
+    return any(base.rsplit(".", 1)[-1].endswith(_ERROR_BASES) for base in bases)
+
+
+def _callable_methods(methods: Iterable[DocSymbol]) -> list[DocSymbol]:
+    return [
+        method
+        for method in methods
+        if not {"property", "staticmethod"} & set(
+            (method.metadata or {}).get("decorators") or [],
+        )
+    ]
+
+
+def _returns(symbol: DocSymbol) -> str:
+    return ((symbol.metadata or {}).get("return_type") or "").strip("'\"")
+
+
+def build_quickstart(
+    package: str,
+    symbols: Sequence[DocSymbol],
+    initializers: Mapping[str, DocSymbol],
+    used: Mapping[str, int] | None = None,
+) -> Quickstart | None:
+    """The snippet of ``package`` from its documented ``symbols``.
+
+    Args:
+        package: Name of the package
+        symbols: The package's documented symbols, in page order
+        initializers: Class qualified name -> its ``__init__`` method
+        used: Qualified name -> number of other packages using it
+    """
+    used = used or {}
+    methods: dict[str, list[DocSymbol]] = {}
+    for symbol in symbols:
+        if symbol.kind == "method" and not symbol.name.startswith("_"):
+            methods.setdefault(symbol.parent or "", []).append(symbol)
+    classes = [
+        (index, symbol)
+        for index, symbol in enumerate(symbols)
+        if symbol.kind == "class" and symbol.is_public and not _is_error(symbol)
+    ]
+    if not classes:
+        return None
+    _, main = min(
+        classes,
+        key=lambda item: (
+            -used.get(item[1].qualified_name, 0),
+            -len(methods.get(item[1].qualified_name, [])),
+            item[0],
+        ),
+    )
+    factory = next(
+        (
+            symbol
+            for symbol in symbols
+            if symbol.kind == "function"
+            and symbol.parent == main.parent
+            and _returns(symbol) == main.name
+        ),
+        None,
+    )
+    module = main.parent or ""
+    variable = _variable(main.name)
+    if factory is not None:
+        imports = f"from {module} import {factory.name}"
+        construct = f"{variable} = {factory.name}({_arguments(factory)})"
+    else:
+        imports = f"from {module} import {main.name}"
+        init = initializers.get(main.qualified_name)
+        construct = f"{variable} = {main.name}({_arguments(init)})"
+    lines = [imports, "", construct]
+    candidates = _callable_methods(methods.get(main.qualified_name, []))
+    method = None
+    if candidates:
+        method = min(
+            enumerate(candidates),
+            key=lambda item: (-used.get(item[1].qualified_name, 0), item[0]),
+        )[1]
+        call = f"{variable}.{method.name}({_arguments(method)})"
+        if (method.metadata or {}).get("is_async"):
+            lines = ["import asyncio", *lines, f"asyncio.run({call})"]
+        else:
+            lines.append(call)
+    return Quickstart(
+        package=package,
+        main_type=main.qualified_name,
+        constructor=(factory or main).qualified_name,
+        method=method.qualified_name if method else None,
+        code="\n".join(lines) + "\n",
+    )
+
+
+def verify_quickstart(quickstart: Quickstart, documented: Iterable[str]) -> list[str]:
+    """Why the snippet would not work; empty if it compiles and resolves.
+
+    The snippet must compile, import only documented names, and call only
+    documented methods of what it constructs.
+    """
+    try:
+        tree = ast.parse(quickstart.code)
+        compile(tree, f"<quickstart {quickstart.package}>", "exec")
+    except SyntaxError as exc:
+        return [f"does not compile: {exc.msg} (line {exc.lineno})"]
+    names = set(documented)
+    problems = []
+    imported: dict[str, str] = {}
+    for node in ast.walk(tree):
+        if isinstance(node, ast.ImportFrom):
+            for alias in node.names:
+                qualified = f"{node.module}.{alias.name}"
+                imported[alias.asname or alias.name] = qualified
+                if qualified not in names:
+                    problems.append(f"imports {qualified}, which is not documented")
+    if quickstart.method is not None and quickstart.method not in names:
+        problems.append(f"calls {quickstart.method}, which is not documented")
+    for node in ast.walk(tree):
+        if isinstance(node, ast.Call) and isinstance(node.func, ast.Name):
+            if node.func.id not in imported and not hasattr(builtins, node.func.id):
+                problems.append(f"calls {node.func.id}, which it does not import")
+    return problems
+
+
+__all__ = [
+    "DEFAULT_PLACEHOLDER",
+    "PLACEHOLDERS",
+    "Quickstart",
+    "build_quickstart",
+    "placeholder",
+    "verify_quickstart",
+]
@@ -0,0 +1,156 @@
+"""Redacting sensitive identifiers from published documentation.
+
+Some of a tree is not for everyone reading its docs: an internal package,
+the modules of a vendored SDK, a ticket prefix or an internal hostname in a
+docstring. ``site.redact`` in ``autodoc.yaml`` lists them::
+
+    site:
+      redact:
+        packages: [billing.internal]      # fnmatch; subpackages too
+        paths: ["*/secrets/*"]            # fnmatch, relative to the root
+        symbols: ["\\._?legacy_"]         # searched in qualified names
+        text: ["ACME-[0-9]+", "[\\w.-]+\\.corp\\.example\\.com"]
+        mode: exclude                     # or mask
+        mask: "[redacted]"
+
+With ``mode: exclude`` the symbols the first three select, and the symbols
+inside them, are left out of the docs as if they did not exist, and so are
+the imports of their modules on the pages built from the import graph.
+With ``mode: mask`` they stay listed with ``mask`` for a docstring. Matches
+of the ``text`` expressions are replaced with ``mask`` in every docstring,
+in the FAQ and troubleshooting entries (:mod:`services.faq`, which include
+comments), and in the READMEs.
+
+``autodoc generate`` and portals (:mod:`services.doc_portal`) redact what
+they publish: :func:`redact_tree` before modelling the tree, then
+:func:`redact_packages`. ``autodoc generate --internal`` builds the
+unredacted docs for internal readers.
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Iterable
+from dataclasses import replace
+from fnmatch import fnmatchcase
+from pathlib import Path
+
+from autodoc.config.project import RedactConfig
+from autodoc.parser import ParsedTree
+from services.doc_site import PackageDoc
+from services.doc_symbols import DocSymbol, relative_path
+
+
+class Redactor:
+    """Applies one ``site.redact`` section."""
+
+    def __init__(self, config: RedactConfig) -> None:
+        self.config = config
+        self.symbols = [re.compile(expression) for expression in config.symbols]
+        self.text = (
+            re.compile("|".join(f"(?:{expression})" for expression in config.text))
+            if config.text
+            else None
+        )
+
+    @property
+    def active(self) -> bool:
+        """Whether the section redacts anything."""
+        config = self.config
+        return bool(config.packages or config.paths or config.symbols or config.text)
+
+    def selects(self, symbol: DocSymbol, root: str | Path) -> bool:
+        """Whether the package, path, or name of ``symbol`` is redacted.
+
+        Symbols inside a selected one are not selected by this alone; see
+        :func:`redact_tree`.
+        """
+        parts = symbol.package.split(".")
+        packages = [".".join(parts[:end]) for end in range(1, len(parts) + 1)]
+        if any(
+            fnmatchcase(package, pattern)
+            for package in packages
+            for pattern in self.config.packages
+        ):
+            return True
+        path = relative_path(symbol.file_path, root)
+        if any(fnmatchcase(path, pattern) for pattern in self.config.paths):
+            return True
+        return any(pattern.search(symbol.qualified_name) for pattern in self.symbols)
+
+    def mask(self, text: str | None) -> str | None:
+        """``text`` with the matches of the ``text`` expressions masked."""
+        if not text or self.text is None:
+            return text
+        return self.text.sub(self.config.mask, text)
+
+
+def _inside(name: str, selected: set[str]) -> bool:
+    parts = name.split(".")
+    return any(".".join(parts[:end]) in selected for end in range(1, len(parts) + 1))
+
+
+def redact_tree(tree: ParsedTree, config: RedactConfig) -> ParsedTree:
+    """``tree`` with its symbols and docstrings redacted as ``config`` says."""
+    redactor = Redactor(config)
+    if not redactor.active:
+        return tree
+    selected = {
+        symbol.qualified_name
+        for symbol in tree.symbols
+        if redactor.selects(symbol, tree.root)
+    }
+    exclude = config.mode == "exclude"
+    symbols: list[DocSymbol] = []
+    for symbol in tree.symbols:
+        if not _inside(symbol.qualified_name, selected):
+            docstring = redactor.mask(symbol.docstring)
+        elif exclude:
+            continue
+        else:
+            docstring = config.mask
+        if docstring != symbol.docstring:
+            symbol = symbol.with_docstring(docstring)
+        symbols.append(symbol)
+    graph = tree.graph
+    if exclude and selected:
+        dropped = {name for name in graph.modules if _inside(name, selected)}
+        graph = graph.without(dropped)
+    return ParsedTree(tree.root, symbols, graph)
+
+
+def redact_packages(
+    packages: Iterable[PackageDoc],
+    config: RedactConfig,
+) -> list[PackageDoc]:
+    """``packages`` with the ``text`` expressions masked in FAQs and READMEs.
+
+    The docstrings are redacted by :func:`redact_tree`; this masks what the
+    model reads from the tree's files besides.
+    """
+    redactor = Redactor(config)
+    packages = list(packages)
+    if redactor.text is None:
+        return packages
+    result = []
+    for package in packages:
+        faq = [
+            replace(
+                entry,
+                question=redactor.mask(entry.question) or "",
+                answer=redactor.mask(entry.answer) or "",
+            )
+            for entry in package.faq
+        ]
+        readme = package.readme
+        if readme is not None:
+            readme = replace(readme, content=redactor.mask(readme.content) or "")
+        result.append(replace(package, faq=faq, readme=readme))
+    return result
+
+
+__all__ = [
+    "Redactor",
+    "redact_packages",
+    "redact_tree",
+]
@@ -0,0 +1,273 @@
+"""Relations between the symbols of a source tree.
+
+:func:`build_relation_graph` turns the documentation symbols and the import
+graph into one graph for architecture tooling. Nodes are the modules,
+classes, functions, and methods of the tree; edges are typed:
+
+- ``imports``: a module imports another module of the tree;
+- ``calls``: a function or method (or module-level code) calls a function or
+  method; ``self.method()`` resolves to the enclosing class;
+- ``constructs``: the same, when the callee is a class;
+- ``inherits``: a class derives from another class;
+- ``implements``: a class derives from an interface, a class declaring
+  ``typing.Protocol`` or ``abc.ABC`` as a base (see
+  :mod:`services.mock_links`);
+- ``embeds``: a class-level field is annotated with another class
+  (``cart: Cart`` in a dataclass or model), Python's form of composition.
+
+Only edges between symbols of the tree are kept; calls into the standard
+library or third-party packages are dropped. Name resolution is static, as in
+:mod:`services.import_graph`: calls through variables, ``getattr`` and other
+dynamic dispatch are not seen.
+"""
+
+from __future__ import annotations
+
+import ast
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from services.doc_symbols import DocSymbol
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    dotted_parts,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+from services.mock_links import INTERFACE_BASES, INTERFACE_METACLASSES
+
+CALLS = "calls"
+CONSTRUCTS = "constructs"
+EMBEDS = "embeds"
+IMPLEMENTS = "implements"
+IMPORTS = "imports"
+INHERITS = "inherits"
+RELATION_KINDS = (CALLS, CONSTRUCTS, EMBEDS, IMPLEMENTS, IMPORTS, INHERITS)
+
+# First parameter names whose attribute calls resolve to the enclosing class.
+SELF_NAMES = frozenset({"self", "cls"})
+
+
+@dataclass(frozen=True)
+class RelationNode:
+    """A module, class, function, or method."""
+
+    id: str
+    kind: str
+    package: str
+    file_path: str
+    lineno: int
+    is_public: bool
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "id": self.id,
+            "kind": self.kind,
+            "package": self.package,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "is_public": self.is_public,
+        }
+
+
+@dataclass(frozen=True)
+class Relation:
+    """``source`` relates to ``target``; the line is the first occurrence."""
+
+    source: str
+    target: str
+    kind: str
+    file_path: str
+    lineno: int
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "source": self.source,
+            "target": self.target,
+            "kind": self.kind,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+        }
+
+
+@dataclass
+class RelationGraph:
+    """Nodes sorted by id; edges sorted by source, kind, and target."""
+
+    nodes: list[RelationNode] = field(default_factory=list)
+    edges: list[Relation] = field(default_factory=list)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "nodes": [node.to_dict() for node in self.nodes],
+            "edges": [edge.to_dict() for edge in self.edges],
+        }
+
+
+def _annotation_names(node: ast.expr) -> list[ast.expr]:
+    """Every dotted name in an annotation (``list[Cart] | None`` -> ``Cart``)."""
+    if isinstance(node, ast.Constant) and isinstance(node.value, str):
+        try:
+            node = ast.parse(node.value, mode="eval").body
+        except SyntaxError:
+            return []
+    return [
+        sub
+        for sub in ast.walk(node)
+        if isinstance(sub, (ast.Name, ast.Attribute)) and dotted_parts(sub)
+    ]
+
+
+class _ModuleScanner:
+    def __init__(
+        self,
+        tree: ast.Module,
+        info: ModuleImports,
+        file_path: str,
+    ) -> None:
+        self.tree = tree
+        self.info = info
+        self.file_path = file_path
+        self.local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        }
+        # (source, resolved target, kind hint, line)
+        self.found: list[tuple[str, str, str, int]] = []
+        self.interfaces: set[str] = set()
+
+    def resolve(self, node: ast.expr) -> str:
+        return resolve_name(node, self.info, self.local_names)
+
+    def scan(self) -> None:
+        module = self.info.module
+        for node in self.tree.body:
+            if isinstance(node, ast.ClassDef):
+                self._class(node, f"{module}.{node.name}")
+            elif isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                self._calls(node, f"{module}.{node.name}")
+            else:
+                self._calls(node, module)
+
+    def _class(self, node: ast.ClassDef, qualname: str) -> None:
+        bases = tuple(
+            self.resolve(base.value if isinstance(base, ast.Subscript) else base)
+            for base in node.bases
+        )
+        metaclass = next(
+            (self.resolve(k.value) for k in node.keywords if k.arg == "metaclass"),
+            None,
+        )
+        if INTERFACE_BASES.intersection(bases) or metaclass in INTERFACE_METACLASSES:
+            self.interfaces.add(qualname)
+        for base in bases:
+            self.found.append((qualname, base, INHERITS, node.lineno))
+        for item in node.body:
+            if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                self._calls(item, f"{qualname}.{item.name}", qualname)
+            elif isinstance(item, ast.AnnAssign):
+                for name in _annotation_names(item.annotation):
+                    target = self.resolve(name)
+                    self.found.append((qualname, target, EMBEDS, item.lineno))
+                if item.value is not None:
+                    self._calls(item.value, qualname, qualname)
+            else:
+                self._calls(item, qualname, qualname)
+
+    def _callee(self, call: ast.Call, owner: str | None) -> str:
+        parts = dotted_parts(call.func)
+        if owner is not None and parts and len(parts) == 2 and parts[0] in SELF_NAMES:
+            return f"{owner}.{parts[1]}"
+        return self.resolve(call.func)
+
+    def _calls(self, node: ast.AST, source: str, owner: str | None = None) -> None:
+        for sub in ast.walk(node):
+            if isinstance(sub, ast.Call):
+                target = self._callee(sub, owner)
+                self.found.append((source, target, CALLS, sub.lineno))
+
+
+def _edge_kind(
+    hint: str,
+    target: str,
+    kinds: dict[str, str],
+    interfaces: set[str],
+) -> str:
+    if hint == CALLS and kinds[target] == "class":
+        return CONSTRUCTS
+    if hint == INHERITS and target in interfaces:
+        return IMPLEMENTS
+    return hint
+
+
+def build_relation_graph(
+    root: str | Path,
+    graph: ImportGraph,
+    symbols: Iterable[DocSymbol],
+) -> RelationGraph:
+    """Build the relation graph of the tree ``graph`` and ``symbols`` describe."""
+    nodes = {
+        symbol.qualified_name: RelationNode(
+            id=symbol.qualified_name,
+            kind=symbol.kind,
+            package=symbol.package,
+            file_path=relative_path(symbol.file_path, root),
+            lineno=symbol.lineno,
+            is_public=symbol.is_public,
+        )
+        for symbol in symbols
+    }
+    kinds = {name: node.kind for name, node in nodes.items()}
+    edges: dict[tuple[str, str, str], Relation] = {}
+
+    def add(source: str, target: str, kind: str, file_path: str, lineno: int) -> None:
+        key = (source, kind, target)
+        if source == target or source not in nodes or key in edges:
+            return
+        edges[key] = Relation(source, target, kind, file_path, lineno)
+
+    for name in sorted(graph.modules):
+        info = graph.modules[name]
+        for target, lineno in sorted(info.imports.items(), key=lambda item: item[1]):
+            module = graph.internal_module(target)
+            if module is not None and module in nodes:
+                add(name, module, IMPORTS, relative_path(info.file_path, root), lineno)
+
+    scanners = []
+    for info, tree in parse_modules(graph, "relation analysis"):
+        scanner = _ModuleScanner(tree, info, relative_path(info.file_path, root))
+        scanner.scan()
+        scanners.append(scanner)
+    interfaces = {name for scanner in scanners for name in scanner.interfaces}
+    for scanner in scanners:
+        for source, target, hint, lineno in scanner.found:
+            if target not in kinds:
+                continue
+            if hint in (INHERITS, EMBEDS) and kinds[target] != "class":
+                continue
+            kind = _edge_kind(hint, target, kinds, interfaces)
+            add(source, target, kind, scanner.file_path, lineno)
+
+    return RelationGraph(
+        nodes=[nodes[name] for name in sorted(nodes)],
+        edges=[edges[key] for key in sorted(edges)],
+    )
+
+
+__all__ = [
+    "CALLS",
+    "CONSTRUCTS",
+    "EMBEDS",
+    "IMPLEMENTS",
+    "IMPORTS",
+    "INHERITS",
+    "RELATION_KINDS",
+    "Relation",
+    "RelationGraph",
+    "RelationNode",
+    "build_relation_graph",
+]
@@ -0,0 +1,139 @@
+"""Release-note fragments kept in docstrings, for ``autodoc relnotes``.
+
+A pull request that changes a symbol records what the release notes should
+say about it in the symbol's docstring, next to the code it describes::
+
+    def total(items, tax=0):
+        \"\"\"Sum of the items plus ``tax``.
+
+        @autodoc:relnote feature: ``total`` takes a ``tax`` rate.
+        \"\"\"
+
+The kind before the colon is one of :data:`RELNOTE_KINDS`; without one, or
+with another word, the note is filed under "Other changes". A note runs on
+over the following lines up to a blank line or the next directive.
+
+At tag time, :func:`new_release_notes` compares the notes of the tree at
+the last release with the current ones, so a fragment is published once,
+in the release that added it; :func:`render_release_notes` compiles them
+into Markdown grouped by kind, each note naming its symbol. Fragments are
+stripped from the documentation site (:func:`strip_release_notes`).
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Iterable
+from dataclasses import dataclass
+
+from services.doc_symbols import DocSymbol
+
+# Kinds of notes, in the order of the release notes, with their headings.
+RELNOTE_KINDS = {
+    "breaking": "Breaking changes",
+    "feature": "Features",
+    "deprecation": "Deprecations",
+    "fix": "Fixes",
+    "other": "Other changes",
+}
+DEFAULT_TITLE = "Release notes"
+_DIRECTIVE = re.compile(
+    r"^\s*@autodoc:relnote(?:\s+(?P<kind>[A-Za-z]+):)?(?:\s+(?P<text>.*))?$",
+)
+
+
+@dataclass(frozen=True)
+class ReleaseNote:
+    """One fragment: what a release changed about ``symbol``."""
+
+    symbol: str
+    kind: str
+    text: str
+
+    def to_dict(self) -> dict[str, str]:
+        return {"symbol": self.symbol, "kind": self.kind, "text": self.text}
+
+
+def _split(docstring: str) -> tuple[list[tuple[str, str]], list[str]]:
+    """The ``(kind, text)`` fragments of ``docstring`` and its other lines."""
+    notes: list[tuple[str, list[str]]] = []
+    rest: list[str] = []
+    current: list[str] | None = None
+    for line in docstring.split("\n"):
+        match = _DIRECTIVE.match(line)
+        if match is not None:
+            kind = (match["kind"] or "other").lower()
+            current = [match["text"] or ""]
+            notes.append((kind if kind in RELNOTE_KINDS else "other", current))
+        elif current is not None and line.strip():
+            current.append(line.strip())
+        else:
+            current = None
+            rest.append(line)
+    fragments = [(kind, " ".join(filter(None, text))) for kind, text in notes]
+    return [(kind, text) for kind, text in fragments if text], rest
+
+
+def parse_release_notes(docstring: str | None) -> list[tuple[str, str]]:
+    """The ``(kind, text)`` of each fragment of ``docstring``, in order."""
+    return _split(docstring)[0] if docstring else []
+
+
+def strip_release_notes(docstring: str | None) -> str | None:
+    """``docstring`` without its fragments; ``None`` if nothing else is left."""
+    if not docstring or "@autodoc:relnote" not in docstring:
+        return docstring
+    text = re.sub(r"\n{3,}", "\n\n", "\n".join(_split(docstring)[1])).strip()
+    return text or None
+
+
+def collect_release_notes(symbols: Iterable[DocSymbol]) -> list[ReleaseNote]:
+    """The fragments of the docstrings of ``symbols``."""
+    return [
+        ReleaseNote(symbol.qualified_name, kind, text)
+        for symbol in symbols
+        for kind, text in parse_release_notes(symbol.docstring)
+    ]
+
+
+def new_release_notes(
+    before: Iterable[ReleaseNote],
+    after: Iterable[ReleaseNote],
+) -> list[ReleaseNote]:
+    """The notes of ``after`` that ``before`` does not have, by symbol."""
+    old = set(before)
+    return sorted(
+        {note for note in after if note not in old},
+        key=lambda note: (list(RELNOTE_KINDS).index(note.kind), note.symbol),
+    )
+
+
+def render_release_notes(
+    notes: Iterable[ReleaseNote],
+    title: str = DEFAULT_TITLE,
+) -> str:
+    """Markdown release notes: one section per kind, in :data:`RELNOTE_KINDS`."""
+    notes = list(notes)
+    lines = [f"# {title}", ""]
+    if not notes:
+        lines += ["No release notes.", ""]
+    for kind, heading in RELNOTE_KINDS.items():
+        entries = [note for note in notes if note.kind == kind]
+        if not entries:
+            continue
+        lines += [f"## {heading}", ""]
+        lines += [f"- {note.text} (`{note.symbol}`)" for note in entries]
+        lines.append("")
+    return "\n".join(lines)
+
+
+__all__ = [
+    "DEFAULT_TITLE",
+    "RELNOTE_KINDS",
+    "ReleaseNote",
+    "collect_release_notes",
+    "new_release_notes",
+    "parse_release_notes",
+    "render_release_notes",
+    "strip_release_notes",
+]
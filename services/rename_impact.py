@@ -0,0 +1,484 @@
+"""What a symbol rename breaks in the documentation, for ``autodoc rename``.
+
+Renaming ``shop.cart.Cart`` to ``Basket`` in code leaves the prose about it
+behind. :func:`find_rename_impact` reports each place that would be left
+naming the old symbol, and can rewrite them:
+
+- ``reference``: a docstring role or bracketed name (``:class:`~shop.cart.Cart```,
+  ``[Cart.total]``) that resolves to the symbol or one of its members;
+- ``code``: a name in docstring code, that is inline literals, doctests, and
+  ``::`` blocks (``>>> Cart().total()``);
+- ``document``: a name in the code of a Markdown or reST file below the
+  root, such as a README example. Markdown code is fenced blocks and inline
+  code; reST files are read the way docstrings are, roles included.
+
+Names resolve through :meth:`~services.doc_links.SymbolIndex.resolve`, so a
+bare ``Cart`` only counts where it cannot mean anything else. Only names that
+spell out the old name are rewritten: a reference through an import alias
+(``from shop.cart import Cart as C``) still works after the rename and is left
+alone. Rewrites touch docstring literals and document text only; renaming the
+symbol in code is left to the editor or refactoring tool.
+"""
+
+from __future__ import annotations
+
+import difflib
+import os
+import re
+from collections.abc import Iterable, Sequence
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+from services.doc_fix import DocFixError, docstring_literals, rewrite_literals
+from services.doc_links import SymbolIndex, find_references
+from services.doc_symbols import (
+    DEFAULT_EXCLUDED_DIRS,
+    DocSymbol,
+    module_name_for,
+)
+from services.ignore_file import load_ignore_file
+
+REFERENCE = "reference"
+CODE = "code"
+DOCUMENT = "document"
+IMPACT_KINDS = (REFERENCE, CODE, DOCUMENT)
+
+# Files searched for code naming the symbol, besides the Python sources.
+DOCUMENT_SUFFIXES = (".md", ".rst")
+
+_IDENTIFIER = re.compile(r"[A-Za-z_]\w*")
+_DOTTED = re.compile(r"(?<![\w.])[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*")
+_LITERAL = re.compile(r"``[^`\n]+``|`[^`\n]+`")
+_ROLE = re.compile(r":[\w:-]+:`[^`\n]*`")
+_FENCE = re.compile(r"^\s*(```|~~~)")
+
+
+class RenameError(Exception):
+    """Raised when a rename cannot be planned."""
+
+
+@dataclass(frozen=True)
+class RenameImpact:
+    """One place in the documentation that names the renamed symbol."""
+
+    kind: str
+    file_path: str
+    lineno: int
+    symbol: str
+    text: str
+    replacement: str
+
+    def format(self) -> str:
+        where = f" in {self.symbol}" if self.symbol else ""
+        return (
+            f"{self.file_path}:{self.lineno}: [{self.kind}] {self.text} -> "
+            f"{self.replacement}{where}"
+        )
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "kind": self.kind,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "symbol": self.symbol,
+            "text": self.text,
+            "replacement": self.replacement,
+        }
+
+
+@dataclass
+class RenamedFile:
+    """The text of one file before and after its references are rewritten."""
+
+    path: str
+    original: str
+    renamed: str
+
+    def diff(self) -> str:
+        """A unified diff from the original to the rewritten text."""
+        return "".join(
+            difflib.unified_diff(
+                self.original.splitlines(keepends=True),
+                self.renamed.splitlines(keepends=True),
+                f"a/{self.path}",
+                f"b/{self.path}",
+            ),
+        )
+
+
+@dataclass
+class RenameReport:
+    """Everything a rename of ``old`` to ``new`` would leave behind."""
+
+    old: str
+    new: str
+    impacts: list[RenameImpact] = field(default_factory=list)
+    files: list[RenamedFile] = field(default_factory=list)
+    errors: list[str] = field(default_factory=list)
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "old": self.old,
+            "new": self.new,
+            "impacts": [impact.to_dict() for impact in self.impacts],
+            "files": [renamed.path for renamed in self.files],
+            "errors": list(self.errors),
+        }
+
+
+def plan_rename(old: str, new: str, index: SymbolIndex) -> str:
+    """The qualified name ``old`` is renamed to.
+
+    ``new`` is the new name of the symbol, bare (``Basket``) or qualified
+    with the same parent (``shop.cart.Basket``).
+
+    Raises:
+        RenameError: If ``old`` is not defined, ``new`` is not a name or moves
+            the symbol, or the new name is taken
+    """
+    if old not in index.names:
+        raise RenameError(f"{old} is not defined in the tree")
+    parent, _, _ = old.rpartition(".")
+    new_parent, _, name = new.rpartition(".")
+    if not _IDENTIFIER.fullmatch(name):
+        raise RenameError(f"{new!r} is not a valid name")
+    if new_parent and new_parent != parent:
+        raise RenameError(
+            f"renaming {old} to {new} moves it; only the last name can change",
+        )
+    qualified = f"{parent}.{name}" if parent else name
+    if qualified == old:
+        raise RenameError(f"{old} already has that name")
+    if qualified in index.names:
+        raise RenameError(f"{qualified} is already defined")
+    return qualified
+
+
+class _Renamer:
+    """Finds and renames the names of ``old`` in one file's text."""
+
+    def __init__(self, index: SymbolIndex, old: str, new: str) -> None:
+        self.index = index
+        self.old = old
+        self.depth = old.count(".") + 1
+        self.old_name = old.rsplit(".", 1)[-1]
+        self.new_name = new.rsplit(".", 1)[-1]
+
+    def rename(self, written: str, resolved: str | None) -> str | None:
+        """``written``, which resolved to ``resolved``, with the old name replaced.
+
+        None when it does not name the symbol, or names it through an alias.
+        """
+        if resolved is None or (
+            resolved != self.old and not resolved.startswith(self.old + ".")
+        ):
+            return None
+        parts = written.split(".")
+        # Written names are tails of the resolved one: align them from the end.
+        position = self.depth - 1 - (resolved.count(".") + 1 - len(parts))
+        if not 0 <= position < len(parts) or parts[position] != self.old_name:
+            return None
+        parts[position] = self.new_name
+        return ".".join(parts)
+
+    def _code_name(self, dotted: str, context: DocSymbol) -> tuple[str, str] | None:
+        """The prefix of ``dotted`` naming the symbol, and its new spelling.
+
+        Trailing parts that resolve to nothing are attributes
+        (``Cart.items``), so shorter prefixes are tried until one resolves.
+        """
+        parts = dotted.split(".")
+        for end in range(len(parts), 0, -1):
+            written = ".".join(parts[:end])
+            resolved = self.index.resolve(written, context)
+            if resolved is not None:
+                renamed = self.rename(written, resolved)
+                return None if renamed is None else (written, renamed)
+        return None
+
+    def in_code(
+        self,
+        text: str,
+        spans: Iterable[tuple[int, int]],
+        context: DocSymbol,
+    ) -> list[tuple[int, int, str]]:
+        edits = []
+        for start, end in spans:
+            for match in _DOTTED.finditer(text, start, end):
+                found = self._code_name(match.group(), context)
+                if found is not None:
+                    written, renamed = found
+                    end = match.start() + len(written)
+                    edits.append((match.start(), end, renamed))
+        return edits
+
+    def in_references(
+        self,
+        text: str,
+        context: DocSymbol,
+    ) -> list[tuple[int, int, str]]:
+        edits = []
+        for reference in find_references(text):
+            resolved = self.index.resolve(reference.target, context)
+            if resolved is None and reference.role and "." in reference.target:
+                # An attribute or constant: resolve its owner.
+                owner, _, attribute = reference.target.rpartition(".")
+                resolved = self.index.resolve(owner, context)
+                resolved = f"{resolved}.{attribute}" if resolved else None
+            renamed = self.rename(reference.target, resolved)
+            if renamed is not None:
+                edits.append((reference.start, reference.end, renamed))
+        return edits
+
+
+def _indent(line: str) -> int:
+    return len(line) - len(line.lstrip())
+
+
+def docstring_code(text: str) -> list[tuple[int, int]]:
+    """Spans of the code in reST text: inline literals, doctests, ``::`` blocks."""
+    spans = []
+    offset = 0
+    block_indent: int | None = None
+    for line in text.splitlines(keepends=True):
+        whole = (offset, offset + len(line))
+        stripped = line.strip()
+        if block_indent is not None:
+            if not stripped or _indent(line) > block_indent:
+                spans.append(whole)
+                offset += len(line)
+                continue
+            block_indent = None
+        if stripped.startswith((">>>", "...")):
+            spans.append(whole)
+        else:
+            # Roles are references, not code.
+            unroled = _ROLE.sub(lambda match: " " * len(match.group()), line)
+            spans.extend(
+                (offset + match.start(), offset + match.end())
+                for match in _LITERAL.finditer(unroled)
+            )
+            if stripped.endswith("::"):
+                block_indent = _indent(line)
+        offset += len(line)
+    return spans
+
+
+def markdown_code(text: str) -> list[tuple[int, int]]:
+    """Spans of the code in Markdown text: fenced blocks and inline code."""
+    spans = []
+    offset = 0
+    fence: str | None = None
+    for line in text.splitlines(keepends=True):
+        match = _FENCE.match(line)
+        if fence is not None:
+            if match and match.group(1) == fence:
+                fence = None
+            else:
+                spans.append((offset, offset + len(line)))
+        elif match:
+            fence = match.group(1)
+        else:
+            spans.extend(
+                (offset + literal.start(), offset + literal.end())
+                for literal in _LITERAL.finditer(line)
+            )
+        offset += len(line)
+    return spans
+
+
+def _merge(
+    references: list[tuple[int, int, str]],
+    code: list[tuple[int, int, str]],
+) -> list[tuple[int, int, str, str]]:
+    """Both kinds of edits in text order; code inside a reference is dropped."""
+    found = [(start, end, REFERENCE, text) for start, end, text in references]
+    for start, end, text in code:
+        if not any(start < other[1] and other[0] < end for other in found):
+            found.append((start, end, CODE, text))
+    return sorted(found)
+
+
+def _apply(text: str, edits: Iterable[tuple[int, int, str]]) -> str:
+    for start, end, replacement in sorted(edits, reverse=True):
+        text = text[:start] + replacement + text[end:]
+    return text
+
+
+def discover_documents(
+    root: str | Path,
+    excluded_dirs: Iterable[str] = DEFAULT_EXCLUDED_DIRS,
+) -> list[Path]:
+    """The Markdown and reST files below ``root``, sorted.
+
+    Hidden and excluded directories are skipped, as are paths the root's
+    ``.autodocignore`` ignores.
+    """
+    root_path = Path(root)
+    if root_path.is_file():
+        return [root_path] if root_path.suffix in DOCUMENT_SUFFIXES else []
+    excluded = set(excluded_dirs)
+    ignore = load_ignore_file(root_path)
+    documents = []
+    for dirpath, dirnames, filenames in os.walk(root_path):
+        relative_dir = Path(dirpath).relative_to(root_path)
+        dirnames[:] = [
+            name
+            for name in sorted(dirnames)
+            if name not in excluded
+            and not name.startswith(".")
+            and not ignore.ignores((relative_dir / name).as_posix(), is_dir=True)
+        ]
+        for name in filenames:
+            relative = (relative_dir / name).as_posix()
+            if Path(name).suffix not in DOCUMENT_SUFFIXES:
+                continue
+            if ignore.ignores(relative):
+                continue
+            documents.append(Path(dirpath) / name)
+    return sorted(documents)
+
+
+def _context(qualified_name: str, file_path: str, lineno: int) -> DocSymbol:
+    return DocSymbol(
+        package="",
+        name=qualified_name.rsplit(".", 1)[-1],
+        qualified_name=qualified_name,
+        kind="",
+        file_path=file_path,
+        lineno=lineno,
+    )
+
+
+def _line_of(text: str, offset: int, first: int = 1) -> int:
+    return first + text.count("\n", 0, offset)
+
+
+def find_rename_impact(
+    root: str | Path,
+    old: str,
+    new: str,
+    index: SymbolIndex,
+    files: Sequence[str | Path],
+    documents: Sequence[str | Path] = (),
+) -> RenameReport:
+    """Report the docstrings and documents of ``root`` naming ``old``.
+
+    Args:
+        root: Tree the paths are reported relative to
+        old: Qualified name of the symbol being renamed
+        new: Its new name (see :func:`plan_rename`)
+        index: The symbols of the tree
+        files: Python files whose docstrings are searched
+        documents: Markdown and reST files whose code is searched
+
+    Returns:
+        The impacts in file order, and each affected file as it reads once
+        they are renamed; files that cannot be read or parsed are listed in
+        ``errors``
+
+    Raises:
+        RenameError: If the rename is not possible
+    """
+    qualified = plan_rename(old, new, index)
+    renamer = _Renamer(index, old, qualified)
+    report = RenameReport(old, qualified)
+    root_path = Path(root)
+    base = root_path if root_path.is_dir() else root_path.parent
+
+    for path in files:
+        relative = Path(path).relative_to(base).as_posix()
+        try:
+            source = Path(path).read_bytes().decode("utf-8")
+            literals = docstring_literals(source, relative)
+        except (OSError, UnicodeDecodeError, DocFixError) as exc:
+            report.errors.append(str(exc))
+            continue
+        module = module_name_for(relative, base)
+        edits = []
+        for literal in literals:
+            name = module
+            if literal.symbol != "<module>":
+                name = f"{module}.{literal.symbol}"
+            context = _context(name, relative, literal.lineno)
+            inner = literal.inner
+            found = _merge(
+                renamer.in_references(inner, context),
+                renamer.in_code(inner, docstring_code(inner), context),
+            )
+            for start, end, kind, replacement in found:
+                report.impacts.append(
+                    RenameImpact(
+                        kind,
+                        relative,
+                        _line_of(inner, start, literal.lineno),
+                        name,
+                        inner[start:end],
+                        replacement,
+                    ),
+                )
+            if found:
+                renamed = _apply(inner, [(f[0], f[1], f[3]) for f in found])
+                edits.append((literal, renamed))
+        if edits:
+            try:
+                renamed = rewrite_literals(source, edits, relative)
+            except DocFixError as exc:
+                report.errors.append(str(exc))
+                continue
+            report.files.append(RenamedFile(relative, source, renamed))
+
+    context = _context("", "", 0)
+    for path in documents:
+        relative = Path(path).relative_to(base).as_posix()
+        try:
+            text = Path(path).read_bytes().decode("utf-8")
+        except (OSError, UnicodeDecodeError) as exc:
+            report.errors.append(f"{relative}: {exc}")
+            continue
+        code = docstring_code(text)
+        references = []
+        if Path(path).suffix == ".md":
+            code = markdown_code(text)
+        else:
+            references = renamer.in_references(text, context)
+        found = [
+            (start, end, replacement)
+            for start, end, _, replacement in _merge(
+                references,
+                renamer.in_code(text, code, context),
+            )
+        ]
+        for start, end, replacement in found:
+            report.impacts.append(
+                RenameImpact(
+                    DOCUMENT,
+                    relative,
+                    _line_of(text, start),
+                    "",
+                    text[start:end],
+                    replacement,
+                ),
+            )
+        if found:
+            report.files.append(RenamedFile(relative, text, _apply(text, found)))
+    return report
+
+
+__all__ = [
+    "CODE",
+    "DOCUMENT",
+    "DOCUMENT_SUFFIXES",
+    "IMPACT_KINDS",
+    "REFERENCE",
+    "RenameError",
+    "RenameImpact",
+    "RenameReport",
+    "RenamedFile",
+    "discover_documents",
+    "docstring_code",
+    "find_rename_impact",
+    "markdown_code",
+    "plan_rename",
+]
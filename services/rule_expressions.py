@@ -0,0 +1,206 @@
+"""Safe boolean expressions over the doc symbol model.
+
+Custom lint rules in ``autodoc.yaml`` are written as small Python-syntax
+expressions such as ``exported and kind == "function" and raises``. They are
+parsed with :mod:`ast` and checked against a whitelist of node types before
+evaluation, so a config file can express conditions but never run code:
+attribute access, lambdas, comprehensions, and arbitrary calls are rejected.
+
+Names resolve to the fields returned by :func:`symbol_fields`; the only
+callable names are the helpers in :data:`FUNCTIONS`.
+"""
+
+from __future__ import annotations
+
+import ast
+import operator
+import re
+from collections.abc import Callable
+from dataclasses import dataclass
+from functools import lru_cache
+from typing import Any
+
+from services.doc_symbols import DocSymbol, is_exported
+
+
+class ExpressionError(Exception):
+    """Raised when a rule expression is invalid or fails to evaluate."""
+
+
+@lru_cache(maxsize=256)
+def _compiled_pattern(pattern: str) -> re.Pattern[str]:
+    try:
+        return re.compile(pattern)
+    except re.error as exc:
+        raise ExpressionError(f"Invalid regular expression {pattern!r}: {exc}") from exc
+
+
+def _matches(value: Any, pattern: str) -> bool:
+    """``re.search`` semantics; ``None`` never matches."""
+    if value is None:
+        return False
+    return _compiled_pattern(pattern).search(str(value)) is not None
+
+
+FUNCTIONS: dict[str, Callable[..., Any]] = {
+    "matches": _matches,
+    "len": len,
+    "lower": lambda value: str(value or "").lower(),
+    "any": any,
+    "all": all,
+}
+
+_COMPARATORS: dict[type[ast.cmpop], Callable[[Any, Any], bool]] = {
+    ast.Eq: operator.eq,
+    ast.NotEq: operator.ne,
+    ast.Lt: operator.lt,
+    ast.LtE: operator.le,
+    ast.Gt: operator.gt,
+    ast.GtE: operator.ge,
+    ast.In: lambda left, right: left in right,
+    ast.NotIn: lambda left, right: left not in right,
+}
+
+
+def symbol_fields(symbol: DocSymbol) -> dict[str, Any]:
+    """Return the names a rule expression can refer to for ``symbol``."""
+    metadata = symbol.metadata or {}
+    return {
+        "name": symbol.name,
+        "qualified_name": symbol.qualified_name,
+        "kind": symbol.kind,
+        "package": symbol.package,
+        "file_path": symbol.file_path,
+        "lineno": symbol.lineno,
+        "parent": symbol.parent or "",
+        "docstring": symbol.docstring or "",
+        "documented": symbol.is_documented,
+        "is_public": symbol.is_public,
+        "exported": is_exported(symbol),
+        "decorators": list(metadata.get("decorators", [])),
+        "parameters": [p["name"] for p in metadata.get("parameters", [])],
+        "return_type": metadata.get("return_type") or "",
+        "base_classes": list(metadata.get("base_classes", [])),
+        "raises": list(metadata.get("raises", [])),
+        "is_async": bool(metadata.get("is_async", False)),
+    }
+
+
+_ALLOWED_NAMES = frozenset(symbol_fields(DocSymbol("", "", "", "", "", 0)))
+
+
+@dataclass(frozen=True)
+class Expression:
+    """A validated rule expression."""
+
+    source: str
+    tree: ast.Expression
+
+    def evaluate(self, fields: dict[str, Any]) -> Any:
+        """Evaluate against ``fields`` (see :func:`symbol_fields`).
+
+        Raises:
+            ExpressionError: If evaluation fails (e.g. comparing a list to an int)
+        """
+        try:
+            return _evaluate(self.tree.body, fields)
+        except ExpressionError:
+            raise
+        except Exception as exc:  # noqa: BLE001 - report any runtime failure uniformly
+            raise ExpressionError(f"Cannot evaluate {self.source!r}: {exc}") from exc
+
+    def matches(self, symbol: DocSymbol) -> bool:
+        return bool(self.evaluate(symbol_fields(symbol)))
+
+
+def _validate(node: ast.AST, source: str) -> None:
+    for child in ast.walk(node):
+        if isinstance(child, ast.Name):
+            if child.id not in _ALLOWED_NAMES and child.id not in FUNCTIONS:
+                raise ExpressionError(f"Unknown name {child.id!r} in {source!r}")
+        elif isinstance(child, ast.Call):
+            if not isinstance(child.func, ast.Name) or child.func.id not in FUNCTIONS:
+                raise ExpressionError(f"Only {sorted(FUNCTIONS)} may be called in {source!r}")
+            if child.keywords:
+                raise ExpressionError(f"Keyword arguments are not supported in {source!r}")
+            if child.func.id == "matches" and len(child.args) == 2:
+                pattern = child.args[1]
+                if isinstance(pattern, ast.Constant) and isinstance(pattern.value, str):
+                    # Fail at config load, not on the first symbol evaluated.
+                    _compiled_pattern(pattern.value)
+        elif isinstance(child, ast.UnaryOp):
+            if not isinstance(child.op, ast.Not):
+                raise ExpressionError(f"Unsupported operator in {source!r}")
+        elif isinstance(child, ast.Compare):
+            if any(type(op) not in _COMPARATORS for op in child.ops):
+                raise ExpressionError(f"Unsupported comparison in {source!r}")
+        elif not isinstance(
+            child,
+            (
+                ast.Expression,
+                ast.BoolOp,
+                ast.And,
+                ast.Or,
+                ast.Not,
+                ast.Constant,
+                ast.List,
+                ast.Tuple,
+                ast.Load,
+                ast.cmpop,
+            ),
+        ):
+            raise ExpressionError(
+                f"Unsupported syntax {type(child).__name__} in {source!r}",
+            )
+
+
+def _evaluate(node: ast.AST, fields: dict[str, Any]) -> Any:
+    if isinstance(node, ast.BoolOp):
+        if isinstance(node.op, ast.And):
+            return all(_evaluate(value, fields) for value in node.values)
+        return any(_evaluate(value, fields) for value in node.values)
+    if isinstance(node, ast.UnaryOp):
+        return not _evaluate(node.operand, fields)
+    if isinstance(node, ast.Compare):
+        left = _evaluate(node.left, fields)
+        for op, comparator in zip(node.ops, node.comparators, strict=True):
+            right = _evaluate(comparator, fields)
+            if not _COMPARATORS[type(op)](left, right):
+                return False
+            left = right
+        return True
+    if isinstance(node, ast.Call):
+        func = FUNCTIONS[node.func.id]  # type: ignore[attr-defined]
+        return func(*(_evaluate(arg, fields) for arg in node.args))
+    if isinstance(node, ast.Name):
+        return fields[node.id]
+    if isinstance(node, ast.Constant):
+        return node.value
+    if isinstance(node, (ast.List, ast.Tuple)):
+        return [_evaluate(element, fields) for element in node.elts]
+    raise ExpressionError(f"Unsupported syntax {type(node).__name__}")
+
+
+def compile_expression(source: str) -> Expression:
+    """Parse and validate ``source``.
+
+    Raises:
+        ExpressionError: If the expression is not valid Python syntax, uses
+            anything outside the supported subset, or has an invalid constant
+            regular expression
+    """
+    try:
+        tree = ast.parse(source.strip(), mode="eval")
+    except SyntaxError as exc:
+        raise ExpressionError(f"Invalid expression {source!r}: {exc.msg}") from exc
+    _validate(tree, source)
+    return Expression(source=source, tree=tree)
+
+
+__all__ = [
+    "FUNCTIONS",
+    "Expression",
+    "ExpressionError",
+    "compile_expression",
+    "symbol_fields",
+]
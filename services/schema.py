@@ -0,0 +1,128 @@
+"""Schema versioning of the JSON artifacts AutoDoc writes.
+
+:data:`SCHEMA_VERSION` covers every machine-readable output: the JSON site,
+the baseline, and the ``--format json`` reports. Each object carries it as
+``schema_version`` (see :func:`stamp_schema`). It is bumped when a field is
+removed, renamed, or changes meaning; new optional fields do not bump it.
+
+Artifacts written before versioning have no ``schema_version`` and count as
+version 0. :func:`migrate` upgrades an older artifact one version at a time
+through :data:`MIGRATIONS`, so baselines and cached sites keep working across
+format changes; readers call :func:`upgrade` before parsing.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Callable
+from dataclasses import dataclass
+from typing import Any
+
+SCHEMA_VERSION = 1
+SCHEMA_KEY = "schema_version"
+
+# Artifact kinds, told apart by their top-level keys.
+BASELINE = "baseline"
+SITE = "site"
+TEST_SUITE = "test-suite"
+KINDS = (BASELINE, SITE, TEST_SUITE)
+
+
+class SchemaError(Exception):
+    """Raised for an artifact that cannot be read or migrated."""
+
+
+def stamp_schema(data: dict[str, Any]) -> dict[str, Any]:
+    """``data`` with the current schema version as its first key."""
+    return {SCHEMA_KEY: SCHEMA_VERSION, **data}
+
+
+def artifact_kind(data: Any) -> str:
+    """The kind of JSON artifact ``data`` is."""
+    if isinstance(data, dict):
+        if "entries" in data and "version" in data:
+            return BASELINE
+        if "packages" in data:
+            return SITE
+        if "tests" in data:
+            return TEST_SUITE
+    raise SchemaError("not an AutoDoc baseline, site, or test suite index")
+
+
+def schema_version(data: dict[str, Any]) -> int:
+    """The schema version ``data`` was written with (0 before versioning)."""
+    version = data.get(SCHEMA_KEY, 0)
+    if not isinstance(version, int) or isinstance(version, bool) or version < 0:
+        raise SchemaError(f"invalid {SCHEMA_KEY} {version!r}")
+    return version
+
+
+def _add_schema_version(kind: str, data: dict[str, Any]) -> dict[str, Any]:
+    # Version 1 only adds the key; the fields are those of version 0.
+    return {**data, SCHEMA_KEY: 1}
+
+
+# Upgrades from version ``n`` to ``n + 1``, keyed by ``n``.
+MIGRATIONS: dict[int, Callable[[str, dict[str, Any]], dict[str, Any]]] = {
+    0: _add_schema_version,
+}
+
+
+@dataclass
+class Migration:
+    """The result of migrating one artifact."""
+
+    kind: str
+    from_version: int
+    data: dict[str, Any]
+
+    @property
+    def to_version(self) -> int:
+        return schema_version(self.data)
+
+    @property
+    def changed(self) -> bool:
+        return self.from_version != self.to_version
+
+
+def migrate(data: Any, kind: str | None = None) -> Migration:
+    """Upgrade ``data`` to :data:`SCHEMA_VERSION`.
+
+    Raises:
+        SchemaError: If ``data`` is not an artifact (of ``kind``, when given)
+            or was written by a newer AutoDoc
+    """
+    detected = artifact_kind(data)
+    if kind is not None and detected != kind:
+        raise SchemaError(f"expected a {kind}, found a {detected}")
+    start = schema_version(data)
+    if start > SCHEMA_VERSION:
+        raise SchemaError(
+            f"schema version {start} is newer than this AutoDoc supports "
+            f"({SCHEMA_VERSION}); upgrade AutoDoc",
+        )
+    for version in range(start, SCHEMA_VERSION):
+        data = MIGRATIONS[version](detected, data)
+    return Migration(detected, start, data)
+
+
+def upgrade(data: Any, kind: str) -> dict[str, Any]:
+    """``data`` migrated to the current schema, for readers of ``kind``."""
+    return migrate(data, kind).data
+
+
+__all__ = [
+    "BASELINE",
+    "KINDS",
+    "MIGRATIONS",
+    "SCHEMA_KEY",
+    "SCHEMA_VERSION",
+    "SITE",
+    "TEST_SUITE",
+    "Migration",
+    "SchemaError",
+    "artifact_kind",
+    "migrate",
+    "schema_version",
+    "stamp_schema",
+    "upgrade",
+]
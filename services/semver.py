@@ -0,0 +1,156 @@
+"""Semantic version bump recommendations from API manifest changes.
+
+A :class:`~services.api_manifest.ManifestDiff` is classified by its worst
+change: a removed line (a symbol deleted or its signature changed) is
+*breaking*, an added line alone is a *feature*, and no change is
+*patch*-only. The matching bump follows semver, except that before 1.0.0 a
+breaking change bumps the minor version, as the 0.x series promises no
+stability.
+"""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Iterable
+from dataclasses import dataclass
+
+from services.api_manifest import ManifestDiff
+
+BREAKING = "breaking"
+FEATURE = "feature"
+PATCH = "patch"
+
+# Change classification -> semver component to bump.
+BUMPS = {BREAKING: "major", FEATURE: "minor", PATCH: "patch"}
+_LEVELS = ("patch", "minor", "major")
+
+_VERSION = re.compile(r"^v?(\d+)\.(\d+)\.(\d+)$")
+
+
+class VersionError(Exception):
+    """Raised when a version or release tag is not ``[v]MAJOR.MINOR.PATCH``."""
+
+
+@dataclass(frozen=True, order=True)
+class Version:
+    """A ``MAJOR.MINOR.PATCH`` release number."""
+
+    major: int
+    minor: int
+    patch: int
+
+    def __str__(self) -> str:
+        return f"{self.major}.{self.minor}.{self.patch}"
+
+    def bump(self, level: str) -> Version:
+        if level == "major":
+            return Version(self.major + 1, 0, 0)
+        if level == "minor":
+            return Version(self.major, self.minor + 1, 0)
+        return Version(self.major, self.minor, self.patch + 1)
+
+    def level_to(self, other: Version) -> str | None:
+        """The component bumped to reach ``other``, or None if it is not newer."""
+        if other <= self:
+            return None
+        if other.major != self.major:
+            return "major"
+        return "minor" if other.minor != self.minor else "patch"
+
+
+def parse_version(text: str) -> Version:
+    """Parse ``1.2.3`` or ``v1.2.3``.
+
+    Raises:
+        VersionError: If ``text`` is not a plain semantic version
+    """
+    match = _VERSION.match(text.strip())
+    if match is None:
+        raise VersionError(f"Not a semantic version: {text!r}")
+    return Version(*(int(part) for part in match.groups()))
+
+
+def latest_tag(tags: Iterable[str]) -> str | None:
+    """The tag with the highest semantic version; other tags are ignored."""
+    versions = {}
+    for tag in tags:
+        try:
+            versions[tag] = parse_version(tag)
+        except VersionError:
+            continue
+    return max(versions, key=versions.__getitem__, default=None)
+
+
+def classify(diff: ManifestDiff) -> str:
+    if diff.removed:
+        return BREAKING
+    return FEATURE if diff.added else PATCH
+
+
+def bump_level(change: str, current: Version) -> str:
+    """The semver component ``change`` requires bumping from ``current``."""
+    level = BUMPS[change]
+    if level == "major" and current.major == 0:
+        return "minor"
+    return level
+
+
+@dataclass(frozen=True)
+class Recommendation:
+    """The bump an API diff calls for, from ``current`` to ``next``."""
+
+    change: str
+    level: str
+    current: Version
+    next: Version
+
+    def check_tag(self, tag: str) -> str | None:
+        """Why the release ``tag`` does not fit this bump, or None if it does.
+
+        A tag may bump more than required (a major release without breaking
+        changes) but not less.
+
+        Raises:
+            VersionError: If ``tag`` is not a semantic version
+        """
+        version = parse_version(tag)
+        level = self.current.level_to(version)
+        if level is None:
+            return f"Tag {tag} is not newer than {self.current}"
+        if _LEVELS.index(level) < _LEVELS.index(self.level):
+            return (
+                f"Tag {tag} is a {level} release, but the API has {self.change} "
+                f"changes requiring a {self.level} bump (expected {self.next})"
+            )
+        return None
+
+    def to_dict(self) -> dict[str, str]:
+        return {
+            "change": self.change,
+            "bump": self.level,
+            "current": str(self.current),
+            "next": str(self.next),
+        }
+
+
+def recommend(diff: ManifestDiff, current: Version) -> Recommendation:
+    """Recommend the release following ``current`` for ``diff``."""
+    change = classify(diff)
+    level = bump_level(change, current)
+    return Recommendation(change, level, current, current.bump(level))
+
+
+__all__ = [
+    "BREAKING",
+    "BUMPS",
+    "FEATURE",
+    "PATCH",
+    "Recommendation",
+    "Version",
+    "VersionError",
+    "bump_level",
+    "classify",
+    "latest_tag",
+    "parse_version",
+    "recommend",
+]
@@ -0,0 +1,92 @@
+"""Deterministic split of a tree's packages across CI matrix jobs.
+
+``autodoc shard --total 8 --index 3`` lets each job of a matrix pick its
+share of the packages without a coordinator: every job computes the same
+assignment from the checkout alone and keeps shard ``index`` (counted from
+0). :func:`assign_shards` balances the shards by the number of Python files
+in each package, largest package first, each to the shard with the fewest
+files so far; ties go to the lower shard and packages are ordered by name
+within equal sizes, so the result depends on neither the file system's
+listing order nor the interpreter's hash seed.
+
+Every package lands in exactly one shard. Adding or removing packages may
+move others to another shard; jobs that rerun on the same commit always get
+the same packages.
+"""
+
+from __future__ import annotations
+
+import os
+from collections import defaultdict
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from services.doc_symbols import package_name_for, relative_path
+
+
+class ShardError(Exception):
+    """Raised for a shard index or count that cannot be satisfied."""
+
+
+@dataclass
+class Shard:
+    """The packages (and their files) one matrix job covers."""
+
+    index: int
+    total: int
+    packages: dict[str, list[str]] = field(default_factory=dict)
+
+    @property
+    def files(self) -> list[str]:
+        return sorted(path for paths in self.packages.values() for path in paths)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "index": self.index,
+            "total": self.total,
+            "packages": [
+                {"name": name, "files": paths}
+                for name, paths in sorted(self.packages.items())
+            ],
+        }
+
+
+def packages_of(files: Iterable[str | Path], root: str | Path) -> dict[str, list[str]]:
+    """Root-relative paths of ``files``, grouped by package."""
+    packages: dict[str, list[str]] = defaultdict(list)
+    for path in files:
+        package = package_name_for(Path(os.path.abspath(path)), root)
+        packages[package].append(relative_path(path, root))
+    return {name: sorted(paths) for name, paths in packages.items()}
+
+
+def assign_shards(packages: dict[str, list[str]], total: int) -> list[Shard]:
+    """Split ``packages`` (name to files) into ``total`` balanced shards."""
+    if total < 1:
+        raise ShardError(f"shard count must be at least 1, not {total}")
+    shards = [Shard(index, total) for index in range(total)]
+    loads = [0] * total
+    for name in sorted(packages, key=lambda name: (-len(packages[name]), name)):
+        index = min(range(total), key=lambda index: (loads[index], index))
+        shards[index].packages[name] = packages[name]
+        loads[index] += len(packages[name])
+    return shards
+
+
+def select_shard(packages: dict[str, list[str]], total: int, index: int) -> Shard:
+    """Shard ``index`` of ``total`` of ``packages``."""
+    if not 0 <= index < max(total, 1):
+        raise ShardError(
+            f"shard index must be between 0 and {total - 1}, not {index}",
+        )
+    return assign_shards(packages, total)[index]
+
+
+__all__ = [
+    "Shard",
+    "ShardError",
+    "assign_shards",
+    "packages_of",
+    "select_shard",
+]
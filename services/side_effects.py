@@ -0,0 +1,250 @@
+"""What importing a module does besides defining names.
+
+Python has no ``init`` function, but the top level of a module runs on its
+first import, and plugins, drivers, and handlers commonly hook themselves up
+there::
+
+    import shop.drivers.postgres  # noqa: F401
+    codecs.register(search_codec)
+    admin.site.register(Order)
+
+    @atexit.register
+    def flush(): ...
+
+:func:`find_side_effects` reports three kinds of :class:`SideEffect`, in
+source order:
+
+- ``import``: an import made only for its side effects, whose name the
+  module never uses or exports (``from . import handlers`` in a package
+  ``__init__.py``, ``import readline``);
+- ``registration``: a call or decorator that registers something, named
+  ``register...`` or one of :data:`REGISTRATION_CALLS`;
+- ``call``: any other call made as a statement at import time.
+
+Module-level ``if``, ``try``, and ``with`` blocks run on import too, and are
+searched; ``if __name__ == "__main__":`` and ``if TYPE_CHECKING:`` blocks
+are not. Assignments such as ``engine = create_engine(url)`` are ordinary
+definitions and are not reported.
+"""
+
+from __future__ import annotations
+
+import ast
+from collections.abc import Callable, Iterator
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    dotted_parts,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+SIDE_EFFECT_KINDS = ("import", "registration", "call")
+# Registering calls whose name does not start with "register".
+REGISTRATION_CALLS = frozenset(
+    {
+        "copyreg.pickle",
+        "faulthandler.enable",
+        "mimetypes.add_type",
+        "signal.signal",
+        "warnings.filterwarnings",
+        "warnings.simplefilter",
+    },
+)
+
+
+@dataclass(frozen=True)
+class SideEffect:
+    """One thing a module does when it is imported."""
+
+    module: str
+    package: str
+    kind: str
+    # The imported module, or the called or decorating function.
+    target: str
+    file_path: str
+    lineno: int
+    # What is registered: the decorated or first passed name, if known.
+    subject: str | None = None
+
+    def sentence(self, code: Callable[[str], str]) -> str:
+        """Describe the effect, with names formatted by ``code``."""
+        if self.kind == "import":
+            return f"imports {code(self.target)} for its side effects"
+        if self.kind == "registration":
+            if self.subject is None:
+                return f"registers with {code(self.target)}"
+            return f"registers {code(self.subject)} with {code(self.target)}"
+        return f"calls {code(self.target)}"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "module": self.module,
+            "package": self.package,
+            "kind": self.kind,
+            "target": self.target,
+            "subject": self.subject,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+        }
+
+
+def is_registration(name: str) -> bool:
+    """Whether calling the function named ``name`` registers something."""
+    if name in REGISTRATION_CALLS:
+        return True
+    return name.rpartition(".")[2].startswith("register")
+
+
+def _skipped_block(node: ast.If) -> bool:
+    """Whether ``node`` is a main guard or a ``TYPE_CHECKING`` block."""
+    test = ast.unparse(node.test)
+    if test in ("TYPE_CHECKING", "typing.TYPE_CHECKING"):
+        return True
+    return test in ("__name__ == '__main__'", "'__main__' == __name__")
+
+
+def _import_time(body: list[ast.stmt]) -> Iterator[ast.stmt]:
+    """Statements of ``body`` that run on import, nested blocks included."""
+    for node in body:
+        if isinstance(node, ast.If):
+            if not _skipped_block(node):
+                yield from _import_time(node.body)
+                yield from _import_time(node.orelse)
+        elif isinstance(node, ast.Try):
+            for block in (node.body, node.orelse, node.finalbody):
+                yield from _import_time(block)
+            for handler in node.handlers:
+                yield from _import_time(handler.body)
+        elif isinstance(node, ast.With):
+            yield from _import_time(node.body)
+        else:
+            yield node
+
+
+def _exported(tree: ast.Module) -> set[str]:
+    for node in tree.body:
+        if (
+            isinstance(node, ast.Assign)
+            and any(isinstance(t, ast.Name) and t.id == "__all__" for t in node.targets)
+            and isinstance(node.value, (ast.List, ast.Tuple))
+        ):
+            return {
+                element.value
+                for element in node.value.elts
+                if isinstance(element, ast.Constant) and isinstance(element.value, str)
+            }
+    return set()
+
+
+class _ModuleScanner:
+    def __init__(
+        self,
+        info: ModuleImports,
+        graph: ImportGraph,
+        tree: ast.Module,
+    ) -> None:
+        self.info = info
+        self.graph = graph
+        self.tree = tree
+        self.local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        }
+        self.used = {
+            node.id
+            for node in ast.walk(tree)
+            if isinstance(node, ast.Name) and isinstance(node.ctx, ast.Load)
+        } | _exported(tree)
+
+    def resolve(self, node: ast.expr) -> str:
+        return resolve_name(node, self.info, self.local_names)
+
+    def scan(self) -> Iterator[tuple[str, str, int, str | None]]:
+        for node in _import_time(self.tree.body):
+            if isinstance(node, ast.Import):
+                for alias in node.names:
+                    local = alias.asname or alias.name.split(".", 1)[0]
+                    if local not in self.used:
+                        yield "import", alias.name, node.lineno, None
+            elif isinstance(node, ast.ImportFrom):
+                for alias in node.names:
+                    target = self.info.aliases.get(alias.asname or alias.name)
+                    # Only submodules run code; other names are definitions.
+                    local = alias.asname or alias.name
+                    if target in self.graph.modules and local not in self.used:
+                        yield "import", target, node.lineno, None
+            elif isinstance(node, ast.Expr) and isinstance(node.value, ast.Call):
+                yield from self._call(node.value)
+            elif isinstance(
+                node,
+                (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef),
+            ):
+                yield from self._decorators(node)
+
+    def _call(self, call: ast.Call) -> Iterator[tuple[str, str, int, str | None]]:
+        if dotted_parts(call.func) is None:
+            return
+        name = self.resolve(call.func)
+        if name.startswith("__all__."):
+            return
+        if not is_registration(name):
+            yield "call", name, call.lineno, None
+            return
+        subject = None
+        if call.args:
+            first = call.args[0]
+            if isinstance(first, ast.Constant) and isinstance(first.value, str):
+                subject = first.value
+            elif dotted_parts(first) is not None:
+                subject = self.resolve(first)
+        yield "registration", name, call.lineno, subject
+
+    def _decorators(
+        self,
+        node: ast.FunctionDef | ast.AsyncFunctionDef | ast.ClassDef,
+    ) -> Iterator[tuple[str, str, int, str | None]]:
+        subject = f"{self.info.module}.{node.name}"
+        for decorator in node.decorator_list:
+            func = decorator.func if isinstance(decorator, ast.Call) else decorator
+            if dotted_parts(func) is None:
+                continue
+            name = self.resolve(func)
+            if is_registration(name):
+                yield "registration", name, decorator.lineno, subject
+
+
+def find_side_effects(root: str | Path, graph: ImportGraph) -> list[SideEffect]:
+    """The import-time effects of the modules of ``graph`` (built for ``root``).
+
+    Effects are sorted by module and line.
+    """
+    effects = []
+    for info, tree in parse_modules(graph, "side effect detection"):
+        for kind, target, lineno, subject in _ModuleScanner(info, graph, tree).scan():
+            effects.append(
+                SideEffect(
+                    module=info.module,
+                    package=info.package,
+                    kind=kind,
+                    target=target,
+                    file_path=relative_path(info.file_path, root),
+                    lineno=lineno,
+                    subject=subject,
+                ),
+            )
+    return sorted(effects, key=lambda effect: (effect.module, effect.lineno))
+
+
+__all__ = [
+    "REGISTRATION_CALLS",
+    "SIDE_EFFECT_KINDS",
+    "SideEffect",
+    "find_side_effects",
+    "is_registration",
+]
@@ -0,0 +1,160 @@
+"""Detached signatures for artifact manifests, made with minisign or cosign.
+
+A signed manifest proves who published a site or an API manifest: the
+attestation of ``autodoc generate`` (see :mod:`services.attestation`) lists
+the hash of every generated file, so its signature covers the whole site.
+Signatures are written next to the manifest, named after it with
+:data:`SIGNATURE_SUFFIXES`, and published with it.
+
+The tools are run as installed; neither ships with AutoDoc. ``minisign``
+signs with a secret key file and is checked with the matching public key.
+``cosign`` signs with a key (a file or a KMS URI such as ``awskms://...``) or,
+without one, keylessly through Sigstore; it writes a bundle that is checked
+with the public key or with the identity and OIDC issuer of the signer.
+Keys must be usable without a prompt: an unencrypted minisign key, or a
+cosign key with ``COSIGN_PASSWORD`` set.
+"""
+
+from __future__ import annotations
+
+import logging
+import subprocess
+import tempfile
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+SIGNING_TOOLS = ("cosign", "minisign")
+SIGNATURE_SUFFIXES = {"cosign": ".sigstore.json", "minisign": ".minisig"}
+# Default of ``--sign-key``, so CI can keep the key out of the command line.
+KEY_ENV = "AUTODOC_SIGNING_KEY"
+
+
+class SigningError(Exception):
+    """Raised when signing fails or a signature does not verify."""
+
+
+def _run(tool: str, *args: str) -> None:
+    try:
+        subprocess.run(
+            [tool, *args],
+            stdin=subprocess.DEVNULL,
+            capture_output=True,
+            text=True,
+            check=True,
+        )
+    except FileNotFoundError as exc:
+        raise SigningError(f"{tool} executable not found") from exc
+    except subprocess.CalledProcessError as exc:
+        output = (exc.stderr or exc.stdout).strip().splitlines()
+        detail = output[-1] if output else f"exit status {exc.returncode}"
+        raise SigningError(f"{tool} failed: {detail}") from exc
+
+
+def signature_path(path: str | Path, tool: str) -> Path:
+    """Where the signature of ``path`` made by ``tool`` is stored."""
+    path = Path(path)
+    return path.with_name(path.name + SIGNATURE_SUFFIXES[tool])
+
+
+def find_signature(path: str | Path) -> tuple[str, Path] | None:
+    """The tool and file of the signature stored next to ``path``, if any."""
+    for tool in SIGNING_TOOLS:
+        candidate = signature_path(path, tool)
+        if candidate.is_file():
+            return tool, candidate
+    return None
+
+
+def sign_file(path: str | Path, tool: str, key: str | None = None) -> Path:
+    """Sign ``path`` with ``tool`` and return the signature file.
+
+    Raises:
+        SigningError: If ``tool`` is unknown, needs a key, or fails
+    """
+    if tool not in SIGNATURE_SUFFIXES:
+        raise SigningError(f"Unknown signing tool {tool!r}")
+    signature = signature_path(path, tool)
+    if tool == "minisign":
+        if not key:
+            message = f"minisign needs a secret key (--sign-key or ${KEY_ENV})"
+            raise SigningError(message)
+        _run("minisign", "-S", "-s", key, "-m", str(path), "-x", str(signature))
+    else:
+        args = ["sign-blob", "--yes", "--bundle", str(signature)]
+        if key:
+            args += ["--key", key]
+        _run("cosign", *args, str(path))
+    logger.info("Signed %s with %s", path, tool)
+    return signature
+
+
+def sign_bytes(
+    data: str | bytes,
+    name: str,
+    tool: str,
+    key: str | None = None,
+) -> bytes:
+    """The signature of ``data``, as if it were a file called ``name``.
+
+    For content that is written later, such as pages of a site, so the
+    signature can be written with it. minisign records ``name`` in the
+    signature's trusted comment.
+    """
+    with tempfile.TemporaryDirectory() as scratch:
+        path = Path(scratch) / name
+        if isinstance(data, str):
+            path.write_text(data, encoding="utf-8")
+        else:
+            path.write_bytes(data)
+        return sign_file(path, tool, key).read_bytes()
+
+
+def verify_file(
+    path: str | Path,
+    tool: str,
+    signature: str | Path | None = None,
+    public_key: str | None = None,
+    identity: str | None = None,
+    issuer: str | None = None,
+) -> None:
+    """Check the signature of ``path``.
+
+    minisign needs ``public_key``. cosign needs ``public_key`` for signatures
+    made with a key, and ``identity`` and ``issuer`` for keyless ones.
+
+    Raises:
+        SigningError: If the signature does not verify
+    """
+    signature = Path(signature) if signature else signature_path(path, tool)
+    if tool == "minisign":
+        if not public_key:
+            raise SigningError("minisign signatures need --public-key to verify")
+        args = ["-V", "-p", public_key, "-m", str(path), "-x", str(signature)]
+        _run("minisign", *args)
+        return
+    args = ["verify-blob", "--bundle", str(signature)]
+    if public_key:
+        args += ["--key", public_key]
+    elif identity and issuer:
+        args += ["--certificate-identity", identity]
+        args += ["--certificate-oidc-issuer", issuer]
+    else:
+        raise SigningError(
+            "cosign signatures need --public-key, or --certificate-identity and "
+            "--certificate-oidc-issuer, to verify",
+        )
+    _run("cosign", *args, str(path))
+
+
+__all__ = [
+    "KEY_ENV",
+    "SIGNATURE_SUFFIXES",
+    "SIGNING_TOOLS",
+    "SigningError",
+    "find_signature",
+    "sign_bytes",
+    "sign_file",
+    "signature_path",
+    "verify_file",
+]
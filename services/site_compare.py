@@ -0,0 +1,254 @@
+"""Comparing two generated sites, for ``autodoc compare``.
+
+Signing off on the docs of a release means reading what changed in them, not
+in the code: which pages a release adds, which it drops, and how much of
+each of the others it rewrites. :func:`compare_sites` compares the files of
+two ``autodoc generate`` outputs, such as the site of the last release and
+that of the release candidate:
+
+- a page only the new site has is *added*, one only the old site has
+  *removed*;
+- a page whose bytes differ is *changed*, with the lines it gained and lost
+  when both versions are text;
+- the bookkeeping of ``generate`` (:data:`~services.doc_site.SITE_FILES`,
+  the incremental state, the checkpoint, the attestation and its
+  signatures) is not part of either site.
+
+The output of several formats (``--format html,json``) is compared as a
+whole, the pages of each format directory included.
+"""
+
+from __future__ import annotations
+
+import difflib
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from fnmatch import fnmatchcase
+from pathlib import Path
+from typing import Any
+
+from services.attestation import ATTESTATION_FILE
+from services.checkpoint import CHECKPOINT_DIR
+from services.doc_site import SITE_FILES
+from services.incremental import STATE_FILE
+
+ADDED = "added"
+REMOVED = "removed"
+CHANGED = "changed"
+# Kinds of change, in report order.
+CHANGE_KINDS = (CHANGED, ADDED, REMOVED)
+_BOOKKEEPING = frozenset({SITE_FILES, STATE_FILE})
+
+
+class SiteCompareError(Exception):
+    """Raised when a directory is not a generated site."""
+
+
+@dataclass(frozen=True)
+class PageChange:
+    """One page that differs between the two sites."""
+
+    path: str
+    kind: str
+    # Lines gained and lost, for changed text pages.
+    added_lines: int = 0
+    removed_lines: int = 0
+    # Unified diff of a changed text page.
+    diff: list[str] = field(default_factory=list)
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "path": self.path,
+            "kind": self.kind,
+            "added_lines": self.added_lines,
+            "removed_lines": self.removed_lines,
+        }
+
+
+@dataclass(frozen=True)
+class SiteComparison:
+    """The pages that differ between two sites, in path order."""
+
+    old: str
+    new: str
+    changes: list[PageChange] = field(default_factory=list)
+    unchanged: int = 0
+
+    def of_kind(self, kind: str) -> list[PageChange]:
+        """The changes of ``kind``, one of :data:`CHANGE_KINDS`."""
+        return [change for change in self.changes if change.kind == kind]
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "old": self.old,
+            "new": self.new,
+            "changed": [c.to_dict() for c in self.of_kind(CHANGED)],
+            "added": [c.path for c in self.of_kind(ADDED)],
+            "removed": [c.path for c in self.of_kind(REMOVED)],
+            "unchanged": self.unchanged,
+        }
+
+
+def _is_bookkeeping(path: str) -> bool:
+    parts = path.split("/")
+    return (
+        parts[-1] in _BOOKKEEPING
+        or parts[-1].startswith(ATTESTATION_FILE)
+        or CHECKPOINT_DIR in parts[:-1]
+    )
+
+
+def site_pages(directory: str | Path, ignore: Iterable[str] = ()) -> dict[str, Path]:
+    """Path relative to ``directory`` -> file, for the pages of a site.
+
+    Pages matching an :mod:`fnmatch` pattern of ``ignore`` are left out.
+
+    Raises:
+        SiteCompareError: If ``directory`` holds no output of ``generate``
+    """
+    root = Path(directory)
+    listings = [root / SITE_FILES, *root.glob(f"*/{SITE_FILES}")]
+    if not root.is_dir() or not any(path.is_file() for path in listings):
+        raise SiteCompareError(
+            f"{root} is not a generated site (no {SITE_FILES}); point at the "
+            "--output of 'autodoc generate'",
+        )
+    patterns = list(ignore)
+    pages = {}
+    for path in sorted(root.rglob("*")):
+        relative = path.relative_to(root).as_posix()
+        if (
+            path.is_file()
+            and not _is_bookkeeping(relative)
+            and not any(fnmatchcase(relative, pattern) for pattern in patterns)
+        ):
+            pages[relative] = path
+    return pages
+
+
+def _text(content: bytes) -> list[str] | None:
+    try:
+        return content.decode("utf-8").splitlines()
+    except UnicodeDecodeError:
+        return None
+
+
+def _changed(path: str, old: bytes, new: bytes) -> PageChange:
+    before, after = _text(old), _text(new)
+    if before is None or after is None:
+        return PageChange(path, CHANGED)
+    diff = list(
+        difflib.unified_diff(before, after, f"old/{path}", f"new/{path}", lineterm=""),
+    )
+    body = diff[2:]
+    return PageChange(
+        path,
+        CHANGED,
+        sum(1 for line in body if line.startswith("+")),
+        sum(1 for line in body if line.startswith("-")),
+        diff,
+    )
+
+
+def compare_sites(
+    old: str | Path,
+    new: str | Path,
+    ignore: Iterable[str] = (),
+) -> SiteComparison:
+    """What changed from the site in ``old`` to the one in ``new``.
+
+    Raises:
+        SiteCompareError: If either directory is not a generated site
+    """
+    ignore = list(ignore)
+    before = site_pages(old, ignore)
+    after = site_pages(new, ignore)
+    changes = []
+    unchanged = 0
+    for path in sorted(before.keys() | after.keys()):
+        if path not in after:
+            changes.append(PageChange(path, REMOVED))
+        elif path not in before:
+            changes.append(PageChange(path, ADDED))
+        else:
+            old_bytes, new_bytes = before[path].read_bytes(), after[path].read_bytes()
+            if old_bytes == new_bytes:
+                unchanged += 1
+            else:
+                changes.append(_changed(path, old_bytes, new_bytes))
+    return SiteComparison(str(old), str(new), changes, unchanged)
+
+
+def _counts(comparison: SiteComparison) -> str:
+    return (
+        f"{len(comparison.of_kind(CHANGED))} changed, "
+        f"{len(comparison.of_kind(ADDED))} added, "
+        f"{len(comparison.of_kind(REMOVED))} removed, "
+        f"{comparison.unchanged} unchanged"
+    )
+
+
+def _lines(change: PageChange) -> str:
+    if change.kind != CHANGED or not change.diff:
+        return ""
+    return f"+{change.added_lines} -{change.removed_lines}"
+
+
+def render_text(comparison: SiteComparison, diff: bool = False) -> str:
+    """The comparison as plain text, with unified diffs if ``diff``."""
+    lines = []
+    for kind in CHANGE_KINDS:
+        changes = comparison.of_kind(kind)
+        if not changes:
+            continue
+        lines.append(f"{kind.capitalize()} ({len(changes)}):")
+        for change in changes:
+            counts = _lines(change)
+            suffix = f"  ({counts})" if counts else ""
+            lines.append(f"  {change.path}{suffix}")
+        lines.append("")
+    lines.append(_counts(comparison))
+    if diff:
+        for change in comparison.of_kind(CHANGED):
+            if change.diff:
+                lines += ["", *change.diff]
+    return "\n".join(lines) + "\n"
+
+
+def render_markdown(comparison: SiteComparison, diff: bool = False) -> str:
+    """The comparison as Markdown, for a sign-off comment or release issue."""
+    lines = [
+        "# Documentation changes",
+        "",
+        f"`{comparison.old}` → `{comparison.new}`: {_counts(comparison)}.",
+        "",
+    ]
+    if comparison.changes:
+        lines += ["| Page | Change | Lines |", "|---|---|---|"]
+        lines += [
+            f"| `{change.path}` | {change.kind} | {_lines(change)} |"
+            for change in comparison.changes
+        ]
+        lines.append("")
+    else:
+        lines += ["The sites are identical.", ""]
+    if diff:
+        for change in comparison.of_kind(CHANGED):
+            if change.diff:
+                lines += [f"## `{change.path}`", "", "```diff", *change.diff, "```", ""]
+    return "\n".join(lines)
+
+
+__all__ = [
+    "ADDED",
+    "CHANGED",
+    "CHANGE_KINDS",
+    "REMOVED",
+    "PageChange",
+    "SiteCompareError",
+    "SiteComparison",
+    "compare_sites",
+    "render_markdown",
+    "render_text",
+    "site_pages",
+]
@@ -0,0 +1,238 @@
+"""The documentation of one symbol, for ``autodoc doc``.
+
+``autodoc doc shop.cart.Cart`` prints what the site says about a symbol,
+the way ``go doc`` does for Go, plus what only the analyses know:
+
+- members: the functions and classes of a module, the methods of a class;
+- subclasses: the classes of the tree deriving from a class, including
+  implementations of an interface (see :mod:`services.relation_graph`);
+- callers: the functions, methods, and modules calling or constructing it,
+  outside the tests;
+- tests: the tests exercising it (see :mod:`services.test_docs`);
+- examples: the code examples of the guides importing its module and
+  naming it (see :mod:`services.code_examples`).
+
+A name is looked up by its qualified name, else as the end of one:
+``Cart`` and ``cart.Cart`` both find ``shop.cart.Cart`` if no other
+symbol ends the same way (see :func:`find_symbols`).
+"""
+
+from __future__ import annotations
+
+import re
+import textwrap
+from collections.abc import Iterable, Sequence
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.code_examples import CodeExample
+from services.doc_markdown import render_symbol_markdown
+from services.doc_site import PackageDoc, signature, summary
+from services.doc_symbols import DocSymbol, relative_path
+from services.relation_graph import (
+    CALLS,
+    CONSTRUCTS,
+    IMPLEMENTS,
+    INHERITS,
+    Relation,
+    RelationGraph,
+)
+from services.test_docs import DocumentedTest, SuiteDoc, is_test_file
+
+_INDENT = "    "
+
+
+@dataclass(frozen=True)
+class SymbolDoc:
+    """One symbol with everything ``autodoc doc`` prints about it."""
+
+    package: PackageDoc
+    symbol: DocSymbol
+    # Where the symbol is defined, relative to the root.
+    file_path: str
+    # Module members, or class methods, in page order.
+    members: tuple[DocSymbol, ...]
+    subclasses: tuple[Relation, ...]
+    callers: tuple[Relation, ...]
+    tests: tuple[DocumentedTest, ...]
+    examples: tuple[CodeExample, ...]
+
+    def to_dict(self) -> dict[str, object]:
+        inherited = self.package.inherited.get(self.symbol.qualified_name)
+        return {
+            "symbol": self.symbol.to_dict(),
+            "file_path": self.file_path,
+            "signature": signature(self.symbol),
+            "inherited_from": inherited.source if inherited else None,
+            "members": [member.qualified_name for member in self.members],
+            "raises": [
+                error.exception
+                for error in self.package.raises.get(self.symbol.qualified_name, [])
+            ],
+            "subclasses": [relation.to_dict() for relation in self.subclasses],
+            "callers": [relation.to_dict() for relation in self.callers],
+            "tests": [test.to_dict() for test in self.tests],
+            "examples": [example.to_dict() for example in self.examples],
+        }
+
+
+def find_symbols(
+    packages: Iterable[PackageDoc],
+    name: str,
+) -> list[tuple[PackageDoc, DocSymbol]]:
+    """The symbols ``name`` refers to: the one named so, else those ending so."""
+    symbols = [(p, symbol) for p in packages for symbol in p.symbols()]
+    exact = [(p, s) for p, s in symbols if s.qualified_name == name]
+    if exact:
+        return exact
+    return [(p, s) for p, s in symbols if s.qualified_name.endswith(f".{name}")]
+
+
+def _module_of(package: PackageDoc, symbol: DocSymbol) -> str:
+    for module in package.modules:
+        names = {module.symbol.qualified_name}
+        names.update(function.qualified_name for function in module.functions)
+        for cls in module.classes:
+            names.add(cls.symbol.qualified_name)
+            names.update(method.qualified_name for method in cls.methods)
+        if symbol.qualified_name in names:
+            return module.symbol.qualified_name
+    return symbol.parent or symbol.qualified_name
+
+
+def _members(package: PackageDoc, symbol: DocSymbol) -> list[DocSymbol]:
+    for module in package.modules:
+        if module.symbol.qualified_name == symbol.qualified_name:
+            return [*module.functions, *(cls.symbol for cls in module.classes)]
+        for cls in module.classes:
+            if cls.symbol.qualified_name == symbol.qualified_name:
+                return list(cls.methods)
+    return []
+
+
+def _uses(example: CodeExample, module: str, name: str) -> bool:
+    """Whether ``example`` imports ``module`` (or from it) and names ``name``."""
+    if not re.search(rf"\b{re.escape(name)}\b", example.code):
+        return False
+    return bool(re.search(rf"\b(?:import|from)\s+{re.escape(module)}\b", example.code))
+
+
+def build_symbol_doc(
+    package: PackageDoc,
+    symbol: DocSymbol,
+    root: str | Path,
+    relations: RelationGraph,
+    suite: SuiteDoc | None = None,
+    examples: Sequence[CodeExample] = (),
+) -> SymbolDoc:
+    """The :class:`SymbolDoc` of ``symbol``, documented on ``package``'s page.
+
+    Args:
+        package: The package page documenting the symbol
+        symbol: The symbol
+        root: Root of the tree, which paths are relative to
+        relations: The relation graph of the tree
+        suite: The tests of the tree, if any
+        examples: Code examples to search for uses of the symbol
+    """
+    name = symbol.qualified_name
+    module = _module_of(package, symbol)
+    targets = [edge for edge in relations.edges if edge.target == name]
+    return SymbolDoc(
+        package=package,
+        symbol=symbol,
+        file_path=relative_path(symbol.file_path, root),
+        members=tuple(_members(package, symbol)),
+        subclasses=tuple(e for e in targets if e.kind in (INHERITS, IMPLEMENTS)),
+        callers=tuple(
+            e
+            for e in targets
+            if e.kind in (CALLS, CONSTRUCTS) and not is_test_file(e.file_path)
+        ),
+        tests=tuple(t for t in (suite.tests if suite else []) if name in t.subjects),
+        examples=tuple(e for e in examples if _uses(e, module, symbol.name)),
+    )
+
+
+def _heading(symbol: DocSymbol) -> str:
+    if symbol.kind == "module":
+        return f"module {symbol.qualified_name}"
+    return signature(symbol)
+
+
+def _located(name: str, found: Relation | DocumentedTest) -> str:
+    return f"{name} ({found.file_path}:{found.lineno})"
+
+
+def _list(title: str, rows: list[str]) -> list[str]:
+    return [f"{title}:", *(f"{_INDENT}{row}" for row in rows), ""] if rows else []
+
+
+def render_symbol_text(doc: SymbolDoc) -> str:
+    """Plain text for a terminal: the signature, the docstring, then the rest."""
+    symbol = doc.symbol
+    lines = [_heading(symbol)]
+    inherited = doc.package.inherited.get(symbol.qualified_name)
+    docstring = symbol.docstring or (inherited.docstring if inherited else None)
+    if docstring and docstring.strip():
+        lines.extend(textwrap.indent(docstring.strip(), _INDENT).splitlines())
+    else:
+        lines.append(f"{_INDENT}No documentation.")
+    if inherited is not None and not symbol.docstring:
+        lines.append(f"{_INDENT}(Inherited from {inherited.source}.)")
+    lines.append("")
+    lines.append(f"Defined in {doc.file_path}:{symbol.lineno} ({doc.package.name})")
+    lines.append("")
+    members = []
+    for member in doc.members:
+        members.append(_heading(member))
+        text = summary(member.docstring)
+        if text:
+            members.append(f"{_INDENT}{text}")
+    lines.extend(_list("Methods" if symbol.kind == "class" else "Members", members))
+    errors = doc.package.raises.get(symbol.qualified_name, [])
+    lines.extend(_list("Raises", list(dict.fromkeys(e.exception for e in errors))))
+    lines.extend(_list("Subclasses", [_located(e.source, e) for e in doc.subclasses]))
+    lines.extend(_list("Callers", [_located(e.source, e) for e in doc.callers]))
+    lines.extend(_list("Tests", [_located(t.qualified_name, t) for t in doc.tests]))
+    examples = []
+    for example in doc.examples:
+        examples.append(f"{example.file_path}:{example.lineno}")
+        examples.extend(
+            textwrap.indent(example.code.rstrip(), _INDENT).splitlines(),
+        )
+    lines.extend(_list("Examples", examples))
+    return "\n".join(lines).rstrip("\n") + "\n"
+
+
+def render_symbol_doc_markdown(doc: SymbolDoc) -> str:
+    """The symbol's entry of its package page, then the rest as lists."""
+    symbol = doc.symbol
+    parts = [render_symbol_markdown(doc.package, symbol.qualified_name) or ""]
+
+    def section(title: str, rows: list[str]) -> None:
+        if rows:
+            parts.append(f"**{title}**\n\n" + "\n".join(f"- {row}" for row in rows))
+
+    section(
+        "Methods" if symbol.kind == "class" else "Members",
+        [f"`{m.qualified_name}`" for m in doc.members],
+    )
+    section("Subclasses", [_located(f"`{e.source}`", e) for e in doc.subclasses])
+    section("Callers", [_located(f"`{e.source}`", e) for e in doc.callers])
+    section("Tests", [_located(f"`{t.qualified_name}`", t) for t in doc.tests])
+    for example in doc.examples:
+        parts.append(
+            f"Example from {example.file_path}:{example.lineno}:\n\n"
+            f"```python\n{example.code.rstrip()}\n```\n",
+        )
+    return "\n".join(part.rstrip("\n") + "\n" for part in parts if part)
+
+
+__all__ = [
+    "SymbolDoc",
+    "build_symbol_doc",
+    "find_symbols",
+    "render_symbol_doc_markdown",
+    "render_symbol_text",
+]
@@ -0,0 +1,192 @@
+"""Where each documented symbol is defined, as byte ranges of its file.
+
+Code browsers overlay documentation on source: hovering a name shows its
+docs, and "jump to definition" opens the file at the right place. They need
+exact positions rather than pages, so the JSON site also writes
+:data:`SPANS_PATH`, built by :func:`find_symbol_spans` and
+:func:`spans_index`: one row per documented symbol in the columns of
+:data:`SPAN_FIELDS`, with file paths listed once and referred to by index::
+
+    {"fields": ["symbol", "kind", "file", "start", "end", ...],
+     "files": ["shop/cart.py"],
+     "symbols": [["shop.cart.Cart", "class", 0, 120, 410, 126, 130, 7]]}
+
+Offsets count bytes of the UTF-8 file from 0, ends exclusive, so they can
+slice the raw file directly. A definition starts at its first decorator; the
+name range covers the identifier after ``def`` or ``class``. Modules span
+the whole file and have no name range.
+"""
+
+from __future__ import annotations
+
+import ast
+import io
+import logging
+import re
+from collections.abc import Iterable, Iterator
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+from services.doc_symbols import DocSymbol
+from services.import_graph import ImportGraph, parse_modules, relative_path
+
+logger = logging.getLogger(__name__)
+
+SPANS_PATH = "spans.json"
+SPAN_FIELDS = (
+    "symbol",
+    "kind",
+    "file",
+    "start",
+    "end",
+    "name_start",
+    "name_end",
+    "line",
+)
+_DEFINITION = re.compile(rb"(?:async\s+)?(?:def|class)\s+(\w+)")
+
+
+@dataclass(frozen=True)
+class SymbolSpan:
+    """The location of one symbol's definition."""
+
+    symbol: str
+    kind: str
+    file_path: str
+    start: int
+    end: int
+    name_start: int | None
+    name_end: int | None
+    # Line of the ``def`` or ``class`` keyword, 1 for a module.
+    lineno: int
+
+    def row(self, file_index: int) -> list[Any]:
+        """The values of :data:`SPAN_FIELDS`, the file given by index."""
+        return [
+            self.symbol,
+            self.kind,
+            file_index,
+            self.start,
+            self.end,
+            self.name_start,
+            self.name_end,
+            self.lineno,
+        ]
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "symbol": self.symbol,
+            "kind": self.kind,
+            "file_path": self.file_path,
+            "start": self.start,
+            "end": self.end,
+            "name_start": self.name_start,
+            "name_end": self.name_end,
+            "lineno": self.lineno,
+        }
+
+
+def line_offsets(source: str) -> list[int]:
+    """Byte offset of the start of each line of ``source``, as ``ast`` counts."""
+    offsets = [0]
+    # Lines as the parser counts them (str.splitlines also splits at \f).
+    for line in io.StringIO(source, newline="").readlines():
+        offsets.append(offsets[-1] + len(line.encode("utf-8")))
+    return offsets
+
+
+_Definition = ast.FunctionDef | ast.AsyncFunctionDef | ast.ClassDef
+
+
+def _definitions(
+    body: list[ast.stmt],
+    prefix: str,
+) -> Iterator[tuple[str, _Definition]]:
+    for node in body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+            qualified = f"{prefix}.{node.name}"
+            yield qualified, node
+            if isinstance(node, ast.ClassDef):
+                yield from _definitions(node.body, qualified)
+
+
+def _span(
+    node: _Definition,
+    data: bytes,
+    offsets: list[int],
+) -> tuple[int, int, int | None, int | None]:
+    start = offsets[node.lineno - 1] + node.col_offset
+    if node.decorator_list:
+        first = node.decorator_list[0]
+        line = offsets[first.lineno - 1]
+        # The expression starts after the "@".
+        start = data.rfind(b"@", line, line + first.col_offset)
+    end = offsets[(node.end_lineno or node.lineno) - 1] + (node.end_col_offset or 0)
+    keyword = offsets[node.lineno - 1] + node.col_offset
+    match = _DEFINITION.match(data, keyword)
+    if match is None or match.group(1) != node.name.encode("utf-8"):
+        return start, end, None, None
+    return start, end, match.start(1), match.end(1)
+
+
+def find_symbol_spans(
+    root: str | Path,
+    graph: ImportGraph,
+    symbols: Iterable[DocSymbol],
+) -> list[SymbolSpan]:
+    """Where ``symbols`` are defined in the modules of ``graph`` (built for ``root``).
+
+    Spans are in module order, then source order.
+    """
+    kinds = {symbol.qualified_name: symbol.kind for symbol in symbols}
+    spans = []
+    for info, tree in parse_modules(graph, "symbol span index"):
+        try:
+            data = Path(info.file_path).read_bytes()
+        except OSError as exc:
+            logger.warning("Skipping %s in symbol span index: %s", info.file_path, exc)
+            continue
+        rel = relative_path(info.file_path, root)
+        offsets = line_offsets(data.decode("utf-8"))
+        if info.module in kinds:
+            module = info.module
+            spans.append(
+                SymbolSpan(module, kinds[module], rel, 0, len(data), None, None, 1),
+            )
+        for name, node in _definitions(tree.body, info.module):
+            if name in kinds:
+                start, end, name_start, name_end = _span(node, data, offsets)
+                spans.append(
+                    SymbolSpan(
+                        name,
+                        kinds[name],
+                        rel,
+                        start,
+                        end,
+                        name_start,
+                        name_end,
+                        node.lineno,
+                    ),
+                )
+    return spans
+
+
+def spans_index(spans: Iterable[SymbolSpan]) -> dict[str, Any]:
+    """The compact :data:`SPANS_PATH` document for ``spans``."""
+    files: dict[str, int] = {}
+    rows = []
+    for span in spans:
+        index = files.setdefault(span.file_path, len(files))
+        rows.append(span.row(index))
+    return {"fields": list(SPAN_FIELDS), "files": list(files), "symbols": rows}
+
+
+__all__ = [
+    "SPANS_PATH",
+    "SPAN_FIELDS",
+    "SymbolSpan",
+    "find_symbol_spans",
+    "line_offsets",
+    "spans_index",
+]
@@ -0,0 +1,154 @@
+"""Bounded-memory storage for the symbols of huge trees (``--max-memory``).
+
+Parsing a monorepo with hundreds of thousands of symbols keeps every one of
+them, docstrings and signatures included, in memory until the command is
+done. A :class:`SymbolSpool` holds them instead: it is a read-only sequence of
+:class:`~services.doc_symbols.DocSymbol` that keeps symbols in memory only
+until their estimated size reaches a quarter of its budget, then pickles that
+chunk to a temporary file and drops it. Iterating streams the chunks back one
+at a time, so a pass over every symbol holds one chunk, the buffer, and the
+chunk last read by index, which leaves the rest of the budget for the
+command itself. Each pass reads the file again: bounded memory is traded for
+speed.
+
+The estimate counts the text a symbol holds (names, path, docstring, and
+signature metadata) plus a fixed overhead per object rather than measuring
+the Python objects, so the budget is approximate. Other state, such as the
+import graph or a page model built from the symbols, is not bounded.
+"""
+
+from __future__ import annotations
+
+import bisect
+import logging
+import pickle
+import tempfile
+from collections.abc import Iterable, Iterator, Sequence
+from typing import IO, TYPE_CHECKING, Any, overload
+
+if TYPE_CHECKING:
+    from services.doc_symbols import DocSymbol
+
+logger = logging.getLogger(__name__)
+
+# Rough per-symbol cost of the dataclass, its dict, and its small fields.
+SYMBOL_OVERHEAD = 600
+
+
+def estimate_size(symbol: DocSymbol) -> int:
+    """Approximate bytes ``symbol`` holds in memory."""
+    text = (
+        len(symbol.qualified_name)
+        + len(symbol.name)
+        + len(symbol.package)
+        + len(symbol.file_path)
+        + len(symbol.docstring or "")
+    )
+    if symbol.metadata:
+        text += len(repr(symbol.metadata))
+    return SYMBOL_OVERHEAD + text
+
+
+class SymbolSpool(Sequence["DocSymbol"]):
+    """Symbols spilled to a temporary file past ``max_memory // 4`` bytes.
+
+    Build it with :meth:`extend`; it is read-only once a consumer has it.
+    """
+
+    def __init__(self, max_memory: int) -> None:
+        self.max_memory = max_memory
+        self.chunk_size = max(max_memory // 4, SYMBOL_OVERHEAD)
+        self._buffer: list[DocSymbol] = []
+        self._buffered = 0
+        self._file: IO[bytes] | None = None
+        # (offset, length) of each spilled chunk, and its first index.
+        self._chunks: list[tuple[int, int]] = []
+        self._starts: list[int] = []
+        self._spilled = 0
+        self._cached: tuple[int, list[DocSymbol]] | None = None
+
+    @property
+    def spilled(self) -> int:
+        """How many symbols are on disk rather than in memory."""
+        return self._spilled
+
+    def append(self, symbol: DocSymbol) -> None:
+        self._buffer.append(symbol)
+        self._buffered += estimate_size(symbol)
+        if self._buffered >= self.chunk_size:
+            self._spill()
+
+    def extend(self, symbols: Iterable[DocSymbol]) -> None:
+        for symbol in symbols:
+            self.append(symbol)
+
+    def _spill(self) -> None:
+        if self._file is None:
+            self._file = tempfile.TemporaryFile(prefix="autodoc-symbols-")
+            logger.info(
+                "Spilling symbols to disk past %d bytes",
+                self.chunk_size,
+                extra={"max_memory": self.max_memory},
+            )
+        data = pickle.dumps(self._buffer, protocol=pickle.HIGHEST_PROTOCOL)
+        self._file.seek(0, 2)
+        self._chunks.append((self._file.tell(), len(data)))
+        self._file.write(data)
+        self._starts.append(self._spilled)
+        self._spilled += len(self._buffer)
+        logger.debug("Spilled %d symbol(s) to disk", len(self._buffer))
+        self._buffer = []
+        self._buffered = 0
+
+    def _read(self, number: int) -> list[DocSymbol]:
+        assert self._file is not None, "nothing was spilled"
+        offset, length = self._chunks[number]
+        self._file.seek(offset)
+        return pickle.loads(self._file.read(length))
+
+    def _chunk(self, number: int) -> list[DocSymbol]:
+        if self._cached is None or self._cached[0] != number:
+            self._cached = (number, self._read(number))
+        return self._cached[1]
+
+    def __len__(self) -> int:
+        return self._spilled + len(self._buffer)
+
+    def __iter__(self) -> Iterator[DocSymbol]:
+        for number in range(len(self._chunks)):
+            yield from self._read(number)
+        yield from list(self._buffer)
+
+    @overload
+    def __getitem__(self, index: int) -> DocSymbol: ...
+
+    @overload
+    def __getitem__(self, index: slice) -> list[DocSymbol]: ...
+
+    def __getitem__(self, index: Any) -> Any:
+        if isinstance(index, slice):
+            return [self[i] for i in range(*index.indices(len(self)))]
+        if index < 0:
+            index += len(self)
+        if not 0 <= index < len(self):
+            raise IndexError("symbol index out of range")
+        if index >= self._spilled:
+            return self._buffer[index - self._spilled]
+        number = bisect.bisect_right(self._starts, index) - 1
+        return self._chunk(number)[index - self._starts[number]]
+
+    def close(self) -> None:
+        """Delete the temporary file; the spool is empty afterwards."""
+        if self._file is not None:
+            self._file.close()
+        self._file = None
+        self._buffer, self._buffered = [], 0
+        self._chunks, self._starts, self._spilled = [], [], 0
+        self._cached = None
+
+
+__all__ = [
+    "SYMBOL_OVERHEAD",
+    "SymbolSpool",
+    "estimate_size",
+]
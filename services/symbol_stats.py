@@ -0,0 +1,154 @@
+"""Size and documentation statistics of each package, for the stats page.
+
+With ``site.stats`` enabled, the site has a "Symbol statistics" page
+(:data:`~services.doc_site.STATS_SLUG`) built by :func:`build_symbol_stats`.
+For every documented package it shows:
+
+- how many modules, classes, functions, and methods it defines;
+- how many of those symbols are exported (see
+  :func:`~services.doc_symbols.is_exported`) and how many are not;
+- the average length of the docstrings of its exported symbols;
+- its largest classes, by lines of source, and the classes with the most
+  methods.
+
+Counts include unexported symbols even when the pages leave them out, so
+the ratio says how much of the package is public API.
+"""
+
+from __future__ import annotations
+
+import inspect
+from collections import defaultdict
+from collections.abc import Iterable
+from dataclasses import dataclass
+
+from services.doc_site import PackageDoc
+from services.doc_symbols import DocSymbol, is_exported
+
+STATS_KINDS = ("module", "class", "function", "method")
+# Column headings of the kinds, in the order of :data:`STATS_KINDS`.
+STATS_HEADINGS = ("Modules", "Classes", "Functions", "Methods")
+
+
+@dataclass(frozen=True)
+class TypeStats:
+    """The size of one class."""
+
+    qualified_name: str
+    lines: int
+    methods: int
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "qualified_name": self.qualified_name,
+            "lines": self.lines,
+            "methods": self.methods,
+        }
+
+
+@dataclass(frozen=True)
+class PackageStats:
+    """The statistics of one package page."""
+
+    name: str
+    slug: str
+    # Number of symbols of each of :data:`STATS_KINDS`, exported or not.
+    kinds: tuple[int, ...]
+    exported: int
+    unexported: int
+    # Exported symbols with a docstring, and their average length in characters.
+    documented: int
+    doc_length: int
+    # The classes with the most lines, and with the most methods, largest first.
+    largest: tuple[TypeStats, ...] = ()
+    most_methods: tuple[TypeStats, ...] = ()
+
+    @property
+    def total(self) -> int:
+        return self.exported + self.unexported
+
+    @property
+    def exported_percent(self) -> int:
+        """The share of symbols that are exported, rounded to a percentage."""
+        return round(100 * self.exported / self.total) if self.total else 0
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "name": self.name,
+            "kinds": dict(zip(STATS_KINDS, self.kinds)),
+            "exported": self.exported,
+            "unexported": self.unexported,
+            "documented": self.documented,
+            "doc_length": self.doc_length,
+            "largest": [stats.to_dict() for stats in self.largest],
+            "most_methods": [stats.to_dict() for stats in self.most_methods],
+        }
+
+
+def _package_stats(
+    package: PackageDoc,
+    symbols: list[DocSymbol],
+    top: int,
+) -> PackageStats:
+    methods: dict[str, int] = defaultdict(int)
+    for symbol in symbols:
+        if symbol.kind == "method" and symbol.parent is not None:
+            methods[symbol.parent] += 1
+    exported = [symbol for symbol in symbols if is_exported(symbol)]
+    lengths = [
+        len(inspect.cleandoc(symbol.docstring))
+        for symbol in exported
+        if symbol.docstring and symbol.docstring.strip()
+    ]
+    types = [
+        TypeStats(
+            symbol.qualified_name,
+            max(symbol.end_lineno, symbol.lineno) - symbol.lineno + 1,
+            methods[symbol.qualified_name],
+        )
+        for symbol in exported
+        if symbol.kind == "class"
+    ]
+    largest = sorted(types, key=lambda t: (-t.lines, t.qualified_name))
+    most_methods = sorted(
+        (t for t in types if t.methods),
+        key=lambda t: (-t.methods, t.qualified_name),
+    )
+    return PackageStats(
+        package.name,
+        package.slug,
+        tuple(sum(s.kind == kind for s in symbols) for kind in STATS_KINDS),
+        len(exported),
+        len(symbols) - len(exported),
+        len(lengths),
+        round(sum(lengths) / len(lengths)) if lengths else 0,
+        tuple(largest[:top]),
+        tuple(most_methods[:top]),
+    )
+
+
+def build_symbol_stats(
+    packages: Iterable[PackageDoc],
+    symbols: Iterable[DocSymbol],
+    top: int = 5,
+) -> list[PackageStats]:
+    """The statistics of ``packages``, counting every one of ``symbols``.
+
+    ``top`` limits the largest types and the types with the most methods.
+    """
+    by_package: dict[str, list[DocSymbol]] = defaultdict(list)
+    for symbol in symbols:
+        by_package[symbol.package].append(symbol)
+    return [
+        _package_stats(package, by_package[package.name], top)
+        for package in packages
+    ]
+
+
+__all__ = [
+    "STATS_HEADINGS",
+    "STATS_KINDS",
+    "PackageStats",
+    "TypeStats",
+    "build_symbol_stats",
+]
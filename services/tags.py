@@ -0,0 +1,155 @@
+"""Editor tags files of the documented symbols.
+
+Editors without a language server navigate with a tags file: ``--format
+ctags`` writes :data:`CTAGS_PATH` for Vim and other ``ctags`` readers, and
+``--format etags`` writes :data:`ETAGS_PATH` for Emacs, from the same
+symbols the docs are built from (see :func:`find_tags`).
+
+Every documented symbol is tagged under its name and its qualified name,
+so both ``:tag total`` and ``:tag shop.cart.Cart.total`` jump to it. The
+``tags`` file is in the extended format of Universal Ctags, sorted, with a
+search pattern for the line of the ``def`` or ``class`` and the kinds of
+:data:`CTAG_KINDS`; methods carry the ``class:`` of their class. Modules
+are tagged at their first line.
+
+File names are relative to the documented root: editors look them up
+relative to the tags file, so copy it into the root to use it.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Iterable
+from dataclasses import dataclass
+from pathlib import Path
+
+from autodoc import __version__
+from services.doc_symbols import DocSymbol
+from services.import_graph import ImportGraph
+from services.symbol_spans import find_symbol_spans, line_offsets
+
+CTAGS_PATH = "tags"
+ETAGS_PATH = "TAGS"
+# DocSymbol.kind -> the ctags kind letter of the Python parser.
+CTAG_KINDS = {"class": "c", "function": "f", "method": "m", "module": "i"}
+
+
+@dataclass(frozen=True)
+class Tag:
+    """Where an editor finds one documented symbol."""
+
+    name: str
+    symbol: str
+    kind: str
+    file_path: str
+    lineno: int
+    # Byte offset of the start of the line.
+    offset: int
+    # The line, without its line ending; empty for a module.
+    text: str
+    # Length in bytes of the line up to the end of the name.
+    prefix: int
+    # Name of the class of a method.
+    scope: str | None = None
+
+    def ctags_line(self) -> str:
+        """The ``tags`` entry of the tag."""
+        address = f"{self.lineno}"
+        if self.text:
+            pattern = self.text.replace("\\", "\\\\").replace("/", "\\/")
+            address = f"/^{pattern}$/"
+        fields = [CTAG_KINDS.get(self.kind, "v"), f"line:{self.lineno}"]
+        if self.scope is not None:
+            fields.append(f"class:{self.scope}")
+        return "\t".join([self.name, self.file_path, f'{address};"', *fields])
+
+    def etags_line(self) -> bytes:
+        """The ``TAGS`` entry of the tag."""
+        text = self.text.encode("utf-8")[: self.prefix]
+        name = self.name.encode("utf-8")
+        return text + b"\x7f" + name + f"\x01{self.lineno},{self.offset}\n".encode()
+
+
+def find_tags(
+    root: str | Path,
+    graph: ImportGraph,
+    symbols: Iterable[DocSymbol],
+) -> list[Tag]:
+    """The tags of ``symbols`` in the modules of ``graph`` (built for ``root``).
+
+    Tags are in module order, then source order, each symbol tagged under
+    its name before its qualified name.
+    """
+    symbols = list(symbols)
+    parents = {symbol.qualified_name: symbol.parent for symbol in symbols}
+    lines: dict[str, tuple[list[bytes], list[int]]] = {}
+    tags = []
+    for span in find_symbol_spans(root, graph, symbols):
+        if span.file_path not in lines:
+            data = (Path(root) / span.file_path).read_bytes()
+            offsets = line_offsets(data.decode("utf-8"))
+            lines[span.file_path] = (data.splitlines(keepends=True), offsets)
+        source, offsets = lines[span.file_path]
+        text, prefix = "", 0
+        if span.kind != "module" and span.lineno <= len(source):
+            line = source[span.lineno - 1].rstrip(b"\r\n")
+            text = line.decode("utf-8")
+            prefix = len(line)
+            if span.name_end is not None:
+                prefix = span.name_end - offsets[span.lineno - 1]
+        scope = None
+        parent = parents.get(span.symbol)
+        if span.kind == "method" and parent is not None:
+            scope = parent.rpartition(".")[2]
+        names = dict.fromkeys([span.symbol.rpartition(".")[2], span.symbol])
+        tags.extend(
+            Tag(
+                name,
+                span.symbol,
+                span.kind,
+                span.file_path,
+                span.lineno,
+                offsets[span.lineno - 1],
+                text,
+                prefix,
+                scope,
+            )
+            for name in names
+        )
+    return tags
+
+
+def render_ctags(tags: Iterable[Tag]) -> str:
+    """The sorted ``tags`` file of ``tags``."""
+    header = [
+        "!_TAG_FILE_FORMAT\t2\t/extended format/",
+        "!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/",
+        "!_TAG_PROGRAM_NAME\tautodoc\t//",
+        f"!_TAG_PROGRAM_VERSION\t{__version__}\t//",
+    ]
+    # Sorted by byte value, as readers binary-search the file.
+    entries = sorted({tag.ctags_line() for tag in tags}, key=str.encode)
+    return "\n".join([*header, *entries]) + "\n"
+
+
+def render_etags(tags: Iterable[Tag]) -> bytes:
+    """The ``TAGS`` file of ``tags``, one section per file in path order."""
+    sections: dict[str, list[bytes]] = {}
+    for tag in tags:
+        sections.setdefault(tag.file_path, []).append(tag.etags_line())
+    output = []
+    for path in sorted(sections):
+        body = b"".join(sections[path])
+        header = f"{path},{len(body)}\n".encode()
+        output.append(b"\x0c\n" + header + body)
+    return b"".join(output)
+
+
+__all__ = [
+    "CTAGS_PATH",
+    "CTAG_KINDS",
+    "ETAGS_PATH",
+    "Tag",
+    "find_tags",
+    "render_ctags",
+    "render_etags",
+]
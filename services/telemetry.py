@@ -0,0 +1,245 @@
+"""OpenTelemetry traces and metrics of AutoDoc's own pipeline.
+
+Large CI runs can be profiled in an existing observability stack: each
+command is a span (``autodoc generate``) with child spans for its phases,
+
+- ``autodoc.parse`` and ``autodoc.analyze`` for loading symbols and imports,
+- ``autodoc.model`` for building the packages,
+- ``autodoc.render``, with one ``autodoc.render.package`` span per package
+  page,
+- ``autodoc.publish`` for uploads to a bucket or a branch,
+
+and counters for the work done (:data:`METRICS`), such as analysis cache hits
+of ``generate --incremental``. Span and metric attributes are prefixed with
+``autodoc.`` (``autodoc.package``, ``autodoc.format``).
+
+Everything is a no-op unless OpenTelemetry is installed and configured.
+:func:`configure`, called by the CLI, sets up OTLP exporters when one of the
+standard ``OTEL_EXPORTER_OTLP_*ENDPOINT`` variables is set and the SDK and
+exporter are installed (``pip install 'autodoc[telemetry]'``); the other
+``OTEL_*`` variables (headers, protocol, ``OTEL_SERVICE_NAME``,
+``OTEL_RESOURCE_ATTRIBUTES``) apply as usual. Under ``opentelemetry-instrument``
+or in a process that already set up its providers, those are used instead.
+"""
+
+from __future__ import annotations
+
+import importlib
+import logging
+import os
+from collections.abc import Iterator, Mapping
+from contextlib import contextmanager
+from typing import Any
+
+from autodoc import __version__
+
+logger = logging.getLogger(__name__)
+
+INSTRUMENTATION_NAME = "autodoc"
+ATTRIBUTE_PREFIX = "autodoc."
+# Any of these enables the exporters of :func:`configure`.
+ENDPOINT_VARIABLES = (
+    "OTEL_EXPORTER_OTLP_ENDPOINT",
+    "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+    "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT",
+)
+
+# Counters: name -> (unit, description).
+METRICS = {
+    "autodoc.files.parsed": ("{file}", "Source files parsed into symbols"),
+    "autodoc.cache.hits": ("{file}", "Files whose cached analysis was reused"),
+    "autodoc.cache.misses": ("{file}", "Files analyzed again"),
+    "autodoc.pages.rendered": ("{page}", "Documentation pages rendered"),
+    "autodoc.objects.published": ("{object}", "Objects uploaded or deleted"),
+}
+
+# The exporter modules of each OTEL_EXPORTER_OTLP_PROTOCOL.
+_EXPORTERS = {
+    "grpc": "opentelemetry.exporter.otlp.proto.grpc",
+    "http/protobuf": "opentelemetry.exporter.otlp.proto.http",
+}
+
+
+def _attributes(attributes: Mapping[str, Any]) -> dict[str, Any]:
+    return {
+        ATTRIBUTE_PREFIX + key: value
+        for key, value in attributes.items()
+        if value is not None
+    }
+
+
+class Span:
+    """A started span, or nothing when telemetry is off."""
+
+    def __init__(self, span: Any = None) -> None:
+        self._span = span
+
+    def set(self, **attributes: Any) -> None:
+        """Add ``autodoc.``-prefixed attributes."""
+        if self._span is not None:
+            self._span.set_attributes(_attributes(attributes))
+
+
+class Telemetry:
+    """Spans and counters through an OpenTelemetry tracer and meter.
+
+    Without a tracer or meter the matching calls do nothing.
+    """
+
+    def __init__(self, tracer: Any = None, meter: Any = None) -> None:
+        self.tracer = tracer
+        self.meter = meter
+        self._counters: dict[str, Any] = {}
+
+    @contextmanager
+    def span(self, name: str, **attributes: Any) -> Iterator[Span]:
+        """Run the body in a child span of the current one."""
+        if self.tracer is None:
+            yield Span()
+            return
+        with self.tracer.start_as_current_span(
+            name,
+            attributes=_attributes(attributes),
+        ) as span:
+            yield Span(span)
+
+    def count(self, name: str, value: int = 1, **attributes: Any) -> None:
+        """Add ``value`` to the counter ``name`` (see :data:`METRICS`)."""
+        if self.meter is None or value <= 0:
+            return
+        counter = self._counters.get(name)
+        if counter is None:
+            unit, description = METRICS.get(name, ("1", ""))
+            counter = self.meter.create_counter(
+                name,
+                unit=unit,
+                description=description,
+            )
+            self._counters[name] = counter
+        counter.add(value, _attributes(attributes))
+
+
+_current: Telemetry | None = None
+# Providers set up by configure(), shut down (and flushed) by shutdown().
+_providers: list[Any] = []
+
+
+def _global() -> Telemetry:
+    try:
+        from opentelemetry import metrics, trace
+    except ImportError:
+        return Telemetry()
+    return Telemetry(
+        trace.get_tracer(INSTRUMENTATION_NAME, __version__),
+        metrics.get_meter(INSTRUMENTATION_NAME, __version__),
+    )
+
+
+def current() -> Telemetry:
+    """The telemetry spans and counters go to; the global providers by default."""
+    global _current
+    if _current is None:
+        _current = _global()
+    return _current
+
+
+def use(telemetry: Telemetry | None) -> Telemetry | None:
+    """Send spans and counters to ``telemetry`` (None: the global providers).
+
+    Returns the previous one, for restoring it.
+    """
+    global _current
+    previous, _current = _current, telemetry
+    return previous
+
+
+def span(name: str, **attributes: Any) -> Any:
+    """Context manager running its body in the span ``name``."""
+    return current().span(name, **attributes)
+
+
+def count(name: str, value: int = 1, **attributes: Any) -> None:
+    """Add ``value`` to the counter ``name``."""
+    current().count(name, value, **attributes)
+
+
+def _exporters(protocol: str) -> tuple[Any, Any]:
+    package = _EXPORTERS.get(protocol)
+    if package is None:
+        raise ImportError(f"unsupported OTEL_EXPORTER_OTLP_PROTOCOL {protocol!r}")
+    traces = importlib.import_module(f"{package}.trace_exporter")
+    metrics = importlib.import_module(f"{package}.metric_exporter")
+    return traces.OTLPSpanExporter(), metrics.OTLPMetricExporter()
+
+
+def configure(environ: Mapping[str, str] | None = None) -> bool:
+    """Export to the OTLP endpoint of the environment, if one is set.
+
+    Returns whether AutoDoc set up exporters. Nothing is set up when no
+    endpoint is configured, ``OTEL_SDK_DISABLED`` is true, providers are
+    already installed, or the SDK is missing (which is logged).
+    """
+    env = os.environ if environ is None else environ
+    if env.get("OTEL_SDK_DISABLED", "").lower() == "true":
+        return False
+    if not any(env.get(name) for name in ENDPOINT_VARIABLES):
+        return False
+    try:
+        from opentelemetry import metrics, trace
+        from opentelemetry.sdk.metrics import MeterProvider
+        from opentelemetry.sdk.metrics.export import PeriodicExportingMetricReader
+        from opentelemetry.sdk.resources import Resource
+        from opentelemetry.sdk.trace import TracerProvider
+        from opentelemetry.sdk.trace.export import BatchSpanProcessor
+
+        protocol = env.get("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+        span_exporter, metric_exporter = _exporters(protocol)
+    except ImportError as exc:
+        logger.warning(
+            "Not exporting telemetry: %s (pip install 'autodoc[telemetry]')",
+            exc,
+        )
+        return False
+    if not isinstance(trace.get_tracer_provider(), trace.ProxyTracerProvider):
+        logger.debug("Using the tracer and meter providers already installed")
+        return False
+
+    service = {} if env.get("OTEL_SERVICE_NAME") else {"service.name": "autodoc"}
+    resource = Resource.create({**service, "service.version": __version__})
+    tracer_provider = TracerProvider(resource=resource)
+    tracer_provider.add_span_processor(BatchSpanProcessor(span_exporter))
+    meter_provider = MeterProvider(
+        resource=resource,
+        metric_readers=[PeriodicExportingMetricReader(metric_exporter)],
+    )
+    trace.set_tracer_provider(tracer_provider)
+    metrics.set_meter_provider(meter_provider)
+    _providers.extend([tracer_provider, meter_provider])
+    use(None)
+    logger.debug("Exporting telemetry over OTLP (%s)", protocol)
+    return True
+
+
+def shutdown() -> None:
+    """Flush and stop the exporters :func:`configure` set up."""
+    while _providers:
+        try:
+            _providers.pop().shutdown()
+        except Exception as exc:  # A run never fails over its telemetry.
+            logger.warning("Cannot flush telemetry: %s", exc)
+
+
+__all__ = [
+    "ATTRIBUTE_PREFIX",
+    "ENDPOINT_VARIABLES",
+    "INSTRUMENTATION_NAME",
+    "METRICS",
+    "Span",
+    "Telemetry",
+    "configure",
+    "count",
+    "current",
+    "shutdown",
+    "span",
+    "use",
+]
@@ -0,0 +1,374 @@
+"""Documentation of a test suite.
+
+:func:`build_test_suite_doc` collects pytest and unittest tests below a root
+and describes each in plain language:
+
+- the test name becomes a sentence (``test_counts_distinct_outside_packages``
+  reads "Counts distinct outside packages");
+- ``@pytest.mark.parametrize`` tables contribute one case name per row, taken
+  from ``ids=``, ``pytest.param(..., id=...)``, or the row's source text;
+- each test is grouped under the production symbol it exercises, chosen from
+  the first-party symbols its body references: a symbol named in the test
+  name wins, then the most referenced, then the most specific.
+
+Fuzz targets are tests too: Hypothesis ``@given`` tests and the functions
+passed to ``atheris.Setup``. Their seeds are the ``@example`` decorators plus
+the files of a corpus directory named after the target, ``corpus/<name>/`` or
+``testdata/fuzz/<name>/`` next to the test module.
+"""
+
+from __future__ import annotations
+
+import ast
+import logging
+import re
+from collections import Counter, defaultdict
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from services.doc_symbols import DocSymbol
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    dotted_parts,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+logger = logging.getLogger(__name__)
+
+TEST_DIRS = frozenset({"test", "tests"})
+PARAMETRIZE = frozenset({"pytest.mark.parametrize"})
+HYPOTHESIS_GIVEN = frozenset({"hypothesis.given"})
+HYPOTHESIS_EXAMPLE = frozenset({"hypothesis.example"})
+ATHERIS_SETUP = frozenset({"atheris.Setup"})
+CORPUS_DIRS = ("corpus", "testdata/fuzz")
+# Heading for tests no production symbol could be matched to.
+UNGROUPED = "Other tests"
+
+_CAMEL = re.compile(r"(?<=[a-z0-9])(?=[A-Z])")
+
+
+def is_test_file(file_path: str | Path) -> bool:
+    """Whether ``file_path`` is a test module (``test_*.py``, ``*_test.py``,
+    or any module inside a ``tests`` directory)."""
+    path = Path(file_path)
+    return (
+        path.stem.startswith("test_")
+        or path.stem.endswith("_test")
+        or path.name == "conftest.py"
+        or any(part in TEST_DIRS for part in path.parent.parts)
+    )
+
+
+def sentence(name: str) -> str:
+    """Turn a test or test class name into a sentence.
+
+    ``test_handles_emptyInput`` becomes "Handles empty input" and
+    ``TestSymbolUsage`` becomes "Symbol usage".
+    """
+    for prefix in ("test_", "Test", "test"):
+        if name.startswith(prefix) and len(name) > len(prefix):
+            name = name[len(prefix) :]
+            break
+    words = [w for part in name.split("_") for w in _CAMEL.split(part) if w]
+    if not words:
+        return name
+    text = " ".join(w if w.isupper() and len(w) > 1 else w.lower() for w in words)
+    return text[0].upper() + text[1:]
+
+
+@dataclass
+class DocumentedTest:
+    """A single test function or method."""
+
+    name: str
+    qualified_name: str
+    file_path: str
+    lineno: int
+    sentence: str
+    docstring: str | None = None
+    test_class: str | None = None
+    cases: list[str] = field(default_factory=list)
+    # First-party symbols the test references, most relevant first.
+    subjects: list[str] = field(default_factory=list)
+    # Fuzzing engine (``hypothesis`` or ``atheris``) for fuzz targets.
+    fuzz: str | None = None
+    examples: int = 0
+    corpus: int = 0
+
+    @property
+    def subject(self) -> str | None:
+        return self.subjects[0] if self.subjects else None
+
+    @property
+    def seeds(self) -> int:
+        return self.examples + self.corpus
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "name": self.name,
+            "qualified_name": self.qualified_name,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "sentence": self.sentence,
+            "docstring": self.docstring,
+            "test_class": self.test_class,
+            "cases": list(self.cases),
+            "subjects": list(self.subjects),
+            "fuzz": self.fuzz,
+            "examples": self.examples,
+            "corpus": self.corpus,
+        }
+
+
+@dataclass
+class SuiteDoc:
+    """Every test below a root, grouped by the symbol under test."""
+
+    tests: list[DocumentedTest] = field(default_factory=list)
+
+    def groups(self) -> list[tuple[str, list[DocumentedTest]]]:
+        """``(subject, tests)`` pairs sorted by subject, ungrouped tests last."""
+        groups: dict[str, list[DocumentedTest]] = defaultdict(list)
+        for test in self.tests:
+            groups[test.subject or UNGROUPED].append(test)
+        order = sorted(name for name in groups if name != UNGROUPED)
+        if UNGROUPED in groups:
+            order.append(UNGROUPED)
+        return [(name, groups[name]) for name in order]
+
+    def fuzz_targets(self) -> list[DocumentedTest]:
+        return sorted(
+            (test for test in self.tests if test.fuzz),
+            key=lambda test: test.qualified_name,
+        )
+
+    def to_dict(self) -> dict[str, object]:
+        return {"tests": [test.to_dict() for test in self.tests]}
+
+
+def _corpus_size(test_dir: Path, target: str) -> int:
+    for corpus in CORPUS_DIRS:
+        directory = test_dir / corpus / target
+        if directory.is_dir():
+            return sum(
+                1
+                for entry in directory.iterdir()
+                if entry.is_file() and not entry.name.startswith(".")
+            )
+    return 0
+
+
+def _case_id(node: ast.expr) -> str:
+    if isinstance(node, ast.Call) and (
+        isinstance(node.func, ast.Attribute) and node.func.attr == "param"
+    ):
+        for keyword in node.keywords:
+            if keyword.arg == "id" and isinstance(keyword.value, ast.Constant):
+                return str(keyword.value.value)
+        return ", ".join(ast.unparse(arg) for arg in node.args)
+    if isinstance(node, ast.Tuple):
+        return ", ".join(ast.unparse(element) for element in node.elts)
+    return ast.unparse(node)
+
+
+class _ModuleScanner:
+    def __init__(
+        self,
+        tree: ast.Module,
+        info: ModuleImports,
+        file_path: str,
+        symbols: dict[str, DocSymbol],
+        test_dir: Path,
+    ) -> None:
+        self.tree = tree
+        self.info = info
+        self.file_path = file_path
+        self.symbols = symbols
+        self.test_dir = test_dir
+        self.local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        }
+
+    def resolve(self, node: ast.expr) -> str:
+        return resolve_name(node, self.info, self.local_names)
+
+    def cases(self, node: ast.FunctionDef | ast.AsyncFunctionDef) -> list[str]:
+        found = []
+        for decorator in node.decorator_list:
+            if not (
+                isinstance(decorator, ast.Call)
+                and self.resolve(decorator.func) in PARAMETRIZE
+                and len(decorator.args) >= 2
+            ):
+                continue
+            ids = next((k.value for k in decorator.keywords if k.arg == "ids"), None)
+            rows = decorator.args[1]
+            if isinstance(ids, (ast.List, ast.Tuple)):
+                found.extend(
+                    str(i.value) if isinstance(i, ast.Constant) else ast.unparse(i)
+                    for i in ids.elts
+                )
+            elif isinstance(rows, (ast.List, ast.Tuple)):
+                found.extend(_case_id(row) for row in rows.elts)
+        return found
+
+    def atheris_targets(self) -> set[str]:
+        """Names of the functions this module passes to ``atheris.Setup``."""
+        found = set()
+        for node in ast.walk(self.tree):
+            if (
+                isinstance(node, ast.Call)
+                and self.resolve(node.func) in ATHERIS_SETUP
+                and len(node.args) >= 2
+                and isinstance(node.args[1], ast.Name)
+            ):
+                found.add(node.args[1].id)
+        return found
+
+    def _decorated(
+        self,
+        node: ast.FunctionDef | ast.AsyncFunctionDef,
+        names: frozenset[str],
+    ) -> int:
+        return sum(
+            1
+            for decorator in node.decorator_list
+            if isinstance(decorator, ast.Call) and self.resolve(decorator.func) in names
+        )
+
+    def _known(self, dotted: str) -> str | None:
+        parts = dotted.split(".")
+        while parts:
+            candidate = ".".join(parts)
+            if candidate in self.symbols:
+                return candidate
+            parts.pop()
+        return None
+
+    def subjects(self, node: ast.AST, context: str) -> list[str]:
+        """First-party symbols ``node`` references, most relevant first.
+
+        A symbol whose name appears in the test (or test class) name ranks
+        first, then symbols by how often they are referenced, then deeper
+        (more specific) names, then by first reference.
+        """
+        counts: Counter[str] = Counter()
+        # ``cart.Cart.add`` is one reference, not three.
+        inner = {
+            id(sub.value) for sub in ast.walk(node) if isinstance(sub, ast.Attribute)
+        }
+        for sub in ast.walk(node):
+            if isinstance(sub, (ast.Name, ast.Attribute)) and id(sub) not in inner:
+                parts = dotted_parts(sub)
+                if parts is None or parts[0] not in self.info.aliases:
+                    continue
+                known = self._known(self.resolve(sub))
+                if known is not None:
+                    counts[known] += 1
+
+        words = context.lower()
+
+        def rank(name: str) -> tuple[int, int, int]:
+            short = self.symbols[name].name.lower().lstrip("_")
+            named = short.replace("_", "") in words.replace("_", "")
+            depth = name.count(".")
+            return (0 if named else 1, -counts[name], -depth)
+
+        return sorted(counts, key=rank)
+
+    def tests(self) -> list[DocumentedTest]:
+        found = []
+        atheris = self.atheris_targets()
+        for node in self.tree.body:
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                if node.name.startswith("test") or node.name in atheris:
+                    found.append(self._test(node, None, node.name in atheris))
+            elif isinstance(node, ast.ClassDef) and node.name.startswith("Test"):
+                for item in node.body:
+                    if isinstance(
+                        item,
+                        (ast.FunctionDef, ast.AsyncFunctionDef),
+                    ) and item.name.startswith("test"):
+                        found.append(self._test(item, node))
+        return found
+
+    def _test(
+        self,
+        node: ast.FunctionDef | ast.AsyncFunctionDef,
+        cls: ast.ClassDef | None,
+        atheris: bool = False,
+    ) -> DocumentedTest:
+        owner = f"{self.info.module}.{cls.name}" if cls else self.info.module
+        context = f"{cls.name} {node.name}" if cls else node.name
+        subjects = self.subjects(node, context)
+        if not subjects and cls is not None:
+            subjects = self.subjects(cls, context)
+        fuzz = None
+        if atheris:
+            fuzz = "atheris"
+        elif self._decorated(node, HYPOTHESIS_GIVEN):
+            fuzz = "hypothesis"
+        return DocumentedTest(
+            name=node.name,
+            qualified_name=f"{owner}.{node.name}",
+            file_path=self.file_path,
+            lineno=node.lineno,
+            sentence=sentence(node.name),
+            docstring=ast.get_docstring(node),
+            test_class=cls.name if cls else None,
+            cases=self.cases(node),
+            subjects=subjects,
+            fuzz=fuzz,
+            examples=self._decorated(node, HYPOTHESIS_EXAMPLE) if fuzz else 0,
+            corpus=_corpus_size(self.test_dir, node.name) if fuzz else 0,
+        )
+
+
+def build_test_suite_doc(
+    root: str | Path,
+    graph: ImportGraph,
+    symbols: Iterable[DocSymbol],
+) -> SuiteDoc:
+    """Document the tests among ``graph``'s modules.
+
+    ``symbols`` are the documentable symbols of ``root``; those outside test
+    modules are the candidates for a test's subject.
+    """
+    production = {
+        symbol.qualified_name: symbol
+        for symbol in symbols
+        if symbol.kind != "module"
+        and not is_test_file(relative_path(symbol.file_path, root))
+    }
+    suite = SuiteDoc()
+    for info, tree in parse_modules(graph, "test documentation"):
+        file_path = relative_path(info.file_path, root)
+        if not is_test_file(file_path) or Path(file_path).name == "conftest.py":
+            continue
+        scanner = _ModuleScanner(
+            tree,
+            info,
+            file_path,
+            production,
+            Path(info.file_path).parent,
+        )
+        suite.tests.extend(scanner.tests())
+    logger.info("Documented %d test(s)", len(suite.tests))
+    return suite
+
+
+__all__ = [
+    "CORPUS_DIRS",
+    "UNGROUPED",
+    "DocumentedTest",
+    "SuiteDoc",
+    "build_test_suite_doc",
+    "is_test_file",
+    "sentence",
+]
@@ -0,0 +1,229 @@
+"""The third-party dependencies of a project, for the dependency appendix.
+
+With ``site.third_party``, the site ends with an appendix of the project's
+direct dependencies: every distribution its ``requirements*.txt`` files
+and ``pyproject.toml`` declare (see
+:func:`~services.doc_external_links.requirement_lines`), with
+
+- the version: the pinned one (``name==version``), else the one installed
+  where the site is built, else the declared specifier;
+- the license: from a license scanner's report (``site.third_party.licenses``,
+  the JSON of ``pip-licenses --format=json`` or a ``{name: license}``
+  mapping), else from the installed distribution's metadata;
+- the packages of the tree that import it.
+
+Import names are mapped to distributions through the installed packages'
+metadata and ``site.external_links.distributions``, as for external links;
+a distribution that is not installed is assumed to be imported under its
+own name (``python-dateutil`` as ``python_dateutil``).
+"""
+
+from __future__ import annotations
+
+import json
+import logging
+import re
+from collections.abc import Mapping
+from dataclasses import dataclass
+from importlib.metadata import (
+    PackageNotFoundError,
+    distribution,
+    packages_distributions,
+)
+from pathlib import Path
+
+from services.doc_external_links import normalize, requirement_lines
+from services.import_graph import ImportGraph
+
+logger = logging.getLogger(__name__)
+
+# Where the version of a dependency comes from.
+VERSION_SOURCES = ("pinned", "installed", "declared")
+_REQUIREMENT = re.compile(
+    r"^\s*(?P<name>[A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?"
+    r"\s*(?P<spec>[^;#]*)",
+)
+_PIN = re.compile(r"^==\s*(?P<version>[^\s,]+)$")
+_LICENSE_CLASSIFIER = "License :: "
+
+
+@dataclass(frozen=True)
+class Dependency:
+    """A direct dependency and the packages of the tree importing it."""
+
+    distribution: str
+    # The declared version specifier, such as ``>=2.0`` (may be empty).
+    requirement: str
+    version: str | None
+    # One of VERSION_SOURCES, or None without a version.
+    version_source: str | None
+    license: str | None
+    # Top-level import names the distribution provides.
+    modules: tuple[str, ...]
+    # Packages of the tree importing one of ``modules``, in name order.
+    importers: tuple[str, ...]
+
+    def version_label(self) -> str:
+        """The version as the appendix shows it, with where it comes from."""
+        if self.version is None:
+            return "unknown"
+        if self.version_source == "pinned":
+            return self.version
+        return f"{self.version} ({self.version_source})"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "distribution": self.distribution,
+            "requirement": self.requirement,
+            "version": self.version,
+            "version_source": self.version_source,
+            "license": self.license,
+            "modules": list(self.modules),
+            "importers": list(self.importers),
+        }
+
+
+def declared_dependencies(base_dir: str | Path) -> dict[str, tuple[str, str]]:
+    """``(name, specifier)`` of each declared distribution, by normalized name.
+
+    Comments, options (``-r``, ``-e``, ``--index-url``), and URL requirements
+    are skipped; the first declaration of a distribution wins.
+    """
+    declared: dict[str, tuple[str, str]] = {}
+    for line in requirement_lines(base_dir):
+        line = line.strip()
+        if not line or line.startswith(("#", "-")) or " @ " in line:
+            continue
+        match = _REQUIREMENT.match(line)
+        if match is not None:
+            declared.setdefault(
+                normalize(match["name"]),
+                (match["name"], match["spec"].strip()),
+            )
+    return declared
+
+
+def load_licenses(path: str | Path) -> dict[str, str]:
+    """The licenses of a scanner report, by normalized distribution name.
+
+    The report is either a ``{name: license}`` mapping or a list of objects
+    with ``Name`` and ``License`` (``pip-licenses --format=json``). A
+    missing or unreadable report is logged and gives no licenses.
+    """
+    try:
+        data = json.loads(Path(path).read_text(encoding="utf-8"))
+    except (OSError, UnicodeDecodeError, json.JSONDecodeError) as exc:
+        logger.warning("Ignoring license report %s: %s", path, exc)
+        return {}
+    if isinstance(data, dict):
+        entries = data.items()
+    elif isinstance(data, list):
+        entries = (
+            (entry.get("Name"), entry.get("License"))
+            for entry in data
+            if isinstance(entry, dict)
+        )
+    else:
+        logger.warning("Ignoring license report %s: not a mapping or list", path)
+        return {}
+    return {
+        normalize(name): license
+        for name, license in entries
+        if isinstance(name, str) and isinstance(license, str)
+    }
+
+
+def installed_license(name: str) -> str | None:
+    """The license of the installed distribution ``name``, if it states one.
+
+    ``License-Expression`` wins over a one-line ``License`` field, which wins
+    over the ``License ::`` trove classifiers.
+    """
+    try:
+        metadata = distribution(name).metadata
+    except PackageNotFoundError:
+        return None
+    expression = metadata.get("License-Expression")
+    if expression:
+        return expression
+    text = (metadata.get("License") or "").strip()
+    if text and "\n" not in text and text.upper() != "UNKNOWN":
+        return text
+    classifiers = [
+        classifier.rpartition(" :: ")[2]
+        for classifier in metadata.get_all("Classifier") or []
+        if classifier.startswith(_LICENSE_CLASSIFIER)
+    ]
+    return ", ".join(classifiers) or None
+
+
+def _installed_version(name: str) -> str | None:
+    try:
+        return distribution(name).version
+    except PackageNotFoundError:
+        return None
+
+
+def find_third_party(
+    base_dir: str | Path,
+    graph: ImportGraph,
+    licenses: Mapping[str, str] | None = None,
+    distributions: Mapping[str, str] | None = None,
+) -> list[Dependency]:
+    """The declared dependencies of ``base_dir``, in name order.
+
+    Args:
+        base_dir: Directory of the ``pyproject.toml`` and requirements files
+        graph: Import graph of the tree, for the importing packages
+        licenses: Scanner licenses by normalized name (see :func:`load_licenses`)
+        distributions: Top-level import names -> distribution, overriding the
+            installed packages' metadata
+    """
+    licenses = licenses or {}
+    providers: dict[str, set[str]] = {}
+    mapping = {top: dists[0] for top, dists in packages_distributions().items()}
+    mapping.update(distributions or {})
+    for top, name in mapping.items():
+        providers.setdefault(normalize(name), set()).add(top)
+    roots = {module.split(".", 1)[0] for module in graph.modules}
+    importers: dict[str, set[str]] = {}
+    for info in graph.modules.values():
+        for target in info.imports:
+            top = target.split(".", 1)[0]
+            if top not in roots:
+                importers.setdefault(top, set()).add(info.package)
+
+    dependencies = []
+    for key, (name, spec) in sorted(declared_dependencies(base_dir).items()):
+        modules = providers.get(key) or {key.replace("-", "_")}
+        pin = _PIN.match(spec)
+        if pin is not None:
+            version, source = pin["version"], "pinned"
+        elif (installed := _installed_version(name)) is not None:
+            version, source = installed, "installed"
+        else:
+            version, source = (spec, "declared") if spec else (None, None)
+        dependencies.append(
+            Dependency(
+                distribution=name,
+                requirement=spec,
+                version=version,
+                version_source=source,
+                license=licenses.get(key) or installed_license(name),
+                modules=tuple(sorted(modules)),
+                importers=tuple(
+                    sorted({p for top in modules for p in importers.get(top, ())}),
+                ),
+            ),
+        )
+    return dependencies
+
+
+__all__ = [
+    "VERSION_SOURCES",
+    "Dependency",
+    "declared_dependencies",
+    "find_third_party",
+    "installed_license",
+    "load_licenses",
+]
@@ -0,0 +1,245 @@
+"""Native code, unsafe calls, and reflection, for security review.
+
+:func:`find_risks` flags three kinds of :class:`RiskUsage` per package, the
+places a reviewer reads first because the interpreter's guarantees stop
+there:
+
+- ``native``: an import of :data:`NATIVE_MODULES` (``ctypes``, ``cffi``),
+  or a compiled extension module (``.so``, ``.pyd``) next to the package's
+  sources;
+- ``unsafe``: a call of :data:`UNSAFE_CALLS`, which run code built at run
+  time (``eval``, ``pickle.loads``) or read and write raw memory
+  (``ctypes.cast``, ``ctypes.memmove``);
+- ``reflection``: ``getattr``, ``setattr``, and ``delattr`` with a computed
+  attribute name, dynamic imports (``__import__``, ``importlib.import_module``
+  with a computed module name), and frame access (``sys._getframe``).
+
+Calls are resolved through the module's imports, so ``from pickle import
+loads`` is still ``pickle.loads``. The site lists them on the risk appendix
+(``site.risks`` in ``autodoc.yaml``) and ``autodoc risks`` reports them as
+JSON.
+"""
+
+from __future__ import annotations
+
+import ast
+from collections.abc import Callable, Iterable, Iterator
+from dataclasses import dataclass
+from pathlib import Path
+
+from services.import_graph import (
+    ImportGraph,
+    ModuleImports,
+    dotted_parts,
+    parse_modules,
+    relative_path,
+    resolve_name,
+)
+
+RISK_KINDS = ("native", "unsafe", "reflection")
+NATIVE_MODULES = frozenset({"_cffi_backend", "cffi", "ctypes"})
+EXTENSION_SUFFIXES = (".pyd", ".so")
+UNSAFE_CALLS = frozenset(
+    {
+        "compile",
+        "ctypes.addressof",
+        "ctypes.cast",
+        "ctypes.memmove",
+        "ctypes.memset",
+        "ctypes.string_at",
+        "ctypes.wstring_at",
+        "dill.load",
+        "dill.loads",
+        "eval",
+        "exec",
+        "marshal.load",
+        "marshal.loads",
+        "pickle.load",
+        "pickle.loads",
+        "shelve.open",
+    },
+)
+# Reflection calls, with the position of the argument naming the target.
+REFLECTION_CALLS = {
+    "__import__": 0,
+    "delattr": 1,
+    "getattr": 1,
+    "importlib.import_module": 0,
+    "setattr": 1,
+}
+FRAME_CALLS = frozenset({"inspect.currentframe", "sys._getframe"})
+
+
+@dataclass(frozen=True)
+class RiskUsage:
+    """One use of native code, an unsafe call, or reflection."""
+
+    module: str
+    package: str
+    kind: str
+    # The imported module, the called function, or the extension's file name.
+    target: str
+    file_path: str
+    lineno: int
+    # Qualified name of the enclosing function or class, if any.
+    symbol: str | None = None
+
+    def sentence(self, code: Callable[[str], str]) -> str:
+        """Describe the usage, with names formatted by ``code``."""
+        if self.kind == "native" and self.lineno == 0:
+            return f"ships the compiled extension {code(self.target)}"
+        if self.kind == "native":
+            return f"imports {code(self.target)}"
+        return f"calls {code(self.target)}"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "module": self.module,
+            "package": self.package,
+            "kind": self.kind,
+            "target": self.target,
+            "symbol": self.symbol,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+        }
+
+
+@dataclass(frozen=True)
+class PackageRisks:
+    """The :class:`RiskUsage` of one package, with counts per kind."""
+
+    package: str
+    usages: tuple[RiskUsage, ...]
+
+    def count(self, kind: str) -> int:
+        return sum(1 for usage in self.usages if usage.kind == kind)
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "package": self.package,
+            "counts": {kind: self.count(kind) for kind in RISK_KINDS},
+            "usages": [usage.to_dict() for usage in self.usages],
+        }
+
+
+def _computed(call: ast.Call, index: int) -> bool:
+    """Whether argument ``index`` of ``call`` is not a string literal."""
+    if len(call.args) <= index:
+        return False
+    arg = call.args[index]
+    return not (isinstance(arg, ast.Constant) and isinstance(arg.value, str))
+
+
+class _Scanner(ast.NodeVisitor):
+    def __init__(self, info: ModuleImports, tree: ast.Module) -> None:
+        self.info = info
+        self.local_names = {
+            node.name
+            for node in tree.body
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef))
+        }
+        self.scope: list[str] = []
+        self.found: list[tuple[str, str, int, str | None]] = []
+
+    def _add(self, kind: str, target: str, lineno: int) -> None:
+        symbol = ".".join([self.info.module, *self.scope]) if self.scope else None
+        self.found.append((kind, target, lineno, symbol))
+
+    def _enter(
+        self,
+        node: ast.FunctionDef | ast.AsyncFunctionDef | ast.ClassDef,
+    ) -> None:
+        self.scope.append(node.name)
+        self.generic_visit(node)
+        self.scope.pop()
+
+    visit_FunctionDef = visit_AsyncFunctionDef = visit_ClassDef = _enter
+
+    def visit_Import(self, node: ast.Import) -> None:
+        for alias in node.names:
+            if alias.name.split(".", 1)[0] in NATIVE_MODULES:
+                self._add("native", alias.name, node.lineno)
+
+    def visit_ImportFrom(self, node: ast.ImportFrom) -> None:
+        if node.level == 0 and (node.module or "").split(".", 1)[0] in NATIVE_MODULES:
+            self._add("native", node.module or "", node.lineno)
+
+    def visit_Call(self, node: ast.Call) -> None:
+        if dotted_parts(node.func) is not None:
+            name = resolve_name(node.func, self.info, self.local_names)
+            if name in UNSAFE_CALLS:
+                self._add("unsafe", name, node.lineno)
+            elif name in FRAME_CALLS or (
+                name in REFLECTION_CALLS and _computed(node, REFLECTION_CALLS[name])
+            ):
+                self._add("reflection", name, node.lineno)
+        self.generic_visit(node)
+
+
+def _extensions(root: str | Path, graph: ImportGraph) -> Iterator[RiskUsage]:
+    """Compiled extension modules in the directories of the packages."""
+    directories = {
+        Path(info.file_path).parent: info
+        for info in sorted(graph.modules.values(), key=lambda info: info.module)
+        if Path(info.file_path).name == "__init__.py"
+    }
+    for directory, info in directories.items():
+        for path in sorted(directory.iterdir()):
+            if path.suffix in EXTENSION_SUFFIXES and path.is_file():
+                yield RiskUsage(
+                    module=info.module,
+                    package=info.package,
+                    kind="native",
+                    target=path.name,
+                    file_path=relative_path(path, root),
+                    lineno=0,
+                )
+
+
+def find_risks(root: str | Path, graph: ImportGraph) -> list[RiskUsage]:
+    """The risky usages of the modules of ``graph`` (built for ``root``).
+
+    Usages are sorted by module and line; extension files come first in
+    their package's ``__init__`` module.
+    """
+    usages = list(_extensions(root, graph))
+    for info, tree in parse_modules(graph, "risk detection"):
+        scanner = _Scanner(info, tree)
+        scanner.visit(tree)
+        for kind, target, lineno, symbol in scanner.found:
+            usages.append(
+                RiskUsage(
+                    module=info.module,
+                    package=info.package,
+                    kind=kind,
+                    target=target,
+                    file_path=relative_path(info.file_path, root),
+                    lineno=lineno,
+                    symbol=symbol,
+                ),
+            )
+    return sorted(usages, key=lambda usage: (usage.module, usage.lineno))
+
+
+def package_risks(usages: Iterable[RiskUsage]) -> list[PackageRisks]:
+    """``usages`` grouped by package, in package order."""
+    grouped: dict[str, list[RiskUsage]] = {}
+    for usage in usages:
+        grouped.setdefault(usage.package, []).append(usage)
+    return [
+        PackageRisks(package, tuple(grouped[package])) for package in sorted(grouped)
+    ]
+
+
+__all__ = [
+    "EXTENSION_SUFFIXES",
+    "FRAME_CALLS",
+    "NATIVE_MODULES",
+    "REFLECTION_CALLS",
+    "RISK_KINDS",
+    "UNSAFE_CALLS",
+    "PackageRisks",
+    "RiskUsage",
+    "find_risks",
+    "package_risks",
+]
@@ -0,0 +1,208 @@
+"""Exported symbols that no other package uses.
+
+:func:`find_unused` reports the exported classes and functions that the
+import graph never sees referenced from outside their own package: candidates
+to make private, or to delete. A symbol referenced by another module of its
+own package is only a candidate for unexporting (``referenced_in_package``);
+one that is not is at most used inside its own module.
+
+The import graph is static, so symbols reached by name at run time would be
+false positives. The report leaves out:
+
+- ``main`` packages, the ones holding a console script, a ``__main__``
+  module, or a ``__main__`` guard, whose symbols are the program itself;
+- objects named by plugin entry points in ``pyproject.toml``;
+- symbols registered by a decorator (:data:`REGISTRATION_DECORATORS`: route
+  handlers, fixtures, CLI commands, signal receivers, ...);
+- test modules, which the test runner discovers;
+- anything matched by the ``unused`` section of ``autodoc.yaml``
+  (:class:`~autodoc.config.project.UnusedConfig`).
+"""
+
+from __future__ import annotations
+
+import logging
+from collections.abc import Iterable
+from dataclasses import dataclass
+from fnmatch import fnmatchcase
+
+from autodoc.config.project import UnusedConfig
+from services.doc_symbols import DocSymbol, is_exported
+from services.entry_points import ArchitectureOverview
+from services.import_graph import ImportGraph, symbol_usage
+
+logger = logging.getLogger(__name__)
+
+# Decorators that hand the decorated object to a framework, matched with
+# fnmatch against the decorator's dotted name (``app.get``, ``pytest.fixture``)
+# without its arguments.
+REGISTRATION_DECORATORS = (
+    "*.api_route",
+    "*.callback",
+    "*.command",
+    "*.delete",
+    "*.exception_handler",
+    "*.fixture",
+    "*.get",
+    "*.group",
+    "*.head",
+    "*.hookimpl",
+    "*.listens_for",
+    "*.middleware",
+    "*.on_event",
+    "*.options",
+    "*.patch",
+    "*.post",
+    "*.put",
+    "*.register",
+    "*.route",
+    "*.task",
+    "*.websocket",
+    "field_validator",
+    "fixture",
+    "hookimpl",
+    "model_validator",
+    "receiver",
+    "register",
+    "validator",
+)
+
+# Qualified names the test runner collects by naming convention.
+TEST_PATTERNS = ("test_*", "*.test_*", "conftest.*", "*.conftest.*")
+
+# Entry point kinds that make their package a program rather than a library.
+MAIN_KINDS = frozenset({"console-script", "main-module", "main-guard"})
+
+CANDIDATE_KINDS = ("class", "function")
+
+
+@dataclass(frozen=True)
+class UnusedSymbol:
+    """An exported symbol no other package references."""
+
+    qualified_name: str
+    kind: str
+    package: str
+    file_path: str
+    lineno: int
+    referenced_in_package: bool
+
+    @property
+    def suggestion(self) -> str:
+        return "unexport" if self.referenced_in_package else "unexport or remove"
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            "qualified_name": self.qualified_name,
+            "kind": self.kind,
+            "package": self.package,
+            "file_path": self.file_path,
+            "lineno": self.lineno,
+            "referenced_in_package": self.referenced_in_package,
+            "suggestion": self.suggestion,
+        }
+
+
+def _matches(value: str, patterns: Iterable[str]) -> bool:
+    return any(fnmatchcase(value, pattern) for pattern in patterns)
+
+
+def main_packages(graph: ImportGraph, overview: ArchitectureOverview) -> set[str]:
+    """Packages holding a program's entry point rather than library code."""
+    packages = set()
+    for entry in overview.entry_points:
+        if entry.kind not in MAIN_KINDS:
+            continue
+        dotted = entry.target if entry.kind == "console-script" else entry.name
+        module = graph.internal_module(dotted or "")
+        if module is not None:
+            packages.add(graph.modules[module].package)
+    return packages
+
+
+def _package_references(
+    graph: ImportGraph,
+    symbols: dict[str, DocSymbol],
+) -> set[str]:
+    """Symbols referenced from another module of their own package."""
+    found = set()
+    for info in graph.modules.values():
+        for reference in info.references:
+            parts = reference.split(".")
+            for end in range(len(parts), 0, -1):
+                symbol = symbols.get(".".join(parts[:end]))
+                if (
+                    symbol is not None
+                    and symbol.package == info.package
+                    and symbol.parent != info.module
+                ):
+                    found.add(symbol.qualified_name)
+    return found
+
+
+def find_unused(
+    graph: ImportGraph,
+    symbols: Iterable[DocSymbol],
+    config: UnusedConfig | None = None,
+    overview: ArchitectureOverview | None = None,
+    plugins: Iterable[str] = (),
+) -> list[UnusedSymbol]:
+    """Report the exported classes and functions no other package uses.
+
+    ``overview`` supplies the entry points that mark ``main`` packages and
+    ``plugins`` the dotted targets of plugin entry points; ``config`` adds
+    project-specific exclusions.
+
+    Returns:
+        Unused symbols sorted by package and name
+    """
+    config = config or UnusedConfig()
+    symbols = list(symbols)
+    by_name = {symbol.qualified_name: symbol for symbol in symbols}
+    excluded = main_packages(graph, overview) if overview is not None else set()
+    if excluded:
+        logger.info("Not reporting main package(s): %s", ", ".join(sorted(excluded)))
+    keep = (*TEST_PATTERNS, *config.keep)
+    decorators = (*REGISTRATION_DECORATORS, *config.decorators)
+    plugins = set(plugins)
+    in_package = _package_references(graph, by_name)
+
+    unused = []
+    for usage in symbol_usage(graph, symbols):
+        symbol = by_name[usage.qualified_name]
+        if (
+            usage.count
+            or symbol.kind not in CANDIDATE_KINDS
+            or not is_exported(symbol)
+            or symbol.package in excluded
+            or _matches(symbol.package, config.packages)
+            or _matches(symbol.qualified_name, keep)
+            or symbol.qualified_name in plugins
+            or any(
+                _matches(decorator.split("(", 1)[0], decorators)
+                for decorator in (symbol.metadata or {}).get("decorators", [])
+            )
+        ):
+            continue
+        unused.append(
+            UnusedSymbol(
+                qualified_name=symbol.qualified_name,
+                kind=symbol.kind,
+                package=symbol.package,
+                file_path=symbol.file_path,
+                lineno=symbol.lineno,
+                referenced_in_package=symbol.qualified_name in in_package,
+            ),
+        )
+    return sorted(unused, key=lambda u: (u.package, u.qualified_name))
+
+
+__all__ = [
+    "CANDIDATE_KINDS",
+    "MAIN_KINDS",
+    "REGISTRATION_DECORATORS",
+    "TEST_PATTERNS",
+    "UnusedSymbol",
+    "find_unused",
+    "main_packages",
+]
@@ -0,0 +1,93 @@
+"""Preview of the files a command would write (``--dry-run``).
+
+Each writing command renders its output in memory first; in dry-run mode the
+rendered content is compared with what is on disk instead of being written,
+so the report says exactly which files would be created, modified, or
+deleted and which would stay byte-for-byte the same.
+"""
+
+from __future__ import annotations
+
+from collections import Counter
+from collections.abc import Iterable
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+CREATE = "create"
+MODIFY = "modify"
+DELETE = "delete"
+UNCHANGED = "unchanged"
+ACTIONS = (CREATE, MODIFY, DELETE, UNCHANGED)
+
+
+@dataclass(frozen=True)
+class PlannedWrite:
+    """What a run would do to ``path``."""
+
+    path: Path
+    action: str
+
+    def to_dict(self) -> dict[str, Any]:
+        return {"path": self.path.as_posix(), "action": self.action}
+
+
+def plan_write(path: str | Path, content: str | bytes) -> PlannedWrite:
+    """Compare ``content`` with the current contents of ``path``."""
+    target = Path(path)
+    if not target.is_file():
+        return PlannedWrite(target, CREATE)
+    data = content.encode("utf-8") if isinstance(content, str) else content
+    action = UNCHANGED if target.read_bytes() == data else MODIFY
+    return PlannedWrite(target, action)
+
+
+@dataclass
+class WritePlan:
+    """The planned writes of one command, in output order."""
+
+    writes: list[PlannedWrite]
+
+    def counts(self) -> dict[str, int]:
+        counter = Counter(write.action for write in self.writes)
+        return {action: counter[action] for action in ACTIONS}
+
+    def lines(self) -> list[str]:
+        """``would create <path>`` lines for the files that would change."""
+        return [
+            f"would {write.action} {write.path.as_posix()}"
+            for write in self.writes
+            if write.action != UNCHANGED
+        ]
+
+    def summary(self) -> str:
+        counts = self.counts()
+        return (
+            f"Dry run: {counts[CREATE]} to create, {counts[MODIFY]} to modify, "
+            f"{counts[DELETE]} to delete, {counts[UNCHANGED]} unchanged; "
+            "nothing was written"
+        )
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "writes": [write.to_dict() for write in self.writes],
+            "counts": self.counts(),
+        }
+
+
+def plan_writes(targets: Iterable[tuple[str | Path, str | bytes]]) -> WritePlan:
+    """Build a :class:`WritePlan` from ``(path, content)`` pairs."""
+    return WritePlan([plan_write(path, content) for path, content in targets])
+
+
+__all__ = [
+    "ACTIONS",
+    "CREATE",
+    "DELETE",
+    "MODIFY",
+    "UNCHANGED",
+    "PlannedWrite",
+    "WritePlan",
+    "plan_write",
+    "plan_writes",
+]
@@ -28,6 +28,8 @@ class FunctionInfo:
     is_method: bool = False
     docstring: Optional[str] = None
     lineno: int = 0
+    end_lineno: int = 0
+    raises: list[str] = field(default_factory=list)
 
     def to_dict(self) -> dict[str, Any]:
         return {
@@ -40,6 +42,8 @@ class FunctionInfo:
             "is_method": self.is_method,
             "docstring": self.docstring,
             "lineno": self.lineno,
+            "end_lineno": self.end_lineno,
+            "raises": self.raises,
         }
 
 
@@ -53,6 +57,7 @@ class ClassInfo:
     is_public: bool = True
     docstring: Optional[str] = None
     lineno: int = 0
+    end_lineno: int = 0
 
     def to_dict(self) -> dict[str, Any]:
         return {
@@ -63,6 +68,7 @@ class ClassInfo:
             "is_public": self.is_public,
             "docstring": self.docstring,
             "lineno": self.lineno,
+            "end_lineno": self.end_lineno,
         }
 
 
@@ -155,6 +161,7 @@ class SymbolExtractor(ast.NodeVisitor):
             is_public=self._is_public(node.name),
             docstring=ast.get_docstring(node),
             lineno=node.lineno,
+            end_lineno=node.end_lineno or node.lineno,
         )
 
         # save current class context
@@ -182,8 +189,33 @@ class SymbolExtractor(ast.NodeVisitor):
             is_public=self._is_public(node.name),
             docstring=ast.get_docstring(node),
             lineno=node.lineno,
+            end_lineno=node.end_lineno or node.lineno,
+            raises=self._extract_raises(node),
         )
 
+    # exception types raised directly in a function body (nested defs excluded)
+    def _extract_raises(
+        self, node: Union[ast.FunctionDef, ast.AsyncFunctionDef]
+    ) -> list[str]:
+        raised: list[str] = []
+        pending: list[ast.AST] = list(node.body)
+        while pending:
+            child = pending.pop(0)
+            if isinstance(
+                child, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef, ast.Lambda)
+            ):
+                continue
+            if isinstance(child, ast.Raise) and child.exc is not None:
+                exc = child.exc.func if isinstance(child.exc, ast.Call) else child.exc
+                try:
+                    name = ast.unparse(exc)
+                except Exception:
+                    name = ast.dump(exc)
+                if name not in raised:
+                    raised.append(name)
+            pending.extend(ast.iter_child_nodes(child))
+        return raised
+
     def _extract_parameters(self, args: ast.arguments) -> list[ParameterInfo]:
         parameters = []
 
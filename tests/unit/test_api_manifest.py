@@ -0,0 +1,124 @@
+"""Unit tests for the public API manifest."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.api_manifest import (
+    ApiManifestError,
+    diff_manifest,
+    load_manifest,
+    manifest_lines,
+)
+from services.doc_symbols import load_doc_symbols
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def project(tmp_path: Path) -> Path:
+    """A module with public and private functions, a class and a method."""
+    _write(
+        tmp_path / "shop" / "cart.py",
+        '"""Carts."""\n\n'
+        "def total(items: list[int]) -> int:\n"
+        "    return sum(items)\n\n"
+        "def _helper():\n"
+        "    pass\n\n"
+        "class Cart(Base):\n"
+        "    def add(self, item):\n"
+        "        pass\n\n"
+        "    def __len__(self):\n"
+        "        return 0\n\n"
+        "class _Hidden:\n"
+        "    def visible(self):\n"
+        "        pass\n",
+    )
+    return tmp_path
+
+
+class TestManifestLines:
+    """Tests for building the manifest."""
+
+    @pytest.mark.unit
+    def test_exported_surface_is_sorted(self, project):
+        assert manifest_lines(load_doc_symbols(project)) == [
+            "shop.cart, class Cart(Base)",
+            "shop.cart, def Cart.add(item)",
+            "shop.cart, def total(items: list[int]) -> int",
+        ]
+
+    @pytest.mark.unit
+    def test_load_ignores_comments_and_blank_lines(self, tmp_path):
+        path = tmp_path / "api.txt"
+        path.write_text("# API\n\nshop.cart, class Cart\n", encoding="utf-8")
+
+        assert load_manifest(path) == ["shop.cart, class Cart"]
+        with pytest.raises(ApiManifestError):
+            load_manifest(tmp_path / "missing.txt")
+
+    @pytest.mark.unit
+    def test_diff(self):
+        diff = diff_manifest(["a", "b"], ["b", "c"])
+
+        assert (diff.added, diff.removed) == (["c"], ["a"])
+        assert not diff_manifest(["a"], ["a"])
+
+
+class TestApiCommand:
+    """Tests for ``autodoc api``."""
+
+    @pytest.mark.unit
+    def test_write_then_check(self, project, capsys):
+        assert run_command(["api", "--root", str(project)]) == 0
+        manifest = (project / "api.txt").read_text(encoding="utf-8")
+        assert manifest.endswith("shop.cart, def total(items: list[int]) -> int\n")
+
+        assert run_command(["api", "--root", str(project), "--check"]) == 0
+
+    @pytest.mark.unit
+    def test_check_fails_on_surface_change(self, project, capsys):
+        run_command(["api", "--root", str(project)])
+        _write(project / "shop" / "tax.py", "def rate():\n    pass\n")
+        capsys.readouterr()
+
+        assert run_command(["api", "--root", str(project), "--check"]) == 1
+        captured = capsys.readouterr()
+        assert "+ shop.tax, def rate()" in captured.out
+        assert "1 added, 0 removed" in captured.err
+
+    @pytest.mark.unit
+    def test_check_without_manifest_fails(self, project):
+        assert run_command(["api", "--root", str(project), "--check"]) == 1
+
+    @pytest.mark.unit
+    def test_frozen_package_keeps_its_api(self, project, capsys):
+        run_command(["api", "--root", str(project)])
+        _write(
+            project / "autodoc.yaml",
+            "api:\n  frozen: [shop]\n  governance: https://example.com/api-review\n",
+        )
+        _write(project / "shop" / "tax.py", "def rate():\n    pass\n")
+        _write(project / "billing.py", "def charge():\n    pass\n")
+        capsys.readouterr()
+
+        assert run_command(["api", "--root", str(project)]) == 0
+        captured = capsys.readouterr()
+        assert "kept the committed API of frozen package shop (1 changed" in (
+            captured.err
+        )
+        manifest = (project / "api.txt").read_text(encoding="utf-8")
+        assert "billing, def charge()" in manifest
+        assert "shop.tax" not in manifest
+
+        assert run_command(["api", "--root", str(project), "--check"]) == 1
+        captured = capsys.readouterr()
+        assert (
+            "the API of frozen package shop changed (1 line(s)); "
+            "see https://example.com/api-review"
+        ) in captured.err
+        assert "run 'autodoc api'" not in captured.err
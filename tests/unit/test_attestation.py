@@ -0,0 +1,102 @@
+"""Unit tests for site attestations and ``autodoc generate --verify``."""
+
+import json
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from autodoc import __version__
+from autodoc.cli.main import run_command
+from services.attestation import (
+    ATTESTATION_FILE,
+    AttestationError,
+    load_attestation,
+    tree_hash,
+)
+
+
+def _git(repo: Path, *args: str) -> str:
+    result = subprocess.run(["git", *args], cwd=repo, check=True, capture_output=True)
+    return result.stdout.decode().strip()
+
+
+@pytest.fixture
+def repo(tmp_path: Path) -> Path:
+    root = tmp_path / "repo"
+    (root / "shop").mkdir(parents=True)
+    (root / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (root / "autodoc.yaml").write_text("site:\n  title: Shop\n", encoding="utf-8")
+    _git(root, "init", "-q")
+    _git(root, "config", "user.email", "dev@example.com")
+    _git(root, "config", "user.name", "Dev")
+    _git(root, "add", "-A")
+    _git(root, "commit", "-q", "-m", "Initial")
+    return root
+
+
+class TestAttestation:
+    """Tests for the attestation written by ``autodoc generate``."""
+
+    @pytest.mark.unit
+    def test_generate_records_inputs_and_outputs(self, repo: Path, tmp_path: Path):
+        site = tmp_path / "site"
+        argv = ["generate", "--root", str(repo), "--output", str(site)]
+        assert run_command([*argv, "--format", "html"]) == 0
+        data = json.loads((site / ATTESTATION_FILE).read_text(encoding="utf-8"))
+        assert data["tool"] == {"name": "autodoc", "version": __version__}
+        inputs = data["inputs"]
+        assert inputs["commit"] == _git(repo, "rev-parse", "HEAD")
+        assert inputs["dirty"] is False
+        assert inputs["settings"]["formats"] == ["html"]
+        assert inputs["source_tree"] == tree_hash(repo, [repo / "shop" / "__init__.py"])
+        assert sorted(data["outputs"]) == [
+            "assets/autodoc.css",
+            "assets/highlight.css",
+            "index.html",
+            "shop.html",
+        ]
+        assert load_attestation(site).outputs == data["outputs"]
+
+    @pytest.mark.unit
+    def test_missing_attestation(self, tmp_path: Path):
+        with pytest.raises(AttestationError, match="has no .autodoc-attestation.json"):
+            load_attestation(tmp_path)
+
+
+class TestVerify:
+    """Tests for ``autodoc generate --verify``."""
+
+    @pytest.mark.unit
+    def test_unchanged_site_verifies(self, repo: Path, tmp_path: Path, capsys):
+        site = tmp_path / "site"
+        argv = ["generate", "--root", str(repo), "--output", str(site), "--timestamp"]
+        assert run_command(argv) == 0
+        # An output directory inside the checkout does not make it dirty.
+        inside = ["generate", "--root", str(repo), "--output", str(repo / "docs")]
+        assert run_command(inside) == 0
+        capsys.readouterr()
+        assert run_command([*argv, "--verify"]) == 0
+        commit = _git(repo, "rev-parse", "HEAD")[:12]
+        out = capsys.readouterr().out
+        assert f"Verified {site}: 2 file(s) match commit {commit}" in out
+        assert run_command([*inside, "--verify"]) == 0
+
+    @pytest.mark.unit
+    def test_tampered_site_and_new_commit_fail(self, repo, tmp_path, capsys):
+        site = tmp_path / "site"
+        argv = ["generate", "--root", str(repo), "--output", str(site)]
+        assert run_command(argv) == 0
+        with (site / "index.md").open("a", encoding="utf-8") as page:
+            page.write("Injected\n")
+        (repo / "shop" / "cart.py").write_text('"""Carts."""\n', encoding="utf-8")
+        capsys.readouterr()
+        assert run_command([*argv, "--verify"]) == 1
+        out = capsys.readouterr().out
+        assert f"{site}: index.md: differs from the recorded hash" in out
+        assert f"{site}: commit: the checkout has uncommitted changes" in out
+        assert f"{site}: shop.md: the rebuild has different content" in out
+        _git(repo, "add", "-A")
+        _git(repo, "commit", "-q", "-m", "Add carts")
+        assert run_command([*argv, "--verify"]) == 1
+        assert f"{site}: commit: built from " in capsys.readouterr().out
@@ -0,0 +1,124 @@
+"""Unit tests for audience tags and ``generate --audience``."""
+
+from __future__ import annotations
+
+import json
+import logging
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.parser import parse_tree
+from services.audience import (
+    audience_tags,
+    for_audience,
+    is_for,
+    parse_audience,
+    strip_audience,
+)
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with an internal module and a partner function."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(
+        '"""Carts."""\n\n\n'
+        "def total():\n"
+        '    """Total price."""\n\n\n'
+        "def rebate():\n"
+        '    """Partner rebate.\n\n'
+        '    @autodoc:audience partners, internal\n    """\n',
+        encoding="utf-8",
+    )
+    (tmp_path / "shop" / "admin.py").write_text(
+        '"""Admin tools.\n\n@autodoc:audience internal\n"""\n\n\n'
+        "def reset():\n"
+        '    """Reset.\n\n    @autodoc:audience public\n    """\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_parse_and_strip() -> None:
+    docstring = "Rotate keys.\n\n@autodoc:audience Internal, partners\n\nMore."
+    assert parse_audience(docstring) == ["internal", "partners"]
+    assert parse_audience("Untagged.") is None
+    assert parse_audience("@autodoc:audience") == []
+    assert strip_audience(docstring) == "Rotate keys.\n\nMore."
+    assert strip_audience("@autodoc:audience internal") is None
+
+
+def test_is_for(tree: Path, caplog) -> None:
+    symbols = parse_tree(tree).symbols
+    tags = audience_tags(symbols)
+    assert tags == {
+        "shop.cart.rebate": ["partners", "internal"],
+        "shop.admin": ["internal"],
+        "shop.admin.reset": ["public"],
+    }
+    assert is_for("shop.cart.total", tags, "public")
+    assert is_for("shop.cart.rebate", tags, "Partners")
+    assert not is_for("shop.cart.rebate", tags, "public")
+    # A tag inside an internal module does not publish it.
+    assert not is_for("shop.admin.reset", tags, "public")
+    assert not is_for("shop.admin.reset", tags, "internal")
+
+    (tree / "shop" / "gift.py").write_text(
+        '"""Gifts.\n\n@autodoc:audience\n"""\n',
+        encoding="utf-8",
+    )
+    with caplog.at_level(logging.WARNING):
+        assert "shop.gift" not in audience_tags(parse_tree(tree).symbols)
+    assert "Ignoring @autodoc:audience without an audience" in caplog.text
+
+
+def test_for_audience(tree: Path) -> None:
+    public = for_audience(parse_tree(tree), "public")
+    names = {symbol.qualified_name for symbol in public.symbols}
+    assert {"shop", "shop.cart", "shop.cart.total"} <= names
+    assert not names & {"shop.cart.rebate", "shop.admin", "shop.admin.reset"}
+    assert "shop.admin" not in public.graph.modules
+
+
+def test_generate(tree: Path) -> None:
+    def symbols(*extra: str) -> dict[str, str | None]:
+        output = tree / "site"
+        argv = ["generate", "--root", str(tree), "--output", str(output)]
+        assert run_command([*argv, "--format", "json", *extra]) == 0
+        data = json.loads((output / "index.json").read_text(encoding="utf-8"))
+        text = json.dumps(data)
+        assert "@autodoc:audience" not in text
+        return {
+            symbol["qualified_name"]: symbol["docstring"]
+            for symbol in _symbols(data)
+        }
+
+    everything = symbols()
+    assert everything["shop.cart.rebate"] == "Partner rebate."
+    assert "shop.admin" in everything
+    public = symbols("--audience", "public")
+    assert "shop.cart.total" in public
+    assert "shop.cart.rebate" not in public
+    assert "shop.admin" not in public
+
+
+def _symbols(data: object) -> list[dict]:
+    """Every mapping with a ``qualified_name`` nested in ``data``."""
+    found = []
+    if isinstance(data, dict):
+        if "qualified_name" in data and "docstring" in data:
+            found.append(data)
+        for value in data.values():
+            found.extend(_symbols(value))
+    elif isinstance(data, list):
+        for value in data:
+            found.extend(_symbols(value))
+    return found
+
+
+def test_invalid_audience(tree: Path) -> None:
+    with pytest.raises(SystemExit):
+        run_command(["generate", "--root", str(tree), "--audience", "a b"])
@@ -0,0 +1,163 @@
+"""Unit tests for publishing sites to S3 and Cloud Storage buckets."""
+
+import hashlib
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.bucket_publisher import (
+    PublishError,
+    S3Store,
+    open_store,
+    sync_site,
+)
+from services.cancellation import CancelToken
+
+
+class FakeStore:
+    """An in-memory bucket prefix."""
+
+    def __init__(self, prefix: str = "docs/", objects=None) -> None:
+        self.prefix = prefix
+        self.objects: dict[str, bytes] = dict(objects or {})
+        self.headers: dict[str, dict[str, str]] = {}
+        self.calls: list[str] = []
+
+    def url(self, key: str) -> str:
+        return f"s3://bucket/{key}"
+
+    def list_objects(self) -> dict[str, str]:
+        return {
+            key: hashlib.md5(data).hexdigest()
+            for key, data in self.objects.items()
+            if key.startswith(self.prefix)
+        }
+
+    def upload(self, key: str, data: bytes, headers: dict[str, str]) -> None:
+        self.calls.append(f"upload {key}")
+        self.objects[key] = data
+        self.headers[key] = headers
+
+    def delete(self, keys: list[str]) -> None:
+        self.calls.append(f"delete {' '.join(keys)}")
+        for key in keys:
+            del self.objects[key]
+
+
+@pytest.fixture
+def site(tmp_path: Path) -> Path:
+    directory = tmp_path / "site"
+    (directory / "assets").mkdir(parents=True)
+    (directory / "index.html").write_text("<h1>Index</h1>", encoding="utf-8")
+    (directory / "shop.html").write_text("<h1>Shop</h1>", encoding="utf-8")
+    (directory / "assets" / "autodoc.css").write_text("body{}", encoding="utf-8")
+    (directory / ".autodoc-files").write_text("index.html\n", encoding="utf-8")
+    (directory / ".autodoc-state.json").write_text("{}", encoding="utf-8")
+    return directory
+
+
+class TestSync:
+    """Tests for :func:`services.bucket_publisher.sync_site`."""
+
+    @pytest.mark.unit
+    def test_uploads_changes_and_deletes_removed_objects(self, site):
+        store = FakeStore(
+            objects={
+                "docs/index.html": b"<h1>Index</h1>",
+                "docs/shop.html": b"<h1>Old</h1>",
+                "docs/removed.html": b"gone",
+                "other/keep.html": b"not ours",
+            },
+        )
+        plan = sync_site(site, store)
+        assert plan.counts() == {"create": 1, "modify": 1, "delete": 1, "unchanged": 1}
+        assert store.calls == [
+            "upload docs/assets/autodoc.css",
+            "upload docs/shop.html",
+            "delete docs/removed.html",
+        ]
+        assert store.headers["docs/assets/autodoc.css"] == {
+            "ContentType": "text/css; charset=utf-8",
+        }
+        assert set(store.objects) == {
+            "docs/assets/autodoc.css",
+            "docs/index.html",
+            "docs/shop.html",
+            "other/keep.html",
+        }
+        assert plan.summary() == (
+            "Published to s3://bucket/docs/: 1 created, 1 modified, 1 deleted, "
+            "1 unchanged"
+        )
+        assert sync_site(site, store).counts()["unchanged"] == 3
+
+    @pytest.mark.unit
+    def test_dry_run_no_delete_and_force(self, site):
+        store = FakeStore(objects={"docs/index.html": b"<h1>Index</h1>", "docs/x": b""})
+        plan = sync_site(site, store, dry_run=True)
+        assert plan.lines(store.url) == [
+            "would create s3://bucket/docs/assets/autodoc.css",
+            "would create s3://bucket/docs/shop.html",
+            "would delete s3://bucket/docs/x",
+        ]
+        assert plan.summary(dry_run=True).endswith("; nothing was published")
+        assert store.calls == []
+        plan = sync_site(site, store, delete=False, force=True)
+        assert plan.counts() == {"create": 2, "modify": 1, "delete": 0, "unchanged": 0}
+        assert "docs/x" in store.objects
+
+    @pytest.mark.unit
+    def test_s3_metadata_headers_are_applied(self, site):
+        (site / "_s3-metadata.json").write_text(
+            '{"schema_version": 1, "objects": {"index.html": '
+            '{"CacheControl": "public, max-age=0, must-revalidate", '
+            '"ContentType": "text/html; charset=utf-8"}}}',
+            encoding="utf-8",
+        )
+        store = FakeStore()
+        sync_site(site, store)
+        assert store.headers["docs/index.html"]["CacheControl"] == (
+            "public, max-age=0, must-revalidate"
+        )
+        assert "docs/_s3-metadata.json" not in store.objects
+        assert "docs/.autodoc-state.json" not in store.objects
+
+    @pytest.mark.unit
+    def test_cancelled_sync_deletes_nothing(self, site):
+        store = FakeStore(objects={"docs/old.html": b""})
+        cancel = CancelToken()
+        cancel.cancel()
+        sync_site(site, store, cancel=cancel)
+        assert store.calls == []
+        assert cancel.skipped == {"publish": 4}
+
+    @pytest.mark.unit
+    def test_errors(self, tmp_path, site):
+        with pytest.raises(PublishError, match="not a generated site"):
+            sync_site(tmp_path, FakeStore())
+        with pytest.raises(PublishError, match="not a bucket URL"):
+            open_store("https://example.com/docs")
+        store = S3Store("bucket", "/api/", client=object())
+        assert store.prefix == "api/"
+        assert store.url("api/index.html") == "s3://bucket/api/index.html"
+
+
+class TestPublishCommand:
+    """Tests for ``autodoc publish``."""
+
+    @pytest.mark.unit
+    def test_publish_dry_run(self, site, monkeypatch, capsys):
+        store = FakeStore()
+        monkeypatch.setattr("autodoc.cli.publish.open_store", lambda url: store)
+        argv = ["publish", "s3://bucket/docs", "--site", str(site)]
+        assert run_command([*argv, "--dry-run"]) == 0
+        out = capsys.readouterr().out
+        assert "would create s3://bucket/docs/index.html\n" in out
+        assert out.endswith(
+            "Dry run: 3 to create, 0 to modify, 0 to delete, 0 unchanged; "
+            "nothing was published\n",
+        )
+        assert run_command(argv) == 0
+        assert len(store.objects) == 3
+        assert run_command(["publish", "s3://bucket", "--site", "missing"]) == 1
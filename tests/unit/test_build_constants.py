@@ -0,0 +1,91 @@
+"""Unit tests for documenting values set at build or deploy time."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.build_constants import find_build_constants, matches
+from services.import_graph import build_import_graph
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with version, commit, and build variables."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text(
+        '"""Shop."""\n'
+        "import importlib.metadata\n"
+        "import os\n\n"
+        '__version__ = "0.0.0.dev0"\n'
+        'BUILD_DATE = "@BUILD_DATE@"\n'
+        'COMMIT = os.environ.get("GIT_COMMIT", "unknown")\n'
+        'GIT_BRANCH: str = os.environ["GIT_BRANCH"]\n'
+        'VERSION = importlib.metadata.version("shop")\n'
+        "MIN_PYTHON_VERSION = (3, 11)\n"
+        "BUILD_NUMBER = 0\n"
+        'DEPLOY_REGION = "eu-west-1"\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_matches() -> None:
+    assert matches("__version__")
+    assert matches("GIT_SHA")
+    assert matches("Build_Time")
+    assert not matches("VERSIONS_SEEN")
+    assert matches("deploy_region", ["deploy_*"])
+
+
+def test_build_constants(tree: Path) -> None:
+    constants = find_build_constants(tree, build_import_graph(tree), ["deploy_*"])
+
+    assert [(c.name, c.source, c.default, c.origin) for c in constants] == [
+        ("__version__", "literal", "'0.0.0.dev0'", None),
+        ("BUILD_DATE", "placeholder", "'@BUILD_DATE@'", None),
+        ("COMMIT", "environment", "'unknown'", "GIT_COMMIT"),
+        ("GIT_BRANCH", "environment", None, "GIT_BRANCH"),
+        ("VERSION", "metadata", None, "shop"),
+        ("BUILD_NUMBER", "literal", "0", None),
+        ("DEPLOY_REGION", "literal", "'eu-west-1'", None),
+    ]
+    assert constants[2].setter(str) == "GIT_COMMIT environment variable"
+    assert constants[0].to_dict()["lineno"] == 5
+
+
+def test_build_page(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    packages = build_model(parsed)
+    sites = render_site(parsed, packages, ("markdown", "html", "json"))
+
+    markdown = {page.path: page.content for page in sites["markdown"]}
+    assert "[Build-time configuration](build.md)" in markdown["index.md"]
+    assert (
+        "| `shop.COMMIT` | `'unknown'` | `GIT_COMMIT` environment variable "
+        "| `shop/__init__.py:7` |\n"
+    ) in markdown["build.md"]
+    html = {page.path: page.content for page in sites["html"]}
+    assert '<table class="autodoc-build">' in html["build.html"]
+    data = json.loads(sites["json"][0].content)
+    assert [c["name"] for c in data["build_constants"]][:2] == [
+        "__version__",
+        "BUILD_DATE",
+    ]
+
+    config = ProjectConfig(
+        site=SiteConfig.from_dict({"build_constants": {"names": ["deploy_*"]}}),
+    )
+    pages = render_site(parsed, packages, ("markdown",), config)["markdown"]
+    assert "`shop.DEPLOY_REGION`" in {p.path: p.content for p in pages}["build.md"]
+    config = ProjectConfig(site=SiteConfig.from_dict({"build_constants": False}))
+    pages = render_site(parsed, packages, ("markdown",), config)["markdown"]
+    assert "build.md" not in {page.path for page in pages}
+    with pytest.raises(ProjectConfigError):
+        SiteConfig.from_dict({"build_constants": "yes"})
@@ -0,0 +1,105 @@
+"""Unit tests for timeouts and interrupt handling."""
+
+import os
+import signal
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.cancellation import (
+    EXIT_TIMED_OUT,
+    INTERRUPTED,
+    CancelToken,
+    handle_interrupts,
+)
+from services.doc_symbols import DocSymbolLoader, discover_python_files
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    """Three undocumented modules."""
+    for name in ("a", "b", "c"):
+        _write(tmp_path / f"{name}.py", "def f():\n    pass\n")
+    return tmp_path
+
+
+class TestCancelToken:
+    """Tests for the token and the stages that honor it."""
+
+    @pytest.mark.unit
+    def test_deadline_cancels(self):
+        token = CancelToken(timeout=0)
+
+        assert token.cancelled
+        assert token.reason == "timed out after 0s"
+        assert token.exit_code == EXIT_TIMED_OUT
+
+    @pytest.mark.unit
+    def test_loader_returns_partial_symbols(self, source_tree):
+        token = CancelToken()
+        loader = DocSymbolLoader(source_tree)
+        files = discover_python_files(source_tree)
+        original = loader.load_file
+
+        def load_then_cancel(path):
+            token.cancel()
+            return original(path)
+
+        loader.load_file = load_then_cancel
+        symbols = loader.load(files, cancel=token)
+
+        assert {s.qualified_name for s in symbols} == {"a", "a.f"}
+        assert token.skipped == {"parse": 2}
+        assert token.summary() == "Interrupted: skipped 2 file(s) in parse"
+
+    @pytest.mark.unit
+    def test_sigint_cancels_then_aborts(self):
+        token = CancelToken()
+        with handle_interrupts(token):
+            os.kill(os.getpid(), signal.SIGINT)
+            assert token.reason == INTERRUPTED
+            with pytest.raises(KeyboardInterrupt):
+                os.kill(os.getpid(), signal.SIGINT)
+        assert signal.getsignal(signal.SIGINT) is signal.default_int_handler
+
+
+class TestTimeoutFlag:
+    """Tests for ``--timeout`` on the commands."""
+
+    @pytest.mark.unit
+    def test_lint_reports_what_was_skipped(self, source_tree, capsys):
+        code = run_command(["lint", "--root", str(source_tree), "--timeout", "0"])
+
+        assert code == EXIT_TIMED_OUT
+        assert "skipped 3 file(s) in parse" in capsys.readouterr().err
+
+    @pytest.mark.unit
+    def test_baseline_is_not_written_from_partial_parse(self, source_tree, capsys):
+        code = run_command(
+            ["baseline", "write", "--root", str(source_tree), "--timeout", "0"],
+        )
+
+        assert code == EXIT_TIMED_OUT
+        assert not (source_tree / ".autodoc-baseline.json").exists()
+        assert "not written from a partial parse" in capsys.readouterr().err
+
+    @pytest.mark.unit
+    def test_site_is_not_replaced_from_partial_parse(self, source_tree, capsys):
+        output = source_tree / "site"
+        argv = ["generate", "--root", str(source_tree), "--output", str(output)]
+        assert run_command(argv) == 0
+        before = sorted(path.name for path in output.iterdir())
+
+        assert run_command([*argv, "--timeout", "0"]) == EXIT_TIMED_OUT
+        assert sorted(path.name for path in output.iterdir()) == before
+        assert "not written from a partial parse" in capsys.readouterr().err
+
+    @pytest.mark.unit
+    def test_generous_timeout_changes_nothing(self, source_tree):
+        assert run_command(["lint", "--root", str(source_tree), "--timeout", "60"]) == 1
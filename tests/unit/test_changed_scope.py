@@ -0,0 +1,78 @@
+"""Unit tests for diff-scoped (changed-only) enforcement."""
+
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from services.changed_scope import ChangedScope, load_changed_scope
+from services.doc_lint import lint_symbols
+from services.doc_symbols import DocSymbol, load_doc_symbols
+from services.git_source import changed_line_ranges
+
+
+def _git(repo: Path, *args: str) -> None:
+    subprocess.run(["git", *args], cwd=repo, check=True, capture_output=True)
+
+
+LEGACY = "def legacy():\n    pass\n\n\ndef stable():\n    return 1\n"
+
+
+@pytest.fixture
+def repo(tmp_path: Path) -> Path:
+    """A repository whose ``main`` branch carries legacy documentation debt."""
+    _git(tmp_path, "init", "-q", "-b", "main")
+    _git(tmp_path, "config", "user.email", "dev@example.com")
+    _git(tmp_path, "config", "user.name", "Dev")
+    (tmp_path / "mod.py").write_text(LEGACY, encoding="utf-8")
+    _git(tmp_path, "add", ".")
+    _git(tmp_path, "commit", "-q", "-m", "legacy")
+    _git(tmp_path, "checkout", "-q", "-b", "feature")
+    return tmp_path
+
+
+def _commit(repo: Path, content: str) -> None:
+    (repo / "mod.py").write_text(content, encoding="utf-8")
+    _git(repo, "commit", "-q", "-am", "change")
+
+
+class TestChangedScope:
+    """Tests for touched-symbol detection."""
+
+    @pytest.mark.unit
+    def test_only_new_symbols_are_enforced(self, repo):
+        _commit(repo, LEGACY + "\n\ndef added():\n    pass\n")
+
+        scope = load_changed_scope(repo, "main")
+        findings = lint_symbols(scope.filter(load_doc_symbols(repo)))
+
+        assert [f.symbol for f in findings] == ["mod.added"]
+
+    @pytest.mark.unit
+    def test_edited_legacy_symbol_is_enforced(self, repo):
+        _commit(repo, LEGACY.replace("return 1", "return 2"))
+
+        scope = load_changed_scope(repo, "main")
+        touched = [s.qualified_name for s in scope.filter(load_doc_symbols(repo))]
+
+        assert touched == ["mod.stable"]
+
+    @pytest.mark.unit
+    def test_pure_deletion_marks_preceding_line(self, repo):
+        _commit(repo, LEGACY.replace("    return 1\n", ""))
+        ranges = changed_line_ranges(repo, "main")
+        assert ranges == {"mod.py": [(5, 5)]}
+
+    @pytest.mark.unit
+    def test_untouched_files_are_excluded(self):
+        scope = ChangedScope(repo=Path("/repo"), ranges={"a.py": [(1, 3)]})
+        symbol = DocSymbol(
+            package="pkg",
+            name="f",
+            qualified_name="b.f",
+            kind="function",
+            file_path="/repo/b.py",
+            lineno=1,
+            end_lineno=3,
+        )
+        assert not scope.touches(symbol)
@@ -0,0 +1,163 @@
+"""Unit tests for checkpointing and resuming long generate runs."""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.checkpoint import CHECKPOINT_DIR, Checkpoint
+from services.doc_site import SitePage
+from services.doc_symbols import DocSymbolLoader
+
+
+class Killed(BaseException):
+    """Stands in for the process being killed mid-run."""
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    for package in ("billing", "shop", "users"):
+        (tmp_path / "src" / package).mkdir(parents=True)
+        (tmp_path / "src" / package / "__init__.py").write_text(
+            f'"""The {package} package."""\n\n\ndef run():\n    """Run."""\n',
+            encoding="utf-8",
+        )
+    return tmp_path
+
+
+def test_records_survive_a_reopen(tmp_path: Path) -> None:
+    directory = tmp_path / "checkpoint"
+    checkpoint = Checkpoint.open(directory, "settings")
+    assert not checkpoint.resumed
+    checkpoint.add_symbols("a.py", "1", [{"name": "a"}])
+    checkpoint.add_imports("a.py", "1", {"module": "a"})
+    checkpoint.add_symbols("b.py", "2", [])
+    checkpoint.add_imports("b.py", "old", {"module": "b"})
+    checkpoint.save_page("html", SitePage("a.html", "<p>a</p>"))
+    # A run killed while appending leaves half a line behind.
+    with (directory / "analysis.jsonl").open("a", encoding="utf-8") as handle:
+        handle.write('{"file": "c.py", "dig')
+
+    reopened = Checkpoint.open(directory, "settings")
+
+    assert reopened.resumed
+    state = reopened.analysis()
+    assert state.sources == {"a.py": "1", "b.py": "2"}
+    assert state.symbols["a.py"] == [{"name": "a"}]
+    # Imports of another version of b.py are analyzed again.
+    assert list(state.imports) == ["a.py"]
+    assert reopened.pages() == {"html": {"a.html"}}
+    [page] = reopened.load_pages("html", ["a.html"])
+    assert page.content == b"<p>a</p>"
+
+    stale = Checkpoint.open(directory, "other settings")
+    assert not stale.resumed
+    assert stale.pages() == {}
+
+
+def test_generate_resumes_an_interrupted_run(
+    tree: Path,
+    monkeypatch: pytest.MonkeyPatch,
+    capsys,
+) -> None:
+    output = tree / "site"
+    args = [
+        "generate",
+        "--root",
+        str(tree / "src"),
+        "--format",
+        "html,markdown",
+        "--output",
+        str(output),
+        "--resume",
+    ]
+    saved: list[str] = []
+    save_page = Checkpoint.save_page
+
+    def killed_after_four(self, fmt, page):
+        if len(saved) == 4:
+            raise Killed
+        save_page(self, fmt, page)
+        saved.append(f"{fmt}/{page.path}")
+
+    monkeypatch.setattr(Checkpoint, "save_page", killed_after_four)
+    with pytest.raises(Killed):
+        run_command(args)
+    assert saved == [
+        "html/billing.html",
+        "html/shop.html",
+        "html/users.html",
+        "markdown/billing.md",
+    ]
+    assert (output / CHECKPOINT_DIR / "pages" / "html" / "shop.html").is_file()
+
+    parsed: list[str] = []
+    load_file = DocSymbolLoader.load_file
+
+    def counting(self, file_path):
+        parsed.append(str(file_path))
+        return load_file(self, file_path)
+
+    def recording(self, fmt, page):
+        save_page(self, fmt, page)
+        saved.append(f"{fmt}/{page.path}")
+
+    saved.clear()
+    monkeypatch.setattr(Checkpoint, "save_page", recording)
+    monkeypatch.setattr(DocSymbolLoader, "load_file", counting)
+    assert run_command(args) == 0
+
+    # Nothing is parsed again, and billing, saved in both formats, is not
+    # rendered again.
+    assert parsed == []
+    assert saved == [
+        "html/shop.html",
+        "html/users.html",
+        "markdown/shop.md",
+        "markdown/users.md",
+    ]
+    assert (output / "html" / "users.html").is_file()
+    assert (output / "markdown" / "billing.md").is_file()
+    assert not (output / CHECKPOINT_DIR).exists()
+    capsys.readouterr()
+
+
+def test_changed_sources_are_rendered_again(
+    tree: Path,
+    monkeypatch: pytest.MonkeyPatch,
+    capsys,
+) -> None:
+    directory = tree / "checkpoint"
+    args = [
+        "generate",
+        "--root",
+        str(tree / "src"),
+        "--format",
+        "html",
+        "--output",
+        str(tree / "site"),
+        "--resume",
+        "--checkpoint-dir",
+        str(directory),
+    ]
+    assert run_command([*args, "--dry-run"]) == 0
+    assert not directory.exists()
+
+    # Leave the checkpoint of a run that rendered every page, then edit a file.
+    remove = Checkpoint.remove
+    monkeypatch.setattr(Checkpoint, "remove", lambda self: None)
+    assert run_command(args) == 0
+    monkeypatch.setattr(Checkpoint, "remove", remove)
+    (tree / "src" / "shop" / "__init__.py").write_text(
+        '"""The new shop."""\n',
+        encoding="utf-8",
+    )
+
+    assert run_command(args) == 0
+
+    html = (tree / "site" / "shop.html").read_text(encoding="utf-8")
+    assert "The new shop." in html
+    assert not directory.exists()
+    capsys.readouterr()
@@ -0,0 +1,71 @@
+"""Unit tests for CLI verbosity and log formats."""
+
+import json
+import logging
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    """One documented module and one that does not parse."""
+    (tmp_path / "ok.py").write_text('"""Fine."""\n', encoding="utf-8")
+    (tmp_path / "broken.py").write_text("def (:\n", encoding="utf-8")
+    return tmp_path
+
+
+class TestCliLogging:
+    """Tests for ``-v`` and ``--log-format``."""
+
+    @pytest.mark.unit
+    def test_default_shows_only_warnings(self, source_tree, capsys):
+        run_command(["coverage", "--root", str(source_tree)])
+
+        err = capsys.readouterr().err
+        assert "Skipping" in err
+        assert "Parsed" not in err
+        assert "Traceback" not in err
+
+    @pytest.mark.unit
+    def test_verbose_levels(self, source_tree, capsys):
+        run_command(["coverage", "--root", str(source_tree), "-v"])
+        info = capsys.readouterr().err
+        run_command(["coverage", "--root", str(source_tree), "-vv"])
+        debug = capsys.readouterr().err
+
+        assert "[INFO] services.doc_symbols: Parsed 2 file(s)" in info
+        assert "coverage finished in" in info
+        assert "Successfully parsed" not in info
+        assert "Successfully parsed" in debug
+
+    @pytest.mark.unit
+    def test_json_records_carry_fields(self, source_tree, capsys):
+        run_command(
+            ["coverage", "--root", str(source_tree), "-v", "--log-format", "json"],
+        )
+
+        records = [json.loads(line) for line in capsys.readouterr().err.splitlines()]
+        parsed = next(r for r in records if r["message"].startswith("Parsed"))
+        assert parsed["files"] == 2
+        assert parsed["level"] == "INFO"
+
+    @pytest.mark.unit
+    def test_nested_commands_accept_flags(self, source_tree, capsys):
+        root = str(source_tree)
+        output = str(source_tree / "baseline.json")
+        run_command(["baseline", "write", "--root", root, "-v", "--output", output])
+
+        assert "baseline finished in" in capsys.readouterr().err
+
+    @pytest.mark.unit
+    def test_logging_is_restored(self, source_tree):
+        root = logging.getLogger()
+        handlers, level = list(root.handlers), root.level
+
+        run_command(["coverage", "--root", str(source_tree), "-vv"])
+
+        assert root.handlers == handlers
+        assert root.level == level
@@ -0,0 +1,144 @@
+"""Unit tests for checking the code examples of docstrings and guides."""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.code_examples import (
+    CodeExample,
+    check_example,
+    check_examples,
+    extract_examples,
+    find_examples,
+)
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with working and broken examples, and a guide."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(
+        "def total(prices):\n"
+        '    """Sum of ``prices``.\n'
+        "\n"
+        "    >>> total([1, 2])\n"
+        "    3\n"
+        "\n"
+        "    .. code-block:: python\n"
+        "\n"
+        "        total([1,, 2])\n"
+        '    """\n'
+        "    return sum(prices)\n",
+        encoding="utf-8",
+    )
+    guides = tmp_path / "docs" / "guides"
+    guides.mkdir(parents=True)
+    (guides / "start.md").write_text(
+        "# Start\n"
+        "\n"
+        "```python\n"
+        "from shop.cart import total\n"
+        "print(totl([1]))\n"
+        "```\n"
+        "\n"
+        "```python nocheck\n"
+        "cart = ...  # and so on\n"
+        "```\n"
+        "\n"
+        "```bash\n"
+        "pip install shop\n"
+        "```\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_extract_examples() -> None:
+    text = (
+        "Summary.\n"
+        "\n"
+        ">>> 1 + 1\n"
+        "2\n"
+        "\n"
+        "```pycon\n"
+        ">>> print('hi')\n"
+        "hi\n"
+        "```\n"
+        "\n"
+        ".. code:: python\n"
+        "   :linenos:\n"
+        "\n"
+        "   x = 1\n"
+        "\n"
+        "::\n"
+        "\n"
+        "   not checked\n"
+    )
+    assert list(extract_examples(text)) == [
+        (2, ">>> 1 + 1\n2\n", True),
+        (6, ">>> print('hi')\nhi\n", True),
+        (13, "x = 1\n", False),
+    ]
+
+
+def test_find_examples(tree: Path) -> None:
+    packages = build_model(parse_tree(tree))
+    examples = find_examples(packages, tree / "docs" / "guides", tree)
+    assert [(e.source, e.file_path, e.lineno, e.session) for e in examples] == [
+        ("shop.cart.total", "shop/cart.py", 4, True),
+        ("shop.cart.total", "shop/cart.py", 9, False),
+        ("start.md", "docs/guides/start.md", 4, False),
+    ]
+
+
+def test_compile_failures(tree: Path) -> None:
+    packages = build_model(parse_tree(tree))
+    examples = find_examples(packages, tree / "docs" / "guides", tree)
+    assert [f.format() for f in check_examples(examples)] == [
+        "shop/cart.py:9: example of shop.cart.total does not compile: "
+        "invalid syntax",
+    ]
+
+
+def test_run_failures(tree: Path) -> None:
+    packages = build_model(parse_tree(tree))
+    examples = find_examples(packages, tree / "docs" / "guides", tree)
+    failures = check_examples(examples, run=True, root=tree)
+    assert [(f.example.source, f.lineno) for f in failures] == [
+        ("shop.cart.total", 9),
+        ("start.md", 5),
+    ]
+    assert failures[1].message.startswith(
+        "raised NameError: name 'totl' is not defined",
+    )
+
+    wrong = CodeExample("shop", "shop.py", 10, ">>> 1 + 1\n3\n>>> 2\n2\n", True)
+    failure = check_example(wrong, run=True, root=tree)
+    assert failure is not None
+    assert (failure.lineno, failure.message) == (10, "printed '2' instead of '3'")
+    skipped = CodeExample("shop", "shop.py", 1, ">>> 1 +  # doctest: +SKIP\n", True)
+    assert check_example(skipped, run=True, root=tree) is None
+
+
+def test_generate_fails_on_broken_examples(tree: Path, capsys) -> None:
+    output = tree / "site"
+    argv = ["generate", "--root", str(tree), "--output", str(output)]
+    (tree / "autodoc.yaml").write_text("site:\n  examples: compile\n", encoding="utf-8")
+    assert run_command(argv) == 1
+    err = capsys.readouterr().err
+    assert "shop/cart.py:9: example of shop.cart.total does not compile" in err
+    assert "Error: 1 of 3 code example(s) are broken" in err
+    assert not output.exists()
+
+    (tree / "autodoc.yaml").write_text("site:\n  examples: off\n", encoding="utf-8")
+    assert run_command(argv) == 0
+    assert SiteConfig().examples == "off"
+    with pytest.raises(ProjectConfigError, match="site.examples"):
+        SiteConfig.from_dict({"examples": "always"})
@@ -0,0 +1,125 @@
+"""Unit tests for the code generation inventory and its page."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.code_generation import command_outputs, find_code_generation
+from services.import_graph import build_import_graph
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with protoc and codegen directives and their output."""
+    _write(
+        tmp_path / "shop" / "__init__.py",
+        '"""Shop."""\n'
+        "# autodoc:generate protoc --python_out=api --pyi_out=api shop.proto\n",
+    )
+    _write(
+        tmp_path / "shop" / "models.py",
+        '"""Data models."""\n'
+        "# autodoc:generate datamodel-codegen --input schema.json "
+        "--output=generated_models.py\n"
+        "# generated models are re-exported below\n"
+        'TEXT = "# autodoc:generate not-a-directive"\n',
+    )
+    _write(tmp_path / "shop" / "api" / "__init__.py", "")
+    _write(
+        tmp_path / "shop" / "api" / "shop_pb2.py",
+        "# -*- coding: utf-8 -*-\n"
+        "# Generated by the protocol buffer compiler.  DO NOT EDIT!\n"
+        '"""Protocol buffers."""\n',
+    )
+    _write(
+        tmp_path / "shop" / "generated_models.py",
+        "# generated by datamodel-codegen:\n#   filename:  schema.json\n",
+    )
+    _write(tmp_path / "shop" / "lexer.py", '# @generated\n"""Lexer."""\n')
+    _write(
+        tmp_path / "shop" / "publisher.py",
+        '"""Publishes docs.\n\nThe bucket layout was generated with care.\n"""\n'
+        "# Placeholders are auto-generated below.\n",
+    )
+    return tmp_path
+
+
+def test_command_outputs() -> None:
+    assert command_outputs("protoc --python_out=pyi_out:gen x.proto") == ["gen"]
+    assert command_outputs("stringer -o kinds.py --type Kind") == ["kinds.py"]
+    assert command_outputs("python -m tool --outfile=a.py > b.py") == [
+        "a.py",
+        "b.py",
+    ]
+    assert command_outputs("gen >c.py") == ["c.py"]
+    assert command_outputs("gen --output") == []
+
+
+def test_directives_and_generated_files(tree: Path) -> None:
+    generation = find_code_generation(tree, build_import_graph(tree))
+
+    assert [
+        (d.tool, d.file_path, d.lineno, d.outputs, d.generated)
+        for d in generation.directives
+    ] == [
+        (
+            "protoc",
+            "shop/__init__.py",
+            2,
+            ("shop/api", "shop/api"),
+            ("shop/api/shop_pb2.py",),
+        ),
+        (
+            "datamodel-codegen",
+            "shop/models.py",
+            2,
+            ("shop/generated_models.py",),
+            ("shop/generated_models.py",),
+        ),
+    ]
+    assert [(g.file_path, g.lineno, g.tool) for g in generation.generated] == [
+        ("shop/api/shop_pb2.py", 2, "protocol buffer compiler"),
+        ("shop/generated_models.py", 1, "datamodel-codegen"),
+        ("shop/lexer.py", 1, None),
+    ]
+    assert generation.generated[0].module == "shop.api.shop_pb2"
+
+
+def test_generation_page(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    packages = build_model(parsed)
+    sites = render_site(parsed, packages, ("markdown", "html", "json"))
+
+    markdown = {page.path: page.content for page in sites["markdown"]}
+    assert "[Code generation](generation.md)" in markdown["index.md"]
+    page = markdown["generation.md"]
+    assert page.startswith("# Code generation\n")
+    assert (
+        "- `datamodel-codegen --input schema.json --output=generated_models.py` "
+        "(`shop/models.py:2`)\n"
+        "  - Writes `shop/generated_models.py`\n"
+        "  - Generated files: `shop/generated_models.py`\n"
+    ) in page
+    assert page.endswith("- `shop/lexer.py` ([`shop.lexer`](shop.md#shoplexer))\n")
+    html = {page.path: page.content for page in sites["html"]}
+    assert "<h2>Generated files</h2>" in html["generation.html"]
+    data = json.loads(sites["json"][0].content)
+    assert data["generation"]["directives"][0]["tool"] == "protoc"
+
+    config = ProjectConfig(site=SiteConfig.from_dict({"generation": False}))
+    pages = render_site(parsed, packages, ("markdown",), config)["markdown"]
+    assert "generation.md" not in {page.path for page in pages}
+    with pytest.raises(ProjectConfigError):
+        SiteConfig.from_dict({"generation": "yes"})
@@ -0,0 +1,170 @@
+"""Unit tests for config-defined lint rules and their expression language."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import (
+    LintConfig,
+    ProjectConfigError,
+    load_project_config,
+)
+from services.custom_lint_rules import (
+    RuleConfigError,
+    rule_from_dict,
+    rules_from_config,
+)
+from services.doc_lint import lint_symbols
+from services.doc_symbols import DocSymbol
+from services.rule_expressions import ExpressionError, compile_expression
+
+
+def _symbol(name: str, docstring: str | None = None, **kwargs) -> DocSymbol:
+    defaults = {
+        "package": "pkg",
+        "name": name.rsplit(".", 1)[-1],
+        "qualified_name": name,
+        "kind": "function",
+        "file_path": "pkg/mod.py",
+        "lineno": 1,
+        "docstring": docstring,
+    }
+    defaults.update(kwargs)
+    return DocSymbol(**defaults)
+
+
+RAISES_RULE = {
+    "id": "document-raises",
+    "when": 'exported and kind in ["function", "method"] and raises',
+    "require": 'matches(docstring, "Raises:")',
+    "message": "{qualified_name} raises {raises} but has no Raises: section",
+}
+
+
+class TestRuleExpressions:
+    """Tests for compiling and evaluating rule expressions."""
+
+    @pytest.mark.unit
+    def test_evaluates_fields_and_helpers(self):
+        symbol = _symbol("pkg.mod.get_user", "Fetch a user.")
+        assert compile_expression('matches(name, "^get_") and documented').matches(
+            symbol,
+        )
+        assert not compile_expression("len(docstring) > 20").matches(symbol)
+        assert compile_expression('not (kind == "class")').matches(symbol)
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        "source",
+        [
+            "__import__('os')",
+            "name.upper()",
+            "[x for x in raises]",
+            "lambda: 1",
+            "unknown_field",
+            "name +",
+            'matches(docstring, "[")',
+        ],
+    )
+    def test_rejects_unsafe_or_invalid_expressions(self, source):
+        with pytest.raises(ExpressionError):
+            compile_expression(source)
+
+
+class TestExpressionRule:
+    """Tests for custom rules built from config entries."""
+
+    @pytest.mark.unit
+    def test_flags_symbols_failing_require(self):
+        rule = rule_from_dict(RAISES_RULE)
+        undocumented = _symbol(
+            "pkg.mod.load",
+            "Load it.",
+            metadata={"raises": ["ValueError"]},
+        )
+        documented = _symbol(
+            "pkg.mod.save",
+            "Save it.\n\nRaises:\n    OSError: on failure",
+            metadata={"raises": ["OSError"]},
+        )
+        silent = _symbol("pkg.mod.ping", "Ping.")
+
+        findings = lint_symbols([undocumented, documented, silent], [rule])
+
+        assert [f.symbol for f in findings] == ["pkg.mod.load"]
+        assert findings[0].rule == "document-raises"
+        assert findings[0].message == (
+            "pkg.mod.load raises ['ValueError'] but has no Raises: section"
+        )
+
+    @pytest.mark.unit
+    def test_invalid_rule_definitions(self):
+        with pytest.raises(RuleConfigError):
+            rule_from_dict({"id": "Bad Id", "require": "documented"})
+        with pytest.raises(RuleConfigError):
+            rule_from_dict({"id": "no-require"})
+        with pytest.raises(RuleConfigError):
+            rule_from_dict({"id": "typo", "require": "documented", "wen": "x"})
+        with pytest.raises(RuleConfigError):
+            rule_from_dict({"id": "unsafe", "require": "open('x')"})
+        with pytest.raises(RuleConfigError, match="Rule bad-regex: Invalid regular"):
+            rule_from_dict({"id": "bad-regex", "require": 'matches(docstring, "[")'})
+
+    @pytest.mark.unit
+    def test_evaluation_errors_name_the_rule(self, tmp_path: Path, capsys):
+        rule = rule_from_dict({"id": "bad-compare", "require": 'lineno > "x"'})
+        with pytest.raises(RuleConfigError, match="Rule bad-compare on pkg.mod.f"):
+            lint_symbols([_symbol("pkg.mod.f", "F.")], [rule])
+
+        (tmp_path / "pkg").mkdir()
+        (tmp_path / "pkg" / "__init__.py").write_text('"""Pkg."""\n', encoding="utf-8")
+        (tmp_path / "autodoc.yaml").write_text(
+            "lint:\n  rules:\n    - id: bad-compare\n      require: lineno > 'x'\n",
+            encoding="utf-8",
+        )
+        assert run_command(["lint", "--root", str(tmp_path)]) == 1
+        assert "Error: Rule bad-compare on pkg" in capsys.readouterr().err
+
+    @pytest.mark.unit
+    def test_rules_from_config_disables_builtins(self):
+        rules = rules_from_config(
+            LintConfig(disable=["missing-docstring"], rules=[RAISES_RULE]),
+        )
+        assert [rule.id for rule in rules] == ["document-raises"]
+
+        with pytest.raises(RuleConfigError):
+            rules_from_config(
+                LintConfig(rules=[{"id": "missing-docstring", "require": "documented"}]),
+            )
+
+
+class TestProjectConfig:
+    """Tests for loading autodoc.yaml."""
+
+    @pytest.mark.unit
+    def test_missing_file_yields_defaults(self, tmp_path: Path):
+        config = load_project_config(tmp_path)
+        assert config.path is None
+        assert config.lint == LintConfig()
+
+    @pytest.mark.unit
+    def test_loads_lint_section(self, tmp_path: Path):
+        (tmp_path / "autodoc.yaml").write_text(
+            "lint:\n"
+            "  disable: [missing-docstring]\n"
+            "  rules:\n"
+            "    - id: short-docs\n"
+            "      require: len(docstring) >= 10\n",
+            encoding="utf-8",
+        )
+        config = load_project_config(tmp_path)
+        assert config.lint.disable == ["missing-docstring"]
+        assert config.lint.rules[0]["id"] == "short-docs"
+
+    @pytest.mark.unit
+    def test_rejects_malformed_config(self, tmp_path: Path):
+        path = tmp_path / "autodoc.yaml"
+        path.write_text("lint:\n  rules: not-a-list\n", encoding="utf-8")
+        with pytest.raises(ProjectConfigError):
+            load_project_config(tmp_path)
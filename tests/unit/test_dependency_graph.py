@@ -0,0 +1,148 @@
+"""Unit tests for the dependency injection graph."""
+
+from pathlib import Path
+
+import pytest
+
+from services.dependency_graph import build_dependency_graph
+from services.doc_markdown import render_dependencies_markdown
+from services.import_graph import build_import_graph
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+def _graph(root: Path):
+    return build_dependency_graph(root, build_import_graph(root))
+
+
+@pytest.fixture
+def fastapi_app(tmp_path: Path) -> Path:
+    """Routes injecting a session directly, through an alias, and as a class."""
+    _write(
+        tmp_path / "app" / "deps.py",
+        "from typing import Annotated\n"
+        "from fastapi import Depends\n\n"
+        "def get_db():\n"
+        "    pass\n\n"
+        "def get_user(db=Depends(get_db)):\n"
+        "    pass\n\n"
+        "UserDep = Annotated[dict, Depends(get_user)]\n",
+    )
+    _write(
+        tmp_path / "app" / "routes.py",
+        "from typing import Annotated\n"
+        "from fastapi import APIRouter, Depends\n"
+        "from app.deps import UserDep, get_db\n"
+        "from app.settings import Settings\n\n"
+        "router = APIRouter(dependencies=[Depends(get_db)])\n\n"
+        "def me(user: UserDep):\n"
+        "    pass\n\n"
+        "def config(settings: Annotated[Settings, Depends()]):\n"
+        "    pass\n\n"
+        '@router.get("/items", dependencies=[Depends(get_user_agent)])\n'
+        "def items(db=Depends(get_db)):\n"
+        "    pass\n\n"
+        "def get_user_agent():\n"
+        "    pass\n",
+    )
+    return tmp_path
+
+
+class TestFastAPIDepends:
+    """Tests for ``Depends`` injections."""
+
+    @pytest.mark.unit
+    def test_injections(self, fastapi_app):
+        graph = _graph(fastapi_app)
+        edges = {(i.consumer, i.provider, i.parameter) for i in graph.injections}
+
+        assert edges == {
+            ("app.deps.get_user", "app.deps.get_db", "db"),
+            ("app.routes.router", "app.deps.get_db", None),
+            ("app.routes.me", "app.deps.get_user", "user"),
+            ("app.routes.config", "app.settings.Settings", "settings"),
+            ("app.routes.items", "app.routes.get_user_agent", None),
+            ("app.routes.items", "app.deps.get_db", "db"),
+        }
+
+    @pytest.mark.unit
+    def test_provider_locations_and_queries(self, fastapi_app):
+        graph = _graph(fastapi_app)
+        provider = graph.providers["app.deps.get_db"]
+
+        assert (provider.kind, provider.file_path, provider.lineno) == (
+            "dependency",
+            "app/deps.py",
+            4,
+        )
+        # Providers outside the tree have no location.
+        assert graph.providers["app.settings.Settings"].file_path is None
+        consumers = [i.consumer for i in graph.consumers_of("app.deps.get_db")]
+        assert consumers == [
+            "app.deps.get_user",
+            "app.routes.items",
+            "app.routes.router",
+        ]
+        assert graph.dependencies_of("app.routes.items") == [
+            "app.deps.get_db",
+            "app.routes.get_user_agent",
+        ]
+
+
+class TestContainers:
+    """Tests for ``dependency_injector`` containers."""
+
+    @pytest.mark.unit
+    def test_container_providers_and_markers(self, tmp_path):
+        _write(
+            tmp_path / "svc" / "container.py",
+            "from dependency_injector import containers, providers\n"
+            "from svc.db import Database\n"
+            "from svc.users import UserService\n\n"
+            "class Container(containers.DeclarativeContainer):\n"
+            "    db = providers.Singleton(Database, url='sqlite://')\n"
+            "    users = providers.Factory(UserService, session=db.provided.session)\n",
+        )
+        _write(
+            tmp_path / "svc" / "handlers.py",
+            "from dependency_injector.wiring import Provide, inject\n"
+            "from svc.container import Container\n\n"
+            "@inject\n"
+            "def handle(users=Provide[Container.users]):\n"
+            "    pass\n",
+        )
+        graph = _graph(tmp_path)
+
+        users = graph.providers["svc.container.Container.users"]
+        assert (users.kind, users.provides, users.lineno) == (
+            "Factory",
+            "svc.users.UserService",
+            7,
+        )
+        assert graph.dependencies_of("svc.container.Container.users") == [
+            "svc.container.Container.db",
+        ]
+        assert graph.dependencies_of("svc.handlers.handle") == [
+            "svc.container.Container.users",
+        ]
+
+
+class TestDependenciesPage:
+    """Tests for the Markdown dependency page."""
+
+    @pytest.mark.unit
+    def test_renders_diagram_and_providers(self, fastapi_app):
+        page = render_dependencies_markdown(
+            _graph(fastapi_app),
+            {"app.deps.get_db": "app.md#get_db"},
+        )
+
+        assert '  n0["app.deps.get_user"] --> n1["app.deps.get_db"]' in page
+        assert (
+            "### `app.deps.get_db`\n\nDependency defined at `app/deps.py:4`." in page
+        )
+        assert "- `app.routes.items` as `db`, `app/routes.py:15`" in page
+        assert "Depends on [`app.deps.get_db`](app.md#get_db)." in page
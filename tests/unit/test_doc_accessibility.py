@@ -0,0 +1,202 @@
+"""Unit tests for the accessibility audit of HTML sites and themes."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import SiteConfig, ThemeConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.doc_accessibility import (
+    audit_page,
+    audit_site,
+    audit_theme,
+    contrast_ratio,
+)
+from services.doc_html import render_html_site
+from services.doc_theme import build_theme_assets
+
+SOURCES = {
+    "shop/__init__.py": '"""Shop."""\n',
+    "shop/cart.py": (
+        '"""Carts."""\n\n\n'
+        "class Cart:\n"
+        '    """A cart."""\n\n'
+        "    def add(self, item):\n"
+        '        """Add an item."""\n\n\n'
+        "def checkout(cart):\n"
+        '    """Pay for a cart."""\n'
+    ),
+}
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    root = tmp_path / "src"
+    for name, content in SOURCES.items():
+        path = root / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content, encoding="utf-8")
+    return root
+
+
+def _pages(root: Path, **kwargs) -> dict[str, str]:
+    tree = parse_tree(root)
+    sites = render_site(tree, build_model(tree), ("html",), **kwargs)
+    return {page.path: page.content for page in sites["html"]}
+
+
+def _rules(findings) -> list[tuple[str, str]]:
+    return [(finding.rule, finding.location) for finding in findings]
+
+
+class TestBuiltInSite:
+    """The built-in layout and theme pass the audit."""
+
+    @pytest.mark.unit
+    def test_landmarks_skip_link_and_toc(self, source_tree):
+        pages = _pages(source_tree, language="pt_BR")
+        html = pages["shop.html"]
+        assert '<html lang="pt-BR">' in html
+        assert (
+            '<body>\n<a class="autodoc-skip" href="#content">Skip to content</a>'
+        ) in html
+        assert '<main class="autodoc-main" id="content" tabindex="-1">' in html
+        assert (
+            '<nav class="autodoc-toc" aria-label="Contents">\n<ul>\n'
+            '<li><a href="#shop"><code>shop</code></a></li>\n'
+            '<li><a href="#shop.cart"><code>shop.cart</code></a>\n<ul>\n'
+            '<li><a href="#shop.cart.checkout"><code>checkout</code></a></li>\n'
+            '<li><a href="#shop.cart.Cart"><code>Cart</code></a></li>\n'
+            "</ul>\n</li>\n</ul>\n</nav>"
+        ) in html
+        assert ":focus-visible" in pages["assets/autodoc.css"]
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize("mode", ["light", "dark", "auto"])
+    def test_default_theme_has_no_findings(self, source_tree, tmp_path, mode):
+        site = SiteConfig(theme=ThemeConfig(mode=mode))
+        tree = parse_tree(source_tree)
+        pages = render_html_site(
+            build_model(tree),
+            site,
+            build_theme_assets(site.theme, tmp_path),
+        )
+        assert audit_site(pages, site.theme, tmp_path) == []
+
+    @pytest.mark.unit
+    def test_contrast_ratio(self):
+        assert contrast_ratio("#000", "#ffffff") == pytest.approx(21)
+        assert contrast_ratio("#777777", "#777777") == pytest.approx(1)
+        assert contrast_ratio("#ffffff", "#0969da") == contrast_ratio(
+            "#0969da",
+            "#ffffff",
+        )
+
+
+class TestThemeAudit:
+    """Tests for :func:`services.doc_accessibility.audit_theme`."""
+
+    @pytest.mark.unit
+    def test_colours_and_custom_css(self, tmp_path):
+        (tmp_path / "brand.css").write_text(
+            "/* Brand\n   colours */\n"
+            ":root { --autodoc-muted: #aaaaaa; }\n"
+            "@media (prefers-color-scheme: dark) {\n"
+            "  :root { --autodoc-bg: #222; }\n"
+            "}\n"
+            "@media print { :root { --autodoc-fg: #eeeeee; } }\n"
+            ".banner { color: #ffffff; background-color: #ffdd00; }\n"
+            "a:focus { outline: none; }\n"
+            "a:focus:not(:focus-visible) { outline: 0; }\n"
+            "a.autodoc-skip { display: none !important; }\n",
+            encoding="utf-8",
+        )
+        theme = ThemeConfig(primary_color="#d4351c", custom_css=["brand.css"])
+        findings = audit_theme(theme, tmp_path)
+        assert _rules(findings) == [
+            ("contrast", "brand.css:3"),
+            ("contrast", "brand.css:8"),
+            ("contrast", "brand.css:8"),
+            ("focus-indicator", "brand.css:9"),
+            ("skip-link", "brand.css:11"),
+            ("contrast", "site.theme.primary_color"),
+        ]
+        assert findings[0].message == (
+            "contrast of muted text is 2.32:1 (#aaaaaa on #ffffff, light "
+            "palette); WCAG AA needs 4.5:1"
+        )
+        assert "'.banner'" in findings[1].message
+        assert "(#d4351c on #222, dark palette)" in findings[-1].message
+
+    @pytest.mark.unit
+    def test_header_and_footer_html(self, tmp_path):
+        theme = ThemeConfig(
+            header_html='<a href="/"><img src="logo.png"></a><h1>Docs</h1>',
+            footer_html='<a href="/help" tabindex="3"></a>',
+        )
+        findings = audit_theme(theme, tmp_path)
+        assert _rules(findings) == [
+            ("link-text", "site.theme.footer_html:1"),
+            ("tabindex", "site.theme.footer_html:1"),
+            ("heading-order", "site.theme.header_html"),
+            ("image-alt", "site.theme.header_html:1"),
+            ("link-text", "site.theme.header_html:1"),
+        ]
+        # Each is reported once, however many pages repeat the header.
+        site = SiteConfig(theme=theme)
+        pages = render_html_site([], site, build_theme_assets(theme, tmp_path))
+        pages += [p for p in pages if p.path == "index.html"]
+        assert len(audit_site(pages, theme, tmp_path)) == len(findings) + 1
+
+
+class TestPageAudit:
+    """Tests for :func:`services.doc_accessibility.audit_page`."""
+
+    @pytest.mark.unit
+    def test_structural_problems(self):
+        html = (
+            "<html>\n<body>\n"
+            '<a href="#top">Top</a>\n'
+            '<main id="main">\n<h2 id="a">A</h2>\n<h1>B</h1>\n'
+            '<h3 id="a">C</h3>\n<h1>D</h1>\n</main>\n'
+            "</body>\n</html>\n"
+        )
+        findings = audit_page("p.html", html)
+        assert [(f.rule, f.location) for f in findings] == [
+            ("lang", "p.html:1"),
+            ("skip-link", "p.html:1"),
+            ("heading-order", "p.html:5"),
+            ("duplicate-id", "p.html:7"),
+            ("heading-order", "p.html:7"),
+            ("heading-order", "p.html:8"),
+        ]
+        assert [f.message for f in findings[2:]] == [
+            "<h2> follows no heading, skipping a level",
+            "id 'a' is also used on line 5",
+            "<h3> follows <h1>, skipping a level",
+            "page has more than one <h1>",
+        ]
+
+
+class TestGenerateAudit:
+    """Tests for ``autodoc generate --audit``."""
+
+    @pytest.mark.unit
+    def test_audit_reports_and_writes_nothing(self, source_tree, tmp_path, capsys):
+        output = tmp_path / "site"
+        argv = ["generate", "--root", str(source_tree), "--output", str(output)]
+        assert run_command([*argv, "--format", "html", "--audit"]) == 0
+        assert "No accessibility problems in 3 page(s)" in capsys.readouterr().out
+        assert not output.exists()
+
+        (source_tree / "autodoc.yaml").write_text(
+            "site:\n  theme:\n    footer_html: <img src=badge.svg>\n",
+            encoding="utf-8",
+        )
+        assert run_command([*argv, "--format", "html", "--audit"]) == 1
+        assert "site.theme.footer_html:1: [image-alt]" in capsys.readouterr().out
+        assert run_command([*argv, "--audit"]) == 1
+        assert "add html to --format" in capsys.readouterr().err
@@ -0,0 +1,129 @@
+"""Unit tests for page-view analytics."""
+
+from __future__ import annotations
+
+import json
+import logging
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import AnalyticsConfig, ProjectConfigError, SiteConfig
+from services.doc_analytics import (
+    SCRIPT_PATH,
+    SERVE_BEACON,
+    PageView,
+    ViewLog,
+    analytics_head,
+    analytics_pages,
+    load_views,
+    most_viewed,
+    parse_view,
+    render_report,
+)
+from services.doc_server import CheckoutSource, DocServer
+
+
+def test_config() -> None:
+    site = SiteConfig.from_dict({"analytics": {"beacon": "https://stats.acme.io/v"}})
+    assert site.analytics.enabled
+    assert not SiteConfig().analytics.enabled
+    with pytest.raises(ProjectConfigError, match="must be an absolute path"):
+        SiteConfig.from_dict({"analytics": {"beacon": "beacon"}})
+    with pytest.raises(ProjectConfigError, match="site.analytics must be a mapping"):
+        SiteConfig.from_dict({"analytics": "yes"})
+
+
+def test_head_and_script() -> None:
+    assert analytics_head(AnalyticsConfig()) == ""
+    assert analytics_pages(AnalyticsConfig()) == []
+    config = AnalyticsConfig(snippet='<script src="x.js"></script>', beacon="/v?")
+    head = analytics_head(config)
+    assert head.startswith('<script src="x.js"></script>\n')
+    assert f'<script src="{SCRIPT_PATH}" data-beacon="/v?" defer>' in head
+    [script] = analytics_pages(config)
+    assert "doNotTrack" in script.content
+
+
+def test_record_and_rank(tmp_path: Path, caplog) -> None:
+    log = ViewLog(tmp_path / "views.jsonl")
+    for symbol in ["shop.cart.Cart", "shop.cart.Cart", "billing.total", "faq", ""]:
+        assert log.record(json.dumps({"page": "shop.html", "symbol": symbol}).encode())
+    assert not log.record(b'{"page": ""}')
+    assert not log.record(b"\xff")
+    with log.path.open("a", encoding="utf-8") as file:
+        file.write("not json\n")
+
+    with caplog.at_level(logging.WARNING):
+        views = load_views([log.path])
+    assert "Skipping" in caplog.text
+    assert len(views) == 5
+    assert views[0].time.endswith("+00:00")
+    assert parse_view({"page": "a.html", "symbol": 3}) is None
+
+    symbols, pages = most_viewed(views, top=2)
+    assert [(c.name, c.views) for c in symbols] == [
+        ("shop.cart.Cart", 2),
+        ("billing.total", 1),
+    ]
+    assert [(c.name, c.views) for c in pages] == [("shop.html", 5)]
+    documented = {"shop.cart.Cart": True, "billing.total": False}
+    symbols, _ = most_viewed(views, documented)
+    assert [c.documented for c in symbols] == [True, False]
+
+    text = render_report(symbols, pages, len(views))
+    assert "       2  shop.cart.Cart  documented\n" in text
+    assert "billing.total   undocumented" in text
+    markdown = render_report(symbols, pages, len(views), markdown=True)
+    assert "| `billing.total` | 1 | undocumented |" in markdown
+
+
+def test_serve_records_views(tmp_path: Path) -> None:
+    root = tmp_path / "shop"
+    (root / "shop").mkdir(parents=True)
+    (root / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (root / "autodoc.yaml").write_text(
+        "site:\n  analytics:\n    snippet: <script>track()</script>\n",
+        encoding="utf-8",
+    )
+    log = ViewLog(tmp_path / "views.jsonl")
+    server = DocServer([CheckoutSource(root, "shop")], analytics=log)
+    body = server.respond("/_sites/shop/shop.html")[2]
+    assert f'data-beacon="{SERVE_BEACON}"'.encode() in body
+    assert b"track()" not in body
+    assert server.respond(f"/_sites/shop/{SCRIPT_PATH}")[0] == 200
+
+    view = b'{"page": "shop.html", "symbol": "shop"}'
+    assert server.respond_post(SERVE_BEACON, view)[0] == 204
+    assert server.respond_post(SERVE_BEACON, b"[]")[0] == 400
+    assert server.respond_post("/elsewhere", view)[0] == 404
+    assert load_views([log.path]) == [
+        PageView("shop.html", "shop", load_views([log.path])[0].time),
+    ]
+    assert DocServer([]).respond_post(SERVE_BEACON, view)[0] == 404
+
+
+def test_command(tmp_path: Path, capsys) -> None:
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text(
+        '"""Shop."""\n\n\ndef pay():\n    pass\n',
+        encoding="utf-8",
+    )
+    log = tmp_path / "views.jsonl"
+    lines = [
+        {"page": "shop.html", "symbol": "shop.pay"},
+        {"page": "shop.html", "symbol": "shop.pay"},
+        {"page": "shop.html", "symbol": "usage"},
+    ]
+    log.write_text("".join(json.dumps(v) + "\n" for v in lines), encoding="utf-8")
+
+    assert run_command(["analytics", str(log)]) == 0
+    assert "usage" in capsys.readouterr().out
+    argv = ["analytics", str(log), "--root", str(tmp_path), "--format", "json"]
+    assert run_command(argv) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert data["views"] == 3
+    assert data["symbols"] == [{"name": "shop.pay", "views": 2, "documented": False}]
+    assert run_command(["analytics", str(tmp_path / "missing.jsonl")]) == 1
+    assert "Error:" in capsys.readouterr().err
@@ -0,0 +1,221 @@
+"""Unit tests for images and diagrams referenced from docstrings."""
+
+import sys
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import DiagramConfig, ProjectConfig, ProjectConfigError
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.doc_assets import AssetError, DiagramRenderer, resolve_assets
+from services.doc_html import render_docstring
+from services.doc_site import ModuleDoc, PackageDoc
+from services.doc_symbols import DocSymbol
+
+PNG = b"\x89PNG\r\n\x1a\nfake"
+
+# Stand-ins for the diagram tools: they echo the source inside an <svg>.
+FAKE_PLANTUML = (
+    "import sys\n"
+    "source = sys.stdin.read()\n"
+    "sys.stdout.write(f'<svg>{sys.argv[1]} {source.strip()}</svg>')\n"
+)
+FAKE_MMDC = (
+    "import sys\n"
+    "args = dict(zip(sys.argv[1::2], sys.argv[2::2]))\n"
+    "source = open(args['-i']).read()\n"
+    "open(args['-o'], 'w').write(f'<svg>mermaid {source.strip()}</svg>')\n"
+)
+
+
+def _package(docstring: str, file_path: Path) -> PackageDoc:
+    symbol = DocSymbol(
+        package="pkg",
+        name="mod",
+        qualified_name="pkg.mod",
+        kind="module",
+        file_path=str(file_path),
+        lineno=1,
+        docstring=docstring,
+    )
+    return PackageDoc(name="pkg", modules=[ModuleDoc(symbol=symbol)])
+
+
+def _docstring(packages: list[PackageDoc]) -> str:
+    return packages[0].modules[0].symbol.docstring
+
+
+@pytest.fixture
+def tools(tmp_path: Path) -> DiagramConfig:
+    (tmp_path / "plantuml.py").write_text(FAKE_PLANTUML, encoding="utf-8")
+    (tmp_path / "mmdc.py").write_text(FAKE_MMDC, encoding="utf-8")
+    return DiagramConfig(
+        plantuml=f"{sys.executable} {tmp_path / 'plantuml.py'}",
+        mermaid=f"{sys.executable} {tmp_path / 'mmdc.py'}",
+    )
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    root = tmp_path / "src"
+    (root / "pkg" / "diagrams").mkdir(parents=True)
+    (root / "docs").mkdir()
+    (root / "pkg" / "diagrams" / "flow.png").write_bytes(PNG)
+    (root / "docs" / "overview.svg").write_text("<svg/>", encoding="utf-8")
+    (root / "pkg" / "diagrams" / "login.puml").write_text(
+        "@startuml\nA -> B\n@enduml\n",
+        encoding="utf-8",
+    )
+    return root
+
+
+class TestResolveAssets:
+    """Tests for :func:`services.doc_assets.resolve_assets`."""
+
+    @pytest.mark.unit
+    def test_images_are_copied_and_rewritten(self, tree):
+        docstring = (
+            "Module.\n\n"
+            ".. image:: diagrams/flow.png\n"
+            "   :alt: Request flow\n\n"
+            ".. figure:: /docs/overview.svg\n\n"
+            "   The services.\n\n"
+            "Inline ![logo](https://example.com/logo.png) and ![](diagrams/flow.png)."
+        )
+        packages, pages = resolve_assets(
+            [_package(docstring, tree / "pkg" / "mod.py")],
+            tree,
+        )
+        assert _docstring(packages) == (
+            "Module.\n\n"
+            "![Request flow](assets/images/pkg/diagrams/flow.png)\n\n"
+            "![The services.](assets/images/docs/overview.svg)\n\n"
+            "The services.\n\n"
+            "Inline ![logo](https://example.com/logo.png) and "
+            "![](assets/images/pkg/diagrams/flow.png)."
+        )
+        assert {page.path: page.content for page in pages} == {
+            "assets/images/docs/overview.svg": b"<svg/>",
+            "assets/images/pkg/diagrams/flow.png": PNG,
+        }
+
+    @pytest.mark.unit
+    def test_diagrams_are_rendered(self, tree, tools):
+        docstring = (
+            "Login.\n\n"
+            ".. uml:: diagrams/login.puml\n\n"
+            ".. mermaid::\n"
+            "   :caption: Jobs\n\n"
+            "   graph LR\n"
+            "     api --> worker\n"
+        )
+        packages, pages = resolve_assets(
+            [_package(docstring, tree / "pkg" / "mod.py")],
+            tree,
+            tools,
+        )
+        assert _docstring(packages) == (
+            "Login.\n\n"
+            "![login.puml](assets/images/pkg/diagrams/login.puml.svg)\n\n"
+            "![Jobs](assets/images/pkg.mod-1.svg)\n\n"
+            "Jobs\n"
+        )
+        contents = {page.path: page.content for page in pages}
+        assert contents["assets/images/pkg/diagrams/login.puml.svg"] == (
+            b"<svg>-tsvg @startuml\nA -> B\n@enduml</svg>"
+        )
+        assert contents["assets/images/pkg.mod-1.svg"] == (
+            b"<svg>mermaid graph LR\n  api --> worker</svg>"
+        )
+
+    @pytest.mark.unit
+    def test_unrendered_and_literal_references_are_kept(self, tree):
+        docstring = (
+            "Example::\n\n"
+            "    .. image:: missing.png\n\n"
+            "Write ``![alt](missing.png)`` for images.\n\n"
+            ".. mermaid::\n\n"
+            "   graph LR\n"
+        )
+        package = _package(docstring, tree / "pkg" / "mod.py")
+        packages, pages = resolve_assets([package], tree, DiagramConfig(render=False))
+        assert _docstring(packages) == docstring
+        assert pages == []
+
+    @pytest.mark.unit
+    def test_bad_references(self, tree):
+        module = tree / "pkg" / "mod.py"
+        with pytest.raises(AssetError, match="mod.py:1: cannot read nope.png"):
+            resolve_assets([_package(".. image:: nope.png", module)], tree)
+        with pytest.raises(AssetError, match="outside the source tree"):
+            resolve_assets([_package("![x](../../secret.png)", module)], tree)
+        config = DiagramConfig(plantuml="autodoc-no-such-plantuml")
+        with pytest.raises(AssetError, match="set site.diagrams.plantuml"):
+            resolve_assets(
+                [_package(".. uml:: diagrams/login.puml", module)],
+                tree,
+                config,
+                DiagramRenderer(config),
+            )
+
+    @pytest.mark.unit
+    def test_figures_in_html(self):
+        html = render_docstring("Flow.\n\n![Request <flow>](assets/images/flow.png)")
+        assert html == (
+            "<p>Flow.</p>\n"
+            '<figure class="autodoc-figure"><img src="assets/images/flow.png" '
+            'alt="Request &lt;flow&gt;"></figure>'
+        )
+
+
+class TestDiagramConfig:
+    """Tests for the ``site.diagrams`` section."""
+
+    @pytest.mark.unit
+    def test_sections(self):
+        assert ProjectConfig.from_dict({}).site.diagrams == DiagramConfig()
+        diagrams = ProjectConfig.from_dict(
+            {"site": {"diagrams": {"format": "png", "plantuml": "java -jar p.jar"}}},
+        ).site.diagrams
+        assert (diagrams.format, diagrams.plantuml) == ("png", "java -jar p.jar")
+        with pytest.raises(ProjectConfigError, match="site.diagrams.format"):
+            ProjectConfig.from_dict({"site": {"diagrams": {"format": "gif"}}})
+        with pytest.raises(ProjectConfigError, match="site.diagrams.timeout"):
+            ProjectConfig.from_dict({"site": {"diagrams": {"timeout": -1}}})
+
+
+class TestGeneratedSite:
+    """Tests for images in generated sites."""
+
+    @pytest.mark.unit
+    def test_every_format_ships_the_images(self, tree):
+        (tree / "pkg" / "__init__.py").write_text('"""Package."""\n', encoding="utf-8")
+        (tree / "pkg" / "mod.py").write_text(
+            '"""Flows.\n\n.. image:: diagrams/flow.png\n"""\n',
+            encoding="utf-8",
+        )
+        parsed = parse_tree(tree)
+        sites = render_site(parsed, build_model(parsed), ("markdown", "html", "json"))
+        for pages in sites.values():
+            assert {p.path: p.content for p in pages}[
+                "assets/images/pkg/diagrams/flow.png"
+            ] == PNG
+        markdown = {p.path: p.content for p in sites["markdown"]}
+        assert "![flow](assets/images/pkg/diagrams/flow.png)" in markdown["pkg.md"]
+        html = {p.path: p.content for p in sites["html"]}
+        assert '<img src="assets/images/pkg/diagrams/flow.png"' in html["pkg.html"]
+
+    @pytest.mark.unit
+    def test_generate_reports_missing_images(self, tree, tmp_path, capsys):
+        (tree / "pkg" / "mod.py").write_text(
+            '"""Flows.\n\n.. image:: gone.png\n"""\n',
+            encoding="utf-8",
+        )
+        output = tmp_path / "site"
+        argv = ["generate", "--root", str(tree), "--output", str(output)]
+        assert run_command(argv) == 1
+        assert "cannot read gone.png" in capsys.readouterr().err
+        assert not output.exists()
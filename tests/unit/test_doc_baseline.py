@@ -0,0 +1,98 @@
+"""Unit tests for the documentation findings baseline."""
+
+import json
+from datetime import date
+
+import pytest
+
+from services.doc_baseline import (
+    Baseline,
+    BaselineError,
+    aging_report,
+    build_baseline,
+    load_baseline,
+    write_baseline,
+)
+from services.doc_lint import LintFinding
+
+
+def _finding(symbol: str, rule: str = "missing-docstring") -> LintFinding:
+    return LintFinding(rule, f"{symbol} has no docstring", "pkg/mod.py", 1, symbol)
+
+
+class TestBaseline:
+    """Tests for building, persisting, and applying baselines."""
+
+    @pytest.mark.unit
+    def test_filter_new_ignores_baselined_findings(self):
+        baseline = build_baseline([_finding("pkg.old")], today=date(2024, 1, 1))
+        new = baseline.filter_new([_finding("pkg.old"), _finding("pkg.new")])
+        assert [f.symbol for f in new] == ["pkg.new"]
+
+    @pytest.mark.unit
+    def test_rewrite_preserves_first_seen_and_prunes_fixed(self):
+        first = build_baseline(
+            [_finding("pkg.a"), _finding("pkg.b")],
+            today=date(2024, 1, 1),
+        )
+        second = build_baseline(
+            [_finding("pkg.a"), _finding("pkg.c")],
+            previous=first,
+            today=date(2024, 3, 1),
+        )
+        seen = {e.symbol: e.first_seen for e in second.entries}
+        assert seen == {"pkg.a": "2024-01-01", "pkg.c": "2024-03-01"}
+
+    @pytest.mark.unit
+    def test_round_trip(self, tmp_path):
+        baseline = build_baseline([_finding("pkg.a")], today=date(2024, 1, 1))
+        path = write_baseline(baseline, tmp_path / "baseline.json")
+        assert load_baseline(path) == baseline
+        assert json.loads(path.read_text())["version"] == 1
+
+    @pytest.mark.unit
+    def test_load_rejects_unknown_version(self, tmp_path):
+        path = tmp_path / "baseline.json"
+        path.write_text(json.dumps({"version": 99, "entries": []}))
+        with pytest.raises(BaselineError, match="Unsupported baseline version"):
+            load_baseline(path)
+
+    @pytest.mark.unit
+    def test_load_missing_file(self, tmp_path):
+        with pytest.raises(BaselineError):
+            load_baseline(tmp_path / "missing.json")
+
+
+class TestAgingReport:
+    """Tests for the baseline aging report."""
+
+    @pytest.mark.unit
+    def test_buckets_and_fixed_entries(self):
+        old = build_baseline([_finding("pkg.old")], today=date(2023, 1, 1))
+        baseline = build_baseline(
+            [_finding("pkg.old"), _finding("pkg.recent")],
+            previous=old,
+            today=date(2024, 1, 1),
+        )
+
+        report = aging_report(
+            baseline,
+            findings=[_finding("pkg.recent")],
+            today=date(2024, 1, 11),
+        )
+
+        assert report["total"] == 2
+        assert report["by_age"] == {
+            "<30d": 1,
+            "30-90d": 0,
+            "90-180d": 0,
+            ">=180d": 1,
+        }
+        assert report["oldest"][0]["symbol"] == "pkg.old"
+        assert [e["symbol"] for e in report["fixed"]] == ["pkg.old"]
+
+    @pytest.mark.unit
+    def test_empty_baseline(self):
+        report = aging_report(Baseline(), today=date(2024, 1, 1))
+        assert report["total"] == 0
+        assert "fixed" not in report
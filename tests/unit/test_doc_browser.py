@@ -0,0 +1,138 @@
+"""Unit tests for the terminal doc browser."""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_browser import DocBrowser, search_symbols, wrap_markdown
+from services.doc_markdown import render_package_markdown, render_symbol_markdown
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """``shop`` and ``billing`` packages."""
+    for name in ("shop", "billing"):
+        (tmp_path / name).mkdir()
+        (tmp_path / name / "__init__.py").write_text(
+            f'"""The {name} package."""\n',
+            encoding="utf-8",
+        )
+    (tmp_path / "shop" / "cart.py").write_text(
+        "class Cart:\n"
+        '    """A shopping cart."""\n\n'
+        "    def total(self) -> int:\n"
+        '        """Total price of the cart."""\n\n\n'
+        "def cart_total(cart: Cart) -> int:\n"
+        '    """Total of ``cart``."""\n',
+        encoding="utf-8",
+    )
+    (tmp_path / "billing" / "invoice.py").write_text(
+        "def issue(amount: int) -> None:\n"
+        '    """Issue an invoice for a cart."""\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def _text(browser: DocBrowser, width: int = 80, height: int = 12) -> list[str]:
+    return [text for text, _ in browser.screen(width, height)]
+
+
+def test_search_symbols(tree: Path) -> None:
+    packages = build_model(parse_tree(tree))
+    results = search_symbols(packages, "CART")
+    assert [symbol.qualified_name for _, symbol in results] == [
+        "shop.cart",
+        "shop.cart.Cart",
+        "shop.cart.cart_total",
+        "shop.cart.Cart.total",
+        "billing.invoice.issue",
+    ]
+    assert search_symbols(packages, "  ") == []
+
+
+def test_symbol_markdown_matches_page(tree: Path) -> None:
+    [_, shop] = build_model(parse_tree(tree))
+    entry = render_symbol_markdown(shop, "shop.cart.Cart.total")
+    assert entry is not None
+    assert entry.startswith("#### `Cart.total`\n")
+    assert entry in render_package_markdown(shop)
+    assert render_symbol_markdown(shop, "shop.missing") is None
+
+
+def test_navigation(tree: Path) -> None:
+    browser = DocBrowser(build_model(parse_tree(tree)), "API Reference")
+    screen = browser.screen(60, 6)
+    assert screen[0] == ("API Reference", "title")
+    assert screen[1] == ("billing (3 symbols)", "selected")
+    assert screen[2] == ("shop (5 symbols)", "plain")
+    assert screen[-1][1] == "status"
+    assert len(screen) == 6
+
+    browser.press("down")
+    browser.press("enter")
+    assert _text(browser)[:5] == [
+        "API Reference > shop",
+        "module shop - The shop package.",
+        "module shop.cart",
+        "  function cart_total - Total of ``cart``.",
+        "  class Cart - A shopping cart.",
+    ]
+    browser.press("end")
+    browser.press("enter")
+    lines = _text(browser)
+    assert lines[0] == "API Reference > shop > shop.cart.Cart.total"
+    assert lines[1] == "#### `Cart.total`"
+    assert "Total price of the cart." in lines
+
+    browser.press("backspace")
+    browser.press("backspace")
+    assert _text(browser)[0] == "API Reference"
+    assert browser.press("q") is False
+
+
+def test_search_view(tree: Path) -> None:
+    browser = DocBrowser(build_model(parse_tree(tree)), "API Reference")
+    browser.press("/")
+    for key in "issue":
+        assert browser.press(key) is True
+    lines = _text(browser)
+    assert lines[0] == "API Reference  /issue"
+    assert lines[1] == "function billing.invoice.issue - Issue an invoice for a cart."
+    browser.press("enter")
+    assert _text(browser)[0] == "API Reference > billing.invoice.issue"
+    browser.press("backspace")
+    for _ in "issue":
+        browser.press("backspace")
+    browser.press("x")
+    assert _text(browser)[1] == "No matches"
+    browser.press("escape")
+    assert browser.view.kind == "packages"
+
+
+def test_wrap_markdown() -> None:
+    text = "A long line of prose to wrap.\n\n```python\nx = 'not wrapped at all'\n```"
+    assert wrap_markdown(text, 12) == [
+        "A long line",
+        "of prose to",
+        "wrap.",
+        "",
+        "```python",
+        "x = 'not wrapped at all'",
+        "```",
+    ]
+
+
+def test_browse_command(tree: Path, capsys) -> None:
+    argv = ["browse", "--root", str(tree)]
+    assert run_command([*argv, "--show", "billing.invoice.issue"]) == 0
+    out = capsys.readouterr().out
+    assert out.startswith("### `issue`\n")
+    assert "Issue an invoice for a cart." in out
+    assert run_command([*argv, "--show", "billing.missing"]) == 1
+    assert "no documented symbol billing.missing" in capsys.readouterr().err
@@ -0,0 +1,129 @@
+"""Unit tests for fingerprinted assets and cache header manifests."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import CachingConfig, ProjectConfig, ProjectConfigError
+from services.doc_caching import (
+    apply_caching,
+    content_type,
+    fingerprint_assets,
+    fingerprinted_path,
+)
+from services.doc_site import SitePage
+
+CSS = "body { color: #000; }"
+PAGE = (
+    '<link rel="stylesheet" href="assets/autodoc.css">\n'
+    '<link rel="stylesheet" href="assets/autodoc.css?v=1">\n'
+    '<img class="autodoc-logo" src="assets/logo.svg" alt="">\n'
+    '<img src="assets/images/pkg/flow.png" alt="Flow">\n'
+    "<p>Load assets/autodoc.css first.</p>\n"
+)
+
+
+def _pages() -> list[SitePage]:
+    return [
+        SitePage("index.html", PAGE),
+        SitePage("assets/autodoc.css", CSS),
+        SitePage("assets/logo.svg", b"<svg/>"),
+        SitePage("assets/images/pkg/flow.png", b"png"),
+    ]
+
+
+class TestFingerprint:
+    """Tests for :func:`services.doc_caching.fingerprint_assets`."""
+
+    @pytest.mark.unit
+    def test_assets_are_renamed_and_referenced(self):
+        css = fingerprinted_path("assets/autodoc.css", CSS.encode())
+        logo = fingerprinted_path("assets/logo.svg", b"<svg/>")
+        assert css.startswith("assets/autodoc.") and css.endswith(".css")
+        assert len(css) == len("assets/autodoc.css") + 11
+        pages = {page.path: page.content for page in fingerprint_assets(_pages())}
+        assert set(pages) == {"index.html", css, logo, "assets/images/pkg/flow.png"}
+        assert pages["index.html"] == (
+            f'<link rel="stylesheet" href="{css}">\n'
+            f'<link rel="stylesheet" href="{css}?v=1">\n'
+            f'<img class="autodoc-logo" src="{logo}" alt="">\n'
+            '<img src="assets/images/pkg/flow.png" alt="Flow">\n'
+            "<p>Load assets/autodoc.css first.</p>\n"
+        )
+
+    @pytest.mark.unit
+    def test_previous_fingerprints_are_replaced(self):
+        # A page carried over by an incremental run names the old file.
+        old = b'<link rel="stylesheet" href="assets/autodoc.0123456789.css">'
+        pages = fingerprint_assets(
+            [SitePage("shop.html", old), SitePage("assets/autodoc.css", CSS)],
+        )
+        new = fingerprinted_path("assets/autodoc.css", CSS.encode())
+        assert pages[0].content == f'<link rel="stylesheet" href="{new}">'
+
+
+class TestHeaders:
+    """Tests for the cache header manifests."""
+
+    @pytest.mark.unit
+    def test_netlify_and_s3_manifests(self):
+        config = CachingConfig(
+            fingerprint=True,
+            headers=["netlify", "s3"],
+            page_max_age=300,
+        )
+        pages = apply_caching(_pages(), config)
+        files = {page.path: page.content for page in pages}
+        css = fingerprinted_path("assets/autodoc.css", CSS.encode())
+        assert files["_headers"].startswith(
+            f"/{css}\n"
+            "  Cache-Control: public, max-age=31536000, immutable\n"
+            "/assets/images/pkg/flow.png\n"
+            "  Cache-Control: public, max-age=300, must-revalidate\n",
+        )
+        assert files["_headers"].endswith(
+            "/\n  Cache-Control: public, max-age=300, must-revalidate\n"
+            "/index.html\n  Cache-Control: public, max-age=300, must-revalidate\n",
+        )
+        metadata = json.loads(files["_s3-metadata.json"])
+        assert metadata["schema_version"] >= 1
+        assert metadata["objects"]["index.html"] == {
+            "CacheControl": "public, max-age=300, must-revalidate",
+            "ContentType": "text/html; charset=utf-8",
+        }
+        assert "_headers" not in metadata["objects"]
+        assert content_type("assets/images/pkg/flow.png") == "image/png"
+
+    @pytest.mark.unit
+    def test_disabled_by_default(self):
+        assert apply_caching(_pages(), CachingConfig()) == _pages()
+        with pytest.raises(ProjectConfigError, match="unknown format 'nginx'"):
+            ProjectConfig.from_dict({"site": {"caching": {"headers": ["nginx"]}}})
+        with pytest.raises(ProjectConfigError, match="site.caching.asset_max_age"):
+            ProjectConfig.from_dict({"site": {"caching": {"asset_max_age": -1}}})
+
+
+class TestGeneratedSite:
+    """Tests for caching in ``autodoc generate``."""
+
+    @pytest.mark.unit
+    def test_generate_writes_fingerprinted_site(self, tmp_path: Path):
+        root = tmp_path / "src"
+        (root / "shop").mkdir(parents=True)
+        (root / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+        (root / "autodoc.yaml").write_text(
+            "site:\n  caching:\n    fingerprint: true\n    headers: netlify\n",
+            encoding="utf-8",
+        )
+        output = tmp_path / "site"
+        argv = ["generate", "--root", str(root), "--output", str(output)]
+        assert run_command([*argv, "--format", "html,markdown"]) == 0
+        html = output / "html"
+        [stylesheet] = (html / "assets").glob("autodoc.*.css")
+        assert not (html / "assets" / "autodoc.css").exists()
+        index = (html / "index.html").read_text(encoding="utf-8")
+        assert f'href="assets/{stylesheet.name}"' in index
+        assert (html / "_headers").is_file()
+        assert not (output / "markdown" / "_headers").exists()
@@ -0,0 +1,228 @@
+"""Unit tests for the documentation symbol model and coverage metrics."""
+
+import json
+import logging
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.doc_coverage import (
+    compute_coverage,
+    compute_package_coverage,
+    packages_below_threshold,
+)
+from services.doc_symbols import (
+    WalkOptions,
+    discover_python_files,
+    is_exported,
+    load_doc_symbols,
+    module_name_for,
+    package_name_for,
+    root_relative,
+)
+
+
+def _write(path: Path, content: str) -> Path:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+    return path
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    """A small tree with one documented and one undocumented package."""
+    _write(
+        tmp_path / "good" / "__init__.py",
+        '"""Good package."""\n',
+    )
+    _write(
+        tmp_path / "good" / "api.py",
+        '"""API module."""\n\n'
+        "def fetch():\n"
+        '    """Fetch things."""\n\n'
+        "def _private():\n"
+        "    pass\n",
+    )
+    _write(
+        tmp_path / "bad" / "core.py",
+        "class Engine:\n"
+        "    def __init__(self):\n"
+        "        pass\n\n"
+        "    def start(self):\n"
+        "        pass\n",
+    )
+    _write(tmp_path / ".venv" / "ignored.py", "def hidden():\n    pass\n")
+    return tmp_path
+
+
+class TestDocSymbols:
+    """Tests for symbol discovery and naming."""
+
+    @pytest.mark.unit
+    def test_discover_skips_excluded_dirs(self, source_tree):
+        files = discover_python_files(source_tree)
+        names = [f.relative_to(source_tree).as_posix() for f in files]
+        assert names == ["bad/core.py", "good/__init__.py", "good/api.py"]
+
+    @pytest.mark.unit
+    def test_symlinks_are_skipped_unless_followed(self, source_tree, tmp_path_factory):
+        outside = tmp_path_factory.mktemp("outside")
+        _write(outside / "extra.py", '"""Extra."""\n')
+        (source_tree / "linked").symlink_to(outside, target_is_directory=True)
+        (source_tree / "good" / "alias.py").symlink_to(source_tree / "good" / "api.py")
+        # A cycle must not hang the walk.
+        loop = source_tree / "good" / "loop"
+        loop.symlink_to(source_tree, target_is_directory=True)
+        follow = WalkOptions(follow_symlinks=True)
+
+        default = discover_python_files(source_tree)
+        followed = discover_python_files(source_tree, walk=follow)
+
+        assert [f.relative_to(source_tree).as_posix() for f in default] == [
+            "bad/core.py",
+            "good/__init__.py",
+            "good/api.py",
+        ]
+        assert [f.relative_to(source_tree).as_posix() for f in followed] == [
+            "bad/core.py",
+            "good/__init__.py",
+            "good/alias.py",
+            "good/api.py",
+            "linked/extra.py",
+        ]
+        symbols = load_doc_symbols(source_tree, walk=follow)
+        assert "linked.extra" in {s.qualified_name for s in symbols}
+
+    @pytest.mark.unit
+    def test_nested_projects_are_skipped_unless_requested(self, source_tree):
+        _write(source_tree / "plugins" / "pyproject.toml", "[project]\nname = 'x'\n")
+        _write(source_tree / "plugins" / "plugin.py", '"""Plugin."""\n')
+
+        default = discover_python_files(source_tree)
+        nested = discover_python_files(
+            source_tree,
+            walk=WalkOptions(nested_projects=True),
+        )
+
+        assert source_tree / "plugins" / "plugin.py" not in default
+        assert source_tree / "plugins" / "plugin.py" in nested
+
+    @pytest.mark.unit
+    def test_walk_flags_on_the_command_line(self, source_tree, capsys):
+        _write(source_tree / "plugins" / "setup.py", '"""Setup."""\n')
+        _write(source_tree / "plugins" / "plugin.py", "def raw():\n    pass\n")
+
+        run_command(["lint", "--root", str(source_tree)])
+        assert "plugins/" not in capsys.readouterr().out
+        run_command(["lint", "--root", str(source_tree), "--nested-projects"])
+        assert "plugins/plugin.py:" in capsys.readouterr().out
+
+    @pytest.mark.unit
+    def test_oversized_and_binary_files_are_skipped(self, source_tree, caplog):
+        _write(source_tree / "gen" / "tables.py", "TABLE = 1\n" * 200)
+        (source_tree / "good" / "blob.py").write_bytes(b"\x89PNG\0\0data")
+
+        with caplog.at_level(logging.WARNING, logger="services.doc_symbols"):
+            files = discover_python_files(
+                source_tree,
+                walk=WalkOptions(max_file_size=1024),
+            )
+
+        names = [f.relative_to(source_tree).as_posix() for f in files]
+        assert names == ["bad/core.py", "good/__init__.py", "good/api.py"]
+        assert "2.0 KiB exceeds the 1.0 KiB limit" in caplog.text
+        assert "contains NUL bytes" in caplog.text
+
+        unlimited = discover_python_files(
+            source_tree,
+            walk=WalkOptions(max_file_size=None),
+        )
+        assert source_tree / "gen" / "tables.py" in unlimited
+
+    @pytest.mark.unit
+    def test_max_file_size_flag(self, source_tree, capsys):
+        _write(source_tree / "gen" / "tables.py", "def big():\n    pass\n" * 100)
+
+        run_command(["lint", "--root", str(source_tree), "--max-file-size", "1K"])
+        assert "gen/tables.py" not in capsys.readouterr().out
+        run_command(["lint", "--root", str(source_tree), "--max-file-size", "0"])
+        assert "gen/tables.py:" in capsys.readouterr().out
+
+    @pytest.mark.unit
+    def test_module_and_package_names(self, source_tree):
+        assert module_name_for(source_tree / "good" / "api.py", source_tree) == (
+            "good.api"
+        )
+        assert module_name_for(source_tree / "good" / "__init__.py", source_tree) == (
+            "good"
+        )
+        assert package_name_for(source_tree / "good" / "api.py", source_tree) == (
+            "good"
+        )
+
+    @pytest.mark.unit
+    def test_relative_root_names(self, source_tree, monkeypatch):
+        monkeypatch.chdir(source_tree.parent)
+        symbols = load_doc_symbols(source_tree.name)
+        assert {s.package for s in symbols} == {"bad", "good"}
+
+    @pytest.mark.unit
+    def test_root_relative_paths(self, source_tree, monkeypatch):
+        absolute = root_relative(load_doc_symbols(source_tree), source_tree)
+        monkeypatch.chdir(source_tree)
+        relative = root_relative(load_doc_symbols("."), ".")
+
+        assert [s.file_path for s in absolute] == [s.file_path for s in relative]
+        assert absolute[0].file_path == "bad/core.py"
+
+    @pytest.mark.unit
+    def test_lint_reports_root_relative_paths(self, source_tree, capsys):
+        run_command(["lint", "--root", str(source_tree)])
+
+        assert capsys.readouterr().out.startswith("bad/core.py:")
+
+    @pytest.mark.unit
+    def test_dunder_methods_are_not_exported(self, source_tree):
+        symbols = load_doc_symbols(source_tree)
+        by_name = {s.qualified_name: s for s in symbols}
+        assert not is_exported(by_name["bad.core.Engine.__init__"])
+        assert is_exported(by_name["bad.core.Engine.start"])
+        assert not is_exported(by_name["good.api._private"])
+
+
+class TestDocCoverage:
+    """Tests for per-package coverage aggregation."""
+
+    @pytest.mark.unit
+    def test_compute_package_coverage(self, source_tree):
+        coverage = {c.package: c for c in compute_package_coverage(source_tree)}
+
+        assert set(coverage) == {"bad", "good"}
+        assert coverage["good"].percent == 100.0
+        assert coverage["bad"].total == 3
+        assert coverage["bad"].documented == 0
+        assert coverage["bad"].undocumented == [
+            "bad.core",
+            "bad.core.Engine",
+            "bad.core.Engine.start",
+        ]
+
+    @pytest.mark.unit
+    def test_packages_below_threshold(self, source_tree):
+        coverage = compute_package_coverage(source_tree)
+        below = packages_below_threshold(coverage, 50.0)
+        assert [c.package for c in below] == ["bad"]
+
+    @pytest.mark.unit
+    def test_empty_input_has_no_packages(self):
+        assert compute_coverage([]) == []
+
+    @pytest.mark.unit
+    def test_json_report(self, source_tree, capsys):
+        argv = ["coverage", "--root", str(source_tree), "--format", "json"]
+        assert run_command([*argv, "--threshold", "50"]) == 1
+        report = json.loads(capsys.readouterr().out)
+        assert report["schema_version"] == 1
+        assert [p["package"] for p in report["packages"]] == ["bad", "good"]
+        assert report["packages"][1]["percent"] == 100.0
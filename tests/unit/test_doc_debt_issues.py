@@ -0,0 +1,219 @@
+"""Unit tests for documentation-debt issue filing."""
+
+from __future__ import annotations
+
+import httpx
+import pytest
+
+from autodoc.config.settings import GitHubSettings
+from services.doc_coverage import PackageCoverage
+from services.doc_debt_issues import (
+    DEBT_LABEL,
+    DocDebtIssueFiler,
+    GitHubIssueTracker,
+    IssueTrackerConfigurationError,
+    IssueTrackerError,
+    TrackedIssue,
+    build_issue_body,
+    debt_issue_key,
+    get_issue_tracker,
+)
+
+
+class FakeTracker:
+    """In-memory tracker keyed by the dedup key."""
+
+    def __init__(self, fail: bool = False) -> None:
+        self.issues: dict[str, dict] = {}
+        self.fail = fail
+
+    def find_open_issue(self, key: str) -> TrackedIssue | None:
+        if self.fail:
+            raise IssueTrackerError("tracker unavailable")
+        return TrackedIssue(id=key) if key in self.issues else None
+
+    def create_issue(self, key: str, title: str, body: str) -> TrackedIssue:
+        self.issues[key] = {"title": title, "body": body}
+        return TrackedIssue(id=key)
+
+    def update_issue(self, issue: TrackedIssue, title: str, body: str) -> None:
+        self.issues[issue.id] = {"title": title, "body": body}
+
+
+def _coverage(package: str, documented: int, total: int) -> PackageCoverage:
+    undocumented = [f"{package}.sym{i}" for i in range(total - documented)]
+    return PackageCoverage(
+        package=package,
+        total=total,
+        documented=documented,
+        undocumented=undocumented,
+    )
+
+
+class TestDocDebtIssueFiler:
+    """Tests for the tracker-agnostic filing logic."""
+
+    @pytest.mark.unit
+    def test_key_is_stable_and_label_safe(self):
+        key = debt_issue_key("services.api")
+        assert key == debt_issue_key("services.api")
+        assert key.startswith(DEBT_LABEL)
+        assert " " not in key
+        assert key != debt_issue_key("services.db")
+
+    @pytest.mark.unit
+    def test_files_only_packages_below_threshold(self):
+        tracker = FakeTracker()
+        filer = DocDebtIssueFiler(tracker, threshold=80.0)
+
+        result = filer.file_issues(
+            [_coverage("low", 1, 4), _coverage("high", 9, 10)],
+        )
+
+        assert result.created == ["low"]
+        assert result.skipped == ["high"]
+        assert list(tracker.issues) == [debt_issue_key("low")]
+
+    @pytest.mark.unit
+    def test_second_run_updates_instead_of_creating(self):
+        tracker = FakeTracker()
+        filer = DocDebtIssueFiler(tracker, threshold=80.0)
+
+        filer.file_issues([_coverage("low", 1, 4)])
+        result = filer.file_issues([_coverage("low", 2, 4)])
+
+        assert result.created == []
+        assert result.updated == ["low"]
+        assert len(tracker.issues) == 1
+        assert "50.0%" in tracker.issues[debt_issue_key("low")]["title"]
+
+    @pytest.mark.unit
+    def test_dry_run_does_not_touch_tracker(self):
+        filer = DocDebtIssueFiler(None, threshold=80.0)
+        result = filer.file_issues([_coverage("low", 0, 2)], dry_run=True)
+        assert result.created == ["low"]
+
+    @pytest.mark.unit
+    def test_dry_run_sorts_like_a_real_run(self):
+        tracker = FakeTracker()
+        tracker.issues[debt_issue_key("old")] = {"title": "t", "body": "b"}
+        filer = DocDebtIssueFiler(tracker, threshold=80.0)
+
+        coverage = [_coverage("old", 0, 2), _coverage("new", 0, 2)]
+        result = filer.file_issues(coverage, dry_run=True)
+        assert (result.created, result.updated) == (["new"], ["old"])
+        assert tracker.issues == {debt_issue_key("old"): {"title": "t", "body": "b"}}
+
+    @pytest.mark.unit
+    def test_tracker_errors_are_collected(self):
+        filer = DocDebtIssueFiler(FakeTracker(fail=True), threshold=80.0)
+        result = filer.file_issues([_coverage("low", 0, 2)])
+        assert not result.success
+        assert result.errors == ["low: tracker unavailable"]
+
+    @pytest.mark.unit
+    def test_body_lists_undocumented_symbols(self):
+        body = build_issue_body(_coverage("pkg", 0, 2), threshold=80.0)
+        assert "- `pkg.sym0`" in body
+        assert debt_issue_key("pkg") in body
+
+
+class RecordingClient:
+    """httpx.Client stand-in that replays canned responses."""
+
+    def __init__(self, responses: list[tuple[int, object] | Exception]) -> None:
+        self._responses = responses
+        self.calls: list[tuple[str, str, dict]] = []
+
+    def _respond(self, method: str, path: str, **kwargs) -> httpx.Response:
+        self.calls.append((method, path, kwargs))
+        response = self._responses.pop(0)
+        if isinstance(response, Exception):
+            raise response
+        status, payload = response
+        return httpx.Response(
+            status,
+            request=httpx.Request(method, f"https://api.test{path}"),
+            json=payload,
+        )
+
+    def get(self, path: str, **kwargs) -> httpx.Response:
+        return self._respond("GET", path, **kwargs)
+
+    def post(self, path: str, **kwargs) -> httpx.Response:
+        return self._respond("POST", path, **kwargs)
+
+    def patch(self, path: str, **kwargs) -> httpx.Response:
+        return self._respond("PATCH", path, **kwargs)
+
+
+class TestGitHubIssueTracker:
+    """Tests for the GitHub tracker implementation."""
+
+    @staticmethod
+    def _settings() -> GitHubSettings:
+        return GitHubSettings(token="t0ken", repository="acme/widgets")
+
+    @pytest.mark.unit
+    def test_requires_configuration(self):
+        with pytest.raises(IssueTrackerConfigurationError):
+            GitHubIssueTracker(settings=GitHubSettings(token=None, repository=None))
+
+    @pytest.mark.unit
+    def test_find_and_create(self):
+        client = RecordingClient(
+            [(200, []), (201, {"number": 7, "html_url": "https://gh/7"})],
+        )
+        tracker = GitHubIssueTracker(settings=self._settings(), client=client)
+
+        assert tracker.find_open_issue("autodoc-doc-debt-abc") is None
+        issue = tracker.create_issue("autodoc-doc-debt-abc", "title", "body")
+
+        assert issue == TrackedIssue(id="7", url="https://gh/7")
+        method, path, kwargs = client.calls[1]
+        assert (method, path) == ("POST", "/repos/acme/widgets/issues")
+        assert kwargs["json"]["labels"] == [DEBT_LABEL, "autodoc-doc-debt-abc"]
+
+    @pytest.mark.unit
+    def test_http_errors_raise_tracker_error(self):
+        client = RecordingClient([(500, {"message": "boom"})])
+        tracker = GitHubIssueTracker(settings=self._settings(), client=client)
+        with pytest.raises(IssueTrackerError, match="500"):
+            tracker.find_open_issue("key")
+
+    @pytest.mark.unit
+    def test_http_errors_do_not_echo_the_token(self):
+        settings = GitHubSettings(token="ghp_secret_token", repository="acme/widgets")
+        client = RecordingClient([(401, {"message": "bad token ghp_secret_token"})])
+        tracker = GitHubIssueTracker(settings=settings, client=client)
+        with pytest.raises(IssueTrackerError) as info:
+            tracker.find_open_issue("key")
+        assert "ghp_secret_token" not in str(info.value)
+
+    @pytest.mark.unit
+    def test_transport_errors_are_collected(self):
+        settings = GitHubSettings(
+            token="t0ken",
+            repository="acme/widgets",
+            max_retries=0,
+        )
+        client = RecordingClient(
+            [httpx.ConnectError("refused"), (200, []), (201, {"number": 8})],
+        )
+        tracker = GitHubIssueTracker(settings=settings, client=client)
+        filer = DocDebtIssueFiler(tracker, threshold=80.0)
+
+        coverage = [_coverage("first", 0, 2), _coverage("second", 0, 2)]
+        result = filer.file_issues(coverage)
+        assert result.errors == ["first: Failed to search GitHub issues: refused"]
+        assert result.created == ["second"]
+
+    @pytest.mark.unit
+    def test_overrides_take_precedence_over_the_environment(self, monkeypatch):
+        monkeypatch.setenv("GITHUB_REPOSITORY", "acme/from-env")
+        monkeypatch.setenv("GITHUB_TOKEN", "env-token")
+        tracker = get_issue_tracker("github", {"repository": "acme/from-config"})
+        assert tracker._repo_path == "/repos/acme/from-config"
+
+        with pytest.raises(IssueTrackerConfigurationError, match="Invalid github"):
+            get_issue_tracker("github", {"max_retries": "many"})
@@ -0,0 +1,140 @@
+"""Unit tests for the periodic documentation digest."""
+
+from __future__ import annotations
+
+import json
+import os
+import subprocess
+from datetime import timedelta
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.doc_digest import (
+    DigestError,
+    collect_digest,
+    parse_period,
+    render_html,
+    render_markdown,
+)
+
+OLD = '''"""Carts."""
+
+
+def total(items):
+    return sum(items)
+
+
+def empty():
+    """Whether nothing is in the cart."""
+'''
+
+NEW = '''"""Carts."""
+
+
+def total(items, tax=0):
+    """Sum of the items plus ``tax``."""
+    return sum(items) + tax
+
+
+def empty():
+    """Whether nothing is in the cart."""
+
+
+class Discount:
+    def apply(self, amount):
+        return amount
+
+
+def refund(order):
+    pass
+'''
+
+
+def _git(repo: Path, *args: str, date: str | None = None) -> None:
+    env = dict(os.environ)
+    if date is not None:
+        env.update(GIT_AUTHOR_DATE=date, GIT_COMMITTER_DATE=date)
+    subprocess.run(["git", *args], cwd=repo, check=True, capture_output=True, env=env)
+
+
+@pytest.fixture
+def repo(tmp_path: Path) -> Path:
+    """A repository with a commit from 2000 and one from today."""
+    _git(tmp_path, "init", "-q", "-b", "main")
+    _git(tmp_path, "config", "user.email", "dev@example.com")
+    _git(tmp_path, "config", "user.name", "Dev")
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "cart.py").write_text(OLD, encoding="utf-8")
+    _git(tmp_path, "add", ".")
+    _git(tmp_path, "commit", "-q", "-m", "old", date="2000-01-01T00:00:00Z")
+    (tmp_path / "shop" / "cart.py").write_text(NEW, encoding="utf-8")
+    _git(tmp_path, "commit", "-q", "-am", "new")
+    return tmp_path
+
+
+def test_parse_period() -> None:
+    assert parse_period("7d") == timedelta(days=7)
+    assert parse_period("12h") == timedelta(hours=12)
+    assert parse_period("2w") == timedelta(weeks=2)
+    for text in ("0d", "7", "1y", "d7"):
+        with pytest.raises(DigestError, match="Invalid period"):
+            parse_period(text)
+
+
+def test_digest_summarizes_the_period(repo: Path) -> None:
+    digest = collect_digest(repo, timedelta(days=7))
+
+    assert digest.commits == 1
+    assert digest.change == "breaking"
+    assert "shop.cart, def total(items)" in digest.api.removed
+    assert [s.qualified_name for s in digest.documented] == ["shop.cart.total"]
+    assert [s.qualified_name for s in digest.undocumented] == [
+        "shop.cart.Discount",
+        "shop.cart.refund",
+        "shop.cart.Discount.apply",
+    ]
+    assert (digest.coverage_before, digest.coverage_after) == (66.67, 50.0)
+    assert [c.to_dict() for c in digest.coverage] == [
+        {"package": "shop", "before": 66.67, "after": 50.0, "delta": -16.67},
+    ]
+
+    markdown = render_markdown(digest)
+    assert markdown.startswith("# Documentation digest: ")
+    assert "## API changes (breaking)" in markdown
+    assert "- `shop.cart.total` (function): Sum of the items plus ``tax``." in markdown
+    assert "| shop | 66.7% | 50.0% | -16.7 points |" in markdown
+    assert "- `shop.cart.Discount` (class), shop/cart.py:13" in markdown
+
+    html = render_html(digest)
+    assert html.startswith("<!DOCTYPE html>")
+    assert "<style" not in html
+    assert "<td style=\"" in html
+    assert "66.7% -&gt; 50.0%" in html
+
+
+def test_everything_is_new_without_an_older_commit(repo: Path) -> None:
+    digest = collect_digest(repo, timedelta(weeks=52 * 40))
+
+    assert digest.base is None
+    assert digest.commits == 2
+    assert digest.api.removed == []
+    assert "since the first commit" in render_markdown(digest)
+
+
+def test_digest_command_writes_html(repo: Path, tmp_path: Path, capsys) -> None:
+    output = tmp_path / "digest.html"
+
+    args = ["digest", "--root", str(repo), "--since", "7d", "--format", "html"]
+    assert run_command([*args, "--output", str(output)]) == 0
+    assert "Wrote the digest to" in capsys.readouterr().out
+    assert "Top undocumented additions" in output.read_text(encoding="utf-8")
+
+    assert run_command(["digest", "--root", str(repo), "--format", "json"]) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert data["api"]["change"] == "breaking"
+    assert data["undocumented"][0]["qualified_name"] == "shop.cart.Discount"
+
+    assert run_command(["digest", "--root", str(repo), "--since", "soon"]) == 1
+    assert "Invalid period" in capsys.readouterr().err
@@ -0,0 +1,158 @@
+"""Unit tests for links to the documentation of external types."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import (
+    ExternalLinksConfig,
+    ProjectConfig,
+    ProjectConfigError,
+)
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.doc_external_links import (
+    ExternalLinkResolver,
+    build_external_links,
+    project_distributions,
+    project_pins,
+    python_version,
+)
+
+SOURCE = (
+    '"""Client."""\n\n'
+    "from pathlib import Path\n\n"
+    "import httpx\n"
+    "import yaml as y\n\n"
+    "from shop import cart\n\n\n"
+    "def load(path: Path, data: y.Node, cart: cart.Cart) -> httpx.Response:\n"
+    '    """Load."""\n\n\n'
+    "def count(items: list[str], other: Undeclared) -> int:\n"
+    '    """Count."""\n'
+)
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    root = tmp_path / "src"
+    (root / "shop").mkdir(parents=True)
+    (root / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (root / "shop" / "cart.py").write_text(
+        '"""Carts."""\n\n\nclass Cart:\n    """A cart."""\n',
+        encoding="utf-8",
+    )
+    (root / "shop" / "client.py").write_text(SOURCE, encoding="utf-8")
+    (root / "requirements.txt").write_text(
+        "# pinned\nhttpx[http2]==0.27.0 ; python_version >= '3.11'\nrich>=13\n",
+        encoding="utf-8",
+    )
+    (root / "pyproject.toml").write_text(
+        '[project]\nname = "shop"\nrequires-python = ">=3.11"\n'
+        'dependencies = ["PyYAML==6.0.1"]\n',
+        encoding="utf-8",
+    )
+    return root
+
+
+def _config(**data) -> ProjectConfig:
+    return ProjectConfig.from_dict({"site": {"external_links": data or True}})
+
+
+class TestResolver:
+    """Tests for :class:`services.doc_external_links.ExternalLinkResolver`."""
+
+    @pytest.mark.unit
+    def test_pins_and_python_version(self, source_tree):
+        assert project_pins(source_tree) == {"httpx": "0.27.0", "pyyaml": "6.0.1"}
+        assert python_version(source_tree) == "3.11"
+        assert python_version(source_tree, "3.13") == "3.13"
+        assert python_version(source_tree.parent) == "3"
+
+    @pytest.mark.unit
+    def test_external_names_are_linked(self, source_tree):
+        tree = parse_tree(source_tree)
+        config = _config(distributions={"yaml": "PyYAML"}).site.external_links
+        resolve = ExternalLinkResolver(
+            config,
+            tree.graph,
+            project_pins(source_tree),
+            "3.11",
+        )
+        load, count = (s for s in tree.symbols if s.name in ("load", "count"))
+        assert resolve(load) == {
+            "pathlib.Path": (
+                "https://docs.python.org/3.11/library/pathlib.html#pathlib.Path"
+            ),
+            "yaml.Node": "https://pypi.org/project/PyYAML/6.0.1/",
+            "httpx.Response": "https://pypi.org/project/httpx/0.27.0/",
+        }
+        assert resolve(count) == {}
+
+    @pytest.mark.unit
+    def test_distributions_come_from_declared_requirements(self, source_tree):
+        assert project_distributions(source_tree) == ["httpx", "rich", "PyYAML"]
+        tree = parse_tree(source_tree)
+        resolve = build_external_links(
+            _config().site.external_links,
+            source_tree,
+            tree.graph,
+        )
+        [load] = (s for s in tree.symbols if s.name == "load")
+        assert resolve(load)["yaml.Node"] == "https://pypi.org/project/PyYAML/6.0.1/"
+
+    @pytest.mark.unit
+    def test_internal_docs_server(self, source_tree):
+        tree = parse_tree(source_tree)
+        config = _config(
+            stdlib="https://docs.corp.internal/python/{python}/{module}#{name}",
+            template="https://docs.corp.internal/{distribution}/{version}/#{name}",
+            packages={"httpx": "https://www.python-httpx.org/api/#{name}"},
+        ).site.external_links
+        resolve = ExternalLinkResolver(config, tree.graph, {}, "3.12")
+        [load] = (s for s in tree.symbols if s.name == "load")
+        assert resolve(load) == {
+            "pathlib.Path": (
+                "https://docs.corp.internal/python/3.12/pathlib#pathlib.Path"
+            ),
+            "yaml.Node": "https://docs.corp.internal/yaml/latest/#yaml.Node",
+            "httpx.Response": "https://www.python-httpx.org/api/#httpx.Response",
+        }
+
+    @pytest.mark.unit
+    def test_invalid_config(self):
+        assert ProjectConfig().site.external_links == ExternalLinksConfig()
+        assert _config(python=3.12).site.external_links.python == "3.12"
+        with pytest.raises(ProjectConfigError, match="site.external_links.template"):
+            _config(template="https://docs/{package}")
+        with pytest.raises(ProjectConfigError, match="site.external_links.python"):
+            _config(python="py3")
+        with pytest.raises(ProjectConfigError, match="external_links.packages"):
+            _config(packages=["httpx"])
+
+
+class TestRenderedSite:
+    """Tests for external type links in generated pages."""
+
+    @pytest.mark.unit
+    def test_html_and_markdown_link_external_types(self, source_tree):
+        tree = parse_tree(source_tree)
+        sites = render_site(
+            tree,
+            build_model(tree),
+            ("html", "markdown"),
+            _config(),
+        )
+        html = {page.path: page.content for page in sites["html"]}["shop.html"]
+        assert (
+            '<p class="autodoc-external">External types: <a href="https://docs.python'
+            '.org/3.11/library/pathlib.html#pathlib.Path"><code>pathlib.Path</code>'
+            "</a>"
+        ) in html
+        markdown = {page.path: page.content for page in sites["markdown"]}
+        assert (
+            "External types: [`pathlib.Path`](https://docs.python.org/3.11/library/"
+            "pathlib.html#pathlib.Path), "
+        ) in markdown["shop.md"]
+        plain = render_site(tree, build_model(tree), ("html",))["html"]
+        assert "autodoc-external" not in "".join(str(page.content) for page in plain)
@@ -0,0 +1,162 @@
+"""Unit tests for the mechanical docstring fixes of ``autodoc fix``."""
+
+from __future__ import annotations
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.doc_fix import (
+    BLANK_BEFORE_SECTION,
+    SUMMARY_PERIOD,
+    TAG_SECTIONS,
+    DocFixError,
+    fix_source,
+)
+
+SOURCE = '''"""cart totals"""
+
+
+def total(items, tax=0):
+    """return the total of the items.
+    Tax is added on top.
+    Args:
+        items: Priced items
+    Returns:
+        The sum
+    """
+    return sum(items) + tax
+'''
+
+FIXED = '''"""Cart totals."""
+
+
+def total(items, tax=0):
+    """Return the total of the items.
+
+    Tax is added on top.
+
+    Args:
+        items: Priced items
+
+    Returns:
+        The sum
+    """
+    return sum(items) + tax
+'''
+
+
+def test_fixes_capital_period_and_blank_lines() -> None:
+    result = fix_source(SOURCE, "cart.py")
+
+    assert result.fixed == FIXED
+    rules = sorted({fix.rule for fix in result.fixes})
+    assert rules == [
+        "blank-after-summary",
+        "blank-before-section",
+        "summary-capital",
+        "summary-period",
+    ]
+    assert result.fixes[0].format().startswith("cart.py:1: [summary-capital]")
+    assert "+    Tax is added on top." not in result.diff()
+    assert result.diff().startswith("--- a/cart.py\n+++ b/cart.py\n")
+
+
+def test_leaves_identifiers_code_and_unsafe_literals_alone() -> None:
+    source = '''def load(path):
+    """path of the file to load"""
+
+
+def show():
+    """Show it::
+
+        print(x)
+        Args:
+    """
+
+
+def parts():
+    "first" "second"
+
+
+def example():
+    """Add numbers.
+
+    >>> add(1, 2)
+    3
+    """
+'''
+    result = fix_source(source, "m.py")
+
+    assert result.fixed == source.replace("file to load", "file to load.")
+    assert [fix.rule for fix in result.fixes] == [SUMMARY_PERIOD]
+
+
+def test_selected_rules_only_and_crlf_preserved() -> None:
+    source = '"""Parse it\r\n:param x: value\r\n"""\r\nx = 1\r\n'
+
+    result = fix_source(source, "m.py", rules=[BLANK_BEFORE_SECTION])
+
+    assert result.fixed == '"""Parse it\r\n\r\n:param x: value\r\n"""\r\nx = 1\r\n'
+
+
+def test_tag_sections_only_when_selected() -> None:
+    source = '''def create(name, age=0):
+    """Create a user.
+    @param name the user name
+    @param {int} age: age in years
+    @return the new user
+    @throws ValueError if the name is empty
+    """
+
+
+def keep(name):
+    """Keep a user.
+
+    @param name the user name
+
+    Tags must end the docstring to be rewritten.
+    """
+'''
+
+    assert "@param name" in fix_source(source, "users.py").fixed
+    result = fix_source(source, "users.py", [TAG_SECTIONS])
+    assert result.fixed == source.replace(
+        '''    @param name the user name
+    @param {int} age: age in years
+    @return the new user
+    @throws ValueError if the name is empty
+''',
+        '''
+    Args:
+        name: the user name
+        age (int): age in years
+
+    Returns:
+        the new user
+
+    Raises:
+        ValueError: if the name is empty
+''',
+        1,
+    )
+    assert [fix.symbol for fix in result.fixes] == ["create"]
+
+
+def test_unparsable_files_are_reported() -> None:
+    with pytest.raises(DocFixError, match="cannot parse"):
+        fix_source("def broken(:\n", "bad.py")
+
+
+def test_fix_command_previews_then_writes(tmp_path, capsys) -> None:
+    path = tmp_path / "cart.py"
+    path.write_text(SOURCE, encoding="utf-8")
+
+    assert run_command(["fix", "--root", str(tmp_path), "--diff"]) == 1
+    out = capsys.readouterr().out
+    assert "+++ b/cart.py" in out
+    assert "Would fix 6 issue(s) in 1 file(s)" in out
+    assert path.read_text(encoding="utf-8") == SOURCE
+
+    assert run_command(["fix", "--root", str(tmp_path)]) == 0
+    assert path.read_text(encoding="utf-8") == FIXED
+    assert run_command(["fix", "--root", str(tmp_path), "--diff"]) == 0
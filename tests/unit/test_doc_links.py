@@ -0,0 +1,344 @@
+"""Unit tests for the dead reference and link checks."""
+
+import json
+import time
+from pathlib import Path
+
+import httpx
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import LinksConfig, ProjectConfig, ProjectConfigError
+from services.doc_links import (
+    LinkCache,
+    LinkStatus,
+    NetrcCredentials,
+    SymbolIndex,
+    UrlChecker,
+    check_links,
+    docstring_urls,
+    is_placeholder,
+    is_private,
+    private_patterns,
+    symbol_references,
+)
+from services.doc_symbols import DocSymbol
+from services.import_graph import build_import_graph
+
+SOURCES = {
+    "pkg/__init__.py": '"""Package."""\n',
+    "pkg/store.py": (
+        '"""Storage."""\n\n\n'
+        "class Store:\n"
+        '    """A store."""\n\n'
+        "    def get(self, key):\n"
+        '        """Read ``key``, see :meth:`put` and :attr:`Store.size`."""\n\n'
+        "    def put(self, key, value):\n"
+        '        """Write ``value``."""\n'
+    ),
+    "pkg/api.py": (
+        '"""API, backed by [store.Store]."""\n\n'
+        "from pkg import store as backend\n\n\n"
+        "def fetch():\n"
+        '    """Fetch via :func:`backend.Store.get`, not :func:`pkg.store.drop`.\n\n'
+        "    Returns a :class:`dict` or :class:`pathlib.Path`, like\n"
+        "    :func:`requests.get` or [Missing]; [optional] is prose.\n"
+        '    """\n'
+    ),
+}
+
+
+def _symbol(docstring: str, name: str = "pkg.mod.fetch", **kwargs) -> DocSymbol:
+    defaults = {
+        "package": "pkg",
+        "name": name.rsplit(".", 1)[-1],
+        "qualified_name": name,
+        "kind": "function",
+        "file_path": "pkg/mod.py",
+        "lineno": 3,
+        "docstring": docstring,
+    }
+    defaults.update(kwargs)
+    return DocSymbol(**defaults)
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    for name, content in SOURCES.items():
+        path = tmp_path / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content, encoding="utf-8")
+    return tmp_path
+
+
+class _Response:
+    def __init__(self, status_code: int) -> None:
+        self.status_code = status_code
+
+
+class _Client:
+    """Answers ``head`` and ``get`` from a table of URL statuses."""
+
+    def __init__(self, head: dict, get: dict | None = None) -> None:
+        self.head_status = head
+        self.get_status = get or {}
+        self.calls: list[tuple[str, str]] = []
+        self.auth: dict[str, object] = {}
+
+    def _answer(self, method: str, table: dict, url: str, auth) -> _Response:
+        self.calls.append((method, url))
+        self.auth[url] = auth
+        status = table.get(url, 200)
+        if isinstance(status, Exception):
+            raise status
+        return _Response(status)
+
+    def head(self, url, auth=None):
+        return self._answer("HEAD", self.head_status, url, auth)
+
+    def get(self, url, auth=None):
+        return self._answer("GET", self.get_status, url, auth)
+
+    def close(self):
+        pass
+
+
+class TestSymbolReferences:
+    """Tests for :func:`services.doc_links.symbol_references`."""
+
+    @pytest.mark.unit
+    def test_roles_and_brackets(self):
+        docstring = (
+            "See :func:`~pkg.run`, :py:class:`the store <pkg.Store>`, :ref:`x`,\n"
+            ":meth:`Store.get()`, [DocSymbol], [store_name] and [Missing]. The\n"
+            "[optional] flag and [README](x) link are prose, as is ``[Code]``."
+        )
+        assert symbol_references(docstring) == [
+            ("func", "pkg.run"),
+            ("class", "pkg.Store"),
+            ("meth", "Store.get"),
+            ("", "DocSymbol"),
+            ("", "store_name"),
+            ("", "Missing"),
+        ]
+
+    @pytest.mark.unit
+    def test_urls_and_placeholders(self):
+        docstring = "Docs: https://docs.example.org/a, (https://x.io/b).\nNot ftp://c."
+        assert docstring_urls(docstring) == [
+            "https://docs.example.org/a",
+            "https://x.io/b",
+        ]
+        assert is_placeholder("https://example.com/api")
+        assert is_placeholder("http://localhost:8000/")
+        assert not is_placeholder("https://docs.python.org/3/")
+
+
+class TestSymbolIndex:
+    """Tests for :class:`services.doc_links.SymbolIndex`."""
+
+    @pytest.mark.unit
+    def test_dead_references_in_tree(self, source_tree):
+        from autodoc.cli.options import load_doc_symbols
+
+        symbols = load_doc_symbols(str(source_tree))
+        index = SymbolIndex(symbols, build_import_graph(str(source_tree)))
+        findings = check_links(symbols, index)
+        assert [f.symbol for f in findings] == [
+            "pkg.api.fetch: Missing",
+            "pkg.api.fetch: pkg.store.drop",
+        ]
+        assert findings[1].rule == "dead-reference"
+        assert findings[1].severity == "error"
+        assert findings[1].message == (
+            "function pkg.api.fetch references pkg.store.drop, which is not defined"
+        )
+
+    @pytest.mark.unit
+    def test_owner_roles_check_the_owner(self):
+        index = SymbolIndex([_symbol("", "pkg.Store", kind="class")])
+        context = _symbol("")
+        assert index.resolves("attr", "Store.size", context)
+        assert index.resolves("attr", "size", context)
+        assert not index.resolves("attr", "pkg.Nope.size", context)
+
+
+class TestUrlChecker:
+    """Tests for :class:`services.doc_links.UrlChecker`."""
+
+    @pytest.mark.unit
+    def test_head_then_get(self):
+        client = _Client(
+            head={"https://a.io": 405, "https://b.io": 404, "https://c.io": 403},
+            get={"https://b.io": 404},
+        )
+        checker = UrlChecker(client)
+        statuses = checker.check(["https://a.io", "https://b.io", "https://c.io"])
+        assert {url: s.ok for url, s in statuses.items()} == {
+            "https://a.io": True,
+            "https://b.io": False,
+            "https://c.io": True,
+        }
+        assert statuses["https://b.io"].detail == "HTTP 404"
+        assert ("GET", "https://c.io") not in client.calls
+
+    @pytest.mark.unit
+    def test_transport_errors_are_dead(self):
+        error = httpx.HTTPError("connection refused")
+        checker = UrlChecker(_Client(head={"https://down.io": error}))
+        [status] = checker.check(["https://down.io"]).values()
+        assert (status.ok, status.detail) == (False, "connection refused")
+
+    @pytest.mark.unit
+    def test_cache_and_offline(self, tmp_path):
+        path = tmp_path / ".autodoc-links.json"
+        client = _Client(head={"https://gone.io": 410}, get={"https://gone.io": 410})
+        UrlChecker(client, cache=LinkCache.load(path)).check(["https://gone.io"])
+        data = json.loads(path.read_text(encoding="utf-8"))
+        assert data["version"] == 1
+        assert data["links"]["https://gone.io"]["ok"] is False
+
+        again = _Client(head={})
+        UrlChecker(again, cache=LinkCache.load(path)).check(["https://gone.io"])
+        assert again.calls == []
+
+        stale = LinkCache(
+            path,
+            {"https://old.io": LinkStatus("https://old.io", False, "HTTP 500", 0.0)},
+        )
+        offline = UrlChecker(_Client(head={}), cache=stale, offline=True)
+        statuses = offline.check(["https://old.io", "https://new.io"])
+        assert list(statuses) == ["https://old.io"]
+        assert stale.get("https://old.io", max_age=60) is None
+        assert stale.get("https://old.io") is not None
+
+    @pytest.mark.unit
+    def test_private_hosts_use_netrc_logins(self, tmp_path):
+        path = tmp_path / ".netrc"
+        path.write_text(
+            "machine wiki.corp.internal login ci password s3cret\n",
+            encoding="utf-8",
+        )
+        client = _Client(
+            head={"https://wiki.corp.internal/gone": 401, "https://public.io": 401},
+            get={"https://wiki.corp.internal/gone": 401},
+        )
+        checker = UrlChecker(
+            client,
+            private=["*.corp.internal"],
+            credentials=NetrcCredentials(path),
+        )
+        statuses = checker.check(
+            [
+                "https://wiki.corp.internal/gone",
+                "https://git.corp.internal/team/repo",
+                "https://public.io",
+            ],
+        )
+        assert {url: s.ok for url, s in statuses.items()} == {
+            "https://wiki.corp.internal/gone": False,
+            "https://public.io": True,
+        }
+        assert statuses["https://wiki.corp.internal/gone"].detail == (
+            "HTTP 401 with the login for wiki.corp.internal from netrc"
+        )
+        auth = client.auth["https://wiki.corp.internal/gone"]
+        assert isinstance(auth, httpx.BasicAuth)
+        assert client.auth["https://public.io"] is None
+
+    @pytest.mark.unit
+    def test_private_patterns(self):
+        patterns = private_patterns(
+            ["git.acme.io/platform"],
+            {"AUTODOC_PRIVATE": "*.corp.internal, docs.acme.io"},
+        )
+        assert patterns == ["git.acme.io/platform", "*.corp.internal", "docs.acme.io"]
+        assert is_private("https://git.acme.io/platform/api/README", patterns)
+        assert not is_private("https://git.acme.io/other/api", patterns)
+        assert is_private("https://WIKI.corp.internal/", patterns)
+        assert not is_private("https://docs.python.org/3/", patterns)
+        assert NetrcCredentials(Path("missing")).login("docs.acme.io") is None
+
+    @pytest.mark.unit
+    def test_findings_skip_placeholders_and_ignored(self):
+        symbol = _symbol(
+            "See https://dead.io/x, https://example.com/y and https://skip.io/z.",
+        )
+        dead = {"https://dead.io/x": 404}
+        client = _Client(head=dead, get=dead)
+        findings = check_links(
+            [symbol],
+            checker=UrlChecker(client),
+            ignore=["https://skip.io/*"],
+        )
+        assert [(f.rule, f.symbol, f.severity) for f in findings] == [
+            ("dead-link", "pkg.mod.fetch: https://dead.io/x", "warning"),
+        ]
+        assert findings[0].message == (
+            "function pkg.mod.fetch links to https://dead.io/x: HTTP 404"
+        )
+        assert [url for _, url in client.calls] == ["https://dead.io/x"] * 2
+
+
+class TestLinksConfig:
+    """Tests for the ``lint.links`` section."""
+
+    @pytest.mark.unit
+    def test_sections(self):
+        assert ProjectConfig.from_dict({}).lint.links == LinksConfig()
+        assert ProjectConfig.from_dict({"lint": {"links": True}}).lint.links.enabled
+        links = ProjectConfig.from_dict(
+            {"lint": {"links": {"urls": False, "cache": False, "ignore": "http://*"}}},
+        ).lint.links
+        assert (links.enabled, links.urls, links.cache) == (True, False, None)
+        assert links.ignore == ["http://*"]
+        links = ProjectConfig.from_dict(
+            {"lint": {"links": {"private": "*.corp", "netrc": "ci/netrc"}}},
+        ).lint.links
+        assert (links.private, links.netrc) == (["*.corp"], "ci/netrc")
+
+    @pytest.mark.unit
+    def test_invalid_sections(self):
+        with pytest.raises(ProjectConfigError, match="lint.links.timeout"):
+            ProjectConfig.from_dict({"lint": {"links": {"timeout": 0}}})
+        with pytest.raises(ProjectConfigError, match="lint.links.urls"):
+            ProjectConfig.from_dict({"lint": {"links": {"urls": "no"}}})
+
+
+class TestLintCommand:
+    """Tests for link findings in ``autodoc lint``."""
+
+    @pytest.mark.unit
+    def test_dead_references_fail_lint(self, source_tree, capsys):
+        (source_tree / "autodoc.yaml").write_text(
+            "lint:\n  links:\n    urls: false\n",
+            encoding="utf-8",
+        )
+        code = run_command(["lint", "--root", str(source_tree), "--format", "json"])
+        assert code == 1
+        report = json.loads(capsys.readouterr().out)
+        assert report["schema_version"] == 1
+        assert [f["symbol"] for f in report["findings"]] == [
+            "pkg.api.fetch: Missing",
+            "pkg.api.fetch: pkg.store.drop",
+        ]
+
+    @pytest.mark.unit
+    def test_offline_uses_the_cache(self, tmp_path, capsys):
+        (tmp_path / "pkg").mkdir()
+        (tmp_path / "pkg" / "mod.py").write_text(
+            '"""Module."""\n\n\ndef fetch():\n    """See https://gone.io/a."""\n',
+            encoding="utf-8",
+        )
+        (tmp_path / "autodoc.yaml").write_text(
+            "lint:\n  links: true\n",
+            encoding="utf-8",
+        )
+        argv = ["lint", "--root", str(tmp_path), "--offline"]
+        assert run_command(argv) == 0
+        cache = LinkCache(tmp_path / ".autodoc-links.json")
+        cache.put(LinkStatus("https://gone.io/a", False, "HTTP 410", time.time()))
+        cache.save()
+        capsys.readouterr()
+        assert run_command(argv) == 1
+        assert "[dead-link]" in capsys.readouterr().out
@@ -0,0 +1,75 @@
+"""Unit tests for documentation lint rules."""
+
+import pytest
+
+from services.doc_lint import (
+    LintFinding,
+    MissingDocstringRule,
+    lint_symbols,
+    new_findings,
+)
+from services.doc_symbols import DocSymbol
+
+
+def _symbol(name: str, docstring: str | None = None, **kwargs) -> DocSymbol:
+    defaults = {
+        "package": "pkg",
+        "name": name.rsplit(".", 1)[-1],
+        "qualified_name": name,
+        "kind": "function",
+        "file_path": "pkg/mod.py",
+        "lineno": 1,
+        "docstring": docstring,
+    }
+    defaults.update(kwargs)
+    return DocSymbol(**defaults)
+
+
+class TestMissingDocstringRule:
+    """Tests for the missing-docstring rule."""
+
+    @pytest.mark.unit
+    def test_flags_undocumented_exported_symbol(self):
+        findings = list(MissingDocstringRule().check(_symbol("pkg.mod.run")))
+        assert len(findings) == 1
+        assert findings[0].rule == "missing-docstring"
+        assert findings[0].symbol == "pkg.mod.run"
+
+    @pytest.mark.unit
+    def test_ignores_documented_private_and_dunder(self):
+        rule = MissingDocstringRule()
+        assert not list(rule.check(_symbol("pkg.mod.run", "Run it.")))
+        assert not list(rule.check(_symbol("pkg.mod._run", is_public=False)))
+        assert not list(
+            rule.check(_symbol("pkg.mod.C.__init__", kind="method")),
+        )
+
+    @pytest.mark.unit
+    def test_whitespace_docstring_counts_as_missing(self):
+        assert list(MissingDocstringRule().check(_symbol("pkg.mod.run", "   ")))
+
+
+class TestLintSymbols:
+    """Tests for running rules and comparing findings."""
+
+    @pytest.mark.unit
+    def test_findings_are_sorted(self):
+        findings = lint_symbols(
+            [
+                _symbol("pkg.mod.b", lineno=20),
+                _symbol("pkg.mod.a", lineno=10),
+            ],
+        )
+        assert [f.symbol for f in findings] == ["pkg.mod.a", "pkg.mod.b"]
+
+    @pytest.mark.unit
+    def test_new_findings_ignore_line_moves(self):
+        old = LintFinding("missing-docstring", "m", "f.py", 10, "pkg.a")
+        moved = LintFinding("missing-docstring", "m", "f.py", 42, "pkg.a")
+        added = LintFinding("missing-docstring", "m", "f.py", 50, "pkg.b")
+        assert new_findings([moved, added], [old]) == [added]
+
+    @pytest.mark.unit
+    def test_format(self):
+        finding = LintFinding("missing-docstring", "msg", "f.py", 3, "pkg.a")
+        assert finding.format() == "f.py:3: [missing-docstring] msg"
@@ -0,0 +1,202 @@
+"""Unit tests for the multi-repository documentation portal."""
+
+from __future__ import annotations
+
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.doc_portal import (
+    PortalError,
+    PortalManifest,
+    build_portal,
+    load_manifest,
+)
+
+BILLING = '''"""Invoices."""
+
+
+class Invoice:
+    """A bill."""
+
+
+def invoice(order):
+    """Bill an order."""
+'''
+
+SHOP = '''"""Carts."""
+
+from billing.invoices import Invoice
+
+
+class Cart:
+    """A shopping cart."""
+
+    def total(self):
+        return 0
+
+    def checkout(self) -> Invoice:
+        """Bill the cart."""
+'''
+
+
+def _git(repo: Path, *args: str) -> None:
+    subprocess.run(["git", *args], cwd=repo, check=True, capture_output=True)
+
+
+def _package(root: Path, name: str, module: str, source: str) -> Path:
+    (root / name).mkdir(parents=True)
+    (root / name / "__init__.py").write_text("", encoding="utf-8")
+    (root / name / f"{module}.py").write_text(source, encoding="utf-8")
+    return root
+
+
+@pytest.fixture
+def repos(tmp_path: Path) -> Path:
+    """A local billing checkout and a git repository of the shop."""
+    _package(tmp_path / "billing", "billing", "invoices", BILLING)
+    shop = _package(tmp_path / "shop", "shop", "cart", SHOP)
+    _git(shop, "init", "-q", "-b", "main")
+    _git(shop, "config", "user.email", "dev@example.com")
+    _git(shop, "config", "user.name", "Dev")
+    _git(shop, "add", ".")
+    _git(shop, "commit", "-q", "-m", "shop")
+    (tmp_path / "portal.yaml").write_text(
+        "title: Acme\nrepositories:\n"
+        "  - billing\n"
+        f"  - url: file://{shop}\n    name: storefront\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_manifest_validation(tmp_path: Path) -> None:
+    manifest = PortalManifest.from_dict(
+        {"repositories": ["../billing", "https://git.example.com/shop.git"]},
+        tmp_path,
+    )
+    billing, shop = manifest.repositories
+    assert (billing.name, billing.path) == ("billing", tmp_path / "../billing")
+    assert (shop.name, shop.url) == ("shop", "https://git.example.com/shop.git")
+
+    cases = [
+        ({"repositories": []}, "non-empty list"),
+        ({"repositories": [{"path": "a", "url": "b"}]}, "exactly one of path"),
+        ({"repositories": [{"path": "a", "branch": "x"}]}, "unknown key"),
+        ({"repositories": ["a", "x/a"]}, "Two repositories are named a"),
+        ({"repositories": [{"path": "a", "name": "a b"}]}, "not a valid name"),
+        ({"repos": ["a"]}, "Unknown manifest key"),
+    ]
+    for data, message in cases:
+        with pytest.raises(PortalError, match=message):
+            PortalManifest.from_dict(data, tmp_path)
+
+
+def test_portal_combines_paths_and_clones(repos: Path) -> None:
+    manifest = load_manifest(repos / "portal.yaml")
+
+    pages, sites = build_portal(manifest, repos / "cache")
+
+    paths = {page.path for page in pages}
+    assert {"index.html", "search-index.js", "search.js"} <= paths
+    assert "billing/index.html" in paths
+    assert "storefront/index.html" in paths
+    assert (repos / "cache" / "storefront" / "shop" / "cart.py").is_file()
+    content = {page.path: page.content for page in pages}
+    assert "<title>Acme</title>" in content["index.html"]
+    assert 'href="storefront/shop.html"' in content["index.html"]
+    search = content["search-index.js"]
+    assert search.startswith("window.AUTODOC_SEARCH = [")
+    assert '"url": "billing/billing.html#billing.invoices.invoice"' in search
+    assert '"name": "shop.cart.Cart.total"' in search
+    assert [site.repo.name for site in sites] == ["billing", "storefront"]
+
+    # The shop's signature links to the billing repository's page.
+    assert (
+        'href="../billing/billing.html#billing.invoices.Invoice"'
+        in content["storefront/shop.html"]
+    )
+    assert "../billing/" not in content["billing/billing.html"]
+
+    # A second run fetches into the existing clone.
+    build_portal(manifest, repos / "cache")
+
+
+def test_portal_policy_replaces_repository_sanitize(tmp_path: Path) -> None:
+    repo = _package(tmp_path / "billing", "billing", "invoices", BILLING)
+    (repo / "billing" / "__init__.py").write_text(
+        '"""Billing.\n\n<script>steal()</script>\n"""\n',
+        encoding="utf-8",
+    )
+    (repo / "autodoc.yaml").write_text(
+        "site:\n  sanitize: false\n  theme:\n    footer_html: <script>x()</script>\n",
+        encoding="utf-8",
+    )
+    manifest = PortalManifest.from_dict(
+        {"repositories": ["billing"], "sanitize": {"tags": ["kbd"]}},
+        tmp_path,
+    )
+    assert manifest.sanitize.tags == ["kbd"]
+
+    pages, _ = build_portal(manifest, tmp_path / "cache")
+
+    page = {p.path: p.content for p in pages}["billing/billing.html"]
+    assert "<script>" not in page
+    assert "&lt;script&gt;steal()&lt;/script&gt;" in page
+    with pytest.raises(PortalError, match="sanitize.tags must be a list"):
+        PortalManifest.from_dict(
+            {"repositories": ["billing"], "sanitize": {"tags": "<b>"}},
+            tmp_path,
+        )
+
+
+def test_portal_audience(tmp_path: Path) -> None:
+    repo = _package(tmp_path / "billing", "billing", "invoices", BILLING)
+    (repo / "billing" / "admin.py").write_text(
+        '"""Admin tools.\n\n@autodoc:audience internal\n"""\n',
+        encoding="utf-8",
+    )
+    manifest = PortalManifest.from_dict(
+        {"repositories": ["billing"], "audience": "Public"},
+        tmp_path,
+    )
+    assert manifest.audience == "public"
+
+    pages, _ = build_portal(manifest, tmp_path / "cache")
+
+    page = {p.path: p.content for p in pages}["billing/billing.html"]
+    assert "billing.invoices" in page
+    assert "billing.admin" not in page
+    with pytest.raises(PortalError, match="audience must be an audience name"):
+        PortalManifest.from_dict(
+            {"repositories": ["billing"], "audience": ["public"]},
+            tmp_path,
+        )
+
+
+def test_missing_repository_is_reported(tmp_path: Path) -> None:
+    manifest = PortalManifest.from_dict({"repositories": ["gone"]}, tmp_path)
+
+    with pytest.raises(PortalError, match="gone: .* is not a directory"):
+        build_portal(manifest, tmp_path / "cache")
+
+
+def test_portal_command(repos: Path, capsys) -> None:
+    output = repos / "site"
+    args = ["portal", str(repos / "portal.yaml"), "--cache", str(repos / "cache")]
+
+    assert run_command([*args, "--output", str(output), "--dry-run"]) == 0
+    assert f"would create {output / 'index.html'}" in capsys.readouterr().out
+    assert not output.exists()
+
+    assert run_command([*args, "--output", str(output)]) == 0
+    assert "for 2 repositories" in capsys.readouterr().out
+    assert (output / "index.html").is_file()
+    assert (output / "storefront" / "index.html").is_file()
+    assert run_command([*args, "--output", str(output), "--dry-run"]) == 0
+    assert "Dry run: 0 to create" in capsys.readouterr().out
+
+    assert run_command(["portal", str(repos / "missing.yaml")]) == 1
+    assert "Cannot read" in capsys.readouterr().err
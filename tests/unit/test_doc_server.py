@@ -0,0 +1,157 @@
+"""Unit tests for the on-demand documentation server."""
+
+import hashlib
+import io
+import os
+import zipfile
+from pathlib import Path
+
+import httpx
+import pytest
+
+from autodoc.cli.main import run_command
+from services.doc_server import CheckoutSource, DocServer
+from services.package_index import SIMPLE_JSON, IndexRelease, PackageIndexError
+
+
+def _checkout(tmp_path: Path) -> Path:
+    root = tmp_path / "shop"
+    (root / "shop").mkdir(parents=True)
+    (root / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (root / "shop" / "cart.py").write_text(
+        '"""Carts."""\n\n\nclass Cart:\n    """A cart."""\n',
+        encoding="utf-8",
+    )
+    return root
+
+
+def _wheel() -> bytes:
+    buffer = io.BytesIO()
+    with zipfile.ZipFile(buffer, "w") as archive:
+        archive.writestr("billing/__init__.py", '"""Billing."""\n')
+        archive.writestr(
+            "billing/invoice.py",
+            '"""Invoices."""\n\n\ndef total() -> int:\n    """Sum."""\n    return 0\n',
+        )
+        archive.writestr("billing-1.2.0.dist-info/METADATA", "Name: billing\n")
+    return buffer.getvalue()
+
+
+class _Index:
+    """A simple index serving one project, ``billing``, and its wheel."""
+
+    def __init__(self, wheel: bytes, sha256: str | None = None) -> None:
+        self.wheel = wheel
+        self.files = [
+            {"filename": "billing-1.1.0-py3-none-any.whl", "url": "../../f/old.whl"},
+            {
+                "filename": "billing-1.2.0-py3-none-any.whl",
+                "url": "../../f/billing.whl",
+                "hashes": {"sha256": sha256 or hashlib.sha256(wheel).hexdigest()},
+            },
+            {"filename": "billing-2.0.0rc1-py3-none-any.whl", "url": "../../f/rc.whl"},
+        ]
+
+    def get(self, url, headers=None):
+        request = httpx.Request("GET", url)
+        if url == "https://pypi.internal/simple/billing/":
+            return httpx.Response(
+                200,
+                request=request,
+                json={"files": self.files},
+                headers={"content-type": SIMPLE_JSON},
+            )
+        if url == "https://pypi.internal/f/billing.whl":
+            return httpx.Response(200, request=request, content=self.wheel)
+        return httpx.Response(404, request=request)
+
+
+class TestCheckouts:
+    """Tests for documenting source checkouts."""
+
+    @pytest.mark.unit
+    def test_import_paths_redirect_to_rendered_pages(self, tmp_path: Path):
+        root = _checkout(tmp_path)
+        server = DocServer([CheckoutSource(root, "shop")])
+        status, headers, _ = server.respond("/shop/cart/Cart")
+        assert status == 302
+        location = headers["Location"]
+        assert location.startswith("/_sites/shop/")
+        assert location.endswith("#shop.cart.Cart")
+        status, headers, body = server.respond(location.split("#")[0])
+        assert status == 200
+        assert headers["Content-Type"].startswith("text/html")
+        assert b'id="shop.cart.Cart"' in body
+        assert server.respond("/_go?path=shop.cart")[1]["Location"].endswith(
+            "#shop.cart",
+        )
+        assert b"<code>shop</code>" in server.respond("/")[2]
+        assert server.respond("/warehouse")[0] == 404
+        assert server.respond("/_sites/shop/missing.html")[0] == 404
+
+    @pytest.mark.unit
+    def test_changed_sources_are_rendered_again(self, tmp_path: Path):
+        root = _checkout(tmp_path)
+        server = DocServer([CheckoutSource(root, "shop")])
+        first = server.rendered("shop")
+        assert server.rendered("shop") is first
+        cart = root / "shop" / "cart.py"
+        cart.write_text(
+            cart.read_text(encoding="utf-8")
+            + '\n\ndef empty() -> None:\n    """Empty."""\n',
+            encoding="utf-8",
+        )
+        os.utime(cart, (first.stamp[1] + 10, first.stamp[1] + 10))
+        assert "shop.cart.empty" in server.rendered("shop").modules["shop.cart"][1]
+
+
+class TestIndex:
+    """Tests for releases downloaded from a package index."""
+
+    @pytest.mark.unit
+    def test_newest_final_release_is_downloaded(self, tmp_path: Path):
+        source = IndexRelease(
+            "https://pypi.internal/simple",
+            "billing",
+            tmp_path / "cache",
+            client=_Index(_wheel()),
+        )
+        server = DocServer([source])
+        location = server.respond("/billing/invoice/total")[1]["Location"]
+        assert location.endswith("#billing.invoice.total")
+        assert source.version == "1.2.0"
+        assert (tmp_path / "cache" / "billing-1.2.0" / "billing").is_dir()
+
+    @pytest.mark.unit
+    def test_hash_mismatch_and_missing_release(self, tmp_path: Path):
+        client = _Index(_wheel(), sha256="0" * 64)
+        source = IndexRelease(
+            "https://pypi.internal/simple",
+            "billing",
+            tmp_path,
+            client,
+        )
+        with pytest.raises(PackageIndexError, match="does not match its sha256 hash"):
+            source.root()
+        pinned = IndexRelease(
+            "https://pypi.internal/simple",
+            "billing==9.0",
+            tmp_path,
+            _Index(_wheel()),
+        )
+        status, _, body = DocServer([pinned]).respond("/billing")
+        assert status == 502
+        assert b"has no release of billing==9.0" in body
+        with pytest.raises(PackageIndexError, match="is not a release"):
+            IndexRelease("https://pypi.internal/simple", "billing>=1", tmp_path)
+
+
+class TestCommand:
+    """Tests for ``autodoc serve`` arguments."""
+
+    @pytest.mark.unit
+    def test_requires_sources(self, capsys):
+        assert run_command(["serve"]) == 1
+        assert "Nothing to serve" in capsys.readouterr().err
+        assert run_command(["serve", "--package", "billing>=1"]) == 1
+        assert "is not a release like billing" in capsys.readouterr().err
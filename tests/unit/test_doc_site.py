@@ -0,0 +1,409 @@
+"""Unit tests for the documentation site model, renderers, and HTML extras."""
+
+import json
+from collections import Counter
+from dataclasses import replace
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import (
+    EditLinkConfig,
+    HighlightConfig,
+    ProjectConfig,
+    ProjectConfigError,
+    SiteConfig,
+    ThemeConfig,
+)
+from services.doc_edit_links import EditLinkBuilder
+from services.doc_highlight import HighlightError, Highlighter
+from services.doc_html import render_docstring, render_html_site
+from services.doc_json import render_json_site
+from services.doc_markdown import heading_slug, render_markdown_site
+import services.doc_site
+from services.doc_site import (
+    SitePage,
+    SiteWriteError,
+    build_site_model,
+    content_hash,
+    generation_time,
+    plan_site,
+    signature,
+    stamp_pages,
+    write_site,
+)
+from services.doc_symbols import load_doc_symbols
+from services.doc_theme import ThemeError, build_theme_assets, stylesheet
+
+
+@pytest.fixture
+def packages(tmp_path: Path):
+    """Site model for a package with a module, class, and function."""
+    pkg = tmp_path / "shop"
+    pkg.mkdir()
+    (pkg / "__init__.py").write_text('"""Shop package."""\n', encoding="utf-8")
+    (pkg / "cart.py").write_text(
+        '"""Shopping carts."""\n\n'
+        "class Cart(Base):\n"
+        '    """A cart."""\n\n'
+        "    def add(self, item: str, qty: int = 1) -> None:\n"
+        '        """Add <item>."""\n\n'
+        "    def _helper(self):\n"
+        "        pass\n\n"
+        "async def checkout(cart, *items, **options):\n"
+        "    pass\n",
+        encoding="utf-8",
+    )
+    return build_site_model(load_doc_symbols(tmp_path))
+
+
+class TestSiteModel:
+    """Tests for grouping symbols into pages."""
+
+    @pytest.mark.unit
+    def test_groups_exported_symbols(self, packages):
+        assert [p.name for p in packages] == ["shop"]
+        modules = packages[0].modules
+        assert [m.name for m in modules] == ["shop", "shop.cart"]
+        cart = modules[1]
+        assert [f.name for f in cart.functions] == ["checkout"]
+        assert [m.name for m in cart.classes[0].methods] == ["add"]
+
+    @pytest.mark.unit
+    def test_signatures(self, packages):
+        cart = packages[0].modules[1]
+        assert signature(cart.classes[0].symbol) == "class Cart(Base)"
+        assert signature(cart.classes[0].methods[0]) == (
+            "def add(item: str, qty: int = 1) -> None"
+        )
+        assert signature(cart.functions[0]) == (
+            "async def checkout(cart, *items, **options)"
+        )
+
+    @pytest.mark.unit
+    def test_content_hash_tracks_signature_and_docstring(self, packages):
+        add = packages[0].modules[1].classes[0].methods[0]
+        reindented = replace(
+            add,
+            docstring="\n        Add <item>.   \n    ",
+            file_path="elsewhere.py",
+            lineno=99,
+        )
+        assert content_hash(reindented) == content_hash(add)
+        assert len(content_hash(add)) == 64
+        retyped = replace(
+            add,
+            metadata={**add.metadata, "return_type": "bool"},
+        )
+        assert content_hash(retyped) != content_hash(add)
+        assert content_hash(replace(add, docstring="Add it.")) != content_hash(add)
+
+
+class TestRenderers:
+    """Tests for Markdown and HTML output."""
+
+    @pytest.mark.unit
+    def test_markdown_site(self, packages):
+        pages = {p.path: p.content for p in render_markdown_site(packages, SiteConfig())}
+        assert set(pages) == {"index.md", "shop.md"}
+        assert "- [shop](shop.md) - Shop package." in pages["index.md"]
+        assert "def add(item: str, qty: int = 1) -> None" in pages["shop.md"]
+        assert "_No documentation._" in pages["shop.md"]
+
+    @pytest.mark.unit
+    def test_heading_slugs_follow_git_host_rules(self):
+        seen = Counter()
+        assert heading_slug("`shop.cart`", seen) == "shopcart"
+        assert heading_slug("Cart.add", seen) == "cartadd"
+        assert heading_slug("Cart add", seen) == "cart-add"
+        assert heading_slug("`Cart.add`", seen) == "cartadd-1"
+
+    @pytest.mark.unit
+    def test_markdown_toc_and_index_links(self, packages):
+        pages = {p.path: p.content for p in render_markdown_site(packages, SiteConfig())}
+        page = pages["shop.md"]
+        assert (
+            "## Contents\n\n"
+            "- [`shop`](#shop-1)\n"
+            "- [`shop.cart`](#shopcart)\n"
+            "  - [`checkout`](#checkout)\n"
+            "  - [`Cart`](#cart)\n"
+            "    - [`Cart.add`](#cartadd)\n"
+        ) in page
+        assert "#### `Cart.add`" in page
+        assert "  - [`shop.cart`](shop.md#shopcart) - Shopping carts." in pages["index.md"]
+
+    @pytest.mark.unit
+    def test_html_escapes_and_links_theme(self, packages, tmp_path):
+        site = SiteConfig(title="Shop API", theme=ThemeConfig(footer_html="<b>Acme</b>"))
+        assets = build_theme_assets(site.theme, tmp_path)
+        pages = {p.path: p.content for p in render_html_site(packages, site, assets)}
+
+        assert set(pages) == {"index.html", "shop.html", "assets/autodoc.css"}
+        html = pages["shop.html"]
+        assert 'id="shop.cart.Cart.add"' in html
+        assert "Add &lt;item&gt;." in html
+        assert '<link rel="stylesheet" href="assets/autodoc.css">' in html
+        assert "<b>Acme</b>" in html
+
+    @pytest.mark.unit
+    def test_json_site(self, packages):
+        pages = render_json_site(packages, SiteConfig(title="Shop API"))
+        assert [p.path for p in pages] == ["index.json"]
+        data = json.loads(pages[0].content)
+
+        assert data["title"] == "Shop API"
+        cart = data["packages"][0]["modules"][1]
+        assert cart["qualified_name"] == "shop.cart"
+        assert cart["functions"][0]["signature"] == (
+            "async def checkout(cart, *items, **options)"
+        )
+        assert [m["name"] for m in cart["classes"][0]["methods"]] == ["add"]
+        checkout = packages[0].modules[1].functions[0]
+        assert cart["functions"][0]["content_hash"] == content_hash(checkout)
+
+    @pytest.mark.unit
+    def test_json_paths_are_root_relative(self, packages, tmp_path):
+        pages = render_json_site(packages, SiteConfig(), root=tmp_path)
+        data = json.loads(pages[0].content)
+
+        assert data["root"] == "."
+        module = data["packages"][0]["modules"][1]
+        assert module["file_path"] == "shop/cart.py"
+
+    @pytest.mark.unit
+    def test_generate_writes_each_format_into_a_subdirectory(self, tmp_path):
+        root = tmp_path / "src"
+        root.mkdir()
+        (root / "mod.py").write_text('"""Mod."""\n', encoding="utf-8")
+        output = tmp_path / "site"
+
+        code = run_command(
+            [
+                "generate",
+                "--root",
+                str(root),
+                "--output",
+                str(output),
+                "--format",
+                "markdown,json",
+            ],
+        )
+
+        assert code == 0
+        assert sorted(p.name for p in output.iterdir()) == ["json", "markdown"]
+        assert (output / "markdown" / "index.md").is_file()
+        assert (output / "json" / "index.json").is_file()
+
+    @pytest.mark.unit
+    def test_output_does_not_depend_on_input_order(self, packages):
+        symbols = [s for p in packages for s in p.symbols()]
+        shuffled = build_site_model(list(reversed(symbols)))
+
+        for render in (render_markdown_site, render_json_site):
+            assert render(shuffled, SiteConfig()) == render(packages, SiteConfig())
+
+    @pytest.mark.unit
+    def test_timestamp_is_opt_in_and_reproducible(self):
+        when = generation_time({"SOURCE_DATE_EPOCH": "1700000000"})
+        pages = stamp_pages(
+            [
+                SitePage("index.md", "# API\n"),
+                SitePage("index.json", '{"title": "API"}'),
+                SitePage("shop.md", "# shop\n"),
+            ],
+            when,
+        )
+
+        assert pages[0].content == "# API\n\n_Generated 2023-11-14T22:13:20Z._\n"
+        assert json.loads(pages[1].content)["generated_at"] == "2023-11-14T22:13:20Z"
+        assert pages[2].content == "# shop\n"
+
+    @pytest.mark.unit
+    def test_docstring_examples_are_preformatted(self):
+        html = render_docstring("Usage:\n\n    >>> run(1)\n    2")
+        assert html == "<p>Usage:</p>\n<pre><code>&gt;&gt;&gt; run(1)\n2</code></pre>"
+
+
+class TestSiteOutput:
+    """Tests for replacing the output directory."""
+
+    @pytest.mark.unit
+    def test_stale_pages_are_pruned_and_hand_added_files_kept(self, tmp_path):
+        site = tmp_path / "site"
+        write_site([SitePage("index.md", "a"), SitePage("old/pkg.md", "b")], site)
+        (site / "CNAME").write_text("docs.example.com\n", encoding="utf-8")
+
+        pages = [SitePage("index.md", "a2")]
+        plan = plan_site(pages, site)
+        write_site(pages, site)
+
+        assert [w.to_dict() for w in plan.writes] == [
+            {"path": (site / "index.md").as_posix(), "action": "modify"},
+            {"path": (site / "old/pkg.md").as_posix(), "action": "delete"},
+        ]
+        assert (site / "index.md").read_text(encoding="utf-8") == "a2"
+        assert not (site / "old").exists()
+        assert (site / "CNAME").exists()
+        assert sorted(p.name for p in tmp_path.iterdir()) == ["site"]
+
+    @pytest.mark.unit
+    def test_failed_write_keeps_previous_site(self, tmp_path, monkeypatch):
+        site = tmp_path / "site"
+        write_site([SitePage("index.md", "old")], site)
+
+        def fail(root, page):
+            raise OSError("disk full")
+
+        monkeypatch.setattr(services.doc_site, "_write_page", fail)
+        with pytest.raises(OSError):
+            write_site([SitePage("index.md", "new")], site)
+
+        assert (site / "index.md").read_text(encoding="utf-8") == "old"
+        assert sorted(p.name for p in tmp_path.iterdir()) == ["site"]
+
+    @pytest.mark.unit
+    def test_refuses_to_replace_working_directory(self, tmp_path, monkeypatch):
+        monkeypatch.chdir(tmp_path)
+
+        with pytest.raises(SiteWriteError):
+            write_site([SitePage("index.md", "x")], ".")
+
+
+class TestTheme:
+    """Tests for theme configuration and assets."""
+
+    @pytest.mark.unit
+    def test_stylesheet_uses_brand_colors(self):
+        css = stylesheet(ThemeConfig(mode="dark", primary_color="#d4351c"))
+        assert "--autodoc-primary: #d4351c;" in css
+        assert "--autodoc-bg: #0d1117;" in css
+        assert "prefers-color-scheme" not in css
+        assert "prefers-color-scheme: dark" in stylesheet(ThemeConfig(mode="auto"))
+
+    @pytest.mark.unit
+    def test_logo_and_custom_css_are_copied(self, tmp_path):
+        (tmp_path / "logo.svg").write_text("<svg/>", encoding="utf-8")
+        (tmp_path / "brand.css").write_text("h1 {}", encoding="utf-8")
+        theme = ThemeConfig(logo="logo.svg", custom_css=["brand.css"])
+
+        assets = build_theme_assets(theme, tmp_path)
+
+        assert assets.logo == "assets/logo.svg"
+        assert assets.stylesheets == ["assets/autodoc.css", "assets/custom-1.css"]
+        assert {p.path: p.content for p in assets.pages}["assets/custom-1.css"] == (
+            b"h1 {}"
+        )
+
+    @pytest.mark.unit
+    def test_missing_asset_raises(self, tmp_path):
+        with pytest.raises(ThemeError):
+            build_theme_assets(ThemeConfig(logo="missing.png"), tmp_path)
+
+    @pytest.mark.unit
+    def test_invalid_theme_config(self):
+        with pytest.raises(ProjectConfigError):
+            ProjectConfig.from_dict({"site": {"theme": {"mode": "sepia"}}})
+        with pytest.raises(ProjectConfigError):
+            ProjectConfig.from_dict({"site": {"theme": {"primary_color": "red"}}})
+
+
+class TestEditLinks:
+    """Tests for per-symbol edit links on the code host."""
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        ("host", "expected"),
+        [
+            ("github", "https://h/acme/shop/edit/main/shop/cart.py#L3"),
+            ("gitlab", "https://h/acme/shop/-/edit/main/shop/cart.py#L3"),
+            (
+                "bitbucket",
+                "https://h/acme/shop/src/main/shop/cart.py?mode=edit&at=main#lines-3",
+            ),
+        ],
+    )
+    def test_host_templates(self, packages, tmp_path, host, expected):
+        config = EditLinkConfig.from_dict(
+            {"repo_url": "https://h/acme/shop/", "host": host},
+        )
+        cart = packages[0].modules[1].classes[0].symbol
+        assert EditLinkBuilder(config, tmp_path)(cart) == expected
+
+    @pytest.mark.unit
+    def test_custom_template_and_outside_paths(self, packages, tmp_path):
+        config = EditLinkConfig(
+            repo_url="https://code.example/shop",
+            branch="release/1.x",
+            template="{repo_url}/files/{path}?ref={branch}&line={line}",
+        )
+        builder = EditLinkBuilder(config, tmp_path / "shop")
+        module = packages[0].modules[1].symbol
+        assert builder(module) == (
+            "https://code.example/shop/files/cart.py?ref=release/1.x&line=1"
+        )
+        assert EditLinkBuilder(config, tmp_path / "other")(module) is None
+
+    @pytest.mark.unit
+    def test_rendered_pages_include_links(self, packages, tmp_path):
+        config = EditLinkConfig(repo_url="https://github.com/acme/shop")
+        builder = EditLinkBuilder(config, tmp_path)
+        markdown = render_markdown_site(packages, SiteConfig(), builder)[1].content
+        assert (
+            "[Edit this doc comment]"
+            "(https://github.com/acme/shop/edit/main/shop/cart.py#L6)"
+        ) in markdown
+
+        assets = build_theme_assets(ThemeConfig(), tmp_path)
+        html = render_html_site(packages, SiteConfig(), assets, builder)[1].content
+        assert (
+            'class="autodoc-edit" '
+            'href="https://github.com/acme/shop/edit/main/shop/cart.py#L6"'
+        ) in html
+
+    @pytest.mark.unit
+    def test_invalid_edit_link_config(self):
+        with pytest.raises(ProjectConfigError):
+            EditLinkConfig.from_dict({"host": "github"})
+        with pytest.raises(ProjectConfigError):
+            EditLinkConfig.from_dict({"repo_url": "https://x", "host": "svn"})
+        with pytest.raises(ProjectConfigError):
+            EditLinkConfig.from_dict({"repo_url": "https://x", "template": "{file}"})
+
+
+class TestHighlighting:
+    """Tests for server-side syntax highlighting."""
+
+    @pytest.mark.unit
+    def test_signatures_and_examples_are_highlighted(self, packages, tmp_path):
+        highlighter = Highlighter(HighlightConfig(), mode="light")
+        assets = build_theme_assets(ThemeConfig(mode="light"), tmp_path)
+        pages = {
+            p.path: p.content
+            for p in render_html_site(packages, SiteConfig(), assets, None, highlighter)
+        }
+
+        assert '<code class="autodoc-hl"><span class="k">def</span>' in pages["shop.html"]
+        assert '<link rel="stylesheet" href="assets/highlight.css">' in pages["shop.html"]
+        assert ".autodoc-hl .k" in pages["assets/highlight.css"]
+        assert "prefers-color-scheme" not in pages["assets/highlight.css"]
+
+        doctest = render_docstring("Example:\n\n    >>> f()", highlighter)
+        assert '<span class="gp">&gt;&gt;&gt; </span>' in doctest
+
+    @pytest.mark.unit
+    def test_auto_mode_adds_dark_styles(self):
+        css = Highlighter(HighlightConfig(), mode="auto").stylesheet()
+        assert "@media (prefers-color-scheme: dark)" in css
+
+    @pytest.mark.unit
+    def test_disabled_highlighting_escapes_only(self):
+        highlighter = Highlighter(HighlightConfig(enabled=False))
+        assert highlighter.highlight("a < b") == "a &lt; b"
+        assert highlighter.stylesheet() == ""
+
+    @pytest.mark.unit
+    def test_unknown_style(self):
+        with pytest.raises(HighlightError):
+            Highlighter(HighlightConfig(style="no-such-style"))
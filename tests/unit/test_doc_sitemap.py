@@ -0,0 +1,131 @@
+"""Unit tests for sitemaps, robots.txt, and canonical URLs."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.doc_sitemap import canonical_url, render_sitemap
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    root = tmp_path / "src"
+    (root / "shop").mkdir(parents=True)
+    (root / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (root / "shop" / "cart.py").write_text(
+        '"""Carts."""\n\n\ndef checkout(cart):\n    """Pay."""\n',
+        encoding="utf-8",
+    )
+    return root
+
+
+def _site(**data) -> SiteConfig:
+    return ProjectConfig.from_dict({"site": data}).site
+
+
+class TestSitemap:
+    """Tests for :func:`services.doc_sitemap.render_sitemap`."""
+
+    @pytest.mark.unit
+    def test_sitemap_and_robots(self):
+        site = _site(
+            base_url="https://docs.acme.internal/platform",
+            sitemap={"exclude": ["internal-*.html"]},
+        )
+        assert site.base_url == "https://docs.acme.internal/platform/"
+        files = render_sitemap(
+            site,
+            ["shop.html", "index.html", "internal-ops.html", "assets/autodoc.css"],
+        )
+        assert {page.path: page.content for page in files} == {
+            "sitemap.xml": (
+                '<?xml version="1.0" encoding="UTF-8"?>\n'
+                '<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">\n'
+                "  <url><loc>https://docs.acme.internal/platform/</loc></url>\n"
+                "  <url><loc>https://docs.acme.internal/platform/shop.html</loc>"
+                "</url>\n"
+                "</urlset>\n"
+            ),
+            "robots.txt": (
+                "User-agent: *\n"
+                "Disallow: /platform/internal-ops.html\n\n"
+                "Sitemap: https://docs.acme.internal/platform/sitemap.xml\n"
+            ),
+        }
+        assert canonical_url(site.base_url, "guide/index.html") == (
+            "https://docs.acme.internal/platform/guide/"
+        )
+
+    @pytest.mark.unit
+    def test_optional_files(self):
+        assert render_sitemap(SiteConfig(), ["index.html"]) == []
+        base = "https://docs.example.com/"
+        assert render_sitemap(_site(base_url=base, sitemap=False), ["index.html"]) == []
+        files = render_sitemap(
+            _site(base_url=base, sitemap={"robots": False}),
+            ["index.html"],
+        )
+        assert [page.path for page in files] == ["sitemap.xml"]
+        robots = render_sitemap(_site(base_url=base), ["index.html"])[1].content
+        assert robots.startswith("User-agent: *\nDisallow:\n")
+
+    @pytest.mark.unit
+    def test_invalid_config(self):
+        with pytest.raises(ProjectConfigError, match="site.base_url must be"):
+            _site(base_url="docs.example.com")
+        with pytest.raises(ProjectConfigError, match="site.sitemap.robots"):
+            _site(sitemap={"robots": "yes"})
+
+
+class TestHostedSite:
+    """Tests for canonical URLs and sitemaps in generated HTML sites."""
+
+    @pytest.mark.unit
+    def test_html_pages_link_their_canonical_url(self, source_tree):
+        config = ProjectConfig.from_dict(
+            {"site": {"base_url": "https://docs.example.com/api/"}},
+        )
+        tree = parse_tree(source_tree)
+        sites = render_site(tree, build_model(tree), ("html", "markdown"), config)
+        html = {page.path: page.content for page in sites["html"]}
+        assert (
+            '<link rel="canonical" href="https://docs.example.com/api/shop.html">'
+        ) in html["shop.html"]
+        assert '<link rel="canonical" href="https://docs.example.com/api/">' in (
+            html["index.html"]
+        )
+        assert "<loc>https://docs.example.com/api/shop.html</loc>" in (
+            html["sitemap.xml"]
+        )
+        markdown = {page.path for page in sites["markdown"]}
+        assert "sitemap.xml" not in markdown
+
+    @pytest.mark.unit
+    def test_incremental_runs_keep_every_page(self, source_tree, tmp_path):
+        (source_tree / "autodoc.yaml").write_text(
+            "site:\n  base_url: https://docs.example.com/\n",
+            encoding="utf-8",
+        )
+        output = tmp_path / "site"
+        argv = [
+            "generate",
+            "--root",
+            str(source_tree),
+            "--format",
+            "html",
+            "--output",
+            str(output),
+            "--incremental",
+        ]
+        assert run_command(argv) == 0
+        (source_tree / "other.py").write_text('"""Other."""\n', encoding="utf-8")
+        assert run_command(argv) == 0
+        sitemap = (output / "sitemap.xml").read_text(encoding="utf-8")
+        assert "<loc>https://docs.example.com/shop.html</loc>" in sitemap
+        assert "<loc>https://docs.example.com/src.html</loc>" in sitemap
+        assert (output / "robots.txt").is_file()
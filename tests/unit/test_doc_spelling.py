@@ -0,0 +1,154 @@
+"""Unit tests for the docstring spelling and terminology rules."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import (
+    LintConfig,
+    ProjectConfig,
+    ProjectConfigError,
+    TerminologyConfig,
+)
+from services.custom_lint_rules import RuleConfigError, rules_from_config
+from services.doc_lint import lint_symbols
+from services.doc_spelling import SpellingRule, TerminologyRule, prose
+from services.doc_symbols import DocSymbol
+
+
+def _symbol(docstring: str, name: str = "pkg.mod.fetch", **kwargs) -> DocSymbol:
+    defaults = {
+        "package": "pkg",
+        "name": name.rsplit(".", 1)[-1],
+        "qualified_name": name,
+        "kind": "function",
+        "file_path": "pkg/mod.py",
+        "lineno": 3,
+        "docstring": docstring,
+    }
+    defaults.update(kwargs)
+    return DocSymbol(**defaults)
+
+
+class TestSpellingRule:
+    """Tests for :class:`services.doc_spelling.SpellingRule`."""
+
+    @pytest.mark.unit
+    def test_reports_misspellings_with_fixes(self):
+        symbol = _symbol(
+            "Recieve the payload.\n\nSeperate teh parts, then recieve more.",
+        )
+        findings = list(SpellingRule.from_words().check(symbol))
+        assert [(f.symbol, f.suggestion) for f in findings] == [
+            ("pkg.mod.fetch: Recieve", "Receive"),
+            ("pkg.mod.fetch: Seperate", "Separate"),
+            ("pkg.mod.fetch: teh", "the"),
+        ]
+        assert findings[0].message == (
+            "function pkg.mod.fetch: 'Recieve' is misspelled, use 'Receive'"
+        )
+        assert findings[0].severity == "warning"
+
+    @pytest.mark.unit
+    def test_project_dictionary_and_corrections(self):
+        rule = SpellingRule.from_words(["teh"], {"serialise": "serialize"})
+        findings = rule.check(_symbol("Serialise teh value."))
+        assert [f.suggestion for f in findings] == ["Serialize"]
+
+    @pytest.mark.unit
+    def test_skips_code_and_private_symbols(self):
+        docstring = (
+            "Read ``recieve`` and `teh`, see https://example.com/seperate.\n\n"
+            "Example::\n\n    recieve(teh)\n\n>>> seperate()\n"
+        )
+        assert prose(docstring).split() == ["Read", "and", ",", "see", "Example::"]
+        rule = SpellingRule.from_words()
+        assert not list(rule.check(_symbol(docstring)))
+        assert not list(rule.check(_symbol("Recieve.", is_public=False)))
+
+
+class TestTerminologyRule:
+    """Tests for :class:`services.doc_spelling.TerminologyRule`."""
+
+    @pytest.mark.unit
+    def test_reports_avoided_terms(self):
+        rule = TerminologyRule.from_config(
+            [TerminologyConfig("user ID", ["userid", "user id", "user-id"])],
+        )
+        symbol = _symbol(
+            "Look up a userid.\n\nUser ID and user ID are fine; User-Id is not.",
+        )
+        findings = list(rule.check(symbol))
+        assert [(f.symbol, f.suggestion) for f in findings] == [
+            ("pkg.mod.fetch: userid", "user ID"),
+            ("pkg.mod.fetch: User-Id", "user ID"),
+        ]
+        assert findings[0].message == (
+            "function pkg.mod.fetch: write 'user ID' instead of 'userid'"
+        )
+
+
+class TestWordingConfig:
+    """Tests for the ``lint.spelling`` and ``lint.terminology`` sections."""
+
+    @pytest.mark.unit
+    def test_rules_are_opt_in(self, tmp_path):
+        assert [rule.id for rule in rules_from_config(LintConfig())] == [
+            "missing-docstring",
+        ]
+        (tmp_path / "words.txt").write_text("# project words\nteh\n", encoding="utf-8")
+        config = ProjectConfig.from_dict(
+            {
+                "lint": {
+                    "spelling": {"dictionary": "words.txt"},
+                    "terminology": [{"prefer": "user ID", "avoid": "userid"}],
+                },
+            },
+        )
+        rules = rules_from_config(config.lint, tmp_path)
+        assert [rule.id for rule in rules] == [
+            "missing-docstring",
+            "spelling",
+            "terminology",
+        ]
+        findings = lint_symbols([_symbol("Fetch teh userid.")], rules)
+        assert [f.rule for f in findings] == ["terminology"]
+
+    @pytest.mark.unit
+    def test_invalid_sections(self, tmp_path):
+        with pytest.raises(ProjectConfigError, match="lint.terminology"):
+            ProjectConfig.from_dict({"lint": {"terminology": [{"prefer": "user ID"}]}})
+        with pytest.raises(ProjectConfigError, match="lint.spelling.corrections"):
+            ProjectConfig.from_dict({"lint": {"spelling": {"corrections": ["x"]}}})
+        config = ProjectConfig.from_dict(
+            {"lint": {"spelling": {"dictionary": "no.txt"}}},
+        )
+        with pytest.raises(RuleConfigError, match="spelling dictionary"):
+            rules_from_config(config.lint, tmp_path)
+        with pytest.raises(RuleConfigError, match="Duplicate rule id"):
+            rules_from_config(
+                LintConfig(rules=[{"id": "spelling", "require": "documented"}]),
+            )
+
+
+class TestLintCommand:
+    """Tests for spelling findings in ``autodoc lint``."""
+
+    @pytest.mark.unit
+    def test_json_findings_carry_suggestions(self, tmp_path: Path, capsys):
+        (tmp_path / "pkg").mkdir()
+        (tmp_path / "pkg" / "mod.py").write_text(
+            '"""Module."""\n\n\ndef fetch():\n    """Fetch teh rows."""\n',
+            encoding="utf-8",
+        )
+        (tmp_path / "autodoc.yaml").write_text(
+            "lint:\n  spelling: true\n",
+            encoding="utf-8",
+        )
+        assert run_command(["lint", "--root", str(tmp_path), "--format", "json"]) == 1
+        [finding] = json.loads(capsys.readouterr().out)["findings"]
+        assert finding["rule"] == "spelling"
+        assert finding["symbol"] == "pkg.mod.fetch: teh"
+        assert finding["suggestion"] == "the"
@@ -0,0 +1,218 @@
+"""Unit tests for docstring translation catalogs."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfig, ProjectConfigError
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_translations import (
+    Catalog,
+    Message,
+    TranslationError,
+    catalog_path,
+    extract_messages,
+    load_catalog,
+    localize,
+    merge_catalog,
+    parse_catalog,
+    render_catalog,
+    source_hash,
+)
+
+SOURCES = {
+    "billing/__init__.py": '"""Billing."""\n',
+    "billing/invoice.py": (
+        '"""Invoices."""\n\n\n'
+        "class Invoice:\n"
+        '    """A bill sent to a customer.\n\n'
+        '    Paid "in full" or not at all.\n    """\n\n\n'
+        "def send(invoice):\n"
+        '    """Email the invoice."""\n\n\n'
+        "def undocumented():\n"
+        "    pass\n"
+    ),
+}
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    for name, content in SOURCES.items():
+        path = tmp_path / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content, encoding="utf-8")
+    return tmp_path
+
+
+def _template(root: Path) -> Catalog:
+    tree = parse_tree(root)
+    return extract_messages(build_model(tree), root)
+
+
+class TestExtract:
+    """Tests for :func:`services.doc_translations.extract_messages`."""
+
+    @pytest.mark.unit
+    def test_one_message_per_docstring(self, source_tree):
+        template = _template(source_tree)
+        assert [(m.symbol, m.references) for m in template.messages] == [
+            ("billing", ["billing/__init__.py:1"]),
+            ("billing.invoice", ["billing/invoice.py:1"]),
+            ("billing.invoice.send", ["billing/invoice.py:11"]),
+            ("billing.invoice.Invoice", ["billing/invoice.py:4"]),
+        ]
+        invoice = template.messages[3]
+        assert invoice.source == (
+            'A bill sent to a customer.\n\nPaid "in full" or not at all.'
+        )
+        assert invoice.source_hash == source_hash(invoice.source)
+
+    @pytest.mark.unit
+    def test_po_round_trip(self, source_tree):
+        template = _template(source_tree)
+        text = render_catalog(template)
+        assert (
+            "#. source-hash: " + template.messages[3].source_hash + "\n"
+            "#: billing/invoice.py:4\n"
+            'msgctxt "billing.invoice.Invoice"\n'
+            'msgid ""\n'
+            '"A bill sent to a customer.\\n"\n'
+            '"\\n"\n'
+            '"Paid \\"in full\\" or not at all."\n'
+            'msgstr ""\n'
+        ) in text
+        assert parse_catalog(text).messages == template.messages
+
+
+class TestMerge:
+    """Tests for :func:`services.doc_translations.merge_catalog`."""
+
+    @pytest.mark.unit
+    def test_changed_sources_become_fuzzy(self):
+        existing = Catalog(
+            "de",
+            [
+                Message("a.f", "Fetch rows.", "Holt Zeilen.", comments=["checked"]),
+                Message("a.g", "Same.", "Gleich."),
+                Message("a.gone", "Removed.", "Entfernt."),
+            ],
+        )
+        template = Catalog(
+            messages=[
+                Message("a.f", "Fetch all rows."),
+                Message("a.g", "Same."),
+                Message("a.new", "New."),
+            ],
+        )
+        merged, removed = merge_catalog(template, existing, "de")
+        assert removed == 1
+        fetch, same, new = merged.messages
+        assert (fetch.translation, fetch.fuzzy, fetch.previous) == (
+            "Holt Zeilen.",
+            True,
+            "Fetch rows.",
+        )
+        assert fetch.comments == ["checked"]
+        assert (same.translation, same.fuzzy) == ("Gleich.", False)
+        assert (new.translation, new.fuzzy) == ("", False)
+        assert merged.status() == {
+            "language": "de",
+            "total": 3,
+            "translated": 1,
+            "fuzzy": 1,
+            "untranslated": 1,
+        }
+
+        text = render_catalog(merged)
+        assert '#, fuzzy\n#| msgid "Fetch rows."\nmsgctxt "a.f"' in text
+        again, _ = merge_catalog(template, parse_catalog(text), "de")
+        assert again.messages == merged.messages
+        assert again.language == "de"
+
+    @pytest.mark.unit
+    def test_bad_catalogs(self, tmp_path):
+        with pytest.raises(TranslationError, match="x.po:1: entry has no msgctxt"):
+            parse_catalog('msgid "x"\nmsgstr "y"\n', "x.po")
+        with pytest.raises(TranslationError, match="x.po:2: cannot parse"):
+            parse_catalog('msgid "x"\nmsgfoo "y"\n', "x.po")
+        with pytest.raises(TranslationError, match="Cannot read catalog"):
+            load_catalog(tmp_path / "de.po")
+        with pytest.raises(TranslationError, match="not a language code"):
+            catalog_path(tmp_path, "../etc")
+        with pytest.raises(ProjectConfigError, match="site.translations.languages"):
+            ProjectConfig.from_dict({"site": {"translations": {"languages": ["d e"]}}})
+
+
+class TestLocalize:
+    """Tests for :func:`services.doc_translations.localize`."""
+
+    @pytest.mark.unit
+    def test_current_translations_replace_docstrings(self, source_tree):
+        packages = build_model(parse_tree(source_tree))
+        catalog = Catalog(
+            "de",
+            [
+                Message(
+                    "billing.invoice.send",
+                    "Email the invoice.",
+                    "Die Rechnung mailen.",
+                ),
+                Message("billing.invoice", "Old text.", "Rechnungen."),
+                Message("billing", "Billing.", "Abrechnung.", fuzzy=True),
+            ],
+        )
+        localized, stale = localize(packages, catalog)
+        assert stale == 1
+        module = localized[0].modules[1]
+        assert module.functions[0].docstring == "Die Rechnung mailen."
+        assert module.symbol.docstring == "Invoices."
+        assert localized[0].modules[0].symbol.docstring == "Billing."
+        assert packages[0].modules[1].functions[0].docstring == "Email the invoice."
+
+
+class TestTranslateCommand:
+    """Tests for ``autodoc translate`` and ``generate --language``."""
+
+    @pytest.mark.unit
+    def test_update_translate_and_generate(self, source_tree, tmp_path, capsys):
+        (source_tree / "autodoc.yaml").write_text(
+            "site:\n  translations:\n    dir: i18n\n    languages: [de]\n",
+            encoding="utf-8",
+        )
+        root = ["--root", str(source_tree)]
+        assert run_command(["translate", "extract", *root]) == 0
+        assert (source_tree / "i18n" / "autodoc.pot").is_file()
+        assert run_command(["translate", "update", *root]) == 0
+        po = source_tree / "i18n" / "de.po"
+        text = po.read_text(encoding="utf-8")
+        assert "Language: de\\n" in text
+        po.write_text(
+            text.replace(
+                'msgid "Email the invoice."\nmsgstr ""',
+                'msgid "Email the invoice."\nmsgstr "Die Rechnung mailen."',
+            ),
+            encoding="utf-8",
+        )
+        capsys.readouterr()
+        assert run_command(["translate", "status", *root, "--format", "json"]) == 0
+        [report] = json.loads(capsys.readouterr().out)["catalogs"]
+        assert (report["translated"], report["untranslated"]) == (1, 3)
+        assert run_command(["translate", "status", *root, "--check"]) == 1
+
+        output = tmp_path / "site"
+        argv = ["generate", *root, "--language", "de", "--output", str(output)]
+        assert run_command(argv) == 0
+        page = (output / "billing.md").read_text(encoding="utf-8")
+        assert "Die Rechnung mailen." in page
+        assert "A bill sent to a customer." in page
+
+    @pytest.mark.unit
+    def test_missing_language(self, source_tree, tmp_path, capsys):
+        assert run_command(["translate", "update", "--root", str(source_tree)]) == 1
+        assert "pass --language" in capsys.readouterr().err
+        argv = ["generate", "--root", str(source_tree), "--language", "fr"]
+        assert run_command([*argv, "--output", str(tmp_path / "site")]) == 1
+        assert "Cannot read catalog" in capsys.readouterr().err
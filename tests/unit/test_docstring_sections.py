@@ -0,0 +1,152 @@
+"""Unit tests for structured docstring sections."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, SiteConfig, ThemeConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_html import render_html_site
+from services.doc_json import render_json_site
+from services.doc_markdown import render_markdown_site
+from services.doc_theme import build_theme_assets
+from services.docstring_sections import DocField, parse_docstring
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with a Google style docstring."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text(
+        '"""Shop."""\n\n'
+        "def create_user(name: str, age: int = 0) -> str:\n"
+        '    """Create a user.\n\n'
+        "    Args:\n"
+        "        name (str): The user name,\n"
+        "            without spaces.\n"
+        "        age: Age in years.\n\n"
+        "    Returns:\n"
+        "        str: The new user id.\n"
+        '    """\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_google_sections() -> None:
+    parsed = parse_docstring(
+        "Create a user.\n\n"
+        "Args:\n"
+        "    name (str): The user name.\n"
+        "    *tags: Labels,\n"
+        "        in order.\n\n"
+        "Raises:\n"
+        "    ValueError: If the name is empty.\n\n"
+        "Example:\n"
+        '    >>> create_user("x")\n',
+    )
+
+    assert parsed.description == 'Create a user.\n\nExample:\n    >>> create_user("x")'
+    assert parsed.parameters == (
+        DocField("name", "str", "The user name."),
+        DocField("*tags", None, "Labels, in order."),
+    )
+    assert parsed.raises == (DocField("ValueError", None, "If the name is empty."),)
+    assert parse_docstring("Answer.\n\nReturns:\n    The answer: 42.\n").returns == (
+        DocField(None, None, "The answer: 42."),
+    )
+    assert not parse_docstring("Just text.").structured
+
+
+def test_numpy_and_rest_sections() -> None:
+    numpy = parse_docstring(
+        "Total.\n\n"
+        "Parameters\n----------\n"
+        "cart : Cart\n    The cart.\n"
+        "coupon : str, optional\n\n"
+        "Returns\n-------\n"
+        "int\n    Cents.\n",
+    )
+    assert numpy.parameters == (
+        DocField("cart", "Cart", "The cart."),
+        DocField("coupon", "str, optional", ""),
+    )
+    assert numpy.returns == (DocField(None, "int", "Cents."),)
+
+    rest = parse_docstring(
+        "Load.\n\n"
+        ":param str path: Where.\n"
+        ":param mode: How.\n"
+        ":type mode: int\n"
+        ":rtype: bytes\n"
+        ":returns: The data\n    read.\n"
+        ":raises OSError: On failure.\n",
+    )
+    assert rest.description == "Load."
+    assert rest.parameters == (
+        DocField("path", "str", "Where."),
+        DocField("mode", "int", "How."),
+    )
+    assert rest.returns == (DocField(None, "bytes", "The data read."),)
+    assert rest.to_dict()["raises"] == [
+        {"name": "OSError", "type": None, "description": "On failure."},
+    ]
+
+
+def test_pages_render_sections(tree: Path, tmp_path: Path) -> None:
+    packages = build_model(parse_tree(tree))
+    page = {p.path: p.content for p in render_markdown_site(packages, SiteConfig())}
+    assert (
+        "Create a user.\n\n"
+        "**Parameters**\n\n"
+        "- `name` (`str`) - The user name, without spaces.\n"
+        "- `age` - Age in years.\n\n"
+        "**Returns**\n\n"
+        "- `str` - The new user id.\n"
+    ) in page["shop.md"]
+    theme = build_theme_assets(ThemeConfig(), tmp_path)
+    html = {
+        p.path: p.content for p in render_html_site(packages, SiteConfig(), theme)
+    }
+    assert (
+        "<dt><code>name</code> (<code>str</code>)</dt>"
+        "<dd>The user name, without spaces.</dd>"
+    ) in html["shop.html"]
+    data = json.loads(render_json_site(packages, SiteConfig())[0].content)
+    shop = next(p for p in data["packages"] if p["name"] == "shop")
+    assert shop["docstrings"][0]["symbol"] == "shop.create_user"
+    assert shop["docstrings"][0]["returns"][0]["type"] == "str"
+
+    config = ProjectConfig(site=SiteConfig.from_dict({"docstring_sections": False}))
+    packages = build_model(parse_tree(tree), config)
+    page = {p.path: p.content for p in render_markdown_site(packages, SiteConfig())}
+    assert "Args:\n    name (str): The user name," in page["shop.md"]
+
+
+def test_javadoc_and_epytext_tags() -> None:
+    javadoc = parse_docstring(
+        "Create a user.\n\n"
+        "@param name the user name,\n"
+        "    without spaces\n"
+        "@param {int} age: age in years\n"
+        "@return {User} the new user\n"
+        "@throws ValueError if the name is empty\n",
+    )
+    assert javadoc.description == "Create a user."
+    assert javadoc.parameters == (
+        DocField("name", None, "the user name, without spaces"),
+        DocField("age", "int", "age in years"),
+    )
+    assert javadoc.returns == (DocField(None, "User", "the new user"),)
+    assert javadoc.raises == (DocField("ValueError", None, "if the name is empty"),)
+
+    epytext = parse_docstring(
+        "Load.\n\n@param path: Where.\n@type path: str\n@rtype: bytes\n"
+        "@return: The data.\n@raise OSError: On failure.\n",
+    )
+    assert epytext.parameters == (DocField("path", "str", "Where."),)
+    assert epytext.returns == (DocField(None, "bytes", "The data."),)
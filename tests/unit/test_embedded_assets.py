@@ -0,0 +1,127 @@
+"""Unit tests for the embedded asset inventory of package pages."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import (
+    ProjectConfig,
+    ProjectConfigError,
+    SiteConfig,
+    ThemeConfig,
+)
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_html import render_html_site
+from services.doc_json import render_json_site
+from services.doc_markdown import render_markdown_site
+from services.doc_theme import build_theme_assets
+from services.embedded_assets import find_embedded_assets
+from services.import_graph import build_import_graph
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package reading templates, a schema, and static files."""
+    _write(tmp_path / "shop" / "__init__.py", '"""Shop."""\n')
+    _write(
+        tmp_path / "shop" / "web.py",
+        '"""Web front end."""\n'
+        "import os\n"
+        "import pkgutil\n"
+        "from importlib import resources\n"
+        "from importlib.resources import files\n"
+        "from pathlib import Path\n\n"
+        "HERE = Path(__file__).resolve().parent\n"
+        'TEMPLATE = files(__package__).joinpath("templates/page.html").read_text()\n'
+        'SCHEMA = pkgutil.get_data(__name__, "schema.json")\n'
+        'STATIC = HERE / "static"\n'
+        'ICONS = sorted((STATIC / "icons").glob("*.svg"))\n'
+        'CONFIG = resources.read_text("shop.conf", "defaults.ini")\n'
+        'LEGACY = os.path.join(os.path.dirname(__file__), "legacy.txt")\n'
+        "NAME = compute(HERE)\n\n"
+        "def page():\n"
+        '    """Render the page."""\n'
+        '    return (HERE / "inline.html").read_text()\n',
+    )
+    _write(tmp_path / "shop" / "conf" / "__init__.py", "")
+    _write(tmp_path / "shop" / "conf" / "defaults.ini", "[shop]\n")
+    _write(tmp_path / "shop" / "templates" / "page.html", "<p></p>\n")
+    _write(tmp_path / "shop" / "schema.json", "{}\n")
+    _write(tmp_path / "shop" / "static" / "app.css", "")
+    _write(tmp_path / "shop" / "static" / "icons" / "cart.svg", "")
+    _write(tmp_path / "shop" / "static" / "__pycache__" / "x.pyc", "")
+    return tmp_path
+
+
+class TestFindEmbeddedAssets:
+    """Tests for finding the data files a module loads."""
+
+    @pytest.mark.unit
+    def test_assets(self, tree):
+        assets = find_embedded_assets(tree, build_import_graph(tree))
+
+        assert [(a.variable, a.pattern, a.files) for a in assets] == [
+            (
+                "TEMPLATE",
+                "shop/templates/page.html",
+                ("shop/templates/page.html",),
+            ),
+            ("SCHEMA", "shop/schema.json", ("shop/schema.json",)),
+            (
+                "STATIC",
+                "shop/static/",
+                ("shop/static/app.css", "shop/static/icons/cart.svg"),
+            ),
+            (
+                "ICONS",
+                "shop/static/icons/*.svg",
+                ("shop/static/icons/cart.svg",),
+            ),
+            ("CONFIG", "shop/conf/defaults.ini", ("shop/conf/defaults.ini",)),
+            ("LEGACY", "shop/legacy.txt", ()),
+        ]
+        assert {a.qualified_name for a in assets} >= {"shop.web.STATIC"}
+        assert assets[0].to_dict()["files"] == ["shop/templates/page.html"]
+        assert assets[0].lineno == 9
+
+
+class TestAssetRendering:
+    """Tests for listing assets on package pages."""
+
+    @pytest.mark.unit
+    def test_package_pages_list_assets(self, tree, tmp_path):
+        packages = build_model(parse_tree(tree))
+        pages = render_markdown_site(packages, SiteConfig())
+        page = {p.path: p.content for p in pages}["shop.md"]
+        assert page.endswith(
+            "## Embedded assets\n\n"
+            "- `shop.web.TEMPLATE` loads `shop/templates/page.html`\n"
+            "- `shop.web.SCHEMA` loads `shop/schema.json`\n"
+            "- `shop.web.STATIC` loads `shop/static/`\n"
+            "  - `shop/static/app.css`\n"
+            "  - `shop/static/icons/cart.svg`\n"
+            "- `shop.web.ICONS` loads `shop/static/icons/*.svg`\n"
+            "  - `shop/static/icons/cart.svg`\n"
+            "- `shop.web.CONFIG` loads `shop/conf/defaults.ini`\n"
+            "- `shop.web.LEGACY` loads `shop/legacy.txt` (no matching file)\n",
+        )
+        theme = build_theme_assets(ThemeConfig(), tmp_path)
+        html = {
+            p.path: p.content for p in render_html_site(packages, SiteConfig(), theme)
+        }
+        assert "<h2>Embedded assets</h2>" in html["shop.html"]
+        data = json.loads(render_json_site(packages, SiteConfig())[0].content)
+        shop = next(p for p in data["packages"] if p["name"] == "shop")
+        assert [a["variable"] for a in shop["assets"]][:2] == ["TEMPLATE", "SCHEMA"]
+
+        config = ProjectConfig(site=SiteConfig.from_dict({"assets": False}))
+        assert build_model(parse_tree(tree), config)[0].assets == []
+        with pytest.raises(ProjectConfigError):
+            SiteConfig.from_dict({"assets": "yes"})
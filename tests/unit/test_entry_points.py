@@ -0,0 +1,123 @@
+"""Unit tests for entry-point detection and the architecture page."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import SiteConfig
+from services.doc_markdown import render_markdown_site
+from services.doc_site import build_site_model
+from services.doc_symbols import load_doc_symbols
+from services.entry_points import ArchitectureError, detect_architecture
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def project(tmp_path: Path) -> Path:
+    """A small FastAPI service with a CLI and a ``__main__`` module."""
+    _write(
+        tmp_path / "pyproject.toml",
+        '[project]\nname = "svc"\n\n[project.scripts]\nsvc = "svc.cli:main"\n',
+    )
+    _write(
+        tmp_path / "svc" / "app.py",
+        '"""Application setup."""\n'
+        "from fastapi import FastAPI\n"
+        "from svc import routes\n"
+        "from svc.errors import install_handlers\n\n"
+        "def create_app():\n"
+        '    """Build the app."""\n'
+        "    app = FastAPI()\n"
+        "    install_handlers(app)\n"
+        '    app.include_router(routes.router, prefix="/v1")\n\n'
+        '    @app.on_event("startup")\n'
+        "    def warm_up():\n"
+        "        pass\n\n"
+        "    return app\n\n"
+        "app = create_app()\n",
+    )
+    _write(
+        tmp_path / "svc" / "cli.py",
+        '"""Command line."""\n'
+        "import logging\n\n"
+        "logging.basicConfig()\n\n"
+        "def main():\n"
+        '    """Run."""\n\n'
+        'if __name__ == "__main__":\n'
+        "    main()\n"
+        '    print("done")\n',
+    )
+    _write(tmp_path / "svc" / "__main__.py", "from svc.cli import main\n\nmain()\n")
+    return tmp_path
+
+
+class TestDetectArchitecture:
+    """Tests for finding entry points and app wiring."""
+
+    @pytest.mark.unit
+    def test_entry_points(self, project):
+        overview = detect_architecture(project)
+        found = {(e.kind, e.name): e for e in overview.entry_points}
+
+        assert found[("console-script", "svc")].target == "svc.cli.main"
+        assert found[("main-module", "svc.__main__")].calls == ("svc.cli.main",)
+        # Builtins such as print() are not listed.
+        assert found[("main-guard", "svc.cli")].calls == ("svc.cli.main",)
+        assert found[("import-time", "svc.cli")].calls == ("logging.basicConfig",)
+        app = found[("app", "svc.app:app")]
+        assert (app.target, app.location) == ("svc.app.create_app", "svc/app.py:18")
+
+    @pytest.mark.unit
+    def test_app_wiring_in_source_order(self, project):
+        (app,) = detect_architecture(project).apps
+
+        assert (app.name, app.framework, app.factory) == (
+            "svc.app.create_app",
+            "FastAPI",
+            "svc.app.create_app",
+        )
+        assert [(s.kind, s.target, s.detail) for s in app.steps] == [
+            ("setup", "svc.errors.install_handlers", None),
+            ("router", "svc.routes.router", "prefix /v1"),
+            ("event handler", "svc.app.create_app.warm_up", "startup"),
+        ]
+
+    @pytest.mark.unit
+    def test_invalid_pyproject(self, tmp_path):
+        _write(tmp_path / "pyproject.toml", "[project\n")
+        with pytest.raises(ArchitectureError):
+            detect_architecture(tmp_path)
+
+
+class TestArchitecturePage:
+    """Tests for rendering the overview into the Markdown site."""
+
+    @pytest.mark.unit
+    def test_page_links_documented_targets(self, project):
+        packages = build_site_model(load_doc_symbols(project))
+        pages = render_markdown_site(
+            packages,
+            SiteConfig(),
+            architecture=detect_architecture(project),
+        )
+        content = {page.path: page.content for page in pages}
+
+        assert "[Architecture](architecture.md)" in content["index.md"]
+        page = content["architecture.md"]
+        assert (
+            "| Console script | `svc` | [`svc.cli.main`](svc.md#main) "
+            "| `pyproject.toml` |" in page
+        )
+        assert "1. Router: `svc.routes.router` (prefix /v1), line 10" in page
+
+    @pytest.mark.unit
+    def test_no_page_without_entry_points(self, tmp_path):
+        _write(tmp_path / "lib" / "util.py", '"""Utilities."""\n')
+        overview = detect_architecture(tmp_path)
+        pages = render_markdown_site([], SiteConfig(), architecture=overview)
+        assert not overview
+        assert [page.path for page in pages] == ["index.md"]
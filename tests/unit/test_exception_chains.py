@@ -0,0 +1,118 @@
+"""Unit tests for documenting the exceptions functions raise."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, SiteConfig, ThemeConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_html import render_html_site
+from services.doc_json import render_json_site
+from services.doc_markdown import render_markdown_site
+from services.doc_theme import build_theme_assets
+from services.exception_chains import find_exception_chains, is_caught
+from services.import_graph import build_import_graph
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package whose cart wraps and groups its errors."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text(
+        '"""Shop."""\n\n'
+        "class CartError(Exception):\n"
+        '    """A cart failure."""\n\n'
+        "class PriceError(CartError):\n"
+        '    """A bad price."""\n\n'
+        "class Cart:\n"
+        '    """A cart."""\n\n'
+        "    def get(self, key: str) -> str:\n"
+        '        """An item."""\n'
+        "        try:\n"
+        "            return self.items[key]\n"
+        "        except KeyError as err:\n"
+        "            raise CartError(key) from err\n\n"
+        "    def price(self, key: str) -> int:\n"
+        '        """A price."""\n'
+        "        try:\n"
+        "            return int(self.get(key))\n"
+        "        except (TypeError, ValueError):\n"
+        "            raise PriceError(key) from None\n\n"
+        "    def checkout(self) -> None:\n"
+        '        """Check out."""\n'
+        "        try:\n"
+        '            self.price("first")\n'
+        "        except PriceError:\n"
+        "            raise\n"
+        "        raise ExceptionGroup(\"invalid\", [PriceError(), CartError('x')])\n\n"
+        "    def safe(self) -> None:\n"
+        '        """Never raises."""\n'
+        "        try:\n"
+        '            self.get("first")\n'
+        "        except Exception:\n"
+        "            pass\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_is_caught() -> None:
+    assert is_caught("KeyError", frozenset({"KeyError"}))
+    assert is_caught("shop.CartError", frozenset({"Exception"}))
+    assert not is_caught("KeyError", frozenset({"ValueError"}))
+
+
+def test_exception_chains(tree: Path) -> None:
+    errors = find_exception_chains(tree, build_import_graph(tree))
+
+    assert [
+        (e.symbol, e.exception, e.causes, e.suppressed, e.members, e.via, e.lineno)
+        for e in errors
+    ] == [
+        ("shop.Cart.get", "shop.CartError", ("KeyError",), False, (), None, 17),
+        ("shop.Cart.price", "shop.CartError", (), False, (), "shop.Cart.get", 22),
+        ("shop.Cart.price", "shop.PriceError", (), True, (), None, 24),
+        ("shop.Cart.checkout", "shop.CartError", (), False, (), "shop.Cart.price", 29),
+        ("shop.Cart.checkout", "shop.PriceError", (), False, (), None, 31),
+        (
+            "shop.Cart.checkout",
+            "ExceptionGroup",
+            (),
+            False,
+            ("shop.PriceError", "shop.CartError"),
+            None,
+            32,
+        ),
+    ]
+    assert errors[0].to_dict()["causes"] == ["KeyError"]
+
+
+def test_pages_list_raises(tree: Path, tmp_path: Path) -> None:
+    packages = build_model(parse_tree(tree))
+    page = {p.path: p.content for p in render_markdown_site(packages, SiteConfig())}
+    assert "Raises: `shop.CartError` from `KeyError`\n" in page["shop.md"]
+    assert (
+        "Raises: `shop.CartError` via `shop.Cart.get`, "
+        "`shop.PriceError` (cause hidden)\n"
+    ) in page["shop.md"]
+    theme = build_theme_assets(ThemeConfig(), tmp_path)
+    html = {
+        p.path: p.content for p in render_html_site(packages, SiteConfig(), theme)
+    }
+    assert (
+        '<p class="autodoc-raises">Raises: <code>shop.CartError</code> from '
+        "<code>KeyError</code></p>"
+    ) in html["shop.html"]
+    data = json.loads(render_json_site(packages, SiteConfig())[0].content)
+    shop = next(p for p in data["packages"] if p["name"] == "shop")
+    assert [e["symbol"] for e in shop["raises"]][:2] == [
+        "shop.Cart.get",
+        "shop.Cart.price",
+    ]
+
+    config = ProjectConfig(site=SiteConfig.from_dict({"raises": False}))
+    assert build_model(parse_tree(tree), config)[0].raises == {}
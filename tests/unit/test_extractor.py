@@ -591,6 +591,27 @@ def func2():
         assert result.classes[0].lineno > 0
         assert result.functions[1].lineno > result.classes[0].lineno
 
+    def test_extract_raised_exceptions(self, extractor):
+        """Test that raised exception types are collected, ignoring nested defs"""
+        code = """
+def func(value):
+    if value is None:
+        raise ValueError("missing")
+    if value < 0:
+        raise errors.RangeError
+    try:
+        pass
+    except KeyError:
+        raise
+    def helper():
+        raise RuntimeError()
+    raise ValueError("again")
+"""
+        tree = ast.parse(code)
+        result = extractor.extract(tree, "test.py")
+
+        assert result.functions[0].raises == ["ValueError", "errors.RangeError"]
+
 
 class TestConvenienceFunction:
     """Tests for extract_symbols convenience function"""
@@ -0,0 +1,162 @@
+"""Unit tests for FAQ and troubleshooting entries."""
+
+from __future__ import annotations
+
+import json
+import logging
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_markdown import render_package_markdown
+import services.faq
+from services.faq import comment_faq, load_faq_file, parse_faq, strip_faq
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with entries in a docstring, a comment, and a sidecar."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(
+        '"""Carts."""\n\n'
+        "# @autodoc:troubleshooting `ValueError: empty cart` on checkout\n"
+        "# Add an item to the cart\n"
+        "# before checking it out.\n\n\n"
+        "def total(items, discount=0):\n"
+        '    """Sum of the items less ``discount``.\n\n'
+        "    @autodoc:faq Why can the total be negative?\n"
+        "    A discount larger than the sum\n"
+        "    is not clamped.\n"
+        '    """\n'
+        "    return 0\n",
+        encoding="utf-8",
+    )
+    (tmp_path / "shop" / "FAQ.md").write_text(
+        "# Shop FAQ\n\n"
+        "Not an entry.\n\n"
+        "## Is a cart thread-safe?\n\n"
+        "No.\n\n"
+        "```python\n## Not a heading\n```\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_parse_and_strip() -> None:
+    docstring = (
+        "Sum of the items.\n\n"
+        "@autodoc:faq Why?\nBecause\nof this.\n\n"
+        "@autodoc:troubleshooting Slow\nUse a cache.\n\n"
+        "More text."
+    )
+    assert parse_faq(docstring) == [
+        ("faq", "Why?", "Because of this."),
+        ("troubleshooting", "Slow", "Use a cache."),
+    ]
+    assert strip_faq(docstring) == "Sum of the items.\n\nMore text."
+    assert strip_faq("@autodoc:faq Why?\nBecause.") is None
+    assert strip_faq("No entries.") == "No entries."
+
+
+def test_code_blocks_are_not_entries() -> None:
+    docstring = (
+        "Sum.\n\n"
+        "Example::\n\n    @autodoc:faq In a literal block\n    No.\n\n"
+        "Text.\n\n    @autodoc:faq Indented\n    No.\n\n"
+        "```\n@autodoc:faq Fenced\nNo.\n```\n\n"
+        "@autodoc:faq Why?\nBecause."
+    )
+    assert parse_faq(docstring) == [("faq", "Why?", "Because.")]
+    assert strip_faq(docstring) == docstring[: docstring.index("\n\n@autodoc:faq Why")]
+    assert parse_faq(services.faq.__doc__) == []
+    assert strip_faq(services.faq.__doc__) == services.faq.__doc__.strip()
+
+
+def test_comment_faq() -> None:
+    source = (
+        "x = 1  # @autodoc:faq Not on a line of its own\n"
+        "# @autodoc:faq Why?\n"
+        "# Because.\n"
+        "#\n"
+        "# Not part of the answer.\n"
+        's = "# @autodoc:faq In a string"\n'
+    )
+    assert comment_faq(source) == [("faq", "Why?", "Because.", 2)]
+
+
+def test_load_faq_file(tree: Path) -> None:
+    assert load_faq_file(tree / "shop" / "FAQ.md") == [
+        ("Is a cart thread-safe?", "No.\n\n```python\n## Not a heading\n```", 5),
+    ]
+    assert load_faq_file(tree / "shop" / "TROUBLESHOOTING.md") == []
+
+
+def test_package_page(tree: Path) -> None:
+    [shop] = build_model(parse_tree(tree))
+    assert [(e.kind, e.question, e.symbol, e.lineno) for e in shop.faq] == [
+        ("faq", "Is a cart thread-safe?", None, 5),
+        ("troubleshooting", "`ValueError: empty cart` on checkout", "shop.cart", 3),
+        ("faq", "Why can the total be negative?", "shop.cart.total", 8),
+    ]
+    assert [e.file_path for e in shop.faq] == [
+        "shop/FAQ.md",
+        "shop/cart.py",
+        "shop/cart.py",
+    ]
+    page = render_package_markdown(shop)
+    assert "@autodoc" not in page
+    assert page.endswith(
+        "## Frequently asked questions\n\n"
+        "### Is a cart thread-safe?\n\n"
+        "No.\n\n```python\n## Not a heading\n```\n\n"
+        "### Why can the total be negative?\n\n"
+        "A discount larger than the sum is not clamped.\n\n"
+        "See [`shop.cart.total`](#total).\n\n"
+        "## Troubleshooting\n\n"
+        "### `ValueError: empty cart` on checkout\n\n"
+        "Add an item to the cart before checking it out.\n\n"
+        "See [`shop.cart`](#shopcart).\n",
+    )
+
+
+def test_incomplete_entries_and_config(tree: Path, caplog) -> None:
+    (tree / "shop" / "gift.py").write_text(
+        '"""Gifts.\n\n@autodoc:faq Is wrapping free?\n"""\n',
+        encoding="utf-8",
+    )
+    with caplog.at_level(logging.WARNING):
+        [shop] = build_model(parse_tree(tree))
+    assert "Ignoring @autodoc:faq without an answer" in caplog.text
+    assert len(shop.faq) == 3
+    assert "@autodoc" not in (shop.modules[1].symbol.docstring or "")
+
+    config = ProjectConfig(site=SiteConfig.from_dict({"faq": False}))
+    [shop] = build_model(parse_tree(tree), config)
+    assert shop.faq == []
+    assert "is not clamped" not in render_package_markdown(shop)
+    with pytest.raises(ProjectConfigError, match="site.faq must be true or false"):
+        SiteConfig.from_dict({"faq": "yes"})
+
+
+def test_html_and_json(tree: Path) -> None:
+    argv = ["generate", "--root", str(tree), "--output"]
+    assert run_command([*argv, str(tree / "html"), "--format", "html"]) == 0
+    page = (tree / "html" / "shop.html").read_text(encoding="utf-8")
+    assert '<section class="autodoc-troubleshooting">' in page
+    assert "<h3><code>ValueError: empty cart</code> on checkout</h3>" in page
+    link = '<a href="#shop.cart.total"><code>shop.cart.total</code></a>'
+    assert f"<p>See {link}.</p>" in page
+
+    assert run_command([*argv, str(tree / "json"), "--format", "json"]) == 0
+    data = json.loads((tree / "json" / "index.json").read_text(encoding="utf-8"))
+    [shop] = data["packages"]
+    assert [entry["question"] for entry in shop["faq"]] == [
+        "Is a cart thread-safe?",
+        "`ValueError: empty cart` on checkout",
+        "Why can the total be negative?",
+    ]
@@ -0,0 +1,57 @@
+"""Unit tests for the flake8 documentation lint plugin."""
+
+import ast
+
+import pytest
+
+from services.custom_lint_rules import rule_from_dict
+from services.doc_lint import MissingDocstringRule
+from services.flake8_doc_lint import DocLintPlugin
+
+SOURCE = '''"""Module."""
+
+
+def documented():
+    """Documented."""
+
+
+def undocumented():
+    pass
+'''
+
+
+def _run(source: str, rules=None) -> list[tuple[int, int, str]]:
+    plugin = DocLintPlugin(ast.parse(source), "pkg/mod.py", rules=rules)
+    return [(line, col, message) for line, col, message, _ in plugin.run()]
+
+
+class TestDocLintPlugin:
+    """Tests for reporting lint findings through flake8."""
+
+    @pytest.mark.unit
+    def test_reports_builtin_rule_with_code(self):
+        results = _run(SOURCE, rules=[MissingDocstringRule()])
+        assert results == [
+            (8, 0, "ADC100 exported function pkg.mod.undocumented has no docstring"),
+        ]
+
+    @pytest.mark.unit
+    def test_reports_custom_rule_with_id(self):
+        rule = rule_from_dict(
+            {
+                "id": "short-docs",
+                "when": 'kind == "function"',
+                "require": "len(docstring) > 15",
+                "message": "{name} docstring is too short",
+            },
+        )
+        results = _run(SOURCE, rules=[rule])
+        assert [message for _, _, message in results] == [
+            "ADC900 [short-docs] documented docstring is too short",
+            "ADC900 [short-docs] undocumented docstring is too short",
+        ]
+
+    @pytest.mark.unit
+    def test_plugin_yields_its_own_type(self):
+        plugin = DocLintPlugin(ast.parse("x = 1\n"), "mod.py", rules=[MissingDocstringRule()])
+        assert all(kind is DocLintPlugin for *_, kind in plugin.run())
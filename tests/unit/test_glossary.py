@@ -0,0 +1,178 @@
+"""Unit tests for the domain term glossary."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import (
+    GlossaryConfig,
+    ProjectConfig,
+    ProjectConfigError,
+    load_project_config,
+)
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.glossary import (
+    GlossaryEntry,
+    GlossaryError,
+    build_glossary,
+    load_glossary_file,
+    name_words,
+)
+
+SOURCES = {
+    "billing/__init__.py": '"""Billing invoices and payment providers."""\n',
+    "billing/invoice.py": (
+        '"""Invoices."""\n\n\n'
+        "class Invoice:\n"
+        '    """A bill sent to a customer."""\n\n\n'
+        "class PaymentProvider:\n"
+        '    """Charges an invoice through a payment gateway."""\n\n\n'
+        "def send_invoices(invoices):\n"
+        '    """Email each invoice."""\n'
+    ),
+    "shop/__init__.py": '"""Shop."""\n',
+    "shop/cart.py": (
+        '"""Carts whose payment is due."""\n\n\n'
+        "def checkout(cart):\n"
+        '    """Create the invoice and take the payment."""\n'
+    ),
+}
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    for name, content in SOURCES.items():
+        path = tmp_path / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content, encoding="utf-8")
+    return tmp_path
+
+
+def _symbols(root: Path):
+    return [s for p in build_model(parse_tree(root)) for s in p.symbols()]
+
+
+class TestBuildGlossary:
+    """Tests for :func:`services.glossary.build_glossary`."""
+
+    @pytest.mark.unit
+    def test_name_words_split_and_singularize(self):
+        assert name_words("PaymentProviders") == ["payment", "provider"]
+        assert name_words("send_HTTPRequests") == ["send", "http", "request"]
+        assert name_words("Address") == ["address"]
+
+    @pytest.mark.unit
+    def test_extracts_terms_from_names_and_docstrings(self, source_tree):
+        terms = {t.term: t for t in build_glossary(_symbols(source_tree))}
+        assert sorted(terms) == ["invoice", "payment"]
+        invoice = terms["invoice"]
+        assert invoice.mentions == 6
+        assert invoice.defined_by == ("billing.invoice.Invoice", "billing.invoice")
+        assert invoice.definition == "A bill sent to a customer."
+        assert terms["payment"].defined_by == ()
+
+    @pytest.mark.unit
+    def test_thresholds_and_exclusions(self, source_tree):
+        config = GlossaryConfig(min_mentions=1, exclude=["Billing"], limit=2)
+        terms = [t.term for t in build_glossary(_symbols(source_tree), config)]
+        assert terms == ["invoice", "payment"]
+
+    @pytest.mark.unit
+    def test_user_terms_take_precedence(self, source_tree):
+        user = {
+            "invoice": GlossaryEntry("What the customer owes."),
+            "settlement": GlossaryEntry("Paying out.", ("shop.cart.checkout",)),
+        }
+        terms = {t.term: t for t in build_glossary(_symbols(source_tree), None, user)}
+        assert terms["invoice"].definition == "What the customer owes."
+        assert terms["invoice"].source == "file"
+        assert terms["settlement"].to_dict() == {
+            "term": "settlement",
+            "definition": "Paying out.",
+            "mentions": 0,
+            "defined_by": ["shop.cart.checkout"],
+            "source": "file",
+        }
+
+
+class TestGlossaryFile:
+    """Tests for :func:`services.glossary.load_glossary_file`."""
+
+    @pytest.mark.unit
+    def test_definitions_and_see_links(self, tmp_path):
+        path = tmp_path / "glossary.yaml"
+        path.write_text(
+            "Payment Providers: Gateways.\n"
+            "settlement:\n  definition: Paying out.\n  see: billing.settle\n",
+            encoding="utf-8",
+        )
+        assert load_glossary_file(path) == {
+            "payment provider": GlossaryEntry("Gateways."),
+            "settlement": GlossaryEntry("Paying out.", ("billing.settle",)),
+        }
+
+    @pytest.mark.unit
+    def test_bad_entries(self, tmp_path):
+        path = tmp_path / "glossary.yaml"
+        path.write_text("invoice:\n  see: [x]\n", encoding="utf-8")
+        with pytest.raises(GlossaryError, match="needs a definition"):
+            load_glossary_file(path)
+        with pytest.raises(GlossaryError, match="Cannot read"):
+            load_glossary_file(tmp_path / "missing.yaml")
+
+    @pytest.mark.unit
+    def test_config_section(self):
+        assert ProjectConfig.from_dict({"site": {"glossary": False}}).site.glossary == (
+            GlossaryConfig(enabled=False)
+        )
+        with pytest.raises(ProjectConfigError, match="site.glossary.min_mentions"):
+            ProjectConfig.from_dict({"site": {"glossary": {"min_mentions": 0}}})
+
+
+class TestGlossaryPages:
+    """Tests for the glossary page of generated sites."""
+
+    @pytest.mark.unit
+    def test_pages_in_every_format(self, source_tree):
+        (source_tree / "terms.yaml").write_text(
+            "settlement: Paying out the merchant.\n",
+            encoding="utf-8",
+        )
+        (source_tree / "autodoc.yaml").write_text(
+            "site:\n  glossary:\n    file: terms.yaml\n",
+            encoding="utf-8",
+        )
+        config = load_project_config(source_tree)
+        tree = parse_tree(source_tree)
+        sites = render_site(
+            tree,
+            build_model(tree, config),
+            ("markdown", "html", "json"),
+            config,
+        )
+        markdown = {p.path: p.content for p in sites["markdown"]}
+        assert "[Glossary](glossary.md)" in markdown["index.md"]
+        assert (
+            "- **invoice** - A bill sent to a customer. See "
+            "[`billing.invoice.Invoice`](billing.md#invoice), "
+            "[`billing.invoice`](billing.md#billinginvoice)."
+        ) in markdown["glossary.md"]
+        assert "- **settlement** - Paying out the merchant." in markdown["glossary.md"]
+
+        html = {p.path: p.content for p in sites["html"]}
+        assert '<dl class="autodoc-glossary">' in html["glossary.html"]
+        assert '<dt id="invoice">invoice</dt>' in html["glossary.html"]
+
+        [index] = sites["json"]
+        terms = json.loads(index.content)["glossary"]
+        assert [t["term"] for t in terms] == ["invoice", "payment", "settlement"]
+
+    @pytest.mark.unit
+    def test_disabled_glossary_has_no_page(self, source_tree):
+        config = ProjectConfig.from_dict({"site": {"glossary": False}})
+        tree = parse_tree(source_tree)
+        [pages] = render_site(tree, build_model(tree, config), config=config).values()
+        assert "glossary.md" not in {page.path for page in pages}
@@ -0,0 +1,81 @@
+"""Unit tests for guide pages built next to the API reference."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.guides import load_guides
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package and two guides, one of them nested."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(
+        '"""Carts."""\n\n\nclass Cart:\n    """A cart."""\n',
+        encoding="utf-8",
+    )
+    guides = tmp_path / "docs" / "guides"
+    (guides / "deploy").mkdir(parents=True)
+    (guides / "start.md").write_text(
+        "# Getting started\n\n"
+        'Fill a {{symbol "shop.cart.Cart"}}, then [deploy](deploy/aws.md#setup).\n\n'
+        "## Next\n\n"
+        'See {{symbol "shop.missing"}}.\n\n'
+        "```\n"
+        '{{symbol "shop.cart.Cart"}}\n'
+        "```\n",
+        encoding="utf-8",
+    )
+    (guides / "deploy" / "aws.md").write_text("Untitled.\n", encoding="utf-8")
+    return tmp_path
+
+
+def test_guide_pages(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed), formats, ProjectConfig())
+
+    markdown = {page.path: page.content for page in sites["markdown"]}
+    assert markdown["index.md"].endswith(
+        "## Guides\n\n- [Aws](guide-deploy-aws.md)\n"
+        "- [Getting started](guide-start.md)\n",
+    )
+    start = markdown["guide-start.md"]
+    assert start.startswith("# Getting started\n\nFill a [`shop.cart.Cart`](")
+    assert "(shop.md#cart)" in start
+    assert "[deploy](guide-deploy-aws.md#setup)" in start
+    assert "## Next\n\nSee `shop.missing`." in start
+    assert '```\n{{symbol "shop.cart.Cart"}}\n```' in start
+    assert markdown["guide-deploy-aws.md"] == "# Aws\n\nUntitled.\n"
+
+    html = {page.path: page.content for page in sites["html"]}
+    assert '<a href="guide-start.html">Getting started</a>' in html["index.html"]
+    page = html["guide-start.html"]
+    assert "<h1>Getting started</h1>" in page
+    assert '<a href="shop.html#shop.cart.Cart"><code>shop.cart.Cart</code></a>' in page
+    assert '<a href="guide-deploy-aws.html#setup">deploy</a>' in page
+    index = {page.path: page.content for page in sites["json"]}["index.json"]
+    guide = json.loads(index)["guides"][1]
+    assert (guide["path"], guide["symbols"]) == (
+        "start.md",
+        ["shop.cart.Cart", "shop.missing"],
+    )
+
+
+def test_guides_config(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig(site=SiteConfig.from_dict({"guides": False}))
+    pages = render_site(parsed, build_model(parsed), ("markdown",), config)
+    assert "Guides" not in pages["markdown"][0].content
+    assert load_guides(tree / "missing", ()) == []
+    with pytest.raises(ProjectConfigError, match="site.guides"):
+        SiteConfig.from_dict({"guides": True})
@@ -0,0 +1,140 @@
+"""Unit tests for sanitizing the HTML of doc content."""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfigError, SanitizeConfig, SiteConfig
+from services.doc_html import render_docstring, render_markdown
+from services.html_sanitizer import HtmlSanitizer, build_sanitizer
+
+
+def test_clean() -> None:
+    sanitizer = HtmlSanitizer()
+    cases = {
+        "Add <item>.": "Add &lt;item&gt;.",
+        "<script>alert(1)</script>": "&lt;script&gt;alert(1)&lt;/script&gt;",
+        'a <b onclick="steal()" class="x">b</b>': 'a <b class="x">b</b>',
+        "<img src=x onerror=alert(1)>": '<img src="x">',
+        '<a href="java&#9;script:alert(1)">x</a>': "<a>x</a>",
+        '<a href="https://example.com/?a=1&amp;b=2">x</a>': (
+            '<a href="https://example.com/?a=1&amp;b=2">x</a>'
+        ),
+        "<div><p>open": "<div><p>open</p></div>",
+        "x </div> y": "x &lt;/div&gt; y",
+        "<!-- hidden -->text": "text",
+        "a < b && c": "a &lt; b &amp;&amp; c",
+        "<details open><summary>S</summary></details>": (
+            "<details open><summary>S</summary></details>"
+        ),
+        "<br/><hr>": "<br><hr>",
+    }
+    for html, expected in cases.items():
+        assert sanitizer.clean(html) == expected, html
+
+
+def test_safe_url_and_extensions() -> None:
+    sanitizer = HtmlSanitizer()
+    assert sanitizer.safe_url("docs/page.html#top")
+    assert sanitizer.safe_url("//cdn.example.com/a.png")
+    assert sanitizer.safe_url("MAILTO:dev@example.com")
+    assert not sanitizer.safe_url(" JavaScript:alert(1)")
+    assert not sanitizer.safe_url("data:text/html;base64,PHNjcmlwdD4=")
+
+    config = SanitizeConfig.from_dict(
+        {"tags": ["Video"], "attributes": ["video.src"], "schemes": ["vscode"]},
+    )
+    extended = build_sanitizer(config)
+    assert extended is not None
+    assert extended.clean('<video src="a.mp4" autoplay>') == (
+        '<video src="a.mp4"></video>'
+    )
+    assert extended.safe_url("vscode://file/a.py")
+    assert build_sanitizer(SanitizeConfig.from_dict(False)) is None
+    for data, message in [
+        ("yes", "site.sanitize must be a mapping or true/false"),
+        ({"tags": ["<b>"]}, "site.sanitize.tags must be a list of tag names"),
+        ({"schemes": [1]}, "site.sanitize.schemes must be a list of scheme names"),
+    ]:
+        with pytest.raises(ProjectConfigError, match=message):
+            SiteConfig.from_dict({"sanitize": data})
+
+
+def test_markdown_and_docstrings() -> None:
+    sanitizer = HtmlSanitizer()
+    markdown = (
+        "<details>\n<summary>More</summary>\n\n"
+        "Press <kbd>Ctrl</kbd> **now**.\n\n"
+        "</details>\n\n"
+        "[Run](javascript:run) and ![Logo](data:image/png;base64,AA)"
+    )
+    assert render_markdown(markdown, sanitizer=sanitizer) == (
+        "<details>\n<summary>More</summary>\n"
+        "<p>Press <kbd>Ctrl</kbd> <strong>now</strong>.</p>\n"
+        "</details>\n"
+        "<p>Run and Logo</p>"
+    )
+    assert render_markdown("Press <kbd>Ctrl</kbd>.") == (
+        "<p>Press &lt;kbd&gt;Ctrl&lt;/kbd&gt;.</p>"
+    )
+
+    docstring = "Use <kbd>Ctrl</kbd>.\n\n<table><tr><td>1</td></tr></table>"
+    assert render_docstring(docstring, sanitizer=sanitizer) == (
+        "<p>Use <kbd>Ctrl</kbd>.</p>\n<table><tr><td>1</td></tr></table>"
+    )
+    assert render_docstring("<item> is kept.", sanitizer=sanitizer) == (
+        "<p>&lt;item&gt; is kept.</p>"
+    )
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A package whose docstring, README, and theme hooks hold scripts."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text(
+        '"""Shop <b>now</b>.\n\n<script>alert(1)</script>\n"""\n',
+        encoding="utf-8",
+    )
+    (tmp_path / "shop" / "README.md").write_text(
+        '# Shop\n\n<p align="center" onmouseover="alert(2)">Carts</p>\n',
+        encoding="utf-8",
+    )
+    (tmp_path / "autodoc.yaml").write_text(
+        "site:\n"
+        "  theme:\n"
+        "    extra_head: <link rel=\"icon\" href=\"favicon.ico\"><script>x()</script>\n"
+        "    footer_html: <p onclick=\"y()\">&copy; Acme</p>\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def _page(tree: Path, name: str) -> str:
+    output = tree / "site"
+    argv = ["generate", "--root", str(tree), "--output", str(output)]
+    assert run_command([*argv, "--format", "html"]) == 0
+    return (output / name).read_text(encoding="utf-8")
+
+
+def test_site_pages(tree: Path) -> None:
+    page = _page(tree, "shop.html")
+    assert "<script>" not in page
+    script = "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"
+    assert f"<p>Shop <b>now</b>.</p>\n{script}" in page
+    assert '<p align="center">Carts</p>' in page
+    assert '<link rel="icon" href="favicon.ico">&lt;script&gt;' in page
+    assert '<footer class="autodoc-footer"><p>© Acme</p></footer>' in page
+
+
+def test_sanitize_off(tree: Path) -> None:
+    config = tree / "autodoc.yaml"
+    config.write_text(
+        config.read_text(encoding="utf-8") + "  sanitize: false\n",
+        encoding="utf-8",
+    )
+    page = _page(tree, "shop.html")
+    assert "<p>Shop &lt;b&gt;now&lt;/b&gt;.</p>" in page
+    assert '<link rel="icon" href="favicon.ico"><script>x()</script>' in page
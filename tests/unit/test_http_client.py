@@ -0,0 +1,120 @@
+"""Unit tests for the retrying, rate-limited integration client."""
+
+from __future__ import annotations
+
+import httpx
+import pytest
+
+from autodoc.config.settings import GitHubSettings
+from services.http_client import (
+    IntegrationClient,
+    RateLimiter,
+    RetryPolicy,
+    integration_client,
+)
+
+
+class ScriptedClient:
+    """Answers each request with the next entry: a status, headers, or an error."""
+
+    def __init__(self, script: list) -> None:
+        self.script = list(script)
+        self.calls: list[tuple[str, str]] = []
+
+    def _send(self, method: str, url: str) -> httpx.Response:
+        self.calls.append((method, url))
+        entry = self.script.pop(0)
+        if isinstance(entry, Exception):
+            raise entry
+        status, headers = entry if isinstance(entry, tuple) else (entry, {})
+        request = httpx.Request(method, f"https://api.example.com{url}")
+        return httpx.Response(status, request=request, json={}, headers=headers)
+
+    def get(self, url: str, **kwargs) -> httpx.Response:
+        return self._send("GET", url)
+
+    def post(self, url: str, **kwargs) -> httpx.Response:
+        return self._send("POST", url)
+
+
+def _client(script: list, **policy) -> tuple[IntegrationClient, ScriptedClient, list]:
+    fake = ScriptedClient(script)
+    waits: list[float] = []
+    client = IntegrationClient("test", fake, RetryPolicy(**policy), sleep=waits.append)
+    return client, fake, waits
+
+
+def test_retries_transient_statuses_with_backoff() -> None:
+    client, fake, waits = _client([503, 502, 200], backoff=1.0)
+
+    assert client.get("/pages").status_code == 200
+    assert len(fake.calls) == 3
+    assert waits == [1.0, 2.0]
+
+
+def test_returns_last_response_when_retries_run_out() -> None:
+    client, fake, _ = _client([503, 503], max_retries=1)
+
+    assert client.get("/pages").status_code == 503
+    assert len(fake.calls) == 2
+
+
+def test_post_is_retried_only_when_the_server_did_not_act() -> None:
+    client, fake, waits = _client([503])
+    assert client.post("/issues").status_code == 503
+    assert len(fake.calls) == 1
+
+    client, fake, waits = _client([(429, {"Retry-After": "7"}), 201])
+    assert client.post("/issues").status_code == 201
+    assert waits == [7.0]
+
+    refused = httpx.ConnectError("refused")
+    client, fake, _ = _client([refused, 201])
+    assert client.post("/issues").status_code == 201
+
+    client, fake, _ = _client([httpx.ReadError("reset")])
+    with pytest.raises(httpx.ReadError):
+        client.post("/issues")
+    assert len(fake.calls) == 1
+
+
+def test_waits_for_an_exhausted_github_rate_limit() -> None:
+    policy = RetryPolicy(max_backoff=60.0)
+    headers = {"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1045"}
+    request = httpx.Request("GET", "https://api.github.com/repos/o/r/issues")
+    response = httpx.Response(403, request=request, headers=headers)
+
+    assert policy.should_retry("GET", response)
+    assert policy.delay(0, response, now=1000.0) == 45.0
+    assert not policy.should_retry("GET", httpx.Response(403, request=request))
+
+
+def test_rate_limiter_spaces_requests_after_the_burst() -> None:
+    now = [0.0]
+    waits: list[float] = []
+
+    def sleep(seconds: float) -> None:
+        waits.append(seconds)
+        now[0] += seconds
+
+    limiter = RateLimiter(2.0, burst=2, clock=lambda: now[0], sleep=sleep)
+    for _ in range(4):
+        limiter.acquire()
+
+    assert waits == [0.5, 0.5]
+
+
+def test_integration_client_is_configured_from_settings() -> None:
+    settings = GitHubSettings(
+        token="t",
+        repository="o/r",
+        max_retries=5,
+        retry_backoff=2.0,
+        rate_limit=10,
+    )
+
+    client = integration_client("github", settings, ScriptedClient([]))
+
+    assert client.policy.max_retries == 5
+    assert client.policy.backoff == 2.0
+    assert client.limiter is not None and client.limiter.rate == 10
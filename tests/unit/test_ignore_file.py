@@ -0,0 +1,94 @@
+"""Unit tests for ``.autodocignore`` handling."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.doc_symbols import discover_python_files
+from services.ignore_file import IGNORE_FILE, load_ignore_file, parse_ignore_file
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+class TestIgnorePatterns:
+    """Tests for gitignore-style pattern matching."""
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        ("pattern", "path", "expected"),
+        [
+            ("gen_*.py", "gen_api.py", True),
+            ("gen_*.py", "pkg/gen_api.py", True),
+            ("/gen_*.py", "pkg/gen_api.py", False),
+            ("pkg/*.py", "pkg/mod.py", True),
+            ("pkg/*.py", "pkg/sub/mod.py", False),
+            ("pkg/**/mod.py", "pkg/a/b/mod.py", True),
+            ("**/migrations", "app/migrations/0001.py", True),
+            ("mod.py", "module.py", False),
+            ("mod_[0-9].py", "mod_3.py", True),
+        ],
+    )
+    def test_matching(self, pattern, path, expected):
+        assert parse_ignore_file(pattern).ignores(path) is expected
+
+    @pytest.mark.unit
+    def test_comments_and_blank_lines(self):
+        ignore = parse_ignore_file("# generated code\n\n\\#odd.py\n")
+
+        assert len(ignore.rules) == 1
+        assert ignore.ignores("#odd.py")
+
+    @pytest.mark.unit
+    def test_directory_only_pattern(self):
+        ignore = parse_ignore_file("build/\n")
+
+        assert ignore.ignores("build/mod.py")
+        assert not ignore.ignores("build")
+
+    @pytest.mark.unit
+    def test_negation_re_includes(self):
+        ignore = parse_ignore_file("*_pb2.py\n!keep_pb2.py\n")
+
+        assert ignore.ignores("api_pb2.py")
+        assert not ignore.ignores("keep_pb2.py")
+
+    @pytest.mark.unit
+    def test_cannot_re_include_inside_ignored_directory(self):
+        ignore = parse_ignore_file("vendor/\n!vendor/keep.py\n")
+
+        assert ignore.ignores("vendor/keep.py")
+
+    @pytest.mark.unit
+    def test_missing_file_ignores_nothing(self, tmp_path):
+        ignore = load_ignore_file(tmp_path)
+
+        assert not ignore
+        assert ignore.filter(["a.py"]) == ["a.py"]
+
+
+class TestIgnoredDiscovery:
+    """Tests for commands skipping ignored paths."""
+
+    @pytest.fixture
+    def root(self, tmp_path: Path) -> Path:
+        """A tree with an undocumented generated package that is ignored."""
+        _write(tmp_path / "app.py", '"""App."""\n')
+        _write(tmp_path / "generated" / "api.py", "def undocumented():\n    pass\n")
+        _write(tmp_path / IGNORE_FILE, "generated/\n")
+        return tmp_path
+
+    @pytest.mark.unit
+    def test_discovery_skips_ignored_paths(self, root):
+        assert discover_python_files(root) == [root / "app.py"]
+
+    @pytest.mark.unit
+    def test_lint_skips_ignored_paths(self, root, capsys):
+        assert run_command(["lint", "--root", str(root)]) == 0
+        assert "undocumented" not in capsys.readouterr().out
+
+        (root / IGNORE_FILE).unlink()
+        assert run_command(["lint", "--root", str(root)]) == 1
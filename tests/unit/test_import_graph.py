@@ -0,0 +1,127 @@
+"""Unit tests for import/reference analysis and symbol usage ranking."""
+
+from pathlib import Path
+
+import pytest
+
+from services.doc_markdown import render_package_markdown
+from services.doc_site import attach_most_used, build_site_model
+from services.doc_symbols import load_doc_symbols
+from services.import_graph import build_import_graph, symbol_usage
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """Three packages: ``api`` and ``cli`` both use ``core``."""
+    _write(
+        tmp_path / "core" / "engine.py",
+        '"""Engine."""\n\n'
+        "class Engine:\n"
+        '    """Engine."""\n\n'
+        "    def start(self):\n"
+        '        """Start."""\n\n'
+        "def helper():\n"
+        '    """Help."""\n\n'
+        "def unused():\n"
+        '    """Nobody calls this."""\n',
+    )
+    _write(
+        tmp_path / "core" / "util.py",
+        "from .engine import helper\n\nhelper()\n",
+    )
+    _write(
+        tmp_path / "api" / "views.py",
+        "import core.engine as ce\n"
+        "from core.engine import Engine\n\n"
+        "def run():\n"
+        "    ce.helper()\n"
+        "    Engine().start()\n",
+    )
+    _write(
+        tmp_path / "cli" / "main.py",
+        "import core.engine\n\n"
+        "def main():\n"
+        "    core.engine.Engine.start(None)\n",
+    )
+    return tmp_path
+
+
+class TestImportGraph:
+    """Tests for building the import graph."""
+
+    @pytest.mark.unit
+    def test_resolves_aliases_and_relative_imports(self, tree):
+        graph = build_import_graph(tree)
+
+        views = graph.modules["api.views"]
+        assert views.aliases == {"ce": "core.engine", "Engine": "core.engine.Engine"}
+        assert {"core.engine.helper", "core.engine.Engine"} <= views.references
+        assert "core.engine.helper" in graph.modules["core.util"].references
+        assert "core.engine.Engine.start" in graph.modules["cli.main"].references
+
+    @pytest.mark.unit
+    def test_relative_root_names_modules_like_symbols(self, tree, monkeypatch):
+        monkeypatch.chdir(tree.parent)
+        graph = build_import_graph(tree.name)
+
+        assert sorted(graph.modules) == [
+            "api.views",
+            "cli.main",
+            "core.engine",
+            "core.util",
+        ]
+        assert graph.modules["core.engine"].package == "core"
+        symbols = load_doc_symbols(tree.name)
+        modules = {s.qualified_name for s in symbols if s.kind == "module"}
+        assert modules <= set(graph.modules)
+
+    @pytest.mark.unit
+    def test_module_and_package_edges(self, tree):
+        graph = build_import_graph(tree)
+        assert graph.module_edges()["core.util"] == {"core.engine"}
+        assert graph.package_edges() == {
+            "api": {"core"},
+            "cli": {"core"},
+            "core": set(),
+        }
+
+
+class TestSymbolUsage:
+    """Tests for counting outside users of symbols."""
+
+    @pytest.mark.unit
+    def test_counts_distinct_outside_packages(self, tree):
+        symbols = load_doc_symbols(tree)
+        usage = {
+            u.qualified_name: u
+            for u in symbol_usage(build_import_graph(tree), symbols)
+        }
+
+        assert usage["core.engine.Engine"].used_by == ("api", "cli")
+        assert usage["core.engine.Engine.start"].used_by == ("cli",)
+        # Same-package use (core.util) does not count.
+        assert usage["core.engine.helper"].used_by == ("api",)
+        assert usage["core.engine.unused"].count == 0
+
+    @pytest.mark.unit
+    def test_attach_most_used(self, tree):
+        symbols = load_doc_symbols(tree)
+        counts = {
+            u.qualified_name: u.count
+            for u in symbol_usage(build_import_graph(tree), symbols)
+        }
+        packages = {p.name: p for p in build_site_model(symbols)}
+        attach_most_used(packages.values(), counts, limit=2)
+
+        ranked = [(s.qualified_name, n) for s, n in packages["core"].most_used]
+        assert ranked == [("core.engine.Engine", 2), ("core.engine.Engine.start", 1)]
+        assert packages["api"].most_used == []
+
+        page = render_package_markdown(packages["core"])
+        assert "## Most used\n\n1. [`core.engine.Engine`](#engine) - used by 2" in page
+        assert "#### `Engine.start`" in page
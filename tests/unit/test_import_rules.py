@@ -0,0 +1,211 @@
+"""Unit tests for import layering rules."""
+
+import json
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import LintConfig, ProjectConfigError
+from services.changed_scope import ChangedScope
+from services.custom_lint_rules import RuleConfigError
+from services.import_graph import build_import_graph
+from services.import_rules import (
+    check_imports,
+    import_rule_from_dict,
+    import_rules_from_config,
+    matches_module,
+)
+
+API_NOT_DB = {
+    "id": "api-not-db",
+    "modules": ["app.api"],
+    "forbid": ["app.db"],
+    "allow": ["app.db.schemas"],
+}
+
+CONFIG = """\
+lint:
+  imports:
+    - id: api-not-db
+      modules: app.api
+      forbid: app.db
+"""
+
+
+def _write(root: Path, files: dict[str, str]) -> None:
+    for name, content in files.items():
+        path = root / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def layered_tree(tmp_path: Path) -> Path:
+    _write(
+        tmp_path,
+        {
+            "app/__init__.py": '"""App."""\n',
+            "app/api/__init__.py": '"""API."""\n',
+            "app/api/routes.py": (
+                '"""Routes."""\n'
+                "from app.db.schemas import Row\n"
+                "from app.db.session import Session, engine\n"
+                "import app.services\n"
+            ),
+            "app/db/__init__.py": '"""DB."""\n',
+            "app/db/models.py": '"""Models."""\n',
+            "app/db/schemas.py": '"""Schemas."""\n',
+            "app/db/session.py": '"""Session."""\n',
+            "app/services.py": '"""Services."""\nfrom app.db import session\n',
+        },
+    )
+    return tmp_path
+
+
+class TestImportRules:
+    """Tests for parsing and evaluating ``lint.imports``."""
+
+    @pytest.mark.unit
+    def test_patterns_match_submodules_and_wildcards(self):
+        assert matches_module("app.db", ["app.db"])
+        assert matches_module("app.db.session", ["app.db"])
+        assert not matches_module("app.dbx", ["app.db"])
+        assert matches_module("app.orders.internal.repo", ["app.*.internal"])
+
+    @pytest.mark.unit
+    def test_forbidden_import_is_reported_once(self, layered_tree):
+        graph = build_import_graph(layered_tree)
+        rule = import_rule_from_dict(API_NOT_DB)
+        findings = check_imports(graph, [rule], layered_tree)
+
+        assert [(f.file_path, f.lineno, f.symbol) for f in findings] == [
+            ("app/api/routes.py", 3, "app.api.routes -> app.db.session"),
+        ]
+        assert findings[0].message == (
+            "app.api.routes must not import app.db.session (api-not-db)"
+        )
+
+    @pytest.mark.unit
+    def test_other_layers_are_unconstrained(self, layered_tree):
+        graph = build_import_graph(layered_tree)
+        rule = import_rule_from_dict({**API_NOT_DB, "modules": "app.services"})
+        findings = check_imports(graph, [rule], layered_tree)
+        assert [f.symbol for f in findings] == ["app.services -> app.db.session"]
+
+    @pytest.mark.unit
+    def test_third_party_imports_match_as_written(self, tmp_path):
+        _write(tmp_path, {"api.py": "from sqlalchemy.orm import Session\n"})
+        rule = import_rule_from_dict(
+            {"id": "no-orm", "modules": "api", "forbid": "sqlalchemy"},
+        )
+        findings = check_imports(build_import_graph(tmp_path), [rule], tmp_path)
+        assert [f.symbol for f in findings] == ["api -> sqlalchemy.orm.Session"]
+
+    @pytest.mark.unit
+    def test_bad_message_template_falls_back(self):
+        rule = import_rule_from_dict({**API_NOT_DB, "message": "{nope}"})
+        assert rule.render("a", "b", "b.c") == "a must not import b (api-not-db)"
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        ("entry", "error"),
+        [
+            ({"modules": "a", "forbid": "b"}, "Rule id"),
+            ({"id": "r", "forbid": "b"}, "required"),
+            ({"id": "r", "modules": "a", "forbid": [1]}, "module pattern"),
+            ({"id": "r", "modules": "a", "forbid": "b", "to": "c"}, "unknown keys"),
+            ({"id": "r", "modules": "a", "forbid": "b", "severity": "x"}, "severity"),
+        ],
+    )
+    def test_invalid_rules_are_rejected(self, entry, error):
+        with pytest.raises(RuleConfigError, match=error):
+            import_rule_from_dict(entry)
+
+    @pytest.mark.unit
+    def test_ids_are_shared_with_lint_rules(self):
+        config = LintConfig(
+            rules=[{"id": "api-not-db", "require": "documented"}],
+            imports=[API_NOT_DB],
+        )
+        with pytest.raises(RuleConfigError, match="Duplicate"):
+            import_rules_from_config(config)
+        assert import_rules_from_config(
+            LintConfig(disable=["api-not-db"], imports=[API_NOT_DB]),
+        ) == []
+
+    @pytest.mark.unit
+    def test_config_section_must_be_a_list(self):
+        with pytest.raises(ProjectConfigError, match="lint.imports"):
+            LintConfig.from_dict({"imports": {"id": "x"}})
+
+    @pytest.mark.unit
+    def test_touches_line(self):
+        scope = ChangedScope(repo=Path("/repo"), ranges={"a.py": [(3, 5)]})
+        assert scope.touches_line("/repo/a.py", 4)
+        assert not scope.touches_line("/repo/a.py", 6)
+        assert not scope.touches_line("/repo/b.py", 4)
+
+
+class TestImportRulesCommand:
+    """Tests for import rules in ``autodoc lint`` and ``autodoc baseline``."""
+
+    @pytest.mark.unit
+    def test_lint_reports_violations_with_doc_findings(self, layered_tree, capsys):
+        (layered_tree / "autodoc.yaml").write_text(CONFIG, encoding="utf-8")
+        (layered_tree / "app/db/session.py").write_text("def engine():\n    pass\n")
+
+        status = run_command(
+            ["lint", "--root", str(layered_tree), "--format", "json"],
+        )
+        findings = json.loads(capsys.readouterr().out)["findings"]
+
+        assert status == 1
+        assert {(f["rule"], f["symbol"]) for f in findings} == {
+            ("api-not-db", "app.api.routes -> app.db.schemas"),
+            ("api-not-db", "app.api.routes -> app.db.session"),
+            ("missing-docstring", "app.db.session"),
+            ("missing-docstring", "app.db.session.engine"),
+        }
+
+    @pytest.mark.unit
+    def test_baseline_accepts_existing_violations(self, layered_tree, capsys):
+        (layered_tree / "autodoc.yaml").write_text(CONFIG, encoding="utf-8")
+        assert run_command(["baseline", "write", "--root", str(layered_tree)]) == 0
+        assert run_command(["lint", "--root", str(layered_tree)]) == 0
+
+        routes = layered_tree / "app/api/routes.py"
+        routes.write_text(routes.read_text() + "import app.db.models\n")
+        capsys.readouterr()
+        assert run_command(["lint", "--root", str(layered_tree)]) == 1
+        assert "must not import app.db.models" in capsys.readouterr().out
+
+    @pytest.mark.unit
+    def test_changed_only_keeps_violations_on_changed_lines(self, layered_tree, capsys):
+        def git(*args: str) -> None:
+            subprocess.run(
+                ["git", *args],
+                cwd=layered_tree,
+                check=True,
+                capture_output=True,
+            )
+
+        (layered_tree / "autodoc.yaml").write_text(CONFIG, encoding="utf-8")
+        git("init", "-q", "-b", "main")
+        git("config", "user.email", "dev@example.com")
+        git("config", "user.name", "Dev")
+        git("add", ".")
+        git("commit", "-q", "-m", "legacy")
+        git("checkout", "-q", "-b", "feature")
+        routes = layered_tree / "app/api/routes.py"
+        routes.write_text(routes.read_text() + "import app.db.models\n")
+        git("commit", "-q", "-am", "change")
+
+        argv = ["lint", "--root", str(layered_tree), "--changed-only", "--base", "main"]
+        assert run_command(argv) == 1
+        lines = capsys.readouterr().out.splitlines()
+        assert lines == [
+            "app/api/routes.py:5: [api-not-db] "
+            "app.api.routes must not import app.db.models (api-not-db)",
+        ]
@@ -0,0 +1,127 @@
+"""Unit tests for ``generate --incremental``."""
+
+import json
+import logging
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.incremental import STATE_FILE
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    """Three packages: ``app`` imports ``core``; ``tools`` stands alone."""
+    src = tmp_path / "src"
+    for package in ("app", "core", "tools"):
+        (src / package).mkdir(parents=True)
+        (src / package / "__init__.py").write_text(
+            f'"""The {package} package."""\n', encoding="utf-8"
+        )
+    (src / "core" / "base.py").write_text(
+        'def helper():\n    """Help."""\n', encoding="utf-8"
+    )
+    (src / "app" / "main.py").write_text(
+        "from core.base import helper\n\n\n"
+        'def run():\n    """Run."""\n    helper()\n',
+        encoding="utf-8",
+    )
+    (src / "tools" / "cli.py").write_text(
+        'def main():\n    """Main."""\n', encoding="utf-8"
+    )
+    return src
+
+
+def _generate(src: Path, out: Path, *extra: str) -> int:
+    return run_command(
+        ["generate", "--root", str(src), "--output", str(out), *extra]
+    )
+
+
+def _snapshot(out: Path) -> dict[str, bytes]:
+    return {
+        path.relative_to(out).as_posix(): path.read_bytes()
+        for path in sorted(out.rglob("*"))
+        if path.is_file() and path.name != STATE_FILE
+    }
+
+
+class TestIncrementalGenerate:
+    """Tests for re-using the previous run's analysis and pages."""
+
+    @pytest.mark.unit
+    def test_first_run_writes_state(self, source_tree, tmp_path):
+        out = tmp_path / "site"
+
+        assert _generate(source_tree, out, "--incremental") == 0
+
+        state = json.loads((out / STATE_FILE).read_text(encoding="utf-8"))
+        assert "core/base.py" in state["sources"]
+        assert "core/base.py" in state["packages"]["app"]
+        assert "core/base.py" not in state["packages"]["tools"]
+
+    @pytest.mark.unit
+    def test_matches_full_run_after_change(self, source_tree, tmp_path):
+        incremental = tmp_path / "incremental"
+        full = tmp_path / "full"
+        _generate(source_tree, incremental, "--incremental")
+        (source_tree / "core" / "base.py").write_text(
+            'def helper():\n    """Help more."""\n\n\n'
+            'def extra():\n    """Extra."""\n',
+            encoding="utf-8",
+        )
+
+        assert _generate(source_tree, incremental, "--incremental") == 0
+        assert _generate(source_tree, full) == 0
+
+        assert _snapshot(incremental) == _snapshot(full)
+
+    @pytest.mark.unit
+    def test_only_changed_files_are_parsed(self, source_tree, tmp_path, caplog):
+        out = tmp_path / "site"
+        _generate(source_tree, out, "--incremental")
+        (source_tree / "tools" / "cli.py").write_text(
+            'def main():\n    """Main entry."""\n', encoding="utf-8"
+        )
+
+        with caplog.at_level(logging.INFO):
+            _generate(source_tree, out, "--incremental", "-v")
+
+        assert "Reusing symbols of 5 unchanged file(s); parsing 1" in caplog.text
+        assert "rendering 1 of 3 package page(s)" in caplog.text
+
+    @pytest.mark.unit
+    def test_unaffected_pages_are_carried_over(self, source_tree, tmp_path):
+        out = tmp_path / "site"
+        _generate(source_tree, out, "--incremental")
+        # A marker in an unaffected page survives only if it is not rendered.
+        page = out / "core.md"
+        page.write_text(page.read_text(encoding="utf-8") + "marker\n")
+        (source_tree / "tools" / "cli.py").write_text(
+            'def main():\n    """Main entry."""\n', encoding="utf-8"
+        )
+
+        _generate(source_tree, out, "--incremental")
+
+        assert page.read_text(encoding="utf-8").endswith("marker\n")
+        assert "Main entry." in (out / "tools.md").read_text(encoding="utf-8")
+
+    @pytest.mark.unit
+    def test_changed_settings_regenerate_everything(self, source_tree, tmp_path):
+        out = tmp_path / "site"
+        _generate(source_tree, out, "--incremental")
+        page = out / "core.md"
+        page.write_text(page.read_text(encoding="utf-8") + "marker\n")
+
+        _generate(source_tree, out, "--incremental", "--include-private")
+
+        assert not page.read_text(encoding="utf-8").endswith("marker\n")
+
+    @pytest.mark.unit
+    def test_dry_run_keeps_state(self, source_tree, tmp_path):
+        out = tmp_path / "site"
+
+        _generate(source_tree, out, "--incremental", "--dry-run")
+
+        assert not (out / STATE_FILE).exists()
@@ -0,0 +1,88 @@
+"""Unit tests for docstrings inherited from interfaces."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.inherited_docs import find_inherited_docs
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A store interface, a middle class, and an implementation."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "store.py").write_text(
+        '"""Stores."""\n\n'
+        "from typing import Protocol\n\n\n"
+        "class Store(Protocol):\n"
+        '    """Where values are kept."""\n\n'
+        "    def get(self, key: str) -> bytes:\n"
+        '        """Return the value under ``key``.\n\n'
+        "        Args:\n"
+        '            key: The name of the value.\n        """\n\n'
+        "    def put(self, key: str, value: bytes) -> None:\n"
+        "        pass\n",
+        encoding="utf-8",
+    )
+    (tmp_path / "shop" / "disk.py").write_text(
+        '"""Disk stores."""\n\n'
+        "from shop.store import Store\n\n\n"
+        "class Cached(Store):\n"
+        '    """A cached store."""\n\n'
+        "    def get(self, key: str) -> bytes:\n"
+        "        return b''\n\n\n"
+        "class DiskStore(Cached):\n"
+        '    """A store on disk."""\n\n'
+        "    def get(self, key: str) -> bytes:\n"
+        "        return b''\n\n"
+        "    def put(self, key: str, value: bytes) -> None:\n"
+        "        pass\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_find_inherited_docs(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    docs = find_inherited_docs(parsed.graph, parsed.symbols)
+
+    assert sorted((doc.symbol, doc.source) for doc in docs) == [
+        ("shop.disk.Cached.get", "shop.store.Store.get"),
+        ("shop.disk.DiskStore.get", "shop.store.Store.get"),
+    ]
+
+
+def test_inherited_docs_rendered(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig(site=SiteConfig.from_dict({"inherit_docs": True}))
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed, config), formats, config)
+
+    markdown = {page.path: page.content for page in sites["markdown"]}["shop.md"]
+    assert (
+        "_Inherited from [`shop.store.Store.get`](shop.md#storeget)._\n\n"
+        "Return the value under ``key``.\n\n**Parameters**"
+    ) in markdown
+    html = {page.path: page.content for page in sites["html"]}["shop.html"]
+    assert (
+        '<p class="autodoc-inherited">Inherited from <a href="shop.html#'
+        'shop.store.Store.get"><code>shop.store.Store.get</code></a></p>'
+    ) in html
+    index = {page.path: page.content for page in sites["json"]}["index.json"]
+    [package] = json.loads(index)["packages"]
+    assert {"symbol": "shop.disk.DiskStore.get", "source": "shop.store.Store.get"} in (
+        package["inherited"]
+    )
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    assert "Inherited from" not in default[1].content
+    with pytest.raises(ProjectConfigError, match="site.inherit_docs"):
+        SiteConfig.from_dict({"inherit_docs": "yes"})
@@ -0,0 +1,90 @@
+"""Unit tests for the library API (``autodoc.parser``, ``.model``, ``.render``)."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site, render_test_site, write_site
+from services.cancellation import CancelToken
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    """A package with a documented function and a test for it."""
+    src = tmp_path / "src"
+    (src / "shop").mkdir(parents=True)
+    (src / "shop" / "__init__.py").write_text(
+        '"""Shop package."""\n', encoding="utf-8"
+    )
+    (src / "shop" / "cart.py").write_text(
+        'def checkout(cart):\n    """Check out ``cart``."""\n', encoding="utf-8"
+    )
+    (src / "tests").mkdir()
+    (src / "tests" / "test_cart.py").write_text(
+        "from shop.cart import checkout\n\n\n"
+        "def test_checkout_empty_cart():\n    checkout([])\n",
+        encoding="utf-8",
+    )
+    return src
+
+
+class TestLibraryApi:
+    """Tests for running the generate pipeline without the CLI."""
+
+    @pytest.mark.unit
+    def test_parse_tree(self, source_tree):
+        tree = parse_tree(source_tree)
+
+        assert "shop.cart.checkout" in {s.qualified_name for s in tree.symbols}
+        assert "shop.cart" in tree.graph.modules
+
+    @pytest.mark.unit
+    def test_cancelled_parse_is_partial(self, source_tree):
+        cancel = CancelToken()
+        cancel.cancel()
+
+        tree = parse_tree(source_tree, cancel=cancel)
+
+        assert tree.symbols == []
+        assert cancel.partial
+
+    @pytest.mark.unit
+    def test_build_model(self, source_tree):
+        packages = build_model(parse_tree(source_tree))
+
+        assert [p.name for p in packages] == ["shop", "tests"]
+
+    @pytest.mark.unit
+    def test_render_matches_cli(self, source_tree, tmp_path):
+        tree = parse_tree(source_tree)
+        sites = render_site(tree, build_model(tree), ["markdown", "html"])
+        for fmt, pages in sites.items():
+            write_site(pages, tmp_path / "library" / fmt)
+
+        run_command(
+            [
+                "generate",
+                "--root",
+                str(source_tree),
+                "--output",
+                str(tmp_path / "cli"),
+                "--format",
+                "markdown,html",
+            ],
+        )
+
+        for fmt in ("markdown", "html"):
+            for page in sites[fmt]:
+                assert (tmp_path / "cli" / fmt / page.path).read_bytes() == (
+                    tmp_path / "library" / fmt / page.path
+                ).read_bytes()
+
+    @pytest.mark.unit
+    def test_render_test_site(self, source_tree):
+        pages = render_test_site(parse_tree(source_tree))["markdown"]
+
+        assert [page.path for page in pages] == ["index.md"]
+        assert "Checkout empty cart" in pages[0].content
@@ -0,0 +1,109 @@
+"""Unit tests for the LSIF code navigation index."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+from typing import Any
+
+import pytest
+
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.lsif import LSIF_PATH, find_references
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package whose checkout imports the cart."""
+    package = tmp_path / "shop"
+    package.mkdir()
+    (package / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (package / "cart.py").write_text(
+        '"""Carts (€)."""\n\n\n'
+        "class Cart:\n"
+        '    """A cart."""\n',
+        encoding="utf-8",
+    )
+    (package / "checkout.py").write_text(
+        '"""Checkout."""\n\n'
+        "from shop.cart import Cart\n\n\n"
+        "def pay(cart: Cart) -> None:\n"
+        '    """Pay for ``cart``."""\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def _dump(tree: Path) -> list[dict[str, Any]]:
+    parsed = parse_tree(tree)
+    [page] = render_site(parsed, build_model(parsed), ("lsif",))["lsif"]
+    assert page.path == LSIF_PATH
+    return [json.loads(line) for line in page.content.splitlines()]
+
+
+def test_find_references(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    references = find_references(tree, parsed.graph, ["shop.cart.Cart"])
+
+    assert [(r.file_path, r.lineno, r.col_offset) for r in references] == [
+        ("shop/checkout.py", 3, 22),
+        ("shop/checkout.py", 6, 14),
+    ]
+
+
+def test_definitions_references_and_hovers(tree: Path) -> None:
+    elements = _dump(tree)
+    by_id = {element["id"]: element for element in elements}
+    assert elements[0]["label"] == "metaData"
+    assert elements[0]["positionEncoding"] == "utf-16"
+    documents = {
+        element["uri"]: element["id"]
+        for element in elements
+        if element["label"] == "document"
+    }
+    assert set(documents) == {
+        "file:///shop/__init__.py",
+        "file:///shop/cart.py",
+        "file:///shop/checkout.py",
+    }
+
+    [moniker] = [
+        element
+        for element in elements
+        if element["type"] == "vertex"
+        and element["label"] == "moniker"
+        and element["identifier"] == "shop.cart.Cart"
+    ]
+    [result_set] = [
+        element["outV"]
+        for element in elements
+        if element["type"] == "edge" and element.get("inV") == moniker["id"]
+    ]
+    edges = {
+        element["label"]: element
+        for element in elements
+        if element["type"] == "edge" and element["outV"] == result_set
+    }
+    hover = by_id[edges["textDocument/hover"]["inV"]]["result"]["contents"]
+    assert hover == [{"language": "python", "value": "class Cart"}, "A cart."]
+
+    items = [
+        element
+        for element in elements
+        if element["label"] == "item"
+        and element["outV"] == edges["textDocument/references"]["inV"]
+    ]
+    ranges = {
+        (item["property"], item["document"]): [by_id[r] for r in item["inVs"]]
+        for item in items
+    }
+    [definition] = ranges["definitions", documents["file:///shop/cart.py"]]
+    assert definition["start"] == {"line": 3, "character": 6}
+    assert definition["end"] == {"line": 3, "character": 10}
+    uses = ranges["references", documents["file:///shop/checkout.py"]]
+    assert [use["start"] for use in uses] == [
+        {"line": 2, "character": 22},
+        {"line": 5, "character": 14},
+    ]
@@ -0,0 +1,130 @@
+"""Unit tests for interface/mock linkage."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfigError, SiteConfig
+from services.doc_markdown import render_markdown_site
+from services.doc_site import attach_mock_links, build_site_model
+from services.doc_symbols import load_doc_symbols
+from services.import_graph import build_import_graph
+from services.mock_links import find_mock_links, is_mock_module, mocked_name
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``Store`` protocol with a subclass mock, a generated mock and a spec."""
+    _write(
+        tmp_path / "shop" / "store.py",
+        '"""Storage."""\n'
+        "import abc\n"
+        "from typing import Protocol\n\n"
+        "class Store(Protocol):\n"
+        '    """Persist carts."""\n\n'
+        "class Cache(abc.ABC):\n"
+        '    """Cache entries."""\n\n'
+        "class Plain:\n"
+        '    """Not an interface."""\n',
+    )
+    _write(
+        tmp_path / "shop" / "mocks" / "store.py",
+        '"""Generated mocks."""\n\n'
+        "class MockStore:\n"
+        '    """Mock of Store."""\n\n'
+        "class MockPlain:\n"
+        '    """Mock of a concrete class."""\n',
+    )
+    _write(
+        tmp_path / "tests" / "test_cart.py",
+        "from unittest import mock\n"
+        "from shop.store import Cache, Store\n\n"
+        "class FakeCache(Cache):\n"
+        "    pass\n\n"
+        "def test_save():\n"
+        "    store = mock.create_autospec(Store)\n\n"
+        "shared = mock.MagicMock(spec=Cache)\n",
+    )
+    return tmp_path
+
+
+class TestFindMockLinks:
+    """Tests for pairing interfaces with their mocks."""
+
+    @pytest.mark.unit
+    def test_links(self, tree):
+        links = find_mock_links(tree, build_import_graph(tree))
+
+        assert [(link.mock, link.interface, link.via) for link in links] == [
+            ("tests.test_cart.FakeCache", "shop.store.Cache", "subclass"),
+            ("tests.test_cart.shared", "shop.store.Cache", "spec"),
+            ("shop.mocks.store.MockStore", "shop.store.Store", "name"),
+            ("tests.test_cart.test_save", "shop.store.Store", "spec"),
+        ]
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        ("path", "expected"),
+        [
+            ("pkg/mocks/store.py", True),
+            ("pkg/fakes/store.py", True),
+            ("pkg/mock_store.py", True),
+            ("pkg/store_mock.py", True),
+            ("pkg/mockingbird.py", False),
+            ("pkg/store.py", False),
+        ],
+    )
+    def test_is_mock_module(self, path, expected):
+        assert is_mock_module(path) is expected
+
+    @pytest.mark.unit
+    def test_mocked_name(self):
+        assert mocked_name("MockStore") == "Store"
+        assert mocked_name("StoreFake") == "Store"
+        assert mocked_name("Mockingbird") is None
+
+
+class TestMockRendering:
+    """Tests for showing mock links on package pages."""
+
+    @pytest.mark.unit
+    def test_interface_page_lists_hidden_mock(self, tree):
+        symbols = [
+            s for s in load_doc_symbols(tree) if not is_mock_module(s.file_path)
+        ]
+        packages = build_site_model(symbols)
+        links = find_mock_links(tree, build_import_graph(tree))
+        attach_mock_links(packages, [(link.mock, link.interface) for link in links])
+        site = render_markdown_site(packages, SiteConfig())
+        pages = {page.path: page.content for page in site}
+
+        assert "shop.mocks.md" not in pages
+        page = pages["shop.md"]
+        # Hidden mocks are named without a link; documented ones are linked.
+        assert (
+            "Mocked by `shop.mocks.store.MockStore`, "
+            "[`tests.test_cart.test_save`](tests.md#test_save)" in page
+        )
+
+    @pytest.mark.unit
+    def test_mock_page_links_interface(self, tree):
+        packages = build_site_model(load_doc_symbols(tree))
+        links = find_mock_links(tree, build_import_graph(tree))
+        attach_mock_links(packages, [(link.mock, link.interface) for link in links])
+        site = render_markdown_site(packages, SiteConfig())
+        pages = {page.path: page.content for page in site}
+
+        mock_link = "[`shop.mocks.store.MockStore`](shop.mocks.md#mockstore)"
+        assert "Mocks [`shop.store.Store`](shop.md#store)" in pages["shop.mocks.md"]
+        assert mock_link in pages["shop.md"]
+
+    @pytest.mark.unit
+    def test_mocks_setting_is_validated(self):
+        assert SiteConfig.from_dict({}).mocks == "hide"
+        with pytest.raises(ProjectConfigError):
+            SiteConfig.from_dict({"mocks": "sometimes"})
@@ -0,0 +1,159 @@
+"""Unit tests for the name collision report and namesake cross-links."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfig, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_html import render_html_site
+from services.doc_markdown import render_markdown_site
+from services.doc_theme import build_theme_assets
+from services.name_collisions import find_collisions
+
+SOURCES = {
+    "billing/__init__.py": '"""Billing."""\n',
+    "billing/config.py": (
+        '"""Billing settings."""\n\n\n'
+        "class Config:\n"
+        '    """Payment provider credentials."""\n'
+    ),
+    "shop/__init__.py": '"""Shop."""\n',
+    "shop/config.py": '"""Shop settings."""\n\n\nclass Config:\n    pass\n',
+    "shop/cart.py": (
+        '"""Carts."""\n\n\n'
+        "def filter(items):\n"
+        '    """Drop empty items."""\n\n\n'
+        "def total(items):\n"
+        '    """Sum the items."""\n'
+    ),
+    "web/__init__.py": '"""Web."""\n',
+    "web/app.py": (
+        '"""App."""\n\n\n'
+        "class Config:\n"
+        '    """Payment provider credentials."""\n\n\n'
+        "def _private():\n"
+        "    pass\n"
+    ),
+}
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    for name, content in SOURCES.items():
+        path = tmp_path / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content, encoding="utf-8")
+    return tmp_path
+
+
+class TestFindCollisions:
+    """Tests for :func:`services.name_collisions.find_collisions`."""
+
+    @pytest.mark.unit
+    def test_collisions_and_shadowed_builtins(self, source_tree):
+        collisions = find_collisions(parse_tree(source_tree).symbols)
+        assert [(c.name, c.packages, c.shadows_builtin) for c in collisions] == [
+            ("Config", ["billing", "shop", "web"], False),
+            ("filter", ["shop"], True),
+        ]
+        assert [s.qualified_name for s in collisions[0].symbols] == [
+            "billing.config.Config",
+            "shop.config.Config",
+            "web.app.Config",
+        ]
+
+    @pytest.mark.unit
+    def test_indistinct_namesakes(self, source_tree):
+        config = find_collisions(parse_tree(source_tree).symbols)[0]
+        assert config.indistinct == [
+            "billing.config.Config",
+            "shop.config.Config",
+            "web.app.Config",
+        ]
+        assert config.to_dict()["symbols"][0]["summary"] == (
+            "Payment provider credentials."
+        )
+
+
+class TestNamesakeDocs:
+    """Tests for namesake headings and cross-links in generated sites."""
+
+    @pytest.mark.unit
+    def test_model_attaches_namesakes_of_other_packages(self, source_tree):
+        packages = {p.name: p for p in build_model(parse_tree(source_tree))}
+        others = packages["shop"].namesakes["shop.config.Config"]
+        assert [s.qualified_name for s in others] == [
+            "billing.config.Config",
+            "web.app.Config",
+        ]
+        assert "shop.cart.filter" not in packages["shop"].namesakes
+
+    @pytest.mark.unit
+    def test_namesakes_can_be_disabled(self, source_tree):
+        config = ProjectConfig.from_dict({"site": {"namesakes": False}})
+        packages = build_model(parse_tree(source_tree), config)
+        assert not any(package.namesakes for package in packages)
+
+    @pytest.mark.unit
+    def test_markdown_qualifies_and_links_namesakes(self, source_tree):
+        packages = build_model(parse_tree(source_tree))
+        pages = {
+            p.path: p.content for p in render_markdown_site(packages, SiteConfig())
+        }
+        shop = pages["shop.md"]
+
+        assert "### `shop.config.Config`" in shop
+        assert "### `total`" in shop
+        assert (
+            "Not to be confused with the other `Config`:\n"
+            "- [`billing.config.Config`](billing.md#billingconfigconfig)"
+            " - Payment provider credentials.\n"
+            "- [`web.app.Config`](web.md#webappconfig)"
+            " - Payment provider credentials."
+        ) in shop
+
+    @pytest.mark.unit
+    def test_html_qualifies_and_links_namesakes(self, source_tree, tmp_path):
+        packages = build_model(parse_tree(source_tree))
+        site = SiteConfig()
+        assets = build_theme_assets(site.theme, tmp_path)
+        pages = {p.path: p.content for p in render_html_site(packages, site, assets)}
+        billing = pages["billing.html"]
+
+        assert ">billing.config.Config</a>" in billing
+        assert 'class="autodoc-namesakes"' in billing
+        assert (
+            '<li><a href="shop.html#shop.config.Config">'
+            "<code>shop.config.Config</code></a></li>"
+        ) in billing
+
+
+class TestCollisionsCommand:
+    """Tests for ``autodoc collisions``."""
+
+    @pytest.mark.unit
+    def test_text_report(self, source_tree, capsys):
+        assert run_command(["collisions", "--root", str(source_tree)]) == 0
+        out = capsys.readouterr().out
+        assert "Config: defined in 3 packages" in out
+        assert "  shop/config.py:4: shop.config.Config - no summary" in out
+        assert (
+            "  web/app.py:4: web.app.Config - Payment provider credentials. "
+            "(same summary as a namesake)"
+        ) in out
+        assert "filter: shadows a builtin" in out
+        assert out.endswith("2 name collision(s)\n")
+
+    @pytest.mark.unit
+    def test_json_and_check(self, source_tree, capsys):
+        argv = ["collisions", "--root", str(source_tree), "--format", "json"]
+        assert run_command([*argv, "--check"]) == 1
+        report = json.loads(capsys.readouterr().out)
+        assert report["schema_version"] == 1
+        collisions = report["collisions"]
+        assert [entry["name"] for entry in collisions] == ["Config", "filter"]
+        assert collisions[1]["symbols"][0]["file_path"] == "shop/cart.py"
@@ -0,0 +1,101 @@
+"""Unit tests for noting whether functions handle None."""
+
+from __future__ import annotations
+
+import ast
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, SiteConfig, ThemeConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_html import render_html_site
+from services.doc_json import render_json_site
+from services.doc_markdown import render_markdown_site
+from services.doc_theme import build_theme_assets
+from services.import_graph import build_import_graph
+from services.none_safety import (
+    check_parameter,
+    find_none_safety,
+    optional_parameters,
+)
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package whose functions take optional parameters."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text(
+        '"""Shop."""\n'
+        "from typing import Optional\n\n"
+        "def total(cart=None, coupon: str | None = None, note=None) -> int:\n"
+        '    """Total of the cart."""\n'
+        "    if coupon is None:\n"
+        '        coupon = "NONE"\n'
+        "    log(note)\n"
+        "    return sum(cart.prices)\n\n"
+        "class Cart:\n"
+        '    """A cart."""\n\n'
+        "    def add(self, item: Optional[str], count: int = 1) -> None:\n"
+        '        """Add an item."""\n'
+        "        self.items = [*self.items, item] if item else self.items\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_optional_parameters() -> None:
+    func = ast.parse(
+        "def f(a, b=None, /, c: int | None = 1, *, d: 'Optional[int]', e=2): pass",
+    ).body[0]
+    assert optional_parameters(func) == ["b", "c", "d"]
+
+
+@pytest.mark.parametrize(
+    ("body", "expected"),
+    [
+        ("assert x is not None\n    return x.strip()", ("unchecked", 2)),
+        ("assert x\n    return x", ("unchecked", 2)),
+        ("if x is None:\n        raise ValueError\n    return x", ("unchecked", 2)),
+        ("if x:\n        return x\n    else:\n        raise E", ("unchecked", 2)),
+        ("y = x.strip()\n    if x is None:\n        return ''", ("unchecked", 2)),
+        ("if x is None:\n        x = ''\n    return x.strip()", ("checked", 2)),
+        ("return x.strip() if x else ''", ("checked", 2)),
+    ],
+)
+def test_check_parameter(body: str, expected: tuple[str, int]) -> None:
+    func = ast.parse(f"def f(x=None):\n    {body}\n").body[0]
+    assert check_parameter(func, "x") == expected
+
+
+def test_none_safety(tree: Path) -> None:
+    checks = find_none_safety(tree, build_import_graph(tree))
+
+    assert [(c.symbol, c.parameter, c.status, c.lineno) for c in checks] == [
+        ("shop.total", "cart", "unchecked", 9),
+        ("shop.total", "coupon", "checked", 6),
+        ("shop.Cart.add", "item", "checked", 16),
+    ]
+    assert checks[0].to_dict()["file_path"] == "shop/__init__.py"
+
+
+def test_pages_note_none_safety(tree: Path, tmp_path: Path) -> None:
+    packages = build_model(parse_tree(tree))
+    page = {p.path: p.content for p in render_markdown_site(packages, SiteConfig())}
+    assert "None-safe: `coupon`\nNot None-safe: `cart`" in page["shop.md"]
+    theme = build_theme_assets(ThemeConfig(), tmp_path)
+    html = {
+        p.path: p.content for p in render_html_site(packages, SiteConfig(), theme)
+    }
+    assert (
+        '<p class="autodoc-none-safety">None-safe: <code>item</code></p>'
+        in html["shop.html"]
+    )
+    data = json.loads(render_json_site(packages, SiteConfig())[0].content)
+    shop = next(p for p in data["packages"] if p["name"] == "shop")
+    assert [c["parameter"] for c in shop["none_safety"]] == ["cart", "coupon", "item"]
+
+    config = ProjectConfig(site=SiteConfig.from_dict({"none_safety": False}))
+    assert build_model(parse_tree(tree), config)[0].none_safety == {}
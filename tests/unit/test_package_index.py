@@ -0,0 +1,123 @@
+"""Unit tests for downloading releases from a package index."""
+
+import hashlib
+import io
+import tarfile
+from pathlib import Path
+
+import httpx
+import pytest
+
+from autodoc.cli.main import run_command
+from services.package_index import (
+    DEFAULT_INDEX,
+    IndexRelease,
+    PackageIndexError,
+    index_url,
+)
+
+INDEX = "https://pypi.internal/simple"
+
+
+def _sdist() -> bytes:
+    files = {
+        "billing-1.2.0/autodoc.yaml": "site:\n  title: Billing\n",
+        "billing-1.2.0/src/billing/__init__.py": '"""Billing."""\n',
+        "billing-1.2.0/src/billing/invoice.py": (
+            '"""Invoices."""\n\n\ndef total() -> int:\n    """Sum."""\n    return 0\n'
+        ),
+    }
+    buffer = io.BytesIO()
+    with tarfile.open(fileobj=buffer, mode="w:gz") as archive:
+        for name, text in files.items():
+            data = text.encode()
+            info = tarfile.TarInfo(name)
+            info.size = len(data)
+            archive.addfile(info, io.BytesIO(data))
+    return buffer.getvalue()
+
+
+class _HtmlIndex:
+    """A PEP 503 index page for ``billing`` with one sdist per version."""
+
+    def __init__(self, sdist: bytes) -> None:
+        self.sdist = sdist
+        self.urls: list[str] = []
+
+    def get(self, url, headers=None):
+        self.urls.append(url)
+        request = httpx.Request("GET", url)
+        if url == f"{INDEX}/billing/":
+            digest = hashlib.sha256(self.sdist).hexdigest()
+            page = (
+                '<a href="../../f/billing-1.2.0.tar.gz#sha256='
+                f'{digest}">billing-1.2.0.tar.gz</a>\n'
+                '<a href="../../f/billing-1.10.0.tar.gz" data-yanked="">'
+                "billing-1.10.0.tar.gz</a>\n"
+            )
+            return httpx.Response(
+                200,
+                request=request,
+                text=page,
+                headers={"content-type": "text/html"},
+            )
+        if url.endswith("/f/billing-1.2.0.tar.gz"):
+            return httpx.Response(200, request=request, content=self.sdist)
+        return httpx.Response(404, request=request)
+
+
+class TestIndexRelease:
+    """Tests for :class:`services.package_index.IndexRelease`."""
+
+    @pytest.mark.unit
+    def test_sdist_from_html_index(self, tmp_path: Path):
+        client = _HtmlIndex(_sdist())
+        release = IndexRelease(INDEX, "billing == 1.2.0", tmp_path, client)
+        root = release.root()
+        assert root == tmp_path / "billing-1.2.0" / "billing-1.2.0" / "src"
+        assert (root / "billing" / "invoice.py").is_file()
+        assert (release.project_dir / "autodoc.yaml").is_file()
+        # Unpacked releases are reused; yanked ones are not the newest.
+        again = IndexRelease(INDEX, "billing", tmp_path, client)
+        assert again.root() == root
+        assert again.version == "1.2.0"
+        assert sum(url.endswith(".tar.gz") for url in client.urls) == 1
+
+    @pytest.mark.unit
+    def test_index_url_and_missing_files(self, tmp_path: Path):
+        assert index_url(None, {}) == DEFAULT_INDEX
+        assert index_url(None, {"PIP_INDEX_URL": INDEX}) == INDEX
+        assert index_url("https://other/simple", {"PIP_INDEX_URL": INDEX}) != INDEX
+        # 1.10.0 is listed, but its file is missing.
+        release = IndexRelease(INDEX, "billing==1.10.0", tmp_path, _HtmlIndex(b""))
+        with pytest.raises(PackageIndexError, match="Cannot download"):
+            release.root()
+        assert not any(tmp_path.iterdir())
+
+
+class TestGenerateRelease:
+    """Tests for ``autodoc generate NAME==VERSION``."""
+
+    @pytest.mark.unit
+    def test_documents_the_release(self, tmp_path: Path, monkeypatch):
+        client = _HtmlIndex(_sdist())
+        monkeypatch.setattr(IndexRelease, "_http", lambda self: client)
+        output = tmp_path / "site"
+        argv = [
+            "generate",
+            "billing==1.2.0",
+            "--index",
+            INDEX,
+            "--cache",
+            str(tmp_path / "cache"),
+            "--output",
+            str(output),
+            "--format",
+            "html",
+        ]
+        assert run_command(argv) == 0
+        index = (output / "index.html").read_text(encoding="utf-8")
+        assert "<title>Billing</title>" in index
+        assert "billing.invoice" in (output / "billing.html").read_text(
+            encoding="utf-8",
+        )
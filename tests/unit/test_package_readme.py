@@ -0,0 +1,90 @@
+"""Unit tests for package READMEs merged into the package pages."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.package_readme import demote_headings
+
+README = (
+    "# Shop\n\n"
+    "The **storefront**.\n\n"
+    "![Checkout flow](docs/flow.png)\n\n"
+    "## Usage\n\n"
+    "- pay with [payments](../payments/)\n"
+    "- read [the notes](docs/notes.txt)\n"
+    "- see [elsewhere](../../outside.md)\n\n"
+    "```python\n"
+    "# Not a heading\n"
+    "![kept](docs/missing.png)\n"
+    "```\n"
+)
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """``shop`` with a README, images and notes, and a ``payments`` package."""
+    for name in ("shop", "payments"):
+        (tmp_path / name).mkdir()
+        (tmp_path / name / "__init__.py").write_text(
+            f'"""{name.capitalize()}."""\n\n\ndef usage() -> None:\n    pass\n',
+            encoding="utf-8",
+        )
+    (tmp_path / "shop" / "README.md").write_text(README, encoding="utf-8")
+    (tmp_path / "shop" / "docs").mkdir()
+    (tmp_path / "shop" / "docs" / "flow.png").write_bytes(b"\x89PNG\r\n\x1a\nfake")
+    (tmp_path / "shop" / "docs" / "notes.txt").write_text("Notes.\n", encoding="utf-8")
+    return tmp_path
+
+
+def test_readme_merged_into_package_pages(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed), formats, ProjectConfig())
+
+    markdown = {page.path: page.content for page in sites["markdown"]}
+    shop = markdown["shop.md"]
+    assert shop.index("## Shop") < shop.index("## Contents") < shop.index("## `shop`")
+    assert "### Usage" in shop
+    assert "![Checkout flow](assets/images/shop/docs/flow.png)" in shop
+    assert "[payments](payments.md)" in shop
+    assert "[the notes](assets/files/shop/docs/notes.txt)" in shop
+    assert "[elsewhere](../../outside.md)" in shop
+    assert "# Not a heading\n![kept](docs/missing.png)" in shop
+    # The README's headings come before the symbols, which keep unique anchors.
+    assert "- [`shop`](#shop-2)" in shop
+    assert "assets/images/shop/docs/flow.png" in markdown
+    assert markdown["assets/files/shop/docs/notes.txt"] == b"Notes.\n"
+
+    html = {page.path: page.content for page in sites["html"]}
+    assert "<h2>Shop</h2>\n<p>The <strong>storefront</strong>.</p>" in html["shop.html"]
+    assert '<a href="payments.html">payments</a>' in html["shop.html"]
+    assert '<img src="assets/images/shop/docs/flow.png" alt="Checkout flow">' in (
+        html["shop.html"]
+    )
+    index = {page.path: page.content for page in sites["json"]}["index.json"]
+    packages = {p["name"]: p for p in json.loads(index)["packages"]}
+    assert packages["shop"]["readme"].startswith("# Shop")
+    assert packages["payments"]["readme"] is None
+
+    off = ProjectConfig(site=SiteConfig.from_dict({"readmes": False}))
+    markdown = render_site(parsed, build_model(parsed, off), ("markdown",), off)
+    shop = {page.path: page.content for page in markdown["markdown"]}["shop.md"]
+    assert "storefront" not in shop
+    with pytest.raises(ProjectConfigError, match="site.readmes"):
+        SiteConfig.from_dict({"readmes": "no"})
+
+
+def test_demote_headings() -> None:
+    assert demote_headings("# A\n\n### B\n\n## C\n\n###### D") == (
+        "## A\n\n### B\n\n### C\n\n#### D"
+    )
+    assert demote_headings("## A ##\n```\n# code\n```") == "## A\n```\n# code\n```"
+    assert demote_headings("no headings") == "no headings"
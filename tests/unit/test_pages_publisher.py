@@ -0,0 +1,136 @@
+"""Unit tests for publishing sites to GitHub Pages branches."""
+
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.bucket_publisher import PublishError
+from services.pages_publisher import PagesTarget, publish_to_branch
+
+
+def _git(repo: Path, *args: str) -> str:
+    return subprocess.run(
+        ["git", *args],
+        cwd=repo,
+        check=True,
+        capture_output=True,
+        text=True,
+    ).stdout
+
+
+@pytest.fixture
+def repo(tmp_path: Path) -> Path:
+    """A repository on ``main`` with a generated site next to it."""
+    repo = tmp_path / "repo"
+    repo.mkdir()
+    _git(repo, "init", "-q", "-b", "main")
+    _git(repo, "config", "user.email", "dev@example.com")
+    _git(repo, "config", "user.name", "Dev")
+    (repo / "shop.py").write_text('"""Shop."""\n', encoding="utf-8")
+    _git(repo, "add", ".")
+    _git(repo, "commit", "-q", "-m", "source")
+    return repo
+
+
+@pytest.fixture
+def site(tmp_path: Path) -> Path:
+    directory = tmp_path / "site"
+    (directory / "assets").mkdir(parents=True)
+    (directory / "index.html").write_text("<h1>Index</h1>", encoding="utf-8")
+    (directory / "assets" / "autodoc.css").write_text("body{}", encoding="utf-8")
+    (directory / ".autodoc-files").write_text("index.html\n", encoding="utf-8")
+    return directory
+
+
+def _files(repo: Path, branch: str) -> list[str]:
+    return _git(repo, "ls-tree", "-r", "--name-only", branch).split()
+
+
+class TestPublishToBranch:
+    """Tests for :func:`services.pages_publisher.publish_to_branch`."""
+
+    @pytest.mark.unit
+    def test_bootstraps_an_orphan_branch(self, repo, site):
+        result = publish_to_branch(site, PagesTarget("gh-pages"), repo)
+        assert result.plan.counts()["create"] == 3
+        assert _files(repo, "gh-pages") == [
+            ".nojekyll",
+            "assets/autodoc.css",
+            "index.html",
+        ]
+        head = _git(repo, "rev-parse", "--short", "main").strip()
+        assert _git(repo, "log", "--format=%P %s", "gh-pages") == (
+            f" Update documentation for {head}\n"
+        )
+        # Neither the working tree nor the index were touched.
+        assert _git(repo, "status", "--porcelain") == ""
+
+    @pytest.mark.unit
+    def test_one_commit_per_run(self, repo, site):
+        target = PagesTarget("gh-pages")
+        first = publish_to_branch(site, target, repo).commit
+        assert publish_to_branch(site, target, repo).commit is None
+        (site / "index.html").write_text("<h1>New</h1>", encoding="utf-8")
+        (site / "assets" / "autodoc.css").unlink()
+        result = publish_to_branch(site, target, repo, message="Docs for {branch}")
+        assert result.plan.counts() == {
+            "create": 0,
+            "modify": 1,
+            "delete": 1,
+            "unchanged": 1,
+        }
+        assert _git(repo, "log", "--format=%P %s", "gh-pages") == (
+            f"{first} Docs for gh-pages\n Update documentation for "
+            f"{_git(repo, 'rev-parse', '--short', 'main').strip()}\n"
+        )
+        squashed = publish_to_branch(site, target, repo, single_commit=True)
+        assert _git(repo, "rev-list", "--count", squashed.commit).strip() == "1"
+
+    @pytest.mark.unit
+    def test_folder_of_another_branch(self, repo, site):
+        _git(repo, "branch", "pages")
+        target = PagesTarget.parse("git:pages:docs/")
+        publish_to_branch(site, target, repo)
+        assert _files(repo, "pages") == [
+            "docs/.nojekyll",
+            "docs/assets/autodoc.css",
+            "docs/index.html",
+            "shop.py",
+        ]
+        with pytest.raises(PublishError, match="main is checked out"):
+            publish_to_branch(site, PagesTarget("main", "docs"), repo)
+
+    @pytest.mark.unit
+    def test_dry_run_and_errors(self, repo, site, tmp_path):
+        result = publish_to_branch(site, PagesTarget("gh-pages"), repo, dry_run=True)
+        assert result.plan.lines(PagesTarget("gh-pages").url)[0] == (
+            "would create gh-pages:.nojekyll"
+        )
+        assert _git(repo, "branch", "--list", "gh-pages") == ""
+        with pytest.raises(PublishError, match="not a branch"):
+            PagesTarget.parse("git:")
+        with pytest.raises(PublishError, match="is not valid"):
+            publish_to_branch(site, PagesTarget("gh-pages"), repo, message="{sha}")
+        with pytest.raises(PublishError, match="not a generated site"):
+            publish_to_branch(tmp_path, PagesTarget("gh-pages"), repo)
+
+
+class TestPublishCommand:
+    """Tests for ``autodoc publish git:<branch>``."""
+
+    @pytest.mark.unit
+    def test_publish_and_push(self, repo, site, tmp_path, capsys):
+        remote = tmp_path / "remote.git"
+        _git(tmp_path, "init", "-q", "--bare", str(remote))
+        argv = ["publish", "git:gh-pages", "--site", str(site), "--repo", str(repo)]
+        assert run_command([*argv, "--push", str(remote)]) == 0
+        assert capsys.readouterr().out.startswith(
+            "Published to gh-pages: 3 created, 0 modified, 0 deleted, 0 unchanged",
+        )
+        assert _files(remote, "gh-pages") == _files(repo, "gh-pages")
+        assert run_command(argv) == 0
+        assert capsys.readouterr().out == (
+            "gh-pages is up to date; nothing was committed\n"
+        )
@@ -0,0 +1,87 @@
+"""Unit tests for placeholder summaries of undocumented symbols."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.placeholder_summaries import placeholder_summary
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with documented and undocumented symbols."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "users.py").write_text(
+        "class UserStore:\n"
+        "    def get_user(self, username: str) -> 'User':\n"
+        "        pass\n\n"
+        "    def is_empty(self) -> bool:\n"
+        "        pass\n\n"
+        "    def list_users(self, team, role):\n"
+        "        pass\n\n\n"
+        "def get(key) -> 'Record':\n"
+        "    pass\n\n\n"
+        "def frobnicate(items, depth, order) -> int:\n"
+        "    pass\n\n\n"
+        "def total(items):\n"
+        '    """Sum of ``items``."""\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_placeholder_summary(tree: Path) -> None:
+    symbols = {s.qualified_name: s for s in parse_tree(tree).symbols}
+    summaries = {
+        name: placeholder_summary(symbol) for name, symbol in symbols.items()
+    }
+    assert summaries == {
+        "shop": None,
+        "shop.users": "The ``users`` module.",
+        "shop.users.UserStore": "A user store.",
+        "shop.users.UserStore.get_user": "Retrieves a user by ``username``.",
+        "shop.users.UserStore.is_empty": "Whether it is empty.",
+        "shop.users.UserStore.list_users": "Lists users by ``team`` and ``role``.",
+        "shop.users.get": "Retrieves a record by ``key``.",
+        "shop.users.frobnicate": (
+            "Frobnicate. Takes ``items``, ``depth``, and ``order``. "
+            "Returns ``int``."
+        ),
+        "shop.users.total": None,
+    }
+
+
+def test_placeholders_rendered(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig(site=SiteConfig.from_dict({"placeholder_summaries": True}))
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed, config), formats, config)
+
+    markdown = {page.path: page.content for page in sites["markdown"]}["shop.md"]
+    assert "_Auto-generated summary:_ Retrieves a user by ``username``." in markdown
+    assert "Sum of ``items``." in markdown
+    assert "No documentation" not in markdown
+    html = {page.path: page.content for page in sites["html"]}["shop.html"]
+    assert (
+        '<p class="autodoc-undocumented autodoc-generated">Auto-generated '
+        "summary: A user store.</p>"
+    ) in html
+    index = {page.path: page.content for page in sites["json"]}["index.json"]
+    [package] = json.loads(index)["packages"]
+    assert {"symbol": "shop.users.UserStore", "summary": "A user store."} in (
+        package["placeholders"]
+    )
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    page = {page.path: page.content for page in default}["shop.md"]
+    assert "Auto-generated" not in page
+    with pytest.raises(ProjectConfigError, match="site.placeholder_summaries"):
+        SiteConfig.from_dict({"placeholder_summaries": "yes"})
@@ -0,0 +1,109 @@
+"""Unit tests for the platform support matrix."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.platform_support import find_platform_support
+
+KEYS = '''"""Reading keys."""
+
+import os
+import sys
+from sys import platform
+
+if sys.platform == "win32":
+
+    def beep():
+        """Beep."""
+
+    def read_key():
+        """Read a key with msvcrt."""
+
+else:
+
+    def read_key():
+        """Read a key with termios."""
+
+if os.name == "posix" and not platform.startswith("linux"):
+    OPENER = "open"
+
+if check_platform():
+    UNKNOWN = 1
+'''
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """``shop.console`` with Windows and POSIX code, and a portable ``shop``."""
+    console = tmp_path / "shop" / "console"
+    console.mkdir(parents=True)
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (console / "__init__.py").write_text('"""Console."""\n', encoding="utf-8")
+    (console / "keys.py").write_text(KEYS, encoding="utf-8")
+    (console / "windows_events.py").write_text('"""Events."""\n', encoding="utf-8")
+    (console / "locking.py").write_text(
+        '"""Locks."""\n\ntry:\n    import msvcrt\nexcept ImportError:\n    pass\n'
+        "import fcntl\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_find_platform_support(tree: Path) -> None:
+    shop, console = find_platform_support(tree, parse_tree(tree).graph)
+    assert (shop.package, shop.restrictions) == ("shop", ())
+    assert [
+        (r.name, r.platforms, r.source, r.detail, r.lineno)
+        for r in console.restrictions
+    ] == [
+        ("shop.console.keys.beep", ("windows",), "guard", "sys.platform == 'win32'", 9),
+        (
+            "shop.console.keys.OPENER",
+            ("macos",),
+            "guard",
+            "os.name == 'posix' and (not platform.startswith('linux'))",
+            21,
+        ),
+        ("shop.console.locking", ("linux", "macos"), "import", "fcntl", 7),
+        ("shop.console.windows_events", ("windows",), "name", "windows", 1),
+    ]
+    assert [console.support(p) for p in ("linux", "macos", "windows")] == [
+        "partial",
+        "partial",
+        "partial",
+    ]
+
+
+def test_platforms_page(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig.from_dict({"site": {"platforms": True}})
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed, config), formats, config)
+
+    markdown = {page.path: page.content for page in sites["markdown"]}
+    assert "[Platform support](platforms.md)" in markdown["index.md"]
+    page = markdown["platforms.md"]
+    assert "| Package | Linux | macOS | Windows |" in page
+    assert "| shop | yes | yes | yes |" in page
+    assert (
+        "| [`shop.console.keys.beep`](shop.console.md#beep) | no | no | yes "
+        "| defined under `sys.platform == 'win32'` (`shop/console/keys.py:9`) |"
+    ) in page
+    html = {page.path: page.content for page in sites["html"]}["platforms.html"]
+    assert "<td>imports <code>fcntl</code>" in html
+    index = {page.path: page.content for page in sites["json"]}["index.json"]
+    support = json.loads(index)["platforms"][1]["support"]
+    assert support == {"linux": "partial", "macos": "partial", "windows": "partial"}
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    assert "platforms.md" not in {page.path for page in default}
+    with pytest.raises(ProjectConfigError, match="site.platforms"):
+        SiteConfig.from_dict({"platforms": "all"})
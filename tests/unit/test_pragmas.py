@@ -0,0 +1,107 @@
+"""Unit tests for reporting tool pragmas on the symbols they apply to."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfig, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_markdown import render_markdown_site
+from services.import_graph import build_import_graph
+from services.pragmas import find_pragmas, parse_pragmas
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with pragmas on headers, in bodies, and file-wide."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(
+        "# mypy: disallow-untyped-defs\n"
+        '"""Carts."""\n'
+        "import functools\n\n\n"
+        "def total(cart):  # noqa: C901\n"
+        '    """Total of the cart."""\n'
+        "    return sum(cart)  # type: ignore[arg-type]\n\n\n"
+        "# pylint: disable-next=protected-access\n"
+        "@functools.cache  # pragma: no cover\n"
+        "def peek(\n"
+        "    cart,  # noqa: SLF001\n"
+        "):\n"
+        '    """Peek inside."""\n'
+        "    return cart._items\n\n\n"
+        "class Cart:  # fmt: skip\n"
+        '    """A cart."""\n\n'
+        "    def add(self, item):  # pyright: ignore[reportPrivateUsage]\n"
+        '        """Add an item."""\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_parse_pragmas() -> None:
+    assert parse_pragmas("# type: ignore[attr-defined]  # noqa: E501,F401") == [
+        ("type: ignore", "type: ignore[attr-defined]", ("attr-defined",)),
+        ("noqa", "noqa: E501,F401", ("E501", "F401")),
+    ]
+    assert parse_pragmas("#pylint:disable=protected-access") == [
+        (
+            "pylint: disable",
+            "pylint:disable=protected-access",
+            ("protected-access",),
+        ),
+    ]
+    assert parse_pragmas("# nosec B101 - trusted input") == [
+        ("nosec", "nosec B101 - trusted input", ("B101",)),
+    ]
+    assert parse_pragmas("# the type is ignored here") == []
+
+
+def test_pragmas_belong_to_the_symbols_they_decorate(tree: Path) -> None:
+    pragmas = find_pragmas(tree, build_import_graph(tree))
+
+    assert [(p.symbol, p.lineno, p.kind, p.private_access) for p in pragmas] == [
+        ("shop.cart", 1, "mypy", False),
+        ("shop.cart.total", 6, "noqa", False),
+        ("shop.cart.peek", 11, "pylint: disable-next", True),
+        ("shop.cart.peek", 12, "pragma: no cover", False),
+        ("shop.cart.peek", 14, "noqa", True),
+        ("shop.cart.Cart", 20, "fmt: skip", False),
+        ("shop.cart.Cart.add", 23, "pyright: ignore", True),
+    ]
+    assert pragmas[0].to_dict()["codes"] == ["disallow-untyped-defs"]
+
+
+def test_pages_list_pragmas(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    pages = render_markdown_site(build_model(parsed), SiteConfig())
+    page = {p.path: p.content for p in pages}["shop.md"]
+    assert (
+        "Pragmas: `pylint: disable-next=protected-access` (private access), "
+        "`pragma: no cover`, `noqa: SLF001` (private access)\n"
+    ) in page
+
+    config = ProjectConfig(site=SiteConfig.from_dict({"pragmas": False}))
+    assert build_model(parsed, config)[0].pragmas == {}
+
+
+def test_pragmas_command(tree: Path, capsys) -> None:
+    assert run_command(["pragmas", "--root", str(tree)]) == 0
+    out = capsys.readouterr().out
+    assert out.startswith(
+        "Maintenance risks (private access):\n"
+        "  shop/cart.py:11: shop.cart.peek - pylint: disable-next=protected-access\n",
+    )
+    assert out.endswith("7 pragma(s) on 5 symbol(s), 3 hiding private access\n")
+
+    assert run_command(["pragmas", "--root", str(tree), "--check"]) == 1
+    capsys.readouterr()
+    run_command(["pragmas", "--root", str(tree), "--format", "json"])
+    data = json.loads(capsys.readouterr().out)
+    assert data["private_access"] == 3
+    assert data["pragmas"][1]["text"] == "noqa: C901"
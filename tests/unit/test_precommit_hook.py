@@ -0,0 +1,90 @@
+"""Unit tests for the staged-files pre-commit documentation check."""
+
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from services.precommit_hook import check_staged
+
+
+def _git(repo: Path, *args: str) -> None:
+    subprocess.run(["git", *args], cwd=repo, check=True, capture_output=True)
+
+
+@pytest.fixture
+def repo(tmp_path: Path) -> Path:
+    """A git repository with one committed module carrying existing debt."""
+    _git(tmp_path, "init", "-q")
+    _git(tmp_path, "config", "user.email", "dev@example.com")
+    _git(tmp_path, "config", "user.name", "Dev")
+    (tmp_path / "app.py").write_text(
+        '"""App module."""\n\ndef legacy():\n    pass\n',
+        encoding="utf-8",
+    )
+    _git(tmp_path, "add", "app.py")
+    _git(tmp_path, "commit", "-q", "-m", "initial")
+    return tmp_path
+
+
+class TestCheckStaged:
+    """Tests for check_staged."""
+
+    @pytest.mark.unit
+    def test_existing_debt_does_not_block(self, repo):
+        (repo / "app.py").write_text(
+            '"""App module."""\n\ndef legacy():\n    pass\n\n'
+            'def helper():\n    """Help."""\n',
+            encoding="utf-8",
+        )
+        _git(repo, "add", "app.py")
+
+        result = check_staged(repo)
+
+        assert result.files_checked == ["app.py"]
+        assert result.passed
+
+    @pytest.mark.unit
+    def test_new_undocumented_symbol_blocks(self, repo):
+        (repo / "app.py").write_text(
+            '"""App module."""\n\ndef legacy():\n    pass\n\n'
+            "def added():\n    pass\n",
+            encoding="utf-8",
+        )
+        _git(repo, "add", "app.py")
+
+        result = check_staged(repo)
+
+        assert not result.passed
+        assert [f.symbol for f in result.findings] == ["app.added"]
+
+    @pytest.mark.unit
+    def test_checks_staged_blob_not_working_tree(self, repo):
+        (repo / "new.py").write_text("def undocumented():\n    pass\n", encoding="utf-8")
+        _git(repo, "add", "new.py")
+        (repo / "new.py").write_text(
+            '"""Fixed."""\n\ndef undocumented():\n    """Now documented."""\n',
+            encoding="utf-8",
+        )
+
+        result = check_staged(repo)
+
+        assert {f.symbol for f in result.findings} == {"new", "new.undocumented"}
+
+    @pytest.mark.unit
+    def test_nothing_staged(self, repo):
+        result = check_staged(repo)
+        assert result.files_checked == []
+        assert result.passed
+
+    @pytest.mark.unit
+    def test_ignored_files_are_skipped(self, repo):
+        (repo / ".autodocignore").write_text("gen/\n", encoding="utf-8")
+        (repo / "gen").mkdir()
+        (repo / "gen" / "api.py").write_text("def raw():\n    pass\n", encoding="utf-8")
+        _git(repo, "add", ".")
+
+        result = check_staged(repo)
+
+        assert result.files_checked == []
+        assert result.passed
@@ -0,0 +1,116 @@
+"""Unit tests for progress events."""
+
+from __future__ import annotations
+
+import io
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.progress import (
+    ANALYZE,
+    DISCOVER,
+    PARSE,
+    RENDER,
+    STAGES,
+    Progress,
+    ProgressEvent,
+    json_listener,
+)
+
+
+class _Clock:
+    def __init__(self) -> None:
+        self.now = 0.0
+
+    def __call__(self) -> float:
+        return self.now
+
+
+def test_counts_and_eta() -> None:
+    events: list[ProgressEvent] = []
+    clock = _Clock()
+    progress = Progress(events.append, interval=1.0, clock=clock)
+    progress.start(PARSE, 10)
+    clock.now = 0.5
+    progress.advance(PARSE)
+    assert len(events) == 1
+    clock.now = 2.0
+    progress.advance(PARSE, 3)
+    progress.finish(PARSE)
+    assert [(e.done, e.total, e.finished) for e in events] == [
+        (0, 10, False),
+        (4, 10, False),
+        (4, 10, True),
+    ]
+    assert events[0].eta is None
+    assert events[1].eta == pytest.approx(3.0)
+    assert events[2].eta == 0.0
+
+    progress.complete(DISCOVER, 7)
+    assert events[-1].to_dict()["done"] == 7
+    assert events[-1].finished
+
+    stream = io.StringIO()
+    json_listener(stream)(events[1])
+    assert json.loads(stream.getvalue()) == {
+        "schema_version": 1,
+        "event": "progress",
+        "stage": "parse",
+        "done": 4,
+        "total": 10,
+        "elapsed": 2.0,
+        "eta": 3.0,
+        "finished": False,
+    }
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    root = tmp_path / "tree"
+    for name in ("shop", "billing"):
+        (root / name).mkdir(parents=True)
+        (root / name / "__init__.py").write_text(f'"""{name}."""\n', encoding="utf-8")
+    (root / "shop" / "cart.py").write_text('"""Carts."""\n', encoding="utf-8")
+    return root
+
+
+def test_library_stages(tree: Path) -> None:
+    events: list[ProgressEvent] = []
+    progress = Progress(events.append)
+    parsed = parse_tree(tree, progress=progress)
+    render_site(parsed, build_model(parsed), ["markdown", "json"], progress=progress)
+    finished = {e.stage: e for e in events if e.finished}
+    assert list(finished) == [DISCOVER, PARSE, ANALYZE, RENDER]
+    assert finished[DISCOVER].done == 3
+    assert finished[PARSE].done == finished[ANALYZE].done == 3
+    # Two package pages of Markdown, and the JSON site as one.
+    assert (finished[RENDER].done, finished[RENDER].total) == (3, 3)
+
+
+def _events(err: str) -> list[dict]:
+    return [json.loads(line) for line in err.splitlines() if line.startswith("{")]
+
+
+def test_generate_progress_json(tree: Path, tmp_path: Path, capsys) -> None:
+    argv = ["generate", "--root", str(tree), "--output", str(tmp_path / "site")]
+    assert run_command([*argv, "--progress", "json"]) == 0
+    captured = capsys.readouterr()
+    assert "Wrote" in captured.out and '"event"' not in captured.out
+    events = _events(captured.err)
+    assert {event["stage"] for event in events} == set(STAGES)
+    [published] = [e for e in events if e["stage"] == "publish" and e["finished"]]
+    written = (tmp_path / "site" / ".autodoc-files").read_text().splitlines()
+    assert published["done"] == published["total"] == len(written)
+
+    incremental = [*argv, "--incremental", "--progress", "json"]
+    assert run_command(incremental) == 0
+    assert run_command(incremental) == 0
+    events = _events(capsys.readouterr().err)
+    parses = [e for e in events if e["stage"] == "parse" and e["finished"]]
+    assert parses[-1]["total"] == 0
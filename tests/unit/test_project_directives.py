@@ -0,0 +1,96 @@
+"""Unit tests for replaced dependencies and retracted versions."""
+
+from __future__ import annotations
+
+import json
+import logging
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.project_directives import Replacement, read_directives
+from services.semver import Version
+
+PYPROJECT = """\
+[project]
+name = "shop"
+version = "1.3.1"
+dependencies = ["httpx", "toolkit @ git+https://example.com/toolkit.git@v2"]
+
+[tool.uv.sources]
+httpx = { git = "https://github.com/encode/httpx", tag = "0.28.0" }
+shared = { workspace = true }
+models = [{ path = "../models" }, { index = "internal" }]
+
+[tool.autodoc]
+retract = [
+    "1.2.0",
+    { versions = "[1.3.0, 1.3.2]", rationale = "Corrupts the cache." },
+    "next",
+]
+"""
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package at retracted version 1.3.1 with replaced sources."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "pyproject.toml").write_text(PYPROJECT, encoding="utf-8")
+    (tmp_path / "requirements-dev.txt").write_text(
+        "-e ./plugins/shop-plugin  # local\n"
+        "-e git+https://example.com/lint.git#egg=shoplint\n"
+        "rich==13.7.1\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_read_directives(tree: Path, caplog: pytest.LogCaptureFixture) -> None:
+    with caplog.at_level(logging.WARNING):
+        directives = read_directives(tree)
+    assert directives.replacements == (
+        Replacement("httpx", "git", "https://github.com/encode/httpx@0.28.0"),
+        Replacement("shared", "workspace", "workspace member"),
+        Replacement("models", "path", "../models"),
+        Replacement("models", "index", "internal"),
+        Replacement("shop-plugin", "editable", "./plugins/shop-plugin"),
+        Replacement("shoplint", "editable", "git+https://example.com/lint.git"),
+        Replacement("toolkit", "git", "git+https://example.com/toolkit.git@v2"),
+    )
+    assert [r.label() for r in directives.retractions] == ["1.2.0", "1.3.0 - 1.3.2"]
+    assert directives.retracted() == directives.retractions[1]
+    assert directives.retractions[1].covers(Version(1, 3, 2))
+    assert "Ignoring retraction 'next'" in caplog.text
+    assert "Documenting retracted version 1.3.1: Corrupts the cache." in caplog.text
+    assert not read_directives(tree / "shop")
+
+
+def test_directives_on_the_index(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig.from_dict({"site": {"directives": True}})
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed, config), formats, config)
+
+    index = sites["markdown"][0].content
+    assert index.startswith(
+        "# API Reference\n\n"
+        "> **Warning:** version 1.3.1 is retracted: Corrupts the cache.\n",
+    )
+    assert "| httpx | git | `https://github.com/encode/httpx@0.28.0` |" in index
+    assert "## Retracted versions\n\nThese releases should not be used.\n\n" in index
+    assert "- `1.2.0`\n- `1.3.0 - 1.3.2`: Corrupts the cache." in index
+    html = sites["html"][0].content
+    assert '<p class="autodoc-retracted" role="alert">' in html
+    assert "<li><code>1.3.0 - 1.3.2</code>: Corrupts the cache.</li>" in html
+    data = json.loads(sites["json"][0].content)
+    assert data["directives"]["retracted"]["low"] == "1.3.0"
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    assert "Retracted" not in default[0].content
+    with pytest.raises(ProjectConfigError, match="site.directives"):
+        SiteConfig.from_dict({"directives": "yes"})
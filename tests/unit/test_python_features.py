@@ -0,0 +1,159 @@
+"""Unit tests for the minimum Python version and newer syntax report."""
+
+from __future__ import annotations
+
+import json
+import sys
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.python_features import (
+    find_language_features,
+    minimum_python,
+    python_version_report,
+)
+
+CART = '''"""Carts."""
+
+
+def total(items, /, tax=0):
+    """Sum of the items plus ``tax``."""
+    if (count := len(items)) == 0:
+        return tax
+    match items:
+        case [first, *rest]:
+            return first + total(rest, tax=tax)
+    return count
+
+
+@registry["carts"].register
+class Cart:
+    """A cart."""
+
+    def check(self):
+        try:
+            self.validate()
+        except* ValueError:
+            pass
+'''
+# Syntax of Python 3.12, which the 3.11 the project runs on cannot parse.
+BOX = '''"""Boxes."""
+
+
+class Box[T]:
+    """A box of ``T``."""
+
+
+type Items = list[int]
+'''
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """``shop.cart`` using 3.8 to 3.11 syntax in a project requiring 3.9."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(CART, encoding="utf-8")
+    (tmp_path / "pyproject.toml").write_text(
+        '[project]\nname = "shop"\nrequires-python = ">=3.9, <4"\n',
+        encoding="utf-8",
+    )
+    (tmp_path / ".python-version").write_text("3.12.4\n", encoding="utf-8")
+    return tmp_path
+
+
+def test_find_language_features(tree: Path) -> None:
+    usages = find_language_features(tree, parse_tree(tree).graph)
+    assert [(usage.feature, usage.lineno) for usage in usages] == [
+        ("positional-only", 4),
+        ("walrus", 6),
+        ("match", 8),
+        ("decorator-expression", 15),
+        ("except-star", 19),
+    ]
+    assert usages[0].file_path == "shop/cart.py"
+
+
+@pytest.mark.skipif(sys.version_info < (3, 12), reason="needs Python 3.12 syntax")
+def test_type_parameters_and_aliases(tree: Path) -> None:
+    (tree / "shop" / "box.py").write_text(BOX, encoding="utf-8")
+    usages = find_language_features(tree, parse_tree(tree).graph)
+    assert [(u.feature, u.lineno) for u in usages if u.module == "shop.box"] == [
+        ("type-parameters", 4),
+        ("type-alias", 8),
+    ]
+    assert python_version_report(tree, tree, parse_tree(tree).graph).required == (3, 12)
+
+
+def test_unparsable_module_needs_newer_python(tree: Path) -> None:
+    future = tree / "shop" / "future.py"
+    future.write_text('"""Future."""\n\nx ?= 1\n', encoding="utf-8")
+    graph = parse_tree(tree).graph
+    usages = find_language_features(tree, graph)
+    [usage] = [u for u in usages if u.module == "shop.future"]
+    assert (usage.feature, usage.file_path, usage.lineno) == (
+        "newer-python",
+        "shop/future.py",
+        3,
+    )
+    assert usage.version == (3, sys.version_info.minor + 1)
+    report = python_version_report(tree, tree, graph)
+    assert report.required == usage.version
+    assert usage in report.newer()
+
+
+def test_report(tree: Path) -> None:
+    report = python_version_report(tree, tree, parse_tree(tree).graph)
+    assert report.minimum == (3, 9)
+    assert report.required == (3, 11)
+    assert [usage.feature for usage in report.newer()] == ["match", "except-star"]
+    assert report.sentence(str) == (
+        "Requires Python 3.9 or newer (developed with 3.12, see .python-version)."
+    )
+    assert minimum_python(tree / "shop") is None
+
+
+def test_syntax_command(tree: Path, capsys: pytest.CaptureFixture[str]) -> None:
+    assert run_command(["syntax", "--root", str(tree)]) == 0
+    out = capsys.readouterr().out
+    assert "shop/cart.py:8: match statements need Python 3.10\n" in out
+    assert out.endswith("2 use(s) of newer syntax\n")
+    assert run_command(["syntax", "--root", str(tree), "--check"]) == 1
+    capsys.readouterr()
+
+    assert run_command(["syntax", "--root", str(tree), "--format", "json"]) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert (data["minimum"], data["toolchain"], data["required"]) == (
+        "3.9",
+        "3.12",
+        "3.11",
+    )
+
+
+def test_minimum_on_the_index(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig.from_dict({"site": {"python_version": True}})
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed, config), formats, config)
+
+    index = sites["markdown"][0].content
+    assert index.startswith(
+        "# API Reference\n\n"
+        "Requires Python 3.9 or newer (developed with 3.12, see `.python-version`).\n",
+    )
+    assert "## Syntax newer than the minimum Python" in index
+    assert "| match statements | 3.10 | `shop/cart.py:8` |" in index
+    assert '<table class="autodoc-newer-syntax">' in sites["html"][0].content
+    data = json.loads(sites["json"][0].content)
+    assert len(data["python_version"]["newer"]) == 2
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    assert "Requires Python" not in default[0].content
+    with pytest.raises(ProjectConfigError, match="site.python_version"):
+        SiteConfig.from_dict({"python_version": "3.9"})
@@ -0,0 +1,159 @@
+"""Unit tests for the quickstart snippets of package pages."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.quickstart import Quickstart, placeholder, verify_quickstart
+
+
+def _write(root: Path, files: dict[str, str]) -> Path:
+    for name, source in files.items():
+        path = root / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(source, encoding="utf-8")
+    return root
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package whose main class is built through its ``__init__``."""
+    return _write(
+        tmp_path,
+        {
+            "shop/__init__.py": '"""Shop."""\n',
+            "shop/cart.py": (
+                "class CartError(Exception):\n"
+                '    """Raised for invalid carts."""\n\n'
+                "    def describe(self) -> str:\n"
+                "        pass\n\n\n"
+                "class Cart:\n"
+                '    """A shopping cart."""\n\n'
+                "    def __init__(self, owner: str, *, currency: str | None, "
+                "limit: int = 3):\n"
+                "        pass\n\n"
+                "    @property\n"
+                "    def size(self) -> int:\n"
+                "        pass\n\n"
+                "    def total(self, tax: float, rounded: bool = True) -> float:\n"
+                '        """Total price."""\n\n'
+                "    def _refresh(self) -> None:\n"
+                "        pass\n"
+            ),
+        },
+    )
+
+
+def _quickstarts(root: Path) -> dict[str, Quickstart | None]:
+    config = ProjectConfig(site=SiteConfig.from_dict({"quickstart": True}))
+    packages = build_model(parse_tree(root), config)
+    return {package.name: package.quickstart for package in packages}
+
+
+def test_placeholder() -> None:
+    assert placeholder("str") == '"..."'
+    assert placeholder("list[int]") == "[]"
+    assert placeholder("typing.Mapping[str, int]") == "{}"
+    assert placeholder("'Path'") == '"."'
+    assert placeholder("int | None") == "None"
+    assert placeholder("Optional[str]") == "None"
+    assert placeholder("Cart") == "..."
+    assert placeholder(None) == "..."
+
+
+def test_constructed_through_init(tree: Path) -> None:
+    quickstart = _quickstarts(tree)["shop"]
+    assert quickstart is not None
+    assert quickstart.main_type == "shop.cart.Cart"
+    assert quickstart.constructor == "shop.cart.Cart"
+    assert quickstart.method == "shop.cart.Cart.total"
+    assert quickstart.code == (
+        "from shop.cart import Cart\n"
+        "\n"
+        'cart = Cart("...", currency=None)\n'
+        "cart.total(0.0)\n"
+    )
+
+
+def test_constructed_through_factory(tmp_path: Path) -> None:
+    root = _write(
+        tmp_path,
+        {
+            "http/__init__.py": "",
+            "http/client.py": (
+                "class HTTPClient:\n"
+                "    async def fetch(self, url: str) -> bytes:\n"
+                "        pass\n\n\n"
+                "def connect(host: str, port: int = 80) -> 'HTTPClient':\n"
+                "    pass\n"
+            ),
+        },
+    )
+    quickstart = _quickstarts(root)["http"]
+    assert quickstart is not None
+    assert quickstart.constructor == "http.client.connect"
+    assert quickstart.code == (
+        "import asyncio\n"
+        "from http.client import connect\n"
+        "\n"
+        'http_client = connect("...")\n'
+        'asyncio.run(http_client.fetch("..."))\n'
+    )
+
+
+def test_no_public_class(tmp_path: Path) -> None:
+    root = _write(tmp_path, {"util/__init__.py": "def helper():\n    pass\n"})
+    assert _quickstarts(root) == {"util": None}
+
+
+def test_verify_quickstart() -> None:
+    documented = ["shop.cart.Cart", "shop.cart.Cart.total"]
+    snippet = Quickstart(
+        package="shop",
+        main_type="shop.cart.Cart",
+        constructor="shop.cart.Cart",
+        method="shop.cart.Cart.total",
+        code="from shop.cart import Cart\n\ncart = Cart()\ncart.total()\n",
+    )
+    assert verify_quickstart(snippet, documented) == []
+    assert verify_quickstart(snippet, ["shop.cart.Cart"]) == [
+        "calls shop.cart.Cart.total, which is not documented",
+    ]
+    unknown = Quickstart("shop", "shop.cart.Cart", "shop.cart.Cart", None, "Cart()\n")
+    assert verify_quickstart(unknown, documented) == [
+        "calls Cart, which it does not import",
+    ]
+    broken = Quickstart("shop", "shop.cart.Cart", "shop.cart.Cart", None, "Cart(\n")
+    [problem] = verify_quickstart(broken, documented)
+    assert problem.startswith("does not compile")
+
+
+def test_quickstart_rendered(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig(site=SiteConfig.from_dict({"quickstart": True}))
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed, config), formats, config)
+
+    markdown = {page.path: page.content for page in sites["markdown"]}["shop.md"]
+    assert (
+        "## Quickstart\n\n```python\nfrom shop.cart import Cart\n\n"
+        'cart = Cart("...", currency=None)\ncart.total(0.0)\n```'
+    ) in markdown
+    html = {page.path: page.content for page in sites["html"]}["shop.html"]
+    assert '<section class="autodoc-quickstart">\n<h2>Quickstart</h2>' in html
+    index = {page.path: page.content for page in sites["json"]}["index.json"]
+    [package] = json.loads(index)["packages"]
+    assert package["quickstart"]["method"] == "shop.cart.Cart.total"
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    page = {page.path: page.content for page in default}["shop.md"]
+    assert "Quickstart" not in page
+    with pytest.raises(ProjectConfigError, match="site.quickstart"):
+        SiteConfig.from_dict({"quickstart": "yes"})
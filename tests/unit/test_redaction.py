@@ -0,0 +1,118 @@
+"""Unit tests for redacting sensitive identifiers from published docs."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfigError, RedactConfig, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.redaction import redact_packages, redact_tree
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with an internal subpackage and a legacy function."""
+    (tmp_path / "shop" / "internal").mkdir(parents=True)
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(
+        '"""Carts, see ACME-123."""\n\n'
+        "from shop.internal.keys import KEY\n\n"
+        "# @autodoc:faq Who owns carts?\n"
+        "# Ask on db1.corp.example.com.\n\n\n"
+        "def total():\n"
+        '    """Total price, see ACME-9."""\n\n\n'
+        "def legacy_total():\n"
+        '    """The old way."""\n',
+        encoding="utf-8",
+    )
+    (tmp_path / "shop" / "README.md").write_text(
+        "# Shop\n\nFixed in ACME-7.\n",
+        encoding="utf-8",
+    )
+    (tmp_path / "shop" / "internal" / "__init__.py").write_text("", encoding="utf-8")
+    (tmp_path / "shop" / "internal" / "keys.py").write_text(
+        '"""Signing keys."""\n\nKEY = "k"\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def _config(**data: object) -> RedactConfig:
+    return RedactConfig.from_dict(
+        {"packages": ["shop.internal"], "symbols": [r"\.legacy_"], **data},
+    )
+
+
+def test_exclude(tree: Path) -> None:
+    redacted = redact_tree(parse_tree(tree), _config())
+    names = {symbol.qualified_name for symbol in redacted.symbols}
+    assert "shop.cart.total" in names
+    assert "shop.cart.legacy_total" not in names
+    assert not any(name.startswith("shop.internal") for name in names)
+    assert "shop.internal.keys" not in redacted.graph.modules
+    assert redacted.graph.modules["shop.cart"].imports == {}
+
+    by_path = RedactConfig.from_dict({"paths": ["shop/internal/*"]})
+    names = {s.qualified_name for s in redact_tree(parse_tree(tree), by_path).symbols}
+    assert "shop.internal.keys" not in names
+    assert "shop.cart" in names
+
+
+def test_mask(tree: Path) -> None:
+    redacted = redact_tree(parse_tree(tree), _config(mode="mask", mask="(hidden)"))
+    docstrings = {s.qualified_name: s.docstring for s in redacted.symbols}
+    assert docstrings["shop.cart.legacy_total"] == "(hidden)"
+    assert docstrings["shop.internal.keys"] == "(hidden)"
+    assert docstrings["shop.cart.total"] == "Total price, see ACME-9."
+    assert "shop.internal.keys" in redacted.graph.modules
+
+
+def test_text(tree: Path) -> None:
+    config = RedactConfig.from_dict(
+        {"text": [r"ACME-\d+", r"[\w.-]+\.corp\.example\.com"]},
+    )
+    packages = build_model(redact_tree(parse_tree(tree), config))
+    [shop, _] = redact_packages(packages, config)
+    assert shop.modules[1].symbol.docstring == "Carts, see [redacted]."
+    [entry] = shop.faq
+    assert entry.answer == "Ask on [redacted]."
+    assert shop.readme is not None
+    assert "Fixed in [redacted]." in shop.readme.content
+
+
+def test_config_errors() -> None:
+    for data, message in [
+        ("yes", "site.redact must be a mapping"),
+        ({"symbols": ["("]}, "site.redact.symbols must be a list of regular"),
+        ({"text": [1]}, "site.redact.text must be a list of regular expressions"),
+        ({"packages": [1]}, "site.redact.packages must be a list of patterns"),
+        ({"mode": "drop"}, "site.redact.mode must be one of exclude, mask"),
+        ({"mask": 0}, "site.redact.mask must be a string"),
+    ]:
+        with pytest.raises(ProjectConfigError, match=message):
+            SiteConfig.from_dict({"redact": data})
+
+
+def test_generate_and_internal(tree: Path) -> None:
+    (tree / "autodoc.yaml").write_text(
+        "site:\n  redact:\n    packages: [shop.internal]\n    text: ['ACME-\\d+']\n",
+        encoding="utf-8",
+    )
+
+    def site(*extra: str) -> str:
+        output = tree / "site"
+        argv = ["generate", "--root", str(tree), "--output", str(output)]
+        assert run_command([*argv, "--format", "json", *extra]) == 0
+        return (output / "index.json").read_text(encoding="utf-8")
+
+    published = site()
+    assert "ACME-" not in published
+    assert "shop.internal" not in {p["name"] for p in json.loads(published)["packages"]}
+    internal = site("--internal")
+    assert "ACME-123" in internal
+    assert "shop.internal.keys" in internal
@@ -0,0 +1,132 @@
+"""Unit tests for the relation graph and its exports."""
+
+import csv
+import io
+import xml.etree.ElementTree as ET
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.parser import parse_tree
+from services.graph_export import render_dot, render_graphml, render_neo4j_csv
+from services.relation_graph import build_relation_graph
+
+
+@pytest.fixture
+def relations(tmp_path: Path):
+    """Relation graph of a package with every kind of edge."""
+    pkg = tmp_path / "shop"
+    pkg.mkdir()
+    (pkg / "__init__.py").write_text("", encoding="utf-8")
+    (pkg / "store.py").write_text(
+        "from typing import Protocol\n\n\n"
+        "class Store(Protocol):\n"
+        "    def get(self, key): ...\n\n\n"
+        "class Base:\n"
+        "    pass\n\n\n"
+        "class MemoryStore(Base, Store):\n"
+        "    def get(self, key):\n"
+        "        return self.lookup(key)\n\n"
+        "    def lookup(self, key):\n"
+        "        return key\n",
+        encoding="utf-8",
+    )
+    (pkg / "cart.py").write_text(
+        "import json\n\n"
+        "from shop.store import MemoryStore, Store\n\n\n"
+        "def total(items):\n"
+        "    return sum(items)\n\n\n"
+        "class Cart:\n"
+        "    store: Store\n\n"
+        "    def checkout(self):\n"
+        "        return json.dumps(total([]))\n\n\n"
+        "def make():\n"
+        "    return Cart(MemoryStore())\n",
+        encoding="utf-8",
+    )
+    tree = parse_tree(tmp_path)
+    return build_relation_graph(tree.root, tree.graph, tree.symbols)
+
+
+def _edges(graph) -> set[tuple[str, str, str]]:
+    return {(e.source, e.kind, e.target) for e in graph.edges}
+
+
+class TestRelationGraph:
+    """Tests for extracting typed edges between symbols."""
+
+    @pytest.mark.unit
+    def test_edge_kinds(self, relations):
+        assert _edges(relations) == {
+            ("shop.cart", "imports", "shop.store"),
+            ("shop.cart.Cart", "embeds", "shop.store.Store"),
+            ("shop.cart.Cart.checkout", "calls", "shop.cart.total"),
+            ("shop.cart.make", "constructs", "shop.cart.Cart"),
+            ("shop.cart.make", "constructs", "shop.store.MemoryStore"),
+            ("shop.store.MemoryStore", "implements", "shop.store.Store"),
+            ("shop.store.MemoryStore", "inherits", "shop.store.Base"),
+            ("shop.store.MemoryStore.get", "calls", "shop.store.MemoryStore.lookup"),
+        }
+
+    @pytest.mark.unit
+    def test_nodes_are_root_relative(self, relations):
+        node = next(n for n in relations.nodes if n.id == "shop.cart.total")
+        assert (node.kind, node.file_path, node.lineno) == (
+            "function",
+            "shop/cart.py",
+            6,
+        )
+
+
+class TestGraphExport:
+    """Tests for the GraphML, DOT, and Neo4j CSV exports."""
+
+    @pytest.mark.unit
+    def test_graphml(self, relations):
+        ns = {"g": "http://graphml.graphdrawing.org/xmlns"}
+        root = ET.fromstring(render_graphml(relations))
+
+        nodes = root.findall("g:graph/g:node", ns)
+        edges = root.findall("g:graph/g:edge", ns)
+        assert len(nodes) == len(relations.nodes)
+        assert {(e.get("source"), e.find("g:data", ns).text) for e in edges} >= {
+            ("shop.cart.make", "constructs"),
+        }
+
+    @pytest.mark.unit
+    def test_dot(self, relations):
+        dot = render_dot(relations)
+
+        assert dot.startswith("digraph autodoc {\n")
+        assert '"shop.store.Store" [shape=box, kind=class];' in dot
+        assert '"shop.cart" -> "shop.store" [label=imports];' in dot
+
+    @pytest.mark.unit
+    def test_neo4j_csv(self, relations):
+        files = render_neo4j_csv(relations)
+
+        nodes = list(csv.DictReader(io.StringIO(files["nodes.csv"])))
+        rels = list(csv.DictReader(io.StringIO(files["relationships.csv"])))
+        base = next(n for n in nodes if n["id:ID"] == "shop.store.Base")
+        assert base[":LABEL"] == "Class"
+        assert {r[":TYPE"] for r in rels} == {
+            "CALLS",
+            "CONSTRUCTS",
+            "EMBEDS",
+            "IMPLEMENTS",
+            "IMPORTS",
+            "INHERITS",
+        }
+
+    @pytest.mark.unit
+    def test_command_writes_neo4j_dir(self, relations, tmp_path):
+        out = tmp_path / "graph"
+
+        args = ["graph", "--root", str(tmp_path), "--format", "neo4j"]
+
+        assert run_command([*args, "--output", str(out)]) == 0
+        assert sorted(p.name for p in out.iterdir()) == [
+            "nodes.csv",
+            "relationships.csv",
+        ]
@@ -0,0 +1,132 @@
+"""Unit tests for release notes compiled from docstring fragments."""
+
+from __future__ import annotations
+
+import json
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.release_notes import (
+    ReleaseNote,
+    new_release_notes,
+    parse_release_notes,
+    render_release_notes,
+    strip_release_notes,
+)
+
+OLD = '''"""Carts."""
+
+
+def empty():
+    """Whether nothing is in the cart.
+
+    @autodoc:relnote fix: ``empty`` ignores removed items.
+    """
+'''
+
+NEW = '''"""Carts."""
+
+
+def empty():
+    """Whether nothing is in the cart.
+
+    @autodoc:relnote fix: ``empty`` ignores removed items.
+    """
+
+
+def total(items, tax=0):
+    """Sum of the items plus ``tax``.
+
+    @autodoc:relnote feature: ``total`` takes a
+    ``tax`` rate.
+    @autodoc:relnote breaking: ``total`` needs a list.
+
+    Totals are rounded.
+    """
+'''
+
+
+def _git(repo: Path, *args: str) -> None:
+    subprocess.run(["git", *args], cwd=repo, check=True, capture_output=True)
+
+
+@pytest.fixture
+def repo(tmp_path: Path) -> Path:
+    """A repository tagged ``v1.0.0`` with a commit after the tag."""
+    _git(tmp_path, "init", "-q", "-b", "main")
+    _git(tmp_path, "config", "user.email", "dev@example.com")
+    _git(tmp_path, "config", "user.name", "Dev")
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "cart.py").write_text(OLD, encoding="utf-8")
+    _git(tmp_path, "add", ".")
+    _git(tmp_path, "commit", "-q", "-m", "old")
+    _git(tmp_path, "tag", "v1.0.0")
+    (tmp_path / "shop" / "cart.py").write_text(NEW, encoding="utf-8")
+    _git(tmp_path, "commit", "-q", "-am", "new")
+    return tmp_path
+
+
+def test_parse_and_strip_release_notes() -> None:
+    docstring = (
+        "Summary.\n\n"
+        "@autodoc:relnote Totals are\nrounded.\n"
+        "@autodoc:relnote perf: Faster.\n\n"
+        "Details."
+    )
+    assert parse_release_notes(docstring) == [
+        ("other", "Totals are rounded."),
+        ("other", "Faster."),
+    ]
+    assert strip_release_notes(docstring) == "Summary.\n\nDetails."
+    assert strip_release_notes("@autodoc:relnote fix: Only a note.") is None
+    assert strip_release_notes("Plain.") == "Plain."
+
+
+def test_render_release_notes() -> None:
+    old = ReleaseNote("shop.a", "fix", "Kept.")
+    notes = new_release_notes(
+        [old],
+        [
+            old,
+            ReleaseNote("shop.b", "fix", "New."),
+            ReleaseNote("shop.c", "breaking", "Gone."),
+        ],
+    )
+    assert render_release_notes(notes, "v2.0.0") == (
+        "# v2.0.0\n\n"
+        "## Breaking changes\n\n- Gone. (`shop.c`)\n\n"
+        "## Fixes\n\n- New. (`shop.b`)\n"
+    )
+    assert "No release notes." in render_release_notes([])
+
+
+def test_relnotes_command(repo: Path, capsys: pytest.CaptureFixture[str]) -> None:
+    assert run_command(["relnotes", "--root", str(repo)]) == 0
+    assert capsys.readouterr().out == (
+        "# Release notes\n\n"
+        "## Breaking changes\n\n- ``total`` needs a list. (`shop.cart.total`)\n\n"
+        "## Features\n\n- ``total`` takes a ``tax`` rate. (`shop.cart.total`)\n"
+    )
+
+    args = ["relnotes", "--root", str(repo), "--format", "json"]
+    assert run_command([*args, "--since", "HEAD~1"]) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert data["since"] == "HEAD~1"
+    assert [note["kind"] for note in data["notes"]] == ["breaking", "feature"]
+
+    assert run_command(["relnotes", "--root", str(repo), "--since", "v9"]) == 1
+    assert "Error" in capsys.readouterr().err
+
+
+def test_fragments_left_out_of_the_site(repo: Path) -> None:
+    parsed = parse_tree(repo)
+    markdown = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    page = {page.path: page.content for page in markdown}["shop.md"]
+    assert "Sum of the items plus ``tax``.\n\nTotals are rounded." in page
+    assert "relnote" not in page
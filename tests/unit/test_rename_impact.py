@@ -0,0 +1,145 @@
+"""Unit tests for the rename impact report of ``autodoc rename``."""
+
+from __future__ import annotations
+
+import json
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.parser import parse_tree
+from services.doc_links import SymbolIndex
+from services.doc_symbols import discover_python_files
+from services.rename_impact import (
+    CODE,
+    DOCUMENT,
+    REFERENCE,
+    RenameError,
+    discover_documents,
+    find_rename_impact,
+    plan_rename,
+)
+
+SOURCES = {
+    "shop/__init__.py": '"""Shop."""\n',
+    "shop/cart.py": (
+        '"""Carts; see :class:`~shop.cart.Cart` and [Cart.total]."""\n\n\n'
+        "class Cart:\n"
+        '    """Items being bought.\n\n'
+        "    >>> Cart().total()\n"
+        "    0\n"
+        '    """\n\n'
+        "    def total(self):\n"
+        '        """Sum of the items; ``Cart.items`` holds them."""\n'
+    ),
+    "shop/api.py": (
+        '"""API over [C], an alias of :class:`shop.cart.Cart`."""\n\n'
+        "from shop.cart import Cart as C\n\n\n"
+        "def checkout(cart):\n"
+        '    """Pay for ``cart``, a :class:`.C`; the Cart word is prose."""\n'
+    ),
+}
+
+README = """# Shop
+
+Create a `Cart`:
+
+```python
+from shop.cart import Cart
+
+cart = Cart()
+```
+
+A Cart outside code is prose.
+"""
+
+
+def _write(tmp_path) -> None:
+    for relative, text in SOURCES.items():
+        path = tmp_path / relative
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(text, encoding="utf-8")
+    (tmp_path / "README.md").write_text(README, encoding="utf-8")
+
+
+def _report(tmp_path, old="shop.cart.Cart", new="Basket"):
+    tree = parse_tree(tmp_path)
+    return find_rename_impact(
+        tmp_path,
+        old,
+        new,
+        SymbolIndex(tree.symbols, tree.graph),
+        discover_python_files(tmp_path),
+        discover_documents(tmp_path),
+    )
+
+
+def test_reports_references_code_and_documents(tmp_path) -> None:
+    _write(tmp_path)
+
+    report = _report(tmp_path)
+
+    found = [(i.kind, i.file_path, i.lineno, i.text) for i in report.impacts]
+    assert found == [
+        (REFERENCE, "shop/api.py", 1, "shop.cart.Cart"),
+        (REFERENCE, "shop/cart.py", 1, "shop.cart.Cart"),
+        (REFERENCE, "shop/cart.py", 1, "Cart.total"),
+        (CODE, "shop/cart.py", 7, "Cart"),
+        (CODE, "shop/cart.py", 12, "Cart"),
+        (DOCUMENT, "README.md", 3, "Cart"),
+        (DOCUMENT, "README.md", 6, "Cart"),
+        (DOCUMENT, "README.md", 8, "Cart"),
+    ]
+    assert report.impacts[2].replacement == "Basket.total"
+    assert report.impacts[1].format() == (
+        "shop/cart.py:1: [reference] shop.cart.Cart -> shop.cart.Basket "
+        "in shop.cart"
+    )
+    renamed = {f.path: f.renamed for f in report.files}
+    assert ":class:`~shop.cart.Basket` and [Basket.total]" in renamed["shop/cart.py"]
+    assert "class Cart:" in renamed["shop/cart.py"]
+    assert "[C], an alias" in renamed["shop/api.py"]
+    assert "the Cart word is prose" in renamed["shop/api.py"]
+    assert "cart = Basket()" in renamed["README.md"]
+    assert "A Cart outside code is prose." in renamed["README.md"]
+
+
+def test_plan_rename_rejects_moves_and_taken_names(tmp_path) -> None:
+    _write(tmp_path)
+    tree = parse_tree(tmp_path)
+    index = SymbolIndex(tree.symbols, tree.graph)
+
+    assert plan_rename("shop.cart.Cart", "shop.cart.Basket", index) == (
+        "shop.cart.Basket"
+    )
+    with pytest.raises(RenameError, match="not defined"):
+        plan_rename("shop.cart.Bag", "Basket", index)
+    with pytest.raises(RenameError, match="moves it"):
+        plan_rename("shop.cart.Cart", "shop.api.Cart", index)
+    with pytest.raises(RenameError, match="already defined"):
+        plan_rename("shop.api", "cart", index)
+    with pytest.raises(RenameError, match="not a valid name"):
+        plan_rename("shop.cart.Cart", "2cart", index)
+
+
+def test_rename_command_previews_then_writes(tmp_path, capsys) -> None:
+    _write(tmp_path)
+    root = str(tmp_path)
+
+    assert run_command(["rename", "shop.cart.Cart", "Basket", "--root", root]) == 0
+    out = capsys.readouterr().out
+    assert "Found 8 reference(s) to shop.cart.Cart in 3 file(s)" in out
+
+    args = ["rename", "shop.cart.Cart", "Basket", "--root", root]
+    assert run_command([*args, "--diff"]) == 0
+    assert "+cart = Basket()" in capsys.readouterr().out
+    assert (tmp_path / "README.md").read_text(encoding="utf-8") == README
+
+    assert run_command([*args, "--write", "--format", "json"]) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert data["new"] == "shop.cart.Basket"
+    assert sorted(data["files"]) == ["README.md", "shop/api.py", "shop/cart.py"]
+    assert "cart = Basket()" in (tmp_path / "README.md").read_text(encoding="utf-8")
+
+    assert run_command(["rename", "shop.cart.Bag", "Basket", "--root", root]) == 1
+    assert "Error: shop.cart.Bag is not defined" in capsys.readouterr().err
@@ -0,0 +1,120 @@
+"""Unit tests for schema versioning and ``autodoc migrate``."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.doc_baseline import BaselineError, load_baseline
+from services.schema import (
+    BASELINE,
+    SCHEMA_KEY,
+    SCHEMA_VERSION,
+    SITE,
+    SchemaError,
+    migrate,
+)
+
+
+@pytest.fixture
+def legacy_baseline(tmp_path: Path) -> Path:
+    """A baseline written before outputs carried a schema version."""
+    path = tmp_path / ".autodoc-baseline.json"
+    entry = {
+        "rule": "missing-docstring",
+        "symbol": "pkg.run",
+        "file_path": "pkg/mod.py",
+        "message": "pkg.run has no docstring",
+        "first_seen": "2024-01-01",
+    }
+    path.write_text(json.dumps({"version": 1, "entries": [entry]}), encoding="utf-8")
+    return path
+
+
+class TestMigrate:
+    """Tests for upgrading artifacts to the current schema."""
+
+    @pytest.mark.unit
+    def test_unversioned_artifacts_are_upgraded(self):
+        migration = migrate({"title": "API", "packages": []})
+
+        assert migration.kind == SITE
+        assert (migration.from_version, migration.to_version) == (0, SCHEMA_VERSION)
+        assert migration.changed
+        assert migration.data[SCHEMA_KEY] == SCHEMA_VERSION
+
+    @pytest.mark.unit
+    def test_current_artifacts_are_unchanged(self):
+        data = {SCHEMA_KEY: SCHEMA_VERSION, "tests": []}
+
+        assert not migrate(data).changed
+
+    @pytest.mark.unit
+    def test_rejects_newer_and_foreign_data(self):
+        with pytest.raises(SchemaError, match="newer than this AutoDoc supports"):
+            migrate({SCHEMA_KEY: SCHEMA_VERSION + 1, "packages": []})
+        with pytest.raises(SchemaError, match="not an AutoDoc"):
+            migrate([])
+        with pytest.raises(SchemaError, match="expected a baseline"):
+            migrate({"packages": []}, BASELINE)
+
+    @pytest.mark.unit
+    def test_legacy_baseline_still_loads(self, legacy_baseline):
+        assert [e.symbol for e in load_baseline(legacy_baseline).entries] == ["pkg.run"]
+
+        data = json.loads(legacy_baseline.read_text(encoding="utf-8"))
+        legacy_baseline.write_text(json.dumps({**data, SCHEMA_KEY: 99}))
+        with pytest.raises(BaselineError, match="newer than this AutoDoc"):
+            load_baseline(legacy_baseline)
+
+
+class TestSchemaInOutputs:
+    """Tests for the schema version written into JSON outputs."""
+
+    @pytest.mark.unit
+    def test_json_site_and_baseline(self, tmp_path, capsys):
+        src = tmp_path / "src"
+        src.mkdir()
+        (src / "mod.py").write_text("def run():\n    pass\n", encoding="utf-8")
+
+        site = tmp_path / "site"
+        run_command(
+            ["generate", "--root", str(src), "--output", str(site), "--format", "json"],
+        )
+        run_command(["baseline", "write", "--root", str(src)])
+
+        index = json.loads((site / "index.json").read_text())
+        baseline = json.loads((src / ".autodoc-baseline.json").read_text())
+        assert index[SCHEMA_KEY] == baseline[SCHEMA_KEY] == SCHEMA_VERSION
+
+
+class TestMigrateCommand:
+    """Tests for ``autodoc migrate``."""
+
+    @pytest.mark.unit
+    def test_rewrites_in_place(self, legacy_baseline, capsys):
+        assert run_command(["migrate", str(legacy_baseline)]) == 0
+        assert "migrated baseline from schema 0 to 1" in capsys.readouterr().out
+        data = json.loads(legacy_baseline.read_text(encoding="utf-8"))
+        assert data[SCHEMA_KEY] == SCHEMA_VERSION
+
+        assert run_command(["migrate", str(legacy_baseline)]) == 0
+        assert "is up to date" in capsys.readouterr().out
+
+    @pytest.mark.unit
+    def test_dry_run(self, legacy_baseline, capsys):
+        before = legacy_baseline.read_text(encoding="utf-8")
+
+        assert run_command(["migrate", str(legacy_baseline), "--dry-run"]) == 0
+
+        assert f"would modify {legacy_baseline.as_posix()}" in capsys.readouterr().out
+        assert legacy_baseline.read_text(encoding="utf-8") == before
+
+    @pytest.mark.unit
+    def test_unreadable_file_fails(self, tmp_path, capsys):
+        path = tmp_path / "other.json"
+        path.write_text("[1, 2]", encoding="utf-8")
+
+        assert run_command(["migrate", str(path)]) == 1
+        assert "not an AutoDoc baseline" in capsys.readouterr().err
@@ -0,0 +1,103 @@
+"""Unit tests for secret references in autodoc.yaml and their redaction."""
+
+from __future__ import annotations
+
+import logging
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError
+from autodoc.config.secrets import (
+    REDACTED,
+    RedactingFilter,
+    SecretError,
+    redact,
+    register_secret,
+    resolve_secret,
+)
+from services.confluence_client import (
+    ConfluenceConfigurationError,
+    load_confluence_settings,
+)
+
+
+def test_resolves_environment_and_file_references(tmp_path) -> None:
+    (tmp_path / "secrets").mkdir()
+    (tmp_path / "secrets" / "jira").write_text("from-a-file\n", encoding="utf-8")
+
+    assert resolve_secret("${JIRA_SECRET}", environ={"JIRA_SECRET": "s3cr3t"}) == (
+        "s3cr3t"
+    )
+    assert resolve_secret("file:secrets/jira", tmp_path) == "from-a-file"
+    assert resolve_secret("https://jira.example.com") == "https://jira.example.com"
+
+    with pytest.raises(SecretError, match="MISSING is not set"):
+        resolve_secret("${MISSING}", environ={})
+    with pytest.raises(SecretError, match="Cannot read secret file"):
+        resolve_secret("file:absent", tmp_path)
+
+
+def test_resolved_secrets_are_redacted_from_text_and_logs() -> None:
+    secret = resolve_secret("${TOKEN}", environ={"TOKEN": "ghp_resolved_token"})
+    register_secret("abc")  # too short to redact safely
+
+    assert redact(f"401 for {secret}") == f"401 for {REDACTED}"
+    assert redact("abc") == "abc"
+
+    record = logging.LogRecord(
+        "test", logging.WARNING, __file__, 1, "GET %s failed", ("/x?t=" + secret,), None
+    )
+    RedactingFilter().filter(record)
+    assert record.getMessage() == f"GET /x?t={REDACTED} failed"
+
+
+def test_integrations_section_requires_token_references(tmp_path) -> None:
+    (tmp_path / "token").write_text("file-token-value", encoding="utf-8")
+    config = ProjectConfig.from_dict(
+        {
+            "integrations": {
+                "github": {
+                    "repository": "acme/shop",
+                    "token": "file:token",
+                    "rate_limit": 1,
+                },
+            },
+        },
+    )
+
+    assert config.integrations.settings("github", tmp_path) == {
+        "repository": "acme/shop",
+        "token": "file-token-value",
+        "rate_limit": 1,
+    }
+    assert config.integrations.settings("jira", tmp_path) == {}
+
+    with pytest.raises(ProjectConfigError, match="must be a reference"):
+        ProjectConfig.from_dict({"integrations": {"github": {"token": "ghp_x"}}})
+    with pytest.raises(ProjectConfigError, match="is not one of"):
+        ProjectConfig.from_dict({"integrations": {"slack": {}}})
+
+
+def test_confluence_settings_take_config_references(tmp_path, monkeypatch) -> None:
+    monkeypatch.setenv("DOCS_CONFLUENCE_TOKEN", "confluence-token-value")
+    (tmp_path / "autodoc.yaml").write_text(
+        "integrations:\n"
+        "  confluence:\n"
+        "    url: https://acme.atlassian.net\n"
+        "    username: docs-bot@acme.com\n"
+        "    token: ${DOCS_CONFLUENCE_TOKEN}\n"
+        "    max_retries: 5\n",
+        encoding="utf-8",
+    )
+
+    settings = load_confluence_settings(tmp_path)
+    assert settings.token == "confluence-token-value"
+    assert (settings.url, settings.max_retries) == ("https://acme.atlassian.net", 5)
+    assert settings.is_configured
+
+    (tmp_path / "autodoc.yaml").write_text(
+        "integrations:\n  confluence:\n    url: acme.atlassian.net\n",
+        encoding="utf-8",
+    )
+    with pytest.raises(ConfluenceConfigurationError, match="Invalid confluence"):
+        load_confluence_settings(tmp_path)
@@ -0,0 +1,110 @@
+"""Unit tests for semver bump recommendations."""
+
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.api_manifest import ManifestDiff
+from services.semver import (
+    BREAKING,
+    FEATURE,
+    PATCH,
+    Version,
+    VersionError,
+    classify,
+    latest_tag,
+    parse_version,
+    recommend,
+)
+
+
+def _git(repo: Path, *args: str) -> None:
+    subprocess.run(["git", *args], cwd=repo, check=True, capture_output=True)
+
+
+class TestRecommendation:
+    """Tests for classifying API diffs and choosing the bump."""
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        ("diff", "expected"),
+        [
+            (ManifestDiff(added=["a"], removed=["b"]), BREAKING),
+            (ManifestDiff(added=["a"]), FEATURE),
+            (ManifestDiff(), PATCH),
+        ],
+    )
+    def test_classify(self, diff, expected):
+        assert classify(diff) == expected
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        ("diff", "current", "expected"),
+        [
+            (ManifestDiff(removed=["b"]), "1.4.2", "2.0.0"),
+            (ManifestDiff(added=["a"]), "1.4.2", "1.5.0"),
+            (ManifestDiff(), "1.4.2", "1.4.3"),
+            # Breaking changes before 1.0 bump the minor version.
+            (ManifestDiff(removed=["b"]), "0.3.1", "0.4.0"),
+        ],
+    )
+    def test_next_version(self, diff, current, expected):
+        assert str(recommend(diff, parse_version(current)).next) == expected
+
+    @pytest.mark.unit
+    def test_check_tag(self):
+        recommendation = recommend(ManifestDiff(added=["a"]), Version(1, 4, 2))
+
+        assert recommendation.check_tag("v1.5.0") is None
+        assert recommendation.check_tag("v2.0.0") is None
+        assert "requiring a minor bump (expected 1.5.0)" in recommendation.check_tag(
+            "v1.4.3",
+        )
+        assert "not newer" in recommendation.check_tag("1.4.2")
+        with pytest.raises(VersionError):
+            recommendation.check_tag("release-7")
+
+    @pytest.mark.unit
+    def test_latest_tag_ignores_other_tags(self):
+        assert latest_tag(["v1.9.0", "v1.10.0", "nightly", "v1.2"]) == "v1.10.0"
+        assert latest_tag(["nightly"]) is None
+
+
+class TestBumpCommand:
+    """Tests for ``autodoc api --bump``."""
+
+    @pytest.fixture
+    def repo(self, tmp_path: Path) -> Path:
+        """A repository tagged ``v1.2.0`` with its manifest committed."""
+        _git(tmp_path, "init", "-q", "-b", "main")
+        _git(tmp_path, "config", "user.email", "dev@example.com")
+        _git(tmp_path, "config", "user.name", "Dev")
+        (tmp_path / "mod.py").write_text("def keep():\n    pass\n", encoding="utf-8")
+        run_command(["api", "--root", str(tmp_path)])
+        _git(tmp_path, "add", ".")
+        _git(tmp_path, "commit", "-q", "-m", "release")
+        _git(tmp_path, "tag", "v1.2.0")
+        return tmp_path
+
+    @pytest.mark.unit
+    def test_feature_release(self, repo, capsys):
+        (repo / "mod.py").write_text(
+            "def keep():\n    pass\n\ndef new():\n    pass\n",
+            encoding="utf-8",
+        )
+
+        assert run_command(["api", "--root", str(repo), "--bump"]) == 0
+        assert "feature changes since v1.2.0: bump minor to 1.3.0" in (
+            capsys.readouterr().out
+        )
+
+    @pytest.mark.unit
+    def test_tag_too_small_for_breaking_change(self, repo, capsys):
+        (repo / "mod.py").write_text("def other():\n    pass\n", encoding="utf-8")
+
+        code = run_command(["api", "--root", str(repo), "--bump", "--tag", "v1.3.0"])
+
+        assert code == 1
+        assert "expected 2.0.0" in capsys.readouterr().err
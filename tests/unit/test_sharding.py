@@ -0,0 +1,81 @@
+"""Unit tests for splitting packages across CI matrix jobs."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.sharding import ShardError, assign_shards, select_shard
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    sizes = {"billing": 3, "shop": 2, "shop/cart": 1, "users": 1, "search": 4}
+    for package, files in sizes.items():
+        (tmp_path / package).mkdir(parents=True, exist_ok=True)
+        for number in range(files):
+            (tmp_path / package / f"m{number}.py").write_text(
+                f'"""Module {number}."""\n',
+                encoding="utf-8",
+            )
+    return tmp_path
+
+
+def test_shards_are_balanced_and_cover_every_package_once() -> None:
+    packages = {
+        name: [f"{name}/m{n}.py" for n in range(size)]
+        for name, size in [("a", 5), ("b", 3), ("c", 3), ("d", 2), ("e", 1)]
+    }
+    shards = assign_shards(packages, 3)
+    reordered = assign_shards(dict(reversed(packages.items())), 3)
+
+    assert [shard.packages for shard in shards] == [
+        shard.packages for shard in reordered
+    ]
+    assert sorted(name for shard in shards for name in shard.packages) == list(
+        "abcde",
+    )
+    assert [len(shard.files) for shard in shards] == [5, 5, 4]
+    assert assign_shards(packages, 8)[7].packages == {}
+    with pytest.raises(ShardError):
+        select_shard(packages, 3, 3)
+    with pytest.raises(ShardError):
+        select_shard(packages, 0, 0)
+
+
+def _shard(tree: Path, index: int, *extra: str) -> int:
+    return run_command(
+        ["shard", "--root", str(tree), "--total", "2", "--index", str(index), *extra],
+    )
+
+
+def test_shard_command_prints_packages_and_files(tree: Path, capsys) -> None:
+    printed = []
+    for index in range(2):
+        assert _shard(tree, index) == 0
+        printed.append(capsys.readouterr().out.split())
+    assert printed == [["search", "shop.cart", "users"], ["billing", "shop"]]
+
+    _shard(tree, 0, "--files")
+    assert capsys.readouterr().out.split() == [
+        "search/m0.py",
+        "search/m1.py",
+        "search/m2.py",
+        "search/m3.py",
+        "shop/cart/m0.py",
+        "users/m0.py",
+    ]
+
+    _shard(tree, 1, "--format", "json")
+    data = json.loads(capsys.readouterr().out)
+    assert (data["index"], data["total"]) == (1, 2)
+    assert data["packages"][0] == {
+        "name": "billing",
+        "files": ["billing/m0.py", "billing/m1.py", "billing/m2.py"],
+    }
+
+    assert _shard(tree, 2) == 1
+    assert "shard index must be between 0 and 1" in capsys.readouterr().err
@@ -0,0 +1,114 @@
+"""Unit tests for documenting what importing a module does."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, SiteConfig, ThemeConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.doc_html import render_html_site
+from services.doc_json import render_json_site
+from services.doc_markdown import render_markdown_site
+from services.doc_theme import build_theme_assets
+from services.import_graph import build_import_graph
+from services.side_effects import find_side_effects, is_registration
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package whose modules register drivers and handlers."""
+    _write(
+        tmp_path / "shop" / "__init__.py",
+        '"""Shop."""\n'
+        "from . import handlers, cart\n"
+        "from .cart import Cart\n\n"
+        '__all__ = ["Cart", "cart"]\n',
+    )
+    _write(tmp_path / "shop" / "cart.py", '"""Carts."""\n\nclass Cart:\n    pass\n')
+    _write(tmp_path / "shop" / "handlers.py", '"""Handlers."""\n')
+    _write(tmp_path / "shop" / "drivers" / "__init__.py", "")
+    _write(tmp_path / "shop" / "drivers" / "postgres.py", '"""Postgres."""\n')
+    _write(
+        tmp_path / "shop" / "db.py",
+        '"""Database access."""\n'
+        "import atexit\n"
+        "import codecs\n"
+        "import logging\n"
+        "from typing import TYPE_CHECKING\n\n"
+        "import shop.drivers.postgres  # noqa: F401\n\n"
+        "if TYPE_CHECKING:\n"
+        "    import readline\n\n"
+        "def search_codec(name):\n"
+        '    """Find a codec."""\n\n'
+        "codecs.register(search_codec)\n"
+        "try:\n"
+        "    logging.basicConfig(level=logging.INFO)\n"
+        "except ValueError:\n"
+        "    pass\n"
+        "engine = logging.getLogger(__name__)\n\n"
+        "@atexit.register\n"
+        "def flush():\n"
+        '    """Flush on exit."""\n\n'
+        'if __name__ == "__main__":\n'
+        "    flush()\n",
+    )
+    return tmp_path
+
+
+def test_is_registration() -> None:
+    assert is_registration("admin.site.register")
+    assert is_registration("sqlalchemy.dialects.registry.register_dialect")
+    assert is_registration("signal.signal")
+    assert not is_registration("logging.basicConfig")
+
+
+def test_side_effects(tree: Path) -> None:
+    effects = find_side_effects(tree, build_import_graph(tree))
+
+    assert [(e.module, e.kind, e.target, e.subject, e.lineno) for e in effects] == [
+        ("shop", "import", "shop.handlers", None, 2),
+        ("shop.db", "import", "shop.drivers.postgres", None, 7),
+        ("shop.db", "registration", "codecs.register", "shop.db.search_codec", 15),
+        ("shop.db", "call", "logging.basicConfig", None, 17),
+        ("shop.db", "registration", "atexit.register", "shop.db.flush", 22),
+    ]
+    assert effects[0].to_dict()["file_path"] == "shop/__init__.py"
+
+
+def test_package_pages_describe_side_effects(tree: Path, tmp_path: Path) -> None:
+    packages = build_model(parse_tree(tree))
+    page = {p.path: p.content for p in render_markdown_site(packages, SiteConfig())}
+    assert page["shop.md"].endswith(
+        "## Import side effects\n\n"
+        "Importing these modules also:\n\n"
+        "- `shop`\n"
+        "  - imports `shop.handlers` for its side effects (line 2)\n"
+        "- `shop.db`\n"
+        "  - imports `shop.drivers.postgres` for its side effects (line 7)\n"
+        "  - registers `shop.db.search_codec` with `codecs.register` (line 15)\n"
+        "  - calls `logging.basicConfig` (line 17)\n"
+        "  - registers `shop.db.flush` with `atexit.register` (line 22)\n",
+    )
+    theme = build_theme_assets(ThemeConfig(), tmp_path)
+    html = {
+        p.path: p.content for p in render_html_site(packages, SiteConfig(), theme)
+    }
+    assert (
+        "<li>registers <code>shop.db.flush</code> with "
+        "<code>atexit.register</code> (line 22)</li>"
+    ) in html["shop.html"]
+    data = json.loads(render_json_site(packages, SiteConfig())[0].content)
+    shop = next(p for p in data["packages"] if p["name"] == "shop")
+    assert [e["kind"] for e in shop["side_effects"]][:2] == ["import", "import"]
+
+    config = ProjectConfig(site=SiteConfig.from_dict({"side_effects": False}))
+    assert build_model(parse_tree(tree), config)[0].side_effects == []
@@ -0,0 +1,109 @@
+"""Unit tests for signing manifests with ``--sign``."""
+
+import os
+import sys
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.attestation import ATTESTATION_FILE
+from services.signing import SigningError, sign_bytes, sign_file, verify_file
+
+# Stands in for minisign: a "signature" is a digest of the key and the file,
+# and a public key verifies it when it holds the same text as the secret key.
+FAKE_MINISIGN = """\
+import hashlib, sys
+mode, *rest = sys.argv[1:]
+opts = dict(zip(rest[::2], rest[1::2]))
+key = open(opts.get("-s") or opts["-p"], "rb").read()
+data = open(opts["-m"], "rb").read()
+digest = hashlib.sha256(key + data).hexdigest()
+if mode == "-S":
+    open(opts["-x"], "w").write(digest)
+elif open(opts["-x"]).read() != digest:
+    sys.exit("Signature verification failed")
+"""
+
+
+@pytest.fixture
+def minisign(tmp_path: Path, monkeypatch) -> tuple[Path, Path]:
+    """A fake minisign on PATH, and its secret and public key files."""
+    bin_dir = tmp_path / "bin"
+    bin_dir.mkdir()
+    script = bin_dir / "minisign"
+    script.write_text(f"#!{sys.executable}\n{FAKE_MINISIGN}", encoding="utf-8")
+    script.chmod(0o755)
+    monkeypatch.setenv("PATH", f"{bin_dir}{os.pathsep}{os.environ['PATH']}")
+    secret = tmp_path / "autodoc.key"
+    public = tmp_path / "autodoc.pub"
+    secret.write_text("key-1", encoding="utf-8")
+    public.write_text("key-1", encoding="utf-8")
+    return secret, public
+
+
+class TestSigning:
+    """Tests for :mod:`services.signing`."""
+
+    @pytest.mark.unit
+    def test_sign_and_verify_file(self, minisign, tmp_path: Path):
+        secret, public = minisign
+        manifest = tmp_path / "api.txt"
+        manifest.write_text("shop.Cart\n", encoding="utf-8")
+        signature = sign_file(manifest, "minisign", str(secret))
+        assert signature == tmp_path / "api.txt.minisig"
+        verify_file(manifest, "minisign", public_key=str(public))
+        assert sign_bytes("shop.Cart\n", "api.txt", "minisign", str(secret)) == (
+            signature.read_bytes()
+        )
+        manifest.write_text("shop.Order\n", encoding="utf-8")
+        with pytest.raises(SigningError, match="Signature verification failed"):
+            verify_file(manifest, "minisign", public_key=str(public))
+
+    @pytest.mark.unit
+    def test_missing_key_and_tool(self, tmp_path: Path, monkeypatch):
+        with pytest.raises(SigningError, match="minisign needs a secret key"):
+            sign_file(tmp_path / "api.txt", "minisign")
+        monkeypatch.setenv("PATH", str(tmp_path))
+        with pytest.raises(SigningError, match="cosign executable not found"):
+            sign_file(tmp_path / "api.txt", "cosign")
+        with pytest.raises(SigningError, match="need --public-key, or"):
+            verify_file(tmp_path / "api.txt", "cosign")
+
+
+class TestSignedSite:
+    """Tests for ``autodoc generate --sign`` and ``--verify --public-key``."""
+
+    @pytest.mark.unit
+    def test_signed_attestation_verifies(self, minisign, tmp_path: Path, capsys):
+        secret, public = minisign
+        root = tmp_path / "src"
+        (root / "shop").mkdir(parents=True)
+        (root / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+        site = tmp_path / "site"
+        argv = ["generate", "--root", str(root), "--output", str(site)]
+        assert run_command(argv) == 0
+        capsys.readouterr()
+        assert run_command([*argv, "--verify", "--public-key", str(public)]) == 1
+        assert f"{ATTESTATION_FILE}: is not signed" in capsys.readouterr().out
+
+        signing = ["--sign", "minisign", "--sign-key", str(secret)]
+        assert run_command([*argv, *signing]) == 0
+        assert (site / f"{ATTESTATION_FILE}.minisig").is_file()
+        capsys.readouterr()
+        assert run_command([*argv, "--verify", "--public-key", str(public)]) == 0
+        assert "signed with minisign" in capsys.readouterr().out
+
+        other = tmp_path / "other.pub"
+        other.write_text("key-2", encoding="utf-8")
+        assert run_command([*argv, "--verify", "--public-key", str(other)]) == 1
+        out = capsys.readouterr().out
+        assert f"{ATTESTATION_FILE}.minisig: minisign failed: Signature" in out
+
+    @pytest.mark.unit
+    def test_api_manifest_is_signed(self, minisign, tmp_path: Path, monkeypatch):
+        secret, public = minisign
+        (tmp_path / "shop.py").write_text('"""Shop."""\n', encoding="utf-8")
+        monkeypatch.setenv("AUTODOC_SIGNING_KEY", str(secret))
+        assert run_command(["api", "--root", str(tmp_path), "--sign", "minisign"]) == 0
+        verify_file(tmp_path / "api.txt", "minisign", public_key=str(public))
@@ -0,0 +1,99 @@
+"""Unit tests for comparing two generated sites."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.site_compare import (
+    SiteCompareError,
+    compare_sites,
+    render_markdown,
+    render_text,
+)
+
+
+def _generate(root: Path, output: Path) -> Path:
+    argv = ["generate", "--root", str(root), "--output", str(output)]
+    assert run_command([*argv, "--format", "markdown"]) == 0
+    return output
+
+
+@pytest.fixture
+def sites(tmp_path: Path) -> tuple[Path, Path]:
+    """The site of a tree before and after a release changes its docs."""
+    tree = tmp_path / "tree"
+    (tree / "shop").mkdir(parents=True)
+    (tree / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tree / "shop" / "cart.py").write_text(
+        'def total():\n    """Total price."""\n',
+        encoding="utf-8",
+    )
+    (tree / "legacy").mkdir()
+    (tree / "legacy" / "__init__.py").write_text('"""Old."""\n', encoding="utf-8")
+    old = _generate(tree, tmp_path / "old")
+
+    (tree / "shop" / "cart.py").write_text(
+        'def total():\n    """Total price, tax included."""\n',
+        encoding="utf-8",
+    )
+    for path in (tree / "legacy").iterdir():
+        path.unlink()
+    (tree / "legacy").rmdir()
+    (tree / "billing").mkdir()
+    (tree / "billing" / "__init__.py").write_text('"""Bills."""\n', encoding="utf-8")
+    new = _generate(tree, tmp_path / "new")
+    return old, new
+
+
+def test_compare_sites(sites: tuple[Path, Path]) -> None:
+    old, new = sites
+    comparison = compare_sites(old, new)
+    kinds = {change.path: change.kind for change in comparison.changes}
+    assert kinds["shop.md"] == "changed"
+    assert kinds["billing.md"] == "added"
+    assert kinds["legacy.md"] == "removed"
+    assert not any(path.startswith(".autodoc") for path in kinds)
+    [shop] = [change for change in comparison.changes if change.path == "shop.md"]
+    assert (shop.added_lines, shop.removed_lines) == (1, 1)
+    assert "+Total price, tax included." in shop.diff
+
+    ignored = compare_sites(old, new, ["*.md"])
+    assert ignored.changes == []
+
+    text = render_text(comparison, diff=True)
+    assert "Changed (" in text
+    assert "  shop.md  (+1 -1)\n" in text
+    assert "Added (1):\n  billing.md\n" in text
+    assert "-Total price." in text
+    markdown = render_markdown(comparison)
+    assert "| `legacy.md` | removed |  |" in markdown
+    assert render_markdown(compare_sites(old, old)).endswith(
+        "The sites are identical.\n",
+    )
+
+
+def test_not_a_site(tmp_path: Path) -> None:
+    (tmp_path / "plain").mkdir()
+    with pytest.raises(SiteCompareError, match="is not a generated site"):
+        compare_sites(tmp_path / "plain", tmp_path / "plain")
+
+
+def test_command(sites: tuple[Path, Path], capsys) -> None:
+    old, new = sites
+    assert run_command(["compare", str(old), str(new)]) == 0
+    assert "1 added, 1 removed" in capsys.readouterr().out
+    assert run_command(["compare", str(old), str(new), "--exit-code"]) == 1
+    capsys.readouterr()
+    assert run_command(["compare", str(old), str(old), "--exit-code"]) == 0
+    capsys.readouterr()
+
+    assert run_command(["compare", str(old), str(new), "--format", "json"]) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert data["added"] == ["billing.md"]
+    assert data["removed"] == ["legacy.md"]
+    assert run_command(["compare", str(old), str(new.parent / "gone")]) == 1
+    assert "is not a generated site" in capsys.readouterr().err
@@ -0,0 +1,116 @@
+"""Unit tests for ``autodoc doc`` and the documentation of one symbol."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from services.symbol_doc import find_symbols
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with a subclass, a caller, a test, and a guide."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(
+        "class Cart:\n"
+        '    """A shopping cart."""\n\n'
+        "    def total(self) -> int:\n"
+        '        """Total price."""\n'
+        "        return 0\n\n\n"
+        "class GiftCart(Cart):\n"
+        '    """A cart for gifts."""\n\n\n'
+        "def checkout(cart: Cart) -> int:\n"
+        '    """Check out ``cart``."""\n'
+        "    if not cart:\n"
+        '        raise ValueError("empty")\n'
+        "    return Cart().total()\n",
+        encoding="utf-8",
+    )
+    (tmp_path / "tests").mkdir()
+    (tmp_path / "tests" / "test_cart.py").write_text(
+        "from shop.cart import Cart\n\n\n"
+        "def test_cart_total():\n"
+        "    assert Cart().total() == 0\n",
+        encoding="utf-8",
+    )
+    guides = tmp_path / "docs" / "guides"
+    guides.mkdir(parents=True)
+    (guides / "start.md").write_text(
+        "# Start\n\n```python\nfrom shop.cart import Cart\nCart().total()\n```\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_find_symbols(tree: Path) -> None:
+    packages = build_model(parse_tree(tree))
+
+    def names(name: str) -> list[str]:
+        return [symbol.qualified_name for _, symbol in find_symbols(packages, name)]
+
+    assert names("shop.cart.Cart") == ["shop.cart.Cart"]
+    assert names("cart.Cart") == ["shop.cart.Cart"]
+    assert names("total") == ["shop.cart.Cart.total"]
+    assert names("art.Cart") == []
+
+
+def test_text(tree: Path, capsys) -> None:
+    assert run_command(["doc", "--root", str(tree), "Cart"]) == 0
+    assert capsys.readouterr().out == (
+        "class Cart\n"
+        "    A shopping cart.\n"
+        "\n"
+        "Defined in shop/cart.py:1 (shop)\n"
+        "\n"
+        "Methods:\n"
+        "    def total() -> int\n"
+        "        Total price.\n"
+        "\n"
+        "Subclasses:\n"
+        "    shop.cart.GiftCart (shop/cart.py:9)\n"
+        "\n"
+        "Callers:\n"
+        "    shop.cart.checkout (shop/cart.py:17)\n"
+        "\n"
+        "Tests:\n"
+        "    tests.test_cart.test_cart_total (tests/test_cart.py:4)\n"
+        "\n"
+        "Examples:\n"
+        "    docs/guides/start.md:4\n"
+        "        from shop.cart import Cart\n"
+        "        Cart().total()\n"
+    )
+
+
+def test_markdown_and_json(tree: Path, capsys) -> None:
+    argv = ["doc", "--root", str(tree), "shop.cart.checkout"]
+    assert run_command([*argv, "--format", "markdown"]) == 0
+    out = capsys.readouterr().out
+    assert out.startswith(
+        "### `checkout`\n\n```python\ndef checkout(cart: Cart) -> int\n",
+    )
+    assert "ValueError" in out
+
+    assert run_command([*argv, "--format", "json"]) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert data["symbol"]["qualified_name"] == "shop.cart.checkout"
+    assert data["raises"] == ["ValueError"]
+    assert data["callers"] == []
+
+
+def test_unknown_and_ambiguous(tree: Path, capsys) -> None:
+    (tree / "shop" / "gift.py").write_text("class Cart:\n    pass\n", encoding="utf-8")
+    argv = ["doc", "--root", str(tree)]
+    assert run_command([*argv, "Basket"]) == 1
+    assert "no documented symbol Basket" in capsys.readouterr().err
+    assert run_command([*argv, "Cart"]) == 1
+    err = capsys.readouterr().err
+    assert "Cart is ambiguous" in err
+    assert "  shop.cart.Cart\n  shop.gift.Cart\n" in err
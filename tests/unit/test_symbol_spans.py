@@ -0,0 +1,64 @@
+"""Unit tests for the definition span index of code browsers."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.symbol_spans import SPAN_FIELDS, line_offsets
+
+SOURCE = (
+    '"""Carts: café edition."""\n\n'
+    "import functools\n\n\n"
+    "@functools.total_ordering\n"
+    "class Cart:\n"
+    '    """A cart."""\n\n'
+    "    async def total(self) -> int:\n"
+    '        """The total, in cents (€)."""\n'
+    "        return 0\n"
+)
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with non-ASCII text before its definitions."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(SOURCE, encoding="utf-8")
+    return tmp_path
+
+
+def test_line_offsets_count_bytes() -> None:
+    assert line_offsets("é\r\nb\x0cc\n") == [0, 4, 8]
+
+
+def test_spans_index(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig(site=SiteConfig.from_dict({"spans": True}))
+    pages = render_site(parsed, build_model(parsed), ("json",), config)["json"]
+    index = json.loads({page.path: page.content for page in pages}["spans.json"])
+
+    assert index["fields"] == list(SPAN_FIELDS)
+    rows = {row[0]: dict(zip(SPAN_FIELDS, row)) for row in index["symbols"]}
+    data = SOURCE.encode("utf-8")
+    cart = rows["shop.cart.Cart"]
+    assert index["files"][cart["file"]] == "shop/cart.py"
+    assert data[cart["start"] : cart["end"]].startswith(b"@functools.total_ordering")
+    assert data[cart["name_start"] : cart["name_end"]] == b"Cart"
+    assert cart["line"] == 7
+    total = rows["shop.cart.Cart.total"]
+    assert total["kind"] == "method"
+    assert data[total["start"] : total["end"]].endswith(b"return 0")
+    assert data[total["name_start"] : total["name_end"]] == b"total"
+    module = rows["shop.cart"]
+    assert (module["start"], module["end"]) == (0, len(data))
+    assert module["name_start"] is None
+
+    default = render_site(parsed, build_model(parsed), ("json",))["json"]
+    assert [page.path for page in default] == ["index.json"]
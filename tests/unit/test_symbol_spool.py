@@ -0,0 +1,87 @@
+"""Unit tests for spilling parsed symbols to disk (``--max-memory``)."""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.parser import parse_tree
+from services.doc_symbols import DocSymbol, root_relative
+from services.symbol_spool import SymbolSpool
+
+
+def _symbol(number: int) -> DocSymbol:
+    return DocSymbol(
+        qualified_name=f"pkg.mod.f{number}",
+        name=f"f{number}",
+        kind="function",
+        package="pkg",
+        file_path="pkg/mod.py",
+        lineno=number,
+        docstring="Do it." * number,
+        is_public=True,
+    )
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    for package in ("billing", "shop"):
+        (tmp_path / package).mkdir()
+        functions = "".join(
+            f'\n\ndef f{n}():\n    """Step {n}."""\n'
+            if n % 3
+            else f"\n\ndef f{n}():\n    pass\n"
+            for n in range(40)
+        )
+        (tmp_path / package / "__init__.py").write_text(
+            f'"""The {package} package."""\n{functions}',
+            encoding="utf-8",
+        )
+    return tmp_path
+
+
+def test_spool_reads_back_what_it_spilled() -> None:
+    symbols = [_symbol(n) for n in range(50)]
+    spool = SymbolSpool(4096)
+    spool.extend(symbols)
+
+    assert len(spool) == 50
+    assert spool.spilled > 0
+    assert list(spool) == symbols
+    assert [spool[n] for n in (0, 49, 17, 18, -1)] == [
+        symbols[0],
+        symbols[49],
+        symbols[17],
+        symbols[18],
+        symbols[-1],
+    ]
+    assert spool[10:13] == symbols[10:13]
+    with pytest.raises(IndexError):
+        spool[50]
+    relative = root_relative(spool, "pkg")
+    assert isinstance(relative, SymbolSpool)
+    assert [symbol.file_path for symbol in relative][:1] == ["mod.py"]
+    spool.close()
+    assert len(spool) == 0
+
+
+def test_parse_tree_spools_past_max_memory(tree: Path) -> None:
+    in_memory = parse_tree(tree)
+    spooled = parse_tree(tree, max_memory=8192)
+
+    assert isinstance(spooled.symbols, SymbolSpool)
+    assert spooled.symbols.spilled > 0
+    assert list(spooled.symbols) == list(in_memory.symbols)
+
+
+def test_max_memory_flag_keeps_results(tree: Path, capsys) -> None:
+    outputs = []
+    for extra in ([], ["--max-memory", "8K"]):
+        for command in ("coverage", "lint"):
+            run_command([command, "--root", str(tree), *extra])
+        outputs.append(capsys.readouterr().out)
+
+    assert "billing" in outputs[0]
+    assert outputs[1] == outputs[0]
@@ -0,0 +1,76 @@
+"""Unit tests for the symbol statistics page."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.symbol_stats import build_symbol_stats
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with a large class and a private helper."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(
+        '"""Carts."""\n\n\n'
+        "class Cart:\n"
+        '    """A cart of items."""\n\n'
+        "    def add(self) -> None:\n"
+        "        pass\n\n"
+        "    def total(self) -> int:\n"
+        "        return 0\n\n\n"
+        "class Item:\n"
+        "    pass\n\n\n"
+        "def _round(value: float) -> int:\n"
+        "    return int(value)\n",
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_build_symbol_stats(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    [stats] = build_symbol_stats(build_model(parsed), parsed.symbols)
+
+    assert stats.name == "shop"
+    assert stats.kinds == (2, 2, 1, 2)
+    assert (stats.exported, stats.unexported) == (6, 1)
+    assert stats.exported_percent == 86
+    assert stats.documented == 3
+    assert stats.doc_length == round(len("Shop.Carts.A cart of items.") / 3)
+    assert [(t.qualified_name, t.lines) for t in stats.largest] == [
+        ("shop.cart.Cart", 8),
+        ("shop.cart.Item", 2),
+    ]
+    assert [(t.qualified_name, t.methods) for t in stats.most_methods] == [
+        ("shop.cart.Cart", 2),
+    ]
+
+
+def test_stats_page(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig(site=SiteConfig.from_dict({"stats": True}))
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed), formats, config)
+
+    markdown = {page.path: page.content for page in sites["markdown"]}
+    assert "[Symbol statistics](stats.md)" in markdown["index.md"]
+    assert "| [shop](shop.md) | 2 | 2 | 1 | 2 | 6 of 7 (86%) |" in markdown["stats.md"]
+    assert "(8 line(s))" in markdown["stats.md"]
+    html = {page.path: page.content for page in sites["html"]}
+    assert '<svg class="autodoc-chart"' in html["stats.html"]
+    [index] = sites["json"]
+    assert json.loads(index.content)["stats"][0]["kinds"]["method"] == 2
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    assert "stats.md" not in [page.path for page in default]
+    with pytest.raises(ProjectConfigError, match="site.stats"):
+        SiteConfig.from_dict({"stats": "yes"})
@@ -0,0 +1,57 @@
+"""Unit tests for the ctags and etags files."""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+
+SOURCE = (
+    '"""Carts (€)."""\n\n\n'
+    "class Cart:\n"
+    '    """A cart."""\n\n'
+    "    def total(self, path: str = 'a/b') -> int:\n"
+    '        """The total."""\n'
+    "        return 0\n"
+)
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``shop`` package with a class and a method."""
+    (tmp_path / "shop").mkdir()
+    (tmp_path / "shop" / "__init__.py").write_text('"""Shop."""\n', encoding="utf-8")
+    (tmp_path / "shop" / "cart.py").write_text(SOURCE, encoding="utf-8")
+    return tmp_path
+
+
+def test_ctags(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    [page] = render_site(parsed, build_model(parsed), ("ctags",))["ctags"]
+    lines = page.content.splitlines()
+
+    assert page.path == "tags"
+    assert lines[0] == "!_TAG_FILE_FORMAT\t2\t/extended format/"
+    entries = [line for line in lines if not line.startswith("!")]
+    assert entries == sorted(entries)
+    method = "shop/cart.py\t/^    def total(self, path: str = 'a\\/b') -> int:$/;\""
+    assert f"total\t{method}\tm\tline:7\tclass:Cart" in entries
+    assert f"shop.cart.Cart.total\t{method}\tm\tline:7\tclass:Cart" in entries
+    assert 'shop.cart\tshop/cart.py\t1;"\ti\tline:1' in entries
+
+
+def test_etags(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    [page] = render_site(parsed, build_model(parsed), ("etags",))["etags"]
+    sections = page.content.split(b"\x0c\n")[1:]
+
+    assert page.path == "TAGS"
+    header, _, body = sections[1].partition(b"\n")
+    assert header == f"shop/cart.py,{len(body)}".encode()
+    offset = len('"""Carts (€)."""\n\n\n'.encode())
+    assert f"class Cart\x7fCart\x014,{offset}\n".encode() in body
+    assert b"    def total\x7fshop.cart.Cart.total\x017," in body
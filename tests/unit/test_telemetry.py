@@ -0,0 +1,156 @@
+"""Unit tests for the OpenTelemetry instrumentation of the pipeline."""
+
+from __future__ import annotations
+
+from contextlib import contextmanager
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services import telemetry
+from services.telemetry import Telemetry, configure, count, span
+
+
+class FakeSpan:
+    def __init__(self, name: str, attributes: dict) -> None:
+        self.name = name
+        self.attributes = dict(attributes)
+        self.children: list[FakeSpan] = []
+
+    def set_attributes(self, attributes: dict) -> None:
+        self.attributes.update(attributes)
+
+
+class FakeTracer:
+    """Records spans as a tree, like a tracer with a current span would."""
+
+    def __init__(self) -> None:
+        self.roots: list[FakeSpan] = []
+        self.stack: list[FakeSpan] = []
+
+    @contextmanager
+    def start_as_current_span(self, name: str, attributes: dict):
+        span = FakeSpan(name, attributes)
+        (self.stack[-1].children if self.stack else self.roots).append(span)
+        self.stack.append(span)
+        try:
+            yield span
+        finally:
+            self.stack.pop()
+
+
+class FakeCounter:
+    def __init__(self) -> None:
+        self.adds: list[tuple[int, dict]] = []
+
+    def add(self, value: int, attributes: dict) -> None:
+        self.adds.append((value, attributes))
+
+
+class FakeMeter:
+    def __init__(self) -> None:
+        self.counters: dict[str, FakeCounter] = {}
+
+    def create_counter(self, name: str, unit: str, description: str) -> FakeCounter:
+        return self.counters.setdefault(name, FakeCounter())
+
+    def total(self, name: str, **attributes: str) -> int:
+        wanted = {f"autodoc.{k}": v for k, v in attributes.items()}
+        counter = self.counters.get(name, FakeCounter())
+        return sum(
+            value
+            for value, attrs in counter.adds
+            if wanted.items() <= attrs.items()
+        )
+
+
+@pytest.fixture
+def recorded():
+    tracer, meter = FakeTracer(), FakeMeter()
+    previous = telemetry.use(Telemetry(tracer, meter))
+    yield tracer, meter
+    telemetry.use(previous)
+
+
+def _names(span: FakeSpan) -> list[str]:
+    return [span.name, *(name for child in span.children for name in _names(child))]
+
+
+def _packages(run: FakeSpan) -> list[str]:
+    return [
+        span.attributes["autodoc.package"]
+        for render in run.children
+        if render.name == "autodoc.render"
+        for span in render.children
+    ]
+
+
+def test_spans_and_counters_are_prefixed(recorded) -> None:
+    tracer, meter = recorded
+
+    with span("autodoc.render", format="html", skipped=None) as current:
+        current.set(pages=3)
+        count("autodoc.pages.rendered", 3, format="html")
+        count("autodoc.pages.rendered", 0, format="html")
+
+    [root] = tracer.roots
+    assert root.attributes == {"autodoc.format": "html", "autodoc.pages": 3}
+    assert meter.counters["autodoc.pages.rendered"].adds == [
+        (3, {"autodoc.format": "html"}),
+    ]
+
+
+def test_without_opentelemetry_nothing_is_recorded() -> None:
+    off = Telemetry()
+    with off.span("autodoc.parse") as current:
+        current.set(files=1)
+    off.count("autodoc.files.parsed")
+
+    assert configure({}) is False
+    assert configure({"OTEL_EXPORTER_OTLP_ENDPOINT": ""}) is False
+    assert configure(
+        {"OTEL_EXPORTER_OTLP_ENDPOINT": "http://x", "OTEL_SDK_DISABLED": "true"},
+    ) is False
+
+
+def test_generate_is_traced_per_phase_and_package(
+    recorded,
+    tmp_path: Path,
+    capsys,
+) -> None:
+    tracer, meter = recorded
+    for package in ("billing", "shop"):
+        (tmp_path / "src" / package).mkdir(parents=True)
+        (tmp_path / "src" / package / "__init__.py").write_text(
+            f'"""The {package} package."""\n\n\ndef run():\n    """Run."""\n',
+            encoding="utf-8",
+        )
+    args = [
+        "generate",
+        "--root",
+        str(tmp_path / "src"),
+        "--format",
+        "html",
+        "--output",
+        str(tmp_path / "site"),
+        "--incremental",
+    ]
+
+    assert run_command(args) == 0
+    assert run_command(args) == 0
+    capsys.readouterr()
+
+    first, second = tracer.roots
+    assert first.name == "autodoc generate"
+    assert first.attributes["autodoc.exit_code"] == 0
+    names = _names(first)
+    for phase in ("autodoc.parse", "autodoc.analyze", "autodoc.model"):
+        assert phase in names
+    assert _packages(first) == ["billing", "shop"]
+    # The second run reuses every file and page.
+    assert _packages(second) == []
+    assert meter.total("autodoc.files.parsed") == 2
+    assert meter.total("autodoc.cache.hits", cache="symbols") == 2
+    assert meter.total("autodoc.cache.misses", cache="imports") == 2
+    assert meter.total("autodoc.pages.rendered", format="html") > 0
@@ -0,0 +1,225 @@
+"""Unit tests for test suite documentation."""
+
+from pathlib import Path
+
+import pytest
+
+from services.doc_markdown import render_test_suite_markdown
+from services.doc_symbols import load_doc_symbols
+from services.import_graph import build_import_graph
+from services.test_docs import UNGROUPED, build_test_suite_doc, is_test_file, sentence
+
+
+def _write(path: Path, content: str) -> None:
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content, encoding="utf-8")
+
+
+def _suite(root: Path):
+    return build_test_suite_doc(
+        root,
+        build_import_graph(root),
+        load_doc_symbols(root),
+    )
+
+
+@pytest.fixture
+def project(tmp_path: Path) -> Path:
+    """A ``cart`` module and a test file exercising it."""
+    _write(
+        tmp_path / "shop" / "cart.py",
+        '"""Carts."""\n\n'
+        "def total(items):\n"
+        '    """Sum prices."""\n'
+        "    return sum(items)\n\n"
+        "def parse(text):\n"
+        '    """Parse a cart."""\n'
+        "    return text\n\n"
+        "class Cart:\n"
+        '    """A cart."""\n\n'
+        "    def add(self, item):\n"
+        '        """Add an item."""\n',
+    )
+    _write(
+        tmp_path / "tests" / "test_cart.py",
+        "import pytest\n"
+        "from shop import cart\n"
+        "from shop.cart import Cart, parse, total\n\n"
+        '@pytest.mark.parametrize(("items", "expected"), [([1, 2], 3), ([], 0)])\n'
+        "def test_total_sums_prices(items, expected):\n"
+        '    """Totals add up."""\n'
+        "    assert total(parse(items)) == expected\n\n"
+        "@pytest.mark.parametrize(\n"
+        '    "text",\n'
+        '    ["a", pytest.param("", id="empty")],\n'
+        ")\n"
+        "def test_parse(text):\n"
+        "    parse(text)\n"
+        "    parse(text)\n"
+        "    cart.total([])\n\n"
+        "class TestCart:\n"
+        '    cart = Cart()\n\n'
+        '    @pytest.mark.parametrize("n", [1, 2], ids=["one", "two"])\n'
+        "    def test_add(self, n):\n"
+        "        Cart.add(Cart(), n)\n\n"
+        "    def test_nothing(self):\n"
+        "        assert True\n\n"
+        "def test_unrelated():\n"
+        "    assert 1 + 1 == 2\n",
+    )
+    _write(tmp_path / "tests" / "conftest.py", "def test_fixture():\n    pass\n")
+    return tmp_path
+
+
+class TestNaming:
+    """Tests for turning test names into sentences."""
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        ("name", "expected"),
+        [
+            ("test_handles_emptyInput", "Handles empty input"),
+            ("TestSymbolUsage", "Symbol usage"),
+            ("test_parses_HTML_pages", "Parses HTML pages"),
+            ("test", "Test"),
+        ],
+    )
+    def test_sentence(self, name, expected):
+        assert sentence(name) == expected
+
+    @pytest.mark.unit
+    @pytest.mark.parametrize(
+        ("path", "expected"),
+        [
+            ("tests/unit/helpers.py", True),
+            ("pkg/test_cart.py", True),
+            ("pkg/cart_test.py", True),
+            ("pkg/testing.py", False),
+        ],
+    )
+    def test_is_test_file(self, path, expected):
+        assert is_test_file(path) is expected
+
+
+class TestSuiteDoc:
+    """Tests for collecting and grouping tests."""
+
+    @pytest.mark.unit
+    def test_collects_tests_and_cases(self, project):
+        suite = _suite(project)
+        by_name = {test.name: test for test in suite.tests}
+
+        assert sorted(by_name) == [
+            "test_add",
+            "test_nothing",
+            "test_parse",
+            "test_total_sums_prices",
+            "test_unrelated",
+        ]
+        total = by_name["test_total_sums_prices"]
+        assert (total.sentence, total.docstring, total.lineno) == (
+            "Total sums prices",
+            "Totals add up.",
+            6,
+        )
+        assert total.cases == ["[1, 2], 3", "[], 0"]
+        assert by_name["test_parse"].cases == ["'a'", "empty"]
+        assert by_name["test_add"].cases == ["one", "two"]
+        assert by_name["test_add"].test_class == "TestCart"
+
+    @pytest.mark.unit
+    def test_subjects_are_ranked(self, project):
+        by_name = {test.name: test for test in _suite(project).tests}
+
+        # Named in the test name beats referenced more often.
+        assert by_name["test_total_sums_prices"].subject == "shop.cart.total"
+        assert by_name["test_parse"].subjects == ["shop.cart.parse", "shop.cart.total"]
+        # The more specific method wins over its class.
+        assert by_name["test_add"].subject == "shop.cart.Cart.add"
+        # Falls back to what the test class references.
+        assert by_name["test_nothing"].subject == "shop.cart.Cart"
+        assert by_name["test_unrelated"].subject is None
+
+    @pytest.mark.unit
+    def test_groups_put_ungrouped_last(self, project):
+        groups = _suite(project).groups()
+
+        assert [name for name, _ in groups] == [
+            "shop.cart.Cart",
+            "shop.cart.Cart.add",
+            "shop.cart.parse",
+            "shop.cart.total",
+            UNGROUPED,
+        ]
+
+    @pytest.mark.unit
+    def test_markdown_page(self, project):
+        page = render_test_suite_markdown(_suite(project))
+
+        assert "5 test(s), grouped by the symbol under test." in page
+        assert "## `shop.cart.total`" in page
+        assert (
+            "- Total sums prices - Totals add up. (`tests/test_cart.py:6`)\n"
+            "  - Cases: `[1, 2], 3`; `[], 0`" in page
+        )
+        assert "- Add (TestCart, `tests/test_cart.py:23`)" in page
+
+
+class TestFuzzTargets:
+    """Tests for documenting fuzz targets and their seeds."""
+
+    @pytest.mark.unit
+    def test_hypothesis_and_atheris_targets(self, project):
+        _write(
+            project / "tests" / "test_fuzz.py",
+            "import sys\n"
+            "import atheris\n"
+            "from hypothesis import example, given, strategies as st\n"
+            "from shop.cart import parse\n\n"
+            "@given(st.text())\n"
+            '@example("")\n'
+            '@example("x")\n'
+            "def test_parse_never_crashes(text):\n"
+            "    parse(text)\n\n"
+            "def fuzz_parse(data):\n"
+            "    parse(data.decode())\n\n"
+            "atheris.Setup(sys.argv, fuzz_parse)\n",
+        )
+        for name in ("a", "b", ".hidden"):
+            _write(project / "tests" / "corpus" / "fuzz_parse" / name, "seed")
+        _write(
+            project / "tests" / "testdata" / "fuzz" / "test_parse_never_crashes" / "1",
+            "seed",
+        )
+        targets = {t.name: t for t in _suite(project).fuzz_targets()}
+
+        given = targets["test_parse_never_crashes"]
+        assert (given.fuzz, given.examples, given.corpus, given.seeds) == (
+            "hypothesis",
+            2,
+            1,
+            3,
+        )
+        assert given.subject == "shop.cart.parse"
+        atheris = targets["fuzz_parse"]
+        assert (atheris.fuzz, atheris.examples, atheris.corpus) == ("atheris", 0, 2)
+        assert "test_parse" not in targets
+
+    @pytest.mark.unit
+    def test_markdown_lists_fuzz_targets(self, project):
+        _write(
+            project / "tests" / "test_fuzz.py",
+            "from hypothesis import given, strategies as st\n"
+            "from shop.cart import total\n\n"
+            "@given(st.lists(st.integers()))\n"
+            "def test_total(items):\n"
+            "    total(items)\n",
+        )
+        page = render_test_suite_markdown(_suite(project))
+
+        assert "- [Fuzz targets](#fuzz-targets) (1)" in page
+        assert (
+            "- `tests.test_fuzz.test_total` (hypothesis, `tests/test_fuzz.py:5`)"
+            " - 0 seed(s): 0 example(s), 0 corpus file(s)\n"
+            "  - Exercises: `shop.cart.total`" in page
+        )
@@ -0,0 +1,121 @@
+"""Unit tests for the third-party dependency appendix."""
+
+from __future__ import annotations
+
+import json
+from email.message import Message
+from importlib.metadata import PackageNotFoundError
+from pathlib import Path
+from types import SimpleNamespace
+
+import pytest
+
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services import third_party
+from services.third_party import declared_dependencies, find_third_party
+
+
+def _installed(name: str) -> SimpleNamespace:
+    """Only ``rich`` 13.7.1 is installed, with an MIT classifier."""
+    if name != "rich":
+        raise PackageNotFoundError(name)
+    metadata = Message()
+    metadata["License"] = "UNKNOWN"
+    metadata["Classifier"] = "License :: OSI Approved :: MIT License"
+    return SimpleNamespace(version="13.7.1", metadata=metadata)
+
+
+@pytest.fixture
+def tree(tmp_path: Path, monkeypatch: pytest.MonkeyPatch) -> Path:
+    """``shop`` importing httpx and yaml, ``shop.cli`` importing rich."""
+    monkeypatch.setattr(third_party, "distribution", _installed)
+    monkeypatch.setattr(third_party, "packages_distributions", lambda: {})
+    (tmp_path / "shop" / "cli").mkdir(parents=True)
+    (tmp_path / "shop" / "__init__.py").write_text(
+        '"""Shop."""\n\nimport httpx\nimport yaml\n',
+        encoding="utf-8",
+    )
+    (tmp_path / "shop" / "cli" / "__init__.py").write_text(
+        '"""Command line."""\n\nfrom rich.console import Console\n',
+        encoding="utf-8",
+    )
+    (tmp_path / "requirements.txt").write_text(
+        "# runtime\n-r base.txt\nhttpx[http2]==0.27.0 ; python_version >= '3.11'\n"
+        "rich>=13\n"
+        "wheel @ https://example.com/wheel.whl\n",
+        encoding="utf-8",
+    )
+    (tmp_path / "pyproject.toml").write_text(
+        '[project]\nname = "shop"\n'
+        'dependencies = ["PyYAML", "boto3>=1.34", "httpx>=0.20"]\n',
+        encoding="utf-8",
+    )
+    (tmp_path / "licenses.json").write_text(
+        json.dumps([{"Name": "httpx", "Version": "0.27.0", "License": "BSD-3-Clause"}]),
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_declared_dependencies(tree: Path) -> None:
+    assert declared_dependencies(tree) == {
+        "httpx": ("httpx", "==0.27.0"),
+        "rich": ("rich", ">=13"),
+        "pyyaml": ("PyYAML", ""),
+        "boto3": ("boto3", ">=1.34"),
+    }
+
+
+def test_find_third_party(tree: Path) -> None:
+    graph = parse_tree(tree).graph
+    dependencies = find_third_party(
+        tree,
+        graph,
+        {"httpx": "BSD-3-Clause"},
+        {"yaml": "PyYAML"},
+    )
+    assert [
+        (d.distribution, d.version_label(), d.license, d.importers)
+        for d in dependencies
+    ] == [
+        ("boto3", ">=1.34 (declared)", None, ()),
+        ("httpx", "0.27.0", "BSD-3-Clause", ("shop",)),
+        ("PyYAML", "unknown", None, ("shop",)),
+        ("rich", "13.7.1 (installed)", "MIT License", ("shop.cli",)),
+    ]
+
+
+def test_third_party_appendix(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig.from_dict(
+        {
+            "site": {
+                "third_party": {"licenses": "licenses.json"},
+                "external_links": {"distributions": {"yaml": "PyYAML"}},
+            },
+        },
+    )
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed, config), formats, config)
+
+    markdown = {page.path: page.content for page in sites["markdown"]}
+    assert "[Third-party dependencies](third-party.md)" in markdown["index.md"]
+    page = markdown["third-party.md"]
+    assert "| httpx | 0.27.0 | BSD-3-Clause | [shop](shop.md) |" in page
+    assert "| boto3 | >=1.34 (declared) | unknown | not imported |" in page
+    assert "| rich | 13.7.1 (installed) | MIT License | [shop.cli](shop.cli.md) |" in (
+        page
+    )
+    html = {page.path: page.content for page in sites["html"]}
+    assert '<td><a href="shop.html">shop</a></td>' in html["third-party.html"]
+    index = {page.path: page.content for page in sites["json"]}["index.json"]
+    assert json.loads(index)["third_party"][1]["version_source"] == "pinned"
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    assert "third-party.md" not in {page.path for page in default}
+    assert SiteConfig.from_dict({"third_party": True}).third_party.enabled
+    with pytest.raises(ProjectConfigError, match="site.third_party"):
+        SiteConfig.from_dict({"third_party": "yes"})
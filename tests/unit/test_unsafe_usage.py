@@ -0,0 +1,106 @@
+"""Unit tests for the native code, unsafe call, and reflection report."""
+
+from __future__ import annotations
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfig, ProjectConfigError, SiteConfig
+from autodoc.model import build_model
+from autodoc.parser import parse_tree
+from autodoc.render import render_site
+from services.unsafe_usage import find_risks, package_risks
+
+
+@pytest.fixture
+def tree(tmp_path: Path) -> Path:
+    """A ``fast`` package with native code and risky calls, and a safe one."""
+    for name in ("fast", "plain"):
+        (tmp_path / name).mkdir()
+        (tmp_path / name / "__init__.py").write_text(
+            f'"""{name.capitalize()}."""\n',
+            encoding="utf-8",
+        )
+    (tmp_path / "fast" / "_speedups.so").write_bytes(b"\x7fELF")
+    (tmp_path / "fast" / "buffers.py").write_text(
+        '"""Buffers."""\n\n'
+        "import ctypes\n"
+        "import importlib\n"
+        "from pickle import loads\n\n\n"
+        "class Buffer:\n"
+        "    def view(self, address):\n"
+        "        return ctypes.cast(address, ctypes.c_char_p)\n\n"
+        "    def field(self, name):\n"
+        "        return getattr(self, name)\n\n"
+        "    def size(self):\n"
+        '        return getattr(self, "size_")\n\n\n'
+        "def restore(data):\n"
+        "    return loads(data)\n\n\n"
+        "def plugin(name):\n"
+        "    return importlib.import_module(name)\n",
+        encoding="utf-8",
+    )
+    (tmp_path / "plain" / "text.py").write_text(
+        '"""Text."""\n\n\ndef upper(text):\n    return text.upper()\n',
+        encoding="utf-8",
+    )
+    return tmp_path
+
+
+def test_find_risks(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    usages = find_risks(parsed.root, parsed.graph)
+
+    assert [(u.kind, u.target, u.lineno, u.symbol) for u in usages] == [
+        ("native", "_speedups.so", 0, None),
+        ("native", "ctypes", 3, None),
+        ("unsafe", "ctypes.cast", 10, "fast.buffers.Buffer.view"),
+        ("reflection", "getattr", 13, "fast.buffers.Buffer.field"),
+        ("unsafe", "pickle.loads", 20, "fast.buffers.restore"),
+        ("reflection", "importlib.import_module", 24, "fast.buffers.plugin"),
+    ]
+    assert usages[0].file_path == "fast/_speedups.so"
+    [package] = package_risks(usages)
+    assert package.to_dict()["counts"] == {"native": 2, "unsafe": 2, "reflection": 2}
+
+
+def test_risk_appendix(tree: Path) -> None:
+    parsed = parse_tree(tree)
+    config = ProjectConfig(site=SiteConfig.from_dict({"risks": True}))
+    formats = ("markdown", "html", "json")
+    sites = render_site(parsed, build_model(parsed, config), formats, config)
+
+    markdown = {page.path: page.content for page in sites["markdown"]}
+    assert "[Risk appendix](risks.md)" in markdown["index.md"]
+    page = markdown["risks.md"]
+    assert "| fast | 2 | 2 | 2 |" in page
+    assert "plain" not in page
+    assert (
+        "- unsafe: `fast/buffers.py:20` in [`fast.buffers.restore`](fast.md#restore)"
+        " calls `pickle.loads`"
+    ) in page
+    assert "- native: `fast/_speedups.so` ships the compiled extension" in page
+    html = {page.path: page.content for page in sites["html"]}
+    assert "<td>fast</td><td>2</td><td>2</td><td>2</td>" in html["risks.html"]
+    index = {page.path: page.content for page in sites["json"]}["index.json"]
+    assert json.loads(index)["risks"][0]["package"] == "fast"
+
+    default = render_site(parsed, build_model(parsed), ("markdown",))["markdown"]
+    assert "risks.md" not in {page.path for page in default}
+    with pytest.raises(ProjectConfigError, match="site.risks"):
+        SiteConfig.from_dict({"risks": "yes"})
+
+
+def test_risks_command(tree: Path, capsys: pytest.CaptureFixture[str]) -> None:
+    assert run_command(["risks", "--root", str(tree)]) == 0
+    out = capsys.readouterr().out
+    assert "fast: 2 native, 2 unsafe, 2 reflection" in out
+    assert "fast/buffers.py:10: unsafe - fast.buffers.Buffer.view calls ctypes" in out
+    assert out.endswith("6 usage(s) in 1 package(s)\n")
+
+    assert run_command(["risks", "--root", str(tree), "--format", "json"]) == 0
+    data = json.loads(capsys.readouterr().out)
+    assert len(data["packages"][0]["usages"]) == 6
@@ -0,0 +1,142 @@
+"""Unit tests for the unused exported symbol report."""
+
+import json
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from autodoc.config.project import ProjectConfig, ProjectConfigError, UnusedConfig
+from autodoc.parser import parse_tree
+from services.entry_points import detect_architecture, read_plugins
+from services.unused_symbols import find_unused
+
+SOURCES = {
+    "shop/__init__.py": "",
+    "shop/cart.py": (
+        '"""Cart."""\n'
+        "class Cart:\n    pass\n\n\n"
+        "def total():\n    return 0\n\n\n"
+        "def helper():\n    return 1\n\n\n"
+        "def _private():\n    return 2\n"
+    ),
+    "shop/checkout.py": (
+        '"""Checkout."""\n'
+        "from shop.cart import helper\n\n\n"
+        "def orphan():\n    return helper()\n\n\n"
+        "@router.get('/checkout')\n"
+        "def checkout_view():\n    return 1\n\n\n"
+        "def plugin():\n    return 2\n"
+    ),
+    "web/__init__.py": "",
+    "web/app.py": '"""App."""\nfrom shop.cart import Cart\n\nCART = Cart\n',
+    "tool/__init__.py": "",
+    "tool/run.py": (
+        '"""Script."""\n\n\n'
+        "def main():\n    return 0\n\n\n"
+        "def unused_in_main():\n    return 1\n\n\n"
+        'if __name__ == "__main__":\n    main()\n'
+    ),
+    "tests/__init__.py": "",
+    "tests/test_shop.py": "def test_cart():\n    pass\n",
+    "pyproject.toml": (
+        '[project.entry-points."shop.plugins"]\n'
+        'checkout = "shop.checkout:plugin"\n'
+    ),
+}
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    for name, content in SOURCES.items():
+        path = tmp_path / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content, encoding="utf-8")
+    return tmp_path
+
+
+def _unused(root: Path, config: UnusedConfig | None = None) -> dict[str, bool]:
+    tree = parse_tree(root)
+    plugins = [entry.target for entry in read_plugins(root)]
+    unused = find_unused(
+        tree.graph,
+        tree.symbols,
+        config,
+        detect_architecture(root, tree.graph),
+        plugins,
+    )
+    return {symbol.qualified_name: symbol.referenced_in_package for symbol in unused}
+
+
+class TestFindUnused:
+    """Tests for :func:`services.unused_symbols.find_unused`."""
+
+    @pytest.mark.unit
+    def test_reports_symbols_unused_outside_their_package(self, source_tree):
+        assert _unused(source_tree) == {
+            "shop.cart.helper": True,
+            "shop.cart.total": False,
+            "shop.checkout.orphan": False,
+        }
+
+    @pytest.mark.unit
+    def test_config_excludes_packages_and_symbols(self, source_tree):
+        config = UnusedConfig(keep=["shop.cart.*"], decorators=[])
+        assert _unused(source_tree, config) == {"shop.checkout.orphan": False}
+        assert _unused(source_tree, UnusedConfig(packages=["sh*"])) == {}
+
+    @pytest.mark.unit
+    def test_without_architecture_main_packages_are_reported(self, source_tree):
+        tree = parse_tree(source_tree)
+        names = {u.qualified_name for u in find_unused(tree.graph, tree.symbols)}
+        assert "tool.run.unused_in_main" in names
+        assert "shop.checkout.plugin" in names
+
+    @pytest.mark.unit
+    def test_read_plugins(self, source_tree):
+        [entry] = read_plugins(source_tree)
+        assert (entry.kind, entry.name, entry.target) == (
+            "plugin",
+            "shop.plugins:checkout",
+            "shop.checkout.plugin",
+        )
+
+    @pytest.mark.unit
+    def test_config_section(self):
+        config = ProjectConfig.from_dict({"unused": {"packages": "cli", "keep": []}})
+        assert config.unused.packages == ["cli"]
+        with pytest.raises(ProjectConfigError, match="unused.keep"):
+            ProjectConfig.from_dict({"unused": {"keep": [1]}})
+
+
+class TestUnusedCommand:
+    """Tests for ``autodoc unused``."""
+
+    @pytest.mark.unit
+    def test_json_output(self, source_tree, capsys):
+        assert run_command(["unused", "--root", str(source_tree), "--format", "json"]) == 0
+        report = json.loads(capsys.readouterr().out)
+        assert report["schema_version"] == 1
+        assert report["unused"][0] == {
+            "qualified_name": "shop.cart.helper",
+            "kind": "function",
+            "package": "shop",
+            "file_path": "shop/cart.py",
+            "lineno": 10,
+            "referenced_in_package": True,
+            "suggestion": "unexport",
+        }
+
+    @pytest.mark.unit
+    def test_check_fails_on_findings(self, source_tree, capsys):
+        (source_tree / "autodoc.yaml").write_text(
+            "unused:\n  keep: [shop.*]\n",
+            encoding="utf-8",
+        )
+        assert run_command(["unused", "--root", str(source_tree), "--check"]) == 0
+        assert "0 unused exported symbol(s)" in capsys.readouterr().out
+
+        (source_tree / "autodoc.yaml").unlink()
+        assert run_command(["unused", "--root", str(source_tree), "--check"]) == 1
+        out = capsys.readouterr().out
+        assert "shop/cart.py:6: function shop.cart.total is not used outside shop" in out
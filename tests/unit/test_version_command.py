@@ -0,0 +1,38 @@
+"""Unit tests for ``autodoc version``."""
+
+import json
+
+import pytest
+
+from autodoc import __version__
+from autodoc.cli.main import COMMANDS, run_command
+from services.schema import SCHEMA_VERSION
+
+
+class TestVersionCommand:
+    """Tests for the version and feature handshake."""
+
+    @pytest.mark.unit
+    def test_json_handshake(self, capsys):
+        assert run_command(["version", "--json"]) == 0
+
+        info = json.loads(capsys.readouterr().out)
+        assert info["name"] == "autodoc"
+        assert info["version"] == __version__
+        assert info["schema_version"] == SCHEMA_VERSION
+        assert info["commands"] == sorted(COMMANDS)
+        assert "generate-incremental" in info["features"]
+        assert info["features"] == sorted(info["features"])
+
+    @pytest.mark.unit
+    def test_text(self, capsys):
+        assert run_command(["version"]) == 0
+
+        out = capsys.readouterr().out
+        assert out.startswith(f"autodoc {__version__} (schema {SCHEMA_VERSION})\n")
+
+    @pytest.mark.unit
+    def test_require(self, capsys):
+        assert run_command(["version", "--require", "dry-run"]) == 0
+        assert run_command(["version", "--require", "dry-run", "--require", "x"]) == 1
+        assert "unsupported feature(s): x" in capsys.readouterr().err
@@ -0,0 +1,69 @@
+"""Unit tests for ``--dry-run`` write plans."""
+
+from pathlib import Path
+
+import pytest
+
+from autodoc.cli.main import run_command
+from services.write_plan import CREATE, MODIFY, UNCHANGED, plan_write
+
+
+@pytest.fixture
+def source_tree(tmp_path: Path) -> Path:
+    """A source tree with one undocumented module."""
+    src = tmp_path / "src"
+    src.mkdir()
+    (src / "mod.py").write_text("def run():\n    pass\n", encoding="utf-8")
+    return src
+
+
+class TestPlanWrite:
+    """Tests for comparing rendered output with the disk."""
+
+    @pytest.mark.unit
+    def test_actions(self, tmp_path):
+        target = tmp_path / "out.txt"
+        assert plan_write(target, "a\n").action == CREATE
+
+        target.write_text("a\n", encoding="utf-8")
+        assert plan_write(target, "a\n").action == UNCHANGED
+        assert plan_write(target, b"b\n").action == MODIFY
+
+
+class TestDryRunCommands:
+    """Tests for ``--dry-run`` on the writing commands."""
+
+    @pytest.mark.unit
+    def test_generate(self, source_tree, tmp_path, capsys):
+        site = tmp_path / "site"
+        args = ["generate", "--root", str(source_tree), "--output", str(site)]
+
+        assert run_command([*args, "--dry-run"]) == 0
+        out = capsys.readouterr().out
+        assert f"would create {(site / 'index.md').as_posix()}" in out
+        assert "Dry run: 3 to create, 0 to modify, 0 to delete, 0 unchanged" in out
+        assert not site.exists()
+
+        run_command(args)
+        capsys.readouterr()
+        run_command([*args, "--dry-run"])
+        assert "0 to create, 0 to modify, 0 to delete, 3 unchanged" in capsys.readouterr().out
+
+    @pytest.mark.unit
+    def test_baseline_write(self, source_tree, capsys):
+        baseline = source_tree / ".autodoc-baseline.json"
+
+        run_command(["baseline", "write", "--root", str(source_tree), "--dry-run"])
+
+        assert f"would create {baseline.as_posix()}" in capsys.readouterr().out
+        assert not baseline.exists()
+
+    @pytest.mark.unit
+    def test_api_manifest(self, source_tree, capsys):
+        manifest = source_tree / "api.txt"
+        manifest.write_text("stale\n", encoding="utf-8")
+
+        run_command(["api", "--root", str(source_tree), "--dry-run"])
+
+        assert f"would modify {manifest.as_posix()}" in capsys.readouterr().out
+        assert manifest.read_text(encoding="utf-8") == "stale\n"